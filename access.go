@@ -0,0 +1,194 @@
+package bacnet
+
+// This file contains the enumerations used by the access control
+// objects: Access Door, Access Point, Access Credential...
+
+// DoorValue is a command sent to an Access Door object
+type DoorValue uint32
+
+//go:generate stringer -type=DoorValue
+const (
+	DoorValueLock                DoorValue = 0
+	DoorValueUnlock              DoorValue = 1
+	DoorValuePulseUnlock         DoorValue = 2
+	DoorValueExtendedPulseUnlock DoorValue = 3
+)
+
+// DoorStatusValue is the physical state of a door, the value of the
+// DoorStatus property
+type DoorStatusValue uint32
+
+//go:generate stringer -type=DoorStatusValue
+const (
+	DoorStatusClosed        DoorStatusValue = 0
+	DoorStatusOpened        DoorStatusValue = 1
+	DoorStatusUnknown       DoorStatusValue = 2
+	DoorStatusDoorFault     DoorStatusValue = 3
+	DoorStatusUnused        DoorStatusValue = 4
+	DoorStatusNone          DoorStatusValue = 5
+	DoorStatusClosing       DoorStatusValue = 6
+	DoorStatusOpening       DoorStatusValue = 7
+	DoorStatusSafetyLocked  DoorStatusValue = 8
+	DoorStatusLimitedOpened DoorStatusValue = 9
+)
+
+// DoorSecuredStatus tells if a door is secured
+type DoorSecuredStatus uint32
+
+//go:generate stringer -type=DoorSecuredStatus
+const (
+	DoorSecuredStatusSecured   DoorSecuredStatus = 0
+	DoorSecuredStatusUnsecured DoorSecuredStatus = 1
+	DoorSecuredStatusUnknown   DoorSecuredStatus = 2
+)
+
+// DoorAlarmStateValue is the alarm state of an Access Door object, the
+// value of its DoorAlarmState property
+type DoorAlarmStateValue uint32
+
+//go:generate stringer -type=DoorAlarmStateValue
+const (
+	DoorAlarmStateNormal          DoorAlarmStateValue = 0
+	DoorAlarmStateAlarm           DoorAlarmStateValue = 1
+	DoorAlarmStateDoorOpenTooLong DoorAlarmStateValue = 2
+	DoorAlarmStateForcedOpen      DoorAlarmStateValue = 3
+	DoorAlarmStateTamper          DoorAlarmStateValue = 4
+	DoorAlarmStateDoorFault       DoorAlarmStateValue = 5
+	DoorAlarmStateLockDown        DoorAlarmStateValue = 6
+	DoorAlarmStateFreeAccess      DoorAlarmStateValue = 7
+	DoorAlarmStateEgressOpen      DoorAlarmStateValue = 8
+)
+
+// AccessEventValue is an event reported by an Access Point object, the
+// value of its AccessEvent property. Values from 128 are denied accesses
+type AccessEventValue uint32
+
+//go:generate stringer -type=AccessEventValue
+const (
+	AccessEventNone                                AccessEventValue = 0
+	AccessEventGranted                             AccessEventValue = 1
+	AccessEventMuster                              AccessEventValue = 2
+	AccessEventPassbackDetected                    AccessEventValue = 3
+	AccessEventDuress                              AccessEventValue = 4
+	AccessEventTrace                               AccessEventValue = 5
+	AccessEventLockoutMaxAttempts                  AccessEventValue = 6
+	AccessEventLockoutOther                        AccessEventValue = 7
+	AccessEventLockoutRelinquished                 AccessEventValue = 8
+	AccessEventLockedByHigherPriority              AccessEventValue = 9
+	AccessEventOutOfService                        AccessEventValue = 10
+	AccessEventOutOfServiceRelinquished            AccessEventValue = 11
+	AccessEventAccompanimentBy                     AccessEventValue = 12
+	AccessEventAuthenticationFactorRead            AccessEventValue = 13
+	AccessEventAuthorizationDelayed                AccessEventValue = 14
+	AccessEventVerificationRequired                AccessEventValue = 15
+	AccessEventNoEntryAfterGranted                 AccessEventValue = 16
+	AccessEventDeniedDenyAll                       AccessEventValue = 128
+	AccessEventDeniedUnknownCredential             AccessEventValue = 129
+	AccessEventDeniedAuthenticationUnavailable     AccessEventValue = 130
+	AccessEventDeniedAuthenticationFactorTimeout   AccessEventValue = 131
+	AccessEventDeniedIncorrectAuthenticationFactor AccessEventValue = 132
+	AccessEventDeniedZoneNoAccessRights            AccessEventValue = 133
+	AccessEventDeniedPointNoAccessRights           AccessEventValue = 134
+	AccessEventDeniedNoAccessRights                AccessEventValue = 135
+	AccessEventDeniedOutOfTimeRange                AccessEventValue = 136
+	AccessEventDeniedThreatLevel                   AccessEventValue = 137
+	AccessEventDeniedPassback                      AccessEventValue = 138
+	AccessEventDeniedUnexpectedLocationUsage       AccessEventValue = 139
+	AccessEventDeniedMaxAttempts                   AccessEventValue = 140
+	AccessEventDeniedLowerOccupancyLimit           AccessEventValue = 141
+	AccessEventDeniedUpperOccupancyLimit           AccessEventValue = 142
+	AccessEventDeniedAuthenticationFactorLost      AccessEventValue = 143
+	AccessEventDeniedAuthenticationFactorStolen    AccessEventValue = 144
+	AccessEventDeniedAuthenticationFactorDamaged   AccessEventValue = 145
+	AccessEventDeniedAuthenticationFactorDestroyed AccessEventValue = 146
+	AccessEventDeniedAuthenticationFactorDisabled  AccessEventValue = 147
+	AccessEventDeniedAuthenticationFactorError     AccessEventValue = 148
+	AccessEventDeniedCredentialUnassigned          AccessEventValue = 149
+	AccessEventDeniedCredentialNotProvisioned      AccessEventValue = 150
+	AccessEventDeniedCredentialNotYetActive        AccessEventValue = 151
+	AccessEventDeniedCredentialExpired             AccessEventValue = 152
+	AccessEventDeniedCredentialManualDisable       AccessEventValue = 153
+	AccessEventDeniedCredentialLockout             AccessEventValue = 154
+	AccessEventDeniedCredentialMaxDays             AccessEventValue = 155
+	AccessEventDeniedCredentialMaxUses             AccessEventValue = 156
+	AccessEventDeniedCredentialInactivity          AccessEventValue = 157
+	AccessEventDeniedCredentialDisabled            AccessEventValue = 158
+	AccessEventDeniedNoAccompaniment               AccessEventValue = 159
+	AccessEventDeniedIncorrectAccompaniment        AccessEventValue = 160
+	AccessEventDeniedLockout                       AccessEventValue = 161
+	AccessEventDeniedVerificationFailed            AccessEventValue = 162
+	AccessEventDeniedVerificationTimeout           AccessEventValue = 163
+	AccessEventDeniedOther                         AccessEventValue = 164
+)
+
+// AuthenticationStatusValue is the state of the authentication process
+// of an Access Point object, the value of its AuthenticationStatus
+// property
+type AuthenticationStatusValue uint32
+
+//go:generate stringer -type=AuthenticationStatusValue
+const (
+	AuthenticationStatusNotReady                       AuthenticationStatusValue = 0
+	AuthenticationStatusReady                          AuthenticationStatusValue = 1
+	AuthenticationStatusDisabled                       AuthenticationStatusValue = 2
+	AuthenticationStatusWaitingForAuthenticationFactor AuthenticationStatusValue = 3
+	AuthenticationStatusWaitingForAccompaniment        AuthenticationStatusValue = 4
+	AuthenticationStatusWaitingForVerification         AuthenticationStatusValue = 5
+	AuthenticationStatusInProgress                     AuthenticationStatusValue = 6
+)
+
+// AccessCredentialDisable tells if and why an Access Credential object is disabled
+type AccessCredentialDisable uint32
+
+//go:generate stringer -type=AccessCredentialDisable
+const (
+	AccessCredentialDisableNone           AccessCredentialDisable = 0
+	AccessCredentialDisableDisable        AccessCredentialDisable = 1
+	AccessCredentialDisableDisableManual  AccessCredentialDisable = 2
+	AccessCredentialDisableDisableLockout AccessCredentialDisable = 3
+)
+
+// AccessAuthenticationFactorDisable tells if and why an authentication factor of a credential is disabled
+type AccessAuthenticationFactorDisable uint32
+
+//go:generate stringer -type=AccessAuthenticationFactorDisable
+const (
+	AccessAuthenticationFactorDisableNone              AccessAuthenticationFactorDisable = 0
+	AccessAuthenticationFactorDisableDisabled          AccessAuthenticationFactorDisable = 1
+	AccessAuthenticationFactorDisableDisabledLost      AccessAuthenticationFactorDisable = 2
+	AccessAuthenticationFactorDisableDisabledStolen    AccessAuthenticationFactorDisable = 3
+	AccessAuthenticationFactorDisableDisabledDamaged   AccessAuthenticationFactorDisable = 4
+	AccessAuthenticationFactorDisableDisabledDestroyed AccessAuthenticationFactorDisable = 5
+)
+
+// AuthenticationFactorType is the format of an authentication factor
+type AuthenticationFactorType uint32
+
+//go:generate stringer -type=AuthenticationFactorType
+const (
+	AuthenticationFactorTypeUndefined          AuthenticationFactorType = 0
+	AuthenticationFactorTypeError              AuthenticationFactorType = 1
+	AuthenticationFactorTypeCustom             AuthenticationFactorType = 2
+	AuthenticationFactorTypeSimpleNumber16     AuthenticationFactorType = 3
+	AuthenticationFactorTypeSimpleNumber32     AuthenticationFactorType = 4
+	AuthenticationFactorTypeSimpleNumber56     AuthenticationFactorType = 5
+	AuthenticationFactorTypeSimpleAlphaNumeric AuthenticationFactorType = 6
+	AuthenticationFactorTypeABATrack2          AuthenticationFactorType = 7
+	AuthenticationFactorTypeWiegand26          AuthenticationFactorType = 8
+	AuthenticationFactorTypeWiegand37          AuthenticationFactorType = 9
+	AuthenticationFactorTypeWiegand37Facility  AuthenticationFactorType = 10
+	AuthenticationFactorTypeFacility16Card32   AuthenticationFactorType = 11
+	AuthenticationFactorTypeFacility32Card32   AuthenticationFactorType = 12
+	AuthenticationFactorTypeFASCN              AuthenticationFactorType = 13
+	AuthenticationFactorTypeFASCNBCD           AuthenticationFactorType = 14
+	AuthenticationFactorTypeFASCNLarge         AuthenticationFactorType = 15
+	AuthenticationFactorTypeFASCNLargeBCD      AuthenticationFactorType = 16
+	AuthenticationFactorTypeGSA75              AuthenticationFactorType = 17
+	AuthenticationFactorTypeCHUID              AuthenticationFactorType = 18
+	AuthenticationFactorTypeCHUIDFull          AuthenticationFactorType = 19
+	AuthenticationFactorTypeGUID               AuthenticationFactorType = 20
+	AuthenticationFactorTypeCbeffA             AuthenticationFactorType = 21
+	AuthenticationFactorTypeCbeffB             AuthenticationFactorType = 22
+	AuthenticationFactorTypeCbeffC             AuthenticationFactorType = 23
+	AuthenticationFactorTypeUserPassword       AuthenticationFactorType = 24
+)
@@ -0,0 +1,122 @@
+package bacnet
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// GlobalNetwork is the network number of a global broadcast, to
+// reach all the networks
+const GlobalNetwork = 0xFFFF
+
+// RoutedAddress returns the address of a device on a remote network,
+// reached through the BACnet router at the given IP address. mac is
+// the address of the device on its own network
+func RoutedAddress(router net.UDPAddr, network uint16, mac []byte) Address {
+	addr := AddressFromUDP(router)
+	addr.Net = network
+	addr.Adr = mac
+	return *addr
+}
+
+// MSTPAddress returns the address of an MS/TP device behind a router.
+// MS/TP MAC addresses are a single byte, from 0 to 254
+func MSTPAddress(router net.UDPAddr, network uint16, mac byte) Address {
+	return RoutedAddress(router, network, []byte{mac})
+}
+
+// ARCNETAddress returns the address of an ARCNET device behind a
+// router. ARCNET MAC addresses are a single byte
+func ARCNETAddress(router net.UDPAddr, network uint16, mac byte) Address {
+	return RoutedAddress(router, network, []byte{mac})
+}
+
+// IsRouted tells if the device is on a remote network
+func (a Address) IsRouted() bool {
+	return a.Net != 0
+}
+
+// String formats the address as the IP address and port of the
+// device, or as the network number and MAC address of the device on
+// its network followed by the router, as in 2001:0x0D@192.168.1.1:47808.
+// A broadcast on a remote network is formatted as the router followed
+// by the network, as in 192.168.1.1:47808@2001. The formats are the
+// ones read by ParseAddress
+func (a Address) String() string {
+	if !a.IsRouted() {
+		return FormatMAC(a.Mac)
+	}
+	if len(a.Adr) == 0 && len(a.Mac) > 0 {
+		return FormatMAC(a.Mac) + "@" + strconv.Itoa(int(a.Net))
+	}
+	s := strconv.Itoa(int(a.Net)) + ":" + FormatMAC(a.Adr)
+	if len(a.Mac) > 0 {
+		s += "@" + FormatMAC(a.Mac)
+	}
+	return s
+}
+
+// FormatMAC formats a MAC address. IP addresses are formatted with
+// their port, the other ones in hexadecimal such as 0x0D. An empty
+// MAC address is a broadcast and is formatted as *
+func FormatMAC(mac []byte) string {
+	switch {
+	case len(mac) == 0:
+		return "*"
+	case len(mac) == 6:
+		//B/IP address of a remote device
+		return udpString(net.IP(mac[:4]), mac[4:])
+	case len(mac) == 1+net.IPv4len+2 && mac[0] == net.IPv4len,
+		len(mac) == 1+net.IPv6len+2 && mac[0] == net.IPv6len:
+		return udpString(net.IP(mac[1:len(mac)-2]), mac[len(mac)-2:])
+	default:
+		return "0x" + strings.ToUpper(hex.EncodeToString(mac))
+	}
+}
+
+func udpString(ip net.IP, port []byte) string {
+	return net.JoinHostPort(ip.String(), strconv.Itoa(int(binary.BigEndian.Uint16(port))))
+}
+
+// ParseMAC parses the MAC address of a device on a remote network.
+// It's either a number such as 13 or 0x0D for MS/TP and ARCNET
+// devices, an hexadecimal string such as 0x0A0B0C for longer
+// addresses, or an IPv4 address and port for B/IP devices
+func ParseMAC(s string) ([]byte, error) {
+	if s == "*" {
+		return []byte{}, nil
+	}
+	if host, port, err := net.SplitHostPort(s); err == nil {
+		ip := net.ParseIP(host).To4()
+		if ip == nil {
+			return nil, fmt.Errorf("invalid IPv4 address %q", host)
+		}
+		p, err := strconv.ParseUint(port, 10, 16)
+		if err != nil {
+			return nil, fmt.Errorf("invalid port %q", port)
+		}
+		mac := make([]byte, 6)
+		copy(mac, ip)
+		binary.BigEndian.PutUint16(mac[4:], uint16(p))
+		return mac, nil
+	}
+	if h := strings.TrimPrefix(strings.TrimPrefix(s, "0x"), "0X"); h != s {
+		if len(h)%2 == 1 {
+			h = "0" + h
+		}
+		mac, err := hex.DecodeString(h)
+		if err != nil || len(mac) == 0 {
+			return nil, fmt.Errorf("invalid MAC address %q", s)
+		}
+		return mac, nil
+	}
+	v, err := strconv.ParseUint(s, 10, 8)
+	if err != nil {
+		return nil, fmt.Errorf("invalid MAC address %q", s)
+	}
+	return []byte{byte(v)}, nil
+}
@@ -0,0 +1,74 @@
+package bacnet
+
+import (
+	"net"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestParseMAC(t *testing.T) {
+	ttc := []struct {
+		s         string
+		mac       []byte
+		formatted string
+	}{
+		{s: "192.168.1.20:47808", mac: []byte{192, 168, 1, 20, 0xBA, 0xC0}, formatted: "192.168.1.20:47808"},
+		{s: "10.0.0.1:47809", mac: []byte{10, 0, 0, 1, 0xBA, 0xC1}, formatted: "10.0.0.1:47809"},
+		{s: "13", mac: []byte{13}, formatted: "0x0D"},
+		{s: "0x0D", mac: []byte{13}, formatted: "0x0D"},
+		{s: "0xd", mac: []byte{13}, formatted: "0x0D"},
+		{s: "254", mac: []byte{254}, formatted: "0xFE"},
+		{s: "0x0A0B0C", mac: []byte{10, 11, 12}, formatted: "0x0A0B0C"},
+		{s: "*", mac: []byte{}, formatted: "*"},
+	}
+	for _, tc := range ttc {
+		t.Run(tc.s, func(t *testing.T) {
+			is := is.New(t)
+			mac, err := ParseMAC(tc.s)
+			is.NoErr(err)
+			is.Equal(mac, tc.mac)
+			is.Equal(FormatMAC(mac), tc.formatted)
+			again, err := ParseMAC(FormatMAC(mac))
+			is.NoErr(err)
+			is.Equal(again, mac)
+		})
+	}
+}
+
+func TestParseMACInvalid(t *testing.T) {
+	for _, s := range []string{"", "256", "-1", "0x", "0xZZ", "mstp", "192.168.1:47808", "192.168.1.20:70000", "[::1]:47808"} {
+		t.Run(s, func(t *testing.T) {
+			_, err := ParseMAC(s)
+			if err == nil {
+				t.Errorf("%q parsed", s)
+			}
+		})
+	}
+}
+
+func TestAddressString(t *testing.T) {
+	router := net.UDPAddr{IP: net.IPv4(192, 168, 1, 1).To4(), Port: 47808}
+	ttc := []struct {
+		name string
+		addr Address
+		s    string
+	}{
+		{name: "local", addr: *AddressFromUDP(net.UDPAddr{IP: net.IPv4(192, 168, 1, 20).To4(), Port: 47808}), s: "192.168.1.20:47808"},
+		{name: "mstp", addr: MSTPAddress(router, 2001, 0x0D), s: "2001:0x0D@192.168.1.1:47808"},
+		{name: "arcnet", addr: ARCNETAddress(router, 3, 0x7F), s: "3:0x7F@192.168.1.1:47808"},
+		{name: "remote bip", addr: RoutedAddress(router, 5, []byte{10, 0, 0, 1, 0xBA, 0xC0}), s: "5:10.0.0.1:47808@192.168.1.1:47808"},
+		{name: "remote network", addr: RoutedAddress(router, 2001, nil), s: "192.168.1.1:47808@2001"},
+		{name: "global broadcast", addr: RoutedAddress(net.UDPAddr{IP: net.IPv4(192, 168, 1, 255).To4(), Port: 47808}, GlobalNetwork, nil), s: "192.168.1.255:47808@65535"},
+		{name: "without router", addr: Address{Net: 2001, Adr: []byte{0x0D}}, s: "2001:0x0D"},
+	}
+	for _, tc := range ttc {
+		t.Run(tc.name, func(t *testing.T) {
+			is := is.New(t)
+			is.Equal(tc.addr.String(), tc.s)
+			addr, err := ParseAddress(tc.s)
+			is.NoErr(err)
+			is.Equal(addr, tc.addr)
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package bacip
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/REQUEA/bacnet"
+	"github.com/REQUEA/bacnet/internal/encoding"
+)
+
+// AuthenticationFactor is a value presented to an access point to
+// authenticate, such as the content of a card or a PIN
+type AuthenticationFactor struct {
+	FormatType  bacnet.AuthenticationFactorType
+	FormatClass uint32
+	Value       []byte
+}
+
+func (f AuthenticationFactor) MarshalBinary() ([]byte, error) {
+	encoder := encoding.NewEncoder()
+	f.encode(&encoder)
+	return encoder.Bytes(), encoder.Error()
+}
+
+func (f *AuthenticationFactor) UnmarshalBinary(data []byte) error {
+	decoder := encoding.NewDecoder(data)
+	f.decode(decoder)
+	return decoder.Error()
+}
+
+func (f AuthenticationFactor) encode(e *encoding.Encoder) {
+	e.ContextUnsigned(0, uint32(f.FormatType))
+	e.ContextUnsigned(1, f.FormatClass)
+	e.ContextOctetString(2, f.Value)
+}
+
+func (f *AuthenticationFactor) decode(d *encoding.Decoder) {
+	var val uint32
+	d.ContextValue(0, &val)
+	f.FormatType = bacnet.AuthenticationFactorType(val)
+	d.ContextValue(1, &f.FormatClass)
+	d.ContextOctetString(2, &f.Value)
+}
+
+// CredentialAuthenticationFactor is an authentication factor of an
+// Access Credential object
+type CredentialAuthenticationFactor struct {
+	Disable bacnet.AccessAuthenticationFactorDisable
+	Factor  AuthenticationFactor
+}
+
+func (f CredentialAuthenticationFactor) MarshalBinary() ([]byte, error) {
+	encoder := encoding.NewEncoder()
+	f.encode(&encoder)
+	return encoder.Bytes(), encoder.Error()
+}
+
+func (f *CredentialAuthenticationFactor) UnmarshalBinary(data []byte) error {
+	decoder := encoding.NewDecoder(data)
+	f.decode(decoder)
+	return decoder.Error()
+}
+
+func (f CredentialAuthenticationFactor) encode(e *encoding.Encoder) {
+	e.ContextUnsigned(0, uint32(f.Disable))
+	e.OpeningTag(1)
+	f.Factor.encode(e)
+	e.ClosingTag(1)
+}
+
+func (f *CredentialAuthenticationFactor) decode(d *encoding.Decoder) {
+	var val uint32
+	d.ContextValue(0, &val)
+	f.Disable = bacnet.AccessAuthenticationFactorDisable(val)
+	d.OpeningTag(1)
+	f.Factor.decode(d)
+	d.ClosingTag(1)
+}
+
+// DecodeCredentialAuthenticationFactors decodes the
+// authentication-factors property of an Access Credential object
+func DecodeCredentialAuthenticationFactors(data []byte) ([]CredentialAuthenticationFactor, error) {
+	factors := []CredentialAuthenticationFactor{}
+	err := decodeList(data, func(d *encoding.Decoder) error {
+		f := CredentialAuthenticationFactor{}
+		f.decode(d)
+		factors = append(factors, f)
+		return nil
+	})
+	return factors, err
+}
+
+// ReadCredentialAuthenticationFactors reads the authentication factors
+// of an Access Credential object
+func (c *Client) ReadCredentialAuthenticationFactors(ctx context.Context, device bacnet.Device, credential bacnet.ObjectID) ([]CredentialAuthenticationFactor, error) {
+	raw, err := c.readRaw(ctx, device, ReadProperty{
+		ObjectID: credential,
+		Property: bacnet.PropertyIdentifier{Type: bacnet.AuthenticationFactors},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("read authentication factors: %w", err)
+	}
+	factors, err := DecodeCredentialAuthenticationFactors(raw)
+	if err != nil {
+		return nil, fmt.Errorf("decode authentication factors: %w", err)
+	}
+	return factors, nil
+}
+
+// CommandDoor writes the command to the present value of an Access
+// Door object at the given priority
+func (c *Client) CommandDoor(ctx context.Context, device bacnet.Device, door bacnet.ObjectID, command bacnet.DoorValue, priority bacnet.PriorityList) error {
+	if door.Type != bacnet.AccessDoor {
+		return fmt.Errorf("%v isn't an access door", door)
+	}
+	return c.WriteProperty(ctx, device, WriteProperty{
+		ObjectID:      door,
+		Property:      bacnet.PropertyIdentifier{Type: bacnet.PresentValue},
+		PropertyValue: bacnet.PropertyValue{Type: bacnet.TypeEnumerated, Value: uint32(command)},
+		Priority:      priority,
+	})
+}
+
+// ReadAccessEvent reads the last access event of an Access Point
+// object, along with its time stamp
+func (c *Client) ReadAccessEvent(ctx context.Context, device bacnet.Device, accessPoint bacnet.ObjectID) (bacnet.AccessEventValue, bacnet.TimeStamp, error) {
+	var ts bacnet.TimeStamp
+	d, err := c.ReadProperty(ctx, device, ReadProperty{
+		ObjectID: accessPoint,
+		Property: bacnet.PropertyIdentifier{Type: bacnet.AccessEvent},
+	})
+	if err != nil {
+		return 0, ts, fmt.Errorf("read access event: %w", err)
+	}
+	event, ok := d.(uint32)
+	if !ok {
+		return 0, ts, fmt.Errorf("read access event: unexpected type %T", d)
+	}
+	raw, err := c.readRaw(ctx, device, ReadProperty{
+		ObjectID: accessPoint,
+		Property: bacnet.PropertyIdentifier{Type: bacnet.AccessEventTime},
+	})
+	if err != nil {
+		return bacnet.AccessEventValue(event), ts, fmt.Errorf("read access event time: %w", err)
+	}
+	decoder := encoding.NewDecoder(raw)
+	decodeTimeStampChoice(decoder, &ts)
+	if decoder.Error() != nil {
+		return bacnet.AccessEventValue(event), ts, fmt.Errorf("decode access event time: %w", decoder.Error())
+	}
+	return bacnet.AccessEventValue(event), ts, nil
+}
@@ -0,0 +1,27 @@
+package bacip
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/REQUEA/bacnet"
+
+	"github.com/matryer/is"
+)
+
+func TestCredentialAuthenticationFactorCoherency(t *testing.T) {
+	is := is.New(t)
+	f := CredentialAuthenticationFactor{
+		Disable: bacnet.AccessAuthenticationFactorDisableNone,
+		Factor: AuthenticationFactor{
+			FormatType: bacnet.AuthenticationFactorTypeWiegand26,
+			Value:      []byte{1, 2, 3, 4},
+		},
+	}
+	b, err := f.MarshalBinary()
+	is.NoErr(err)
+	is.Equal(hex.EncodeToString(b), "09001e090819002c010203041f")
+	factors, err := DecodeCredentialAuthenticationFactors(append(b, b...))
+	is.NoErr(err)
+	is.Equal(factors, []CredentialAuthenticationFactor{f, f})
+}
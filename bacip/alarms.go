@@ -0,0 +1,234 @@
+package bacip
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/REQUEA/bacnet"
+	"github.com/REQUEA/bacnet/internal/encoding"
+)
+
+// maxAlarmConcurrency is the number of devices polled at the same
+// time by AlarmSummaries
+const maxAlarmConcurrency = 16
+
+// EventTransitions are flags for the to-offnormal, to-fault and
+// to-normal transitions of an object, such as the acknowledged ones
+type EventTransitions struct {
+	ToOffnormal bool
+	ToFault     bool
+	ToNormal    bool
+}
+
+func eventTransitionsFromBits(bs bacnet.BitString) EventTransitions {
+	return EventTransitions{
+		ToOffnormal: bs.Bit(0),
+		ToFault:     bs.Bit(1),
+		ToNormal:    bs.Bit(2),
+	}
+}
+
+// AlarmSummary is an object of a device in alarm
+type AlarmSummary struct {
+	ObjectID         bacnet.ObjectID
+	AlarmState       bacnet.EventStateValue
+	AckedTransitions EventTransitions
+}
+
+// GetAlarmSummary is the GetAlarmSummary request. The request has no
+// parameters, Alarms is set with the answer
+type GetAlarmSummary struct {
+	Alarms []AlarmSummary
+}
+
+func (s GetAlarmSummary) MarshalBinary() ([]byte, error) {
+	encoder := encoding.NewEncoder()
+	return encoder.Bytes(), encoder.Error()
+}
+
+func (s *GetAlarmSummary) UnmarshalBinary(data []byte) error {
+	decoder := encoding.NewDecoder(data)
+	s.Alarms = nil
+	for decoder.Len() > 0 && decoder.Error() == nil {
+		var a AlarmSummary
+		var acked bacnet.BitString
+		decoder.AppData(&a.ObjectID)
+		decoder.AppData(&a.AlarmState)
+		decoder.AppData(&acked)
+		a.AckedTransitions = eventTransitionsFromBits(acked)
+		s.Alarms = append(s.Alarms, a)
+	}
+	return decoder.Error()
+}
+
+// AckFilter selects the enrollments by acknowledgment state
+type AckFilter uint32
+
+const (
+	AckFilterAll      AckFilter = 0
+	AckFilterAcked    AckFilter = 1
+	AckFilterNotAcked AckFilter = 2
+)
+
+// EventStateFilter selects the enrollments by event state
+type EventStateFilter uint32
+
+const (
+	EventStateFilterOffnormal EventStateFilter = 0
+	EventStateFilterFault     EventStateFilter = 1
+	EventStateFilterNormal    EventStateFilter = 2
+	EventStateFilterAll       EventStateFilter = 3
+	EventStateFilterActive    EventStateFilter = 4
+)
+
+// PriorityFilter selects the enrollments whose priority is between
+// Min and Max, included
+type PriorityFilter struct {
+	Min, Max uint8
+}
+
+// EnrollmentSummary is an object of a device generating events
+type EnrollmentSummary struct {
+	ObjectID   bacnet.ObjectID
+	EventType  bacnet.EventTypeValue
+	EventState bacnet.EventStateValue
+	Priority   uint8
+	//NotificationClass is optional
+	NotificationClass *uint32
+}
+
+// GetEnrollmentSummary is the GetEnrollmentSummary request. The
+// optional filters are ignored when nil. Enrollments is set with the
+// answer
+type GetEnrollmentSummary struct {
+	AckFilter               AckFilter
+	EventStateFilter        *EventStateFilter
+	EventTypeFilter         *bacnet.EventTypeValue
+	PriorityFilter          *PriorityFilter
+	NotificationClassFilter *uint32
+
+	Enrollments []EnrollmentSummary
+}
+
+func (s GetEnrollmentSummary) MarshalBinary() ([]byte, error) {
+	encoder := encoding.NewEncoder()
+	encoder.ContextUnsigned(0, uint32(s.AckFilter))
+	if s.EventStateFilter != nil {
+		encoder.ContextUnsigned(2, uint32(*s.EventStateFilter))
+	}
+	if s.EventTypeFilter != nil {
+		encoder.ContextUnsigned(3, uint32(*s.EventTypeFilter))
+	}
+	if s.PriorityFilter != nil {
+		if s.PriorityFilter.Min > s.PriorityFilter.Max {
+			return nil, fmt.Errorf("invalid priority filter: [%d, %d]", s.PriorityFilter.Min, s.PriorityFilter.Max)
+		}
+		encoder.OpeningTag(4)
+		encoder.ContextUnsigned(0, uint32(s.PriorityFilter.Min))
+		encoder.ContextUnsigned(1, uint32(s.PriorityFilter.Max))
+		encoder.ClosingTag(4)
+	}
+	if s.NotificationClassFilter != nil {
+		encoder.ContextUnsigned(5, *s.NotificationClassFilter)
+	}
+	return encoder.Bytes(), encoder.Error()
+}
+
+func (s *GetEnrollmentSummary) UnmarshalBinary(data []byte) error {
+	decoder := encoding.NewDecoder(data)
+	var values []bacnet.PropertyValue
+	for decoder.Len() > 0 && decoder.Error() == nil {
+		var v bacnet.PropertyValue
+		decoder.AppValue(&v)
+		values = append(values, v)
+	}
+	if err := decoder.Error(); err != nil {
+		return err
+	}
+	s.Enrollments = nil
+	for len(values) > 0 {
+		if len(values) < 4 {
+			return fmt.Errorf("truncated enrollment summary: %d values", len(values))
+		}
+		id, ok := values[0].Value.(bacnet.ObjectID)
+		eventType, ok1 := values[1].Value.(uint32)
+		eventState, ok2 := values[2].Value.(uint32)
+		priority, ok3 := values[3].Value.(uint32)
+		if !ok || !ok1 || !ok2 || !ok3 {
+			return fmt.Errorf("invalid enrollment summary: %v", values[:4])
+		}
+		e := EnrollmentSummary{
+			ObjectID:   id,
+			EventType:  bacnet.EventTypeValue(eventType),
+			EventState: bacnet.EventStateValue(eventState),
+			Priority:   uint8(priority),
+		}
+		values = values[4:]
+		//The notification class is optional, the next summary starts
+		//with an object identifier
+		if len(values) > 0 && values[0].Type == bacnet.TypeUnsignedInt {
+			class := values[0].Value.(uint32)
+			e.NotificationClass = &class
+			values = values[1:]
+		}
+		s.Enrollments = append(s.Enrollments, e)
+	}
+	return nil
+}
+
+// GetAlarmSummary returns the objects of the device in alarm
+func (c *Client) GetAlarmSummary(ctx context.Context, device bacnet.Device) ([]AlarmSummary, error) {
+	apdu, err := c.confirmedRequest(ctx, device, ServiceConfirmedGetAlarmSummary, &GetAlarmSummary{})
+	if err != nil {
+		return nil, err
+	}
+	s, ok := apdu.Payload.(*GetAlarmSummary)
+	if !ok {
+		return nil, errors.New("invalid answer")
+	}
+	return s.Alarms, nil
+}
+
+// GetEnrollmentSummary returns the objects of the device generating
+// events that match the filters of the request
+func (c *Client) GetEnrollmentSummary(ctx context.Context, device bacnet.Device, req GetEnrollmentSummary) ([]EnrollmentSummary, error) {
+	apdu, err := c.confirmedRequest(ctx, device, ServiceConfirmedGetEnrollmentSummary, &req)
+	if err != nil {
+		return nil, err
+	}
+	s, ok := apdu.Payload.(*GetEnrollmentSummary)
+	if !ok {
+		return nil, errors.New("invalid answer")
+	}
+	return s.Enrollments, nil
+}
+
+// DeviceAlarms are the objects of a device in alarm, or the error that
+// prevented getting them
+type DeviceAlarms struct {
+	Device bacnet.Device
+	Alarms []AlarmSummary
+	Err    error
+}
+
+// AlarmSummaries gets the alarm summaries of several devices
+// concurrently, in the order of the devices
+func (c *Client) AlarmSummaries(ctx context.Context, devices []bacnet.Device) []DeviceAlarms {
+	summaries := make([]DeviceAlarms, len(devices))
+	slots := make(chan struct{}, maxAlarmConcurrency)
+	var wg sync.WaitGroup
+	for i, d := range devices {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(i int, d bacnet.Device) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			alarms, err := c.GetAlarmSummary(ctx, d)
+			summaries[i] = DeviceAlarms{Device: d, Alarms: alarms, Err: err}
+		}(i, d)
+	}
+	wg.Wait()
+	return summaries
+}
@@ -0,0 +1,91 @@
+package bacip
+
+import (
+	"context"
+	"encoding/hex"
+	"net"
+	"testing"
+
+	"github.com/REQUEA/bacnet"
+	"github.com/REQUEA/bacnet/internal/encoding"
+	"github.com/matryer/is"
+)
+
+func TestGetEnrollmentSummaryEncoding(t *testing.T) {
+	is := is.New(t)
+	state := EventStateFilterActive
+	class := uint32(5)
+	b, err := GetEnrollmentSummary{
+		AckFilter:               AckFilterNotAcked,
+		EventStateFilter:        &state,
+		PriorityFilter:          &PriorityFilter{Min: 1, Max: 100},
+		NotificationClassFilter: &class,
+	}.MarshalBinary()
+	is.NoErr(err)
+	is.Equal(hex.EncodeToString(b), "090229044e090119644f5905")
+	_, err = GetEnrollmentSummary{PriorityFilter: &PriorityFilter{Min: 10, Max: 1}}.MarshalBinary()
+	is.True(err != nil)
+}
+
+func TestAlarmSummaries(t *testing.T) {
+	is := is.New(t)
+	av := bacnet.ObjectID{Type: bacnet.AnalogValue, Instance: 1}
+	bv := bacnet.ObjectID{Type: bacnet.BinaryValue, Instance: 2}
+	enum := func(v uint32) bacnet.PropertyValue {
+		return bacnet.PropertyValue{Type: bacnet.TypeEnumerated, Value: v}
+	}
+	m := newMemTransport()
+	m.respond = answerRequests(t, func(request *APDU) *APDU {
+		e := encoding.NewEncoder()
+		switch request.ServiceType {
+		case ServiceConfirmedGetAlarmSummary:
+			e.AppData(av)
+			e.AppValue(enum(uint32(bacnet.EventStateHighLimit)))
+			e.AppData(bacnet.BitString{false, true, true})
+		case ServiceConfirmedGetEnrollmentSummary:
+			e.AppData(av)
+			e.AppValue(enum(uint32(bacnet.EventTypeOutOfRange)))
+			e.AppValue(enum(uint32(bacnet.EventStateHighLimit)))
+			e.AppData(uint32(100))
+			e.AppData(uint32(5))
+			e.AppData(bv)
+			e.AppValue(enum(uint32(bacnet.EventTypeChangeOfState)))
+			e.AppValue(enum(uint32(bacnet.EventStateNormal)))
+			e.AppData(uint32(200))
+		}
+		is.NoErr(e.Error())
+		return &APDU{
+			DataType:    ComplexAck,
+			ServiceType: request.ServiceType,
+			InvokeID:    request.InvokeID,
+			Payload:     &DataPayload{Bytes: e.Bytes()},
+		}
+	})
+	c := newMemClient(t, m)
+	device := func(instance bacnet.ObjectInstance) bacnet.Device {
+		return bacnet.Device{
+			ID:   bacnet.ObjectID{Type: bacnet.BacnetDevice, Instance: instance},
+			Addr: *bacnet.AddressFromUDP(net.UDPAddr{IP: net.IPv4(10, 0, 2, byte(instance)).To4(), Port: DefaultUDPPort}),
+		}
+	}
+
+	summaries := c.AlarmSummaries(context.Background(), []bacnet.Device{device(3), device(4)})
+	is.Equal(len(summaries), 2)
+	for i, s := range summaries {
+		is.NoErr(s.Err)
+		is.Equal(s.Device.ID.Instance, bacnet.ObjectInstance(3+i))
+		is.Equal(s.Alarms, []AlarmSummary{{
+			ObjectID:         av,
+			AlarmState:       bacnet.EventStateHighLimit,
+			AckedTransitions: EventTransitions{ToFault: true, ToNormal: true},
+		}})
+	}
+
+	enrollments, err := c.GetEnrollmentSummary(context.Background(), device(3), GetEnrollmentSummary{})
+	is.NoErr(err)
+	class := uint32(5)
+	is.Equal(enrollments, []EnrollmentSummary{
+		{ObjectID: av, EventType: bacnet.EventTypeOutOfRange, EventState: bacnet.EventStateHighLimit, Priority: 100, NotificationClass: &class},
+		{ObjectID: bv, EventType: bacnet.EventTypeChangeOfState, EventState: bacnet.EventStateNormal, Priority: 200},
+	})
+}
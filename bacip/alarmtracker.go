@@ -0,0 +1,228 @@
+package bacip
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/REQUEA/bacnet"
+)
+
+// TrackedAlarm is an object of a device tracked by an AlarmTracker
+// because its event state isn't normal or some of its transitions
+// aren't acknowledged
+type TrackedAlarm struct {
+	Device           bacnet.ObjectID
+	ObjectID         bacnet.ObjectID
+	EventState       bacnet.EventStateValue
+	AckedTransitions EventTransitions
+}
+
+// pending tells if the alarm is still to be tracked
+func (a TrackedAlarm) pending() bool {
+	t := a.AckedTransitions
+	return a.EventState != bacnet.EventStateNormal || !t.ToOffnormal || !t.ToFault || !t.ToNormal
+}
+
+// DiscrepancyKind describes how the tracked alarms of a device
+// diverged from the device
+type DiscrepancyKind byte
+
+const (
+	//AlarmMissed is an alarm reported by the device but not tracked
+	AlarmMissed DiscrepancyKind = iota
+	//AlarmCleared is an alarm tracked but no longer reported by the
+	//device, whose return to normal was missed
+	AlarmCleared
+	//AlarmChanged is an alarm whose event state or acknowledged
+	//transitions differ
+	AlarmChanged
+)
+
+func (k DiscrepancyKind) String() string {
+	switch k {
+	case AlarmMissed:
+		return "missed"
+	case AlarmCleared:
+		return "cleared"
+	case AlarmChanged:
+		return "changed"
+	default:
+		return fmt.Sprintf("DiscrepancyKind(%d)", k)
+	}
+}
+
+// AlarmDiscrepancy is a difference between the tracked alarms and
+// the event information of a device, fixed by the reconciliation.
+// Tracked is nil for missed alarms and Reported is nil for cleared
+// alarms
+type AlarmDiscrepancy struct {
+	Kind     DiscrepancyKind
+	Tracked  *TrackedAlarm
+	Reported *TrackedAlarm
+}
+
+type alarmKey struct {
+	device bacnet.ObjectInstance
+	object bacnet.ObjectID
+}
+
+// AlarmTracker keeps the list of the alarms of the devices up to
+// date with the event notifications received. As notifications can be
+// lost, the list is reconciled with the event information of the
+// devices
+type AlarmTracker struct {
+	client *Client
+	mutex  sync.Mutex
+	alarms map[alarmKey]TrackedAlarm
+}
+
+// NewAlarmTracker returns a tracker without alarms. It's fed with the
+// notifications with c.OnEventNotification(tracker.Handle)
+func NewAlarmTracker(c *Client) *AlarmTracker {
+	return &AlarmTracker{client: c, alarms: map[alarmKey]TrackedAlarm{}}
+}
+
+// Handle updates the alarm of the object of an event notification
+func (t *AlarmTracker) Handle(n EventNotification, _ bacnet.Address) {
+	key := alarmKey{device: n.InitiatingDevice.Instance, object: n.EventObject}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	a, ok := t.alarms[key]
+	if !ok {
+		//An untracked object had all its transitions acknowledged
+		a = TrackedAlarm{
+			Device:           n.InitiatingDevice,
+			ObjectID:         n.EventObject,
+			EventState:       bacnet.EventStateNormal,
+			AckedTransitions: EventTransitions{ToOffnormal: true, ToFault: true, ToNormal: true},
+		}
+	}
+	transition := transitionOf(&a.AckedTransitions, n.ToState)
+	if n.NotifyType == bacnet.NotifyTypeAckNotification {
+		*transition = true
+	} else {
+		a.EventState = n.ToState
+		*transition = !n.AckRequired
+	}
+	if a.pending() {
+		t.alarms[key] = a
+	} else {
+		delete(t.alarms, key)
+	}
+}
+
+// transitionOf returns the flag of the transition to the state
+func transitionOf(t *EventTransitions, state bacnet.EventStateValue) *bool {
+	switch state {
+	case bacnet.EventStateNormal:
+		return &t.ToNormal
+	case bacnet.EventStateFault:
+		return &t.ToFault
+	default:
+		return &t.ToOffnormal
+	}
+}
+
+// Alarms returns the tracked alarms, sorted by device and object
+func (t *AlarmTracker) Alarms() []TrackedAlarm {
+	t.mutex.Lock()
+	alarms := make([]TrackedAlarm, 0, len(t.alarms))
+	for _, a := range t.alarms {
+		alarms = append(alarms, a)
+	}
+	t.mutex.Unlock()
+	sort.Slice(alarms, func(i, j int) bool {
+		if alarms[i].Device != alarms[j].Device {
+			return alarms[i].Device.Instance < alarms[j].Device.Instance
+		}
+		return lessObjectID(alarms[i].ObjectID, alarms[j].ObjectID)
+	})
+	return alarms
+}
+
+// Reconcile reads the event information of the device and replaces
+// its tracked alarms with it. It returns the differences found,
+// sorted by object
+func (t *AlarmTracker) Reconcile(ctx context.Context, device bacnet.Device) ([]AlarmDiscrepancy, error) {
+	summaries, err := t.client.GetEventInformation(ctx, device)
+	if err != nil {
+		return nil, err
+	}
+	reported := make(map[bacnet.ObjectID]TrackedAlarm, len(summaries))
+	for _, s := range summaries {
+		reported[s.ObjectID] = TrackedAlarm{
+			Device:           device.ID,
+			ObjectID:         s.ObjectID,
+			EventState:       s.EventState,
+			AckedTransitions: s.AckedTransitions,
+		}
+	}
+	var discrepancies []AlarmDiscrepancy
+	t.mutex.Lock()
+	for key, a := range t.alarms {
+		if key.device != device.ID.Instance {
+			continue
+		}
+		a := a
+		r, ok := reported[key.object]
+		switch {
+		case !ok:
+			discrepancies = append(discrepancies, AlarmDiscrepancy{Kind: AlarmCleared, Tracked: &a})
+			delete(t.alarms, key)
+		case r != a:
+			discrepancies = append(discrepancies, AlarmDiscrepancy{Kind: AlarmChanged, Tracked: &a, Reported: &r})
+		}
+	}
+	for id, r := range reported {
+		r := r
+		key := alarmKey{device: device.ID.Instance, object: id}
+		if _, ok := t.alarms[key]; !ok {
+			discrepancies = append(discrepancies, AlarmDiscrepancy{Kind: AlarmMissed, Reported: &r})
+		}
+		t.alarms[key] = r
+	}
+	t.mutex.Unlock()
+	sort.Slice(discrepancies, func(i, j int) bool {
+		return lessObjectID(discrepancies[i].objectID(), discrepancies[j].objectID())
+	})
+	return discrepancies, nil
+}
+
+func (d AlarmDiscrepancy) objectID() bacnet.ObjectID {
+	if d.Tracked != nil {
+		return d.Tracked.ObjectID
+	}
+	return d.Reported.ObjectID
+}
+
+// Run reconciles the tracked alarms of the devices every interval
+// until the context is done, calling f with the discrepancies found.
+// The devices that can't be reached are logged and retried at the
+// next interval
+func (t *AlarmTracker) Run(ctx context.Context, interval time.Duration, devices []bacnet.Device, f func(bacnet.Device, AlarmDiscrepancy)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		for _, device := range devices {
+			discrepancies, err := t.Reconcile(ctx, device)
+			if ctx.Err() != nil {
+				return
+			}
+			if err != nil {
+				t.client.logger.Error("reconcile alarms of ", device.ID, ": ", err)
+				continue
+			}
+			for _, d := range discrepancies {
+				f(device, d)
+			}
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package bacip
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/REQUEA/bacnet"
+	"github.com/REQUEA/bacnet/internal/encoding"
+	"github.com/matryer/is"
+)
+
+func TestAlarmTracker(t *testing.T) {
+	is := is.New(t)
+	device := bacnet.Device{
+		ID:   bacnet.ObjectID{Type: bacnet.BacnetDevice, Instance: 3},
+		Addr: *bacnet.AddressFromUDP(net.UDPAddr{IP: net.IPv4(10, 0, 2, 3).To4(), Port: DefaultUDPPort}),
+	}
+	av := func(instance bacnet.ObjectInstance) bacnet.ObjectID {
+		return bacnet.ObjectID{Type: bacnet.AnalogValue, Instance: instance}
+	}
+	notification := func(object bacnet.ObjectID, notifyType bacnet.NotifyTypeValue, to bacnet.EventStateValue) EventNotification {
+		return EventNotification{
+			InitiatingDevice: device.ID,
+			EventObject:      object,
+			NotifyType:       notifyType,
+			AckRequired:      true,
+			ToState:          to,
+		}
+	}
+	allAcked := EventTransitions{ToOffnormal: true, ToFault: true, ToNormal: true}
+	summaries := []EventSummary{
+		{ObjectID: av(1), EventState: bacnet.EventStateHighLimit, AckedTransitions: allAcked},
+		{ObjectID: av(3), EventState: bacnet.EventStateFault, AckedTransitions: EventTransitions{ToOffnormal: true, ToNormal: true}},
+	}
+	m := newMemTransport()
+	m.respond = answerRequests(t, func(request *APDU) *APDU {
+		e := encoding.NewEncoder()
+		e.OpeningTag(0)
+		for _, s := range summaries {
+			encodeEventSummary(&e, s)
+		}
+		e.ClosingTag(0)
+		e.ContextBool(1, false)
+		is.NoErr(e.Error())
+		return &APDU{
+			DataType:    ComplexAck,
+			ServiceType: request.ServiceType,
+			InvokeID:    request.InvokeID,
+			Payload:     &DataPayload{Bytes: e.Bytes()},
+		}
+	})
+	c := newMemClient(t, m)
+
+	tracker := NewAlarmTracker(c)
+	tracker.Handle(notification(av(1), bacnet.NotifyTypeAlarm, bacnet.EventStateHighLimit), device.Addr)
+	tracker.Handle(notification(av(2), bacnet.NotifyTypeAlarm, bacnet.EventStateOffnormal), device.Addr)
+	//An alarm back to normal and acknowledged isn't tracked anymore
+	tracker.Handle(notification(av(4), bacnet.NotifyTypeAlarm, bacnet.EventStateOffnormal), device.Addr)
+	tracker.Handle(notification(av(4), bacnet.NotifyTypeAckNotification, bacnet.EventStateOffnormal), device.Addr)
+	tracker.Handle(notification(av(4), bacnet.NotifyTypeEvent, bacnet.EventStateNormal), device.Addr)
+	tracker.Handle(notification(av(4), bacnet.NotifyTypeAckNotification, bacnet.EventStateNormal), device.Addr)
+	tracked := func(object bacnet.ObjectID, state bacnet.EventStateValue, acked EventTransitions) TrackedAlarm {
+		return TrackedAlarm{Device: device.ID, ObjectID: object, EventState: state, AckedTransitions: acked}
+	}
+	av1 := tracked(av(1), bacnet.EventStateHighLimit, EventTransitions{ToFault: true, ToNormal: true})
+	av2 := tracked(av(2), bacnet.EventStateOffnormal, EventTransitions{ToFault: true, ToNormal: true})
+	is.Equal(tracker.Alarms(), []TrackedAlarm{av1, av2})
+
+	discrepancies, err := tracker.Reconcile(context.Background(), device)
+	is.NoErr(err)
+	reported1 := tracked(av(1), bacnet.EventStateHighLimit, allAcked)
+	reported3 := tracked(av(3), bacnet.EventStateFault, summaries[1].AckedTransitions)
+	is.Equal(discrepancies, []AlarmDiscrepancy{
+		{Kind: AlarmChanged, Tracked: &av1, Reported: &reported1},
+		{Kind: AlarmCleared, Tracked: &av2},
+		{Kind: AlarmMissed, Reported: &reported3},
+	})
+	is.Equal(tracker.Alarms(), []TrackedAlarm{reported1, reported3})
+
+	discrepancies, err = tracker.Reconcile(context.Background(), device)
+	is.NoErr(err)
+	is.Equal(len(discrepancies), 0)
+}
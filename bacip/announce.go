@@ -0,0 +1,195 @@
+package bacip
+
+import (
+	"errors"
+	"math/rand"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/REQUEA/bacnet"
+)
+
+// Default throttling of the IAm broadcast in response to WhoIs. See
+// SetIAmThrottle
+const (
+	DefaultIAmInterval = time.Second
+	DefaultIAmJitter   = 100 * time.Millisecond
+)
+
+// SetLocalDevice gives the client the identity of a device. The IAm
+// of the device is broadcast, and sent again in response to the WhoIs
+// including its instance: directed WhoIs are answered to their
+// sender, the other ones with a throttled broadcast (see
+// SetIAmThrottle).
+func (c *Client) SetLocalDevice(iam Iam) error {
+	if iam.ObjectID.Type != bacnet.BacnetDevice {
+		return errors.New("local device identifier must be a device object")
+	}
+	c.localDevice.Store(&iam)
+	return c.IAm(nil)
+}
+
+// LocalDevice returns the identity set by SetLocalDevice
+func (c *Client) LocalDevice() (Iam, bool) {
+	iam, ok := c.localDevice.Load().(*Iam)
+	if !ok || iam == nil {
+		return Iam{}, false
+	}
+	return *iam, true
+}
+
+// IAm sends the IAm of the local device to dest, or broadcasts it if
+// dest is nil. The local device must have been set with
+// SetLocalDevice
+func (c *Client) IAm(dest *bacnet.Address) error {
+	iam, ok := c.LocalDevice()
+	if !ok {
+		return errors.New("no local device")
+	}
+	npdu := NPDU{
+		Version:  Version1,
+		Priority: Normal,
+		ADPU: &APDU{
+			DataType:    UnconfirmedServiceRequest,
+			ServiceType: ServiceUnconfirmedIAm,
+			Payload:     &iam,
+		},
+	}
+	var err error
+	if dest == nil {
+		_, err = c.broadcast(npdu)
+	} else {
+		npdu.Destination = dest
+		if dest.Net != 0 {
+			npdu.HopCount = 255
+		}
+		_, err = c.send(npdu)
+	}
+	return err
+}
+
+// answerWhoIs sends the IAm of the local device if the WhoIs includes
+// it
+func (c *Client) answerWhoIs(bvlc BVLC, src *net.UDPAddr) {
+	whoIs, ok := bvlc.NPDU.ADPU.Payload.(*WhoIs)
+	if !ok {
+		return
+	}
+	iam, ok := c.LocalDevice()
+	if !ok {
+		return
+	}
+	if src.IP.Equal(c.ipAddress) && src.Port == c.udpPort {
+		//Our own broadcast
+		return
+	}
+	low, high := whoIsRange(*whoIs)
+	instance := uint32(iam.ObjectID.Instance)
+	if instance < low || instance > high {
+		return
+	}
+	if bvlc.Function == BacFuncUnicast {
+		err := c.IAm(replyAddress(bvlc, src))
+		if err != nil {
+			c.logger.Error("answer WhoIs: ", err)
+		}
+		return
+	}
+	delay, ok := c.iamThrottle.schedule(time.Now())
+	if !ok {
+		//An answer is already scheduled
+		return
+	}
+	time.AfterFunc(delay, func() {
+		c.iamThrottle.sent(time.Now())
+		if !c.runFlag.Load() {
+			return
+		}
+		err := c.IAm(nil)
+		if err != nil {
+			c.logger.Error("answer WhoIs: ", err)
+		}
+	})
+}
+
+// SetIAmThrottle sets how the local device answers the broadcast
+// WhoIs: the IAm broadcasts are spaced by at least interval, and
+// delayed by a random duration up to jitter so that the devices
+// answering the same WhoIs don't all answer at once. The WhoIs
+// received while an answer is scheduled share it, so a WhoIs flood
+// doesn't make the local device flood the network too. Directed WhoIs
+// are always answered immediately
+func (c *Client) SetIAmThrottle(interval, jitter time.Duration) {
+	c.iamThrottle.Lock()
+	defer c.iamThrottle.Unlock()
+	c.iamThrottle.interval = interval
+	c.iamThrottle.jitter = jitter
+}
+
+// iamThrottle schedules the IAm broadcast in response to WhoIs
+type iamThrottle struct {
+	sync.Mutex
+	interval time.Duration
+	jitter   time.Duration
+	rand     *rand.Rand
+	//pending is true while an answer is scheduled
+	pending bool
+	last    time.Time
+}
+
+func newIAmThrottle() *iamThrottle {
+	return &iamThrottle{
+		interval: DefaultIAmInterval,
+		jitter:   DefaultIAmJitter,
+		rand:     rand.New(rand.NewSource(time.Now().UnixNano())), //nolint: gosec
+	}
+}
+
+// schedule returns how long to wait before answering a WhoIs received
+// at now, or false if an answer is already scheduled
+func (t *iamThrottle) schedule(now time.Time) (time.Duration, bool) {
+	t.Lock()
+	defer t.Unlock()
+	if t.pending {
+		return 0, false
+	}
+	t.pending = true
+	delay := t.last.Add(t.interval).Sub(now)
+	if delay < 0 {
+		delay = 0
+	}
+	if t.jitter > 0 {
+		delay += time.Duration(t.rand.Int63n(int64(t.jitter)))
+	}
+	return delay, true
+}
+
+// sent records that the scheduled answer was sent
+func (t *iamThrottle) sent(now time.Time) {
+	t.Lock()
+	defer t.Unlock()
+	t.pending = false
+	t.last = now
+}
+
+// SourceAddress returns the BACnet address of the sender of a message
+// received from src. The messages of the devices on a remote network
+// are received from their router: the address is then the network
+// number and MAC address of the device (SNET and SADR) behind the
+// router
+func SourceAddress(bvlc BVLC, src net.UDPAddr) bacnet.Address {
+	addr := bacnet.AddressFromUDP(src)
+	if source := bvlc.NPDU.Source; source != nil && source.Net != 0 {
+		addr.Net = source.Net
+		addr.Adr = source.Adr
+	}
+	return *addr
+}
+
+// replyAddress returns the address of the sender of a message, behind
+// its router if it's on a remote network
+func replyAddress(bvlc BVLC, src *net.UDPAddr) *bacnet.Address {
+	addr := SourceAddress(bvlc, *src)
+	return &addr
+}
@@ -0,0 +1,126 @@
+package bacip
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/REQUEA/bacnet"
+
+	"github.com/matryer/is"
+)
+
+func TestIAm(t *testing.T) {
+	is := is.New(t)
+	m := newMemTransport()
+	c := newMemClient(t, m)
+	is.True(c.IAm(nil) != nil) //no local device yet
+
+	local := Iam{
+		ObjectID:            bacnet.ObjectID{Type: bacnet.BacnetDevice, Instance: 42},
+		MaxApduLength:       1476,
+		SegmentationSupport: bacnet.SegmentationSupportNone,
+		VendorID:            5,
+	}
+	is.NoErr(c.SetLocalDevice(local))
+	is.Equal(m.count(), 1)
+	is.Equal(m.to[0].IP.String(), "10.0.2.255")
+
+	whoIs := func(function Function, low, high uint32) []byte {
+		b, err := BVLC{
+			Type:     TypeBacnetIP,
+			Function: function,
+			NPDU: NPDU{
+				Version: Version1,
+				ADPU: &APDU{
+					DataType:    UnconfirmedServiceRequest,
+					ServiceType: ServiceUnconfirmedWhoIs,
+					Payload:     &WhoIs{Low: &low, High: &high},
+				},
+			},
+		}.MarshalBinary()
+		is.NoErr(err)
+		return b
+	}
+	sender := &net.UDPAddr{IP: net.IPv4(10, 0, 2, 9), Port: DefaultUDPPort}
+	wait := func(n int) {
+		deadline := time.Now().Add(time.Second)
+		for m.count() < n && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	//Directed WhoIs are answered to the sender
+	m.in <- datagram{data: whoIs(BacFuncUnicast, 40, 50), addr: sender}
+	wait(2)
+	is.Equal(m.count(), 2)
+	m.Lock()
+	to, answer := m.to[1], m.written[1]
+	m.Unlock()
+	is.True(to.IP.Equal(sender.IP))
+	var bvlc BVLC
+	is.NoErr(bvlc.UnmarshalBinary(answer))
+	is.Equal(*bvlc.NPDU.ADPU.Payload.(*Iam), local)
+
+	//WhoIs for other devices are ignored, global ones answered with a
+	//broadcast
+	m.in <- datagram{data: whoIs(BacFuncBroadcast, 1, 10), addr: sender}
+	m.in <- datagram{data: whoIs(BacFuncBroadcast, 42, 42), addr: sender}
+	wait(3)
+	time.Sleep(10 * time.Millisecond)
+	is.Equal(m.count(), 3)
+	m.Lock()
+	to = m.to[2]
+	m.Unlock()
+	is.Equal(to.IP.String(), "10.0.2.255")
+}
+
+func TestIAmThrottle(t *testing.T) {
+	is := is.New(t)
+	m := newMemTransport()
+	c := newMemClient(t, m)
+	c.SetIAmThrottle(100*time.Millisecond, 20*time.Millisecond)
+	is.NoErr(c.SetLocalDevice(Iam{ObjectID: bacnet.ObjectID{Type: bacnet.BacnetDevice, Instance: 42}}))
+	is.Equal(m.count(), 1)
+
+	whoIs := func(function Function) []byte {
+		b, err := BVLC{
+			Type:     TypeBacnetIP,
+			Function: function,
+			NPDU: NPDU{
+				Version: Version1,
+				ADPU: &APDU{
+					DataType:    UnconfirmedServiceRequest,
+					ServiceType: ServiceUnconfirmedWhoIs,
+					Payload:     &WhoIs{},
+				},
+			},
+		}.MarshalBinary()
+		is.NoErr(err)
+		return b
+	}
+	sender := &net.UDPAddr{IP: net.IPv4(10, 0, 2, 9), Port: DefaultUDPPort}
+
+	//A flood of broadcast WhoIs is answered once
+	for i := 0; i < 10; i++ {
+		m.in <- datagram{data: whoIs(BacFuncBroadcast), addr: sender}
+	}
+	//Directed WhoIs are answered right away
+	m.in <- datagram{data: whoIs(BacFuncUnicast), addr: sender}
+	is.True(waitFor(func() bool { return m.count() == 3 }))
+	time.Sleep(50 * time.Millisecond)
+	is.Equal(m.count(), 3)
+	m.Lock()
+	answers := map[string]bool{m.to[1].IP.String(): true, m.to[2].IP.String(): true}
+	m.Unlock()
+	is.Equal(answers, map[string]bool{sender.IP.String(): true, "10.0.2.255": true})
+
+	//The next answer waits for the interval
+	start := time.Now()
+	m.in <- datagram{data: whoIs(BacFuncBroadcast), addr: sender}
+	is.True(waitFor(func() bool { return m.count() == 4 }))
+	is.True(time.Since(start) >= 40*time.Millisecond)
+	m.Lock()
+	is.Equal(m.to[3].IP.String(), "10.0.2.255")
+	m.Unlock()
+}
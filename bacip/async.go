@@ -0,0 +1,283 @@
+package bacip
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/REQUEA/bacnet"
+)
+
+// Future is a confirmed request in progress, started by Async
+type Future struct {
+	c       *Client
+	ctx     context.Context
+	cancel  context.CancelFunc
+	request *request
+	writes  []WriteAccessSpecification
+
+	mutex     sync.Mutex
+	attempt   int
+	sent      time.Time
+	timer     *time.Timer
+	completed bool
+	//acks receives the answers while the segments of the request are
+	//sent
+	acks chan APDU
+
+	done chan struct{}
+	apdu APDU
+	err  error
+}
+
+// Async sends a confirmed request to the device without waiting for
+// its answer, which is then available from the returned future. The
+// request is made like by the blocking services: paced, retried and
+// its error answers returned as ApduError. Writes are checked against
+// the write policy and the datatypes of the properties, and the
+// properties written are removed from the read cache once done.
+//
+// Async only blocks until the request is sent, which includes waiting
+// for the pacing of the device and for the acknowledgement of the
+// segments of a segmented request. No goroutine waits for the answer:
+// the future is completed by the client when it receives it, or when
+// the request times out. The futures of several requests can be waited
+// for in a single select on their Done channels.
+//
+// The request is aborted when ctx is cancelled or its deadline passes,
+// even without timeout. Cancel aborts the request at once
+func (c *Client) Async(ctx context.Context, device bacnet.Device, service ServiceType, payload Payload) *Future {
+	ctx, cancel := context.WithCancel(ctx)
+	f := &Future{c: c, ctx: ctx, cancel: cancel, done: make(chan struct{})}
+	payload, writes, err := c.prepareWrite(device.ID, payload)
+	if err != nil {
+		f.fail(err)
+		return f
+	}
+	r, err := c.newRequest(ctx, device, service, payload)
+	if err != nil {
+		f.fail(err)
+		return f
+	}
+	f.request = r
+	f.writes = writes
+	c.transactions.setFuture(r.invokeID, f)
+	c.transactions.describe(r.invokeID, service, r.device.Addr)
+	//ctx is cancelled by complete once the request is done
+	go func() {
+		<-ctx.Done()
+		f.end()
+	}()
+	f.send(1)
+	return f
+}
+
+// Done returns a channel closed when the answer is received, or the
+// request failed
+func (f *Future) Done() <-chan struct{} {
+	return f.done
+}
+
+// Result waits for the request to complete and returns the answer of
+// the device
+func (f *Future) Result() (APDU, error) {
+	<-f.done
+	return f.apdu, f.err
+}
+
+// Cancel aborts the request if it's still in progress. Result then
+// returns context.Canceled
+func (f *Future) Cancel() {
+	f.cancel()
+	f.complete(APDU{}, context.Canceled, TransactionAborted)
+}
+
+// fail completes the future of a request which couldn't be made
+func (f *Future) fail(err error) {
+	f.completed = true
+	f.err = err
+	f.cancel()
+	close(f.done)
+}
+
+// send makes an attempt of the request
+func (f *Future) send(attempt int) {
+	c, r := f.c, f.request
+	f.mutex.Lock()
+	if f.completed {
+		f.mutex.Unlock()
+		return
+	}
+	f.attempt = attempt
+	f.sent = time.Now()
+	var acks chan APDU
+	if r.segments != nil {
+		acks = make(chan APDU, maxWindowSize+1)
+		f.acks = acks
+	}
+	f.mutex.Unlock()
+	var err error
+	var early *APDU
+	if r.segments != nil {
+		early, err = c.sendSegments(f.ctx, r.npdu, r.segments, acks, r.pc)
+		f.mutex.Lock()
+		f.acks = nil
+		f.mutex.Unlock()
+		//The answer may have been received after the last segment was
+		//acknowledged
+	drain:
+		for early == nil {
+			select {
+			case apdu := <-acks:
+				if apdu.DataType != SegmentAck {
+					early = &apdu
+				}
+			default:
+				break drain
+			}
+		}
+	} else {
+		_, err = c.send(r.npdu)
+	}
+	if err != nil {
+		f.complete(APDU{}, err, sendFailure(f.ctx, err))
+		return
+	}
+	f.mutex.Lock()
+	completed := f.completed
+	f.mutex.Unlock()
+	if completed {
+		//Cancelled while it was sent
+		return
+	}
+	if attempt == 1 {
+		f.emit(TransactionSent, nil)
+	} else {
+		f.emit(TransactionRetried, nil)
+	}
+	if early != nil {
+		//The device answered before the last segment
+		f.answer(*early)
+		return
+	}
+	f.wait(r.pc.timeout())
+}
+
+// wait starts the timeout of the current attempt. Without timeout nor
+// deadline, the answer is waited for until ctx is done
+func (f *Future) wait(d time.Duration) {
+	if deadline, ok := f.ctx.Deadline(); ok {
+		if left := time.Until(deadline); d <= 0 || left < d {
+			d = left
+		}
+		if d <= 0 {
+			d = time.Nanosecond
+		}
+	}
+	if d <= 0 {
+		return
+	}
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if !f.completed {
+		f.timer = time.AfterFunc(d, f.expire)
+	}
+}
+
+// end completes the future when its context is done
+func (f *Future) end() {
+	err := f.ctx.Err()
+	if errors.Is(err, context.DeadlineExceeded) {
+		f.complete(APDU{}, err, TransactionTimedOut)
+	} else {
+		f.complete(APDU{}, err, TransactionAborted)
+	}
+}
+
+// expire is called when the timeout of an attempt elapsed
+func (f *Future) expire() {
+	if f.ctx.Err() != nil {
+		f.end()
+		return
+	}
+	r := f.request
+	if d := r.pc.timeout(); f.c.segments.receiving(r.invokeID, d) {
+		//The answer is segmented, its next segments are still coming
+		f.wait(d)
+		return
+	}
+	f.mutex.Lock()
+	attempt := f.attempt
+	f.mutex.Unlock()
+	if attempt <= r.pc.profile.Retries {
+		f.send(attempt + 1)
+		return
+	}
+	f.complete(APDU{}, context.DeadlineExceeded, TransactionTimedOut)
+}
+
+// deliver handles an answer of the device, received by the client
+func (f *Future) deliver(apdu APDU) {
+	f.mutex.Lock()
+	if f.acks != nil {
+		//The segments of the request are being sent
+		select {
+		case f.acks <- apdu:
+		default:
+		}
+		f.mutex.Unlock()
+		return
+	}
+	f.mutex.Unlock()
+	f.answer(apdu)
+}
+
+// answer completes the future with the answer of the device
+func (f *Future) answer(apdu APDU) {
+	if apdu.DataType == SegmentAck {
+		//Late ack of the segments of the request
+		return
+	}
+	f.mutex.Lock()
+	first, sent := f.attempt == 1, f.sent
+	f.mutex.Unlock()
+	if first && f.request.segments == nil {
+		f.request.pc.sample(time.Since(sent))
+	}
+	typ, err := answerError(apdu)
+	f.complete(apdu, err, typ)
+}
+
+// complete ends the request, unless it's already done
+func (f *Future) complete(apdu APDU, err error, typ TransactionEventType) {
+	f.mutex.Lock()
+	if f.completed {
+		f.mutex.Unlock()
+		return
+	}
+	f.completed = true
+	if f.timer != nil {
+		f.timer.Stop()
+	}
+	f.mutex.Unlock()
+	c, r := f.c, f.request
+	c.transactions.StopTransaction(r.invokeID)
+	c.segments.remove(r.invokeID)
+	r.free(c)
+	f.cancel()
+	//Even a failed write may have changed the value
+	c.invalidateWrites(r.device.ID, f.writes)
+	f.emit(typ, err)
+	f.apdu, f.err = apdu, err
+	close(f.done)
+}
+
+// emit emits the event of the current attempt
+func (f *Future) emit(typ TransactionEventType, err error) {
+	f.mutex.Lock()
+	ev, sent := f.request.ev, f.sent
+	ev.Attempt = f.attempt
+	f.mutex.Unlock()
+	f.c.emit(ev, typ, f.request.start, sent, err)
+}
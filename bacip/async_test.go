@@ -0,0 +1,142 @@
+package bacip
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/REQUEA/bacnet"
+	"github.com/matryer/is"
+)
+
+func TestAsync(t *testing.T) {
+	is := is.New(t)
+	ai1 := bacnet.ObjectID{Type: bacnet.AnalogInput, Instance: 1}
+	d1 := newMemDevice(10)
+	d1.set(ai1, bacnet.PresentValue, float32(21.5))
+	d2 := newMemDevice(20)
+	d2.set(ai1, bacnet.PresentValue, float32(19))
+	d2.drop = -1
+	m := newMemTransport()
+	m.respond = serveDevices(t, d1, d2)
+	c := newMemClient(t, m)
+	read := &ReadProperty{ObjectID: ai1, Property: bacnet.PropertyIdentifier{Type: bacnet.PresentValue}}
+
+	f1 := c.Async(context.Background(), d1.device(), ServiceConfirmedReadProperty, read)
+	f2 := c.Async(context.Background(), d2.device(), ServiceConfirmedReadProperty, read)
+	select {
+	case <-f1.Done():
+	case <-f2.Done():
+		t.Fatal("dropped request answered")
+	case <-time.After(time.Second):
+		t.Fatal("request not answered")
+	}
+	apdu, err := f1.Result()
+	is.NoErr(err)
+	is.Equal(apdu.Payload.(*ReadProperty).Data, float32(21.5))
+
+	f2.Cancel()
+	_, err = f2.Result()
+	is.True(errors.Is(err, context.Canceled))
+
+	c.SetWritePolicy(&WritePolicy{ReadOnly: true})
+	f3 := c.Async(context.Background(), d1.device(), ServiceConfirmedWriteProperty, &WriteProperty{
+		ObjectID:      ai1,
+		Property:      bacnet.PropertyIdentifier{Type: bacnet.OutOfService},
+		PropertyValue: bacnet.PropertyValue{Value: true},
+	})
+	_, err = f3.Result()
+	is.True(errors.Is(err, ErrWriteDenied))
+
+	//The properties written are read again from the device
+	c.SetWritePolicy(nil)
+	c.SetReadCache(NewReadCache(time.Minute, bacnet.PresentValue))
+	v, err := c.ReadProperty(context.Background(), d1.device(), *read)
+	is.NoErr(err)
+	is.Equal(v, float32(21.5))
+	f4 := c.Async(context.Background(), d1.device(), ServiceConfirmedWriteProperty, &WriteProperty{
+		ObjectID:      ai1,
+		Property:      bacnet.PropertyIdentifier{Type: bacnet.PresentValue},
+		PropertyValue: bacnet.PropertyValue{Value: 22.5},
+	})
+	_, err = f4.Result()
+	is.NoErr(err)
+	v, _ = d1.written(ai1, bacnet.PresentValue)
+	is.Equal(v, float32(22.5))
+	v, err = c.ReadProperty(context.Background(), d1.device(), *read)
+	is.NoErr(err)
+	is.Equal(v, float32(22.5))
+}
+
+func TestAsyncTimeout(t *testing.T) {
+	is := is.New(t)
+	ai1 := bacnet.ObjectID{Type: bacnet.AnalogInput, Instance: 1}
+	d := newMemDevice(10)
+	d.set(ai1, bacnet.PresentValue, float32(21.5))
+	d.drop = 2
+	m := newMemTransport()
+	m.respond = serveDevices(t, d)
+	c := newMemClient(t, m)
+	c.SetDeviceProfile(d.id, DeviceProfile{Timeout: 20 * time.Millisecond, Retries: 2})
+	var events []TransactionEventType
+	var mutex sync.Mutex
+	c.OnTransaction(func(ev TransactionEvent) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		events = append(events, ev.Type)
+	})
+	read := &ReadProperty{ObjectID: ai1, Property: bacnet.PropertyIdentifier{Type: bacnet.PresentValue}}
+
+	//The third attempt is answered
+	apdu, err := c.Async(context.Background(), d.device(), ServiceConfirmedReadProperty, read).Result()
+	is.NoErr(err)
+	is.Equal(apdu.Payload.(*ReadProperty).Data, float32(21.5))
+	is.Equal(len(d.received()), 3)
+	mutex.Lock()
+	is.Equal(events, []TransactionEventType{TransactionSent, TransactionRetried, TransactionRetried, TransactionAcked})
+	mutex.Unlock()
+
+	d.Lock()
+	d.drop = -1
+	d.Unlock()
+	_, err = c.Async(context.Background(), d.device(), ServiceConfirmedReadProperty, read).Result()
+	is.True(errors.Is(err, context.DeadlineExceeded))
+
+	//The deadline of the context bounds the attempts
+	c.SetDeviceProfile(d.id, DeviceProfile{Timeout: time.Hour})
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = c.Async(ctx, d.device(), ServiceConfirmedReadProperty, read).Result()
+	is.True(errors.Is(err, context.DeadlineExceeded))
+}
+
+func TestAsyncContext(t *testing.T) {
+	is := is.New(t)
+	device := bacnet.Device{
+		ID:   bacnet.ObjectID{Type: bacnet.BacnetDevice, Instance: 10},
+		Addr: *bacnet.AddressFromUDP(net.UDPAddr{IP: net.IPv4(10, 0, 2, 3).To4(), Port: DefaultUDPPort}),
+	}
+	//The device never answers, and there is no timeout
+	c := newMemClient(t, newMemTransport())
+	c.SetDeviceProfile(device.ID, DeviceProfile{})
+	read := &ReadProperty{ObjectID: device.ID, Property: bacnet.PropertyIdentifier{Type: bacnet.ObjectName}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	f := c.Async(ctx, device, ServiceConfirmedReadProperty, read)
+	cancel()
+	select {
+	case <-f.Done():
+	case <-time.After(time.Second):
+		t.Fatal("cancellation not noticed")
+	}
+	_, err := f.Result()
+	is.True(errors.Is(err, context.Canceled))
+
+	ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = c.Async(ctx, device, ServiceConfirmedReadProperty, read).Result()
+	is.True(errors.Is(err, context.DeadlineExceeded))
+}
@@ -0,0 +1,261 @@
+package bacip
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/REQUEA/bacnet"
+	"github.com/REQUEA/bacnet/internal/encoding"
+)
+
+// ReinitializedState is the state a device is asked to enter by a
+// ReinitializeDevice request
+type ReinitializedState uint32
+
+//go:generate stringer -type=ReinitializedState
+const (
+	ColdStart       ReinitializedState = 0
+	WarmStart       ReinitializedState = 1
+	StartBackup     ReinitializedState = 2
+	EndBackup       ReinitializedState = 3
+	StartRestore    ReinitializedState = 4
+	EndRestore      ReinitializedState = 5
+	AbortRestore    ReinitializedState = 6
+	ActivateChanges ReinitializedState = 7
+)
+
+// ReinitializeDevice asks a device to restart or to enter or leave
+// the backup and restore procedures
+type ReinitializeDevice struct {
+	State ReinitializedState
+	//Password is sent if not empty. It's at most 20 characters long
+	Password string
+}
+
+func (r ReinitializeDevice) MarshalBinary() ([]byte, error) {
+	if len(r.Password) > 20 {
+		return nil, errors.New("password longer than 20 characters")
+	}
+	encoder := encoding.NewEncoder()
+	encoder.ContextUnsigned(0, uint32(r.State))
+	if r.Password != "" {
+		encoder.ContextString(1, r.Password)
+	}
+	return encoder.Bytes(), encoder.Error()
+}
+
+func (r *ReinitializeDevice) UnmarshalBinary(data []byte) error {
+	decoder := encoding.NewDecoder(data)
+	var val uint32
+	decoder.ContextValue(0, &val)
+	r.State = ReinitializedState(val)
+	r.Password = ""
+	if decoder.IsContextTag(1) {
+		decoder.ContextString(1, &r.Password)
+	}
+	return decoder.Error()
+}
+
+// ReinitializeDevice sends a ReinitializeDevice request to the device
+func (c *Client) ReinitializeDevice(ctx context.Context, device bacnet.Device, req ReinitializeDevice) error {
+	err := c.checkRequest(device.ID, &req)
+	if err != nil {
+		return err
+	}
+	apdu, err := c.confirmedRequest(ctx, device, ServiceConfirmedReinitializeDevice, &req)
+	if err != nil {
+		return err
+	}
+	if apdu.DataType == SimpleAck {
+		return nil
+	}
+	return errors.New("invalid answer")
+}
+
+// BackupFile is the content of a configuration file of a device
+type BackupFile struct {
+	ObjectID bacnet.ObjectID
+	Data     []byte
+}
+
+// DeviceBackup is the backup of the configuration files of a device
+type DeviceBackup struct {
+	Device bacnet.ObjectID
+	Files  []BackupFile
+}
+
+// BackupOptions configures BackupDevice and RestoreDevice
+type BackupOptions struct {
+	//Password is sent with the ReinitializeDevice requests
+	Password string
+	//Progress, if set, is called after each file transferred with
+	//the number of files transferred and the number of files
+	Progress func(file bacnet.ObjectID, done, total int)
+}
+
+// cleanupTimeout bounds the requests bringing a device back to its
+// normal operation after a failed procedure
+const cleanupTimeout = 10 * time.Second
+
+// cleanupContext returns the context of a request bringing a device
+// back to its normal operation. It isn't derived from the context of
+// the procedure, whose cancellation is the usual cause of the failure
+func cleanupContext() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), cleanupTimeout)
+}
+
+// BackupDevice backs the configuration of the device up, following the
+// standard procedure: the device is asked to prepare the backup, its
+// configuration files are read, then the backup is ended. The backup
+// is ended even if reading a file failed or ctx is done, so the device
+// doesn't stay in backup mode
+func (c *Client) BackupDevice(ctx context.Context, device bacnet.Device, opts BackupOptions) (DeviceBackup, error) {
+	backup := DeviceBackup{Device: device.ID}
+	err := c.ReinitializeDevice(ctx, device, ReinitializeDevice{State: StartBackup, Password: opts.Password})
+	if err != nil {
+		return backup, fmt.Errorf("start backup: %w", err)
+	}
+	err = c.backupFiles(ctx, device, opts, &backup)
+	cleanupCtx, cancel := cleanupContext()
+	defer cancel()
+	endErr := c.ReinitializeDevice(cleanupCtx, device, ReinitializeDevice{State: EndBackup, Password: opts.Password})
+	if err != nil {
+		return backup, err
+	}
+	if endErr != nil {
+		return backup, fmt.Errorf("end backup: %w", endErr)
+	}
+	return backup, nil
+}
+
+func (c *Client) backupFiles(ctx context.Context, device bacnet.Device, opts BackupOptions, backup *DeviceBackup) error {
+	err := c.waitPreparation(ctx, device, bacnet.BackupPreparationTime)
+	if err != nil {
+		return err
+	}
+	files, err := c.configurationFiles(ctx, device)
+	if err != nil {
+		return err
+	}
+	for i, file := range files {
+		var buf bytes.Buffer
+		_, err := c.ReadFile(ctx, device, file, &buf)
+		if err != nil {
+			return fmt.Errorf("backup %v: %w", file, err)
+		}
+		backup.Files = append(backup.Files, BackupFile{ObjectID: file, Data: buf.Bytes()})
+		if opts.Progress != nil {
+			opts.Progress(file, i+1, len(files))
+		}
+	}
+	return nil
+}
+
+// RestoreDevice restores a backup made by BackupDevice, following the
+// standard procedure: the device is asked to prepare the restore, the
+// configuration files are written, then the restore is ended. The
+// restore is aborted if writing a file failed, even if ctx is done
+func (c *Client) RestoreDevice(ctx context.Context, device bacnet.Device, backup DeviceBackup, opts BackupOptions) error {
+	if backup.Device != device.ID {
+		return fmt.Errorf("backup of %v can't be restored to %v", backup.Device, device.ID)
+	}
+	err := c.ReinitializeDevice(ctx, device, ReinitializeDevice{State: StartRestore, Password: opts.Password})
+	if err != nil {
+		return fmt.Errorf("start restore: %w", err)
+	}
+	err = c.restoreFiles(ctx, device, backup, opts)
+	if err != nil {
+		cleanupCtx, cancel := cleanupContext()
+		defer cancel()
+		abortErr := c.ReinitializeDevice(cleanupCtx, device, ReinitializeDevice{State: AbortRestore, Password: opts.Password})
+		if abortErr != nil {
+			c.logger.Error("abort restore: ", abortErr)
+		}
+		return err
+	}
+	err = c.ReinitializeDevice(ctx, device, ReinitializeDevice{State: EndRestore, Password: opts.Password})
+	if err != nil {
+		return fmt.Errorf("end restore: %w", err)
+	}
+	return nil
+}
+
+func (c *Client) restoreFiles(ctx context.Context, device bacnet.Device, backup DeviceBackup, opts BackupOptions) error {
+	err := c.waitPreparation(ctx, device, bacnet.RestorePreparationTime)
+	if err != nil {
+		return err
+	}
+	for i, file := range backup.Files {
+		//The files are replaced: a larger file would keep its end
+		err := c.WriteProperty(ctx, device, WriteProperty{
+			ObjectID:      file.ObjectID,
+			Property:      bacnet.PropertyIdentifier{Type: bacnet.FileSize},
+			PropertyValue: bacnet.PropertyValue{Value: uint32(0)},
+		})
+		if err != nil && !isWriteAccessDenied(err) {
+			return fmt.Errorf("restore %v: truncate: %w", file.ObjectID, err)
+		}
+		_, err = c.WriteFile(ctx, device, file.ObjectID, bytes.NewReader(file.Data))
+		if err != nil {
+			return fmt.Errorf("restore %v: %w", file.ObjectID, err)
+		}
+		if opts.Progress != nil {
+			opts.Progress(file.ObjectID, i+1, len(backup.Files))
+		}
+	}
+	return nil
+}
+
+// waitPreparation waits for the preparation time announced by the
+// device, if any
+func (c *Client) waitPreparation(ctx context.Context, device bacnet.Device, prop bacnet.PropertyType) error {
+	d, err := c.ReadProperty(ctx, device, ReadProperty{
+		ObjectID: device.ID,
+		Property: bacnet.PropertyIdentifier{Type: prop},
+	})
+	if err != nil {
+		if isUnknownProperty(err) {
+			return nil
+		}
+		return fmt.Errorf("read %v: %w", prop, err)
+	}
+	seconds, ok := d.(uint32)
+	if !ok || seconds == 0 {
+		return nil
+	}
+	timer := time.NewTimer(time.Duration(seconds) * time.Second)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// configurationFiles reads the files to back up from the device
+func (c *Client) configurationFiles(ctx context.Context, device bacnet.Device) ([]bacnet.ObjectID, error) {
+	d, err := c.ReadProperty(ctx, device, ReadProperty{
+		ObjectID: device.ID,
+		Property: bacnet.PropertyIdentifier{Type: bacnet.ConfigurationFiles},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("read configuration files: %w", err)
+	}
+	values, ok := d.([]interface{})
+	if !ok {
+		values = []interface{}{d}
+	}
+	files := make([]bacnet.ObjectID, 0, len(values))
+	for _, v := range values {
+		id, ok := v.(bacnet.ObjectID)
+		if !ok || id.Type != bacnet.File {
+			return nil, fmt.Errorf("invalid configuration file %v", v)
+		}
+		files = append(files, id)
+	}
+	return files, nil
+}
@@ -0,0 +1,214 @@
+package bacip
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/REQUEA/bacnet"
+	"github.com/REQUEA/bacnet/internal/encoding"
+	"github.com/matryer/is"
+)
+
+func TestReinitializeDeviceEncoding(t *testing.T) {
+	is := is.New(t)
+	b, err := ReinitializeDevice{State: StartBackup, Password: "abc"}.MarshalBinary()
+	is.NoErr(err)
+	is.Equal(hex.EncodeToString(b), "09021c00616263")
+	var r ReinitializeDevice
+	is.NoErr(r.UnmarshalBinary(b))
+	is.Equal(r, ReinitializeDevice{State: StartBackup, Password: "abc"})
+	_, err = ReinitializeDevice{Password: "a password too long for bacnet"}.MarshalBinary()
+	is.True(err != nil)
+}
+
+// backupDevice is a fake device implementing the services used by the
+// backup and restore procedures
+type backupDevice struct {
+	is     *is.I
+	id     bacnet.ObjectID
+	files  map[bacnet.ObjectID][]byte
+	states []ReinitializedState
+	//failWrite makes the writes of files fail
+	failWrite bool
+}
+
+func (d *backupDevice) answer(request *APDU) *APDU {
+	is := d.is
+	data := request.Payload.(*DataPayload).Bytes
+	answer := &APDU{DataType: SimpleAck, ServiceType: request.ServiceType, InvokeID: request.InvokeID, Payload: &DataPayload{}}
+	dec := encoding.NewDecoder(data)
+	e := encoding.NewEncoder()
+	switch request.ServiceType {
+	case ServiceConfirmedReinitializeDevice:
+		var r ReinitializeDevice
+		is.NoErr(r.UnmarshalBinary(data))
+		is.Equal(r.Password, "secret")
+		d.states = append(d.states, r.State)
+	case ServiceConfirmedReadProperty:
+		var rp ReadProperty
+		dec.ContextObjectID(0, &rp.ObjectID)
+		var prop uint32
+		dec.ContextValue(1, &prop)
+		if bacnet.PropertyType(prop) != bacnet.ConfigurationFiles {
+			answer.DataType = Error
+			answer.Payload = &ApduError{Class: bacnet.PropertyError, Code: bacnet.UnknownProperty}
+			break
+		}
+		value := encoding.NewEncoder()
+		for i := 1; i <= len(d.files); i++ {
+			value.AppData(bacnet.ObjectID{Type: bacnet.File, Instance: bacnet.ObjectInstance(i)})
+		}
+		e.ContextObjectID(0, rp.ObjectID)
+		e.ContextUnsigned(1, prop)
+		e.ContextRaw(3, value.Bytes())
+		answer.DataType = ComplexAck
+		answer.Payload = &DataPayload{Bytes: e.Bytes()}
+	case ServiceConfirmedWriteProperty:
+		var id bacnet.ObjectID
+		var prop uint32
+		dec.ContextObjectID(0, &id)
+		dec.ContextValue(1, &prop)
+		is.Equal(bacnet.PropertyType(prop), bacnet.FileSize)
+		d.files[id] = nil
+	case ServiceConfirmedAtomicReadFile, ServiceConfirmedAtomicWriteFile:
+		var id bacnet.ObjectID
+		var start int32
+		dec.AppData(&id)
+		dec.OpeningTag(0)
+		dec.AppData(&start)
+		if request.ServiceType == ServiceConfirmedAtomicWriteFile {
+			if d.failWrite {
+				answer.DataType = Error
+				answer.Payload = &ApduError{Class: bacnet.ServicesError, Code: bacnet.FileAccessDenied}
+				break
+			}
+			var chunk []byte
+			dec.AppData(&chunk)
+			d.files[id] = append(d.files[id], chunk...)
+			e.ContextSigned(0, start)
+		} else {
+			var count uint32
+			dec.AppData(&count)
+			content := d.files[id][start:]
+			if len(content) > int(count) {
+				content = content[:count]
+			}
+			e.AppData(int(start)+len(content) == len(d.files[id]))
+			e.OpeningTag(0)
+			e.AppData(start)
+			e.AppData(content)
+			e.ClosingTag(0)
+		}
+		answer.DataType = ComplexAck
+		answer.Payload = &DataPayload{Bytes: e.Bytes()}
+	default:
+		return nil
+	}
+	is.NoErr(dec.Error())
+	return answer
+}
+
+func TestBackupRestore(t *testing.T) {
+	is := is.New(t)
+	addr := net.UDPAddr{IP: net.IPv4(10, 0, 2, 3).To4(), Port: DefaultUDPPort}
+	device := bacnet.Device{
+		ID:      bacnet.ObjectID{Type: bacnet.BacnetDevice, Instance: 10},
+		MaxApdu: 480,
+		Addr:    *bacnet.AddressFromUDP(addr),
+	}
+	file1 := bacnet.ObjectID{Type: bacnet.File, Instance: 1}
+	file2 := bacnet.ObjectID{Type: bacnet.File, Instance: 2}
+	fake := &backupDevice{
+		is: is,
+		id: device.ID,
+		files: map[bacnet.ObjectID][]byte{
+			file1: bytes.Repeat([]byte("configuration "), 100),
+			file2: []byte("small"),
+		},
+	}
+	m := newMemTransport()
+	m.respond = answerRequests(t, fake.answer)
+	c := newMemClient(t, m)
+
+	var progress []int
+	opts := BackupOptions{
+		Password: "secret",
+		Progress: func(_ bacnet.ObjectID, done, total int) {
+			is.Equal(total, 2)
+			progress = append(progress, done)
+		},
+	}
+	backup, err := c.BackupDevice(context.Background(), device, opts)
+	is.NoErr(err)
+	is.Equal(fake.states, []ReinitializedState{StartBackup, EndBackup})
+	is.Equal(progress, []int{1, 2})
+	is.Equal(backup, DeviceBackup{
+		Device: device.ID,
+		Files: []BackupFile{
+			{ObjectID: file1, Data: fake.files[file1]},
+			{ObjectID: file2, Data: fake.files[file2]},
+		},
+	})
+
+	fake.files[file2] = []byte("changed configuration")
+	fake.states = nil
+	progress = nil
+	is.NoErr(c.RestoreDevice(context.Background(), device, backup, opts))
+	is.Equal(fake.states, []ReinitializedState{StartRestore, EndRestore})
+	is.Equal(fake.files[file2], []byte("small"))
+	is.Equal(progress, []int{1, 2})
+
+	//A failed restore is aborted
+	fake.states = nil
+	fake.failWrite = true
+	is.True(c.RestoreDevice(context.Background(), device, backup, opts) != nil)
+	is.Equal(fake.states, []ReinitializedState{StartRestore, AbortRestore})
+}
+
+func TestBackupCancelled(t *testing.T) {
+	is := is.New(t)
+	addr := net.UDPAddr{IP: net.IPv4(10, 0, 2, 3).To4(), Port: DefaultUDPPort}
+	device := bacnet.Device{
+		ID:      bacnet.ObjectID{Type: bacnet.BacnetDevice, Instance: 10},
+		MaxApdu: 480,
+		Addr:    *bacnet.AddressFromUDP(addr),
+	}
+	fake := &backupDevice{
+		is: is,
+		id: device.ID,
+		files: map[bacnet.ObjectID][]byte{
+			{Type: bacnet.File, Instance: 1}: []byte("first"),
+			{Type: bacnet.File, Instance: 2}: []byte("second"),
+		},
+	}
+	m := newMemTransport()
+	m.respond = answerRequests(t, fake.answer)
+	c := newMemClient(t, m)
+	//The requests made with a cancelled context wait for their turn
+	//and fail
+	c.SetDeviceProfile(device.ID, DeviceProfile{RequestDelay: 20 * time.Millisecond})
+
+	//The context is cancelled after the first file
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	opts := BackupOptions{
+		Password: "secret",
+		Progress: func(bacnet.ObjectID, int, int) { cancel() },
+	}
+	backup, err := c.BackupDevice(ctx, device, opts)
+	is.True(errors.Is(err, context.Canceled))
+	//The backup is ended anyway
+	is.Equal(fake.states, []ReinitializedState{StartBackup, EndBackup})
+
+	fake.states = nil
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	backup.Files = append(backup.Files, BackupFile{ObjectID: bacnet.ObjectID{Type: bacnet.File, Instance: 2}})
+	is.True(errors.Is(c.RestoreDevice(ctx, device, backup, opts), context.Canceled))
+	is.Equal(fake.states, []ReinitializedState{StartRestore, AbortRestore})
+}
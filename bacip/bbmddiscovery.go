@@ -0,0 +1,136 @@
+package bacip
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"sort"
+	"sync"
+	"time"
+)
+
+// forwarders records when the BBMDs forwarding broadcasts on the local
+// network were last seen
+type forwarders struct {
+	sync.Mutex
+	seen map[string]forwarder
+}
+
+type forwarder struct {
+	addr net.UDPAddr
+	last time.Time
+}
+
+func (f *forwarders) add(addr net.UDPAddr, now time.Time) {
+	f.Lock()
+	defer f.Unlock()
+	if f.seen == nil {
+		f.seen = map[string]forwarder{}
+	}
+	f.seen[addr.String()] = forwarder{addr: addr, last: now}
+}
+
+// since returns the BBMDs seen since the time
+func (f *forwarders) since(t time.Time) []net.UDPAddr {
+	f.Lock()
+	defer f.Unlock()
+	var addrs []net.UDPAddr
+	for _, fw := range f.seen {
+		if !fw.last.Before(t) {
+			addrs = append(addrs, fw.addr)
+		}
+	}
+	return addrs
+}
+
+// BBMDInfo is a BBMD found on the local network
+type BBMDInfo struct {
+	Addr net.UDPAddr
+	//BDT is the broadcast distribution table of the BBMD, nil if it
+	//didn't answer the Read-BDT
+	BDT []BDTEntry
+	//ForeignDevices are the foreign devices registered with the
+	//BBMD, nil if it refused to send its foreign device table
+	ForeignDevices []FDTEntry
+	//Forwarding is true if the BBMD was seen forwarding broadcasts
+	//on the local network
+	Forwarding bool
+}
+
+// DiscoverBBMDs looks for the BBMDs of the local network: it
+// broadcasts a Read-BDT, which only BBMDs answer with their table, and
+// watches the BBMDs forwarding broadcasts on the network for wait. The
+// foreign device tables of the BBMDs found are then read. The BBMDs
+// are sorted by address
+func (c *Client) DiscoverBBMDs(ctx context.Context, wait time.Duration) ([]BBMDInfo, error) {
+	type seen struct {
+		src net.UDPAddr
+		bdt []BDTEntry
+	}
+	messages := make(chan seen, 256)
+	unsubscribe := c.subscriptions.subscribe(func(bvlc BVLC, src net.UDPAddr) {
+		if bvlc.Function != BacFuncBroadcastDistributionTableAck {
+			return
+		}
+		bdt, err := decodeBDT(bvlc.Data)
+		if err != nil {
+			return
+		}
+		select {
+		case messages <- seen{src: src, bdt: bdt}:
+		default:
+		}
+	})
+	defer unsubscribe()
+	start := time.Now()
+	b, err := BVLC{Type: TypeBacnetIP, Function: BacFuncBroadcastDistributionTable}.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	_, err = c.udp.WriteToUDP(b, &net.UDPAddr{IP: c.broadcastAddress, Port: DefaultUDPPort})
+	if err != nil {
+		return nil, err
+	}
+	bbmds := map[string]*BBMDInfo{}
+	get := func(addr net.UDPAddr) *BBMDInfo {
+		info, ok := bbmds[addr.String()]
+		if !ok {
+			info = &BBMDInfo{Addr: addr}
+			bbmds[addr.String()] = info
+		}
+		return info
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+wait:
+	for {
+		select {
+		case s := <-messages:
+			get(s.src).BDT = s.bdt
+		case <-timer.C:
+			break wait
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	for _, addr := range c.forwarders.since(start) {
+		get(addr).Forwarding = true
+	}
+	result := make([]BBMDInfo, 0, len(bbmds))
+	for _, info := range bbmds {
+		addr := info.Addr
+		fdt, err := c.ReadFDT(ctx, &addr)
+		if err != nil && ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		info.ForeignDevices = fdt
+		result = append(result, *info)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if cmp := bytes.Compare(result[i].Addr.IP.To16(), result[j].Addr.IP.To16()); cmp != 0 {
+			return cmp < 0
+		}
+		return result[i].Addr.Port < result[j].Addr.Port
+	})
+	return result, nil
+}
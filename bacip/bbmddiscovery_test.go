@@ -0,0 +1,74 @@
+package bacip
+
+import (
+	"context"
+	"encoding/hex"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestDiscoverBBMDs(t *testing.T) {
+	is := is.New(t)
+	bbmd := &net.UDPAddr{IP: net.IPv4(10, 0, 2, 5).To4(), Port: DefaultUDPPort}
+	forwarder := &net.UDPAddr{IP: net.IPv4(10, 0, 2, 6).To4(), Port: DefaultUDPPort}
+	m := newMemTransport()
+	m.respond = func(b []byte, addr *net.UDPAddr) []byte {
+		var bvlc BVLC
+		if bvlc.UnmarshalBinary(b) != nil {
+			return nil
+		}
+		switch {
+		case bvlc.Function == BacFuncBroadcastDistributionTable && addr.IP.Equal(net.IPv4(10, 0, 2, 255)):
+			table, _ := hex.DecodeString("0a000205bac0ffffffff" + "0a000301bac0ffffffff")
+			ack, _ := BVLC{Type: TypeBacnetIP, Function: BacFuncBroadcastDistributionTableAck, Data: table}.MarshalBinary()
+			m.in <- datagram{data: ack, addr: bbmd}
+			//Another BBMD forwards a broadcast from another network
+			forwarded, _ := BVLC{
+				Type:     TypeBacnetIP,
+				Function: BacFuncForwardedNPDU,
+				Origin:   &net.UDPAddr{IP: net.IPv4(10, 0, 4, 3).To4(), Port: DefaultUDPPort},
+				NPDU: NPDU{Version: Version1, Priority: Normal, ADPU: &APDU{
+					DataType:    UnconfirmedServiceRequest,
+					ServiceType: ServiceUnconfirmedWhoIs,
+					Payload:     &WhoIs{},
+				}},
+			}.MarshalBinary()
+			m.in <- datagram{data: forwarded, addr: forwarder}
+		case bvlc.Function == BacFuncReadForeignDeviceTable && addr.IP.Equal(bbmd.IP):
+			table, _ := hex.DecodeString("0a000901bac0003c0041")
+			ack, _ := BVLC{Type: TypeBacnetIP, Function: BacFuncReadForeignDeviceTableAck, Data: table}.MarshalBinary()
+			return ack
+		case bvlc.Function == BacFuncReadForeignDeviceTable:
+			nak, _ := BVLC{Type: TypeBacnetIP, Function: BacFuncResult, Data: []byte{0x00, 0x40}}.MarshalBinary()
+			return nak
+		}
+		return nil
+	}
+	c := newMemClient(t, m)
+
+	bbmds, err := c.DiscoverBBMDs(context.Background(), 100*time.Millisecond)
+	is.NoErr(err)
+	is.Equal(len(bbmds), 2)
+	is.Equal(bbmds[0].Addr.String(), bbmd.String())
+	is.Equal(len(bbmds[0].BDT), 2)
+	is.True(!bbmds[0].Forwarding)
+	is.Equal(bbmds[0].ForeignDevices, []FDTEntry{{
+		Addr:      net.UDPAddr{IP: net.IPv4(10, 0, 9, 1).To4(), Port: DefaultUDPPort},
+		TTL:       60 * time.Second,
+		Remaining: 65 * time.Second,
+	}})
+	is.Equal(bbmds[1].Addr.String(), forwarder.String())
+	is.Equal(bbmds[1].BDT, nil)
+	is.Equal(bbmds[1].ForeignDevices, nil)
+	is.True(bbmds[1].Forwarding)
+
+	var findings []Finding
+	c.checkBBMDs(context.Background(), 100*time.Millisecond, func(check string, severity Severity, format string, args ...interface{}) {
+		findings = append(findings, Finding{Check: check, Severity: severity})
+	})
+	is.Equal(len(findings), 3)
+	is.Equal(findings[2].Severity, SeverityWarning)
+}
@@ -0,0 +1,212 @@
+package bacip
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
+
+// bdtEntrySize is the size of an encoded BDT entry: the IPv4 address
+// and port of the BBMD, and its broadcast distribution mask
+const bdtEntrySize = 10
+
+// BDTEntry is an entry of a broadcast distribution table: a BBMD and
+// its broadcast distribution mask
+type BDTEntry struct {
+	Addr net.UDPAddr
+	Mask net.IPMask
+}
+
+// twoHop tells if the broadcasts are sent to the BBMD, which
+// broadcasts them on its network. Otherwise they are sent directly to
+// the broadcast address of its network, and the routers must forward
+// directed broadcasts
+func (e BDTEntry) twoHop() bool {
+	ones, bits := e.Mask.Size()
+	return bits == 8*net.IPv4len && ones == bits
+}
+
+// forwardAddress returns the address the broadcasts are forwarded to
+// for the entry
+func (e BDTEntry) forwardAddress() *net.UDPAddr {
+	ip := e.Addr.IP.To4()
+	if e.twoHop() {
+		return &net.UDPAddr{IP: ip, Port: e.Addr.Port}
+	}
+	broadcast := make(net.IP, net.IPv4len)
+	for i := range broadcast {
+		broadcast[i] = ip[i] | ^e.Mask[i]
+	}
+	return &net.UDPAddr{IP: broadcast, Port: e.Addr.Port}
+}
+
+func decodeBDT(data []byte) ([]BDTEntry, error) {
+	if len(data)%bdtEntrySize != 0 {
+		return nil, fmt.Errorf("invalid BDT length %d", len(data))
+	}
+	bdt := make([]BDTEntry, 0, len(data)/bdtEntrySize)
+	for buf := bytes.NewBuffer(data); buf.Len() > 0; {
+		e := buf.Next(bdtEntrySize)
+		bdt = append(bdt, BDTEntry{
+			Addr: net.UDPAddr{
+				IP:   net.IPv4(e[0], e[1], e[2], e[3]).To4(),
+				Port: int(binary.BigEndian.Uint16(e[4:6])),
+			},
+			Mask: net.IPv4Mask(e[6], e[7], e[8], e[9]),
+		})
+	}
+	return bdt, nil
+}
+
+// errBDTNAK is returned when a BBMD refuses to send its table
+var errBDTNAK = errors.New("read BDT rejected")
+
+// ReadBDT reads the broadcast distribution table of a BBMD
+func (c *Client) ReadBDT(ctx context.Context, bbmd *net.UDPAddr) ([]BDTEntry, error) {
+	bvlc, err := c.bvllQuery(ctx, bbmd, BacFuncBroadcastDistributionTable, BacFuncBroadcastDistributionTableAck)
+	if err != nil {
+		return nil, err
+	}
+	if bvlc.Function == BacFuncResult {
+		return nil, errBDTNAK
+	}
+	return decodeBDT(bvlc.Data)
+}
+
+// fdtEntrySize is the size of an encoded FDT entry: the IPv4 address
+// and port of the foreign device, its time-to-live and the time
+// remaining before its registration expires
+const fdtEntrySize = 10
+
+// FDTEntry is an entry of a foreign device table: a foreign device
+// registered with a BBMD
+type FDTEntry struct {
+	Addr net.UDPAddr
+	TTL  time.Duration
+	//Remaining is the time before the registration expires, grace
+	//period included
+	Remaining time.Duration
+}
+
+func decodeFDT(data []byte) ([]FDTEntry, error) {
+	if len(data)%fdtEntrySize != 0 {
+		return nil, fmt.Errorf("invalid FDT length %d", len(data))
+	}
+	fdt := make([]FDTEntry, 0, len(data)/fdtEntrySize)
+	for buf := bytes.NewBuffer(data); buf.Len() > 0; {
+		e := buf.Next(fdtEntrySize)
+		fdt = append(fdt, FDTEntry{
+			Addr: net.UDPAddr{
+				IP:   net.IPv4(e[0], e[1], e[2], e[3]).To4(),
+				Port: int(binary.BigEndian.Uint16(e[4:6])),
+			},
+			TTL:       time.Duration(binary.BigEndian.Uint16(e[6:8])) * time.Second,
+			Remaining: time.Duration(binary.BigEndian.Uint16(e[8:10])) * time.Second,
+		})
+	}
+	return fdt, nil
+}
+
+// errFDTNAK is returned when a BBMD refuses to send its foreign device
+// table, as the BBMDs not accepting foreign devices do
+var errFDTNAK = errors.New("read FDT rejected")
+
+// ReadFDT reads the foreign device table of a BBMD
+func (c *Client) ReadFDT(ctx context.Context, bbmd *net.UDPAddr) ([]FDTEntry, error) {
+	bvlc, err := c.bvllQuery(ctx, bbmd, BacFuncReadForeignDeviceTable, BacFuncReadForeignDeviceTableAck)
+	if err != nil {
+		return nil, err
+	}
+	if bvlc.Function == BacFuncResult {
+		return nil, errFDTNAK
+	}
+	return decodeFDT(bvlc.Data)
+}
+
+// bvllQuery sends a BVLL request without data to addr, and returns its
+// answer: the ack function or a BVLC-Result
+func (c *Client) bvllQuery(ctx context.Context, addr *net.UDPAddr, request, ack Function) (BVLC, error) {
+	answer := make(chan BVLC, 1)
+	unsubscribe := c.subscriptions.subscribe(func(bvlc BVLC, src net.UDPAddr) {
+		if !src.IP.Equal(addr.IP) || src.Port != addr.Port ||
+			(bvlc.Function != ack && bvlc.Function != BacFuncResult) {
+			return
+		}
+		select {
+		case answer <- bvlc:
+		default:
+		}
+	})
+	defer unsubscribe()
+	b, err := BVLC{Type: TypeBacnetIP, Function: request}.MarshalBinary()
+	if err != nil {
+		return BVLC{}, err
+	}
+	_, err = c.udp.WriteToUDP(b, addr)
+	if err != nil {
+		return BVLC{}, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, foreignDeviceTimeout)
+	defer cancel()
+	select {
+	case bvlc := <-answer:
+		return bvlc, nil
+	case <-ctx.Done():
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return BVLC{}, fmt.Errorf("no answer within %v", foreignDeviceTimeout)
+		}
+		return BVLC{}, ctx.Err()
+	}
+}
+
+// SetBroadcastDistribution makes the client distribute its broadcasts
+// like a BBMD with the given broadcast distribution table. The
+// broadcasts are sent on the local network, and forwarded to the
+// networks of the other entries of the table: to the BBMD of the entry
+// if its mask is all ones (two-hop distribution: the BBMD broadcasts
+// them on its network), or to the broadcast address of its network
+// otherwise (one-hop distribution). The entry of the client itself is
+// skipped. A nil table disables the distribution. It's ignored while
+// the client is registered as a foreign device
+func (c *Client) SetBroadcastDistribution(bdt []BDTEntry) error {
+	for _, e := range bdt {
+		if e.Addr.IP.To4() == nil || len(e.Mask) != net.IPv4len {
+			return fmt.Errorf("invalid BDT entry %v/%v: IPv4 address and mask expected", e.Addr.String(), e.Mask)
+		}
+	}
+	c.bdt.Store(append([]BDTEntry(nil), bdt...))
+	return nil
+}
+
+// distributeBroadcast forwards a broadcast NPDU to the networks of the
+// broadcast distribution table
+func (c *Client) distributeBroadcast(npdu NPDU) error {
+	bdt, _ := c.bdt.Load().([]BDTEntry)
+	if len(bdt) == 0 {
+		return nil
+	}
+	self := &net.UDPAddr{IP: c.ipAddress, Port: c.udpPort}
+	b, err := BVLC{
+		Type:     TypeBacnetIP,
+		Function: BacFuncForwardedNPDU,
+		Origin:   self,
+		NPDU:     npdu,
+	}.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	for _, e := range bdt {
+		if e.Addr.IP.Equal(c.ipAddress) && e.Addr.Port == c.udpPort {
+			continue
+		}
+		_, err := c.udp.WriteToUDP(b, e.forwardAddress())
+		if err != nil {
+			return fmt.Errorf("forward broadcast to %v: %w", e.Addr.String(), err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package bacip
+
+import (
+	"context"
+	"encoding/hex"
+	"net"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestBroadcastDistribution(t *testing.T) {
+	is := is.New(t)
+	bbmd := net.UDPAddr{IP: net.IPv4(10, 0, 3, 1).To4(), Port: DefaultUDPPort}
+	m := newMemTransport()
+	m.respond = func(b []byte, addr *net.UDPAddr) []byte {
+		var bvlc BVLC
+		if bvlc.UnmarshalBinary(b) != nil || bvlc.Function != BacFuncBroadcastDistributionTable {
+			return nil
+		}
+		//The BBMD is one-hop, the client two-hop. The port of the
+		//memory transport is 0
+		table, _ := hex.DecodeString("0a000301bac0ffffff00" + "0a0002020000ffffffff")
+		answer, _ := BVLC{Type: TypeBacnetIP, Function: BacFuncBroadcastDistributionTableAck, Data: table}.MarshalBinary()
+		m.in <- datagram{data: answer, addr: &bbmd}
+		return nil
+	}
+	c := newMemClient(t, m)
+
+	bdt, err := c.ReadBDT(context.Background(), &bbmd)
+	is.NoErr(err)
+	is.Equal(len(bdt), 2)
+	is.Equal(bdt[0].Addr.String(), "10.0.3.1:47808")
+	is.Equal(bdt[0].Mask.String(), "ffffff00")
+	is.True(!bdt[0].twoHop())
+	is.True(bdt[1].twoHop())
+	bdt = append(bdt, BDTEntry{Addr: net.UDPAddr{IP: net.IPv4(10, 0, 4, 1), Port: DefaultUDPPort}, Mask: net.CIDRMask(32, 32)})
+	is.NoErr(c.SetBroadcastDistribution(bdt))
+	is.True(c.SetBroadcastDistribution([]BDTEntry{{Addr: bbmd}}) != nil)
+
+	m.Lock()
+	sent := len(m.written)
+	m.Unlock()
+	_, err = c.broadcast(NPDU{Version: Version1, Priority: Normal, ADPU: &APDU{
+		DataType:    UnconfirmedServiceRequest,
+		ServiceType: ServiceUnconfirmedWhoIs,
+		Payload:     &WhoIs{},
+	}})
+	is.NoErr(err)
+	m.Lock()
+	defer m.Unlock()
+	is.Equal(len(m.written), sent+3)
+	var functions, destinations []string
+	for i := sent; i < len(m.written); i++ {
+		var bvlc BVLC
+		is.NoErr(bvlc.UnmarshalBinary(m.written[i]))
+		functions = append(functions, bvlc.Function.String())
+		destinations = append(destinations, m.to[i].String())
+		if bvlc.Function == BacFuncForwardedNPDU {
+			is.Equal(bvlc.Origin.String(), "10.0.2.2:0")
+		}
+	}
+	//Broadcast locally, to the network of the one-hop BBMD and to the
+	//two-hop BBMD, but not to the client itself
+	is.Equal(functions, []string{"BacFuncBroadcast", "BacFuncForwardedNPDU", "BacFuncForwardedNPDU"})
+	is.Equal(destinations, []string{"10.0.2.255:47808", "10.0.3.255:47808", "10.0.4.1:47808"})
+}
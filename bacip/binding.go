@@ -0,0 +1,187 @@
+package bacip
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/REQUEA/bacnet"
+)
+
+// autoBindTimeout is how long the client waits for the IAm of an
+// unknown device
+const autoBindTimeout = 3 * time.Second
+
+// deviceRegistry keeps the devices the client heard from
+type deviceRegistry struct {
+	sync.RWMutex
+	devices map[bacnet.ObjectID]bacnet.Device
+	//pending are the devices being looked up
+	pending   map[bacnet.ObjectID]struct{}
+	autoBind  bool
+	onNew     func(bacnet.Device)
+	onRestart func(bacnet.Device, DeviceCapabilities)
+}
+
+// add records the device and returns true if it wasn't known
+func (r *deviceRegistry) add(d bacnet.Device) bool {
+	r.Lock()
+	if r.devices == nil {
+		r.devices = map[bacnet.ObjectID]bacnet.Device{}
+	}
+	_, known := r.devices[d.ID]
+	r.devices[d.ID] = d
+	onNew := r.onNew
+	r.Unlock()
+	if !known && onNew != nil {
+		onNew(d)
+	}
+	return !known
+}
+
+// update replaces the device if it's known
+func (r *deviceRegistry) update(d bacnet.Device) {
+	r.Lock()
+	defer r.Unlock()
+	if _, known := r.devices[d.ID]; known {
+		r.devices[d.ID] = d
+	}
+}
+
+// lookup returns true if the device isn't known and isn't already
+// being looked up, and marks it as being looked up
+func (r *deviceRegistry) lookup(id bacnet.ObjectID) bool {
+	r.Lock()
+	defer r.Unlock()
+	if !r.autoBind {
+		return false
+	}
+	if _, ok := r.devices[id]; ok {
+		return false
+	}
+	if _, ok := r.pending[id]; ok {
+		return false
+	}
+	if r.pending == nil {
+		r.pending = map[bacnet.ObjectID]struct{}{}
+	}
+	r.pending[id] = struct{}{}
+	return true
+}
+
+func (r *deviceRegistry) done(id bacnet.ObjectID) {
+	r.Lock()
+	defer r.Unlock()
+	delete(r.pending, id)
+}
+
+// KnownDevices returns the devices the client received an IAm from,
+// sorted by identifier
+func (c *Client) KnownDevices() []bacnet.Device {
+	c.registry.RLock()
+	defer c.registry.RUnlock()
+	devices := make([]bacnet.Device, 0, len(c.registry.devices))
+	for _, d := range c.registry.devices {
+		devices = append(devices, d)
+	}
+	sort.Slice(devices, func(i, j int) bool {
+		a, b := devices[i].ID, devices[j].ID
+		return a.Type < b.Type || (a.Type == b.Type && a.Instance < b.Instance)
+	})
+	return devices
+}
+
+// KnownDevice returns the device with the given identifier, if the
+// client received an IAm from it
+func (c *Client) KnownDevice(id bacnet.ObjectID) (bacnet.Device, bool) {
+	c.registry.RLock()
+	defer c.registry.RUnlock()
+	d, ok := c.registry.devices[id]
+	return d, ok
+}
+
+// OnNewDevice sets the function called when an IAm is received from a
+// device for the first time. It must not block
+func (c *Client) OnNewDevice(f func(bacnet.Device)) {
+	c.registry.Lock()
+	defer c.registry.Unlock()
+	c.registry.onNew = f
+}
+
+// SetAutoBinding enables the automatic lookup of the devices sending
+// unsolicited notifications or IHave without being known: a WhoIs
+// is sent for the device, and its IAm adds it to the known devices.
+func (c *Client) SetAutoBinding(enabled bool) {
+	c.registry.Lock()
+	defer c.registry.Unlock()
+	c.registry.autoBind = enabled
+}
+
+// bind records the devices announcing themselves, and looks up the
+// unknown devices sending other unconfirmed requests
+func (c *Client) bind(apdu *APDU, src bacnet.Address) {
+	if apdu.DataType != UnconfirmedServiceRequest {
+		return
+	}
+	if iam, ok := apdu.Payload.(*Iam); ok {
+		d := bacnet.Device{
+			ID:           iam.ObjectID,
+			MaxApdu:      iam.MaxApduLength,
+			Segmentation: iam.SegmentationSupport,
+			Vendor:       iam.VendorID,
+			Addr:         src,
+		}
+		old, known := c.KnownDevice(d.ID)
+		if c.registry.add(d) {
+			c.autoTimeSync(d)
+		}
+		//Capabilities changing is the sign of a firmware upgrade
+		if known && (old.MaxApdu != d.MaxApdu || old.Segmentation != d.Segmentation) {
+			c.refreshRestarted(d)
+		}
+		return
+	}
+	if n, ok := apdu.Payload.(*COVNotification); ok && isRestartNotification(*n) {
+		//The device may come back with another configuration
+		if rc := c.cache(); rc != nil {
+			rc.InvalidateDevice(n.Device)
+		}
+		if d, known := c.KnownDevice(n.Device); known {
+			c.autoTimeSync(d)
+			c.refreshRestarted(d)
+		}
+	}
+	id, ok := senderID(apdu)
+	if !ok || !c.registry.lookup(id) {
+		return
+	}
+	go func() {
+		defer c.registry.done(id)
+		if !c.runFlag.Load() {
+			return
+		}
+		instance := uint32(id.Instance)
+		_, err := c.WhoIs(WhoIs{Low: &instance, High: &instance}, autoBindTimeout)
+		if err != nil {
+			c.logger.Error("auto binding of ", id, ": ", err)
+		}
+	}()
+}
+
+// senderID returns the device that sent an unconfirmed request, if
+// the request contains it
+func senderID(apdu *APDU) (bacnet.ObjectID, bool) {
+	if n, ok := apdu.Payload.(*COVNotification); ok {
+		return n.Device, n.Device.Type == bacnet.BacnetDevice
+	}
+	if m, ok := apdu.Payload.(*TextMessage); ok {
+		return m.Source, m.Source.Type == bacnet.BacnetDevice
+	}
+	if ihave, ok := apdu.Payload.(*IHave); ok {
+		return ihave.Device, ihave.Device.Type == bacnet.BacnetDevice
+	}
+	if n, ok := apdu.Payload.(*EventNotification); ok {
+		return n.InitiatingDevice, n.InitiatingDevice.Type == bacnet.BacnetDevice
+	}
+	return bacnet.ObjectID{}, false
+}
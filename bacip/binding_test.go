@@ -0,0 +1,123 @@
+package bacip
+
+import (
+	"encoding/hex"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/REQUEA/bacnet"
+
+	"github.com/matryer/is"
+)
+
+func TestSenderID(t *testing.T) {
+	is := is.New(t)
+	var apdu APDU
+	//COV notification of device 7
+	b, _ := hex.DecodeString("1002090f1c020000072c0000000139004e09552e4441a800002f4f")
+	is.NoErr(apdu.UnmarshalBinary(b))
+	id, ok := senderID(&apdu)
+	is.True(ok)
+	is.Equal(id, bacnet.ObjectID{Type: bacnet.BacnetDevice, Instance: 7})
+	//WhoIs doesn't tell who sent it
+	b, _ = hex.DecodeString("1008")
+	is.NoErr(apdu.UnmarshalBinary(b))
+	_, ok = senderID(&apdu)
+	is.True(!ok)
+}
+
+func TestAutoBinding(t *testing.T) {
+	is := is.New(t)
+	device := bacnet.ObjectID{Type: bacnet.BacnetDevice, Instance: 7}
+	deviceAddr := &net.UDPAddr{IP: net.IPv4(10, 0, 2, 7), Port: DefaultUDPPort}
+	m := newMemTransport()
+	m.respond = func(b []byte, addr *net.UDPAddr) []byte {
+		var bvlc BVLC
+		if bvlc.UnmarshalBinary(b) != nil || bvlc.NPDU.ADPU == nil {
+			return nil
+		}
+		whoIs, ok := bvlc.NPDU.ADPU.Payload.(*WhoIs)
+		if !ok || whoIs.Low == nil || *whoIs.Low != 7 {
+			return nil
+		}
+		iam, _ := BVLC{
+			Type:     TypeBacnetIP,
+			Function: BacFuncBroadcast,
+			NPDU: NPDU{
+				Version: Version1,
+				ADPU: &APDU{
+					DataType:    UnconfirmedServiceRequest,
+					ServiceType: ServiceUnconfirmedIAm,
+					Payload: &Iam{
+						ObjectID:            device,
+						MaxApduLength:       480,
+						SegmentationSupport: bacnet.SegmentationSupportNone,
+						VendorID:            5,
+					},
+				},
+			},
+		}.MarshalBinary()
+		return iam
+	}
+	c := newMemClient(t, m)
+	seen := make(chan bacnet.Device, 1)
+	c.OnNewDevice(func(d bacnet.Device) { seen <- d })
+	c.SetAutoBinding(true)
+
+	cov, _ := hex.DecodeString("810a002101001002090f1c020000072c0000000139004e09552e4441a800002f4f")
+	m.in <- datagram{data: cov, addr: deviceAddr}
+	select {
+	case d := <-seen:
+		is.Equal(d.ID, device)
+		is.Equal(d.MaxApdu, uint32(480))
+	case <-time.After(time.Second):
+		t.Fatal("device not bound")
+	}
+	_, ok := c.KnownDevice(device)
+	is.True(ok)
+	is.Equal(len(c.KnownDevices()), 1)
+}
+
+func TestRoutedSource(t *testing.T) {
+	is := is.New(t)
+	router := net.UDPAddr{IP: net.IPv4(10, 0, 2, 1).To4(), Port: DefaultUDPPort}
+	device := bacnet.ObjectID{Type: bacnet.BacnetDevice, Instance: 7}
+	m := newMemTransport()
+	m.respond = func(b []byte, _ *net.UDPAddr) []byte {
+		var bvlc BVLC
+		if bvlc.UnmarshalBinary(b) != nil || bvlc.NPDU.ADPU == nil {
+			return nil
+		}
+		if _, ok := bvlc.NPDU.ADPU.Payload.(*WhoIs); !ok {
+			return nil
+		}
+		//An MS/TP device answering through its router
+		iam, _ := BVLC{
+			Type:     TypeBacnetIP,
+			Function: BacFuncBroadcast,
+			NPDU: NPDU{
+				Version: Version1,
+				Source:  &bacnet.Address{Net: 5, Adr: []byte{0x0D}},
+				ADPU: &APDU{
+					DataType:    UnconfirmedServiceRequest,
+					ServiceType: ServiceUnconfirmedIAm,
+					Payload:     &Iam{ObjectID: device, MaxApduLength: 480},
+				},
+			},
+		}.MarshalBinary()
+		m.in <- datagram{data: iam, addr: &router}
+		return nil
+	}
+	c := newMemClient(t, m)
+	c.SetWhoIsCoalescing(0)
+
+	want := bacnet.MSTPAddress(router, 5, 0x0D)
+	devices, err := c.WhoIs(WhoIs{}, 100*time.Millisecond)
+	is.NoErr(err)
+	is.Equal(len(devices), 1)
+	is.Equal(devices[0].Addr.String(), want.String())
+	d, ok := c.KnownDevice(device)
+	is.True(ok)
+	is.Equal(d.Addr.String(), want.String())
+}
@@ -0,0 +1,175 @@
+package bacip
+
+import (
+	_ "embed" //Used by DiscoveryCacheSchema
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/REQUEA/bacnet"
+)
+
+// DiscoveryCacheVersion is the version of the JSON format of the
+// discovery cache written by this package
+const DiscoveryCacheVersion = 1
+
+// DiscoveryCacheSchema is the JSON schema of the discovery cache
+// format, for the applications that don't use this package to read it
+//
+//go:embed discoverycache.schema.json
+var DiscoveryCacheSchema string
+
+// DiscoveryCache contains the devices found on the network and their
+// objects. It can be exported to JSON to be shared between several
+// gateways, or with a backend, to avoid scanning the same devices
+// again.
+type DiscoveryCache struct {
+	Devices []DeviceSnapshot
+}
+
+// Lookup returns the snapshot of the device, if any
+func (dc DiscoveryCache) Lookup(id bacnet.ObjectID) (DeviceSnapshot, bool) {
+	for _, d := range dc.Devices {
+		if d.Device.ID == id {
+			return d, true
+		}
+	}
+	return DeviceSnapshot{}, false
+}
+
+// Merge adds the devices of other to the cache. When both caches
+// contain the same device, the most recent snapshot is kept.
+func (dc *DiscoveryCache) Merge(other DiscoveryCache) {
+	index := make(map[bacnet.ObjectID]int, len(dc.Devices))
+	for i, d := range dc.Devices {
+		index[d.Device.ID] = i
+	}
+	for _, d := range other.Devices {
+		i, ok := index[d.Device.ID]
+		if !ok {
+			index[d.Device.ID] = len(dc.Devices)
+			dc.Devices = append(dc.Devices, d)
+			continue
+		}
+		if d.Taken.After(dc.Devices[i].Taken) {
+			dc.Devices[i] = d
+		}
+	}
+}
+
+type jsonCache struct {
+	Version int          `json:"version"`
+	Devices []jsonDevice `json:"devices"`
+}
+
+type jsonObjectID struct {
+	Type     bacnet.ObjectType     `json:"type"`
+	Instance bacnet.ObjectInstance `json:"instance"`
+}
+
+type jsonAddress struct {
+	Mac string `json:"mac"`
+	Net uint16 `json:"net,omitempty"`
+	Adr string `json:"adr,omitempty"`
+}
+
+func newJSONAddress(addr bacnet.Address) jsonAddress {
+	return jsonAddress{
+		Mac: hex.EncodeToString(addr.Mac),
+		Net: addr.Net,
+		Adr: hex.EncodeToString(addr.Adr),
+	}
+}
+
+func (a jsonAddress) address() (bacnet.Address, error) {
+	mac, err := hex.DecodeString(a.Mac)
+	if err != nil {
+		return bacnet.Address{}, fmt.Errorf("invalid mac: %w", err)
+	}
+	adr, err := hex.DecodeString(a.Adr)
+	if err != nil {
+		return bacnet.Address{}, fmt.Errorf("invalid adr: %w", err)
+	}
+	if len(adr) == 0 {
+		adr = nil
+	}
+	return bacnet.Address{Mac: mac, Net: a.Net, Adr: adr}, nil
+}
+
+type jsonDevice struct {
+	ID           jsonObjectID               `json:"id"`
+	Address      jsonAddress                `json:"address"`
+	MaxApdu      uint32                     `json:"maxApdu"`
+	Segmentation bacnet.SegmentationSupport `json:"segmentation"`
+	Vendor       uint32                     `json:"vendor"`
+	Taken        time.Time                  `json:"taken"`
+	Objects      []jsonObject               `json:"objects"`
+}
+
+type jsonObject struct {
+	ID          jsonObjectID `json:"id"`
+	Name        string       `json:"name"`
+	Description string       `json:"description,omitempty"`
+}
+
+func (dc DiscoveryCache) MarshalJSON() ([]byte, error) {
+	cache := jsonCache{Version: DiscoveryCacheVersion, Devices: []jsonDevice{}}
+	for _, d := range dc.Devices {
+		device := jsonDevice{
+			ID:           jsonObjectID(d.Device.ID),
+			Address:      newJSONAddress(d.Device.Addr),
+			MaxApdu:      d.Device.MaxApdu,
+			Segmentation: d.Device.Segmentation,
+			Vendor:       d.Device.Vendor,
+			Taken:        d.Taken,
+			Objects:      []jsonObject{},
+		}
+		for _, o := range d.Objects {
+			device.Objects = append(device.Objects, jsonObject{
+				ID:          jsonObjectID(o.ID),
+				Name:        o.Name,
+				Description: o.Description,
+			})
+		}
+		cache.Devices = append(cache.Devices, device)
+	}
+	return json.Marshal(cache)
+}
+
+func (dc *DiscoveryCache) UnmarshalJSON(data []byte) error {
+	var cache jsonCache
+	err := json.Unmarshal(data, &cache)
+	if err != nil {
+		return err
+	}
+	if cache.Version < 1 || cache.Version > DiscoveryCacheVersion {
+		return fmt.Errorf("unsupported discovery cache version %d", cache.Version)
+	}
+	dc.Devices = make([]DeviceSnapshot, 0, len(cache.Devices))
+	for _, d := range cache.Devices {
+		addr, err := d.Address.address()
+		if err != nil {
+			return fmt.Errorf("device %d: %w", d.ID.Instance, err)
+		}
+		snapshot := DeviceSnapshot{
+			Device: bacnet.Device{
+				ID:           bacnet.ObjectID(d.ID),
+				MaxApdu:      d.MaxApdu,
+				Segmentation: d.Segmentation,
+				Vendor:       d.Vendor,
+				Addr:         addr,
+			},
+			Taken: d.Taken,
+		}
+		for _, o := range d.Objects {
+			snapshot.Objects = append(snapshot.Objects, ObjectInfo{
+				ID:          bacnet.ObjectID(o.ID),
+				Name:        o.Name,
+				Description: o.Description,
+			})
+		}
+		dc.Devices = append(dc.Devices, snapshot)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package bacip
+
+import (
+	"encoding/json"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/REQUEA/bacnet"
+
+	"github.com/matryer/is"
+)
+
+func TestDiscoveryCacheJSON(t *testing.T) {
+	is := is.New(t)
+	taken := time.Date(2022, 12, 1, 10, 0, 0, 0, time.UTC)
+	cache := DiscoveryCache{Devices: []DeviceSnapshot{{
+		Device: bacnet.Device{
+			ID:      bacnet.ObjectID{Type: bacnet.BacnetDevice, Instance: 1234},
+			MaxApdu: 1476,
+			Vendor:  5,
+			Addr:    *bacnet.AddressFromUDP(net.UDPAddr{IP: net.IPv4(192, 168, 1, 10).To4(), Port: DefaultUDPPort}),
+		},
+		Taken: taken,
+		Objects: []ObjectInfo{
+			{ID: bacnet.ObjectID{Type: bacnet.AnalogInput, Instance: 1}, Name: "OAT", Description: "Outside air"},
+		},
+	}}}
+	b, err := json.Marshal(cache)
+	is.NoErr(err)
+	is.Equal(string(b), `{"version":1,"devices":[{"id":{"type":8,"instance":1234},"address":{"mac":"04c0a8010abac0"},"maxApdu":1476,"segmentation":0,"vendor":5,"taken":"2022-12-01T10:00:00Z","objects":[{"id":{"type":0,"instance":1},"name":"OAT","description":"Outside air"}]}]}`)
+	var cache2 DiscoveryCache
+	is.NoErr(json.Unmarshal(b, &cache2))
+	is.Equal(cache2, cache)
+
+	is.True(json.Unmarshal([]byte(`{"version":2,"devices":[]}`), &cache2) != nil)
+	is.True(json.Valid([]byte(DiscoveryCacheSchema)))
+}
+
+func TestDiscoveryCacheMerge(t *testing.T) {
+	is := is.New(t)
+	now := time.Now()
+	d1 := bacnet.Device{ID: bacnet.ObjectID{Type: bacnet.BacnetDevice, Instance: 1}}
+	d2 := bacnet.Device{ID: bacnet.ObjectID{Type: bacnet.BacnetDevice, Instance: 2}}
+	cache := DiscoveryCache{Devices: []DeviceSnapshot{
+		{Device: d1, Taken: now},
+		{Device: d2, Taken: now},
+	}}
+	cache.Merge(DiscoveryCache{Devices: []DeviceSnapshot{
+		{Device: d1, Taken: now.Add(-time.Hour), Objects: []ObjectInfo{{Name: "old"}}},
+		{Device: d2, Taken: now.Add(time.Hour), Objects: []ObjectInfo{{Name: "new"}}},
+		{Device: bacnet.Device{ID: bacnet.ObjectID{Type: bacnet.BacnetDevice, Instance: 3}}},
+	}})
+	is.Equal(len(cache.Devices), 3)
+	s, ok := cache.Lookup(d1.ID)
+	is.True(ok)
+	is.Equal(len(s.Objects), 0)
+	s, _ = cache.Lookup(d2.ID)
+	is.Equal(s.Objects[0].Name, "new")
+}
@@ -0,0 +1,256 @@
+package bacip
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/REQUEA/bacnet"
+	"github.com/REQUEA/bacnet/internal/encoding"
+)
+
+//go:generate stringer -type=CalendarEntryKind
+type CalendarEntryKind byte
+
+const (
+	CalendarDate      CalendarEntryKind = 0
+	CalendarDateRange CalendarEntryKind = 1
+	CalendarWeekNDay  CalendarEntryKind = 2
+)
+
+// WeekNDay matches days by month, week of the month and day of the
+// week. Each field may be set to bacnet.Unspecified.
+type WeekNDay struct {
+	//Month is 1-12, 13 for odd months and 14 for even months
+	Month int
+	//WeekOfMonth is 1 for days 1-7, 2 for days 8-14,..., 6 for the
+	//last 7 days of the month
+	WeekOfMonth int
+	//Weekday goes from 1 (Monday) to 7 (Sunday)
+	Weekday int
+}
+
+// CalendarEntry is a date, a range of dates or a WeekNDay pattern,
+// depending on its Kind. Only the fields matching the kind are
+// meaningful
+type CalendarEntry struct {
+	Kind CalendarEntryKind
+	//Date is the date of a CalendarDate entry and the start of a
+	//CalendarDateRange entry
+	Date     bacnet.Date
+	EndDate  bacnet.Date
+	WeekNDay WeekNDay
+}
+
+// DateEntry returns a calendar entry matching the day of t
+func DateEntry(t time.Time) CalendarEntry {
+	return CalendarEntry{Kind: CalendarDate, Date: bacnet.DateFromTime(t)}
+}
+
+// DateRangeEntry returns a calendar entry matching the days from start
+// to end, both included
+func DateRangeEntry(start, end time.Time) CalendarEntry {
+	return CalendarEntry{
+		Kind:    CalendarDateRange,
+		Date:    bacnet.DateFromTime(start),
+		EndDate: bacnet.DateFromTime(end),
+	}
+}
+
+// WeekNDayEntry returns a calendar entry matching the given pattern
+func WeekNDayEntry(w WeekNDay) CalendarEntry {
+	return CalendarEntry{Kind: CalendarWeekNDay, WeekNDay: w}
+}
+
+// Matches tells if the day of t matches the pattern
+func (w WeekNDay) Matches(t time.Time) bool {
+	month := bacnet.Date{Year: bacnet.UnspecifiedYear, Month: w.Month, Day: bacnet.Unspecified, Weekday: w.Weekday}
+	if !month.Matches(t) {
+		return false
+	}
+	switch w.WeekOfMonth {
+	case bacnet.Unspecified:
+		return true
+	case 6:
+		//The last 7 days of the month
+		return t.AddDate(0, 0, 7).Month() != t.Month()
+	default:
+		return (t.Day()-1)/7+1 == w.WeekOfMonth
+	}
+}
+
+// Matches tells if the day of t is included in the entry. The bounds
+// of a date range whose year, month or day are unspecified are open
+func (ce CalendarEntry) Matches(t time.Time) bool {
+	switch ce.Kind {
+	case CalendarDate:
+		return ce.Date.Matches(t)
+	case CalendarDateRange:
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		if ce.Date.Specified() && day.Before(bacnet.DateTime{Date: ce.Date}.ToTime(time.UTC)) {
+			return false
+		}
+		return !ce.EndDate.Specified() || !day.After(bacnet.DateTime{Date: ce.EndDate}.ToTime(time.UTC))
+	case CalendarWeekNDay:
+		return ce.WeekNDay.Matches(t)
+	}
+	return false
+}
+
+func (ce CalendarEntry) encode(e *encoding.Encoder) {
+	switch ce.Kind {
+	case CalendarDate:
+		e.ContextDate(0, ce.Date)
+	case CalendarDateRange:
+		e.OpeningTag(1)
+		e.AppData(ce.Date)
+		e.AppData(ce.EndDate)
+		e.ClosingTag(1)
+	case CalendarWeekNDay:
+		w := ce.WeekNDay
+		e.ContextOctetString(2, []byte{byte(w.Month), byte(w.WeekOfMonth), byte(w.Weekday)})
+	}
+}
+
+func (ce *CalendarEntry) decode(d *encoding.Decoder) error {
+	switch {
+	case d.IsContextTag(0):
+		ce.Kind = CalendarDate
+		d.ContextDate(0, &ce.Date)
+	case d.IsOpeningTag(1):
+		ce.Kind = CalendarDateRange
+		d.OpeningTag(1)
+		d.AppData(&ce.Date)
+		d.AppData(&ce.EndDate)
+		d.ClosingTag(1)
+	case d.IsContextTag(2):
+		ce.Kind = CalendarWeekNDay
+		var b []byte
+		d.ContextOctetString(2, &b)
+		if d.Error() == nil && len(b) != 3 {
+			return fmt.Errorf("invalid WeekNDay length %d", len(b))
+		}
+		if len(b) == 3 {
+			ce.WeekNDay = WeekNDay{Month: int(b[0]), WeekOfMonth: int(b[1]), Weekday: int(b[2])}
+		}
+	default:
+		return fmt.Errorf("invalid calendar entry")
+	}
+	return d.Error()
+}
+
+// DateList is the date-list of a Calendar object
+type DateList []CalendarEntry
+
+func (dl DateList) MarshalBinary() ([]byte, error) {
+	encoder := encoding.NewEncoder()
+	for _, ce := range dl {
+		ce.encode(&encoder)
+	}
+	return encoder.Bytes(), encoder.Error()
+}
+
+func (dl *DateList) UnmarshalBinary(data []byte) error {
+	*dl = DateList{}
+	return decodeList(data, func(d *encoding.Decoder) error {
+		ce := CalendarEntry{}
+		err := ce.decode(d)
+		*dl = append(*dl, ce)
+		return err
+	})
+}
+
+// Matches tells if the day of t is included in any entry of the list,
+// as the present value of the calendar would be on that day
+func (dl DateList) Matches(t time.Time) bool {
+	for _, ce := range dl {
+		if ce.Matches(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// Contains returns true if the list contains the entry
+func (dl DateList) Contains(entry CalendarEntry) bool {
+	for _, ce := range dl {
+		if ce == entry {
+			return true
+		}
+	}
+	return false
+}
+
+// ReadDateList reads the date-list of a Calendar object
+func (c *Client) ReadDateList(ctx context.Context, device bacnet.Device, calendar bacnet.ObjectID) (DateList, error) {
+	raw, err := c.readRaw(ctx, device, ReadProperty{
+		ObjectID: calendar,
+		Property: bacnet.PropertyIdentifier{Type: bacnet.DateList},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("read date list: %w", err)
+	}
+	dl := DateList{}
+	err = dl.UnmarshalBinary(raw)
+	if err != nil {
+		return nil, fmt.Errorf("decode date list: %w", err)
+	}
+	return dl, nil
+}
+
+// WriteDateList replaces the date-list of a Calendar object
+func (c *Client) WriteDateList(ctx context.Context, device bacnet.Device, calendar bacnet.ObjectID, dl DateList) error {
+	err := c.WriteProperty(ctx, device, WriteProperty{
+		ObjectID:      calendar,
+		Property:      bacnet.PropertyIdentifier{Type: bacnet.DateList},
+		PropertyValue: bacnet.PropertyValue{Value: dl},
+	})
+	if err != nil {
+		return fmt.Errorf("write date list: %w", err)
+	}
+	return nil
+}
+
+// AddCalendarEntries adds the entries to the date-list of a Calendar
+// object. Entries already in the list are not added twice. The list
+// is read then written back, so concurrent modifications by other
+// clients may be lost.
+func (c *Client) AddCalendarEntries(ctx context.Context, device bacnet.Device, calendar bacnet.ObjectID, entries ...CalendarEntry) error {
+	dl, err := c.ReadDateList(ctx, device, calendar)
+	if err != nil {
+		return err
+	}
+	changed := false
+	for _, ce := range entries {
+		if !dl.Contains(ce) {
+			dl = append(dl, ce)
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	return c.WriteDateList(ctx, device, calendar, dl)
+}
+
+// RemoveCalendarEntries removes the entries from the date-list of a
+// Calendar object. Entries must be identical to the ones of the list,
+// including wildcards, to be removed. Like AddCalendarEntries, the
+// list is read then written back.
+func (c *Client) RemoveCalendarEntries(ctx context.Context, device bacnet.Device, calendar bacnet.ObjectID, entries ...CalendarEntry) error {
+	dl, err := c.ReadDateList(ctx, device, calendar)
+	if err != nil {
+		return err
+	}
+	toRemove := DateList(entries)
+	kept := DateList{}
+	for _, ce := range dl {
+		if !toRemove.Contains(ce) {
+			kept = append(kept, ce)
+		}
+	}
+	if len(kept) == len(dl) {
+		return nil
+	}
+	return c.WriteDateList(ctx, device, calendar, kept)
+}
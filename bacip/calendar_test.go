@@ -0,0 +1,78 @@
+package bacip
+
+import (
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/REQUEA/bacnet"
+
+	"github.com/matryer/is"
+)
+
+func TestDateListCoherency(t *testing.T) {
+	is := is.New(t)
+	dl := DateList{
+		{Kind: CalendarDate, Date: bacnet.Date{Year: 2022, Month: 12, Day: 25, Weekday: 7}},
+		{
+			Kind:    CalendarDateRange,
+			Date:    bacnet.Date{Year: 2022, Month: 8, Day: 1, Weekday: 1},
+			EndDate: bacnet.Date{Year: 2022, Month: 8, Day: 15, Weekday: 1},
+		},
+		{Kind: CalendarWeekNDay, WeekNDay: WeekNDay{Month: bacnet.Unspecified, WeekOfMonth: bacnet.Unspecified, Weekday: 5}},
+	}
+	b, err := dl.MarshalBinary()
+	is.NoErr(err)
+	is.Equal(hex.EncodeToString(b), "0c7a0c1907"+"1ea47a0801"+"01a47a080f011f"+"2bffff05")
+	dl2 := DateList{}
+	is.NoErr(dl2.UnmarshalBinary(b))
+	is.Equal(dl2, dl)
+	is.True(dl.Contains(WeekNDayEntry(WeekNDay{Month: bacnet.Unspecified, WeekOfMonth: bacnet.Unspecified, Weekday: 5})))
+	is.True(!dl.Contains(WeekNDayEntry(WeekNDay{Month: 1, WeekOfMonth: bacnet.Unspecified, Weekday: 5})))
+}
+
+func TestCalendarMatches(t *testing.T) {
+	is := is.New(t)
+	day := func(year int, month time.Month, d int) time.Time {
+		return time.Date(year, month, d, 12, 0, 0, 0, time.UTC)
+	}
+	//Fridays of odd months
+	oddFridays := bacnet.Date{Year: bacnet.UnspecifiedYear, Month: bacnet.OddMonths, Day: bacnet.Unspecified, Weekday: 5}
+	is.True(oddFridays.Matches(day(2023, time.March, 3)))
+	is.True(!oddFridays.Matches(day(2023, time.April, 7)))
+	is.True(!oddFridays.Matches(day(2023, time.March, 4)))
+	is.True(!oddFridays.Specified())
+
+	lastDay := bacnet.Date{Year: 2024, Month: bacnet.EvenMonths, Day: bacnet.LastDayOfMonth, Weekday: bacnet.Unspecified}
+	is.True(lastDay.Matches(day(2024, time.February, 29)))
+	is.True(!lastDay.Matches(day(2024, time.February, 28)))
+	is.True(!lastDay.Matches(day(2024, time.January, 31)))
+	is.Equal(lastDay.String(), "2024-even-last")
+	evenDays := bacnet.Date{Year: bacnet.Unspecified, Month: 7, Day: bacnet.EvenDays, Weekday: bacnet.Unspecified}
+	is.True(evenDays.Matches(day(2031, time.July, 14)))
+	is.True(!evenDays.Matches(day(2031, time.July, 15)))
+	is.Equal(evenDays.String(), "*-07-even")
+
+	dl := DateList{
+		WeekNDayEntry(WeekNDay{Month: bacnet.Unspecified, WeekOfMonth: 6, Weekday: 1}),
+		{
+			Kind:    CalendarDateRange,
+			Date:    bacnet.Date{Year: 2022, Month: 8, Day: 1, Weekday: 1},
+			EndDate: bacnet.Date{Year: 2022, Month: 8, Day: 15, Weekday: 1},
+		},
+	}
+	//Last Monday of the month
+	is.True(dl.Matches(day(2023, time.May, 29)))
+	is.True(!dl.Matches(day(2023, time.May, 22)))
+	is.True(dl.Matches(day(2022, time.August, 1)))
+	is.True(dl.Matches(day(2022, time.August, 15)))
+	is.True(!dl.Matches(day(2022, time.August, 16)))
+	//A range without end
+	open := DateRangeEntry(day(2022, time.August, 1), day(2022, time.August, 1))
+	open.EndDate = bacnet.Date{Year: bacnet.UnspecifiedYear, Month: bacnet.Unspecified, Day: bacnet.Unspecified, Weekday: bacnet.Unspecified}
+	is.True(open.Matches(day(2030, time.January, 1)))
+	is.True(!open.Matches(day(2022, time.July, 31)))
+
+	is.True(bacnet.Time{Hour: 8, Minute: bacnet.Unspecified, Second: bacnet.Unspecified, Hundredths: bacnet.Unspecified}.Matches(day(2022, time.August, 1).Add(-4 * time.Hour)))
+	is.True(!bacnet.Time{Hour: 8, Minute: 0, Second: 0, Hundredths: 0}.Matches(day(2022, time.August, 1)))
+}
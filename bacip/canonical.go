@@ -0,0 +1,53 @@
+package bacip
+
+import (
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/REQUEA/bacnet/internal/encoding"
+)
+
+// ErrNonCanonical is returned, wrapped, by CheckCanonical for a valid
+// encoding that isn't the one required by the standard
+var ErrNonCanonical = errors.New("non-canonical encoding")
+
+// CheckCanonical checks that the encoded service data of an APDU is
+// canonical: the tags are in their shortest form and balanced, and
+// the application tagged values don't have redundant bytes. The
+// payloads of this package are always encoded canonically, and the
+// same payload always to the same bytes, so that the encodings can be
+// compared byte by byte. The content of the context tagged values
+// isn't checked
+func CheckCanonical(data []byte) error {
+	err := encoding.Canonical(data)
+	var e encoding.NonCanonicalError
+	if errors.As(err, &e) {
+		return fmt.Errorf("%w: %v", ErrNonCanonical, err)
+	}
+	return err
+}
+
+// SetStrictDecoding enables the check of the encoding of the messages
+// received from the peers. The service data that isn't canonical is
+// still decoded and handled, but the messages are logged and listed in
+// the decode errors of the debug info, for the certification of the
+// devices
+func (c *Client) SetStrictDecoding(strict bool) {
+	c.strictDecoding.Store(strict)
+}
+
+// checkCanonical reports the received messages whose service data
+// isn't canonical, if strict decoding is enabled. header is the length
+// of the headers of the message, as decoded
+func (c *Client) checkCanonical(src *net.UDPAddr, bvlc BVLC, b []byte, header int) {
+	apdu := bvlc.NPDU.ADPU
+	if !c.strictDecoding.Load() || apdu == nil || apdu.Segmented || header > len(b) {
+		return
+	}
+	err := CheckCanonical(b[header:])
+	if err != nil {
+		c.decodeErrors.add(src, b, err)
+		c.logger.Error("non-canonical message from ", src, ": ", err)
+	}
+}
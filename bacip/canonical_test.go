@@ -0,0 +1,91 @@
+package bacip
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/REQUEA/bacnet"
+	"github.com/matryer/is"
+)
+
+func TestCanonicalEncodings(t *testing.T) {
+	is := is.New(t)
+	for _, s := range goldenSamples() {
+		b, err := s.payload.MarshalBinary()
+		is.NoErr(err)
+		again, err := s.payload.MarshalBinary()
+		is.NoErr(err)
+		if !bytes.Equal(b, again) {
+			t.Errorf("%s: non-deterministic encoding", s.name)
+		}
+		if err := CheckCanonical(b); err != nil {
+			t.Errorf("%s: %v", s.name, err)
+		}
+	}
+}
+
+func TestStrictDecoding(t *testing.T) {
+	is := is.New(t)
+	//IAm whose max APDU has a leading zero byte
+	payload, err := hex.DecodeString("c4020000012200329103210f")
+	is.NoErr(err)
+	is.True(errors.Is(CheckCanonical(payload), ErrNonCanonical))
+
+	m := newMemTransport()
+	c := newMemClient(t, m)
+	c.SetStrictDecoding(true)
+	b, err := datagramOf(&APDU{
+		DataType:    UnconfirmedServiceRequest,
+		ServiceType: ServiceUnconfirmedIAm,
+		Payload:     &DataPayload{Bytes: payload},
+	})
+	is.NoErr(err)
+	m.in <- datagram{data: b, addr: &net.UDPAddr{IP: net.IPv4(10, 0, 2, 3), Port: DefaultUDPPort}}
+	deadline := time.Now().Add(time.Second)
+	for len(c.decodeErrors.list()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	errs := c.decodeErrors.list()
+	is.Equal(len(errs), 1)
+	is.True(strings.HasPrefix(errs[0].Error, ErrNonCanonical.Error()))
+	//The message is handled anyway
+	device := bacnet.ObjectID{Type: bacnet.BacnetDevice, Instance: 1}
+	_, ok := c.KnownDevice(device)
+	for !ok && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+		_, ok = c.KnownDevice(device)
+	}
+	is.True(ok)
+}
+
+func TestStrictDecodingHeaders(t *testing.T) {
+	is := is.New(t)
+	m := newMemTransport()
+	c := newMemClient(t, m)
+	c.SetStrictDecoding(true)
+	iam, err := Iam{
+		ObjectID:            bacnet.ObjectID{Type: bacnet.BacnetDevice, Instance: 1},
+		MaxApduLength:       480,
+		SegmentationSupport: bacnet.SegmentationSupportNone,
+	}.MarshalBinary()
+	is.NoErr(err)
+	//The NPDU has a source address on the network 0, which isn't
+	//encoded again
+	b := append([]byte{0x81, 0x0a, 0x00, byte(4 + 6 + 2 + len(iam)), 0x01, 0x08, 0x00, 0x00, 0x01, 0x05, 0x10, 0x00}, iam...)
+	m.in <- datagram{data: b, addr: &net.UDPAddr{IP: net.IPv4(10, 0, 2, 3), Port: DefaultUDPPort}}
+	device := bacnet.ObjectID{Type: bacnet.BacnetDevice, Instance: 1}
+	deadline := time.Now().Add(time.Second)
+	_, ok := c.KnownDevice(device)
+	for !ok && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+		_, ok = c.KnownDevice(device)
+	}
+	is.True(ok)
+	//The service data is canonical
+	is.Equal(len(c.decodeErrors.list()), 0)
+}
@@ -0,0 +1,129 @@
+package bacip
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/REQUEA/bacnet"
+)
+
+// refreshTimeout bounds the refresh of the capabilities of a device
+// that restarted
+const refreshTimeout = 30 * time.Second
+
+// DeviceCapabilities are the properties of a device that can change
+// when its firmware is upgraded
+type DeviceCapabilities struct {
+	MaxApdu          uint32
+	Segmentation     bacnet.SegmentationSupport
+	FirmwareRevision string
+	//ApplicationSoftwareVersion is empty if the device doesn't have it
+	ApplicationSoftwareVersion string
+}
+
+// ReadCapabilities reads the capabilities of the device from its
+// device object. The read cache is bypassed
+func (c *Client) ReadCapabilities(ctx context.Context, device bacnet.Device) (DeviceCapabilities, error) {
+	var caps DeviceCapabilities
+	if rc := c.cache(); rc != nil {
+		rc.InvalidateObject(device.ID, device.ID)
+	}
+	read := func(prop bacnet.PropertyType) (interface{}, error) {
+		return c.ReadProperty(ctx, device, ReadProperty{
+			ObjectID: device.ID,
+			Property: bacnet.PropertyIdentifier{Type: prop},
+		})
+	}
+	d, err := read(bacnet.MaxApduLengthAccepted)
+	if err != nil {
+		return caps, fmt.Errorf("read max APDU: %w", err)
+	}
+	maxApdu, ok := d.(uint32)
+	if !ok {
+		return caps, fmt.Errorf("unexpected max APDU type %T", d)
+	}
+	caps.MaxApdu = maxApdu
+	d, err = read(bacnet.SegmentationSupported)
+	if err != nil {
+		return caps, fmt.Errorf("read segmentation: %w", err)
+	}
+	segmentation, ok := d.(uint32)
+	if !ok || segmentation > uint32(bacnet.SegmentationSupportNone) {
+		return caps, fmt.Errorf("unexpected segmentation %v", d)
+	}
+	caps.Segmentation = bacnet.SegmentationSupport(segmentation)
+	d, err = read(bacnet.FirmwareRevision)
+	if err != nil && !isUnknownProperty(err) {
+		return caps, fmt.Errorf("read firmware revision: %w", err)
+	}
+	caps.FirmwareRevision, _ = d.(string)
+	d, err = read(bacnet.ApplicationSoftwareVersion)
+	if err != nil && !isUnknownProperty(err) {
+		return caps, fmt.Errorf("read application software version: %w", err)
+	}
+	caps.ApplicationSoftwareVersion, _ = d.(string)
+	return caps, nil
+}
+
+// RefreshDevice reads the capabilities of the device and returns the
+// device updated with them. The known device is updated too. It
+// should be called after a firmware upgrade, as stale capabilities
+// make the requests too large or wrongly segmented, and the device
+// abort them
+func (c *Client) RefreshDevice(ctx context.Context, device bacnet.Device) (bacnet.Device, DeviceCapabilities, error) {
+	caps, err := c.ReadCapabilities(ctx, device)
+	if err != nil {
+		return device, caps, err
+	}
+	device.MaxApdu = caps.MaxApdu
+	device.Segmentation = caps.Segmentation
+	c.registry.update(device)
+	return device, caps, nil
+}
+
+// SetRefreshOnRestart sets the function called with the refreshed
+// capabilities of a known device that restarted. A device is
+// considered restarted when it sends a restart notification, or an IAm
+// announcing different capabilities. f is called from its own
+// goroutine. nil disables the refresh
+func (c *Client) SetRefreshOnRestart(f func(bacnet.Device, DeviceCapabilities)) {
+	c.registry.Lock()
+	defer c.registry.Unlock()
+	c.registry.onRestart = f
+}
+
+// refreshRestarted refreshes the capabilities of a device that
+// restarted, if enabled
+func (c *Client) refreshRestarted(device bacnet.Device) {
+	c.registry.RLock()
+	onRestart := c.registry.onRestart
+	c.registry.RUnlock()
+	if onRestart == nil {
+		return
+	}
+	go func() {
+		if !c.runFlag.Load() {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), refreshTimeout)
+		defer cancel()
+		device, caps, err := c.RefreshDevice(ctx, device)
+		if err != nil {
+			c.logger.Error("refresh of ", device.ID, ": ", err)
+			return
+		}
+		onRestart(device, caps)
+	}()
+}
+
+// isRestartNotification returns true if the notification is the
+// restart notification a device sends to its restart notification
+// recipients
+func isRestartNotification(n COVNotification) bool {
+	if n.ObjectID != n.Device || n.Device.Type != bacnet.BacnetDevice {
+		return false
+	}
+	_, ok := n.Value(bacnet.TimeOfDeviceRestart)
+	return ok
+}
@@ -0,0 +1,123 @@
+package bacip
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/REQUEA/bacnet"
+	"github.com/REQUEA/bacnet/internal/encoding"
+	"github.com/matryer/is"
+)
+
+func TestRefreshDevice(t *testing.T) {
+	is := is.New(t)
+	id := bacnet.ObjectID{Type: bacnet.BacnetDevice, Instance: 7}
+	deviceAddr := &net.UDPAddr{IP: net.IPv4(10, 0, 2, 7).To4(), Port: DefaultUDPPort}
+	var mutex sync.Mutex
+	properties := map[bacnet.PropertyType]interface{}{
+		bacnet.MaxApduLengthAccepted: uint32(1476),
+		bacnet.SegmentationSupported: uint32(bacnet.SegmentationSupportBoth),
+		bacnet.FirmwareRevision:      "2.0",
+	}
+	m := newMemTransport()
+	m.respond = answerRequests(t, func(request *APDU) *APDU {
+		dec := encoding.NewDecoder(request.Payload.(*DataPayload).Bytes)
+		var object bacnet.ObjectID
+		var prop uint32
+		dec.ContextObjectID(0, &object)
+		dec.ContextValue(1, &prop)
+		is.NoErr(dec.Error())
+		is.Equal(object, id)
+		mutex.Lock()
+		v, ok := properties[bacnet.PropertyType(prop)]
+		mutex.Unlock()
+		answer := &APDU{ServiceType: request.ServiceType, InvokeID: request.InvokeID}
+		if !ok {
+			answer.DataType = Error
+			answer.Payload = &ApduError{Class: bacnet.PropertyError, Code: bacnet.UnknownProperty}
+		} else {
+			value := encoding.NewEncoder()
+			value.AppData(v)
+			e := encoding.NewEncoder()
+			e.ContextObjectID(0, object)
+			e.ContextUnsigned(1, prop)
+			e.ContextRaw(3, value.Bytes())
+			answer.DataType = ComplexAck
+			answer.Payload = &DataPayload{Bytes: e.Bytes()}
+		}
+		return answer
+	})
+	c := newMemClient(t, m)
+	iam := func(maxApdu uint32) datagram {
+		b, err := datagramOf(&APDU{
+			DataType:    UnconfirmedServiceRequest,
+			ServiceType: ServiceUnconfirmedIAm,
+			Payload: &Iam{
+				ObjectID:            id,
+				MaxApduLength:       maxApdu,
+				SegmentationSupport: bacnet.SegmentationSupportNone,
+			},
+		})
+		is.NoErr(err)
+		return datagram{data: b, addr: deviceAddr}
+	}
+	m.in <- iam(480)
+	is.True(waitFor(func() bool {
+		_, ok := c.KnownDevice(id)
+		return ok
+	}))
+	device, _ := c.KnownDevice(id)
+
+	device, caps, err := c.RefreshDevice(context.Background(), device)
+	is.NoErr(err)
+	is.Equal(caps, DeviceCapabilities{MaxApdu: 1476, Segmentation: bacnet.SegmentationSupportBoth, FirmwareRevision: "2.0"})
+	is.Equal(device.MaxApdu, uint32(1476))
+	known, _ := c.KnownDevice(id)
+	is.Equal(known, device)
+
+	refreshed := make(chan DeviceCapabilities, 1)
+	c.SetRefreshOnRestart(func(d bacnet.Device, caps DeviceCapabilities) {
+		is.Equal(d.ID, id)
+		refreshed <- caps
+	})
+	wait := func() DeviceCapabilities {
+		select {
+		case caps := <-refreshed:
+			return caps
+		case <-time.After(time.Second):
+			t.Fatal("device not refreshed")
+			return DeviceCapabilities{}
+		}
+	}
+	//An IAm with other capabilities follows a firmware upgrade
+	mutex.Lock()
+	properties[bacnet.FirmwareRevision] = "2.1"
+	mutex.Unlock()
+	m.in <- iam(1024)
+	is.Equal(wait().FirmwareRevision, "2.1")
+
+	//So does a restart notification
+	mutex.Lock()
+	properties[bacnet.MaxApduLengthAccepted] = uint32(480)
+	mutex.Unlock()
+	b, err := datagramOf(&APDU{
+		DataType:    UnconfirmedServiceRequest,
+		ServiceType: ServiceUnconfirmedCOVNotification,
+		Payload: &COVNotification{
+			Device:   id,
+			ObjectID: id,
+			Values: []COVValue{
+				{Property: bacnet.PropertyIdentifier{Type: bacnet.SystemStatus}, Value: uint32(0)},
+				{Property: bacnet.PropertyIdentifier{Type: bacnet.TimeOfDeviceRestart}, Value: uint32(1)},
+			},
+		},
+	})
+	is.NoErr(err)
+	m.in <- datagram{data: b, addr: deviceAddr}
+	is.Equal(wait().MaxApdu, uint32(480))
+	known, _ = c.KnownDevice(id)
+	is.Equal(known.MaxApdu, uint32(480))
+}
@@ -0,0 +1,408 @@
+package bacip
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/REQUEA/bacnet"
+	"github.com/REQUEA/bacnet/internal/encoding"
+)
+
+// LightingCommand is a command of a Lighting Output object. Optional
+// fields are nil when not used by the operation
+type LightingCommand struct {
+	Operation     bacnet.LightingOperation
+	TargetLevel   *float32
+	RampRate      *float32
+	StepIncrement *float32
+	//FadeTime is in milliseconds
+	FadeTime *uint32
+	Priority *uint32
+}
+
+func (lc LightingCommand) MarshalBinary() ([]byte, error) {
+	encoder := encoding.NewEncoder()
+	lc.encode(&encoder)
+	return encoder.Bytes(), encoder.Error()
+}
+
+func (lc *LightingCommand) UnmarshalBinary(data []byte) error {
+	decoder := encoding.NewDecoder(data)
+	lc.decode(decoder)
+	return decoder.Error()
+}
+
+func (lc LightingCommand) encode(e *encoding.Encoder) {
+	e.ContextUnsigned(0, uint32(lc.Operation))
+	if lc.TargetLevel != nil {
+		e.ContextReal(1, *lc.TargetLevel)
+	}
+	if lc.RampRate != nil {
+		e.ContextReal(2, *lc.RampRate)
+	}
+	if lc.StepIncrement != nil {
+		e.ContextReal(3, *lc.StepIncrement)
+	}
+	if lc.FadeTime != nil {
+		e.ContextUnsigned(4, *lc.FadeTime)
+	}
+	if lc.Priority != nil {
+		e.ContextUnsigned(5, *lc.Priority)
+	}
+}
+
+func (lc *LightingCommand) decode(d *encoding.Decoder) {
+	var val uint32
+	d.ContextValue(0, &val)
+	lc.Operation = bacnet.LightingOperation(val)
+	*lc = LightingCommand{Operation: lc.Operation}
+	if d.IsContextTag(1) {
+		lc.TargetLevel = new(float32)
+		d.ContextReal(1, lc.TargetLevel)
+	}
+	if d.IsContextTag(2) {
+		lc.RampRate = new(float32)
+		d.ContextReal(2, lc.RampRate)
+	}
+	if d.IsContextTag(3) {
+		lc.StepIncrement = new(float32)
+		d.ContextReal(3, lc.StepIncrement)
+	}
+	if d.IsContextTag(4) {
+		lc.FadeTime = new(uint32)
+		d.ContextValue(4, lc.FadeTime)
+	}
+	if d.IsContextTag(5) {
+		lc.Priority = new(uint32)
+		d.ContextValue(5, lc.Priority)
+	}
+}
+
+// ChannelValue is the present value of a Channel object: either an
+// application value, written as is to the members of the channel, or
+// a lighting command if LightingCommand is not nil
+type ChannelValue struct {
+	Value           bacnet.PropertyValue
+	LightingCommand *LightingCommand
+}
+
+func (v ChannelValue) MarshalBinary() ([]byte, error) {
+	encoder := encoding.NewEncoder()
+	v.encode(&encoder)
+	return encoder.Bytes(), encoder.Error()
+}
+
+func (v *ChannelValue) UnmarshalBinary(data []byte) error {
+	decoder := encoding.NewDecoder(data)
+	v.decode(decoder)
+	return decoder.Error()
+}
+
+func (v ChannelValue) encode(e *encoding.Encoder) {
+	if v.LightingCommand != nil {
+		e.OpeningTag(0)
+		v.LightingCommand.encode(e)
+		e.ClosingTag(0)
+		return
+	}
+	e.AppValue(v.Value)
+}
+
+func (v *ChannelValue) decode(d *encoding.Decoder) {
+	*v = ChannelValue{}
+	if d.IsOpeningTag(0) {
+		v.LightingCommand = &LightingCommand{}
+		d.OpeningTag(0)
+		v.LightingCommand.decode(d)
+		d.ClosingTag(0)
+		return
+	}
+	d.AppValue(&v.Value)
+}
+
+// WriteChannel writes the value to the Channel object, which writes
+// it to all its members at the given priority. If priority is 0, the
+// channel uses its own priority-for-writing
+func (c *Client) WriteChannel(ctx context.Context, device bacnet.Device, channel bacnet.ObjectID, value ChannelValue, priority bacnet.PriorityList) error {
+	if channel.Type != bacnet.Channel {
+		return fmt.Errorf("%v isn't a channel", channel)
+	}
+	return c.WriteProperty(ctx, device, WriteProperty{
+		ObjectID:      channel,
+		Property:      bacnet.PropertyIdentifier{Type: bacnet.PresentValue},
+		PropertyValue: bacnet.PropertyValue{Value: value},
+		Priority:      priority,
+	})
+}
+
+// ChannelConfig contains the configuration properties of a Channel
+// object. Nil fields are left unchanged on the device
+type ChannelConfig struct {
+	//ChannelNumber identifies the channel in WriteGroup requests
+	ChannelNumber *uint16
+	//WritePriority is the priority used to write the members when
+	//no priority is given
+	WritePriority *bacnet.PriorityList
+	//ControlGroups are the groups of WriteGroup requests the
+	//channel responds to. 0 is unused
+	ControlGroups []uint32
+}
+
+// Validate checks the values before sending them to a device
+func (cfg ChannelConfig) Validate() error {
+	if cfg.WritePriority != nil && (*cfg.WritePriority < 1 || *cfg.WritePriority > 16) {
+		return fmt.Errorf("invalid write priority %d", *cfg.WritePriority)
+	}
+	return nil
+}
+
+// ReadChannelConfig reads the configuration properties of a Channel
+// object
+func (c *Client) ReadChannelConfig(ctx context.Context, device bacnet.Device, channel bacnet.ObjectID) (ChannelConfig, error) {
+	cfg := ChannelConfig{}
+	read := func(prop bacnet.PropertyType) (uint32, error) {
+		d, err := c.ReadProperty(ctx, device, ReadProperty{
+			ObjectID: channel,
+			Property: bacnet.PropertyIdentifier{Type: prop},
+		})
+		if err != nil {
+			return 0, fmt.Errorf("read %v: %w", prop, err)
+		}
+		v, ok := d.(uint32)
+		if !ok {
+			return 0, fmt.Errorf("read %v: unexpected type %T", prop, d)
+		}
+		return v, nil
+	}
+	number, err := read(bacnet.ChannelNumber)
+	if err != nil {
+		return cfg, err
+	}
+	cfg.ChannelNumber = new(uint16)
+	*cfg.ChannelNumber = uint16(number)
+	priority, err := read(bacnet.PriorityForWriting)
+	if err != nil {
+		return cfg, err
+	}
+	cfg.WritePriority = new(bacnet.PriorityList)
+	*cfg.WritePriority = bacnet.PriorityList(priority)
+	raw, err := c.readRaw(ctx, device, ReadProperty{
+		ObjectID: channel,
+		Property: bacnet.PropertyIdentifier{Type: bacnet.ControlGroups},
+	})
+	if err != nil {
+		return cfg, fmt.Errorf("read control groups: %w", err)
+	}
+	cfg.ControlGroups = []uint32{}
+	err = decodeList(raw, func(d *encoding.Decoder) error {
+		var group uint32
+		d.AppData(&group)
+		cfg.ControlGroups = append(cfg.ControlGroups, group)
+		return nil
+	})
+	if err != nil {
+		return cfg, fmt.Errorf("decode control groups: %w", err)
+	}
+	return cfg, nil
+}
+
+// ConfigureChannel writes the non nil fields of cfg to the Channel
+// object
+func (c *Client) ConfigureChannel(ctx context.Context, device bacnet.Device, channel bacnet.ObjectID, cfg ChannelConfig) error {
+	if channel.Type != bacnet.Channel {
+		return fmt.Errorf("%v isn't a channel", channel)
+	}
+	err := cfg.Validate()
+	if err != nil {
+		return err
+	}
+	write := func(prop bacnet.PropertyType, value bacnet.PropertyValue) error {
+		err := c.WriteProperty(ctx, device, WriteProperty{
+			ObjectID:      channel,
+			Property:      bacnet.PropertyIdentifier{Type: prop},
+			PropertyValue: value,
+		})
+		if err != nil {
+			return fmt.Errorf("write %v: %w", prop, err)
+		}
+		return nil
+	}
+	if cfg.ChannelNumber != nil {
+		err := write(bacnet.ChannelNumber, bacnet.PropertyValue{Value: uint32(*cfg.ChannelNumber)})
+		if err != nil {
+			return err
+		}
+	}
+	if cfg.WritePriority != nil {
+		err := write(bacnet.PriorityForWriting, bacnet.PropertyValue{Value: uint32(*cfg.WritePriority)})
+		if err != nil {
+			return err
+		}
+	}
+	if cfg.ControlGroups != nil {
+		return write(bacnet.ControlGroups, bacnet.PropertyValue{Value: unsignedList(cfg.ControlGroups)})
+	}
+	return nil
+}
+
+// unsignedList encodes a list or an array of unsigned values
+type unsignedList []uint32
+
+func (l unsignedList) MarshalBinary() ([]byte, error) {
+	encoder := encoding.NewEncoder()
+	for _, v := range l {
+		encoder.AppData(v)
+	}
+	return encoder.Bytes(), encoder.Error()
+}
+
+// GroupChannelValue is the value written to the channels with the
+// given number by a WriteGroup request
+type GroupChannelValue struct {
+	Channel uint16
+	//OverridingPriority is 0 to use the priority of the request
+	OverridingPriority bacnet.PriorityList
+	Value              ChannelValue
+}
+
+func (v GroupChannelValue) encode(e *encoding.Encoder) error {
+	e.ContextUnsigned(0, uint32(v.Channel))
+	if v.OverridingPriority != 0 {
+		if v.OverridingPriority > 16 {
+			return fmt.Errorf("invalid overriding priority %d", v.OverridingPriority)
+		}
+		e.ContextUnsigned(1, uint32(v.OverridingPriority))
+	}
+	v.Value.encode(e)
+	return nil
+}
+
+func (v *GroupChannelValue) decode(d *encoding.Decoder) error {
+	var val uint32
+	d.ContextValue(0, &val)
+	if val > 0xFFFF {
+		return errors.New("invalid channel number")
+	}
+	v.Channel = uint16(val)
+	v.OverridingPriority = 0
+	if d.IsContextTag(1) {
+		d.ContextValue(1, &val)
+		v.OverridingPriority = bacnet.PriorityList(val)
+	}
+	v.Value.decode(d)
+	return d.Error()
+}
+
+// WriteGroup writes values to the channels of a control group, in all
+// the devices receiving the request
+type WriteGroup struct {
+	Group    uint32
+	Priority bacnet.PriorityList
+	Changes  []GroupChannelValue
+	//InhibitDelay, if not nil, tells the channels whether to skip
+	//their execution delay
+	InhibitDelay *bool
+}
+
+func (w WriteGroup) MarshalBinary() ([]byte, error) {
+	if w.Group == 0 {
+		return nil, errors.New("group number 0 is reserved")
+	}
+	if w.Priority < 1 || w.Priority > 16 {
+		return nil, fmt.Errorf("invalid write priority %d", w.Priority)
+	}
+	encoder := encoding.NewEncoder()
+	encoder.ContextUnsigned(0, w.Group)
+	encoder.ContextUnsigned(1, uint32(w.Priority))
+	encoder.OpeningTag(2)
+	for _, v := range w.Changes {
+		err := v.encode(&encoder)
+		if err != nil {
+			return nil, err
+		}
+	}
+	encoder.ClosingTag(2)
+	if w.InhibitDelay != nil {
+		encoder.ContextBool(3, *w.InhibitDelay)
+	}
+	return encoder.Bytes(), encoder.Error()
+}
+
+func (w *WriteGroup) UnmarshalBinary(data []byte) error {
+	decoder := encoding.NewDecoder(data)
+	var val uint32
+	decoder.ContextValue(0, &w.Group)
+	decoder.ContextValue(1, &val)
+	w.Priority = bacnet.PriorityList(val)
+	w.Changes = []GroupChannelValue{}
+	decoder.OpeningTag(2)
+	for decoder.Error() == nil && !decoder.IsClosingTag(2) {
+		v := GroupChannelValue{}
+		err := v.decode(decoder)
+		if err != nil {
+			return err
+		}
+		w.Changes = append(w.Changes, v)
+	}
+	decoder.ClosingTag(2)
+	w.InhibitDelay = nil
+	if decoder.IsContextTag(3) {
+		w.InhibitDelay = new(bool)
+		decoder.ContextBool(3, w.InhibitDelay)
+	}
+	return decoder.Error()
+}
+
+// WriteGroup sends the WriteGroup request to the device, or broadcasts
+// it if device is nil. The request is unconfirmed: the channels of the
+// group don't answer. The write policy sees each change as a write of
+// the present value of the Channel object whose instance is the
+// channel number, at the device ID 0 for a broadcast
+func (c *Client) WriteGroup(device *bacnet.Device, w WriteGroup) error {
+	var target bacnet.ObjectID
+	if device != nil {
+		target = device.ID
+	}
+	specs := make([]WriteAccessSpecification, 0, len(w.Changes))
+	for _, v := range w.Changes {
+		priority := v.OverridingPriority
+		if priority == 0 {
+			priority = w.Priority
+		}
+		specs = append(specs, WriteAccessSpecification{
+			ObjectID: bacnet.ObjectID{Type: bacnet.Channel, Instance: bacnet.ObjectInstance(v.Channel)},
+			Properties: []PropertyWrite{{
+				Property: bacnet.PropertyIdentifier{Type: bacnet.PresentValue},
+				Value:    bacnet.PropertyValue{Value: v.Value},
+				Priority: priority,
+			}},
+		})
+	}
+	err := c.checkWrites(target, specs)
+	if err != nil {
+		return err
+	}
+	npdu := NPDU{
+		Version:  Version1,
+		Priority: Normal,
+		HopCount: 255,
+		ADPU: &APDU{
+			DataType:    UnconfirmedServiceRequest,
+			ServiceType: ServiceUnconfirmedWriteGroup,
+			Payload:     &w,
+		},
+	}
+	if device == nil {
+		_, err = c.broadcast(npdu)
+		return err
+	}
+	npdu.Destination = &device.Addr
+	npdu.Source = bacnet.AddressFromUDP(net.UDPAddr{
+		IP:   c.ipAddress,
+		Port: c.udpPort,
+	})
+	_, err = c.send(npdu)
+	return err
+}
@@ -0,0 +1,110 @@
+package bacip
+
+import (
+	"encoding/hex"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/REQUEA/bacnet"
+	"github.com/REQUEA/bacnet/internal/encoding"
+
+	"github.com/matryer/is"
+)
+
+func TestChannelValueCoherency(t *testing.T) {
+	level := float32(100)
+	ttable := []struct {
+		value   ChannelValue
+		encoded string
+	}{
+		{
+			value:   ChannelValue{Value: bacnet.PropertyValue{Type: bacnet.TypeReal, Value: float32(50)}},
+			encoded: "4442480000",
+		},
+		{
+			value:   ChannelValue{Value: bacnet.PropertyValue{Type: bacnet.TypeNull}},
+			encoded: "00",
+		},
+		{
+			value: ChannelValue{LightingCommand: &LightingCommand{
+				Operation:   bacnet.LightingOperationFadeTo,
+				TargetLevel: &level,
+			}},
+			encoded: "0e09011c42c800000f",
+		},
+	}
+	for _, tt := range ttable {
+		t.Run(tt.encoded, func(t *testing.T) {
+			is := is.New(t)
+			b, err := tt.value.MarshalBinary()
+			is.NoErr(err)
+			is.Equal(hex.EncodeToString(b), tt.encoded)
+			v := ChannelValue{}
+			is.NoErr(v.UnmarshalBinary(b))
+			is.Equal(v, tt.value)
+		})
+	}
+}
+
+func TestGroupChannelValueCoherency(t *testing.T) {
+	is := is.New(t)
+	v := GroupChannelValue{
+		Channel:            3,
+		OverridingPriority: bacnet.ManualOperator8,
+		Value:              ChannelValue{Value: bacnet.PropertyValue{Type: bacnet.TypeEnumerated, Value: uint32(1)}},
+	}
+	e := encoding.NewEncoder()
+	is.NoErr(v.encode(&e))
+	is.NoErr(e.Error())
+	is.Equal(hex.EncodeToString(e.Bytes()), "090319089101")
+	v2 := GroupChannelValue{}
+	is.NoErr(v2.decode(encoding.NewDecoder(e.Bytes())))
+	is.Equal(v2, v)
+}
+
+func TestWriteGroup(t *testing.T) {
+	is := is.New(t)
+	inhibit := true
+	w := WriteGroup{
+		Group:    5,
+		Priority: bacnet.ManualOperator8,
+		Changes: []GroupChannelValue{{
+			Channel: 3,
+			Value:   ChannelValue{Value: bacnet.PropertyValue{Type: bacnet.TypeEnumerated, Value: uint32(1)}},
+		}},
+		InhibitDelay: &inhibit,
+	}
+	b, err := w.MarshalBinary()
+	is.NoErr(err)
+	is.Equal(hex.EncodeToString(b), "090519082e090391012f3901")
+	w2 := WriteGroup{}
+	is.NoErr(w2.UnmarshalBinary(b))
+	is.Equal(w2, w)
+	_, err = WriteGroup{Priority: bacnet.ManualOperator8}.MarshalBinary()
+	is.True(err != nil)
+	_, err = WriteGroup{Group: 5}.MarshalBinary()
+	is.True(err != nil)
+
+	m := newMemTransport()
+	c := newMemClient(t, m)
+	device := bacnet.Device{
+		ID:   bacnet.ObjectID{Type: bacnet.BacnetDevice, Instance: 3},
+		Addr: *bacnet.AddressFromUDP(net.UDPAddr{IP: net.IPv4(10, 0, 2, 3).To4(), Port: DefaultUDPPort}),
+	}
+	is.NoErr(c.WriteGroup(&device, w))
+	is.Equal(m.count(), 1)
+	m.Lock()
+	var sent BVLC
+	is.NoErr(sent.UnmarshalBinary(m.written[0]))
+	m.Unlock()
+	is.Equal(sent.NPDU.ADPU.ServiceType, ServiceUnconfirmedWriteGroup)
+	is.Equal(sent.NPDU.ADPU.Payload, &w)
+
+	//The changes are denied by the write policy like writes of the
+	//channels
+	c.SetWritePolicy(&WritePolicy{MaxPriority: bacnet.ManualOperator8 - 1})
+	err = c.WriteGroup(nil, w)
+	is.True(errors.Is(err, ErrWriteDenied))
+	is.Equal(m.count(), 1)
+}
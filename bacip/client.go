@@ -20,9 +20,30 @@ type Client struct {
 	ipAddress        net.IP
 	broadcastAddress net.IP
 	udpPort          int
-	udp              *net.UDPConn
+	udp              Transport
 	subscriptions    *Subscriptions
 	transactions     *Transactions
+	whoIs            *whoIsCoalescer
+	iamThrottle      *iamThrottle
+	readCache        atomic.Value
+	writePolicy      atomic.Value
+	profiles         profiles
+	foreignMutex     sync.Mutex
+	foreign          *foreignDevice
+	decodeErrors     decodeErrors
+	strictDecoding   atomic.Bool
+	forwarders       forwarders
+	registry         deviceRegistry
+	localDevice      atomic.Value
+	txHook           atomic.Value
+	textHook         atomic.Value
+	eventHook        atomic.Value
+	whoAmIHook       atomic.Value
+	timeSyncMode     atomic.Value
+	bdt              atomic.Value
+	covs             covSubscriptions
+	dedup            unconfirmedDedup
+	segments         reassembly
 	logger           Logger
 	runFlag          atomic.Bool
 	wg               sync.WaitGroup
@@ -38,9 +59,40 @@ type NoOpLogger struct{}
 func (NoOpLogger) Info(...interface{})  {}
 func (NoOpLogger) Error(...interface{}) {}
 
+// Transport sends and receives the UDP datagrams of a client. It is
+// implemented by *net.UDPConn, and can be replaced by a wrapper to
+// alter the traffic, for instance in tests
+type Transport interface {
+	ReadFromUDP(b []byte) (int, *net.UDPAddr, error)
+	WriteToUDP(b []byte, addr *net.UDPAddr) (int, error)
+	LocalAddr() net.Addr
+	Close() error
+}
+
+// Subscriptions are the functions called for every message
+// received. They must not block
 type Subscriptions struct {
 	sync.RWMutex
-	f func(BVLC, net.UDPAddr)
+	next int
+	subs map[int]func(BVLC, net.UDPAddr)
+}
+
+// subscribe adds f to the subscriptions and returns the function
+// removing it
+func (s *Subscriptions) subscribe(f func(BVLC, net.UDPAddr)) func() {
+	s.Lock()
+	defer s.Unlock()
+	if s.subs == nil {
+		s.subs = map[int]func(BVLC, net.UDPAddr){}
+	}
+	id := s.next
+	s.next++
+	s.subs[id] = f
+	return func() {
+		s.Lock()
+		defer s.Unlock()
+		delete(s.subs, id)
+	}
 }
 
 const DefaultUDPPort = 47808
@@ -58,8 +110,22 @@ func broadcastAddr(n *net.IPNet) (net.IP, error) {
 // NewClient creates a new bacnet client. It binds on the given port
 // and network interface or cidr addr. If Port is 0, a random port is used
 func NewClient(netInterface string, port int, logger Logger) (*Client, error) {
+	return NewClientWithTransport(netInterface, func(port int) (Transport, error) {
+		return net.ListenUDP("udp4", &net.UDPAddr{
+			IP:   net.IPv4zero,
+			Port: port,
+		})
+	}, port, logger)
+}
+
+// NewClientWithTransport is like NewClient, but the datagrams are
+// sent and received with the transport returned by listen, which is
+// called with the port to bind on
+func NewClientWithTransport(netInterface string, listen func(port int) (Transport, error), port int, logger Logger) (*Client, error) {
 	c := &Client{subscriptions: &Subscriptions{},
 		transactions: NewTransactions(),
+		whoIs:        &whoIsCoalescer{window: DefaultWhoIsCoalescing},
+		iamThrottle:  newIAmThrottle(),
 		logger:       logger,
 		runFlag:      atomic.Bool{},
 		wg:           sync.WaitGroup{},
@@ -87,10 +153,7 @@ func NewClient(netInterface string, port int, logger Logger) (*Client, error) {
 	if c.ipAddress == nil {
 		return nil, fmt.Errorf("no IPv4 address assigned to interface %s", netInterface)
 	}
-	conn, err := net.ListenUDP("udp4", &net.UDPAddr{
-		IP:   net.IPv4zero,
-		Port: port,
-	})
+	conn, err := listen(port)
 	if err != nil {
 		return nil, err
 	}
@@ -127,7 +190,11 @@ func (c *Client) listen() {
 		b := make([]byte, 2048)
 		i, addr, err := c.udp.ReadFromUDP(b)
 		if err != nil {
+			if !c.runFlag.Load() {
+				return
+			}
 			c.logger.Error(err.Error())
+			continue
 		}
 		go func() {
 			defer func() {
@@ -144,34 +211,86 @@ func (c *Client) listen() {
 }
 
 func (c *Client) Close() error {
+	c.stopForeignDevice()
 	c.runFlag.Store(false)
+	//Closing the transport unblocks the pending read
+	err := c.udp.Close()
 	c.wg.Wait()
-	return c.udp.Close()
+	return err
 }
 
 func (c *Client) handleMessage(src *net.UDPAddr, b []byte) error {
 	var bvlc BVLC
-	err := bvlc.UnmarshalBinary(b)
-	if err != nil && errors.Is(err, ErrNotBAcnetIP) {
-		return err
+	header, err := bvlc.unmarshal(b)
+	if err != nil {
+		c.decodeErrors.add(src, b, err)
+		if errors.Is(err, ErrNotBAcnetIP) {
+			return err
+		}
+	}
+	if err == nil {
+		c.checkCanonical(src, bvlc, b, header)
+	}
+	if bvlc.Origin != nil {
+		//The message was forwarded by a BBMD
+		c.forwarders.add(*src, time.Now())
+		src = bvlc.Origin
 	}
 	apdu := bvlc.NPDU.ADPU
-	if apdu == nil {
-		c.logger.Info(fmt.Sprintf("Received network packet %+v", bvlc.NPDU))
+	if apdu != nil && err == nil && c.dedup.duplicate(src, apdu, time.Now()) {
 		return nil
 	}
+	if apdu != nil && apdu.DataType == UnconfirmedServiceRequest && apdu.ServiceType == ServiceUnconfirmedIAm {
+		if iam, ok := apdu.Payload.(*Iam); ok {
+			c.whoIs.record(*iam, SourceAddress(bvlc, *src), time.Now())
+		}
+	}
+	if apdu != nil && err == nil {
+		c.bind(apdu, SourceAddress(bvlc, *src))
+		if apdu.DataType == UnconfirmedServiceRequest && apdu.ServiceType == ServiceUnconfirmedWhoIs {
+			c.answerWhoIs(bvlc, src)
+		}
+		if _, ok := apdu.Payload.(*COVNotification); ok {
+			c.handleCOVNotification(bvlc, src)
+		}
+		if _, ok := apdu.Payload.(*TextMessage); ok {
+			c.handleTextMessage(bvlc, src)
+		}
+		if _, ok := apdu.Payload.(*EventNotification); ok {
+			c.handleEventNotification(bvlc, src)
+		}
+		if _, ok := apdu.Payload.(*WhoAmI); ok {
+			c.handleWhoAmI(bvlc, src)
+		}
+	}
 	c.subscriptions.RLock()
-	if c.subscriptions.f != nil {
-		//If f block, there is a deadlock here
-		c.subscriptions.f(bvlc, *src)
+	for _, f := range c.subscriptions.subs {
+		f(bvlc, *src)
 	}
 	c.subscriptions.RUnlock()
-	if apdu.DataType == ComplexAck || apdu.DataType == SimpleAck || apdu.DataType == Error {
-		invokeID := bvlc.NPDU.ADPU.InvokeID
+	if apdu == nil {
+		if bvlc.Function.hasNPDU() {
+			c.logger.Info(fmt.Sprintf("Received network packet %+v", bvlc.NPDU))
+		}
+		return nil
+	}
+	if apdu.DataType == ComplexAck && apdu.Segmented {
+		apdu, err = c.handleSegment(bvlc, src)
+		if err != nil || apdu == nil {
+			return err
+		}
+	}
+	if apdu.DataType == ComplexAck || apdu.DataType == SimpleAck || apdu.DataType == Error ||
+		apdu.DataType == Reject || apdu.DataType == Abort || apdu.DataType == SegmentAck {
+		invokeID := apdu.InvokeID
 		tx, ok := c.transactions.GetTransaction(invokeID)
 		if !ok {
 			return fmt.Errorf("no transaction found for id %d", invokeID)
 		}
+		if tx.future != nil {
+			tx.future.deliver(*apdu)
+			return nil
+		}
 		select {
 		case tx.APDU <- *apdu:
 			return nil
@@ -196,46 +315,100 @@ func (c *Client) WhoIs(data WhoIs, timeout time.Duration) ([]bacnet.Device, erro
 			Payload:     &data,
 		},
 	}
+	return c.collectIAms(data, timeout, func(set map[Iam]bacnet.Address) error {
+		low, high := whoIsRange(data)
+		now := time.Now()
+		if since, ok := c.whoIs.covering(low, high, now); ok {
+			//A broadcast for these devices was sent recently, reuse
+			//its answers instead of sending a new one
+			for _, r := range c.whoIs.received(since) {
+				if r.iam.ObjectID.Instance >= bacnet.ObjectInstance(low) &&
+					r.iam.ObjectID.Instance <= bacnet.ObjectInstance(high) {
+					set[r.iam] = r.addr
+				}
+			}
+			return nil
+		}
+		c.whoIs.sent(low, high, now)
+		_, err := c.broadcast(npdu)
+		return err
+	})
+}
 
+// WhoIsAt sends the WhoIs to the destination instead of the local
+// network, and returns the devices which answered within the timeout.
+// The destination is either:
+//   - the IP address of a device, or the directed broadcast address of
+//     another IP subnet
+//   - a remote network behind the router at the IP address, as
+//     returned by RoutedAddress with an empty MAC address, or a device
+//     on the remote network
+//   - all the networks, with the network bacnet.GlobalNetwork
+//
+// Without IP address, the request is broadcast on the local network
+// for its routers to forward it to the remote network
+func (c *Client) WhoIsAt(dest bacnet.Address, data WhoIs, timeout time.Duration) ([]bacnet.Device, error) {
+	npdu := NPDU{
+		Version:  Version1,
+		Priority: Normal,
+		HopCount: 255,
+		ADPU: &APDU{
+			DataType:    UnconfirmedServiceRequest,
+			ServiceType: ServiceUnconfirmedWhoIs,
+			Payload:     &data,
+		},
+	}
+	return c.collectIAms(data, timeout, func(map[Iam]bacnet.Address) error {
+		var err error
+		if len(dest.Mac) == 0 {
+			if dest.Net != 0 {
+				npdu.Destination = &dest
+			}
+			_, err = c.broadcast(npdu)
+		} else {
+			npdu.Destination = &dest
+			_, err = c.send(npdu)
+		}
+		return err
+	})
+}
+
+// collectIAms sends a WhoIs with send, and collects the IAm of the
+// devices in its range received until the timeout. send may add
+// devices already known to the set
+func (c *Client) collectIAms(data WhoIs, timeout time.Duration, send func(set map[Iam]bacnet.Address) error) ([]bacnet.Device, error) {
 	rChan := make(chan struct {
 		bvlc BVLC
 		src  net.UDPAddr
 	})
-	c.subscriptions.Lock()
-	//TODO:  add errgroup ?, ensure all f are done and not blocked
-	c.subscriptions.f = func(bvlc BVLC, src net.UDPAddr) {
-		rChan <- struct {
+	done := make(chan struct{})
+	unsubscribe := c.subscriptions.subscribe(func(bvlc BVLC, src net.UDPAddr) {
+		select {
+		case rChan <- struct {
 			bvlc BVLC
 			src  net.UDPAddr
 		}{
 			bvlc: bvlc,
 			src:  src,
+		}:
+		case <-done:
 		}
-	}
-	c.subscriptions.Unlock()
-	defer func() {
-		c.subscriptions.f = nil
-	}()
-	_, err := c.broadcast(npdu)
-	if err != nil {
+	})
+	defer unsubscribe()
+	defer close(done)
+	//Use a set to deduplicate results
+	set := map[Iam]bacnet.Address{}
+	if err := send(set); err != nil {
 		return nil, err
 	}
 	timer := time.NewTimer(timeout)
 	defer timer.Stop()
-	//Use a set to deduplicate results
-	set := map[Iam]bacnet.Address{}
 	for {
 		select {
 		case <-timer.C:
 			result := []bacnet.Device{}
 			for iam, addr := range set {
-				result = append(result, bacnet.Device{
-					ID:           iam.ObjectID,
-					MaxApdu:      iam.MaxApduLength,
-					Segmentation: iam.SegmentationSupport,
-					Vendor:       iam.VendorID,
-					Addr:         addr,
-				})
+				result = append(result, deviceOf(iam, addr))
 			}
 			return result, nil
 		case r := <-rChan:
@@ -256,12 +429,10 @@ func (c *Client) WhoIs(data WhoIs, timeout time.Duration) ([]bacnet.Device, erro
 					if data.High != nil && data.Low != nil {
 						if iam.ObjectID.Instance >= bacnet.ObjectInstance(*data.Low) &&
 							iam.ObjectID.Instance <= bacnet.ObjectInstance(*data.High) {
-							addr := bacnet.AddressFromUDP(r.src)
-							set[*iam] = *addr
+							set[*iam] = SourceAddress(r.bvlc, r.src)
 						}
 					} else {
-						addr := bacnet.AddressFromUDP(r.src)
-						set[*iam] = *addr
+						set[*iam] = SourceAddress(r.bvlc, r.src)
 					}
 
 				}
@@ -271,53 +442,171 @@ func (c *Client) WhoIs(data WhoIs, timeout time.Duration) ([]bacnet.Device, erro
 }
 
 func (c *Client) ReadProperty(ctx context.Context, device bacnet.Device, readProp ReadProperty) (interface{}, error) {
-	invokeID := c.transactions.GetID()
-	defer c.transactions.FreeID(invokeID)
-	npdu := NPDU{
-		Version:               Version1,
-		IsNetworkLayerMessage: false,
-		ExpectingReply:        true,
-		Priority:              Normal,
-		Destination:           &device.Addr,
-		Source: bacnet.AddressFromUDP(net.UDPAddr{
-			IP:   c.ipAddress,
-			Port: c.udpPort,
-		}),
-		HopCount: 255,
-		ADPU: &APDU{
-			DataType:    ConfirmedServiceRequest,
-			ServiceType: ServiceConfirmedReadProperty,
-			InvokeID:    invokeID,
-			Payload:     &readProp,
-		},
+	rc := c.cache()
+	if device.ID == bacnet.WildcardDevice || readProp.ObjectID == bacnet.WildcardDevice {
+		//Any device may answer to the wildcard
+		rc = nil
 	}
-	rChan := make(chan APDU)
-	c.transactions.SetTransaction(invokeID, rChan, ctx)
-	defer c.transactions.StopTransaction(invokeID)
-	_, err := c.send(npdu)
+	if rc != nil {
+		if v, ok := rc.get(device.ID, readProp.ObjectID, readProp.Property); ok {
+			return v, nil
+		}
+	}
+	if readProp.Property.ArrayIndex != nil && c.pacer(device.ID).profile.NoArrayIndex {
+		return c.readWholeArray(ctx, device, readProp)
+	}
+	apdu, err := c.confirmedRequest(ctx, device, ServiceConfirmedReadProperty, &readProp)
 	if err != nil {
 		return nil, err
 	}
-	select {
-	case apdu := <-rChan:
-		//Todo: ensure response validity, ensure conversion cannot panic
-		if apdu.DataType == Error {
-			return nil, *apdu.Payload.(*ApduError)
+	//Todo: ensure response validity, ensure conversion cannot panic
+	if apdu.DataType == ComplexAck && apdu.ServiceType == ServiceConfirmedReadProperty {
+		data := apdu.Payload.(*ReadProperty).Data
+		if rc != nil {
+			rc.put(device.ID, readProp.ObjectID, readProp.Property, data)
 		}
-		if apdu.DataType == ComplexAck && apdu.ServiceType == ServiceConfirmedReadProperty {
-			data := apdu.Payload.(*ReadProperty).Data
-			return data, nil
-		}
-		return nil, errors.New("invalid answer")
-	case <-ctx.Done():
-		return nil, ctx.Err()
+		return data, nil
 	}
+	return nil, errors.New("invalid answer")
 }
 
+// readRaw reads a property and returns its encoded value, to be
+// decoded by helpers of constructed properties
+func (c *Client) readRaw(ctx context.Context, device bacnet.Device, readProp ReadProperty) ([]byte, error) {
+	apdu, err := c.confirmedRequest(ctx, device, ServiceConfirmedReadProperty, &readProp)
+	if err != nil {
+		return nil, err
+	}
+	if apdu.DataType == ComplexAck && apdu.ServiceType == ServiceConfirmedReadProperty {
+		return apdu.Payload.(*ReadProperty).raw, nil
+	}
+	return nil, errors.New("invalid answer")
+}
+
+// WriteProperty writes the value to the property. The values of the
+// standard properties whose datatype is known are converted to it, or
+// rejected with a DatatypeError before being sent
 func (c *Client) WriteProperty(ctx context.Context, device bacnet.Device, writeProp WriteProperty) error {
+	payload, writes, err := c.prepareWrite(device.ID, &writeProp)
+	if err != nil {
+		return err
+	}
+	//Even a failed write may have changed the value
+	defer c.invalidateWrites(device.ID, writes)
+	apdu, err := c.confirmedRequest(ctx, device, ServiceConfirmedWriteProperty, payload)
+	if err != nil {
+		return err
+	}
+	if apdu.DataType == SimpleAck {
+		return nil
+	}
+	return errors.New("invalid answer")
+}
+
+// prepareWrite checks the writes of a WriteProperty or
+// WritePropertyMultiple payload against the write policy, and returns
+// a copy of the payload whose values are converted to the datatypes of
+// the properties, with its writes. Other payloads are returned as is,
+// once checked by checkRequest
+func (c *Client) prepareWrite(device bacnet.ObjectID, payload Payload) (Payload, []WriteAccessSpecification, error) {
+	var specs []WriteAccessSpecification
+	switch p := payload.(type) {
+	case *WriteProperty:
+		specs = []WriteAccessSpecification{{
+			ObjectID: p.ObjectID,
+			Properties: []PropertyWrite{{
+				Property: p.Property,
+				Value:    p.PropertyValue,
+				Priority: p.Priority,
+			}},
+		}}
+	case *WritePropertyMultiple:
+		specs = p.Specifications
+	default:
+		err := c.checkRequest(device, payload)
+		if err != nil {
+			return nil, nil, err
+		}
+		return payload, nil, nil
+	}
+	err := c.checkWrites(device, specs)
+	if err != nil {
+		return nil, nil, err
+	}
+	specs, err = coerceWrites(specs)
+	if err != nil {
+		return nil, nil, err
+	}
+	if p, ok := payload.(*WriteProperty); ok {
+		wp := *p
+		wp.PropertyValue = specs[0].Properties[0].Value
+		return &wp, specs, nil
+	}
+	return &WritePropertyMultiple{Specifications: specs}, specs, nil
+}
+
+// invalidateWrites removes the properties written from the read cache
+func (c *Client) invalidateWrites(device bacnet.ObjectID, specs []WriteAccessSpecification) {
+	rc := c.cache()
+	if rc == nil {
+		return
+	}
+	for _, s := range specs {
+		for _, p := range s.Properties {
+			rc.invalidateProperty(device, s.ObjectID, p.Property.Type)
+		}
+	}
+}
+
+// request is a confirmed service request ready to be sent to a device
+type request struct {
+	device   bacnet.Device
+	service  ServiceType
+	npdu     NPDU
+	segments [][]byte
+	invokeID byte
+	pc       *pacer
+	release  func()
+	ev       TransactionEvent
+	start    time.Time
+}
+
+// newRequest waits for the tenant and the pacer of the device to allow
+// a new request, and prepares it. free must be called once it's done
+func (c *Client) newRequest(ctx context.Context, device bacnet.Device, service ServiceType, payload Payload) (*request, error) {
+	tenant := tenantFrom(ctx)
+	if tenant != nil {
+		err := tenant.acquire(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+	pc := c.pacer(device.ID)
+	release, err := pc.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	device = pc.profile.apply(device)
+	segments, err := segmentRequest(device, payload)
+	if err != nil {
+		release()
+		return nil, err
+	}
 	invokeID := c.transactions.GetID()
-	defer c.transactions.FreeID(invokeID)
-	npdu := NPDU{
+	r := &request{
+		device:   device,
+		service:  service,
+		segments: segments,
+		invokeID: invokeID,
+		pc:       pc,
+		release:  release,
+		ev:       TransactionEvent{InvokeID: invokeID, Service: service, Device: device, tenant: tenant},
+		start:    time.Now(),
+	}
+	if tenant != nil {
+		r.ev.Tenant = tenant.name
+	}
+	r.npdu = NPDU{
 		Version:               Version1,
 		IsNetworkLayerMessage: false,
 		ExpectingReply:        true,
@@ -329,62 +618,215 @@ func (c *Client) WriteProperty(ctx context.Context, device bacnet.Device, writeP
 		}),
 		HopCount: 255,
 		ADPU: &APDU{
-			DataType:    ConfirmedServiceRequest,
-			ServiceType: ServiceConfirmedWriteProperty,
-			InvokeID:    invokeID,
-			Payload:     &writeProp,
+			DataType:                  ConfirmedServiceRequest,
+			ServiceType:               service,
+			InvokeID:                  invokeID,
+			SegmentedResponseAccepted: true,
+			Payload:                   payload,
 		},
 	}
+	return r, nil
+}
+
+// free gives back the invoke ID and the slot of the request
+func (r *request) free(c *Client) {
+	c.transactions.FreeID(r.invokeID)
+	r.release()
+}
+
+// sendFailure returns the event of a request which couldn't be sent
+func sendFailure(ctx context.Context, err error) TransactionEventType {
+	switch {
+	case ctx.Err() != nil && !errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return TransactionAborted
+	case errors.Is(err, context.DeadlineExceeded):
+		//The segments weren't acknowledged in time
+		return TransactionTimedOut
+	default:
+		return TransactionErrored
+	}
+}
+
+// answerError returns the error sent by the device in its answer, if
+// any, and the event of the answer
+func answerError(apdu APDU) (TransactionEventType, error) {
+	switch apdu.DataType {
+	case Error:
+		switch p := apdu.Payload.(type) {
+		case *ApduError:
+			return TransactionErrored, *p
+		case *WritePropertyMultipleError:
+			return TransactionErrored, *p
+		case *CreateObjectError:
+			return TransactionErrored, *p
+		case *PrivateTransferError:
+			return TransactionErrored, *p
+		}
+	case Reject:
+		return TransactionErrored, RejectError{Reason: RejectReason(apdu.ServiceType)}
+	case Abort:
+		return TransactionAborted, AbortError{Reason: AbortReason(apdu.ServiceType)}
+	}
+	return TransactionAcked, nil
+}
+
+// confirmedRequest sends a confirmed service request to the device
+// and waits for its answer. Error answers are returned as ApduError
+func (c *Client) confirmedRequest(ctx context.Context, device bacnet.Device, service ServiceType, payload Payload) (APDU, error) {
+	r, err := c.newRequest(ctx, device, service, payload)
+	if err != nil {
+		return APDU{}, err
+	}
+	defer r.free(c)
+	invokeID, pc, ev := r.invokeID, r.pc, r.ev
 	rChan := make(chan APDU)
 	c.transactions.SetTransaction(invokeID, rChan, ctx)
+	c.transactions.describe(invokeID, service, r.device.Addr)
 	defer c.transactions.StopTransaction(invokeID)
-	_, err := c.send(npdu)
-	if err != nil {
-		return err
-	}
-	select {
-	case apdu := <-rChan:
-		//Todo: ensure response validity, ensure conversion cannot panic
-		if apdu.DataType == Error {
-			return *apdu.Payload.(*ApduError)
+	defer c.segments.remove(invokeID)
+	for attempt := 1; ; attempt++ {
+		ev.Attempt = attempt
+		sent := time.Now()
+		answers := (<-chan APDU)(rChan)
+		if r.segments != nil {
+			var early *APDU
+			early, err = c.sendSegments(ctx, r.npdu, r.segments, rChan, pc)
+			if early != nil {
+				//The device answered before the last segment
+				answered := make(chan APDU, 1)
+				answered <- *early
+				answers = answered
+			}
+		} else {
+			_, err = c.send(r.npdu)
 		}
-		if apdu.DataType == SimpleAck {
-			return nil
+		if err != nil {
+			c.emit(ev, sendFailure(ctx, err), r.start, sent, err)
+			return APDU{}, err
+		}
+		if attempt == 1 {
+			c.emit(ev, TransactionSent, r.start, sent, nil)
+		} else {
+			c.emit(ev, TransactionRetried, r.start, sent, nil)
+		}
+		var timeout <-chan time.Time
+		var timer *time.Timer
+		if d := pc.timeout(); d > 0 {
+			timer = time.NewTimer(d)
+			timeout = timer.C
+		}
+	wait:
+		select {
+		case apdu := <-answers:
+			if apdu.DataType == SegmentAck {
+				//Late ack of the segments of the request
+				goto wait
+			}
+			if timer != nil {
+				timer.Stop()
+			}
+			if attempt == 1 && r.segments == nil {
+				pc.sample(time.Since(sent))
+			}
+			typ, err := answerError(apdu)
+			c.emit(ev, typ, r.start, sent, err)
+			return apdu, err
+		case <-timeout:
+			if d := pc.timeout(); c.segments.receiving(invokeID, d) {
+				//The answer is segmented, its next segments are
+				//still coming
+				timer.Reset(d)
+				goto wait
+			}
+			if attempt <= pc.profile.Retries {
+				continue
+			}
+			c.emit(ev, TransactionTimedOut, r.start, sent, context.DeadlineExceeded)
+			return APDU{}, context.DeadlineExceeded
+		case <-ctx.Done():
+			if timer != nil {
+				timer.Stop()
+			}
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				c.emit(ev, TransactionTimedOut, r.start, sent, ctx.Err())
+			} else {
+				c.emit(ev, TransactionAborted, r.start, sent, ctx.Err())
+			}
+			return APDU{}, ctx.Err()
 		}
-		return errors.New("invalid answer")
-	case <-ctx.Done():
-		return ctx.Err()
 	}
 }
 
 func (c *Client) send(npdu NPDU) (int, error) {
-	bytes, err := BVLC{
-		Type:     TypeBacnetIP,
-		Function: BacFuncUnicast,
-		NPDU:     npdu,
-	}.MarshalBinary()
+	return c.Transmit(BacFuncUnicast, nil, npdu)
+}
+
+func (c *Client) broadcast(npdu NPDU) (int, error) {
+	bbmd, err := c.foreignBBMD()
 	if err != nil {
 		return 0, err
 	}
-	if npdu.Destination == nil {
-		return 0, fmt.Errorf("destination bacnet address should be not nil to send unicast")
+	if bbmd != nil {
+		return c.Transmit(BacFuncDistributeBroadcastToNetwork, bbmd, npdu)
 	}
-	addr := bacnet.UDPFromAddress(*npdu.Destination)
-	return c.udp.WriteToUDP(bytes, &addr)
-
+	return c.Transmit(BacFuncBroadcast, nil, npdu)
 }
 
-func (c *Client) broadcast(npdu NPDU) (int, error) {
+// Transmit sends the NPDU with the BVLC function, to addr or if it's
+// nil to:
+//   - the IP address of the destination of the NPDU for
+//     BacFuncUnicast
+//   - the broadcast address of the local network for BacFuncBroadcast.
+//     If the client is a BBMD, the broadcast is also forwarded to the
+//     BBMDs of its broadcast distribution table
+//   - the BBMD the client is registered with as a foreign device for
+//     BacFuncDistributeBroadcastToNetwork
+//
+// The requests of the client choose their function themselves. This
+// is for the messages built by the application, such as a broadcast
+// sent through a BBMD the client isn't registered with
+func (c *Client) Transmit(function Function, addr *net.UDPAddr, npdu NPDU) (int, error) {
+	local := false
+	switch function {
+	case BacFuncUnicast:
+		if addr == nil {
+			if npdu.Destination == nil {
+				return 0, fmt.Errorf("destination bacnet address should be not nil to send unicast")
+			}
+			dest := bacnet.UDPFromAddress(*npdu.Destination)
+			addr = &dest
+		}
+	case BacFuncBroadcast:
+		if addr == nil {
+			addr = &net.UDPAddr{IP: c.broadcastAddress, Port: DefaultUDPPort}
+			local = true
+		}
+	case BacFuncDistributeBroadcastToNetwork:
+		if addr == nil {
+			bbmd, err := c.foreignBBMD()
+			if err != nil {
+				return 0, err
+			}
+			if bbmd == nil {
+				return 0, errors.New("not a foreign device")
+			}
+			addr = bbmd
+		}
+	default:
+		return 0, fmt.Errorf("can't transmit a NPDU with BVLC function %v", function)
+	}
 	bytes, err := BVLC{
 		Type:     TypeBacnetIP,
-		Function: BacFuncBroadcast,
+		Function: function,
 		NPDU:     npdu,
 	}.MarshalBinary()
 	if err != nil {
 		return 0, err
 	}
-	return c.udp.WriteToUDP(bytes, &net.UDPAddr{
-		IP:   c.broadcastAddress,
-		Port: DefaultUDPPort,
-	})
+	c.dedup.sent(npdu.ADPU, time.Now())
+	n, err := c.udp.WriteToUDP(bytes, addr)
+	if err != nil || !local {
+		return n, err
+	}
+	return n, c.distributeBroadcast(npdu)
 }
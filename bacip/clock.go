@@ -0,0 +1,77 @@
+package bacip
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/REQUEA/bacnet"
+)
+
+// DeviceLocation reads the UTC_Offset and Daylight_Savings_Status
+// properties of the device and returns its time zone, to convert the
+// local date times of its trend logs and schedules. Daylight saving
+// time is considered inactive if the device doesn't have the property
+func (c *Client) DeviceLocation(ctx context.Context, device bacnet.Device) (*time.Location, error) {
+	v, err := c.ReadProperty(ctx, device, ReadProperty{
+		ObjectID: device.ID,
+		Property: bacnet.PropertyIdentifier{Type: bacnet.UtcOffset},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("read utc offset: %w", err)
+	}
+	var offset int
+	switch v := v.(type) {
+	case int32:
+		offset = int(v)
+	case uint32:
+		//Some devices send positive offsets as unsigned
+		offset = int(v)
+	default:
+		return nil, fmt.Errorf("unexpected utc offset type %T", v)
+	}
+	if offset < -780 || offset > 780 {
+		return nil, fmt.Errorf("invalid utc offset %d", offset)
+	}
+	v, err = c.ReadProperty(ctx, device, ReadProperty{
+		ObjectID: device.ID,
+		Property: bacnet.PropertyIdentifier{Type: bacnet.DaylightSavingsStatus},
+	})
+	if err != nil && !isUnknownProperty(err) {
+		return nil, fmt.Errorf("read daylight savings status: %w", err)
+	}
+	dst, _ := v.(bool)
+	return bacnet.DeviceLocation(offset, dst), nil
+}
+
+// DeviceTime reads the Local_Date and Local_Time properties of the
+// device and returns its wall clock time in its time zone
+func (c *Client) DeviceTime(ctx context.Context, device bacnet.Device) (time.Time, error) {
+	loc, err := c.DeviceLocation(ctx, device)
+	if err != nil {
+		return time.Time{}, err
+	}
+	v, err := c.ReadProperty(ctx, device, ReadProperty{
+		ObjectID: device.ID,
+		Property: bacnet.PropertyIdentifier{Type: bacnet.LocalDate},
+	})
+	if err != nil {
+		return time.Time{}, fmt.Errorf("read local date: %w", err)
+	}
+	date, ok := v.(bacnet.Date)
+	if !ok || !date.Specified() {
+		return time.Time{}, fmt.Errorf("invalid local date %v", v)
+	}
+	v, err = c.ReadProperty(ctx, device, ReadProperty{
+		ObjectID: device.ID,
+		Property: bacnet.PropertyIdentifier{Type: bacnet.LocalTime},
+	})
+	if err != nil {
+		return time.Time{}, fmt.Errorf("read local time: %w", err)
+	}
+	tod, ok := v.(bacnet.Time)
+	if !ok {
+		return time.Time{}, fmt.Errorf("invalid local time %v", v)
+	}
+	return bacnet.DateTime{Date: date, Time: tod}.ToTime(loc), nil
+}
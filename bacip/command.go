@@ -0,0 +1,32 @@
+package bacip
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/REQUEA/bacnet"
+)
+
+// WriteAtPriority commands the present value of an object: the value
+// is written in the slot of the priority array of the priority, from 1
+// (manual life safety) to 16 (lowest). The object takes the value of
+// the highest priority slot in use, so the command must be released
+// with Relinquish when it's not needed anymore
+func (c *Client) WriteAtPriority(ctx context.Context, device bacnet.Device, object bacnet.ObjectID, value bacnet.PropertyValue, priority bacnet.PriorityList) error {
+	if priority < bacnet.ManualLifeSafety1 || priority > bacnet.Available16 {
+		return fmt.Errorf("invalid priority %d", priority)
+	}
+	return c.WriteProperty(ctx, device, WriteProperty{
+		ObjectID:      object,
+		Property:      bacnet.PropertyIdentifier{Type: bacnet.PresentValue},
+		PropertyValue: value,
+		Priority:      priority,
+	})
+}
+
+// Relinquish releases the command of the present value of an object at
+// the priority, by writing a Null in its slot. The object then takes
+// the value of the next priority in use, or its relinquish default
+func (c *Client) Relinquish(ctx context.Context, device bacnet.Device, object bacnet.ObjectID, priority bacnet.PriorityList) error {
+	return c.WriteAtPriority(ctx, device, object, bacnet.PropertyValue{Type: bacnet.TypeNull}, priority)
+}
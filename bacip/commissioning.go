@@ -0,0 +1,255 @@
+package bacip
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/REQUEA/bacnet"
+)
+
+// DefaultConfigProperties are the properties read by DumpConfig when
+// none are given: the configuration of the objects, without their
+// live values
+var DefaultConfigProperties = []bacnet.PropertyType{
+	bacnet.ObjectName,
+	bacnet.Description,
+	bacnet.Units,
+	bacnet.Resolution,
+	bacnet.MinPresValue,
+	bacnet.MaxPresValue,
+	bacnet.CovIncrement,
+	bacnet.HighLimit,
+	bacnet.LowLimit,
+	bacnet.Deadband,
+	bacnet.LimitEnable,
+	bacnet.EventEnable,
+	bacnet.NotifyType,
+	bacnet.NotificationClassProp,
+	bacnet.TimeDelay,
+	bacnet.AlarmValue,
+	bacnet.RelinquishDefault,
+	bacnet.Polarity,
+	bacnet.InactiveText,
+	bacnet.ActiveText,
+	bacnet.NumberOfStates,
+	bacnet.StateText,
+	bacnet.MinimumOnTime,
+	bacnet.MinimumOffTime,
+}
+
+// ObjectConfig is the configuration of an object
+type ObjectConfig struct {
+	ID bacnet.ObjectID
+	//Properties are the values of the properties the object has,
+	//decoded like ReadProperty.Data
+	Properties map[bacnet.PropertyType]interface{}
+}
+
+// ConfigDump is the configuration of the objects of a device, as read
+// by DumpConfig. It can be saved as JSON, to verify later that the
+// device, or the one replacing it, has the same configuration
+type ConfigDump struct {
+	Device bacnet.ObjectID
+	Taken  time.Time
+	//Objects are sorted by ID
+	Objects []ObjectConfig
+}
+
+// DumpConfig reads the properties of all the objects of the device,
+// DefaultConfigProperties if props is empty. The properties that an
+// object doesn't have are skipped
+func (c *Client) DumpConfig(ctx context.Context, device bacnet.Device, props []bacnet.PropertyType) (ConfigDump, error) {
+	if len(props) == 0 {
+		props = DefaultConfigProperties
+	}
+	dump := ConfigDump{Device: device.ID, Taken: time.Now()}
+	ids, err := c.ReadObjectList(ctx, device)
+	if err != nil {
+		return dump, err
+	}
+	sort.Slice(ids, func(i, j int) bool { return lessObjectID(ids[i], ids[j]) })
+	spec := ReadAccessSpecification{}
+	for _, p := range props {
+		spec.Properties = append(spec.Properties, bacnet.PropertyIdentifier{Type: p})
+	}
+	for _, id := range ids {
+		spec.ObjectID = id
+		results, err := c.ReadPropertyMultiple(ctx, device, []ReadAccessSpecification{spec})
+		if err != nil {
+			return dump, fmt.Errorf("read configuration of %v: %w", id, err)
+		}
+		object := ObjectConfig{ID: id, Properties: map[bacnet.PropertyType]interface{}{}}
+		for _, r := range results {
+			for _, res := range r.Results {
+				if res.Error == nil {
+					object.Properties[res.Property.Type] = res.Value
+				}
+			}
+		}
+		dump.Objects = append(dump.Objects, object)
+	}
+	return dump, nil
+}
+
+// ConfigDifference is a difference between two configuration dumps.
+// The kind is ObjectAdded for an object only in the second dump,
+// ObjectRemoved for an object only in the first one, ObjectRenamed
+// and ObjectModified for a property whose values differ
+type ConfigDifference struct {
+	Kind     ChangeKind
+	ObjectID bacnet.ObjectID
+	//Property is the property whose values differ
+	Property bacnet.PropertyType
+	//A and B are the values of the property in each dump, nil if the
+	//object doesn't have it
+	A, B interface{}
+}
+
+func (d ConfigDifference) String() string {
+	switch d.Kind {
+	case ObjectAdded, ObjectRemoved:
+		return fmt.Sprintf("%v %v", d.ObjectID, d.Kind)
+	default:
+		return fmt.Sprintf("%v %v: %v != %v", d.ObjectID, d.Property, d.A, d.B)
+	}
+}
+
+// DiffConfig compares the configuration of the objects of two dumps,
+// such as a device and its replacement, or a template and a device it
+// was applied to. Numeric values are equal if they differ by at most
+// tolerance. The device objects are compared even if their instances
+// differ, and their differences are reported with the instance of the
+// first dump. The differences are sorted by object, then by property.
+// The properties to ignore, such as ObjectName when comparing
+// different devices, must be removed from the dumps beforehand
+func DiffConfig(a, b ConfigDump, tolerance float64) []ConfigDifference {
+	objects := make(map[bacnet.ObjectID]ObjectConfig, len(b.Objects))
+	for _, o := range b.Objects {
+		if o.ID == b.Device {
+			//The device objects are compared together
+			o.ID = a.Device
+		}
+		objects[o.ID] = o
+	}
+	var diff []ConfigDifference
+	for _, oa := range a.Objects {
+		ob, ok := objects[oa.ID]
+		if !ok {
+			diff = append(diff, ConfigDifference{Kind: ObjectRemoved, ObjectID: oa.ID})
+			continue
+		}
+		delete(objects, oa.ID)
+		props := map[bacnet.PropertyType]struct{}{}
+		for p := range oa.Properties {
+			props[p] = struct{}{}
+		}
+		for p := range ob.Properties {
+			props[p] = struct{}{}
+		}
+		for p := range props {
+			va, okA := oa.Properties[p]
+			vb, okB := ob.Properties[p]
+			if okA && okB && sameValue(va, vb, tolerance) {
+				continue
+			}
+			kind := ObjectModified
+			if p == bacnet.ObjectName {
+				kind = ObjectRenamed
+			}
+			diff = append(diff, ConfigDifference{Kind: kind, ObjectID: oa.ID, Property: p, A: va, B: vb})
+		}
+	}
+	for id := range objects {
+		diff = append(diff, ConfigDifference{Kind: ObjectAdded, ObjectID: id})
+	}
+	sort.Slice(diff, func(i, j int) bool {
+		if diff[i].ObjectID != diff[j].ObjectID {
+			return lessObjectID(diff[i].ObjectID, diff[j].ObjectID)
+		}
+		return diff[i].Property < diff[j].Property
+	})
+	return diff
+}
+
+type jsonConfigDump struct {
+	Device  jsonObjectID       `json:"device"`
+	Taken   time.Time          `json:"taken"`
+	Objects []jsonObjectConfig `json:"objects"`
+}
+
+type jsonObjectConfig struct {
+	ID         jsonObjectID      `json:"id"`
+	Properties []jsonConfigValue `json:"properties"`
+}
+
+// jsonConfigValue is a property value in its bacnet encoding, which
+// keeps its datatype
+type jsonConfigValue struct {
+	Property bacnet.PropertyType `json:"property"`
+	Value    string              `json:"value"`
+	//List is true if the value is a list, which may have a single
+	//element
+	List bool `json:"list,omitempty"`
+}
+
+func (d ConfigDump) MarshalJSON() ([]byte, error) {
+	dump := jsonConfigDump{Device: jsonObjectID(d.Device), Taken: d.Taken, Objects: []jsonObjectConfig{}}
+	for _, o := range d.Objects {
+		object := jsonObjectConfig{ID: jsonObjectID(o.ID), Properties: []jsonConfigValue{}}
+		for p, v := range o.Properties {
+			values, list := v.([]interface{})
+			if !list {
+				values = []interface{}{v}
+			}
+			var raw []byte
+			for _, v := range values {
+				pv := bacnet.PropertyValue{Value: v}
+				if _, ok := v.(bool); ok {
+					pv.Type = bacnet.TypeBoolean
+				}
+				b, err := encodedValue(pv)
+				if err != nil {
+					return nil, fmt.Errorf("encode %v of %v: %w", p, o.ID, err)
+				}
+				raw = append(raw, b...)
+			}
+			object.Properties = append(object.Properties, jsonConfigValue{Property: p, Value: hex.EncodeToString(raw), List: list})
+		}
+		sort.Slice(object.Properties, func(i, j int) bool {
+			return object.Properties[i].Property < object.Properties[j].Property
+		})
+		dump.Objects = append(dump.Objects, object)
+	}
+	return json.Marshal(dump)
+}
+
+func (d *ConfigDump) UnmarshalJSON(data []byte) error {
+	var dump jsonConfigDump
+	err := json.Unmarshal(data, &dump)
+	if err != nil {
+		return err
+	}
+	d.Device = bacnet.ObjectID(dump.Device)
+	d.Taken = dump.Taken
+	d.Objects = make([]ObjectConfig, 0, len(dump.Objects))
+	for _, o := range dump.Objects {
+		object := ObjectConfig{ID: bacnet.ObjectID(o.ID), Properties: map[bacnet.PropertyType]interface{}{}}
+		for _, p := range o.Properties {
+			raw, err := hex.DecodeString(p.Value)
+			if err != nil {
+				return fmt.Errorf("invalid value of %v of %v: %w", p.Property, object.ID, err)
+			}
+			v := decodeValue(raw)
+			if _, ok := v.([]interface{}); p.List && !ok {
+				v = []interface{}{v}
+			}
+			object.Properties[p.Property] = v
+		}
+		d.Objects = append(d.Objects, object)
+	}
+	return nil
+}
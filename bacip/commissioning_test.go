@@ -0,0 +1,59 @@
+package bacip
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/REQUEA/bacnet"
+	"github.com/matryer/is"
+)
+
+func TestDiffConfig(t *testing.T) {
+	is := is.New(t)
+	ai1 := bacnet.ObjectID{Type: bacnet.AnalogInput, Instance: 1}
+	av1 := bacnet.ObjectID{Type: bacnet.AnalogValue, Instance: 1}
+	bv1 := bacnet.ObjectID{Type: bacnet.BinaryValue, Instance: 1}
+	original := newMemDevice(10)
+	replacement := newMemDevice(20)
+	for _, d := range []*memDevice{original, replacement} {
+		d.set(ai1, bacnet.ObjectName, "SupplyTemp")
+		d.set(ai1, bacnet.Units, bacnet.PropertyValue{Type: bacnet.TypeEnumerated, Value: uint32(62)})
+		d.set(ai1, bacnet.PresentValue, float32(d.id.Instance))
+		d.set(av1, bacnet.ObjectName, "Setpoint")
+	}
+	original.set(ai1, bacnet.CovIncrement, float32(0.5))
+	replacement.set(ai1, bacnet.CovIncrement, float32(0.50001))
+	original.set(av1, bacnet.HighLimit, float32(30))
+	replacement.set(av1, bacnet.HighLimit, float32(35))
+	original.set(bv1, bacnet.ObjectName, "Fan")
+	original.set(original.id, bacnet.ObjectName, "AHU-1")
+	replacement.set(replacement.id, bacnet.ObjectName, "AHU-1 (new)")
+	m := newMemTransport()
+	m.respond = serveDevices(t, original, replacement)
+	c := newMemClient(t, m)
+
+	a, err := c.DumpConfig(context.Background(), original.device(), nil)
+	is.NoErr(err)
+	b, err := c.DumpConfig(context.Background(), replacement.device(), nil)
+	is.NoErr(err)
+	is.Equal(a.Objects[0].ID, ai1)
+	is.Equal(a.Objects[0].Properties, map[bacnet.PropertyType]interface{}{
+		bacnet.ObjectName:   "SupplyTemp",
+		bacnet.Units:        uint32(62),
+		bacnet.CovIncrement: float32(0.5),
+	})
+
+	//Compare with a saved dump
+	saved, err := json.Marshal(a)
+	is.NoErr(err)
+	var loaded ConfigDump
+	is.NoErr(json.Unmarshal(saved, &loaded))
+	is.Equal(loaded.Objects, a.Objects)
+	diff := DiffConfig(loaded, b, 0.001)
+	is.Equal(diff, []ConfigDifference{
+		{Kind: ObjectModified, ObjectID: av1, Property: bacnet.HighLimit, A: float32(30), B: float32(35)},
+		{Kind: ObjectRemoved, ObjectID: bv1},
+		{Kind: ObjectRenamed, ObjectID: original.id, Property: bacnet.ObjectName, A: "AHU-1", B: "AHU-1 (new)"},
+	})
+}
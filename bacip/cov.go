@@ -0,0 +1,451 @@
+package bacip
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/REQUEA/bacnet"
+	"github.com/REQUEA/bacnet/internal/encoding"
+)
+
+// SubscribeCOV subscribes to the change of value notifications of an
+// object, or cancels the subscription
+type SubscribeCOV struct {
+	ProcessID uint32
+	ObjectID  bacnet.ObjectID
+	//Cancel cancels the subscription, the other fields are ignored
+	Cancel         bool
+	IssueConfirmed bool
+	//Lifetime is rounded down to the second, but a lifetime under a
+	//second is sent as 1s. 0 for an indefinite subscription
+	Lifetime time.Duration
+}
+
+func (s SubscribeCOV) MarshalBinary() ([]byte, error) {
+	encoder := encoding.NewEncoder()
+	encoder.ContextUnsigned(0, s.ProcessID)
+	encoder.ContextObjectID(1, s.ObjectID)
+	if !s.Cancel {
+		encoder.ContextBool(2, s.IssueConfirmed)
+		lifetime := uint32(s.Lifetime / time.Second)
+		if lifetime == 0 && s.Lifetime > 0 {
+			//0 would be an indefinite subscription
+			lifetime = 1
+		}
+		encoder.ContextUnsigned(3, lifetime)
+	}
+	return encoder.Bytes(), encoder.Error()
+}
+
+func (s *SubscribeCOV) UnmarshalBinary(data []byte) error {
+	decoder := encoding.NewDecoder(data)
+	decoder.ContextValue(0, &s.ProcessID)
+	decoder.ContextObjectID(1, &s.ObjectID)
+	s.Cancel = true
+	if decoder.IsContextTag(2) {
+		s.Cancel = false
+		decoder.ContextBool(2, &s.IssueConfirmed)
+	}
+	if decoder.IsContextTag(3) {
+		s.Cancel = false
+		var lifetime uint32
+		decoder.ContextValue(3, &lifetime)
+		s.Lifetime = time.Duration(lifetime) * time.Second
+	}
+	return decoder.Error()
+}
+
+// COVValue is a property value of a change of value notification
+type COVValue struct {
+	Property bacnet.PropertyIdentifier
+	//Value is decoded like ReadProperty.Data
+	Value    interface{}
+	Priority bacnet.PriorityList
+}
+
+// COVNotification is a change of value notification sent by a device
+// to its subscribers
+type COVNotification struct {
+	ProcessID uint32
+	//Device is the device sending the notification
+	Device   bacnet.ObjectID
+	ObjectID bacnet.ObjectID
+	//TimeRemaining is the remaining lifetime of the subscription, 0
+	//for an indefinite one
+	TimeRemaining time.Duration
+	Values        []COVValue
+}
+
+func (n COVNotification) MarshalBinary() ([]byte, error) {
+	encoder := encoding.NewEncoder()
+	encoder.ContextUnsigned(0, n.ProcessID)
+	encoder.ContextObjectID(1, n.Device)
+	encoder.ContextObjectID(2, n.ObjectID)
+	encoder.ContextUnsigned(3, uint32(n.TimeRemaining/time.Second))
+	encoder.OpeningTag(4)
+	for _, v := range n.Values {
+		encoder.ContextUnsigned(0, uint32(v.Property.Type))
+		if v.Property.ArrayIndex != nil {
+			encoder.ContextUnsigned(1, *v.Property.ArrayIndex)
+		}
+		encoder.ContextAbstractType(2, bacnet.PropertyValue{Value: v.Value})
+		if v.Priority != 0 {
+			encoder.ContextUnsigned(3, uint32(v.Priority))
+		}
+	}
+	encoder.ClosingTag(4)
+	return encoder.Bytes(), encoder.Error()
+}
+
+func (n *COVNotification) UnmarshalBinary(data []byte) error {
+	decoder := encoding.NewDecoder(data)
+	decoder.ContextValue(0, &n.ProcessID)
+	decoder.ContextObjectID(1, &n.Device)
+	decoder.ContextObjectID(2, &n.ObjectID)
+	var remaining uint32
+	decoder.ContextValue(3, &remaining)
+	n.TimeRemaining = time.Duration(remaining) * time.Second
+	decoder.OpeningTag(4)
+	n.Values = decodePropertyValues(decoder, 4)
+	decoder.ClosingTag(4)
+	return decoder.Error()
+}
+
+// decodePropertyValues decodes a list of property values up to the
+// closing tag of the given number
+func decodePropertyValues(d *encoding.Decoder, closingTag byte) []COVValue {
+	values := []COVValue{}
+	for d.Error() == nil && !d.IsClosingTag(closingTag) {
+		var val uint32
+		v := COVValue{}
+		d.ContextValue(0, &val)
+		v.Property.Type = bacnet.PropertyType(val)
+		if d.IsContextTag(1) {
+			v.Property.ArrayIndex = new(uint32)
+			d.ContextValue(1, v.Property.ArrayIndex)
+		}
+		var raw []byte
+		d.ContextRaw(2, &raw)
+		v.Value = decodeValue(raw)
+		if d.IsContextTag(3) {
+			var priority uint32
+			d.ContextValue(3, &priority)
+			v.Priority = bacnet.PriorityList(priority)
+		}
+		values = append(values, v)
+	}
+	return values
+}
+
+// Value returns the value of the property in the notification
+func (n COVNotification) Value(prop bacnet.PropertyType) (interface{}, bool) {
+	for _, v := range n.Values {
+		if v.Property.Type == prop && v.Property.ArrayIndex == nil {
+			return v.Value, true
+		}
+	}
+	return nil, false
+}
+
+// COVSubscription is a subscription made by the client
+type COVSubscription struct {
+	ProcessID uint32
+	Device    bacnet.Device
+	ObjectID  bacnet.ObjectID
+	Confirmed bool
+	Lifetime  time.Duration
+	//Expires is the end of the subscription, zero if it's indefinite
+	Expires time.Time
+}
+
+type covKey struct {
+	processID uint32
+	device    bacnet.ObjectInstance
+	object    bacnet.ObjectID
+}
+
+type covEntry struct {
+	sub      COVSubscription
+	callback func(COVNotification)
+}
+
+// covSubscriptions routes the notifications to the callbacks of the
+// subscriptions
+type covSubscriptions struct {
+	sync.Mutex
+	//next is the last process ID allocated, or the one before the first
+	//one to allocate, once started
+	next    uint32
+	started bool
+	subs    map[covKey]*covEntry
+}
+
+// start sets the process IDs to start at a random value, unless they
+// already started
+func (s *covSubscriptions) start(now time.Time) {
+	if !s.started {
+		s.next = rand.New(rand.NewSource(now.UnixNano())).Uint32() //nolint: gosec
+		s.started = true
+	}
+}
+
+// processID allocates the process ID of a new subscription to the
+// object. Unless set by SetCOVProcessIDStart, the IDs start at a
+// random value, so that a restarted client is unlikely to take over
+// the subscriptions left on the devices by the previous run. They then
+// increase, skipping 0 and the IDs of the live subscriptions to the
+// same object. The IDs of the subscriptions cancelled or expired are
+// reused after a wrap around
+func (s *covSubscriptions) processID(device bacnet.ObjectInstance, object bacnet.ObjectID, now time.Time) uint32 {
+	s.Lock()
+	defer s.Unlock()
+	s.start(now)
+	for {
+		s.next++
+		if s.next == 0 {
+			continue
+		}
+		e, ok := s.subs[covKey{processID: s.next, device: device, object: object}]
+		if !ok || (!e.sub.Expires.IsZero() && now.After(e.sub.Expires)) {
+			return s.next
+		}
+	}
+}
+
+// reserve makes the allocation continue after the process ID of a
+// resumed subscription, so that new subscriptions don't take the IDs
+// of those left on the devices by the previous run. The allocation
+// only moves forward, the IDs following it being allocated first
+func (s *covSubscriptions) reserve(processID uint32) {
+	s.Lock()
+	defer s.Unlock()
+	if !s.started {
+		s.next = processID
+		s.started = true
+		return
+	}
+	if int32(processID-s.next) > 0 {
+		s.next = processID
+	}
+}
+
+// set sets the callback of the subscription, and returns the new
+// entry and the previous one if the subscription is renewed
+func (s *covSubscriptions) set(sub COVSubscription, callback func(COVNotification)) (entry *covEntry, previous *covEntry) {
+	s.Lock()
+	defer s.Unlock()
+	if s.subs == nil {
+		s.subs = map[covKey]*covEntry{}
+	}
+	entry = &covEntry{sub: sub, callback: callback}
+	previous = s.subs[sub.key()]
+	s.subs[sub.key()] = entry
+	return entry, previous
+}
+
+// restore puts back the previous entry replaced by entry, or removes
+// entry if it's a new subscription. Nothing is done if entry was
+// already replaced
+func (s *covSubscriptions) restore(entry *covEntry, previous *covEntry) {
+	s.Lock()
+	defer s.Unlock()
+	key := entry.sub.key()
+	if s.subs[key] != entry {
+		return
+	}
+	if previous == nil {
+		delete(s.subs, key)
+	} else {
+		s.subs[key] = previous
+	}
+}
+
+func (s *covSubscriptions) remove(sub COVSubscription) {
+	s.Lock()
+	defer s.Unlock()
+	delete(s.subs, sub.key())
+}
+
+// callback returns the callback of the subscription the notification
+// belongs to, if any. Expired subscriptions are removed
+func (s *covSubscriptions) callback(n COVNotification, now time.Time) func(COVNotification) {
+	s.Lock()
+	defer s.Unlock()
+	key := covKey{processID: n.ProcessID, device: n.Device.Instance, object: n.ObjectID}
+	e, ok := s.subs[key]
+	if !ok {
+		return nil
+	}
+	if !e.sub.Expires.IsZero() && now.After(e.sub.Expires) {
+		delete(s.subs, key)
+		return nil
+	}
+	return e.callback
+}
+
+func (sub COVSubscription) key() covKey {
+	return covKey{processID: sub.ProcessID, device: sub.Device.ID.Instance, object: sub.ObjectID}
+}
+
+// SubscribeCOV subscribes to the change of value notifications of the
+// object. callback is called with every notification of the
+// subscription until it's cancelled or it expires, and must not
+// block. The subscription lasts lifetime, 0 for an indefinite one.
+// The process ID of the subscription is allocated by the client, see
+// COVSubscriptions
+func (c *Client) SubscribeCOV(ctx context.Context, device bacnet.Device, object bacnet.ObjectID, confirmed bool, lifetime time.Duration, callback func(COVNotification)) (COVSubscription, error) {
+	sub := COVSubscription{
+		ProcessID: c.covs.processID(device.ID.Instance, object, time.Now()),
+		Device:    device,
+		ObjectID:  object,
+		Confirmed: confirmed,
+		Lifetime:  lifetime,
+	}
+	return c.subscribeCOV(ctx, sub, callback)
+}
+
+// RenewCOV sends the subscription again, to extend its lifetime
+func (c *Client) RenewCOV(ctx context.Context, sub COVSubscription) (COVSubscription, error) {
+	c.covs.Lock()
+	e, ok := c.covs.subs[sub.key()]
+	c.covs.Unlock()
+	if !ok {
+		return sub, errors.New("unknown subscription")
+	}
+	return c.subscribeCOV(ctx, sub, e.callback)
+}
+
+func (c *Client) subscribeCOV(ctx context.Context, sub COVSubscription, callback func(COVNotification)) (COVSubscription, error) {
+	if sub.Lifetime > 0 {
+		sub.Expires = time.Now().Add(sub.Lifetime)
+	}
+	//The callback must be ready before the first notification, which
+	//may be sent before the acknowledgment
+	entry, previous := c.covs.set(sub, callback)
+	apdu, err := c.confirmedRequest(ctx, sub.Device, ServiceConfirmedSubscribeCOV, &SubscribeCOV{
+		ProcessID:      sub.ProcessID,
+		ObjectID:       sub.ObjectID,
+		IssueConfirmed: sub.Confirmed,
+		Lifetime:       sub.Lifetime,
+	})
+	if err == nil && apdu.DataType != SimpleAck {
+		err = errors.New("invalid answer")
+	}
+	if err != nil {
+		//A failed renewal doesn't end the subscription, which is still
+		//active on the device until it expires
+		c.covs.restore(entry, previous)
+		return sub, err
+	}
+	return sub, nil
+}
+
+// UnsubscribeCOV cancels the subscription
+func (c *Client) UnsubscribeCOV(ctx context.Context, sub COVSubscription) error {
+	return c.CancelCOV(ctx, sub.Device, sub.ObjectID, sub.ProcessID)
+}
+
+// CancelCOV cancels the subscription of the client to the object with
+// the process ID. Unlike UnsubscribeCOV, the subscription doesn't have
+// to be one of the client: applications keeping the process IDs of
+// their indefinite subscriptions, listed by COVSubscriptions, can
+// cancel them after a restart
+func (c *Client) CancelCOV(ctx context.Context, device bacnet.Device, object bacnet.ObjectID, processID uint32) error {
+	c.covs.remove(COVSubscription{ProcessID: processID, Device: device, ObjectID: object})
+	apdu, err := c.confirmedRequest(ctx, device, ServiceConfirmedSubscribeCOV, &SubscribeCOV{
+		ProcessID: processID,
+		ObjectID:  object,
+		Cancel:    true,
+	})
+	if err != nil {
+		return err
+	}
+	if apdu.DataType != SimpleAck {
+		return errors.New("invalid answer")
+	}
+	return nil
+}
+
+// COVSubscriptions returns the subscriptions of the client that
+// didn't expire, sorted by process ID
+func (c *Client) COVSubscriptions() []COVSubscription {
+	now := time.Now()
+	c.covs.Lock()
+	defer c.covs.Unlock()
+	subs := []COVSubscription{}
+	for _, e := range c.covs.subs {
+		if e.sub.Expires.IsZero() || now.Before(e.sub.Expires) {
+			subs = append(subs, e.sub)
+		}
+	}
+	sort.Slice(subs, func(i, j int) bool { return subs[i].ProcessID < subs[j].ProcessID })
+	return subs
+}
+
+// NextCOVProcessID returns the process ID from which the client
+// allocates those of its next COV subscriptions. An application saving
+// it and restoring it with SetCOVProcessIDStart after a restart doesn't
+// reuse the process IDs of the subscriptions left on the devices by the
+// previous run, until the IDs wrap around
+func (c *Client) NextCOVProcessID() uint32 {
+	c.covs.Lock()
+	defer c.covs.Unlock()
+	c.covs.start(time.Now())
+	if c.covs.next == ^uint32(0) {
+		//0 is skipped
+		return 1
+	}
+	return c.covs.next + 1
+}
+
+// SetCOVProcessIDStart sets the process ID from which the client
+// allocates those of its next COV subscriptions, instead of a random
+// one
+func (c *Client) SetCOVProcessIDStart(start uint32) {
+	c.covs.Lock()
+	defer c.covs.Unlock()
+	c.covs.next = start - 1
+	c.covs.started = true
+}
+
+// handleCOVNotification calls the callback of the subscription of a
+// notification, and acknowledges the confirmed notifications
+func (c *Client) handleCOVNotification(bvlc BVLC, src *net.UDPAddr) {
+	apdu := bvlc.NPDU.ADPU
+	n, ok := apdu.Payload.(*COVNotification)
+	if !ok {
+		return
+	}
+	if callback := c.covs.callback(*n, time.Now()); callback != nil {
+		callback(*n)
+	}
+	if apdu.DataType != ConfirmedServiceRequest {
+		return
+	}
+	if err := c.acknowledge(bvlc, src); err != nil {
+		c.logger.Error("acknowledge COV notification: ", err)
+	}
+}
+
+// acknowledge sends a SimpleAck to a confirmed request
+func (c *Client) acknowledge(bvlc BVLC, src *net.UDPAddr) error {
+	apdu := bvlc.NPDU.ADPU
+	_, err := c.send(NPDU{
+		Version:     Version1,
+		Priority:    Normal,
+		Destination: replyAddress(bvlc, src),
+		HopCount:    255,
+		ADPU: &APDU{
+			DataType:    SimpleAck,
+			ServiceType: apdu.ServiceType,
+			InvokeID:    apdu.InvokeID,
+			Payload:     &DataPayload{},
+		},
+	})
+	return err
+}
@@ -0,0 +1,320 @@
+package bacip
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/REQUEA/bacnet"
+	"github.com/matryer/is"
+)
+
+func TestCOVNotificationEncoding(t *testing.T) {
+	is := is.New(t)
+	index := uint32(2)
+	n := COVNotification{
+		ProcessID:     7,
+		Device:        bacnet.ObjectID{Type: bacnet.BacnetDevice, Instance: 10},
+		ObjectID:      bacnet.ObjectID{Type: bacnet.AnalogInput, Instance: 1},
+		TimeRemaining: time.Minute,
+		Values: []COVValue{
+			{Property: bacnet.PropertyIdentifier{Type: bacnet.PresentValue}, Value: float32(21)},
+			{Property: bacnet.PropertyIdentifier{Type: bacnet.PriorityArray, ArrayIndex: &index}, Value: uint32(3), Priority: bacnet.ManualOperator8},
+		},
+	}
+	b, err := n.MarshalBinary()
+	is.NoErr(err)
+	var decoded COVNotification
+	is.NoErr(decoded.UnmarshalBinary(b))
+	is.Equal(decoded, n)
+	v, ok := decoded.Value(bacnet.PresentValue)
+	is.True(ok)
+	is.Equal(v, float32(21))
+	_, ok = decoded.Value(bacnet.StatusFlags)
+	is.True(!ok)
+
+	var s SubscribeCOV
+	b, err = SubscribeCOV{ProcessID: 1, ObjectID: n.ObjectID, Cancel: true}.MarshalBinary()
+	is.NoErr(err)
+	is.NoErr(s.UnmarshalBinary(b))
+	is.True(s.Cancel)
+	b, err = SubscribeCOV{ProcessID: 1, ObjectID: n.ObjectID, Lifetime: time.Minute}.MarshalBinary()
+	is.NoErr(err)
+	is.NoErr(s.UnmarshalBinary(b))
+	is.True(!s.Cancel)
+	is.Equal(s.Lifetime, time.Minute)
+	b, err = SubscribeCOV{ProcessID: 1, ObjectID: n.ObjectID, Lifetime: 500 * time.Millisecond}.MarshalBinary()
+	is.NoErr(err)
+	is.NoErr(s.UnmarshalBinary(b))
+	is.Equal(s.Lifetime, time.Second)
+}
+
+func TestSubscribeCOV(t *testing.T) {
+	is := is.New(t)
+	addr := net.UDPAddr{IP: net.IPv4(10, 0, 2, 3).To4(), Port: DefaultUDPPort}
+	device := bacnet.Device{
+		ID:   bacnet.ObjectID{Type: bacnet.BacnetDevice, Instance: 10},
+		Addr: *bacnet.AddressFromUDP(addr),
+	}
+	object := bacnet.ObjectID{Type: bacnet.AnalogInput, Instance: 1}
+	m := newAckTransport(t)
+	c := newMemClient(t, m)
+
+	received := make(chan COVNotification, 1)
+	sub, err := c.SubscribeCOV(context.Background(), device, object, true, time.Minute, func(n COVNotification) {
+		received <- n
+	})
+	is.NoErr(err)
+	is.True(sub.ProcessID != 0)
+	is.Equal(len(c.COVSubscriptions()), 1)
+
+	notify := func(processID uint32) {
+		b, err := datagramOf(&APDU{
+			DataType:    ConfirmedServiceRequest,
+			ServiceType: ServiceConfirmedCOVNotification,
+			InvokeID:    42,
+			Payload: &COVNotification{
+				ProcessID: processID,
+				Device:    device.ID,
+				ObjectID:  object,
+				Values: []COVValue{
+					{Property: bacnet.PropertyIdentifier{Type: bacnet.PresentValue}, Value: float32(21)},
+				},
+			},
+		})
+		is.NoErr(err)
+		m.in <- datagram{data: b, addr: &addr}
+	}
+	//A notification of another subscription is only acknowledged
+	notify(sub.ProcessID + 1)
+	notify(sub.ProcessID)
+	select {
+	case n := <-received:
+		is.Equal(n.ProcessID, sub.ProcessID)
+		v, _ := n.Value(bacnet.PresentValue)
+		is.Equal(v, float32(21))
+	case <-time.After(time.Second):
+		t.Fatal("callback not called")
+	}
+	deadline := time.Now().Add(time.Second)
+	for m.count() < 3 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	m.Lock()
+	var ack BVLC
+	is.NoErr(ack.UnmarshalBinary(m.written[len(m.written)-1]))
+	m.Unlock()
+	is.Equal(ack.NPDU.ADPU.DataType, SimpleAck)
+	is.Equal(ack.NPDU.ADPU.ServiceType, ServiceConfirmedCOVNotification)
+	is.Equal(ack.NPDU.ADPU.InvokeID, byte(42))
+
+	is.NoErr(c.UnsubscribeCOV(context.Background(), sub))
+	is.Equal(len(c.COVSubscriptions()), 0)
+
+	//Cancellation of a subscription left by a previous run
+	m.Lock()
+	written := len(m.written)
+	m.Unlock()
+	is.NoErr(c.CancelCOV(context.Background(), device, object, 1234))
+	m.Lock()
+	var cancel BVLC
+	is.NoErr(cancel.UnmarshalBinary(m.written[written]))
+	m.Unlock()
+	var s SubscribeCOV
+	is.NoErr(s.UnmarshalBinary(cancel.NPDU.ADPU.Payload.(*DataPayload).Bytes))
+	is.Equal(s.ProcessID, uint32(1234))
+	is.True(s.Cancel)
+}
+
+func TestRenewCOVFailure(t *testing.T) {
+	is := is.New(t)
+	device := bacnet.Device{
+		ID:   bacnet.ObjectID{Type: bacnet.BacnetDevice, Instance: 10},
+		Addr: *bacnet.AddressFromUDP(net.UDPAddr{IP: net.IPv4(10, 0, 2, 3).To4(), Port: DefaultUDPPort}),
+	}
+	object := bacnet.ObjectID{Type: bacnet.AnalogInput, Instance: 1}
+	m := newMemTransport()
+	var answered int32
+	m.respond = answerRequests(t, func(request *APDU) *APDU {
+		//Only the first subscription is acknowledged
+		if atomic.AddInt32(&answered, 1) > 1 {
+			return nil
+		}
+		return &APDU{DataType: SimpleAck, ServiceType: request.ServiceType, InvokeID: request.InvokeID, Payload: &DataPayload{}}
+	})
+	c := newMemClient(t, m)
+	sub, err := c.SubscribeCOV(context.Background(), device, object, false, time.Minute, func(COVNotification) {})
+	is.NoErr(err)
+
+	//The subscription is still active on the device after a failed
+	//renewal, its callback is kept
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = c.RenewCOV(ctx, sub)
+	is.True(err != nil)
+	is.Equal(c.COVSubscriptions(), []COVSubscription{sub})
+	is.True(c.covs.callback(COVNotification{ProcessID: sub.ProcessID, Device: device.ID, ObjectID: object}, time.Now()) != nil)
+
+	//A failed first subscription is forgotten
+	other := bacnet.ObjectID{Type: bacnet.AnalogInput, Instance: 2}
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = c.SubscribeCOV(ctx, device, other, false, time.Minute, func(COVNotification) {})
+	is.True(err != nil)
+	is.Equal(c.COVSubscriptions(), []COVSubscription{sub})
+}
+
+func TestCOVProcessID(t *testing.T) {
+	is := is.New(t)
+	object := bacnet.ObjectID{Type: bacnet.AnalogInput, Instance: 1}
+	device := bacnet.Device{ID: bacnet.ObjectID{Type: bacnet.BacnetDevice, Instance: 10}}
+	now := time.Now()
+	var s covSubscriptions
+	first := s.processID(device.ID.Instance, object, now)
+	is.True(first != 0)
+	s.set(COVSubscription{ProcessID: first + 1, Device: device, ObjectID: object}, nil)
+	s.set(COVSubscription{ProcessID: first + 2, Device: device, ObjectID: object, Expires: now.Add(-time.Second)}, nil)
+	//The live subscription is skipped, the expired one reused
+	is.Equal(s.processID(device.ID.Instance, object, now), first+2)
+	//IDs are only unique per object
+	other := bacnet.ObjectID{Type: bacnet.AnalogInput, Instance: 2}
+	is.Equal(s.processID(device.ID.Instance, other, now), first+3)
+
+	//0 is skipped on wrap around
+	s.next = ^uint32(0)
+	is.Equal(s.processID(device.ID.Instance, object, now), uint32(1))
+}
+
+func TestCOVProcessIDStart(t *testing.T) {
+	is := is.New(t)
+	device := bacnet.Device{
+		ID:   bacnet.ObjectID{Type: bacnet.BacnetDevice, Instance: 10},
+		Addr: *bacnet.AddressFromUDP(net.UDPAddr{IP: net.IPv4(10, 0, 2, 3).To4(), Port: DefaultUDPPort}),
+	}
+	object := bacnet.ObjectID{Type: bacnet.AnalogInput, Instance: 1}
+	c := newMemClient(t, newAckTransport(t))
+	next := c.NextCOVProcessID()
+	is.True(next != 0)
+	//Reading it doesn't allocate it
+	is.Equal(c.NextCOVProcessID(), next)
+	sub, err := c.SubscribeCOV(context.Background(), device, object, false, time.Minute, func(COVNotification) {})
+	is.NoErr(err)
+	is.Equal(sub.ProcessID, next)
+
+	//A restarted client continues after the IDs of the previous run
+	restarted := newMemClient(t, newAckTransport(t))
+	restarted.SetCOVProcessIDStart(c.NextCOVProcessID())
+	sub, err = restarted.SubscribeCOV(context.Background(), device, object, false, time.Minute, func(COVNotification) {})
+	is.NoErr(err)
+	is.Equal(sub.ProcessID, next+1)
+
+	restarted.SetCOVProcessIDStart(^uint32(0))
+	is.Equal(restarted.NextCOVProcessID(), ^uint32(0))
+	sub, err = restarted.SubscribeCOV(context.Background(), device, object, false, time.Minute, func(COVNotification) {})
+	is.NoErr(err)
+	is.Equal(sub.ProcessID, ^uint32(0))
+	//0 is skipped
+	is.Equal(restarted.NextCOVProcessID(), uint32(1))
+	restarted.SetCOVProcessIDStart(0)
+	is.Equal(restarted.NextCOVProcessID(), uint32(1))
+}
+
+func TestCOVState(t *testing.T) {
+	is := is.New(t)
+	router := net.UDPAddr{IP: net.IPv4(10, 0, 2, 3).To4(), Port: DefaultUDPPort}
+	device := bacnet.Device{
+		ID:           bacnet.ObjectID{Type: bacnet.BacnetDevice, Instance: 10},
+		MaxApdu:      480,
+		Segmentation: bacnet.SegmentationSupportNone,
+		Vendor:       7,
+		Addr:         bacnet.MSTPAddress(router, 2001, 0x0D),
+	}
+	objects := []bacnet.ObjectID{
+		{Type: bacnet.AnalogInput, Instance: 1},
+		{Type: bacnet.BinaryInput, Instance: 2},
+	}
+	c := newMemClient(t, newAckTransport(t))
+	_, err := c.SubscribeCOV(context.Background(), device, objects[0], true, 0, func(COVNotification) {})
+	is.NoErr(err)
+	_, err = c.SubscribeCOV(context.Background(), device, objects[1], false, time.Hour, func(COVNotification) {})
+	is.NoErr(err)
+	saved := c.COVState()
+	is.Equal(len(saved.Subscriptions), 2)
+
+	b, err := json.Marshal(saved)
+	is.NoErr(err)
+	var state COVState
+	is.NoErr(json.Unmarshal(b, &state))
+	is.Equal(state.NextProcessID, saved.NextProcessID)
+	is.Equal(len(state.Subscriptions), 2)
+	for i, sub := range state.Subscriptions {
+		is.True(sub.Expires.Equal(saved.Subscriptions[i].Expires))
+		sub.Expires, saved.Subscriptions[i].Expires = time.Time{}, time.Time{}
+		is.Equal(sub, saved.Subscriptions[i])
+	}
+
+	//A restarted client makes the subscriptions again with their
+	//process IDs, and continues the allocation after them
+	m := newAckTransport(t)
+	restarted := newMemClient(t, m)
+	restarted.SetCOVProcessIDStart(state.NextProcessID)
+	received := make(chan COVNotification, 1)
+	for _, sub := range state.Subscriptions {
+		_, err := restarted.ResumeCOV(context.Background(), sub, func(n COVNotification) { received <- n })
+		is.NoErr(err)
+	}
+	requests := subscribeRequests(m)
+	is.Equal(len(requests), 2)
+	for i, sub := range state.Subscriptions {
+		is.Equal(requests[i].ProcessID, sub.ProcessID)
+		is.Equal(requests[i].ObjectID, sub.ObjectID)
+		is.Equal(requests[i].IssueConfirmed, sub.Confirmed)
+		is.Equal(requests[i].Lifetime, sub.Lifetime)
+	}
+	is.Equal(len(restarted.COVSubscriptions()), 2)
+	is.True(restarted.COVSubscriptions()[1].Expires.After(time.Now()))
+
+	b, err = datagramOf(&APDU{
+		DataType:    UnconfirmedServiceRequest,
+		ServiceType: ServiceUnconfirmedCOVNotification,
+		Payload: &COVNotification{
+			ProcessID: state.Subscriptions[1].ProcessID,
+			Device:    device.ID,
+			ObjectID:  objects[1],
+		},
+	})
+	is.NoErr(err)
+	m.in <- datagram{data: b, addr: &router}
+	select {
+	case n := <-received:
+		is.Equal(n.ObjectID, objects[1])
+	case <-time.After(time.Second):
+		t.Fatal("no notification")
+	}
+	sub, err := restarted.SubscribeCOV(context.Background(), device, objects[0], false, time.Hour, func(COVNotification) {})
+	is.NoErr(err)
+	is.Equal(sub.ProcessID, state.NextProcessID)
+
+	//Without the saved allocation, the IDs allocated after a resumed
+	//subscription follow it
+	other := newMemClient(t, newAckTransport(t))
+	other.SetCOVProcessIDStart(100)
+	_, err = other.ResumeCOV(context.Background(), COVSubscription{ProcessID: 1234, Device: device, ObjectID: objects[0]}, func(COVNotification) {})
+	is.NoErr(err)
+	is.Equal(other.NextCOVProcessID(), uint32(1235))
+	//but never go back
+	_, err = other.ResumeCOV(context.Background(), COVSubscription{ProcessID: 12, Device: device, ObjectID: objects[1]}, func(COVNotification) {})
+	is.NoErr(err)
+	is.Equal(other.NextCOVProcessID(), uint32(1235))
+	unstarted := newMemClient(t, newAckTransport(t))
+	_, err = unstarted.ResumeCOV(context.Background(), COVSubscription{ProcessID: 1234, Device: device, ObjectID: objects[0]}, func(COVNotification) {})
+	is.NoErr(err)
+	is.Equal(unstarted.NextCOVProcessID(), uint32(1235))
+
+	_, err = restarted.ResumeCOV(context.Background(), COVSubscription{Device: device, ObjectID: objects[0]}, func(COVNotification) {})
+	is.True(err != nil)
+	is.True(json.Unmarshal([]byte(`{"version":2,"subscriptions":[]}`), &state) != nil)
+}
@@ -0,0 +1,306 @@
+package bacip
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/REQUEA/bacnet"
+)
+
+// DefaultCOVLifetime is the lifetime of the subscriptions of a
+// COVManager created with a lifetime of 0
+const DefaultCOVLifetime = 5 * time.Minute
+
+// covEventBuffer is the size of the event channels of a COVManager
+const covEventBuffer = 16
+
+// COVEvent is a change of value notification received for a monitored
+// object
+type COVEvent struct {
+	COVNotification
+	Received time.Time
+}
+
+// COVManager keeps COV subscriptions alive: they are renewed before
+// they expire, and made again when a monitored device sends an IAm,
+// as devices do when they restart and forget their subscriptions
+type COVManager struct {
+	client    *Client
+	lifetime  time.Duration
+	confirmed bool
+	//RenewBefore is how long before their expiration the
+	//subscriptions are renewed, a quarter of the lifetime if 0. It
+	//must be set before the first call to Monitor
+	RenewBefore time.Duration
+
+	ctx         context.Context
+	cancel      context.CancelFunc
+	unsubscribe func()
+	wg          sync.WaitGroup
+	mutex       sync.Mutex
+	monitors    map[covMonitorKey]*covMonitor
+}
+
+type covMonitorKey struct {
+	device bacnet.ObjectInstance
+	object bacnet.ObjectID
+}
+
+type covMonitor struct {
+	//sub is only changed by the goroutine renewing it
+	mutex sync.Mutex
+	sub   COVSubscription
+	//eventsMutex protects the channel from being closed while an event
+	//is pushed
+	eventsMutex sync.Mutex
+	events      chan COVEvent
+	closed      bool
+	//renew wakes up the renewal loop to subscribe again right away
+	renew chan struct{}
+	//done stops the renewal loop, which closes exited
+	done   chan struct{}
+	exited chan struct{}
+}
+
+// NewCOVManager returns a manager making subscriptions of the given
+// lifetime, DefaultCOVLifetime if 0. Confirmed subscriptions are
+// acknowledged by the client, so the devices know when it's gone
+func NewCOVManager(c *Client, lifetime time.Duration, confirmed bool) *COVManager {
+	if lifetime <= 0 {
+		lifetime = DefaultCOVLifetime
+	}
+	m := &COVManager{
+		client:    c,
+		lifetime:  lifetime,
+		confirmed: confirmed,
+		monitors:  map[covMonitorKey]*covMonitor{},
+	}
+	m.ctx, m.cancel = context.WithCancel(context.Background())
+	m.unsubscribe = c.subscriptions.subscribe(m.handleIam)
+	return m
+}
+
+// Monitor subscribes to the changes of value of the object and returns
+// the channel of its events. The subscription is kept alive until
+// Stop or Close, which close the channel. When the events aren't read
+// fast enough, the oldest ones are dropped
+func (m *COVManager) Monitor(ctx context.Context, device bacnet.Device, object bacnet.ObjectID) (<-chan COVEvent, error) {
+	return m.monitor(ctx, device, object, 0)
+}
+
+// Resume monitors the object of a subscription saved before a restart,
+// see COVState. The subscription is made again with its process ID, so
+// the device updates it if it still has it rather than notifying two
+// subscriptions. Its lifetime and confirmation are those of the
+// manager. Like for ResumeCOV, the process IDs allocated next follow
+// that of the subscription
+func (m *COVManager) Resume(ctx context.Context, sub COVSubscription) (<-chan COVEvent, error) {
+	if sub.ProcessID == 0 {
+		return nil, errors.New("subscription without process ID")
+	}
+	m.client.covs.reserve(sub.ProcessID)
+	return m.monitor(ctx, sub.Device, sub.ObjectID, sub.ProcessID)
+}
+
+// monitor subscribes to the object with the process ID, allocated by
+// the client if 0
+func (m *COVManager) monitor(ctx context.Context, device bacnet.Device, object bacnet.ObjectID, processID uint32) (<-chan COVEvent, error) {
+	key := covMonitorKey{device: device.ID.Instance, object: object}
+	m.mutex.Lock()
+	if m.ctx.Err() != nil {
+		m.mutex.Unlock()
+		return nil, errors.New("COV manager closed")
+	}
+	if mon, ok := m.monitors[key]; ok {
+		m.mutex.Unlock()
+		return mon.events, nil
+	}
+	if processID == 0 {
+		processID = m.client.covs.processID(device.ID.Instance, object, time.Now())
+	}
+	mon := &covMonitor{
+		sub: COVSubscription{
+			ProcessID: processID,
+			Device:    device,
+			ObjectID:  object,
+			Confirmed: m.confirmed,
+			Lifetime:  m.lifetime,
+		},
+		events: make(chan COVEvent, covEventBuffer),
+		renew:  make(chan struct{}, 1),
+		done:   make(chan struct{}),
+		exited: make(chan struct{}),
+	}
+	m.monitors[key] = mon
+	m.mutex.Unlock()
+
+	err := m.subscribe(ctx, mon)
+	if err != nil {
+		m.mutex.Lock()
+		if m.monitors[key] == mon {
+			delete(m.monitors, key)
+		}
+		m.mutex.Unlock()
+		close(mon.exited)
+		return nil, err
+	}
+	m.wg.Add(1)
+	go m.keepAlive(mon)
+	return mon.events, nil
+}
+
+// Stop cancels the subscription of the object and closes its channel
+func (m *COVManager) Stop(ctx context.Context, device bacnet.Device, object bacnet.ObjectID) error {
+	key := covMonitorKey{device: device.ID.Instance, object: object}
+	m.mutex.Lock()
+	mon, ok := m.monitors[key]
+	delete(m.monitors, key)
+	m.mutex.Unlock()
+	if !ok {
+		return errors.New("object not monitored")
+	}
+	return m.stop(ctx, mon)
+}
+
+// Close cancels all the subscriptions and closes their channels
+func (m *COVManager) Close(ctx context.Context) error {
+	m.mutex.Lock()
+	monitors := m.monitors
+	m.monitors = map[covMonitorKey]*covMonitor{}
+	m.cancel()
+	m.mutex.Unlock()
+	m.unsubscribe()
+	var err error
+	for _, mon := range monitors {
+		if e := m.stop(ctx, mon); e != nil && err == nil {
+			err = e
+		}
+	}
+	m.wg.Wait()
+	return err
+}
+
+func (m *COVManager) stop(ctx context.Context, mon *covMonitor) error {
+	close(mon.done)
+	//A renewal in progress would subscribe again after the
+	//cancellation
+	<-mon.exited
+	mon.mutex.Lock()
+	sub := mon.sub
+	mon.mutex.Unlock()
+	err := m.client.UnsubscribeCOV(ctx, sub)
+	mon.eventsMutex.Lock()
+	mon.closed = true
+	close(mon.events)
+	mon.eventsMutex.Unlock()
+	return err
+}
+
+// subscribe sends the subscription of the monitor. The lock isn't
+// held during the request: the notifications received meanwhile must
+// not wait for it
+func (m *COVManager) subscribe(ctx context.Context, mon *covMonitor) error {
+	mon.mutex.Lock()
+	sub := mon.sub
+	mon.mutex.Unlock()
+	sub, err := m.client.subscribeCOV(ctx, sub, mon.push)
+	if err != nil {
+		return err
+	}
+	mon.mutex.Lock()
+	mon.sub = sub
+	mon.mutex.Unlock()
+	return nil
+}
+
+// push sends the notification to the channel of the monitor, dropping
+// the oldest event if it's full
+func (mon *covMonitor) push(n COVNotification) {
+	mon.eventsMutex.Lock()
+	defer mon.eventsMutex.Unlock()
+	if mon.closed {
+		return
+	}
+	ev := COVEvent{COVNotification: n, Received: time.Now()}
+	for {
+		select {
+		case mon.events <- ev:
+			return
+		default:
+		}
+		select {
+		case <-mon.events:
+		default:
+		}
+	}
+}
+
+// renewDelay returns the delay before the renewal of the subscription
+func (m *COVManager) renewDelay() time.Duration {
+	before := m.RenewBefore
+	if before <= 0 || before >= m.lifetime {
+		before = m.lifetime / 4
+	}
+	return m.lifetime - before
+}
+
+// keepAlive renews the subscription of the monitor until it's stopped.
+// A failed renewal is retried after a tenth of the renewal delay
+func (m *COVManager) keepAlive(mon *covMonitor) {
+	defer m.wg.Done()
+	defer close(mon.exited)
+	delay := m.renewDelay()
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	for {
+		select {
+		case <-mon.done:
+			return
+		case <-m.ctx.Done():
+			return
+		case <-timer.C:
+		case <-mon.renew:
+			timer.Stop()
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		err := m.subscribe(m.ctx, mon)
+		if err != nil {
+			if m.ctx.Err() == nil {
+				m.client.logger.Error("renew COV subscription: ", err)
+			}
+			timer.Reset(delay / 10)
+			continue
+		}
+		timer.Reset(delay)
+	}
+}
+
+// handleIam subscribes again to the objects of a device sending an
+// IAm
+func (m *COVManager) handleIam(bvlc BVLC, _ net.UDPAddr) {
+	apdu := bvlc.NPDU.ADPU
+	if apdu == nil || apdu.DataType != UnconfirmedServiceRequest || apdu.ServiceType != ServiceUnconfirmedIAm {
+		return
+	}
+	iam, ok := apdu.Payload.(*Iam)
+	if !ok {
+		return
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	for key, mon := range m.monitors {
+		if key.device != iam.ObjectID.Instance {
+			continue
+		}
+		select {
+		case mon.renew <- struct{}{}:
+		default:
+		}
+	}
+}
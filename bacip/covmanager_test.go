@@ -0,0 +1,185 @@
+package bacip
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/REQUEA/bacnet"
+	"github.com/matryer/is"
+)
+
+// subscribeRequests returns the SubscribeCOV requests written to the
+// transport
+func subscribeRequests(m *memTransport) []SubscribeCOV {
+	m.Lock()
+	defer m.Unlock()
+	var requests []SubscribeCOV
+	for _, b := range m.written {
+		var bvlc BVLC
+		if bvlc.UnmarshalBinary(b) != nil || bvlc.NPDU.ADPU == nil || bvlc.NPDU.ADPU.ServiceType != ServiceConfirmedSubscribeCOV ||
+			bvlc.NPDU.ADPU.DataType != ConfirmedServiceRequest {
+			continue
+		}
+		var s SubscribeCOV
+		if p, ok := bvlc.NPDU.ADPU.Payload.(*DataPayload); ok && s.UnmarshalBinary(p.Bytes) == nil {
+			requests = append(requests, s)
+		}
+	}
+	return requests
+}
+
+// waitFor polls cond until it's true or a second elapsed
+func waitFor(cond func() bool) bool {
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return true
+}
+
+func TestCOVManager(t *testing.T) {
+	is := is.New(t)
+	addr := net.UDPAddr{IP: net.IPv4(10, 0, 2, 3).To4(), Port: DefaultUDPPort}
+	device := bacnet.Device{
+		ID:   bacnet.ObjectID{Type: bacnet.BacnetDevice, Instance: 10},
+		Addr: *bacnet.AddressFromUDP(addr),
+	}
+	object := bacnet.ObjectID{Type: bacnet.AnalogInput, Instance: 1}
+	m := newAckTransport(t)
+	c := newMemClient(t, m)
+
+	manager := NewCOVManager(c, 2*time.Second, false)
+	manager.RenewBefore = 1900 * time.Millisecond
+	events, err := manager.Monitor(context.Background(), device, object)
+	is.NoErr(err)
+	is.Equal(len(subscribeRequests(m)), 1)
+	is.Equal(subscribeRequests(m)[0].Lifetime, 2*time.Second)
+	processID := subscribeRequests(m)[0].ProcessID
+
+	//Renewed every 100ms with the same process ID
+	is.True(waitFor(func() bool { return len(subscribeRequests(m)) >= 3 }))
+	is.Equal(subscribeRequests(m)[2].ProcessID, processID)
+
+	b, err := datagramOf(&APDU{
+		DataType:    UnconfirmedServiceRequest,
+		ServiceType: ServiceUnconfirmedCOVNotification,
+		Payload: &COVNotification{
+			ProcessID: processID,
+			Device:    device.ID,
+			ObjectID:  object,
+			Values: []COVValue{
+				{Property: bacnet.PropertyIdentifier{Type: bacnet.PresentValue}, Value: float32(21)},
+			},
+		},
+	})
+	is.NoErr(err)
+	m.in <- datagram{data: b, addr: &addr}
+	select {
+	case ev := <-events:
+		v, _ := ev.Value(bacnet.PresentValue)
+		is.Equal(v, float32(21))
+		is.True(!ev.Received.IsZero())
+	case <-time.After(time.Second):
+		t.Fatal("no event")
+	}
+	is.NoErr(manager.Close(context.Background()))
+	_, ok := <-events
+	is.True(!ok)
+	requests := subscribeRequests(m)
+	is.True(requests[len(requests)-1].Cancel)
+}
+
+func TestCOVManagerReboot(t *testing.T) {
+	is := is.New(t)
+	addr := net.UDPAddr{IP: net.IPv4(10, 0, 2, 3).To4(), Port: DefaultUDPPort}
+	device := bacnet.Device{
+		ID:   bacnet.ObjectID{Type: bacnet.BacnetDevice, Instance: 10},
+		Addr: *bacnet.AddressFromUDP(addr),
+	}
+	m := newAckTransport(t)
+	c := newMemClient(t, m)
+
+	manager := NewCOVManager(c, time.Hour, true)
+	defer manager.Close(context.Background())
+	for i := 1; i <= 2; i++ {
+		_, err := manager.Monitor(context.Background(), device, bacnet.ObjectID{Type: bacnet.AnalogInput, Instance: bacnet.ObjectInstance(i)})
+		is.NoErr(err)
+	}
+	is.Equal(len(subscribeRequests(m)), 2)
+
+	iam := func(instance bacnet.ObjectInstance) {
+		b, err := datagramOf(&APDU{
+			DataType:    UnconfirmedServiceRequest,
+			ServiceType: ServiceUnconfirmedIAm,
+			Payload: &Iam{
+				ObjectID:            bacnet.ObjectID{Type: bacnet.BacnetDevice, Instance: instance},
+				MaxApduLength:       1476,
+				SegmentationSupport: bacnet.SegmentationSupportNone,
+			},
+		})
+		is.NoErr(err)
+		m.in <- datagram{data: b, addr: &addr}
+	}
+	//Another device restarted
+	iam(11)
+	time.Sleep(50 * time.Millisecond)
+	is.Equal(len(subscribeRequests(m)), 2)
+	//The monitored device restarted, both objects are subscribed again
+	iam(10)
+	is.True(waitFor(func() bool { return len(subscribeRequests(m)) == 4 }))
+	for _, s := range subscribeRequests(m)[2:] {
+		is.True(s.IssueConfirmed)
+		is.True(!s.Cancel)
+	}
+}
+
+func TestCOVManagerResume(t *testing.T) {
+	is := is.New(t)
+	addr := net.UDPAddr{IP: net.IPv4(10, 0, 2, 3).To4(), Port: DefaultUDPPort}
+	device := bacnet.Device{
+		ID:   bacnet.ObjectID{Type: bacnet.BacnetDevice, Instance: 10},
+		Addr: *bacnet.AddressFromUDP(addr),
+	}
+	object := bacnet.ObjectID{Type: bacnet.AnalogInput, Instance: 1}
+	saved := COVSubscription{ProcessID: 1234, Device: device, ObjectID: object, Lifetime: time.Minute}
+	m := newAckTransport(t)
+	c := newMemClient(t, m)
+
+	manager := NewCOVManager(c, time.Hour, true)
+	defer manager.Close(context.Background())
+	events, err := manager.Resume(context.Background(), saved)
+	is.NoErr(err)
+	requests := subscribeRequests(m)
+	is.Equal(len(requests), 1)
+	is.Equal(requests[0].ProcessID, uint32(1234))
+	is.Equal(requests[0].Lifetime, time.Hour)
+	is.True(requests[0].IssueConfirmed)
+	is.Equal(c.NextCOVProcessID(), uint32(1235))
+	//The object is already monitored
+	again, err := manager.Monitor(context.Background(), device, object)
+	is.NoErr(err)
+	is.Equal(again, events)
+	is.Equal(len(subscribeRequests(m)), 1)
+
+	b, err := datagramOf(&APDU{
+		DataType:    UnconfirmedServiceRequest,
+		ServiceType: ServiceUnconfirmedCOVNotification,
+		Payload:     &COVNotification{ProcessID: 1234, Device: device.ID, ObjectID: object},
+	})
+	is.NoErr(err)
+	m.in <- datagram{data: b, addr: &addr}
+	select {
+	case ev := <-events:
+		is.Equal(ev.ObjectID, object)
+	case <-time.After(time.Second):
+		t.Fatal("no event")
+	}
+
+	_, err = manager.Resume(context.Background(), COVSubscription{Device: device, ObjectID: bacnet.ObjectID{Type: bacnet.AnalogInput, Instance: 2}})
+	is.True(err != nil)
+}
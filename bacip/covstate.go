@@ -0,0 +1,141 @@
+package bacip
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/REQUEA/bacnet"
+)
+
+// COVStateVersion is the version of the JSON format of the COV state
+// written by this package
+const COVStateVersion = 1
+
+// COVState is the state of the COV subscriptions of a client. An
+// application saving it, and restoring it after a restart with
+// SetCOVProcessIDStart and ResumeCOV, or COVManager.Resume, gets the
+// notifications of its subscriptions again right away, instead of
+// noticing the gap in the data once they expire.
+type COVState struct {
+	//NextProcessID is the process ID from which the client allocates
+	//those of its next subscriptions
+	NextProcessID uint32
+	Subscriptions []COVSubscription
+}
+
+// COVState returns the state of the subscriptions of the client that
+// didn't expire, to be saved
+func (c *Client) COVState() COVState {
+	return COVState{
+		NextProcessID: c.NextCOVProcessID(),
+		Subscriptions: c.COVSubscriptions(),
+	}
+}
+
+// ResumeCOV makes again a subscription saved before a restart, with
+// the same process ID and lifetime. The device updates its
+// subscription if it still has it, or makes it again otherwise.
+// callback is called with the notifications of the subscription, like
+// for SubscribeCOV. The process IDs allocated next follow that of the
+// subscription
+func (c *Client) ResumeCOV(ctx context.Context, sub COVSubscription, callback func(COVNotification)) (COVSubscription, error) {
+	if sub.ProcessID == 0 {
+		return sub, errors.New("subscription without process ID")
+	}
+	c.covs.reserve(sub.ProcessID)
+	sub.Expires = time.Time{}
+	return c.subscribeCOV(ctx, sub, callback)
+}
+
+type jsonCOVState struct {
+	Version       int                   `json:"version"`
+	NextProcessID uint32                `json:"nextProcessId"`
+	Subscriptions []jsonCOVSubscription `json:"subscriptions"`
+}
+
+type jsonCOVDevice struct {
+	ID           jsonObjectID               `json:"id"`
+	Address      jsonAddress                `json:"address"`
+	MaxApdu      uint32                     `json:"maxApdu"`
+	Segmentation bacnet.SegmentationSupport `json:"segmentation"`
+	Vendor       uint32                     `json:"vendor"`
+}
+
+type jsonCOVSubscription struct {
+	ProcessID uint32        `json:"processId"`
+	Device    jsonCOVDevice `json:"device"`
+	Object    jsonObjectID  `json:"object"`
+	Confirmed bool          `json:"confirmed"`
+	//Lifetime is in seconds, 0 for an indefinite subscription
+	Lifetime uint32     `json:"lifetime"`
+	Expires  *time.Time `json:"expires,omitempty"`
+}
+
+func (s COVState) MarshalJSON() ([]byte, error) {
+	state := jsonCOVState{
+		Version:       COVStateVersion,
+		NextProcessID: s.NextProcessID,
+		Subscriptions: []jsonCOVSubscription{},
+	}
+	for _, sub := range s.Subscriptions {
+		js := jsonCOVSubscription{
+			ProcessID: sub.ProcessID,
+			Device: jsonCOVDevice{
+				ID:           jsonObjectID(sub.Device.ID),
+				Address:      newJSONAddress(sub.Device.Addr),
+				MaxApdu:      sub.Device.MaxApdu,
+				Segmentation: sub.Device.Segmentation,
+				Vendor:       sub.Device.Vendor,
+			},
+			Object:    jsonObjectID(sub.ObjectID),
+			Confirmed: sub.Confirmed,
+			Lifetime:  uint32(sub.Lifetime / time.Second),
+		}
+		if !sub.Expires.IsZero() {
+			expires := sub.Expires
+			js.Expires = &expires
+		}
+		state.Subscriptions = append(state.Subscriptions, js)
+	}
+	return json.Marshal(state)
+}
+
+func (s *COVState) UnmarshalJSON(data []byte) error {
+	var state jsonCOVState
+	err := json.Unmarshal(data, &state)
+	if err != nil {
+		return err
+	}
+	if state.Version < 1 || state.Version > COVStateVersion {
+		return fmt.Errorf("unsupported COV state version %d", state.Version)
+	}
+	s.NextProcessID = state.NextProcessID
+	s.Subscriptions = make([]COVSubscription, 0, len(state.Subscriptions))
+	for _, js := range state.Subscriptions {
+		addr, err := js.Device.Address.address()
+		if err != nil {
+			return fmt.Errorf("subscription %d: %w", js.ProcessID, err)
+		}
+		sub := COVSubscription{
+			ProcessID: js.ProcessID,
+			Device: bacnet.Device{
+				ID:           bacnet.ObjectID(js.Device.ID),
+				MaxApdu:      js.Device.MaxApdu,
+				Segmentation: js.Device.Segmentation,
+				Vendor:       js.Device.Vendor,
+				Addr:         addr,
+			},
+			ObjectID:  bacnet.ObjectID(js.Object),
+			Confirmed: js.Confirmed,
+			Lifetime:  time.Duration(js.Lifetime) * time.Second,
+		}
+		if js.Expires != nil {
+			sub.Expires = *js.Expires
+		}
+		s.Subscriptions = append(s.Subscriptions, sub)
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package bacip
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/REQUEA/bacnet"
+	"github.com/REQUEA/bacnet/internal/encoding"
+)
+
+// CreateObject asks a device to create an object
+type CreateObject struct {
+	//ObjectType is the type of the object to create, the device
+	//choosing its instance. It's ignored if ObjectID is set
+	ObjectType bacnet.ObjectType
+	//ObjectID is the identifier of the object to create, nil to let
+	//the device choose the instance
+	ObjectID *bacnet.ObjectID
+	//InitialValues are written to the object when it's created
+	InitialValues []PropertyWrite
+}
+
+func (co CreateObject) MarshalBinary() ([]byte, error) {
+	encoder := encoding.NewEncoder()
+	encoder.OpeningTag(0)
+	if co.ObjectID != nil {
+		encoder.ContextObjectID(1, *co.ObjectID)
+	} else {
+		encoder.ContextUnsigned(0, uint32(co.ObjectType))
+	}
+	encoder.ClosingTag(0)
+	if len(co.InitialValues) > 0 {
+		encoder.OpeningTag(1)
+		encodePropertyWrites(&encoder, co.InitialValues)
+		encoder.ClosingTag(1)
+	}
+	return encoder.Bytes(), encoder.Error()
+}
+
+// UnmarshalBinary decodes a CreateObject acknowledgment: the
+// identifier of the object created is stored in ObjectID
+func (co *CreateObject) UnmarshalBinary(data []byte) error {
+	decoder := encoding.NewDecoder(data)
+	co.ObjectID = new(bacnet.ObjectID)
+	decoder.AppData(co.ObjectID)
+	co.ObjectType = co.ObjectID.Type
+	return decoder.Error()
+}
+
+// CreateObjectError is returned when a device refuses to create an
+// object
+type CreateObjectError struct {
+	Err ApduError
+	//FirstFailedElement is the position, starting at 1, of the
+	//initial value that caused the failure, 0 if the failure isn't
+	//due to an initial value
+	FirstFailedElement uint32
+}
+
+func (e CreateObjectError) Error() string {
+	if e.FirstFailedElement == 0 {
+		return fmt.Sprintf("create object: %v", e.Err)
+	}
+	return fmt.Sprintf("create object: initial value %d: %v", e.FirstFailedElement, e.Err)
+}
+
+func (e CreateObjectError) Unwrap() error {
+	return e.Err
+}
+
+func (e CreateObjectError) MarshalBinary() ([]byte, error) {
+	encoder := encoding.NewEncoder()
+	encoder.OpeningTag(0)
+	e.Err.encode(&encoder)
+	encoder.ClosingTag(0)
+	encoder.ContextUnsigned(1, e.FirstFailedElement)
+	return encoder.Bytes(), encoder.Error()
+}
+
+func (e *CreateObjectError) UnmarshalBinary(data []byte) error {
+	decoder := encoding.NewDecoder(data)
+	decoder.OpeningTag(0)
+	e.Err.decode(decoder)
+	decoder.ClosingTag(0)
+	decoder.ContextValue(1, &e.FirstFailedElement)
+	return decoder.Error()
+}
+
+// DeleteObject asks a device to delete an object
+type DeleteObject struct {
+	ObjectID bacnet.ObjectID
+}
+
+func (do DeleteObject) MarshalBinary() ([]byte, error) {
+	encoder := encoding.NewEncoder()
+	encoder.AppData(do.ObjectID)
+	return encoder.Bytes(), encoder.Error()
+}
+
+func (do *DeleteObject) UnmarshalBinary(data []byte) error {
+	decoder := encoding.NewDecoder(data)
+	decoder.AppData(&do.ObjectID)
+	return decoder.Error()
+}
+
+// CreateObject creates an object in the device and returns its
+// identifier. If the device refuses, a CreateObjectError tells which
+// initial value caused the failure
+func (c *Client) CreateObject(ctx context.Context, device bacnet.Device, create CreateObject) (bacnet.ObjectID, error) {
+	err := c.checkRequest(device.ID, &create)
+	if err != nil {
+		return bacnet.ObjectID{}, err
+	}
+	apdu, err := c.confirmedRequest(ctx, device, ServiceConfirmedCreateObject, &create)
+	if err != nil {
+		return bacnet.ObjectID{}, err
+	}
+	if apdu.DataType == ComplexAck && apdu.ServiceType == ServiceConfirmedCreateObject {
+		return *apdu.Payload.(*CreateObject).ObjectID, nil
+	}
+	return bacnet.ObjectID{}, errors.New("invalid answer")
+}
+
+// DeleteObject deletes an object of the device
+func (c *Client) DeleteObject(ctx context.Context, device bacnet.Device, object bacnet.ObjectID) error {
+	req := DeleteObject{ObjectID: object}
+	err := c.checkRequest(device.ID, &req)
+	if err != nil {
+		return err
+	}
+	if rc := c.cache(); rc != nil {
+		defer rc.InvalidateObject(device.ID, object)
+	}
+	apdu, err := c.confirmedRequest(ctx, device, ServiceConfirmedDeleteObject, &req)
+	if err != nil {
+		return err
+	}
+	if apdu.DataType == SimpleAck {
+		return nil
+	}
+	return errors.New("invalid answer")
+}
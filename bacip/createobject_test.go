@@ -0,0 +1,125 @@
+package bacip
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/REQUEA/bacnet"
+	"github.com/REQUEA/bacnet/internal/encoding"
+	"github.com/matryer/is"
+)
+
+func TestCreateObjectEncoding(t *testing.T) {
+	is := is.New(t)
+	co := CreateObject{
+		ObjectType: bacnet.Trendlog,
+		InitialValues: []PropertyWrite{{
+			Property: bacnet.PropertyIdentifier{Type: bacnet.ObjectName},
+			Value:    bacnet.PropertyValue{Type: bacnet.TypeCharacterString, Value: "t"},
+		}},
+	}
+	b, err := co.MarshalBinary()
+	is.NoErr(err)
+	is.Equal(hex.EncodeToString(b), "0e09140f1e094d2e7200742f1f")
+
+	id := bacnet.ObjectID{Type: bacnet.NotificationClass, Instance: 3}
+	b, err = CreateObject{ObjectID: &id}.MarshalBinary()
+	is.NoErr(err)
+	is.Equal(hex.EncodeToString(b), "0e1c03c000030f")
+
+	b, err = DeleteObject{ObjectID: id}.MarshalBinary()
+	is.NoErr(err)
+	is.Equal(hex.EncodeToString(b), "c403c00003")
+}
+
+func TestCreateObjectError(t *testing.T) {
+	is := is.New(t)
+	e := CreateObjectError{
+		Err:                ApduError{Class: bacnet.PropertyError, Code: bacnet.ValueOutOfRange},
+		FirstFailedElement: 2,
+	}
+	b, err := e.MarshalBinary()
+	is.NoErr(err)
+	is.Equal(hex.EncodeToString(b), "0e910291250f1902")
+	e2 := CreateObjectError{}
+	is.NoErr(e2.UnmarshalBinary(b))
+	is.Equal(e2, e)
+	var apduErr ApduError
+	is.True(errors.As(error(e2), &apduErr))
+	is.Equal(apduErr.Code, bacnet.ValueOutOfRange)
+}
+
+func TestCreateDeleteObject(t *testing.T) {
+	is := is.New(t)
+	addr := net.UDPAddr{IP: net.IPv4(10, 0, 2, 3).To4(), Port: DefaultUDPPort}
+	device := bacnet.Device{
+		ID:      bacnet.ObjectID{Type: bacnet.BacnetDevice, Instance: 10},
+		MaxApdu: 480,
+		Addr:    *bacnet.AddressFromUDP(addr),
+	}
+	created := bacnet.ObjectID{Type: bacnet.Trendlog, Instance: 7}
+	var deleted []bacnet.ObjectID
+	m := newMemTransport()
+	m.respond = answerRequests(t, func(request *APDU) *APDU {
+		data := request.Payload.(*DataPayload).Bytes
+		answer := &APDU{ServiceType: request.ServiceType, InvokeID: request.InvokeID}
+		switch request.ServiceType {
+		case ServiceConfirmedCreateObject:
+			//Only a single initial value is accepted
+			dec := encoding.NewDecoder(data)
+			dec.OpeningTag(0)
+			var objectType uint32
+			dec.ContextValue(0, &objectType)
+			dec.ClosingTag(0)
+			is.NoErr(dec.Error())
+			is.Equal(bacnet.ObjectType(objectType), bacnet.Trendlog)
+			if len(data) > 20 {
+				answer.DataType = Error
+				answer.Payload = &CreateObjectError{
+					Err:                ApduError{Class: bacnet.PropertyError, Code: bacnet.ValueOutOfRange},
+					FirstFailedElement: 2,
+				}
+				break
+			}
+			e := encoding.NewEncoder()
+			e.AppData(created)
+			answer.DataType = ComplexAck
+			answer.Payload = &DataPayload{Bytes: e.Bytes()}
+		case ServiceConfirmedDeleteObject:
+			var do DeleteObject
+			is.NoErr(do.UnmarshalBinary(data))
+			deleted = append(deleted, do.ObjectID)
+			answer.DataType = SimpleAck
+			answer.Payload = &DataPayload{}
+		default:
+			return nil
+		}
+		return answer
+	})
+	c := newMemClient(t, m)
+
+	name := PropertyWrite{
+		Property: bacnet.PropertyIdentifier{Type: bacnet.ObjectName},
+		Value:    bacnet.PropertyValue{Type: bacnet.TypeCharacterString, Value: "log"},
+	}
+	id, err := c.CreateObject(context.Background(), device, CreateObject{
+		ObjectType:    bacnet.Trendlog,
+		InitialValues: []PropertyWrite{name},
+	})
+	is.NoErr(err)
+	is.Equal(id, created)
+
+	_, err = c.CreateObject(context.Background(), device, CreateObject{
+		ObjectType:    bacnet.Trendlog,
+		InitialValues: []PropertyWrite{name, name},
+	})
+	var coErr CreateObjectError
+	is.True(errors.As(err, &coErr))
+	is.Equal(coErr.FirstFailedElement, uint32(2))
+
+	is.NoErr(c.DeleteObject(context.Background(), device, created))
+	is.Equal(deleted, []bacnet.ObjectID{created})
+}
@@ -0,0 +1,128 @@
+package bacip
+
+import (
+	"fmt"
+
+	"github.com/REQUEA/bacnet"
+	"github.com/REQUEA/bacnet/internal/encoding"
+)
+
+// This file contains the codecs of the constructed data types that
+// are shared between several services
+
+func encodeDateTime(e *encoding.Encoder, dt bacnet.DateTime) {
+	e.AppData(dt.Date)
+	e.AppData(dt.Time)
+}
+
+func decodeDateTime(d *encoding.Decoder, dt *bacnet.DateTime) {
+	d.AppData(&dt.Date)
+	d.AppData(&dt.Time)
+}
+
+func encodeTimeStamp(e *encoding.Encoder, tagNumber byte, ts bacnet.TimeStamp) {
+	e.OpeningTag(tagNumber)
+	encodeTimeStampChoice(e, ts)
+	e.ClosingTag(tagNumber)
+}
+
+// encodeTimeStampChoice writes the time stamp without enclosing tag,
+// as in the value of a property
+func encodeTimeStampChoice(e *encoding.Encoder, ts bacnet.TimeStamp) {
+	switch ts.Kind {
+	case bacnet.TimeStampTime:
+		e.ContextTime(0, ts.Time)
+	case bacnet.TimeStampSequence:
+		e.ContextUnsigned(1, ts.SequenceNumber)
+	default:
+		e.OpeningTag(2)
+		encodeDateTime(e, ts.DateTime)
+		e.ClosingTag(2)
+	}
+}
+
+func decodeTimeStamp(d *encoding.Decoder, tagNumber byte, ts *bacnet.TimeStamp) {
+	d.OpeningTag(tagNumber)
+	decodeTimeStampChoice(d, ts)
+	d.ClosingTag(tagNumber)
+}
+
+func decodeTimeStampChoice(d *encoding.Decoder, ts *bacnet.TimeStamp) {
+	switch {
+	case d.IsContextTag(0):
+		ts.Kind = bacnet.TimeStampTime
+		d.ContextTime(0, &ts.Time)
+	case d.IsContextTag(1):
+		ts.Kind = bacnet.TimeStampSequence
+		d.ContextValue(1, &ts.SequenceNumber)
+	default:
+		ts.Kind = bacnet.TimeStampDateTime
+		d.OpeningTag(2)
+		decodeDateTime(d, &ts.DateTime)
+		d.ClosingTag(2)
+	}
+}
+
+// decodeList calls decodeItem until the data is consumed
+func decodeList(data []byte, decodeItem func(d *encoding.Decoder) error) error {
+	d := encoding.NewDecoder(data)
+	for i := 0; d.Len() > 0; i++ {
+		err := decodeItem(d)
+		if err == nil {
+			err = d.Error()
+		}
+		if err != nil {
+			return fmt.Errorf("decode item %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// DeviceObjectPropertyReference references a property of an object,
+// optionally located in another device
+type DeviceObjectPropertyReference struct {
+	ObjectID bacnet.ObjectID
+	Property bacnet.PropertyIdentifier
+	//Device is nil if the object is in the same device
+	Device *bacnet.ObjectID
+}
+
+func (r DeviceObjectPropertyReference) MarshalBinary() ([]byte, error) {
+	encoder := encoding.NewEncoder()
+	r.encode(&encoder)
+	return encoder.Bytes(), encoder.Error()
+}
+
+func (r *DeviceObjectPropertyReference) UnmarshalBinary(data []byte) error {
+	decoder := encoding.NewDecoder(data)
+	r.decode(decoder)
+	return decoder.Error()
+}
+
+func (r DeviceObjectPropertyReference) encode(e *encoding.Encoder) {
+	e.ContextObjectID(0, r.ObjectID)
+	e.ContextUnsigned(1, uint32(r.Property.Type))
+	if r.Property.ArrayIndex != nil {
+		e.ContextUnsigned(2, *r.Property.ArrayIndex)
+	}
+	if r.Device != nil {
+		e.ContextObjectID(3, *r.Device)
+	}
+}
+
+func (r *DeviceObjectPropertyReference) decode(d *encoding.Decoder) {
+	d.ContextObjectID(0, &r.ObjectID)
+	var val uint32
+	d.ContextValue(1, &val)
+	r.Property.Type = bacnet.PropertyType(val)
+	r.Property.ArrayIndex = nil
+	if d.IsContextTag(2) {
+		r.Property.ArrayIndex = new(uint32)
+		d.ContextValue(2, r.Property.ArrayIndex)
+	}
+	r.Device = nil
+	if d.IsContextTag(3) {
+		r.Device = new(bacnet.ObjectID)
+		d.ContextObjectID(3, r.Device)
+	}
+}
@@ -0,0 +1,223 @@
+package bacip
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"html/template"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxDecodeErrors is the number of decode errors kept for debugging
+const maxDecodeErrors = 32
+
+// DecodeError is a datagram that couldn't be decoded
+type DecodeError struct {
+	Time   time.Time `json:"time"`
+	Source string    `json:"source"`
+	Error  string    `json:"error"`
+	//Data is the datagram in hexadecimal
+	Data string `json:"data"`
+}
+
+// decodeErrors keeps the most recent decode errors
+type decodeErrors struct {
+	sync.Mutex
+	errors []DecodeError
+	next   int
+}
+
+func (d *decodeErrors) add(src *net.UDPAddr, data []byte, err error) {
+	d.Lock()
+	defer d.Unlock()
+	e := DecodeError{
+		Time:   time.Now(),
+		Source: src.String(),
+		Error:  err.Error(),
+		Data:   hex.EncodeToString(data),
+	}
+	if len(d.errors) < maxDecodeErrors {
+		d.errors = append(d.errors, e)
+		return
+	}
+	d.errors[d.next] = e
+	d.next = (d.next + 1) % maxDecodeErrors
+}
+
+// list returns the errors, the most recent first
+func (d *decodeErrors) list() []DecodeError {
+	d.Lock()
+	defer d.Unlock()
+	result := make([]DecodeError, 0, len(d.errors))
+	for i := range d.errors {
+		result = append(result, d.errors[(d.next+len(d.errors)-1-i)%len(d.errors)])
+	}
+	return result
+}
+
+// confirmedServiceNames are the names of the confirmed services
+var confirmedServiceNames = map[ServiceType]string{
+	ServiceConfirmedAcknowledgeAlarm:           "AcknowledgeAlarm",
+	ServiceConfirmedCOVNotification:            "COVNotification",
+	ServiceConfirmedEventNotification:          "EventNotification",
+	ServiceConfirmedGetAlarmSummary:            "GetAlarmSummary",
+	ServiceConfirmedGetEnrollmentSummary:       "GetEnrollmentSummary",
+	ServiceConfirmedGetEventInformation:        "GetEventInformation",
+	ServiceConfirmedSubscribeCOV:               "SubscribeCOV",
+	ServiceConfirmedSubscribeCOVProperty:       "SubscribeCOVProperty",
+	ServiceConfirmedLifeSafetyOperation:        "LifeSafetyOperation",
+	ServiceConfirmedAtomicReadFile:             "AtomicReadFile",
+	ServiceConfirmedAtomicWriteFile:            "AtomicWriteFile",
+	ServiceConfirmedAddListElement:             "AddListElement",
+	ServiceConfirmedRemoveListElement:          "RemoveListElement",
+	ServiceConfirmedCreateObject:               "CreateObject",
+	ServiceConfirmedDeleteObject:               "DeleteObject",
+	ServiceConfirmedReadProperty:               "ReadProperty",
+	ServiceConfirmedReadPropConditional:        "ReadPropConditional",
+	ServiceConfirmedReadPropMultiple:           "ReadPropMultiple",
+	ServiceConfirmedReadRange:                  "ReadRange",
+	ServiceConfirmedWriteProperty:              "WriteProperty",
+	ServiceConfirmedWritePropMultiple:          "WritePropMultiple",
+	ServiceConfirmedDeviceCommunicationControl: "DeviceCommunicationControl",
+	ServiceConfirmedPrivateTransfer:            "PrivateTransfer",
+	ServiceConfirmedTextMessage:                "TextMessage",
+	ServiceConfirmedReinitializeDevice:         "ReinitializeDevice",
+	ServiceConfirmedVTOpen:                     "VTOpen",
+	ServiceConfirmedVTClose:                    "VTClose",
+	ServiceConfirmedVTData:                     "VTData",
+	ServiceConfirmedAuthenticate:               "Authenticate",
+	ServiceConfirmedRequestKey:                 "RequestKey",
+}
+
+// confirmedServiceName returns the name of a confirmed service
+func confirmedServiceName(s ServiceType) string {
+	if name, ok := confirmedServiceNames[s]; ok {
+		return name
+	}
+	return "ServiceType(" + strconv.Itoa(int(s)) + ")"
+}
+
+// TransactionInfo describes a confirmed request waiting for an answer
+type TransactionInfo struct {
+	InvokeID    byte          `json:"invokeId"`
+	Service     string        `json:"service"`
+	Destination string        `json:"destination"`
+	Age         time.Duration `json:"age"`
+}
+
+// SeenDevice is a device that recently answered a WhoIs
+type SeenDevice struct {
+	ID      string    `json:"id"`
+	Address string    `json:"address"`
+	Seen    time.Time `json:"seen"`
+}
+
+// DebugInfo is a snapshot of the internals of a client
+type DebugInfo struct {
+	LocalAddress     string `json:"localAddress"`
+	BroadcastAddress string `json:"broadcastAddress"`
+	//BBMD is the BBMD the client is registered with as a foreign
+	//device, if any
+	BBMD             string            `json:"bbmd,omitempty"`
+	Transactions     []TransactionInfo `json:"transactions"`
+	Subscriptions    int               `json:"subscriptions"`
+	RecentDevices    []SeenDevice      `json:"recentDevices"`
+	KnownDevices     int               `json:"knownDevices"`
+	ReadCacheEntries int               `json:"readCacheEntries"`
+	DecodeErrors     []DecodeError     `json:"decodeErrors"`
+	//DroppedDuplicates is the number of unconfirmed requests dropped
+	//by SetUnconfirmedDedup
+	DroppedDuplicates int `json:"droppedDuplicates"`
+}
+
+// DebugInfo returns a snapshot of the internals of the client
+func (c *Client) DebugInfo() DebugInfo {
+	info := DebugInfo{
+		LocalAddress:      (&net.UDPAddr{IP: c.ipAddress, Port: c.udpPort}).String(),
+		BroadcastAddress:  (&net.UDPAddr{IP: c.broadcastAddress, Port: DefaultUDPPort}).String(),
+		Transactions:      []TransactionInfo{},
+		RecentDevices:     []SeenDevice{},
+		DecodeErrors:      c.decodeErrors.list(),
+		DroppedDuplicates: c.dedup.droppedCount(),
+	}
+	if status, ok := c.ForeignDeviceStatus(); ok && status.BBMD != nil {
+		info.BBMD = status.BBMD.String()
+	}
+	now := time.Now()
+	c.transactions.Lock()
+	for id, tx := range c.transactions.currents {
+		info.Transactions = append(info.Transactions, TransactionInfo{
+			InvokeID:    id,
+			Service:     confirmedServiceName(tx.service),
+			Destination: tx.destination.String(),
+			Age:         now.Sub(tx.started),
+		})
+	}
+	c.transactions.Unlock()
+	sort.Slice(info.Transactions, func(i, j int) bool {
+		return info.Transactions[i].InvokeID < info.Transactions[j].InvokeID
+	})
+	c.subscriptions.RLock()
+	info.Subscriptions = len(c.subscriptions.subs)
+	c.subscriptions.RUnlock()
+	for _, r := range c.whoIs.received(time.Time{}) {
+		info.RecentDevices = append(info.RecentDevices, SeenDevice{
+			ID:      r.iam.ObjectID.String(),
+			Address: r.addr.String(),
+			Seen:    r.at,
+		})
+	}
+	c.registry.RLock()
+	info.KnownDevices = len(c.registry.devices)
+	c.registry.RUnlock()
+	if rc := c.cache(); rc != nil {
+		rc.Lock()
+		info.ReadCacheEntries = len(rc.entries)
+		rc.Unlock()
+	}
+	return info
+}
+
+var debugTemplate = template.Must(template.New("debug").Parse(`<!DOCTYPE html>
+<html><head><title>bacip client</title></head><body>
+<h1>bacip client</h1>
+<p>Local address {{.LocalAddress}}, broadcast {{.BroadcastAddress}}{{if .BBMD}}, foreign device of {{.BBMD}}{{end}}</p>
+<p>{{.Subscriptions}} subscriptions, {{.KnownDevices}} known devices, {{.ReadCacheEntries}} cached properties, {{.DroppedDuplicates}} dropped duplicates</p>
+<h2>Transactions</h2>
+<table><tr><th>Invoke ID</th><th>Service</th><th>Destination</th><th>Age</th></tr>
+{{range .Transactions}}<tr><td>{{.InvokeID}}</td><td>{{.Service}}</td><td>{{.Destination}}</td><td>{{.Age}}</td></tr>
+{{end}}</table>
+<h2>Recent devices</h2>
+<table><tr><th>Device</th><th>Address</th><th>Seen</th></tr>
+{{range .RecentDevices}}<tr><td>{{.ID}}</td><td>{{.Address}}</td><td>{{.Seen}}</td></tr>
+{{end}}</table>
+<h2>Decode errors</h2>
+<table><tr><th>Time</th><th>Source</th><th>Error</th><th>Data</th></tr>
+{{range .DecodeErrors}}<tr><td>{{.Time}}</td><td>{{.Source}}</td><td>{{.Error}}</td><td><code>{{.Data}}</code></td></tr>
+{{end}}</table>
+</body></html>
+`))
+
+// DebugHandler returns an HTTP handler serving DebugInfo, as JSON or
+// as an HTML page when requested by a browser or with ?format=html.
+// It isn't registered anywhere, mount it on a private endpoint:
+//
+//	http.Handle("/debug/bacnet", client.DebugHandler())
+func (c *Client) DebugHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		info := c.DebugInfo()
+		format := r.URL.Query().Get("format")
+		if format == "html" || (format == "" && strings.Contains(r.Header.Get("Accept"), "text/html")) {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			_ = debugTemplate.Execute(w, info)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(info)
+	})
+}
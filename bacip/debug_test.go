@@ -0,0 +1,49 @@
+package bacip
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestDecodeErrors(t *testing.T) {
+	is := is.New(t)
+	d := decodeErrors{}
+	src := &net.UDPAddr{IP: net.IPv4(10, 0, 0, 1), Port: DefaultUDPPort}
+	for i := 0; i < maxDecodeErrors+2; i++ {
+		d.add(src, []byte{byte(i)}, errors.New("bad"))
+	}
+	list := d.list()
+	is.Equal(len(list), maxDecodeErrors)
+	is.Equal(list[0].Data, "21")
+	is.Equal(list[len(list)-1].Data, "02")
+}
+
+func TestDebugHandler(t *testing.T) {
+	is := is.New(t)
+	m := newMemTransport()
+	c := newMemClient(t, m)
+	m.in <- datagram{data: []byte{0x81, 0x0a, 0x00}, addr: &net.UDPAddr{IP: net.IPv4(10, 0, 2, 3), Port: DefaultUDPPort}}
+	deadline := time.Now().Add(time.Second)
+	for len(c.decodeErrors.list()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	rec := httptest.NewRecorder()
+	c.DebugHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/debug", nil))
+	var info DebugInfo
+	is.NoErr(json.NewDecoder(rec.Body).Decode(&info))
+	is.Equal(info.BroadcastAddress, "10.0.2.255:47808")
+	is.Equal(len(info.DecodeErrors), 1)
+	is.Equal(info.DecodeErrors[0].Source, "10.0.2.3:47808")
+
+	rec = httptest.NewRecorder()
+	c.DebugHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/debug?format=html", nil))
+	is.True(strings.Contains(rec.Body.String(), "10.0.2.3:47808"))
+}
@@ -0,0 +1,123 @@
+package bacip
+
+import (
+	"net"
+	"sync"
+	"time"
+)
+
+// SetUnconfirmedDedup sets the duration during which an unconfirmed
+// request (IAm, COV or event notification...) identical to a previous
+// one from the same device is dropped. When several BBMDs forward the
+// same broadcast, the client receives it once per BBMD: the copies
+// are dropped before reaching the subscriptions and callbacks. A
+// duration of 0, the default, disables deduplication. The IAm and
+// IHave answering a WhoIs or WhoHas sent by the client are never
+// dropped as copies of the answers to a previous request
+func (c *Client) SetUnconfirmedDedup(window time.Duration) {
+	c.dedup.Lock()
+	defer c.dedup.Unlock()
+	c.dedup.window = window
+	if window == 0 {
+		c.dedup.seen = nil
+		c.dedup.requested = nil
+	}
+}
+
+// unconfirmedDedup remembers the recent unconfirmed requests
+type unconfirmedDedup struct {
+	sync.Mutex
+	window  time.Duration
+	seen    map[dedupKey]time.Time
+	pruneAt int
+	//requested is when the last request answered by the service was sent
+	requested map[ServiceType]time.Time
+	dropped   int
+}
+
+type dedupKey struct {
+	source  string
+	service ServiceType
+	payload string
+}
+
+// duplicate tells if the APDU was already received from src during
+// the window, and remembers it otherwise. Only unconfirmed requests
+// are deduplicated
+func (d *unconfirmedDedup) duplicate(src *net.UDPAddr, apdu *APDU, now time.Time) bool {
+	if apdu.DataType != UnconfirmedServiceRequest || apdu.Payload == nil {
+		return false
+	}
+	d.Lock()
+	defer d.Unlock()
+	if d.window == 0 {
+		return false
+	}
+	payload, err := apdu.Payload.MarshalBinary()
+	if err != nil {
+		return false
+	}
+	key := dedupKey{source: src.String(), service: apdu.ServiceType, payload: string(payload)}
+	if d.seen == nil {
+		d.seen = map[dedupKey]time.Time{}
+		d.pruneAt = minPruneSize
+	}
+	if at, ok := d.seen[key]; ok && now.Sub(at) < d.window && !at.Before(d.requested[apdu.ServiceType]) {
+		d.dropped++
+		return true
+	}
+	if len(d.seen) >= d.pruneAt {
+		for k, at := range d.seen {
+			if now.Sub(at) >= d.window {
+				delete(d.seen, k)
+			}
+		}
+		d.pruneAt = 2 * len(d.seen)
+		if d.pruneAt < minPruneSize {
+			d.pruneAt = minPruneSize
+		}
+	}
+	d.seen[key] = now
+	return false
+}
+
+// answeredBy returns the service of the unconfirmed answers to the
+// request, if any
+func answeredBy(apdu *APDU) (ServiceType, bool) {
+	if apdu == nil || apdu.DataType != UnconfirmedServiceRequest {
+		return 0, false
+	}
+	switch apdu.ServiceType {
+	case ServiceUnconfirmedWhoIs:
+		return ServiceUnconfirmedIAm, true
+	case ServiceUnconfirmedWhoHas:
+		return ServiceUnconfirmedIHave, true
+	}
+	return 0, false
+}
+
+// sent records that the client sends the APDU, for the answers to a
+// request not to be dropped as copies of the answers to a previous
+// one
+func (d *unconfirmedDedup) sent(apdu *APDU, now time.Time) {
+	service, ok := answeredBy(apdu)
+	if !ok {
+		return
+	}
+	d.Lock()
+	defer d.Unlock()
+	if d.window == 0 {
+		return
+	}
+	if d.requested == nil {
+		d.requested = map[ServiceType]time.Time{}
+	}
+	d.requested[service] = now
+}
+
+// droppedCount returns the number of duplicates dropped
+func (d *unconfirmedDedup) droppedCount() int {
+	d.Lock()
+	defer d.Unlock()
+	return d.dropped
+}
@@ -0,0 +1,125 @@
+package bacip
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/REQUEA/bacnet"
+	"github.com/matryer/is"
+)
+
+func TestUnconfirmedDedup(t *testing.T) {
+	is := is.New(t)
+	src := &net.UDPAddr{IP: net.IPv4(10, 0, 2, 3).To4(), Port: DefaultUDPPort}
+	iam := func(instance bacnet.ObjectInstance) *APDU {
+		return &APDU{
+			DataType:    UnconfirmedServiceRequest,
+			ServiceType: ServiceUnconfirmedIAm,
+			Payload: &Iam{
+				ObjectID:            bacnet.ObjectID{Type: bacnet.BacnetDevice, Instance: instance},
+				MaxApduLength:       1476,
+				SegmentationSupport: bacnet.SegmentationSupportNone,
+			},
+		}
+	}
+	now := time.Now()
+	d := unconfirmedDedup{}
+	is.True(!d.duplicate(src, iam(1), now))
+	is.True(!d.duplicate(src, iam(1), now))
+
+	d.window = time.Second
+	is.True(!d.duplicate(src, iam(1), now))
+	is.True(d.duplicate(src, iam(1), now.Add(100*time.Millisecond)))
+	is.True(!d.duplicate(src, iam(2), now.Add(100*time.Millisecond)))
+	other := &net.UDPAddr{IP: net.IPv4(10, 0, 2, 4).To4(), Port: DefaultUDPPort}
+	is.True(!d.duplicate(other, iam(1), now.Add(100*time.Millisecond)))
+	//The window is over
+	is.True(!d.duplicate(src, iam(1), now.Add(2*time.Second)))
+	is.Equal(d.droppedCount(), 1)
+
+	//Confirmed requests are never dropped
+	confirmed := iam(1)
+	confirmed.DataType = ConfirmedServiceRequest
+	is.True(!d.duplicate(src, confirmed, now))
+	is.True(!d.duplicate(src, confirmed, now))
+
+	//The IAm answering a new WhoIs isn't a copy of the previous
+	//answer, but its own copies are
+	now = now.Add(time.Minute)
+	is.True(!d.duplicate(src, iam(1), now))
+	d.sent(&APDU{DataType: UnconfirmedServiceRequest, ServiceType: ServiceUnconfirmedWhoIs, Payload: &WhoIs{}}, now.Add(100*time.Millisecond))
+	is.True(!d.duplicate(src, iam(1), now.Add(200*time.Millisecond)))
+	is.True(d.duplicate(src, iam(1), now.Add(300*time.Millisecond)))
+	is.Equal(d.droppedCount(), 2)
+}
+
+func TestUnconfirmedDedupPrune(t *testing.T) {
+	is := is.New(t)
+	d := unconfirmedDedup{window: time.Second}
+	now := time.Now()
+	for i := 0; i < minPruneSize; i++ {
+		src := &net.UDPAddr{IP: net.IPv4(10, 0, byte(i>>8), byte(i)).To4(), Port: DefaultUDPPort}
+		is.True(!d.duplicate(src, &APDU{
+			DataType:    UnconfirmedServiceRequest,
+			ServiceType: ServiceUnconfirmedWhoIs,
+			Payload:     &WhoIs{},
+		}, now))
+	}
+	is.Equal(len(d.seen), minPruneSize)
+	//The expired entries are pruned once the threshold is reached
+	src := &net.UDPAddr{IP: net.IPv4(10, 1, 0, 1).To4(), Port: DefaultUDPPort}
+	is.True(!d.duplicate(src, &APDU{
+		DataType:    UnconfirmedServiceRequest,
+		ServiceType: ServiceUnconfirmedWhoIs,
+		Payload:     &WhoIs{},
+	}, now.Add(2*time.Second)))
+	is.Equal(len(d.seen), 1)
+	is.Equal(d.pruneAt, minPruneSize)
+}
+
+func TestUnconfirmedDedupClient(t *testing.T) {
+	is := is.New(t)
+	m := newMemTransport()
+	c := newMemClient(t, m)
+	c.SetUnconfirmedDedup(time.Second)
+	received := make(chan struct{}, 4)
+	unsubscribe := c.subscriptions.subscribe(func(bvlc BVLC, _ net.UDPAddr) {
+		received <- struct{}{}
+	})
+	defer unsubscribe()
+
+	src := &net.UDPAddr{IP: net.IPv4(10, 0, 2, 3).To4(), Port: DefaultUDPPort}
+	apdu := &APDU{
+		DataType:    UnconfirmedServiceRequest,
+		ServiceType: ServiceUnconfirmedIAm,
+		Payload: &Iam{
+			ObjectID:            bacnet.ObjectID{Type: bacnet.BacnetDevice, Instance: 1},
+			MaxApduLength:       1476,
+			SegmentationSupport: bacnet.SegmentationSupportNone,
+		},
+	}
+	direct, err := datagramOf(apdu)
+	is.NoErr(err)
+	forwarded, err := BVLC{
+		Type:     TypeBacnetIP,
+		Function: BacFuncForwardedNPDU,
+		Origin:   src,
+		NPDU:     NPDU{Version: Version1, Priority: Normal, ADPU: apdu},
+	}.MarshalBinary()
+	is.NoErr(err)
+	//The same IAm received directly and forwarded by two BBMDs
+	is.NoErr(c.handleMessage(src, direct))
+	is.NoErr(c.handleMessage(&net.UDPAddr{IP: net.IPv4(10, 0, 3, 1).To4(), Port: DefaultUDPPort}, forwarded))
+	is.NoErr(c.handleMessage(&net.UDPAddr{IP: net.IPv4(10, 0, 4, 1).To4(), Port: DefaultUDPPort}, forwarded))
+	is.Equal(len(received), 1)
+	is.Equal(c.DebugInfo().DroppedDuplicates, 2)
+
+	//A new WhoIs gets the IAm again
+	_, err = c.WhoIs(WhoIs{}, 0)
+	is.NoErr(err)
+	is.NoErr(c.handleMessage(src, direct))
+	is.NoErr(c.handleMessage(&net.UDPAddr{IP: net.IPv4(10, 0, 3, 1).To4(), Port: DefaultUDPPort}, forwarded))
+	is.Equal(len(received), 2)
+	is.Equal(c.DebugInfo().DroppedDuplicates, 3)
+}
@@ -0,0 +1,225 @@
+package bacip
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
+
+// Severity tells if a finding of the diagnostics prevents the client
+// from working
+type Severity int
+
+//go:generate stringer -type=Severity
+const (
+	SeverityInfo    Severity = 0
+	SeverityWarning Severity = 1
+	SeverityError   Severity = 2
+)
+
+// Finding is the result of a check of the diagnostics
+type Finding struct {
+	Check    string
+	Severity Severity
+	Message  string
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("%v: %s: %s", f.Severity, f.Check, f.Message)
+}
+
+// DiagnosticsOptions configures Diagnose
+type DiagnosticsOptions struct {
+	//Wait is how long the answers to the broadcast WhoIs are waited
+	//for, 2s if 0
+	Wait time.Duration
+	//BBMD, if set, is sent a Read-Broadcast-Distribution-Table request
+	//to check it's reachable
+	BBMD *net.UDPAddr
+	//DetectBBMDs looks for the BBMDs of the local network, of which
+	//there must be at most one
+	DetectBBMDs bool
+}
+
+// interfaceAddrs and listenUDP are replaced by the tests
+var (
+	interfaceAddrs = net.InterfaceAddrs
+	listenUDP      = func(port int) (Transport, error) {
+		return net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4zero, Port: port})
+	}
+)
+
+// Diagnose checks the environment of the client and returns what
+// should be fixed before polling devices: the address and broadcast
+// address chosen, the other BACnet applications on the host, the
+// devices answering a broadcast WhoIs and the BBMD. It sends a
+// broadcast WhoIs, and should be run when the application starts
+func (c *Client) Diagnose(ctx context.Context, opts DiagnosticsOptions) []Finding {
+	var findings []Finding
+	add := func(check string, severity Severity, format string, args ...interface{}) {
+		findings = append(findings, Finding{Check: check, Severity: severity, Message: fmt.Sprintf(format, args...)})
+	}
+	c.checkAddress(add)
+	c.checkPort(add)
+	c.checkBroadcast(ctx, opts.Wait, add)
+	if opts.BBMD != nil {
+		err := c.pingBBMD(ctx, opts.BBMD)
+		if err != nil {
+			add("bbmd", SeverityError, "BBMD %v didn't answer: %v", opts.BBMD, err)
+		} else {
+			add("bbmd", SeverityInfo, "BBMD %v answered", opts.BBMD)
+		}
+	}
+	if opts.DetectBBMDs {
+		c.checkBBMDs(ctx, opts.Wait, add)
+	}
+	return findings
+}
+
+func (c *Client) checkAddress(add func(string, Severity, string, ...interface{})) {
+	const check = "address"
+	if c.ipAddress.IsLoopback() {
+		add(check, SeverityError, "%v is a loopback address, devices can't be reached", c.ipAddress)
+	}
+	if c.broadcastAddress.Equal(c.ipAddress) {
+		add(check, SeverityError, "the network of %v has a single address, broadcasts reach no device", c.ipAddress)
+	}
+	addrs, err := interfaceAddrs()
+	if err != nil {
+		add(check, SeverityWarning, "list interface addresses: %v", err)
+		return
+	}
+	for _, a := range addrs {
+		ipnet, ok := a.(*net.IPNet)
+		if !ok || !ipnet.IP.Equal(c.ipAddress) {
+			continue
+		}
+		broadcast, err := broadcastAddr(ipnet)
+		if err == nil && !broadcast.Equal(c.broadcastAddress) {
+			add(check, SeverityWarning, "broadcast address %v doesn't match %v of the interface", c.broadcastAddress, broadcast)
+			return
+		}
+		add(check, SeverityInfo, "using %v, broadcasting to %v", c.ipAddress, c.broadcastAddress)
+		return
+	}
+	add(check, SeverityError, "%v isn't assigned to any interface", c.ipAddress)
+}
+
+func (c *Client) checkPort(add func(string, Severity, string, ...interface{})) {
+	const check = "port"
+	if c.udpPort == DefaultUDPPort {
+		add(check, SeverityInfo, "bound on port %d", c.udpPort)
+		return
+	}
+	add(check, SeverityWarning, "bound on port %d: the IAm and COV notifications broadcast by devices to port %d aren't received", c.udpPort, DefaultUDPPort)
+	conn, err := listenUDP(DefaultUDPPort)
+	if err != nil {
+		add(check, SeverityWarning, "port %d is used by another application, probably another BACnet application: %v", DefaultUDPPort, err)
+		return
+	}
+	conn.Close()
+}
+
+func (c *Client) checkBroadcast(ctx context.Context, wait time.Duration, add func(string, Severity, string, ...interface{})) {
+	const check = "broadcast"
+	if wait <= 0 {
+		wait = 2 * time.Second
+	}
+	type received struct {
+		src net.UDPAddr
+		iam bool
+	}
+	messages := make(chan received, 256)
+	unsubscribe := c.subscriptions.subscribe(func(bvlc BVLC, src net.UDPAddr) {
+		apdu := bvlc.NPDU.ADPU
+		iam := apdu != nil && apdu.DataType == UnconfirmedServiceRequest && apdu.ServiceType == ServiceUnconfirmedIAm
+		select {
+		case messages <- received{src: src, iam: iam}:
+		default:
+		}
+	})
+	defer unsubscribe()
+	_, err := c.broadcast(NPDU{
+		Version:  Version1,
+		Priority: Normal,
+		ADPU: &APDU{
+			DataType:    UnconfirmedServiceRequest,
+			ServiceType: ServiceUnconfirmedWhoIs,
+			Payload:     &WhoIs{},
+		},
+	})
+	if err != nil {
+		add(check, SeverityError, "send WhoIs: %v", err)
+		return
+	}
+	devices := map[string]bool{}
+	others := map[string]bool{}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+wait:
+	for {
+		select {
+		case m := <-messages:
+			if m.src.IP.Equal(c.ipAddress) && m.src.Port != c.udpPort {
+				others[m.src.String()] = true
+			}
+			if m.iam {
+				devices[m.src.String()] = true
+			}
+		case <-timer.C:
+			break wait
+		case <-ctx.Done():
+			add(check, SeverityWarning, "interrupted: %v", ctx.Err())
+			return
+		}
+	}
+	for addr := range others {
+		add(check, SeverityWarning, "another BACnet application runs on this host at %s", addr)
+	}
+	if len(devices) == 0 {
+		add(check, SeverityError, "no device answered a broadcast WhoIs within %v: check the broadcast address, the firewall and the BBMD", wait)
+		return
+	}
+	add(check, SeverityInfo, "%d devices answered a broadcast WhoIs", len(devices))
+}
+
+func (c *Client) checkBBMDs(ctx context.Context, wait time.Duration, add func(string, Severity, string, ...interface{})) {
+	const check = "bbmds"
+	if wait <= 0 {
+		wait = 2 * time.Second
+	}
+	bbmds, err := c.DiscoverBBMDs(ctx, wait)
+	if err != nil {
+		add(check, SeverityWarning, "look for BBMDs: %v", err)
+		return
+	}
+	if len(bbmds) == 0 {
+		add(check, SeverityInfo, "no BBMD on the local network")
+		return
+	}
+	for _, b := range bbmds {
+		switch {
+		case b.BDT == nil:
+			add(check, SeverityInfo, "%v forwards broadcasts but didn't send its BDT", b.Addr.String())
+		case b.ForeignDevices == nil:
+			add(check, SeverityInfo, "BBMD at %v, %d BDT entries", b.Addr.String(), len(b.BDT))
+		default:
+			add(check, SeverityInfo, "BBMD at %v, %d BDT entries, %d foreign devices", b.Addr.String(), len(b.BDT), len(b.ForeignDevices))
+		}
+	}
+	if len(bbmds) > 1 {
+		add(check, SeverityWarning, "%d BBMDs on the local network: the broadcasts are forwarded several times", len(bbmds))
+	}
+}
+
+// pingBBMD reads the broadcast distribution table of the BBMD, which
+// any BBMD answers, with the table or a NAK
+func (c *Client) pingBBMD(ctx context.Context, bbmd *net.UDPAddr) error {
+	_, err := c.ReadBDT(ctx, bbmd)
+	if errors.Is(err, errBDTNAK) {
+		return nil
+	}
+	return err
+}
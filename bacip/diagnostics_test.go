@@ -0,0 +1,78 @@
+package bacip
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/REQUEA/bacnet"
+	"github.com/matryer/is"
+)
+
+func TestDiagnose(t *testing.T) {
+	is := is.New(t)
+	defer func(addrs func() ([]net.Addr, error), listen func(int) (Transport, error)) {
+		interfaceAddrs, listenUDP = addrs, listen
+	}(interfaceAddrs, listenUDP)
+	interfaceAddrs = func() ([]net.Addr, error) {
+		return []net.Addr{&net.IPNet{IP: net.IPv4(10, 0, 2, 2), Mask: net.CIDRMask(24, 32)}}, nil
+	}
+	listenUDP = func(int) (Transport, error) {
+		return nil, errors.New("address already in use")
+	}
+	bbmd := &net.UDPAddr{IP: net.IPv4(10, 0, 9, 1).To4(), Port: DefaultUDPPort}
+	m := newMemTransport()
+	m.respond = func(b []byte, addr *net.UDPAddr) []byte {
+		var bvlc BVLC
+		if bvlc.UnmarshalBinary(b) != nil {
+			return nil
+		}
+		if bvlc.Function == BacFuncBroadcastDistributionTable && addr.IP.Equal(bbmd.IP) {
+			ack, _ := BVLC{Type: TypeBacnetIP, Function: BacFuncBroadcastDistributionTableAck}.MarshalBinary()
+			return ack
+		}
+		if bvlc.Function != BacFuncBroadcast {
+			return nil
+		}
+		iam, _ := datagramOf(&APDU{
+			DataType:    UnconfirmedServiceRequest,
+			ServiceType: ServiceUnconfirmedIAm,
+			Payload: &Iam{
+				ObjectID:            bacnet.ObjectID{Type: bacnet.BacnetDevice, Instance: 3},
+				MaxApduLength:       1476,
+				SegmentationSupport: bacnet.SegmentationSupportNone,
+			},
+		})
+		m.in <- datagram{data: iam, addr: &net.UDPAddr{IP: net.IPv4(10, 0, 2, 3).To4(), Port: DefaultUDPPort}}
+		//Another application of the host sends the same WhoIs
+		m.in <- datagram{data: b, addr: &net.UDPAddr{IP: net.IPv4(10, 0, 2, 2).To4(), Port: DefaultUDPPort + 1}}
+		return nil
+	}
+	c := newMemClient(t, m)
+
+	findings := c.Diagnose(context.Background(), DiagnosticsOptions{Wait: 100 * time.Millisecond, BBMD: bbmd})
+	messages := map[string][]string{}
+	for _, f := range findings {
+		is.True(f.Severity != SeverityError)
+		messages[f.Check] = append(messages[f.Check], f.Message)
+	}
+	is.Equal(messages["address"], []string{"using 10.0.2.2, broadcasting to 10.0.2.255"})
+	is.Equal(len(messages["port"]), 2)
+	is.True(strings.Contains(messages["port"][1], "another BACnet application"))
+	is.Equal(messages["broadcast"], []string{"another BACnet application runs on this host at 10.0.2.2:47809", "1 devices answered a broadcast WhoIs"})
+	is.Equal(messages["bbmd"], []string{"BBMD 10.0.9.1:47808 answered"})
+
+	//Nothing answers
+	m.respond = nil
+	interfaceAddrs = func() ([]net.Addr, error) { return nil, nil }
+	var errs []string
+	for _, f := range c.Diagnose(context.Background(), DiagnosticsOptions{Wait: 10 * time.Millisecond}) {
+		if f.Severity == SeverityError {
+			errs = append(errs, f.Check)
+		}
+	}
+	is.Equal(errs, []string{"address", "broadcast"})
+}
@@ -0,0 +1,400 @@
+package bacip
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/REQUEA/bacnet"
+)
+
+// DefaultWhoIsCoalescing is the default duration during which a WhoIs
+// doesn't broadcast again for the devices already asked for. See
+// SetWhoIsCoalescing
+const DefaultWhoIsCoalescing = time.Second
+
+// SetWhoIsCoalescing sets the duration during which the answers of a
+// WhoIs broadcast are reused by the following WhoIs requests for the
+// same devices, instead of broadcasting again. This prevents
+// applications calling WhoIs in parallel or in a loop from flooding
+// the network. A duration of 0 disables coalescing
+func (c *Client) SetWhoIsCoalescing(window time.Duration) {
+	c.whoIs.Lock()
+	defer c.whoIs.Unlock()
+	c.whoIs.window = window
+}
+
+// whoIsCoalescer remembers the recent WhoIs broadcasts and the IAm
+// received since then
+type whoIsCoalescer struct {
+	sync.Mutex
+	window    time.Duration
+	broadcast []sentWhoIs
+	iams      []receivedIam
+}
+
+type sentWhoIs struct {
+	low, high uint32
+	at        time.Time
+}
+
+type receivedIam struct {
+	iam  Iam
+	addr bacnet.Address
+	at   time.Time
+}
+
+// whoIsRange returns the range of instances of the request
+func whoIsRange(w WhoIs) (uint32, uint32) {
+	if w.Low == nil || w.High == nil {
+		return 0, bacnet.MaxInstance
+	}
+	return *w.Low, *w.High
+}
+
+// prune removes the broadcasts and answers older than the window.
+// The mutex must be held
+func (w *whoIsCoalescer) prune(now time.Time) {
+	limit := now.Add(-w.window)
+	i := 0
+	for i < len(w.broadcast) && !w.broadcast[i].at.After(limit) {
+		i++
+	}
+	w.broadcast = w.broadcast[i:]
+	i = 0
+	for i < len(w.iams) && !w.iams[i].at.After(limit) {
+		i++
+	}
+	w.iams = w.iams[i:]
+}
+
+// covering returns the time of a recent broadcast including all the
+// instances from low to high
+func (w *whoIsCoalescer) covering(low, high uint32, now time.Time) (time.Time, bool) {
+	w.Lock()
+	defer w.Unlock()
+	w.prune(now)
+	for _, b := range w.broadcast {
+		if b.low <= low && b.high >= high {
+			return b.at, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func (w *whoIsCoalescer) sent(low, high uint32, now time.Time) {
+	w.Lock()
+	defer w.Unlock()
+	if w.window == 0 {
+		return
+	}
+	w.broadcast = append(w.broadcast, sentWhoIs{low: low, high: high, at: now})
+}
+
+func (w *whoIsCoalescer) record(iam Iam, addr bacnet.Address, now time.Time) {
+	w.Lock()
+	defer w.Unlock()
+	w.prune(now)
+	if len(w.broadcast) == 0 {
+		//Nobody will ask for it
+		return
+	}
+	w.iams = append(w.iams, receivedIam{iam: iam, addr: addr, at: now})
+}
+
+// received returns the IAm received since the given time
+func (w *whoIsCoalescer) received(since time.Time) []receivedIam {
+	w.Lock()
+	defer w.Unlock()
+	var result []receivedIam
+	for _, r := range w.iams {
+		if !r.at.Before(since) {
+			result = append(result, r)
+		}
+	}
+	return result
+}
+
+// Sweep configures WhoIsSweep. The zero value sweeps all the
+// instances with the defaults
+type Sweep struct {
+	//Low and High bound the instances to look for. High 0 means
+	//bacnet.MaxInstance
+	Low, High uint32
+	//SliceSize is the number of instances of each WhoIs, 65536 if 0
+	SliceSize uint32
+	//Wait is how long the IAm of a slice are waited for, 1s if 0
+	Wait time.Duration
+	//Delay is the pause between two slices
+	Delay time.Duration
+	//Progress, if set, is called after each slice with the range of
+	//the slice and the number of devices found so far
+	Progress func(low, high uint32, found int)
+}
+
+// WhoIsSweep looks for the devices by sending a WhoIs for each slice
+// of the instance range, one after the other. On dense sites this
+// spreads the IAm answers over time instead of having all the devices
+// answer at once, and it finds the devices ignoring WhoIs without
+// range. The devices found are sorted by instance.
+func (c *Client) WhoIsSweep(ctx context.Context, sweep Sweep) ([]bacnet.Device, error) {
+	high := sweep.High
+	if high == 0 || high > bacnet.MaxInstance {
+		high = bacnet.MaxInstance
+	}
+	if sweep.Low > high {
+		return nil, fmt.Errorf("invalid sweep range: [%d, %d]", sweep.Low, high)
+	}
+	size := sweep.SliceSize
+	if size == 0 {
+		size = 65536
+	}
+	wait := sweep.Wait
+	if wait == 0 {
+		wait = time.Second
+	}
+	found := map[bacnet.ObjectID]bacnet.Device{}
+	for low := sweep.Low; ; {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		sliceHigh := high
+		if high-low >= size {
+			sliceHigh = low + size - 1
+		}
+		l, h := low, sliceHigh
+		devices, err := c.WhoIs(WhoIs{Low: &l, High: &h}, wait)
+		if err != nil {
+			return nil, fmt.Errorf("whois [%d, %d]: %w", low, sliceHigh, err)
+		}
+		for _, d := range devices {
+			found[d.ID] = d
+		}
+		if sweep.Progress != nil {
+			sweep.Progress(low, sliceHigh, len(found))
+		}
+		if sliceHigh == high {
+			break
+		}
+		low = sliceHigh + 1
+		if sweep.Delay > 0 {
+			timer := time.NewTimer(sweep.Delay)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				return nil, ctx.Err()
+			}
+		}
+	}
+	result := make([]bacnet.Device, 0, len(found))
+	for _, d := range found {
+		result = append(result, d)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ID.Instance < result[j].ID.Instance
+	})
+	return result, nil
+}
+
+// Discover broadcasts the WhoIs and returns a channel receiving the
+// devices as their IAm arrive, each device once, to show the devices
+// found progressively. The channel is closed when the context is
+// done, which is how the discovery is stopped
+func (c *Client) Discover(ctx context.Context, data WhoIs) (<-chan bacnet.Device, error) {
+	low, high := whoIsRange(data)
+	received := make(chan bacnet.Device)
+	unsubscribe := c.subscriptions.subscribe(func(bvlc BVLC, src net.UDPAddr) {
+		apdu := bvlc.NPDU.ADPU
+		if apdu == nil || apdu.DataType != UnconfirmedServiceRequest || apdu.ServiceType != ServiceUnconfirmedIAm {
+			return
+		}
+		iam, ok := apdu.Payload.(*Iam)
+		if !ok || iam.ObjectID.Instance < bacnet.ObjectInstance(low) || iam.ObjectID.Instance > bacnet.ObjectInstance(high) {
+			return
+		}
+		select {
+		case received <- deviceOf(*iam, SourceAddress(bvlc, src)):
+		case <-ctx.Done():
+		}
+	})
+	var pending []bacnet.Device
+	now := time.Now()
+	if since, ok := c.whoIs.covering(low, high, now); ok {
+		//Start with the answers of a recent broadcast
+		for _, r := range c.whoIs.received(since) {
+			if r.iam.ObjectID.Instance >= bacnet.ObjectInstance(low) &&
+				r.iam.ObjectID.Instance <= bacnet.ObjectInstance(high) {
+				pending = append(pending, deviceOf(r.iam, r.addr))
+			}
+		}
+	} else {
+		c.whoIs.sent(low, high, now)
+		_, err := c.broadcast(NPDU{
+			Version:  Version1,
+			Priority: Normal,
+			ADPU: &APDU{
+				DataType:    UnconfirmedServiceRequest,
+				ServiceType: ServiceUnconfirmedWhoIs,
+				Payload:     &data,
+			},
+		})
+		if err != nil {
+			unsubscribe()
+			return nil, err
+		}
+	}
+	devices := make(chan bacnet.Device)
+	go func() {
+		defer close(devices)
+		defer unsubscribe()
+		seen := map[bacnet.ObjectID]bool{}
+		for _, d := range pending {
+			seen[d.ID] = true
+		}
+		//The devices are queued so that a slow reader doesn't block the
+		//client
+		for {
+			var out chan bacnet.Device
+			var next bacnet.Device
+			if len(pending) > 0 {
+				out, next = devices, pending[0]
+			}
+			select {
+			case d := <-received:
+				if !seen[d.ID] {
+					seen[d.ID] = true
+					pending = append(pending, d)
+				}
+			case out <- next:
+				pending = pending[1:]
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return devices, nil
+}
+
+// ReadDeviceID reads the identifier of the device at the address,
+// without discovery, by reading the object identifier of the wildcard
+// device object. The capabilities of the returned device are unknown
+// until RefreshDevice is called
+func (c *Client) ReadDeviceID(ctx context.Context, addr bacnet.Address) (bacnet.Device, error) {
+	device := bacnet.Device{ID: bacnet.WildcardDevice, Addr: addr}
+	d, err := c.ReadProperty(ctx, device, ReadProperty{
+		ObjectID: bacnet.WildcardDevice,
+		Property: bacnet.PropertyIdentifier{Type: bacnet.ObjectIdentifier},
+	})
+	if err != nil {
+		return device, err
+	}
+	id, ok := d.(bacnet.ObjectID)
+	if !ok || id.Type != bacnet.BacnetDevice {
+		return device, fmt.Errorf("unexpected device identifier %v", d)
+	}
+	device.ID = id
+	return device, nil
+}
+
+func deviceOf(iam Iam, addr bacnet.Address) bacnet.Device {
+	return bacnet.Device{
+		ID:           iam.ObjectID,
+		MaxApdu:      iam.MaxApduLength,
+		Segmentation: iam.SegmentationSupport,
+		Vendor:       iam.VendorID,
+		Addr:         addr,
+	}
+}
+
+// DeviceDetails is a device and the properties of its device object
+// describing it
+type DeviceDetails struct {
+	bacnet.Device
+	Name                       string
+	ModelName                  string
+	VendorName                 string
+	FirmwareRevision           string
+	ApplicationSoftwareVersion string
+	//Err is the error that prevented reading the properties
+	Err error
+}
+
+// detailsProperties are the properties read by ReadDetails
+var detailsProperties = []bacnet.PropertyType{
+	bacnet.ObjectName,
+	bacnet.ModelName,
+	bacnet.VendorName,
+	bacnet.FirmwareRevision,
+	bacnet.ApplicationSoftwareVersion,
+}
+
+// ReadDetails reads the name, model, vendor and versions of the device
+// in a single ReadPropertyMultiple. The properties the device doesn't
+// have are left empty
+func (c *Client) ReadDetails(ctx context.Context, device bacnet.Device) (DeviceDetails, error) {
+	details := DeviceDetails{Device: device}
+	spec := ReadAccessSpecification{ObjectID: device.ID}
+	for _, p := range detailsProperties {
+		spec.Properties = append(spec.Properties, bacnet.PropertyIdentifier{Type: p})
+	}
+	results, err := c.ReadPropertyMultiple(ctx, device, []ReadAccessSpecification{spec})
+	if err != nil {
+		return details, err
+	}
+	fields := map[bacnet.PropertyType]*string{
+		bacnet.ObjectName:                 &details.Name,
+		bacnet.ModelName:                  &details.ModelName,
+		bacnet.VendorName:                 &details.VendorName,
+		bacnet.FirmwareRevision:           &details.FirmwareRevision,
+		bacnet.ApplicationSoftwareVersion: &details.ApplicationSoftwareVersion,
+	}
+	for _, r := range results {
+		for _, res := range r.Results {
+			if field, ok := fields[res.Property.Type]; ok && res.Error == nil {
+				*field, _ = res.Value.(string)
+			}
+		}
+	}
+	return details, nil
+}
+
+// DiscoverDetailed is like Discover, but reads the details of every
+// device found before passing it to the channel. The details of
+// several devices are read concurrently, so the devices may not be
+// received in the order they answered
+func (c *Client) DiscoverDetailed(ctx context.Context, data WhoIs) (<-chan DeviceDetails, error) {
+	devices, err := c.Discover(ctx, data)
+	if err != nil {
+		return nil, err
+	}
+	details := make(chan DeviceDetails)
+	go func() {
+		defer close(details)
+		slots := make(chan struct{}, maxCountConcurrency)
+		var wg sync.WaitGroup
+		defer wg.Wait()
+		for d := range devices {
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			wg.Add(1)
+			go func(d bacnet.Device) {
+				defer wg.Done()
+				defer func() { <-slots }()
+				dd, err := c.ReadDetails(ctx, d)
+				dd.Err = err
+				select {
+				case details <- dd:
+				case <-ctx.Done():
+				}
+			}(d)
+		}
+	}()
+	return details, nil
+}
@@ -0,0 +1,159 @@
+package bacip
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/REQUEA/bacnet"
+
+	"github.com/matryer/is"
+)
+
+func TestWhoIsCoalescer(t *testing.T) {
+	is := is.New(t)
+	w := &whoIsCoalescer{window: time.Second}
+	now := time.Now()
+	iam := Iam{ObjectID: bacnet.ObjectID{Type: bacnet.BacnetDevice, Instance: 10}}
+
+	//Answers received without broadcast aren't kept
+	w.record(iam, bacnet.Address{}, now)
+	is.Equal(len(w.iams), 0)
+
+	_, ok := w.covering(0, 100, now)
+	is.True(!ok)
+	w.sent(0, 100, now)
+	w.record(iam, bacnet.Address{}, now.Add(10*time.Millisecond))
+
+	since, ok := w.covering(5, 50, now.Add(500*time.Millisecond))
+	is.True(ok)
+	is.Equal(since, now)
+	is.Equal(len(w.received(since)), 1)
+	_, ok = w.covering(5, 200, now.Add(500*time.Millisecond))
+	is.True(!ok) //wider range
+
+	_, ok = w.covering(5, 50, now.Add(2*time.Second))
+	is.True(!ok) //expired
+	is.Equal(len(w.iams), 0)
+
+	w.window = 0
+	w.sent(0, 100, now)
+	_, ok = w.covering(0, 100, now)
+	is.True(!ok)
+}
+
+func TestWhoIsSweep(t *testing.T) {
+	is := is.New(t)
+	instances := []uint32{5, 2000000, bacnet.MaxInstance}
+	m := newMemTransport()
+	m.respond = func(b []byte, addr *net.UDPAddr) []byte {
+		var bvlc BVLC
+		if bvlc.UnmarshalBinary(b) != nil || bvlc.NPDU.ADPU == nil {
+			return nil
+		}
+		whoIs, ok := bvlc.NPDU.ADPU.Payload.(*WhoIs)
+		if !ok || whoIs.Low == nil {
+			//Devices of this site ignore WhoIs without range
+			return nil
+		}
+		for _, i := range instances {
+			if i >= *whoIs.Low && i <= *whoIs.High {
+				answer, err := BVLC{
+					Type:     TypeBacnetIP,
+					Function: BacFuncUnicast,
+					NPDU: NPDU{
+						Version: Version1,
+						ADPU: &APDU{
+							DataType:    UnconfirmedServiceRequest,
+							ServiceType: ServiceUnconfirmedIAm,
+							Payload:     &Iam{ObjectID: bacnet.ObjectID{Type: bacnet.BacnetDevice, Instance: bacnet.ObjectInstance(i)}},
+						},
+					},
+				}.MarshalBinary()
+				is.NoErr(err)
+				return answer
+			}
+		}
+		return nil
+	}
+	c := newMemClient(t, m)
+
+	slices := 0
+	devices, err := c.WhoIsSweep(context.Background(), Sweep{
+		SliceSize: 1 << 20,
+		Wait:      20 * time.Millisecond,
+		Progress:  func(low, high uint32, found int) { slices++ },
+	})
+	is.NoErr(err)
+	is.Equal(slices, 4)
+	is.Equal(len(devices), 3)
+	for i, d := range devices {
+		is.Equal(uint32(d.ID.Instance), instances[i])
+	}
+
+	devices, err = c.WhoIsSweep(context.Background(), Sweep{Low: 10, High: 3000000, SliceSize: 1 << 20, Wait: 20 * time.Millisecond})
+	is.NoErr(err)
+	is.Equal(len(devices), 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = c.WhoIsSweep(ctx, Sweep{})
+	is.Equal(err, context.Canceled)
+}
+
+func TestWhoIsAt(t *testing.T) {
+	is := is.New(t)
+	router := net.UDPAddr{IP: net.IPv4(10, 0, 3, 1).To4(), Port: DefaultUDPPort}
+	m := newMemTransport()
+	m.respond = func(b []byte, addr *net.UDPAddr) []byte {
+		var bvlc BVLC
+		if bvlc.UnmarshalBinary(b) != nil || bvlc.NPDU.ADPU == nil {
+			return nil
+		}
+		dest := bvlc.NPDU.Destination
+		if bvlc.Function != BacFuncUnicast || dest == nil || dest.Net != 2001 || len(dest.Adr) != 0 {
+			return nil
+		}
+		//The router forwards the broadcast to the network 2001, where an
+		//MS/TP device answers
+		answer, err := BVLC{
+			Type:     TypeBacnetIP,
+			Function: BacFuncUnicast,
+			NPDU: NPDU{
+				Version: Version1,
+				Source:  &bacnet.Address{Net: 2001, Adr: []byte{0x0D}},
+				ADPU: &APDU{
+					DataType:    UnconfirmedServiceRequest,
+					ServiceType: ServiceUnconfirmedIAm,
+					Payload:     &Iam{ObjectID: bacnet.ObjectID{Type: bacnet.BacnetDevice, Instance: 12}},
+				},
+			},
+		}.MarshalBinary()
+		is.NoErr(err)
+		return answer
+	}
+	c := newMemClient(t, m)
+
+	devices, err := c.WhoIsAt(bacnet.RoutedAddress(router, 2001, nil), WhoIs{}, 100*time.Millisecond)
+	is.NoErr(err)
+	is.Equal(len(devices), 1)
+	is.Equal(devices[0].ID.Instance, bacnet.ObjectInstance(12))
+	is.Equal(devices[0].Addr, bacnet.MSTPAddress(router, 2001, 0x0D))
+	m.Lock()
+	to := m.to[len(m.to)-1]
+	m.Unlock()
+	is.Equal(to.String(), router.String())
+
+	//A global broadcast is sent on the local network
+	_, err = c.WhoIsAt(bacnet.Address{Net: bacnet.GlobalNetwork}, WhoIs{}, 10*time.Millisecond)
+	is.NoErr(err)
+	m.Lock()
+	var sent BVLC
+	is.NoErr(sent.UnmarshalBinary(m.written[len(m.written)-1]))
+	to = m.to[len(m.to)-1]
+	m.Unlock()
+	is.Equal(sent.Function, BacFuncBroadcast)
+	is.Equal(sent.NPDU.Destination.Net, uint16(bacnet.GlobalNetwork))
+	is.Equal(to.IP.String(), "10.0.2.255")
+}
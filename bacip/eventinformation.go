@@ -0,0 +1,109 @@
+package bacip
+
+import (
+	"context"
+	"errors"
+
+	"github.com/REQUEA/bacnet"
+	"github.com/REQUEA/bacnet/internal/encoding"
+)
+
+// EventSummary is the event state of an object, as returned by
+// GetEventInformation
+type EventSummary struct {
+	ObjectID         bacnet.ObjectID
+	EventState       bacnet.EventStateValue
+	AckedTransitions EventTransitions
+	//EventTimeStamps are the times of the last to-offnormal, to-fault
+	//and to-normal transitions
+	EventTimeStamps [3]bacnet.TimeStamp
+	NotifyType      bacnet.NotifyTypeValue
+	EventEnable     EventTransitions
+	//EventPriorities are the priorities of the to-offnormal, to-fault
+	//and to-normal notifications
+	EventPriorities [3]uint32
+}
+
+func (s *EventSummary) decode(d *encoding.Decoder) {
+	var val uint32
+	var bits bacnet.BitString
+	d.ContextObjectID(0, &s.ObjectID)
+	d.ContextValue(1, &val)
+	s.EventState = bacnet.EventStateValue(val)
+	d.ContextBitString(2, &bits)
+	s.AckedTransitions = eventTransitionsFromBits(bits)
+	d.OpeningTag(3)
+	for i := range s.EventTimeStamps {
+		decodeTimeStampChoice(d, &s.EventTimeStamps[i])
+	}
+	d.ClosingTag(3)
+	d.ContextValue(4, &val)
+	s.NotifyType = bacnet.NotifyTypeValue(val)
+	d.ContextBitString(5, &bits)
+	s.EventEnable = eventTransitionsFromBits(bits)
+	d.OpeningTag(6)
+	for i := range s.EventPriorities {
+		d.AppData(&s.EventPriorities[i])
+	}
+	d.ClosingTag(6)
+}
+
+// GetEventInformation is a request for the event summaries of a
+// device, starting after LastReceived if set. Summaries and MoreEvents
+// are set with the answer
+type GetEventInformation struct {
+	LastReceived *bacnet.ObjectID
+	Summaries    []EventSummary
+	//MoreEvents is true if the answer didn't contain all the summaries
+	MoreEvents bool
+}
+
+func (g GetEventInformation) MarshalBinary() ([]byte, error) {
+	encoder := encoding.NewEncoder()
+	if g.LastReceived != nil {
+		encoder.ContextObjectID(0, *g.LastReceived)
+	}
+	return encoder.Bytes(), encoder.Error()
+}
+
+func (g *GetEventInformation) UnmarshalBinary(data []byte) error {
+	decoder := encoding.NewDecoder(data)
+	g.Summaries = nil
+	decoder.OpeningTag(0)
+	for !decoder.IsClosingTag(0) && decoder.Error() == nil {
+		var s EventSummary
+		s.decode(decoder)
+		g.Summaries = append(g.Summaries, s)
+	}
+	decoder.ClosingTag(0)
+	decoder.ContextBool(1, &g.MoreEvents)
+	return decoder.Error()
+}
+
+// GetEventInformation returns the summaries of the objects of the
+// device having an event state other than normal or transitions not
+// acknowledged. The answer is requested again, after the last object
+// received, until the device has no more events
+func (c *Client) GetEventInformation(ctx context.Context, device bacnet.Device) ([]EventSummary, error) {
+	var summaries []EventSummary
+	var last *bacnet.ObjectID
+	for {
+		apdu, err := c.confirmedRequest(ctx, device, ServiceConfirmedGetEventInformation, &GetEventInformation{LastReceived: last})
+		if err != nil {
+			return nil, err
+		}
+		answer, ok := apdu.Payload.(*GetEventInformation)
+		if !ok {
+			return nil, errors.New("invalid answer")
+		}
+		summaries = append(summaries, answer.Summaries...)
+		if !answer.MoreEvents {
+			return summaries, nil
+		}
+		if len(answer.Summaries) == 0 {
+			//Asking again would get the same answer
+			return nil, errors.New("more events announced without event summary")
+		}
+		last = &answer.Summaries[len(answer.Summaries)-1].ObjectID
+	}
+}
@@ -0,0 +1,103 @@
+package bacip
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/REQUEA/bacnet"
+	"github.com/REQUEA/bacnet/internal/encoding"
+	"github.com/matryer/is"
+)
+
+// encodeEventSummary writes a summary as in a GetEventInformation
+// answer
+func encodeEventSummary(e *encoding.Encoder, s EventSummary) {
+	bits := func(t EventTransitions) bacnet.BitString {
+		return bacnet.BitString{t.ToOffnormal, t.ToFault, t.ToNormal}
+	}
+	e.ContextObjectID(0, s.ObjectID)
+	e.ContextUnsigned(1, uint32(s.EventState))
+	e.ContextBitString(2, bits(s.AckedTransitions))
+	e.OpeningTag(3)
+	for _, ts := range s.EventTimeStamps {
+		encodeTimeStampChoice(e, ts)
+	}
+	e.ClosingTag(3)
+	e.ContextUnsigned(4, uint32(s.NotifyType))
+	e.ContextBitString(5, bits(s.EventEnable))
+	e.OpeningTag(6)
+	for _, p := range s.EventPriorities {
+		e.AppData(p)
+	}
+	e.ClosingTag(6)
+}
+
+func TestGetEventInformation(t *testing.T) {
+	is := is.New(t)
+	var summaries []EventSummary
+	for i := 1; i <= 5; i++ {
+		summaries = append(summaries, EventSummary{
+			ObjectID:         bacnet.ObjectID{Type: bacnet.AnalogValue, Instance: bacnet.ObjectInstance(i)},
+			EventState:       bacnet.EventStateHighLimit,
+			AckedTransitions: EventTransitions{ToFault: true, ToNormal: true},
+			EventTimeStamps: [3]bacnet.TimeStamp{
+				{Kind: bacnet.TimeStampSequence, SequenceNumber: uint32(i)},
+				{Kind: bacnet.TimeStampTime, Time: bacnet.Time{Hour: 10}},
+				{Kind: bacnet.TimeStampDateTime, DateTime: bacnet.DateTime{
+					Date: bacnet.Date{Year: 2024, Month: 3, Day: 14, Weekday: 4},
+					Time: bacnet.Time{Hour: 9, Minute: 30},
+				}},
+			},
+			NotifyType:      bacnet.NotifyTypeAlarm,
+			EventEnable:     EventTransitions{ToOffnormal: true, ToFault: true, ToNormal: true},
+			EventPriorities: [3]uint32{100, 50, 200},
+		})
+	}
+	var requests []*bacnet.ObjectID
+	m := newMemTransport()
+	m.respond = answerRequests(t, func(request *APDU) *APDU {
+		d := encoding.NewDecoder(request.Payload.(*DataPayload).Bytes)
+		var last *bacnet.ObjectID
+		start := 0
+		if d.Len() > 0 {
+			last = &bacnet.ObjectID{}
+			d.ContextObjectID(0, last)
+			is.NoErr(d.Error())
+			start = int(last.Instance)
+		}
+		requests = append(requests, last)
+		//Answer by pages of 2 summaries
+		end := start + 2
+		if end > len(summaries) {
+			end = len(summaries)
+		}
+		e := encoding.NewEncoder()
+		e.OpeningTag(0)
+		for _, s := range summaries[start:end] {
+			encodeEventSummary(&e, s)
+		}
+		e.ClosingTag(0)
+		e.ContextBool(1, end < len(summaries))
+		is.NoErr(e.Error())
+		return &APDU{
+			DataType:    ComplexAck,
+			ServiceType: request.ServiceType,
+			InvokeID:    request.InvokeID,
+			Payload:     &DataPayload{Bytes: e.Bytes()},
+		}
+	})
+	c := newMemClient(t, m)
+	device := bacnet.Device{
+		ID:   bacnet.ObjectID{Type: bacnet.BacnetDevice, Instance: 3},
+		Addr: *bacnet.AddressFromUDP(net.UDPAddr{IP: net.IPv4(10, 0, 2, 3).To4(), Port: DefaultUDPPort}),
+	}
+
+	got, err := c.GetEventInformation(context.Background(), device)
+	is.NoErr(err)
+	is.Equal(got, summaries)
+	is.Equal(len(requests), 3)
+	is.Equal(requests[0], nil)
+	is.Equal(*requests[1], summaries[1].ObjectID)
+	is.Equal(*requests[2], summaries[3].ObjectID)
+}
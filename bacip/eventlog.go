@@ -0,0 +1,119 @@
+package bacip
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/REQUEA/bacnet"
+	"github.com/REQUEA/bacnet/internal/encoding"
+)
+
+// LogStatus is the status of a log object, as recorded in its buffer
+type LogStatus struct {
+	LogDisabled    bool
+	BufferPurged   bool
+	LogInterrupted bool
+}
+
+func logStatusFromBits(bs bacnet.BitString) LogStatus {
+	return LogStatus{
+		LogDisabled:    bs.Bit(0),
+		BufferPurged:   bs.Bit(1),
+		LogInterrupted: bs.Bit(2),
+	}
+}
+
+func (s LogStatus) bits() bacnet.BitString {
+	return bacnet.BitString{s.LogDisabled, s.BufferPurged, s.LogInterrupted}
+}
+
+// EventLogRecord is an entry of the log buffer of an Event Log
+// object. Exactly one of LogStatus, Notification and TimeChange is
+// set.
+type EventLogRecord struct {
+	Timestamp    bacnet.DateTime
+	LogStatus    *LogStatus
+	Notification *EventNotification
+	//TimeChange is the clock adjustment in seconds
+	TimeChange *float32
+}
+
+func (r EventLogRecord) encode(e *encoding.Encoder) error {
+	e.OpeningTag(0)
+	encodeDateTime(e, r.Timestamp)
+	e.ClosingTag(0)
+	e.OpeningTag(1)
+	switch {
+	case r.LogStatus != nil:
+		e.ContextBitString(0, r.LogStatus.bits())
+	case r.Notification != nil:
+		e.OpeningTag(1)
+		r.Notification.encode(e)
+		e.ClosingTag(1)
+	case r.TimeChange != nil:
+		e.ContextReal(2, *r.TimeChange)
+	default:
+		return fmt.Errorf("empty event log record")
+	}
+	e.ClosingTag(1)
+	return nil
+}
+
+func (r *EventLogRecord) decode(d *encoding.Decoder) error {
+	d.OpeningTag(0)
+	decodeDateTime(d, &r.Timestamp)
+	d.ClosingTag(0)
+	d.OpeningTag(1)
+	switch {
+	case d.IsContextTag(0):
+		var bs bacnet.BitString
+		d.ContextBitString(0, &bs)
+		status := logStatusFromBits(bs)
+		r.LogStatus = &status
+	case d.IsOpeningTag(1):
+		r.Notification = &EventNotification{}
+		d.OpeningTag(1)
+		r.Notification.decode(d)
+		d.ClosingTag(1)
+	case d.IsContextTag(2):
+		r.TimeChange = new(float32)
+		d.ContextReal(2, r.TimeChange)
+	default:
+		return fmt.Errorf("unknown event log datum")
+	}
+	d.ClosingTag(1)
+	return d.Error()
+}
+
+// DecodeEventLogRecords decodes the items returned by a ReadRange
+// of the log buffer of an Event Log object
+func DecodeEventLogRecords(itemData []byte) ([]EventLogRecord, error) {
+	var records []EventLogRecord
+	err := decodeList(itemData, func(d *encoding.Decoder) error {
+		r := EventLogRecord{}
+		err := r.decode(d)
+		records = append(records, r)
+		return err
+	})
+	return records, err
+}
+
+// ReadEventLog reads the records of the log buffer of an Event Log
+// object selected by rng, or the whole buffer if rng is nil. The
+// ReadRange acknowledgment is also returned to allow the caller to
+// check if more items are available.
+func (c *Client) ReadEventLog(ctx context.Context, device bacnet.Device, eventLog bacnet.ObjectID, rng *Range) ([]EventLogRecord, ReadRange, error) {
+	ack, err := c.ReadRange(ctx, device, ReadRange{
+		ObjectID: eventLog,
+		Property: bacnet.PropertyIdentifier{Type: bacnet.LogBuffer},
+		Range:    rng,
+	})
+	if err != nil {
+		return nil, ack, err
+	}
+	records, err := DecodeEventLogRecords(ack.ItemData)
+	if err != nil {
+		return nil, ack, fmt.Errorf("decode event log records: %w", err)
+	}
+	return records, ack, nil
+}
@@ -0,0 +1,458 @@
+package bacip
+
+import (
+	"fmt"
+
+	"github.com/REQUEA/bacnet"
+	"github.com/REQUEA/bacnet/internal/encoding"
+)
+
+// PropertyState is a value of the property states choice, such as the
+// new state of a change of state event. Kind is the number of the
+// choice: 0 for a boolean, 1 for a binary value, 2 for an event type,
+// ... Value is a bool for the kind 0, an uint32 otherwise
+type PropertyState struct {
+	Kind  uint8
+	Value interface{}
+}
+
+func (s *PropertyState) decode(d *encoding.Decoder) {
+	kind, ok := d.ContextTagNumber()
+	if !ok {
+		//Let the decoder report the missing tag
+		var v uint32
+		d.ContextValue(0, &v)
+		return
+	}
+	s.Kind = kind
+	if kind == 0 {
+		var v bool
+		d.ContextBool(0, &v)
+		s.Value = v
+		return
+	}
+	var v uint32
+	d.ContextValue(kind, &v)
+	s.Value = v
+}
+
+func decodeStatusFlags(d *encoding.Decoder, tagNumber byte) StatusFlags {
+	var bits bacnet.BitString
+	d.ContextBitString(tagNumber, &bits)
+	return StatusFlagsFromBits(bits)
+}
+
+// decodeAbstract decodes a value enclosed in a context tag, like
+// ReadProperty.Data
+func decodeAbstract(d *encoding.Decoder, tagNumber byte) interface{} {
+	var raw []byte
+	d.ContextRaw(tagNumber, &raw)
+	return decodeValue(raw)
+}
+
+// ChangeOfBitstringParameters are the parameters of a change of
+// bitstring notification
+type ChangeOfBitstringParameters struct {
+	ReferencedBitstring bacnet.BitString
+	StatusFlags         StatusFlags
+}
+
+func (p *ChangeOfBitstringParameters) decode(d *encoding.Decoder) {
+	d.ContextBitString(0, &p.ReferencedBitstring)
+	p.StatusFlags = decodeStatusFlags(d, 1)
+}
+
+// ChangeOfStateParameters are the parameters of a change of state
+// notification
+type ChangeOfStateParameters struct {
+	NewState    PropertyState
+	StatusFlags StatusFlags
+}
+
+func (p *ChangeOfStateParameters) decode(d *encoding.Decoder) {
+	d.OpeningTag(0)
+	p.NewState.decode(d)
+	d.ClosingTag(0)
+	p.StatusFlags = decodeStatusFlags(d, 1)
+}
+
+// ChangeOfValueParameters are the parameters of a change of value
+// notification. Either ChangedBits or ChangedValue is set
+type ChangeOfValueParameters struct {
+	ChangedBits  bacnet.BitString
+	ChangedValue *float32
+	StatusFlags  StatusFlags
+}
+
+func (p *ChangeOfValueParameters) decode(d *encoding.Decoder) {
+	d.OpeningTag(0)
+	if d.IsContextTag(0) {
+		d.ContextBitString(0, &p.ChangedBits)
+	} else {
+		p.ChangedValue = new(float32)
+		d.ContextReal(1, p.ChangedValue)
+	}
+	d.ClosingTag(0)
+	p.StatusFlags = decodeStatusFlags(d, 1)
+}
+
+// CommandFailureParameters are the parameters of a command failure
+// notification
+type CommandFailureParameters struct {
+	CommandValue  interface{}
+	StatusFlags   StatusFlags
+	FeedbackValue interface{}
+}
+
+func (p *CommandFailureParameters) decode(d *encoding.Decoder) {
+	p.CommandValue = decodeAbstract(d, 0)
+	p.StatusFlags = decodeStatusFlags(d, 1)
+	p.FeedbackValue = decodeAbstract(d, 2)
+}
+
+// FloatingLimitParameters are the parameters of a floating limit
+// notification
+type FloatingLimitParameters struct {
+	ReferenceValue float32
+	StatusFlags    StatusFlags
+	SetpointValue  float32
+	ErrorLimit     float32
+}
+
+func (p *FloatingLimitParameters) decode(d *encoding.Decoder) {
+	d.ContextReal(0, &p.ReferenceValue)
+	p.StatusFlags = decodeStatusFlags(d, 1)
+	d.ContextReal(2, &p.SetpointValue)
+	d.ContextReal(3, &p.ErrorLimit)
+}
+
+// OutOfRangeParameters are the parameters of an out of range
+// notification
+type OutOfRangeParameters struct {
+	ExceedingValue float32
+	StatusFlags    StatusFlags
+	Deadband       float32
+	ExceededLimit  float32
+}
+
+func (p *OutOfRangeParameters) decode(d *encoding.Decoder) {
+	d.ContextReal(0, &p.ExceedingValue)
+	p.StatusFlags = decodeStatusFlags(d, 1)
+	d.ContextReal(2, &p.Deadband)
+	d.ContextReal(3, &p.ExceededLimit)
+}
+
+// ComplexEventParameters are the property values of a complex event
+// notification
+type ComplexEventParameters struct {
+	Values []COVValue
+}
+
+func (p *ComplexEventParameters) decode(d *encoding.Decoder) {
+	p.Values = decodePropertyValues(d, byte(bacnet.EventTypeComplexEventType))
+}
+
+// ChangeOfLifeSafetyParameters are the parameters of a change of life
+// safety notification
+type ChangeOfLifeSafetyParameters struct {
+	NewState          bacnet.LifeSafetyState
+	NewMode           bacnet.LifeSafetyMode
+	StatusFlags       StatusFlags
+	OperationExpected bacnet.LifeSafetyOperation
+}
+
+func (p *ChangeOfLifeSafetyParameters) decode(d *encoding.Decoder) {
+	var val uint32
+	d.ContextValue(0, &val)
+	p.NewState = bacnet.LifeSafetyState(val)
+	d.ContextValue(1, &val)
+	p.NewMode = bacnet.LifeSafetyMode(val)
+	p.StatusFlags = decodeStatusFlags(d, 2)
+	d.ContextValue(3, &val)
+	p.OperationExpected = bacnet.LifeSafetyOperation(val)
+}
+
+// ExtendedParameters are the parameters of a proprietary event
+// notification. Parameters contains the encoded parameters, whose
+// structure is defined by the vendor
+type ExtendedParameters struct {
+	VendorID          uint32
+	ExtendedEventType uint32
+	Parameters        RawValue
+}
+
+func (p *ExtendedParameters) decode(d *encoding.Decoder) {
+	d.ContextValue(0, &p.VendorID)
+	d.ContextValue(1, &p.ExtendedEventType)
+	var raw []byte
+	d.ContextRaw(2, &raw)
+	p.Parameters = raw
+}
+
+// BufferReadyParameters are the parameters of a buffer ready
+// notification, sent by the logs whose buffer must be read
+type BufferReadyParameters struct {
+	BufferProperty       DeviceObjectPropertyReference
+	PreviousNotification uint32
+	CurrentNotification  uint32
+}
+
+func (p *BufferReadyParameters) decode(d *encoding.Decoder) {
+	d.OpeningTag(0)
+	p.BufferProperty.decode(d)
+	d.ClosingTag(0)
+	d.ContextValue(1, &p.PreviousNotification)
+	d.ContextValue(2, &p.CurrentNotification)
+}
+
+// UnsignedRangeParameters are the parameters of an unsigned range
+// notification
+type UnsignedRangeParameters struct {
+	ExceedingValue uint32
+	StatusFlags    StatusFlags
+	ExceededLimit  uint32
+}
+
+func (p *UnsignedRangeParameters) decode(d *encoding.Decoder) {
+	d.ContextValue(0, &p.ExceedingValue)
+	p.StatusFlags = decodeStatusFlags(d, 1)
+	d.ContextValue(2, &p.ExceededLimit)
+}
+
+// AccessEventParameters are the parameters of an access event
+// notification. AuthenticationFactor is the encoded authentication
+// factor, if any
+type AccessEventParameters struct {
+	AccessEvent          bacnet.AccessEventValue
+	StatusFlags          StatusFlags
+	AccessEventTag       uint32
+	AccessEventTime      bacnet.TimeStamp
+	AccessCredential     DeviceObjectReference
+	AuthenticationFactor RawValue
+}
+
+func (p *AccessEventParameters) decode(d *encoding.Decoder) {
+	var val uint32
+	d.ContextValue(0, &val)
+	p.AccessEvent = bacnet.AccessEventValue(val)
+	p.StatusFlags = decodeStatusFlags(d, 1)
+	d.ContextValue(2, &p.AccessEventTag)
+	decodeTimeStamp(d, 3, &p.AccessEventTime)
+	d.OpeningTag(4)
+	p.AccessCredential.decode(d)
+	d.ClosingTag(4)
+	p.AuthenticationFactor = nil
+	if d.IsOpeningTag(5) {
+		var raw []byte
+		d.ContextRaw(5, &raw)
+		p.AuthenticationFactor = raw
+	}
+}
+
+// DoubleOutOfRangeParameters are the parameters of a double out of
+// range notification
+type DoubleOutOfRangeParameters struct {
+	ExceedingValue float64
+	StatusFlags    StatusFlags
+	Deadband       float64
+	ExceededLimit  float64
+}
+
+func (p *DoubleOutOfRangeParameters) decode(d *encoding.Decoder) {
+	d.ContextDouble(0, &p.ExceedingValue)
+	p.StatusFlags = decodeStatusFlags(d, 1)
+	d.ContextDouble(2, &p.Deadband)
+	d.ContextDouble(3, &p.ExceededLimit)
+}
+
+// SignedOutOfRangeParameters are the parameters of a signed out of
+// range notification
+type SignedOutOfRangeParameters struct {
+	ExceedingValue int32
+	StatusFlags    StatusFlags
+	Deadband       uint32
+	ExceededLimit  int32
+}
+
+func (p *SignedOutOfRangeParameters) decode(d *encoding.Decoder) {
+	d.ContextSigned(0, &p.ExceedingValue)
+	p.StatusFlags = decodeStatusFlags(d, 1)
+	d.ContextValue(2, &p.Deadband)
+	d.ContextSigned(3, &p.ExceededLimit)
+}
+
+// UnsignedOutOfRangeParameters are the parameters of an unsigned out
+// of range notification
+type UnsignedOutOfRangeParameters struct {
+	ExceedingValue uint32
+	StatusFlags    StatusFlags
+	Deadband       uint32
+	ExceededLimit  uint32
+}
+
+func (p *UnsignedOutOfRangeParameters) decode(d *encoding.Decoder) {
+	d.ContextValue(0, &p.ExceedingValue)
+	p.StatusFlags = decodeStatusFlags(d, 1)
+	d.ContextValue(2, &p.Deadband)
+	d.ContextValue(3, &p.ExceededLimit)
+}
+
+// ChangeOfCharacterStringParameters are the parameters of a change of
+// character string notification
+type ChangeOfCharacterStringParameters struct {
+	ChangedValue string
+	StatusFlags  StatusFlags
+	AlarmValue   string
+}
+
+func (p *ChangeOfCharacterStringParameters) decode(d *encoding.Decoder) {
+	d.ContextString(0, &p.ChangedValue)
+	p.StatusFlags = decodeStatusFlags(d, 1)
+	d.ContextString(2, &p.AlarmValue)
+}
+
+// ChangeOfStatusFlagsParameters are the parameters of a change of
+// status flags notification. PresentValue is nil if absent
+type ChangeOfStatusFlagsParameters struct {
+	PresentValue    interface{}
+	ReferencedFlags StatusFlags
+}
+
+func (p *ChangeOfStatusFlagsParameters) decode(d *encoding.Decoder) {
+	p.PresentValue = nil
+	if d.IsOpeningTag(0) {
+		p.PresentValue = decodeAbstract(d, 0)
+	}
+	p.ReferencedFlags = decodeStatusFlags(d, 1)
+}
+
+// ChangeOfReliabilityParameters are the parameters of a change of
+// reliability notification
+type ChangeOfReliabilityParameters struct {
+	Reliability    Reliability
+	StatusFlags    StatusFlags
+	PropertyValues []COVValue
+}
+
+func (p *ChangeOfReliabilityParameters) decode(d *encoding.Decoder) {
+	var val uint32
+	d.ContextValue(0, &val)
+	p.Reliability = Reliability(val)
+	p.StatusFlags = decodeStatusFlags(d, 1)
+	d.OpeningTag(2)
+	p.PropertyValues = decodePropertyValues(d, 2)
+	d.ClosingTag(2)
+}
+
+// ChangeOfDiscreteValueParameters are the parameters of a change of
+// discrete value notification
+type ChangeOfDiscreteValueParameters struct {
+	NewValue    interface{}
+	StatusFlags StatusFlags
+}
+
+func (p *ChangeOfDiscreteValueParameters) decode(d *encoding.Decoder) {
+	p.NewValue = decodeAbstract(d, 0)
+	p.StatusFlags = decodeStatusFlags(d, 1)
+}
+
+// ChangeOfTimerParameters are the parameters of a change of timer
+// notification. The optional fields are nil if absent
+type ChangeOfTimerParameters struct {
+	NewState        uint32
+	StatusFlags     StatusFlags
+	UpdateTime      bacnet.DateTime
+	LastStateChange *uint32
+	InitialTimeout  *uint32
+	ExpirationTime  *bacnet.DateTime
+}
+
+func (p *ChangeOfTimerParameters) decode(d *encoding.Decoder) {
+	d.ContextValue(0, &p.NewState)
+	p.StatusFlags = decodeStatusFlags(d, 1)
+	d.OpeningTag(2)
+	decodeDateTime(d, &p.UpdateTime)
+	d.ClosingTag(2)
+	p.LastStateChange, p.InitialTimeout, p.ExpirationTime = nil, nil, nil
+	if d.IsContextTag(3) {
+		p.LastStateChange = new(uint32)
+		d.ContextValue(3, p.LastStateChange)
+	}
+	if d.IsContextTag(4) {
+		p.InitialTimeout = new(uint32)
+		d.ContextValue(4, p.InitialTimeout)
+	}
+	if d.IsOpeningTag(5) {
+		p.ExpirationTime = new(bacnet.DateTime)
+		d.OpeningTag(5)
+		decodeDateTime(d, p.ExpirationTime)
+		d.ClosingTag(5)
+	}
+}
+
+// Parameters decodes the notification parameters of the notification
+// according to its event type. It returns a pointer to one of the
+// ...Parameters types, such as *OutOfRangeParameters for an out of
+// range event, or nil if the notification has no parameters, as the
+// acknowledgment notifications
+func (n EventNotification) Parameters() (interface{}, error) {
+	if len(n.EventValues) == 0 {
+		return nil, nil
+	}
+	var p interface {
+		decode(d *encoding.Decoder)
+	}
+	switch n.EventType {
+	case bacnet.EventTypeChangeOfBitstring:
+		p = &ChangeOfBitstringParameters{}
+	case bacnet.EventTypeChangeOfState:
+		p = &ChangeOfStateParameters{}
+	case bacnet.EventTypeChangeOfValue:
+		p = &ChangeOfValueParameters{}
+	case bacnet.EventTypeCommandFailure:
+		p = &CommandFailureParameters{}
+	case bacnet.EventTypeFloatingLimit:
+		p = &FloatingLimitParameters{}
+	case bacnet.EventTypeOutOfRange:
+		p = &OutOfRangeParameters{}
+	case bacnet.EventTypeComplexEventType:
+		p = &ComplexEventParameters{}
+	case bacnet.EventTypeChangeOfLifeSafety:
+		p = &ChangeOfLifeSafetyParameters{}
+	case bacnet.EventTypeExtended:
+		p = &ExtendedParameters{}
+	case bacnet.EventTypeBufferReady:
+		p = &BufferReadyParameters{}
+	case bacnet.EventTypeUnsignedRange:
+		p = &UnsignedRangeParameters{}
+	case bacnet.EventTypeAccessEvent:
+		p = &AccessEventParameters{}
+	case bacnet.EventTypeDoubleOutOfRange:
+		p = &DoubleOutOfRangeParameters{}
+	case bacnet.EventTypeSignedOutOfRange:
+		p = &SignedOutOfRangeParameters{}
+	case bacnet.EventTypeUnsignedOutOfRange:
+		p = &UnsignedOutOfRangeParameters{}
+	case bacnet.EventTypeChangeOfCharacterstring:
+		p = &ChangeOfCharacterStringParameters{}
+	case bacnet.EventTypeChangeOfStatusFlags:
+		p = &ChangeOfStatusFlagsParameters{}
+	case bacnet.EventTypeChangeOfReliability:
+		p = &ChangeOfReliabilityParameters{}
+	case bacnet.EventTypeChangeOfDiscreteValue:
+		p = &ChangeOfDiscreteValueParameters{}
+	case bacnet.EventTypeChangeOfTimer:
+		p = &ChangeOfTimerParameters{}
+	default:
+		return nil, fmt.Errorf("no notification parameters for event type %v", n.EventType)
+	}
+	d := encoding.NewDecoder(n.EventValues)
+	tag := byte(n.EventType)
+	d.OpeningTag(tag)
+	p.decode(d)
+	d.ClosingTag(tag)
+	if err := d.Error(); err != nil {
+		return nil, fmt.Errorf("decode %v parameters: %w", n.EventType, err)
+	}
+	return p, nil
+}
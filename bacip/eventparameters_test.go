@@ -0,0 +1,170 @@
+package bacip
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/REQUEA/bacnet"
+	"github.com/REQUEA/bacnet/internal/encoding"
+	"github.com/matryer/is"
+)
+
+// eventValues encodes notification parameters of the event type, as
+// in EventNotification.EventValues
+func eventValues(t *testing.T, eventType bacnet.EventTypeValue, encode func(e *encoding.Encoder)) []byte {
+	e := encoding.NewEncoder()
+	e.OpeningTag(byte(eventType))
+	encode(&e)
+	e.ClosingTag(byte(eventType))
+	if err := e.Error(); err != nil {
+		t.Fatal(err)
+	}
+	return e.Bytes()
+}
+
+func TestEventNotificationParameters(t *testing.T) {
+	is := is.New(t)
+	inAlarm := bacnet.BitString{true, false, false, false}
+	one := uint32(1)
+	changed := float32(2.5)
+	updated := bacnet.DateTime{
+		Date: bacnet.Date{Year: 2024, Month: 3, Day: 14, Weekday: 4},
+		Time: bacnet.Time{Hour: 9, Minute: 30},
+	}
+	for _, tc := range []struct {
+		eventType bacnet.EventTypeValue
+		encode    func(e *encoding.Encoder)
+		expected  interface{}
+	}{
+		{bacnet.EventTypeOutOfRange, func(e *encoding.Encoder) {
+			e.ContextReal(0, 80.5)
+			e.ContextBitString(1, inAlarm)
+			e.ContextReal(2, 1)
+			e.ContextReal(3, 75)
+		}, &OutOfRangeParameters{ExceedingValue: 80.5, StatusFlags: StatusFlags{InAlarm: true}, Deadband: 1, ExceededLimit: 75}},
+		{bacnet.EventTypeChangeOfState, func(e *encoding.Encoder) {
+			e.OpeningTag(0)
+			e.ContextUnsigned(1, 1)
+			e.ClosingTag(0)
+			e.ContextBitString(1, inAlarm)
+		}, &ChangeOfStateParameters{NewState: PropertyState{Kind: 1, Value: uint32(1)}, StatusFlags: StatusFlags{InAlarm: true}}},
+		{bacnet.EventTypeChangeOfValue, func(e *encoding.Encoder) {
+			e.OpeningTag(0)
+			e.ContextReal(1, 2.5)
+			e.ClosingTag(0)
+			e.ContextBitString(1, inAlarm)
+		}, &ChangeOfValueParameters{ChangedValue: &changed, StatusFlags: StatusFlags{InAlarm: true}}},
+		{bacnet.EventTypeDoubleOutOfRange, func(e *encoding.Encoder) {
+			e.ContextDouble(0, 1e10)
+			e.ContextBitString(1, inAlarm)
+			e.ContextDouble(2, 0.5)
+			e.ContextDouble(3, 1e9)
+		}, &DoubleOutOfRangeParameters{ExceedingValue: 1e10, StatusFlags: StatusFlags{InAlarm: true}, Deadband: 0.5, ExceededLimit: 1e9}},
+		{bacnet.EventTypeBufferReady, func(e *encoding.Encoder) {
+			e.OpeningTag(0)
+			DeviceObjectPropertyReference{
+				ObjectID: bacnet.ObjectID{Type: bacnet.Trendlog, Instance: 1},
+				Property: bacnet.PropertyIdentifier{Type: bacnet.LogBuffer},
+			}.encode(e)
+			e.ClosingTag(0)
+			e.ContextUnsigned(1, 100)
+			e.ContextUnsigned(2, 200)
+		}, &BufferReadyParameters{
+			BufferProperty: DeviceObjectPropertyReference{
+				ObjectID: bacnet.ObjectID{Type: bacnet.Trendlog, Instance: 1},
+				Property: bacnet.PropertyIdentifier{Type: bacnet.LogBuffer},
+			},
+			PreviousNotification: 100,
+			CurrentNotification:  200,
+		}},
+		{bacnet.EventTypeChangeOfReliability, func(e *encoding.Encoder) {
+			e.ContextUnsigned(0, uint32(ReliabilityNoSensor))
+			e.ContextBitString(1, bacnet.BitString{false, true, false, false})
+			e.OpeningTag(2)
+			e.ContextUnsigned(0, uint32(bacnet.PresentValue))
+			e.OpeningTag(2)
+			e.AppData(float32(0))
+			e.ClosingTag(2)
+			e.ClosingTag(2)
+		}, &ChangeOfReliabilityParameters{
+			Reliability:    ReliabilityNoSensor,
+			StatusFlags:    StatusFlags{Fault: true},
+			PropertyValues: []COVValue{{Property: bacnet.PropertyIdentifier{Type: bacnet.PresentValue}, Value: float32(0)}},
+		}},
+		{bacnet.EventTypeChangeOfTimer, func(e *encoding.Encoder) {
+			e.ContextUnsigned(0, 1)
+			e.ContextBitString(1, inAlarm)
+			e.OpeningTag(2)
+			encodeDateTime(e, updated)
+			e.ClosingTag(2)
+			e.ContextUnsigned(4, 1)
+		}, &ChangeOfTimerParameters{NewState: 1, StatusFlags: StatusFlags{InAlarm: true}, UpdateTime: updated, InitialTimeout: &one}},
+	} {
+		n := EventNotification{EventType: tc.eventType, EventValues: eventValues(t, tc.eventType, tc.encode)}
+		p, err := n.Parameters()
+		is.NoErr(err)
+		is.Equal(p, tc.expected)
+	}
+
+	p, err := EventNotification{EventType: bacnet.EventTypeOutOfRange}.Parameters()
+	is.NoErr(err)
+	is.Equal(p, nil)
+	_, err = EventNotification{
+		EventType:   bacnet.EventTypeOutOfRange,
+		EventValues: eventValues(t, bacnet.EventTypeOutOfRange, func(e *encoding.Encoder) { e.ContextReal(0, 1) }),
+	}.Parameters()
+	is.True(err != nil)
+}
+
+func TestEventNotificationHook(t *testing.T) {
+	is := is.New(t)
+	deviceAddr := net.UDPAddr{IP: net.IPv4(10, 0, 2, 3).To4(), Port: DefaultUDPPort}
+	m := newMemTransport()
+	c := newMemClient(t, m)
+
+	received := make(chan EventNotification, 1)
+	c.OnEventNotification(func(n EventNotification, _ bacnet.Address) { received <- n })
+	n := EventNotification{
+		ProcessID:         1,
+		InitiatingDevice:  bacnet.ObjectID{Type: bacnet.BacnetDevice, Instance: 3},
+		EventObject:       bacnet.ObjectID{Type: bacnet.AnalogInput, Instance: 1},
+		TimeStamp:         bacnet.TimeStamp{Kind: bacnet.TimeStampSequence, SequenceNumber: 7},
+		NotificationClass: 5,
+		Priority:          100,
+		EventType:         bacnet.EventTypeChangeOfStatusFlags,
+		NotifyType:        bacnet.NotifyTypeAlarm,
+		AckRequired:       true,
+		FromState:         bacnet.EventStateNormal,
+		ToState:           bacnet.EventStateOffnormal,
+		EventValues: eventValues(t, bacnet.EventTypeChangeOfStatusFlags, func(e *encoding.Encoder) {
+			e.ContextBitString(1, bacnet.BitString{true, false, false, true})
+		}),
+	}
+	b, err := datagramOf(&APDU{
+		DataType:    ConfirmedServiceRequest,
+		ServiceType: ServiceConfirmedEventNotification,
+		InvokeID:    42,
+		Payload:     &n,
+	})
+	is.NoErr(err)
+	m.in <- datagram{data: b, addr: &deviceAddr}
+	select {
+	case got := <-received:
+		is.Equal(got, n)
+		p, err := got.Parameters()
+		is.NoErr(err)
+		is.Equal(p, &ChangeOfStatusFlagsParameters{ReferencedFlags: StatusFlags{InAlarm: true, OutOfService: true}})
+	case <-time.After(time.Second):
+		t.Fatal("event notification not received")
+	}
+	//The confirmed notification is acknowledged
+	is.True(waitFor(func() bool { return m.count() > 0 }))
+	m.Lock()
+	var ack BVLC
+	is.NoErr(ack.UnmarshalBinary(m.written[len(m.written)-1]))
+	m.Unlock()
+	is.Equal(ack.NPDU.ADPU.DataType, SimpleAck)
+	is.Equal(ack.NPDU.ADPU.ServiceType, ServiceConfirmedEventNotification)
+	is.Equal(ack.NPDU.ADPU.InvokeID, byte(42))
+}
@@ -0,0 +1,123 @@
+package bacip
+
+import (
+	"net"
+
+	"github.com/REQUEA/bacnet"
+	"github.com/REQUEA/bacnet/internal/encoding"
+)
+
+// EventNotification is the content of an event notification, sent by
+// a device when one of its objects changes event state.
+type EventNotification struct {
+	ProcessID         uint32
+	InitiatingDevice  bacnet.ObjectID
+	EventObject       bacnet.ObjectID
+	TimeStamp         bacnet.TimeStamp
+	NotificationClass uint32
+	Priority          uint8
+	EventType         bacnet.EventTypeValue
+	MessageText       string //Optional
+	NotifyType        bacnet.NotifyTypeValue
+	//AckRequired and FromState are absent of ack notifications
+	AckRequired bool
+	FromState   bacnet.EventStateValue
+	ToState     bacnet.EventStateValue
+	//EventValues contains the encoded notification parameters, if
+	//any. Their structure depends on the EventType
+	EventValues []byte
+}
+
+func (n EventNotification) MarshalBinary() ([]byte, error) {
+	encoder := encoding.NewEncoder()
+	n.encode(&encoder)
+	return encoder.Bytes(), encoder.Error()
+}
+
+func (n *EventNotification) UnmarshalBinary(data []byte) error {
+	decoder := encoding.NewDecoder(data)
+	n.decode(decoder)
+	return decoder.Error()
+}
+
+func (n EventNotification) encode(e *encoding.Encoder) {
+	e.ContextUnsigned(0, n.ProcessID)
+	e.ContextObjectID(1, n.InitiatingDevice)
+	e.ContextObjectID(2, n.EventObject)
+	encodeTimeStamp(e, 3, n.TimeStamp)
+	e.ContextUnsigned(4, n.NotificationClass)
+	e.ContextUnsigned(5, uint32(n.Priority))
+	e.ContextUnsigned(6, uint32(n.EventType))
+	if n.MessageText != "" {
+		e.ContextString(7, n.MessageText)
+	}
+	e.ContextUnsigned(8, uint32(n.NotifyType))
+	if n.NotifyType != bacnet.NotifyTypeAckNotification {
+		e.ContextBool(9, n.AckRequired)
+		e.ContextUnsigned(10, uint32(n.FromState))
+	}
+	e.ContextUnsigned(11, uint32(n.ToState))
+	if n.EventValues != nil {
+		e.ContextRaw(12, n.EventValues)
+	}
+}
+
+func (n *EventNotification) decode(d *encoding.Decoder) {
+	var val uint32
+	d.ContextValue(0, &n.ProcessID)
+	d.ContextObjectID(1, &n.InitiatingDevice)
+	d.ContextObjectID(2, &n.EventObject)
+	decodeTimeStamp(d, 3, &n.TimeStamp)
+	d.ContextValue(4, &n.NotificationClass)
+	d.ContextValue(5, &val)
+	n.Priority = uint8(val)
+	d.ContextValue(6, &val)
+	n.EventType = bacnet.EventTypeValue(val)
+	n.MessageText = ""
+	if d.IsContextTag(7) {
+		d.ContextString(7, &n.MessageText)
+	}
+	d.ContextValue(8, &val)
+	n.NotifyType = bacnet.NotifyTypeValue(val)
+	if d.IsContextTag(9) {
+		d.ContextBool(9, &n.AckRequired)
+	}
+	if d.IsContextTag(10) {
+		d.ContextValue(10, &val)
+		n.FromState = bacnet.EventStateValue(val)
+	}
+	d.ContextValue(11, &val)
+	n.ToState = bacnet.EventStateValue(val)
+	n.EventValues = nil
+	if d.IsOpeningTag(12) {
+		d.ContextRaw(12, &n.EventValues)
+	}
+}
+
+// OnEventNotification sets the function called with the event
+// notifications received, and the address of their sender, so that
+// the client can be a recipient of the notification classes. The
+// confirmed notifications are acknowledged once it returns. It must
+// not block
+func (c *Client) OnEventNotification(f func(EventNotification, bacnet.Address)) {
+	c.eventHook.Store(f)
+}
+
+// handleEventNotification passes an event notification to the hook,
+// and acknowledges the confirmed notifications
+func (c *Client) handleEventNotification(bvlc BVLC, src *net.UDPAddr) {
+	apdu := bvlc.NPDU.ADPU
+	n, ok := apdu.Payload.(*EventNotification)
+	if !ok {
+		return
+	}
+	if f, _ := c.eventHook.Load().(func(EventNotification, bacnet.Address)); f != nil {
+		f(*n, SourceAddress(bvlc, *src))
+	}
+	if apdu.DataType != ConfirmedServiceRequest {
+		return
+	}
+	if err := c.acknowledge(bvlc, src); err != nil {
+		c.logger.Error("acknowledge event notification: ", err)
+	}
+}
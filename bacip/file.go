@@ -0,0 +1,247 @@
+package bacip
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/REQUEA/bacnet"
+	"github.com/REQUEA/bacnet/internal/encoding"
+)
+
+// AtomicReadFile reads a part of a File object, as a stream of octets
+// or as records depending on the access method of the file
+type AtomicReadFile struct {
+	ObjectID bacnet.ObjectID
+	//Record selects the record access, the stream access is used
+	//otherwise
+	Record bool
+	//Start is the position of the first octet or record to read
+	Start int32
+	//Count is the number of octets or records to read
+	Count uint32
+
+	//The following fields contains the response
+	EndOfFile bool
+	//Data contains the octets read with the stream access
+	Data []byte
+	//Records contains the records read with the record access
+	Records [][]byte
+}
+
+func (rf AtomicReadFile) MarshalBinary() ([]byte, error) {
+	encoder := encoding.NewEncoder()
+	encoder.AppData(rf.ObjectID)
+	tag := byte(0)
+	if rf.Record {
+		tag = 1
+	}
+	encoder.OpeningTag(tag)
+	encoder.AppData(rf.Start)
+	encoder.AppData(rf.Count)
+	encoder.ClosingTag(tag)
+	return encoder.Bytes(), encoder.Error()
+}
+
+// UnmarshalBinary decodes an AtomicReadFile acknowledgment
+func (rf *AtomicReadFile) UnmarshalBinary(data []byte) error {
+	decoder := encoding.NewDecoder(data)
+	decoder.AppData(&rf.EndOfFile)
+	rf.Data, rf.Records = nil, nil
+	if decoder.IsOpeningTag(0) {
+		rf.Record = false
+		decoder.OpeningTag(0)
+		decoder.AppData(&rf.Start)
+		decoder.AppData(&rf.Data)
+		decoder.ClosingTag(0)
+		rf.Count = uint32(len(rf.Data))
+		return decoder.Error()
+	}
+	rf.Record = true
+	decoder.OpeningTag(1)
+	decoder.AppData(&rf.Start)
+	decoder.AppData(&rf.Count)
+	for i := uint32(0); i < rf.Count && decoder.Error() == nil; i++ {
+		var record []byte
+		decoder.AppData(&record)
+		rf.Records = append(rf.Records, record)
+	}
+	decoder.ClosingTag(1)
+	return decoder.Error()
+}
+
+// AtomicWriteFile writes a part of a File object, as a stream of
+// octets or as records depending on the access method of the file
+type AtomicWriteFile struct {
+	ObjectID bacnet.ObjectID
+	//Record selects the record access, the stream access is used
+	//otherwise
+	Record bool
+	//Start is the position of the first octet or record to write, -1
+	//to append to the file. The acknowledgment sets it to the
+	//position where the data was actually written
+	Start int32
+	//Data contains the octets written with the stream access
+	Data []byte
+	//Records contains the records written with the record access
+	Records [][]byte
+}
+
+func (wf AtomicWriteFile) MarshalBinary() ([]byte, error) {
+	encoder := encoding.NewEncoder()
+	encoder.AppData(wf.ObjectID)
+	if !wf.Record {
+		encoder.OpeningTag(0)
+		encoder.AppData(wf.Start)
+		encoder.AppData(wf.Data)
+		encoder.ClosingTag(0)
+		return encoder.Bytes(), encoder.Error()
+	}
+	encoder.OpeningTag(1)
+	encoder.AppData(wf.Start)
+	encoder.AppData(uint32(len(wf.Records)))
+	for _, record := range wf.Records {
+		encoder.AppData(record)
+	}
+	encoder.ClosingTag(1)
+	return encoder.Bytes(), encoder.Error()
+}
+
+// UnmarshalBinary decodes an AtomicWriteFile acknowledgment
+func (wf *AtomicWriteFile) UnmarshalBinary(data []byte) error {
+	decoder := encoding.NewDecoder(data)
+	wf.Record = decoder.IsContextTag(1)
+	if wf.Record {
+		decoder.ContextSigned(1, &wf.Start)
+	} else {
+		decoder.ContextSigned(0, &wf.Start)
+	}
+	return decoder.Error()
+}
+
+// AtomicReadFile reads a part of a File object
+func (c *Client) AtomicReadFile(ctx context.Context, device bacnet.Device, readFile AtomicReadFile) (AtomicReadFile, error) {
+	apdu, err := c.confirmedRequest(ctx, device, ServiceConfirmedAtomicReadFile, &readFile)
+	if err != nil {
+		return AtomicReadFile{}, err
+	}
+	if apdu.DataType == ComplexAck && apdu.ServiceType == ServiceConfirmedAtomicReadFile {
+		ack := *apdu.Payload.(*AtomicReadFile)
+		ack.ObjectID = readFile.ObjectID
+		return ack, nil
+	}
+	return AtomicReadFile{}, errors.New("invalid answer")
+}
+
+// AtomicWriteFile writes a part of a File object and returns the
+// position of the first octet or record written
+func (c *Client) AtomicWriteFile(ctx context.Context, device bacnet.Device, writeFile AtomicWriteFile) (int32, error) {
+	err := c.checkRequest(device.ID, &writeFile)
+	if err != nil {
+		return 0, err
+	}
+	apdu, err := c.confirmedRequest(ctx, device, ServiceConfirmedAtomicWriteFile, &writeFile)
+	if err != nil {
+		return 0, err
+	}
+	if apdu.DataType == ComplexAck && apdu.ServiceType == ServiceConfirmedAtomicWriteFile {
+		return apdu.Payload.(*AtomicWriteFile).Start, nil
+	}
+	return 0, errors.New("invalid answer")
+}
+
+// fileChunkOverhead is the size of the APDU header and of the tags
+// around the data of a stream access, rounded up
+const fileChunkOverhead = 20
+
+// defaultFileChunk is the size of the chunks transferred with devices
+// of unknown max APDU, small enough for any device
+const defaultFileChunk = 480 - fileChunkOverhead
+
+// fileChunkSize returns the number of octets transferred by each
+// request of the stream helpers, so that neither the requests nor the
+// answers need to be segmented
+func (c *Client) fileChunkSize(device bacnet.Device) int {
+	maxApdu := c.pacer(device.ID).profile.apply(device).MaxApdu
+	if maxApdu == 0 {
+		return defaultFileChunk
+	}
+	//The client doesn't accept APDUs larger than 1476 bytes
+	if maxApdu > 1476 {
+		maxApdu = 1476
+	}
+	if maxApdu < 50 {
+		//Smaller than allowed by the standard
+		return defaultFileChunk
+	}
+	return int(maxApdu) - fileChunkOverhead
+}
+
+// ReadFile downloads the content of a File object with stream access
+// to w, in chunks fitting in the max APDU of the device. It returns
+// the number of octets written to w
+func (c *Client) ReadFile(ctx context.Context, device bacnet.Device, file bacnet.ObjectID, w io.Writer) (int64, error) {
+	if file.Type != bacnet.File {
+		return 0, fmt.Errorf("%v isn't a file", file)
+	}
+	chunk := c.fileChunkSize(device)
+	var total int64
+	for {
+		ack, err := c.AtomicReadFile(ctx, device, AtomicReadFile{
+			ObjectID: file,
+			Start:    int32(total),
+			Count:    uint32(chunk),
+		})
+		if err != nil {
+			return total, fmt.Errorf("read file at %d: %w", total, err)
+		}
+		if ack.Record {
+			return total, fmt.Errorf("%v uses record access", file)
+		}
+		n, err := w.Write(ack.Data)
+		total += int64(n)
+		if err != nil {
+			return total, err
+		}
+		if ack.EndOfFile {
+			return total, nil
+		}
+		if len(ack.Data) == 0 {
+			return total, errors.New("device returned no data before the end of the file")
+		}
+	}
+}
+
+// WriteFile uploads the content of r to a File object with stream
+// access, from its beginning, in chunks fitting in the max APDU of the
+// device. The file isn't truncated: the File_Size property must be
+// set to 0 beforehand to replace a larger file. It returns the number
+// of octets written
+func (c *Client) WriteFile(ctx context.Context, device bacnet.Device, file bacnet.ObjectID, r io.Reader) (int64, error) {
+	if file.Type != bacnet.File {
+		return 0, fmt.Errorf("%v isn't a file", file)
+	}
+	buf := make([]byte, c.fileChunkSize(device))
+	var total int64
+	for {
+		n, err := io.ReadFull(r, buf)
+		if n > 0 {
+			_, writeErr := c.AtomicWriteFile(ctx, device, AtomicWriteFile{
+				ObjectID: file,
+				Start:    int32(total),
+				Data:     buf[:n],
+			})
+			if writeErr != nil {
+				return total, fmt.Errorf("write file at %d: %w", total, writeErr)
+			}
+			total += int64(n)
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return total, nil
+		}
+		if err != nil {
+			return total, err
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package bacip
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"net"
+	"testing"
+
+	"github.com/REQUEA/bacnet"
+	"github.com/REQUEA/bacnet/internal/encoding"
+	"github.com/matryer/is"
+)
+
+func TestFileEncoding(t *testing.T) {
+	is := is.New(t)
+	file := bacnet.ObjectID{Type: bacnet.File, Instance: 1}
+	b, err := AtomicReadFile{ObjectID: file, Count: 100}.MarshalBinary()
+	is.NoErr(err)
+	is.Equal(hex.EncodeToString(b), "c4028000010e310021640f")
+
+	b, _ = hex.DecodeString("110e3100636162630f")
+	var rf AtomicReadFile
+	is.NoErr(rf.UnmarshalBinary(b))
+	is.Equal(rf, AtomicReadFile{EndOfFile: true, Count: 3, Data: []byte("abc")})
+
+	b, _ = hex.DecodeString("101e31022102616161621f")
+	rf = AtomicReadFile{}
+	is.NoErr(rf.UnmarshalBinary(b))
+	is.Equal(rf, AtomicReadFile{Record: true, Start: 2, Count: 2, Records: [][]byte{[]byte("a"), []byte("b")}})
+
+	b, err = AtomicWriteFile{ObjectID: file, Record: true, Start: -1, Records: [][]byte{[]byte("a")}}.MarshalBinary()
+	is.NoErr(err)
+	is.Equal(hex.EncodeToString(b), "c4028000011e31ff210161611f")
+	var wf AtomicWriteFile
+	is.NoErr(wf.UnmarshalBinary([]byte{0x19, 0x05}))
+	is.Equal(wf, AtomicWriteFile{Record: true, Start: 5})
+}
+
+func TestFileTransfer(t *testing.T) {
+	is := is.New(t)
+	addr := net.UDPAddr{IP: net.IPv4(10, 0, 2, 3).To4(), Port: DefaultUDPPort}
+	device := bacnet.Device{
+		ID:      bacnet.ObjectID{Type: bacnet.BacnetDevice, Instance: 10},
+		MaxApdu: 128,
+		Addr:    *bacnet.AddressFromUDP(addr),
+	}
+	file := bacnet.ObjectID{Type: bacnet.File, Instance: 1}
+	content := bytes.Repeat([]byte("firmware "), 100)
+
+	//The device stores the file written and returns it when read
+	var stored []byte
+	requests := 0
+	m := newMemTransport()
+	m.respond = answerRequests(t, func(request *APDU) *APDU {
+		requests++
+		data := request.Payload.(*DataPayload).Bytes
+		is.True(len(data)+4 <= int(device.MaxApdu))
+		d := encoding.NewDecoder(data)
+		var id bacnet.ObjectID
+		var start int32
+		d.AppData(&id)
+		is.Equal(id, file)
+		d.OpeningTag(0)
+		d.AppData(&start)
+		e := encoding.NewEncoder()
+		switch request.ServiceType {
+		case ServiceConfirmedAtomicWriteFile:
+			var chunk []byte
+			d.AppData(&chunk)
+			is.Equal(int(start), len(stored))
+			stored = append(stored, chunk...)
+			e.ContextSigned(0, start)
+		case ServiceConfirmedAtomicReadFile:
+			var count uint32
+			d.AppData(&count)
+			end := int(start) + int(count)
+			if end > len(stored) {
+				end = len(stored)
+			}
+			e.AppData(end == len(stored))
+			e.OpeningTag(0)
+			e.AppData(start)
+			e.AppData(stored[start:end])
+			e.ClosingTag(0)
+		default:
+			return nil
+		}
+		d.ClosingTag(0)
+		is.NoErr(d.Error())
+		is.NoErr(e.Error())
+		ack := &APDU{
+			DataType:    ComplexAck,
+			ServiceType: request.ServiceType,
+			InvokeID:    request.InvokeID,
+			Payload:     &DataPayload{Bytes: e.Bytes()},
+		}
+		return ack
+	})
+	c := newMemClient(t, m)
+
+	n, err := c.WriteFile(context.Background(), device, file, bytes.NewReader(content))
+	is.NoErr(err)
+	is.Equal(n, int64(len(content)))
+	is.Equal(stored, content)
+	is.Equal(requests, 9) //900 bytes in chunks of 108 bytes
+
+	var read bytes.Buffer
+	n, err = c.ReadFile(context.Background(), device, file, &read)
+	is.NoErr(err)
+	is.Equal(n, int64(len(content)))
+	is.Equal(read.Bytes(), content)
+
+	_, err = c.ReadFile(context.Background(), device, device.ID, &read)
+	is.True(err != nil)
+}
@@ -0,0 +1,271 @@
+package bacip
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+)
+
+// BVLCResultCode is the answer of a BBMD to a BVLL request
+type BVLCResultCode uint16
+
+//go:generate stringer -type=BVLCResultCode
+const (
+	BVLCResultSuccess                            BVLCResultCode = 0x0000
+	BVLCResultWriteBroadcastDistributionTableNAK BVLCResultCode = 0x0010
+	BVLCResultReadBroadcastDistributionTableNAK  BVLCResultCode = 0x0020
+	BVLCResultRegisterForeignDeviceNAK           BVLCResultCode = 0x0030
+	BVLCResultReadForeignDeviceTableNAK          BVLCResultCode = 0x0040
+	BVLCResultDeleteForeignDeviceTableEntryNAK   BVLCResultCode = 0x0050
+	BVLCResultDistributeBroadcastToNetworkNAK    BVLCResultCode = 0x0060
+)
+
+// foreignDeviceTimeout is how long a BBMD has to answer a
+// registration before the next one is tried
+var foreignDeviceTimeout = 3 * time.Second
+
+// foreignDeviceRetry is the delay between two registration attempts
+// when no BBMD answered
+const foreignDeviceRetry = 10 * time.Second
+
+// ForeignDeviceStatus is the state of the foreign device registration
+type ForeignDeviceStatus struct {
+	//BBMD is the BBMD the client is registered with, nil if none of
+	//them accepted the last registration
+	BBMD *net.UDPAddr
+	//Registered is the time of the last successful registration
+	Registered time.Time
+	//LastError is the error of the last registration, if it failed
+	LastError error
+}
+
+type foreignDevice struct {
+	bbmds []*net.UDPAddr
+	ttl   time.Duration
+	stop  chan struct{}
+	done  chan struct{}
+
+	sync.Mutex
+	status ForeignDeviceStatus
+}
+
+// RegisterForeignDevice registers the client as a foreign device, so
+// that it can discover devices on a remote network through a BBMD.
+// Broadcasts are then sent to the BBMD, which distributes them.
+//
+// The BBMDs are tried in order until one accepts the registration.
+// The registration is renewed every ttl/2 in the background, starting
+// again from the first BBMD so that the client goes back to the
+// primary BBMD once it answers again. An error is returned if no BBMD
+// accepted the first registration.
+func (c *Client) RegisterForeignDevice(ctx context.Context, bbmds []*net.UDPAddr, ttl time.Duration) error {
+	if len(bbmds) == 0 {
+		return errors.New("no BBMD to register with")
+	}
+	if ttl < time.Second || ttl > 0xFFFF*time.Second {
+		return fmt.Errorf("invalid time to live %v", ttl)
+	}
+	c.stopForeignDevice()
+	fd := &foreignDevice{
+		bbmds: bbmds,
+		ttl:   ttl,
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+	err := c.registerAny(ctx, fd)
+	if err != nil {
+		return err
+	}
+	c.foreignMutex.Lock()
+	c.foreign = fd
+	c.foreignMutex.Unlock()
+	go c.renewForeignDevice(fd)
+	return nil
+}
+
+// ForeignDeviceStatus returns the state of the registration, and false
+// if the client isn't registered as a foreign device
+func (c *Client) ForeignDeviceStatus() (ForeignDeviceStatus, bool) {
+	fd := c.foreignDevice()
+	if fd == nil {
+		return ForeignDeviceStatus{}, false
+	}
+	fd.Lock()
+	defer fd.Unlock()
+	return fd.status, true
+}
+
+// UnregisterForeignDevice stops renewing the registration, and removes
+// the client from the foreign device table of its BBMD. Broadcasts
+// are then sent on the local network again
+func (c *Client) UnregisterForeignDevice(ctx context.Context) error {
+	fd := c.stopForeignDevice()
+	if fd == nil {
+		return nil
+	}
+	fd.Lock()
+	bbmd := fd.status.BBMD
+	fd.Unlock()
+	if bbmd == nil {
+		return nil
+	}
+	entry := make([]byte, 6)
+	copy(entry, c.ipAddress.To4())
+	binary.BigEndian.PutUint16(entry[4:], uint16(c.udpPort))
+	return c.bvllRequest(ctx, bbmd, BVLC{
+		Type:     TypeBacnetIP,
+		Function: BacFuncDeleteForeignDeviceTableEntry,
+		Data:     entry,
+	})
+}
+
+func (c *Client) foreignDevice() *foreignDevice {
+	c.foreignMutex.Lock()
+	defer c.foreignMutex.Unlock()
+	return c.foreign
+}
+
+// stopForeignDevice stops the renewal of the current registration and
+// returns it
+func (c *Client) stopForeignDevice() *foreignDevice {
+	c.foreignMutex.Lock()
+	fd := c.foreign
+	c.foreign = nil
+	c.foreignMutex.Unlock()
+	if fd != nil {
+		close(fd.stop)
+		<-fd.done
+	}
+	return fd
+}
+
+// foreignBBMD returns the BBMD broadcasts must be sent to, or nil if
+// the client isn't a foreign device
+func (c *Client) foreignBBMD() (*net.UDPAddr, error) {
+	fd := c.foreignDevice()
+	if fd == nil {
+		return nil, nil
+	}
+	fd.Lock()
+	defer fd.Unlock()
+	if fd.status.BBMD == nil {
+		return nil, fmt.Errorf("not registered with any BBMD: %w", fd.status.LastError)
+	}
+	return fd.status.BBMD, nil
+}
+
+func (c *Client) renewForeignDevice(fd *foreignDevice) {
+	defer close(fd.done)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-fd.stop
+		cancel()
+	}()
+	delay := fd.ttl / 2
+	for {
+		timer := time.NewTimer(delay)
+		select {
+		case <-fd.stop:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		delay = fd.ttl / 2
+		err := c.registerAny(ctx, fd)
+		if err != nil && ctx.Err() == nil {
+			c.logger.Error("foreign device registration: ", err)
+			if delay > foreignDeviceRetry {
+				delay = foreignDeviceRetry
+			}
+		}
+	}
+}
+
+// registerAny registers with the first BBMD that accepts the
+// registration and updates the status
+func (c *Client) registerAny(ctx context.Context, fd *foreignDevice) error {
+	ttl := make([]byte, 2)
+	binary.BigEndian.PutUint16(ttl, uint16(fd.ttl/time.Second))
+	var errs []error
+	for _, bbmd := range fd.bbmds {
+		tctx, cancel := context.WithTimeout(ctx, foreignDeviceTimeout)
+		err := c.bvllRequest(tctx, bbmd, BVLC{
+			Type:     TypeBacnetIP,
+			Function: BacFuncRegisterForeignDevice,
+			Data:     ttl,
+		})
+		cancel()
+		if err == nil {
+			fd.Lock()
+			fd.status = ForeignDeviceStatus{BBMD: bbmd, Registered: time.Now()}
+			fd.Unlock()
+			return nil
+		}
+		errs = append(errs, fmt.Errorf("bbmd %v: %w", bbmd, err))
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	err := joinErrors(errs)
+	fd.Lock()
+	fd.status.BBMD = nil
+	fd.status.LastError = err
+	fd.Unlock()
+	return err
+}
+
+// bvllRequest sends a BVLL request to the BBMD and waits for its
+// result
+func (c *Client) bvllRequest(ctx context.Context, bbmd *net.UDPAddr, request BVLC) error {
+	result := make(chan BVLCResultCode, 1)
+	unsubscribe := c.subscriptions.subscribe(func(bvlc BVLC, src net.UDPAddr) {
+		if bvlc.Function != BacFuncResult || len(bvlc.Data) != 2 ||
+			!src.IP.Equal(bbmd.IP) || src.Port != bbmd.Port {
+			return
+		}
+		select {
+		case result <- BVLCResultCode(binary.BigEndian.Uint16(bvlc.Data)):
+		default:
+		}
+	})
+	defer unsubscribe()
+	b, err := request.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	_, err = c.udp.WriteToUDP(b, bbmd)
+	if err != nil {
+		return err
+	}
+	select {
+	case code := <-result:
+		if code != BVLCResultSuccess {
+			return fmt.Errorf("request refused: %v", code)
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// joinErrors returns an error containing the messages of all the
+// errors and wrapping the last one, or nil if there are none
+func joinErrors(errs []error) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	last := errs[len(errs)-1]
+	if len(errs) == 1 {
+		return last
+	}
+	msg := ""
+	for _, err := range errs[:len(errs)-1] {
+		msg += err.Error() + "; "
+	}
+	return fmt.Errorf("%s%w", msg, last)
+}
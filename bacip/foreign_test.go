@@ -0,0 +1,104 @@
+package bacip
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestForeignDeviceFailover(t *testing.T) {
+	is := is.New(t)
+	foreignDeviceTimeout = 20 * time.Millisecond
+	defer func() { foreignDeviceTimeout = 3 * time.Second }()
+	primary := &net.UDPAddr{IP: net.IPv4(10, 0, 0, 1), Port: DefaultUDPPort}
+	secondary := &net.UDPAddr{IP: net.IPv4(10, 0, 1, 1), Port: DefaultUDPPort}
+	m := newMemTransport()
+	primaryUp := false
+	m.respond = func(b []byte, addr *net.UDPAddr) []byte {
+		var bvlc BVLC
+		if bvlc.UnmarshalBinary(b) != nil || bvlc.Function != BacFuncRegisterForeignDevice {
+			return nil
+		}
+		if addr.IP.Equal(primary.IP) && !primaryUp {
+			return nil
+		}
+		result, _ := BVLC{Type: TypeBacnetIP, Function: BacFuncResult, Data: []byte{0, 0}}.MarshalBinary()
+		return result
+	}
+	c := newMemClient(t, m)
+
+	err := c.RegisterForeignDevice(context.Background(), []*net.UDPAddr{primary, secondary}, 60*time.Second)
+	is.NoErr(err)
+	status, ok := c.ForeignDeviceStatus()
+	is.True(ok)
+	is.Equal(status.BBMD, secondary)
+
+	_, err = c.WhoIs(WhoIs{}, 10*time.Millisecond)
+	is.NoErr(err)
+	m.Lock()
+	last := m.written[len(m.written)-1]
+	to := m.to[len(m.to)-1]
+	m.Unlock()
+	is.Equal(to, secondary)
+	is.Equal(Function(last[1]), BacFuncDistributeBroadcastToNetwork)
+
+	//The next renewal goes back to the primary
+	primaryUp = true
+	is.NoErr(c.registerAny(context.Background(), c.foreignDevice()))
+	status, _ = c.ForeignDeviceStatus()
+	is.Equal(status.BBMD, primary)
+}
+
+func TestForeignDeviceNoBBMD(t *testing.T) {
+	is := is.New(t)
+	foreignDeviceTimeout = 10 * time.Millisecond
+	defer func() { foreignDeviceTimeout = 3 * time.Second }()
+	m := newMemTransport()
+	c := newMemClient(t, m)
+	err := c.RegisterForeignDevice(context.Background(), []*net.UDPAddr{{IP: net.IPv4(10, 0, 0, 1), Port: DefaultUDPPort}}, time.Minute)
+	is.True(err != nil)
+	_, ok := c.ForeignDeviceStatus()
+	is.True(!ok)
+}
+
+func TestTransmit(t *testing.T) {
+	is := is.New(t)
+	m := newMemTransport()
+	c := newMemClient(t, m)
+	npdu := NPDU{Version: Version1, Priority: Normal, ADPU: &APDU{
+		DataType:    UnconfirmedServiceRequest,
+		ServiceType: ServiceUnconfirmedWhoIs,
+		Payload:     &WhoIs{},
+	}}
+	last := func() (Function, string) {
+		m.Lock()
+		defer m.Unlock()
+		var sent BVLC
+		is.NoErr(sent.UnmarshalBinary(m.written[len(m.written)-1]))
+		return sent.Function, m.to[len(m.to)-1].String()
+	}
+
+	_, err := c.Transmit(BacFuncBroadcast, nil, npdu)
+	is.NoErr(err)
+	f, to := last()
+	is.Equal(f, BacFuncBroadcast)
+	is.Equal(to, "10.0.2.255:47808")
+
+	//Through a BBMD without registering as a foreign device
+	bbmd := &net.UDPAddr{IP: net.IPv4(10, 0, 9, 1).To4(), Port: DefaultUDPPort}
+	_, err = c.Transmit(BacFuncDistributeBroadcastToNetwork, bbmd, npdu)
+	is.NoErr(err)
+	f, to = last()
+	is.Equal(f, BacFuncDistributeBroadcastToNetwork)
+	is.Equal(to, bbmd.String())
+	_, err = c.Transmit(BacFuncDistributeBroadcastToNetwork, nil, npdu)
+	is.True(err != nil) //not registered
+
+	_, err = c.Transmit(BacFuncUnicast, nil, npdu)
+	is.True(err != nil) //no destination
+	_, err = c.Transmit(BacFuncForwardedNPDU, bbmd, npdu)
+	is.True(err != nil)
+}
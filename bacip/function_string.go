@@ -13,28 +13,23 @@ func _() {
 	_ = x[BacFuncBroadcastDistributionTable-2]
 	_ = x[BacFuncBroadcastDistributionTableAck-3]
 	_ = x[BacFuncForwardedNPDU-4]
+	_ = x[BacFuncRegisterForeignDevice-5]
+	_ = x[BacFuncReadForeignDeviceTable-6]
+	_ = x[BacFuncReadForeignDeviceTableAck-7]
+	_ = x[BacFuncDeleteForeignDeviceTableEntry-8]
+	_ = x[BacFuncDistributeBroadcastToNetwork-9]
 	_ = x[BacFuncUnicast-10]
 	_ = x[BacFuncBroadcast-11]
 }
 
-const (
-	_Function_name_0 = "BacFuncResultBacFuncWriteBroadcastDistributionTableBacFuncBroadcastDistributionTableBacFuncBroadcastDistributionTableAckBacFuncForwardedNPDU"
-	_Function_name_1 = "BacFuncUnicastBacFuncBroadcast"
-)
+const _Function_name = "BacFuncResultBacFuncWriteBroadcastDistributionTableBacFuncBroadcastDistributionTableBacFuncBroadcastDistributionTableAckBacFuncForwardedNPDUBacFuncRegisterForeignDeviceBacFuncReadForeignDeviceTableBacFuncReadForeignDeviceTableAckBacFuncDeleteForeignDeviceTableEntryBacFuncDistributeBroadcastToNetworkBacFuncUnicastBacFuncBroadcast"
 
-var (
-	_Function_index_0 = [...]uint8{0, 13, 51, 84, 120, 140}
-	_Function_index_1 = [...]uint8{0, 14, 30}
-)
+var _Function_index = [...]uint16{0, 13, 51, 84, 120, 140, 168, 197, 229, 265, 300, 314, 330}
 
 func (i Function) String() string {
-	switch {
-	case i <= 4:
-		return _Function_name_0[_Function_index_0[i]:_Function_index_0[i+1]]
-	case 10 <= i && i <= 11:
-		i -= 10
-		return _Function_name_1[_Function_index_1[i]:_Function_index_1[i+1]]
-	default:
+	idx := int(i) - 0
+	if i < 0 || idx >= len(_Function_index)-1 {
 		return "Function(" + strconv.FormatInt(int64(i), 10) + ")"
 	}
+	return _Function_name[_Function_index[idx]:_Function_index[idx+1]]
 }
@@ -0,0 +1,218 @@
+package bacip
+
+import (
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/REQUEA/bacnet"
+)
+
+// GoldenVersion is the version of the golden vectors. It's incremented
+// when an encoding is changed on purpose
+const GoldenVersion = 1
+
+// GoldenVector is the canonical encoding of a sample service payload
+type GoldenVector struct {
+	Name string `json:"name"`
+	Hex  string `json:"hex"`
+}
+
+// GoldenSet contains the golden vectors of a version of the package.
+// Applications can store it, as JSON, and check it against the
+// following versions with CheckGoldenVectors to detect the changes of
+// the wire format when upgrading
+type GoldenSet struct {
+	Version int            `json:"version"`
+	Vectors []GoldenVector `json:"vectors"`
+}
+
+// GoldenMismatch is a stored golden vector that doesn't match the
+// encoding of this version of the package
+type GoldenMismatch struct {
+	Name string
+	Want string
+	Got  string
+	//Removed is true if the payload isn't encoded anymore
+	Removed bool
+}
+
+func (m GoldenMismatch) String() string {
+	if m.Removed {
+		return fmt.Sprintf("%s: removed, was %s", m.Name, m.Want)
+	}
+	return fmt.Sprintf("%s: encoded as %s, was %s", m.Name, m.Got, m.Want)
+}
+
+type goldenSample struct {
+	name    string
+	payload Payload
+}
+
+// goldenSamples returns the sample payloads of the golden vectors. The
+// samples are only added, never changed: a changed encoding must show
+// as a mismatch
+func goldenSamples() []goldenSample {
+	low, high := uint32(10), uint32(20)
+	index := uint32(2)
+	device := bacnet.ObjectID{Type: bacnet.BacnetDevice, Instance: 10}
+	av := bacnet.ObjectID{Type: bacnet.AnalogValue, Instance: 1}
+	file := bacnet.ObjectID{Type: bacnet.File, Instance: 2}
+	sequence := uint32(8)
+	return []goldenSample{
+		{"WhoIs", &WhoIs{}},
+		{"WhoIs/range", &WhoIs{Low: &low, High: &high}},
+		{"IAm", &Iam{ObjectID: device, MaxApduLength: 1476, SegmentationSupport: bacnet.SegmentationSupportBoth, VendorID: 260}},
+		{"ReadProperty", &ReadProperty{ObjectID: av, Property: bacnet.PropertyIdentifier{Type: bacnet.PresentValue}}},
+		{"ReadProperty/index", &ReadProperty{ObjectID: av, Property: bacnet.PropertyIdentifier{Type: bacnet.PriorityArray, ArrayIndex: &index}}},
+		{"WriteProperty", &WriteProperty{
+			ObjectID:      av,
+			Property:      bacnet.PropertyIdentifier{Type: bacnet.PresentValue},
+			PropertyValue: bacnet.PropertyValue{Type: bacnet.TypeReal, Value: float32(21.5)},
+			Priority:      bacnet.ManualOperator8,
+		}},
+		{"WriteProperty/relinquish", &WriteProperty{
+			ObjectID:      av,
+			Property:      bacnet.PropertyIdentifier{Type: bacnet.PresentValue},
+			PropertyValue: bacnet.PropertyValue{Type: bacnet.TypeNull},
+			Priority:      bacnet.ManualOperator8,
+		}},
+		{"Error", &ApduError{Class: bacnet.PropertyError, Code: bacnet.UnknownProperty}},
+		{"ReadPropertyMultiple", &ReadPropertyMultiple{Specifications: []ReadAccessSpecification{{
+			ObjectID: av,
+			Properties: []bacnet.PropertyIdentifier{
+				{Type: bacnet.PresentValue},
+				{Type: bacnet.StatusFlags},
+			},
+		}}}},
+		{"WritePropertyMultiple", &WritePropertyMultiple{Specifications: []WriteAccessSpecification{{
+			ObjectID: av,
+			Properties: []PropertyWrite{{
+				Property: bacnet.PropertyIdentifier{Type: bacnet.PresentValue},
+				Value:    bacnet.PropertyValue{Type: bacnet.TypeReal, Value: float32(21)},
+				Priority: bacnet.ManualOperator8,
+			}},
+		}}}},
+		{"WritePropertyMultiple/error", &WritePropertyMultipleError{
+			Err:      ApduError{Class: bacnet.PropertyError, Code: bacnet.WriteAccessDenied},
+			ObjectID: av,
+			Property: bacnet.PropertyIdentifier{Type: bacnet.PresentValue},
+		}},
+		{"ReadRange/position", &ReadRange{
+			ObjectID: bacnet.ObjectID{Type: bacnet.Trendlog, Instance: 1},
+			Property: bacnet.PropertyIdentifier{Type: bacnet.LogBuffer},
+			Range:    &Range{Type: RangeByPosition, ReferenceIndex: 1, Count: 10},
+		}},
+		{"ReadRange/sequence", &ReadRange{
+			ObjectID: bacnet.ObjectID{Type: bacnet.Trendlog, Instance: 1},
+			Property: bacnet.PropertyIdentifier{Type: bacnet.LogBuffer},
+			Range:    &Range{Type: RangeBySequence, ReferenceSequence: 100, Count: -10},
+		}},
+		{"SubscribeCOV", &SubscribeCOV{ProcessID: 1, ObjectID: av, IssueConfirmed: true, Lifetime: 5 * time.Minute}},
+		{"SubscribeCOV/cancel", &SubscribeCOV{ProcessID: 1, ObjectID: av, Cancel: true}},
+		{"COVNotification", &COVNotification{
+			ProcessID:     1,
+			Device:        device,
+			ObjectID:      av,
+			TimeRemaining: time.Minute,
+			Values: []COVValue{
+				{Property: bacnet.PropertyIdentifier{Type: bacnet.PresentValue}, Value: float32(21)},
+			},
+		}},
+		{"EventNotification", &EventNotification{
+			ProcessID:         1,
+			InitiatingDevice:  device,
+			EventObject:       av,
+			TimeStamp:         bacnet.TimeStamp{Kind: bacnet.TimeStampSequence, SequenceNumber: sequence},
+			NotificationClass: 5,
+			Priority:          100,
+			EventType:         bacnet.EventTypeOutOfRange,
+			NotifyType:        bacnet.NotifyTypeAlarm,
+			AckRequired:       true,
+			FromState:         bacnet.EventStateNormal,
+			ToState:           bacnet.EventStateHighLimit,
+		}},
+		{"AtomicReadFile", &AtomicReadFile{ObjectID: file, Start: 0, Count: 480}},
+		{"AtomicWriteFile", &AtomicWriteFile{ObjectID: file, Start: 128, Data: []byte("data")}},
+		{"ReinitializeDevice", &ReinitializeDevice{State: WarmStart, Password: "secret"}},
+		{"LifeSafetyOperation", &LifeSafetyOperationRequest{ProcessID: 1, RequestingSource: "operator", Request: bacnet.LifeSafetyOperationSilence}},
+		{"CreateObject", &CreateObject{
+			ObjectType: bacnet.NotificationClass,
+			InitialValues: []PropertyWrite{{
+				Property: bacnet.PropertyIdentifier{Type: bacnet.ObjectName},
+				Value:    bacnet.PropertyValue{Type: bacnet.TypeCharacterString, Value: "alarms"},
+			}},
+		}},
+		{"CreateObject/error", &CreateObjectError{Err: ApduError{Class: bacnet.ObjectError, Code: bacnet.DynamicCreationNotSupported}}},
+		{"DeleteObject", &DeleteObject{ObjectID: av}},
+		{"PrivateTransfer", &PrivateTransfer{VendorID: 260, ServiceNumber: 1, Parameters: []byte{0x21, 0x05}}},
+		{"PrivateTransfer/error", &PrivateTransferError{
+			Err:           ApduError{Class: bacnet.ServicesError, Code: bacnet.ServiceRequestDenied},
+			VendorID:      260,
+			ServiceNumber: 1,
+		}},
+		{"TextMessage", &TextMessage{Source: device, Class: "maintenance", Priority: TextMessageUrgent, Message: "filter"}},
+		{"TimeSync", &TimeSync{Date: bacnet.Date{Year: 2024, Month: 3, Day: 14, Weekday: 4}, Time: bacnet.Time{Hour: 13, Minute: 5, Second: 30}}},
+		{"GetEnrollmentSummary", &GetEnrollmentSummary{AckFilter: AckFilterNotAcked, PriorityFilter: &PriorityFilter{Min: 1, Max: 100}}},
+		{"GetEventInformation", &GetEventInformation{LastReceived: &av}},
+		{"WhoHas/id", &WhoHas{ObjectID: &av}},
+		{"WhoHas/name", &WhoHas{Low: &low, High: &high, ObjectName: "ChilledWaterSetpoint"}},
+		{"IHave", &IHave{Device: device, ObjectID: av, ObjectName: "ChilledWaterSetpoint"}},
+		{"ReadPropertyConditional", &ReadPropertyConditional{
+			Logic: SelectionLogicAnd,
+			Criteria: []SelectionCriterion{{
+				Property: bacnet.PropertyIdentifier{Type: bacnet.PresentValue},
+				Relation: RelationGreaterThan,
+				Value:    bacnet.PropertyValue{Type: bacnet.TypeReal, Value: float32(21)},
+			}},
+			Properties: []bacnet.PropertyIdentifier{{Type: bacnet.ObjectName}},
+		}},
+		{"WriteGroup", &WriteGroup{
+			Group:    5,
+			Priority: bacnet.ManualOperator8,
+			Changes: []GroupChannelValue{{
+				Channel: 3,
+				Value:   ChannelValue{Value: bacnet.PropertyValue{Type: bacnet.TypeEnumerated, Value: uint32(1)}},
+			}},
+		}},
+		{"WhoAmI", &WhoAmI{VendorID: 260, ModelName: "X1", SerialNumber: "42"}},
+		{"YouAre", &YouAre{VendorID: 260, ModelName: "X1", SerialNumber: "42", DeviceID: &device}},
+	}
+}
+
+// GoldenVectors returns the golden vectors of this version of the
+// package: the encodings of samples of every service payload
+func GoldenVectors() (GoldenSet, error) {
+	set := GoldenSet{Version: GoldenVersion}
+	for _, s := range goldenSamples() {
+		b, err := s.payload.MarshalBinary()
+		if err != nil {
+			return set, fmt.Errorf("encode %s: %w", s.name, err)
+		}
+		set.Vectors = append(set.Vectors, GoldenVector{Name: s.name, Hex: hex.EncodeToString(b)})
+	}
+	return set, nil
+}
+
+// CheckGoldenVectors compares stored golden vectors with the encodings
+// of this version of the package and returns the vectors that don't
+// match. The vectors added since the stored set don't break the
+// compatibility and are ignored
+func CheckGoldenVectors(stored GoldenSet) ([]GoldenMismatch, error) {
+	current, err := GoldenVectors()
+	if err != nil {
+		return nil, err
+	}
+	encodings := make(map[string]string, len(current.Vectors))
+	for _, v := range current.Vectors {
+		encodings[v.Name] = v.Hex
+	}
+	var mismatches []GoldenMismatch
+	for _, v := range stored.Vectors {
+		got, ok := encodings[v.Name]
+		if !ok || got != v.Hex {
+			mismatches = append(mismatches, GoldenMismatch{Name: v.Name, Want: v.Hex, Got: got, Removed: !ok})
+		}
+	}
+	return mismatches, nil
+}
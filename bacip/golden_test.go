@@ -0,0 +1,42 @@
+package bacip
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestGoldenVectors(t *testing.T) {
+	is := is.New(t)
+	b, err := os.ReadFile("testdata/golden.json")
+	is.NoErr(err)
+	var stored GoldenSet
+	is.NoErr(json.Unmarshal(b, &stored))
+	is.Equal(stored.Version, GoldenVersion)
+	mismatches, err := CheckGoldenVectors(stored)
+	is.NoErr(err)
+	for _, m := range mismatches {
+		t.Errorf("golden vector %v", m)
+	}
+
+	current, err := GoldenVectors()
+	is.NoErr(err)
+	names := map[string]bool{}
+	for _, v := range current.Vectors {
+		is.True(!names[v.Name])
+		names[v.Name] = true
+	}
+
+	stored.Vectors = []GoldenVector{
+		{Name: "DeleteObject", Hex: "c400800002"},
+		{Name: "RemovedService", Hex: "09"},
+	}
+	mismatches, err = CheckGoldenVectors(stored)
+	is.NoErr(err)
+	is.Equal(mismatches, []GoldenMismatch{
+		{Name: "DeleteObject", Want: "c400800002", Got: "c400800001"},
+		{Name: "RemovedService", Want: "09", Removed: true},
+	})
+}
@@ -0,0 +1,117 @@
+package bacip
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/REQUEA/bacnet"
+	"github.com/REQUEA/bacnet/internal/encoding"
+)
+
+// Group is the content of a Group object: the properties it
+// references and their current values
+type Group struct {
+	Members []ReadAccessSpecification
+	Values  []ReadAccessResult
+}
+
+// ReadGroup reads the list-of-group-members and the present-value of a
+// Group object
+func (c *Client) ReadGroup(ctx context.Context, device bacnet.Device, group bacnet.ObjectID) (Group, error) {
+	g := Group{}
+	raw, err := c.readRaw(ctx, device, ReadProperty{
+		ObjectID: group,
+		Property: bacnet.PropertyIdentifier{Type: bacnet.ListOfGroupMembers},
+	})
+	if err != nil {
+		return g, fmt.Errorf("read group members: %w", err)
+	}
+	g.Members = []ReadAccessSpecification{}
+	err = decodeList(raw, func(d *encoding.Decoder) error {
+		s := ReadAccessSpecification{}
+		s.decode(d)
+		g.Members = append(g.Members, s)
+		return nil
+	})
+	if err != nil {
+		return g, fmt.Errorf("decode group members: %w", err)
+	}
+	raw, err = c.readRaw(ctx, device, ReadProperty{
+		ObjectID: group,
+		Property: bacnet.PropertyIdentifier{Type: bacnet.PresentValue},
+	})
+	if err != nil {
+		return g, fmt.Errorf("read group present value: %w", err)
+	}
+	g.Values, err = decodeReadAccessResults(raw)
+	return g, err
+}
+
+// PropertyAccessResult is the result of the read of a property of an
+// object, optionally located in another device. Either Value or
+// Error is set
+type PropertyAccessResult struct {
+	Reference DeviceObjectPropertyReference
+	//Value is decoded like ReadProperty.Data
+	Value interface{}
+	Error *ApduError
+}
+
+func (r PropertyAccessResult) encode(e *encoding.Encoder) {
+	r.Reference.encode(e)
+	encodeResult(e, 4, r.Value, r.Error)
+}
+
+func (r *PropertyAccessResult) decode(d *encoding.Decoder) {
+	r.Reference.decode(d)
+	r.Value, r.Error = decodeResult(d, 4)
+}
+
+// GlobalGroup is the content of a Global Group object: the properties
+// it references, which may be located in other devices, and their
+// current values
+type GlobalGroup struct {
+	Members []DeviceObjectPropertyReference
+	Values  []PropertyAccessResult
+}
+
+// ReadGlobalGroup reads the group-members and the present-value of a
+// Global Group object
+func (c *Client) ReadGlobalGroup(ctx context.Context, device bacnet.Device, group bacnet.ObjectID) (GlobalGroup, error) {
+	g := GlobalGroup{}
+	raw, err := c.readRaw(ctx, device, ReadProperty{
+		ObjectID: group,
+		Property: bacnet.PropertyIdentifier{Type: bacnet.GroupMembers},
+	})
+	if err != nil {
+		return g, fmt.Errorf("read global group members: %w", err)
+	}
+	g.Members = []DeviceObjectPropertyReference{}
+	err = decodeList(raw, func(d *encoding.Decoder) error {
+		r := DeviceObjectPropertyReference{}
+		r.decode(d)
+		g.Members = append(g.Members, r)
+		return nil
+	})
+	if err != nil {
+		return g, fmt.Errorf("decode global group members: %w", err)
+	}
+	raw, err = c.readRaw(ctx, device, ReadProperty{
+		ObjectID: group,
+		Property: bacnet.PropertyIdentifier{Type: bacnet.PresentValue},
+	})
+	if err != nil {
+		return g, fmt.Errorf("read global group present value: %w", err)
+	}
+	g.Values = []PropertyAccessResult{}
+	err = decodeList(raw, func(d *encoding.Decoder) error {
+		r := PropertyAccessResult{}
+		r.decode(d)
+		g.Values = append(g.Values, r)
+		return nil
+	})
+	if err != nil {
+		return g, fmt.Errorf("decode global group present value: %w", err)
+	}
+	return g, nil
+}
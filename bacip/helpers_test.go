@@ -0,0 +1,468 @@
+package bacip
+
+import (
+	"net"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/REQUEA/bacnet"
+	"github.com/REQUEA/bacnet/internal/encoding"
+)
+
+// memTransport is a transport whose incoming datagrams are pushed by
+// the test and outgoing ones are recorded
+type memTransport struct {
+	sync.Mutex
+	in      chan datagram
+	written [][]byte
+	to      []*net.UDPAddr
+	//respond, if set, returns the answer to a written datagram
+	respond   func(b []byte, addr *net.UDPAddr) []byte
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+func newMemTransport() *memTransport {
+	return &memTransport{in: make(chan datagram, 16), closed: make(chan struct{})}
+}
+
+// newMemClient returns a client using the memory transport, closed at
+// the end of the test
+func newMemClient(t *testing.T, m *memTransport) *Client {
+	c, err := NewClientWithTransport("10.0.2.2/24", func(int) (Transport, error) {
+		return m, nil
+	}, 0, NoOpLogger{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+// answerRequests returns a respond function of a memory transport
+// answering the confirmed requests with the APDU returned by answer,
+// unless it's nil
+func answerRequests(t *testing.T, answer func(request *APDU) *APDU) func(b []byte, addr *net.UDPAddr) []byte {
+	return func(b []byte, _ *net.UDPAddr) []byte {
+		var bvlc BVLC
+		if bvlc.UnmarshalBinary(b) != nil || bvlc.NPDU.ADPU == nil || bvlc.NPDU.ADPU.DataType != ConfirmedServiceRequest {
+			return nil
+		}
+		apdu := answer(bvlc.NPDU.ADPU)
+		if apdu == nil {
+			return nil
+		}
+		datagram, err := datagramOf(apdu)
+		if err != nil {
+			t.Error(err)
+			return nil
+		}
+		return datagram
+	}
+}
+
+func (m *memTransport) ReadFromUDP(b []byte) (int, *net.UDPAddr, error) {
+	select {
+	case d := <-m.in:
+		return copy(b, d.data), d.addr, nil
+	case <-m.closed:
+		return 0, nil, net.ErrClosed
+	}
+}
+
+func (m *memTransport) WriteToUDP(b []byte, addr *net.UDPAddr) (int, error) {
+	m.Lock()
+	m.written = append(m.written, b)
+	m.to = append(m.to, addr)
+	m.Unlock()
+	if m.respond != nil {
+		if answer := m.respond(b, addr); answer != nil {
+			m.in <- datagram{data: answer, addr: addr}
+		}
+	}
+	return len(b), nil
+}
+
+func (m *memTransport) count() int {
+	m.Lock()
+	defer m.Unlock()
+	return len(m.written)
+}
+
+func (m *memTransport) LocalAddr() net.Addr { return &net.UDPAddr{} }
+
+func (m *memTransport) Close() error {
+	m.closeOnce.Do(func() { close(m.closed) })
+	return nil
+}
+
+// newAckTransport returns a transport answering all the confirmed
+// requests with a SimpleAck
+func newAckTransport(t *testing.T) *memTransport {
+	m := newMemTransport()
+	m.respond = answerRequests(t, func(request *APDU) *APDU {
+		return &APDU{
+			DataType:    SimpleAck,
+			ServiceType: request.ServiceType,
+			InvokeID:    request.InvokeID,
+			Payload:     &DataPayload{},
+		}
+	})
+	return m
+}
+
+// datagramOf returns the unicast datagram carrying the APDU
+func datagramOf(apdu *APDU) ([]byte, error) {
+	return BVLC{
+		Type:     TypeBacnetIP,
+		Function: BacFuncUnicast,
+		NPDU:     NPDU{Version: Version1, Priority: Normal, ADPU: apdu},
+	}.MarshalBinary()
+}
+
+// memDevice is a fake device answering ReadProperty,
+// ReadPropertyMultiple and WriteProperty requests with the properties
+// of its objects. Their values are the types accepted by the encoder,
+// enumerated values being set as a bacnet.PropertyValue and arrays as
+// a []interface{}. The values written are kept encoded, as a RawValue
+type memDevice struct {
+	sync.Mutex
+	id       bacnet.ObjectID
+	addr     net.UDPAddr
+	objects  map[bacnet.ObjectID]map[bacnet.PropertyType]interface{}
+	requests []memRequest
+	//drop is the number of the next requests left unanswered, all of
+	//them if negative
+	drop int
+}
+
+// memRequest is a request received by a memDevice, one per property
+// for ReadPropertyMultiple
+type memRequest struct {
+	service  ServiceType
+	object   bacnet.ObjectID
+	property bacnet.PropertyIdentifier
+	//value is the written value, decoded like ReadProperty.Data
+	value    interface{}
+	priority bacnet.PriorityList
+}
+
+func newMemDevice(instance bacnet.ObjectInstance) *memDevice {
+	id := bacnet.ObjectID{Type: bacnet.BacnetDevice, Instance: instance}
+	return &memDevice{
+		id:      id,
+		addr:    net.UDPAddr{IP: net.IPv4(10, 0, 2, byte(instance)).To4(), Port: DefaultUDPPort},
+		objects: map[bacnet.ObjectID]map[bacnet.PropertyType]interface{}{id: {}},
+	}
+}
+
+func (d *memDevice) device() bacnet.Device {
+	return bacnet.Device{
+		ID:           d.id,
+		MaxApdu:      1476,
+		Segmentation: bacnet.SegmentationSupportNone,
+		Addr:         *bacnet.AddressFromUDP(d.addr),
+	}
+}
+
+// set sets the value of a property, creating the object if needed.
+// A nil value only creates the object
+func (d *memDevice) set(id bacnet.ObjectID, prop bacnet.PropertyType, value interface{}) {
+	d.Lock()
+	defer d.Unlock()
+	props, ok := d.objects[id]
+	if !ok {
+		props = map[bacnet.PropertyType]interface{}{}
+		d.objects[id] = props
+	}
+	if value != nil {
+		props[prop] = value
+	}
+}
+
+// written returns the last value written to the property, decoded
+// like ReadProperty.Data
+func (d *memDevice) written(id bacnet.ObjectID, prop bacnet.PropertyType) (interface{}, bool) {
+	d.Lock()
+	defer d.Unlock()
+	raw, ok := d.objects[id][prop].(RawValue)
+	if !ok {
+		return nil, false
+	}
+	return decodeRaw(raw), true
+}
+
+// received returns the requests received by the device, oldest first
+func (d *memDevice) received() []memRequest {
+	d.Lock()
+	defer d.Unlock()
+	return append([]memRequest(nil), d.requests...)
+}
+
+func (d *memDevice) reset() {
+	d.Lock()
+	defer d.Unlock()
+	d.requests = nil
+}
+
+// read tells if the property was read
+func (d *memDevice) read(id bacnet.ObjectID, prop bacnet.PropertyType) bool {
+	for _, r := range d.received() {
+		if (r.service == ServiceConfirmedReadProperty || r.service == ServiceConfirmedReadPropMultiple) &&
+			r.object == id && r.property.Type == prop {
+			return true
+		}
+	}
+	return false
+}
+
+// serveDevices returns a respond function of a memory transport
+// answering the requests sent to the devices
+func serveDevices(t *testing.T, devices ...*memDevice) func(b []byte, addr *net.UDPAddr) []byte {
+	return func(b []byte, addr *net.UDPAddr) []byte {
+		for _, d := range devices {
+			if d.addr.String() == addr.String() {
+				return answerRequests(t, d.answer)(b, addr)
+			}
+		}
+		return nil
+	}
+}
+
+// answer returns the answer to a request, nil if it's dropped
+func (d *memDevice) answer(request *APDU) *APDU {
+	d.Lock()
+	defer d.Unlock()
+	if d.drop != 0 {
+		if d.drop > 0 {
+			d.drop--
+		}
+		d.requests = append(d.requests, memRequest{service: request.ServiceType})
+		return nil
+	}
+	data := request.Payload.(*DataPayload).Bytes
+	dec := encoding.NewDecoder(data)
+	e := encoding.NewEncoder()
+	var apduErr *ApduError
+	switch request.ServiceType {
+	case ServiceConfirmedReadProperty:
+		var id bacnet.ObjectID
+		dec.ContextObjectID(0, &id)
+		prop := decodePropertyID(dec, 1)
+		d.requests = append(d.requests, memRequest{service: request.ServiceType, object: id, property: prop})
+		var raw []byte
+		raw, apduErr = d.value(id, prop)
+		e.ContextObjectID(0, id)
+		encodePropertyID(&e, 1, prop)
+		e.ContextRaw(3, raw)
+	case ServiceConfirmedReadPropMultiple:
+		for dec.Error() == nil && dec.Len() > 0 {
+			var id bacnet.ObjectID
+			dec.ContextObjectID(0, &id)
+			dec.OpeningTag(1)
+			e.ContextObjectID(0, id)
+			e.OpeningTag(1)
+			for dec.Error() == nil && !dec.IsClosingTag(1) {
+				prop := decodePropertyID(dec, 0)
+				d.requests = append(d.requests, memRequest{service: request.ServiceType, object: id, property: prop})
+				props := []bacnet.PropertyIdentifier{prop}
+				if _, ok := d.objects[d.object(id)]; ok && prop.Type == bacnet.All {
+					props = d.properties(id)
+				}
+				for _, p := range props {
+					encodePropertyID(&e, 2, p)
+					raw, err := d.value(id, p)
+					if err != nil {
+						e.OpeningTag(5)
+						e.AppValue(bacnet.PropertyValue{Type: bacnet.TypeEnumerated, Value: uint32(err.Class)})
+						e.AppValue(bacnet.PropertyValue{Type: bacnet.TypeEnumerated, Value: uint32(err.Code)})
+						e.ClosingTag(5)
+						continue
+					}
+					e.ContextRaw(4, raw)
+				}
+			}
+			dec.ClosingTag(1)
+			e.ClosingTag(1)
+		}
+	case ServiceConfirmedWriteProperty:
+		var id bacnet.ObjectID
+		dec.ContextObjectID(0, &id)
+		prop := decodePropertyID(dec, 1)
+		var raw []byte
+		dec.ContextRaw(3, &raw)
+		var priority uint32
+		if dec.IsContextTag(4) {
+			dec.ContextValue(4, &priority)
+		}
+		d.requests = append(d.requests, memRequest{
+			service:  request.ServiceType,
+			object:   id,
+			property: prop,
+			value:    decodeRaw(raw),
+			priority: bacnet.PriorityList(priority),
+		})
+		if props, ok := d.objects[d.object(id)]; ok {
+			props[prop.Type] = RawValue(raw)
+		} else {
+			apduErr = &ApduError{Class: bacnet.ObjectError, Code: bacnet.UnknownObject}
+		}
+	default:
+		return nil
+	}
+	if dec.Error() != nil || e.Error() != nil {
+		return &APDU{DataType: Reject, InvokeID: request.InvokeID, ServiceType: ServiceType(RejectReasonInvalidTag), Payload: &DataPayload{}}
+	}
+	switch {
+	case apduErr != nil:
+		return &APDU{DataType: Error, InvokeID: request.InvokeID, ServiceType: request.ServiceType, Payload: apduErr}
+	case request.ServiceType == ServiceConfirmedWriteProperty:
+		return &APDU{DataType: SimpleAck, InvokeID: request.InvokeID, ServiceType: request.ServiceType, Payload: &DataPayload{}}
+	}
+	return &APDU{DataType: ComplexAck, InvokeID: request.InvokeID, ServiceType: request.ServiceType, Payload: &DataPayload{Bytes: e.Bytes()}}
+}
+
+// object returns the object designated by the identifier of a
+// request: the device object for the wildcard device
+func (d *memDevice) object(id bacnet.ObjectID) bacnet.ObjectID {
+	if id == bacnet.WildcardDevice {
+		return d.id
+	}
+	return id
+}
+
+// value returns the encoded value of a property, or of an element of
+// an array. The lock must be held
+func (d *memDevice) value(id bacnet.ObjectID, prop bacnet.PropertyIdentifier) ([]byte, *ApduError) {
+	id = d.object(id)
+	props, ok := d.objects[id]
+	if !ok {
+		return nil, &ApduError{Class: bacnet.ObjectError, Code: bacnet.UnknownObject}
+	}
+	v, ok := props[prop.Type]
+	switch {
+	case ok:
+	case prop.Type == bacnet.ObjectIdentifier:
+		v = id
+	case prop.Type == bacnet.ObjectTypeProp:
+		v = bacnet.PropertyValue{Type: bacnet.TypeEnumerated, Value: uint32(id.Type)}
+	case prop.Type == bacnet.ObjectList && id == d.id:
+		var list []interface{}
+		for _, o := range d.objectIDs() {
+			list = append(list, o)
+		}
+		v = list
+	default:
+		return nil, &ApduError{Class: bacnet.PropertyError, Code: bacnet.UnknownProperty}
+	}
+	if prop.ArrayIndex != nil {
+		array, ok := v.([]interface{})
+		switch {
+		case !ok:
+			return nil, &ApduError{Class: bacnet.PropertyError, Code: bacnet.PropertyIsNotAnArray}
+		case *prop.ArrayIndex == 0:
+			v = uint32(len(array))
+		case int(*prop.ArrayIndex) <= len(array):
+			v = array[*prop.ArrayIndex-1]
+		default:
+			return nil, &ApduError{Class: bacnet.PropertyError, Code: bacnet.InvalidArrayIndex}
+		}
+	}
+	values, ok := v.([]interface{})
+	if !ok {
+		values = []interface{}{v}
+	}
+	var b []byte
+	for _, v := range values {
+		if raw, ok := v.(RawValue); ok {
+			b = append(b, raw...)
+			continue
+		}
+		e := encoding.NewEncoder()
+		if pv, ok := v.(bacnet.PropertyValue); ok {
+			e.AppValue(pv)
+		} else {
+			e.AppData(v)
+		}
+		if e.Error() != nil {
+			return nil, &ApduError{Class: bacnet.PropertyError, Code: bacnet.InvalidDataType}
+		}
+		b = append(b, e.Bytes()...)
+	}
+	return b, nil
+}
+
+// objectIDs returns the identifiers of the objects of the device,
+// sorted. The lock must be held
+func (d *memDevice) objectIDs() []bacnet.ObjectID {
+	ids := make([]bacnet.ObjectID, 0, len(d.objects))
+	for id := range d.objects {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		a, b := ids[i], ids[j]
+		return a.Type < b.Type || (a.Type == b.Type && a.Instance < b.Instance)
+	})
+	return ids
+}
+
+// properties returns the properties of an object, for the reads of
+// all of them. The lock must be held
+func (d *memDevice) properties(id bacnet.ObjectID) []bacnet.PropertyIdentifier {
+	id = d.object(id)
+	types := []bacnet.PropertyType{bacnet.ObjectIdentifier, bacnet.ObjectTypeProp}
+	if id == d.id {
+		types = append(types, bacnet.ObjectList)
+	}
+	var others []bacnet.PropertyType
+	for p := range d.objects[id] {
+		if p != bacnet.ObjectIdentifier && p != bacnet.ObjectTypeProp && p != bacnet.ObjectList {
+			others = append(others, p)
+		}
+	}
+	sort.Slice(others, func(i, j int) bool { return others[i] < others[j] })
+	var props []bacnet.PropertyIdentifier
+	for _, p := range append(types, others...) {
+		props = append(props, bacnet.PropertyIdentifier{Type: p})
+	}
+	return props
+}
+
+// decodePropertyID decodes a property identifier and its optional
+// array index
+func decodePropertyID(dec *encoding.Decoder, tag byte) bacnet.PropertyIdentifier {
+	var val uint32
+	dec.ContextValue(tag, &val)
+	prop := bacnet.PropertyIdentifier{Type: bacnet.PropertyType(val)}
+	if dec.IsContextTag(tag + 1) {
+		prop.ArrayIndex = new(uint32)
+		dec.ContextValue(tag+1, prop.ArrayIndex)
+	}
+	return prop
+}
+
+func encodePropertyID(e *encoding.Encoder, tag byte, prop bacnet.PropertyIdentifier) {
+	e.ContextUnsigned(tag, uint32(prop.Type))
+	if prop.ArrayIndex != nil {
+		e.ContextUnsigned(tag+1, *prop.ArrayIndex)
+	}
+}
+
+// decodeRaw decodes application tagged data like ReadProperty.Data
+func decodeRaw(raw []byte) interface{} {
+	dec := encoding.NewDecoder(raw)
+	values := []interface{}{}
+	for dec.Len() > 0 {
+		var v interface{}
+		dec.AppData(&v)
+		if dec.Error() != nil {
+			return RawValue(raw)
+		}
+		values = append(values, v)
+	}
+	if len(values) == 1 {
+		return values[0]
+	}
+	return values
+}
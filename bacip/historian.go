@@ -0,0 +1,206 @@
+package bacip
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/REQUEA/bacnet"
+)
+
+// RingBuffer keeps the last samples of a point. It isn't safe for
+// concurrent use, see Historian
+type RingBuffer struct {
+	samples []Sample
+	//next is the index where the next sample is written
+	next  int
+	count int
+}
+
+// NewRingBuffer returns a buffer keeping the last capacity samples
+func NewRingBuffer(capacity int) *RingBuffer {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &RingBuffer{samples: make([]Sample, capacity)}
+}
+
+// Add adds a sample to the buffer, removing the oldest one if the
+// buffer is full
+func (r *RingBuffer) Add(s Sample) {
+	r.samples[r.next] = s
+	r.next = (r.next + 1) % len(r.samples)
+	if r.count < len(r.samples) {
+		r.count++
+	}
+}
+
+// Len returns the number of samples in the buffer
+func (r *RingBuffer) Len() int {
+	return r.count
+}
+
+// all returns the samples from the oldest added to the newest
+func (r *RingBuffer) all() []Sample {
+	result := make([]Sample, 0, r.count)
+	start := (r.next - r.count + len(r.samples)) % len(r.samples)
+	for i := 0; i < r.count; i++ {
+		result = append(result, r.samples[(start+i)%len(r.samples)])
+	}
+	return result
+}
+
+// Latest returns the most recent sample
+func (r *RingBuffer) Latest() (Sample, bool) {
+	var latest Sample
+	found := false
+	for _, s := range r.all() {
+		if !found || !s.Time.Before(latest.Time) {
+			latest = s
+			found = true
+		}
+	}
+	return latest, found
+}
+
+// Range returns the samples taken from from (included) to to
+// (excluded), sorted by time. Samples may be added in any order, for
+// instance when a gap is filled from a trend log.
+func (r *RingBuffer) Range(from, to time.Time) []Sample {
+	result := []Sample{}
+	for _, s := range r.all() {
+		if !s.Time.Before(from) && s.Time.Before(to) {
+			result = append(result, s)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Time.Before(result[j].Time)
+	})
+	return result
+}
+
+// Aggregate summarizes the numeric samples of a time range
+type Aggregate struct {
+	//Count is the number of samples used. Samples of bad quality
+	//and non numeric samples are ignored
+	Count int
+	Min   float64
+	Max   float64
+	Mean  float64
+}
+
+// Aggregate computes the aggregate of the samples taken from from
+// (included) to to (excluded). It returns false if no sample can be
+// used.
+func (r *RingBuffer) Aggregate(from, to time.Time) (Aggregate, bool) {
+	a := Aggregate{}
+	sum := 0.0
+	for _, s := range r.Range(from, to) {
+		if s.Quality == QualityBad || s.Quality == QualityCommFailure {
+			continue
+		}
+		v, ok := toFloat(s.Value)
+		if !ok {
+			continue
+		}
+		if a.Count == 0 || v < a.Min {
+			a.Min = v
+		}
+		if a.Count == 0 || v > a.Max {
+			a.Max = v
+		}
+		sum += v
+		a.Count++
+	}
+	if a.Count == 0 {
+		return a, false
+	}
+	a.Mean = sum / float64(a.Count)
+	return a, true
+}
+
+// PointRef identifies a point: a property of an object of a device
+type PointRef struct {
+	Device   bacnet.ObjectID
+	Object   bacnet.ObjectID
+	Property bacnet.PropertyType
+}
+
+// Historian keeps the last samples of many points in memory, with a
+// ring buffer per point. It's safe for concurrent use.
+type Historian struct {
+	sync.RWMutex
+	capacity int
+	points   map[PointRef]*RingBuffer
+}
+
+// NewHistorian returns a historian keeping the last capacity samples
+// of each point
+func NewHistorian(capacity int) *Historian {
+	return &Historian{capacity: capacity, points: map[PointRef]*RingBuffer{}}
+}
+
+// Record adds a sample of the point
+func (h *Historian) Record(p PointRef, s Sample) {
+	h.Lock()
+	defer h.Unlock()
+	r, ok := h.points[p]
+	if !ok {
+		r = NewRingBuffer(h.capacity)
+		h.points[p] = r
+	}
+	r.Add(s)
+}
+
+// Points returns the points having samples
+func (h *Historian) Points() []PointRef {
+	h.RLock()
+	defer h.RUnlock()
+	points := make([]PointRef, 0, len(h.points))
+	for p := range h.points {
+		points = append(points, p)
+	}
+	return points
+}
+
+// Latest returns the most recent sample of the point
+func (h *Historian) Latest(p PointRef) (Sample, bool) {
+	h.RLock()
+	defer h.RUnlock()
+	r, ok := h.points[p]
+	if !ok {
+		return Sample{}, false
+	}
+	return r.Latest()
+}
+
+// Range returns the samples of the point taken from from (included)
+// to to (excluded), sorted by time
+func (h *Historian) Range(p PointRef, from, to time.Time) []Sample {
+	h.RLock()
+	defer h.RUnlock()
+	r, ok := h.points[p]
+	if !ok {
+		return []Sample{}
+	}
+	return r.Range(from, to)
+}
+
+// Aggregate computes the aggregate of the samples of the point taken
+// from from (included) to to (excluded)
+func (h *Historian) Aggregate(p PointRef, from, to time.Time) (Aggregate, bool) {
+	h.RLock()
+	defer h.RUnlock()
+	r, ok := h.points[p]
+	if !ok {
+		return Aggregate{}, false
+	}
+	return r.Aggregate(from, to)
+}
+
+// Forget removes the samples of the point
+func (h *Historian) Forget(p PointRef) {
+	h.Lock()
+	defer h.Unlock()
+	delete(h.points, p)
+}
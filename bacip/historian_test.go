@@ -0,0 +1,63 @@
+package bacip
+
+import (
+	"testing"
+	"time"
+
+	"github.com/REQUEA/bacnet"
+
+	"github.com/matryer/is"
+)
+
+func TestRingBuffer(t *testing.T) {
+	is := is.New(t)
+	start := time.Now()
+	at := func(s int) time.Time { return start.Add(time.Duration(s) * time.Second) }
+	r := NewRingBuffer(3)
+	_, ok := r.Latest()
+	is.True(!ok)
+	for i := 0; i < 5; i++ {
+		r.Add(Sample{Time: at(i), Value: float32(i)})
+	}
+	is.Equal(r.Len(), 3)
+	latest, ok := r.Latest()
+	is.True(ok)
+	is.Equal(latest.Value, float32(4))
+	samples := r.Range(at(0), at(4))
+	is.Equal(len(samples), 2) //0 and 1 were overwritten, 4 is excluded
+	is.Equal(samples[0].Value, float32(2))
+
+	//Gap filling with an older sample
+	r.Add(Sample{Time: at(1), Value: float32(10), Quality: QualityGood})
+	samples = r.Range(at(0), at(10))
+	is.Equal(samples[0].Value, float32(10))
+	latest, _ = r.Latest()
+	is.Equal(latest.Value, float32(4))
+
+	r.Add(Sample{Time: at(5), Value: float32(100), Quality: QualityBad})
+	a, ok := r.Aggregate(at(0), at(10))
+	is.True(ok)
+	is.Equal(a, Aggregate{Count: 2, Min: 4, Max: 10, Mean: 7})
+	_, ok = r.Aggregate(at(20), at(30))
+	is.True(!ok)
+}
+
+func TestHistorian(t *testing.T) {
+	is := is.New(t)
+	h := NewHistorian(10)
+	p := PointRef{
+		Device:   bacnet.ObjectID{Type: bacnet.BacnetDevice, Instance: 1},
+		Object:   bacnet.ObjectID{Type: bacnet.AnalogInput, Instance: 1},
+		Property: bacnet.PresentValue,
+	}
+	now := time.Now()
+	h.Record(p, Sample{Time: now, Value: float32(1)})
+	is.Equal(h.Points(), []PointRef{p})
+	s, ok := h.Latest(p)
+	is.True(ok)
+	is.Equal(s.Value, float32(1))
+	is.Equal(len(h.Range(p, now, now.Add(time.Second))), 1)
+	h.Forget(p)
+	_, ok = h.Latest(p)
+	is.True(!ok)
+}
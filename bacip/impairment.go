@@ -0,0 +1,192 @@
+package bacip
+
+import (
+	"math/rand"
+	"net"
+	"sync"
+	"time"
+)
+
+// Impairment describes the network conditions simulated by an
+// ImpairedTransport in one direction. Probabilities go from 0 to 1
+type Impairment struct {
+	//Latency is added to every datagram
+	Latency time.Duration
+	//Jitter is the maximum random delay added to the latency
+	Jitter time.Duration
+	//Loss is the probability that a datagram is dropped
+	Loss float64
+	//Duplication is the probability that a datagram is delivered
+	//twice
+	Duplication float64
+	//Reordering is the probability that a datagram is held back by
+	//ReorderDelay, so that it's delivered after the following ones
+	Reordering   float64
+	ReorderDelay time.Duration
+}
+
+// ImpairedTransport wraps a transport to inject latency, jitter,
+// packet loss, duplication and reordering. It's meant to test the
+// behavior of the client on bad networks:
+//
+//	c, err := NewClientWithTransport("192.168.1.2/24", func(port int) (Transport, error) {
+//		conn, err := net.ListenUDP("udp4", &net.UDPAddr{Port: port})
+//		if err != nil {
+//			return nil, err
+//		}
+//		return NewImpairedTransport(conn, Impairment{Loss: 0.1}, Impairment{Latency: 50 * time.Millisecond}, 1), nil
+//	}, 0, NoOpLogger{})
+type ImpairedTransport struct {
+	inner   Transport
+	send    Impairment
+	receive Impairment
+
+	randMutex sync.Mutex
+	rand      *rand.Rand
+
+	received  chan datagram
+	closeOnce sync.Once
+	closed    chan struct{}
+	wg        sync.WaitGroup
+}
+
+type datagram struct {
+	data []byte
+	addr *net.UDPAddr
+}
+
+// NewImpairedTransport wraps inner. send applies to the written
+// datagrams and receive to the read ones. seed makes the random
+// decisions reproducible
+func NewImpairedTransport(inner Transport, send, receive Impairment, seed int64) *ImpairedTransport {
+	t := &ImpairedTransport{
+		inner:    inner,
+		send:     send,
+		receive:  receive,
+		rand:     rand.New(rand.NewSource(seed)), //nolint: gosec
+		received: make(chan datagram, 64),
+		closed:   make(chan struct{}),
+	}
+	t.wg.Add(1)
+	go t.readLoop()
+	return t
+}
+
+func (t *ImpairedTransport) readLoop() {
+	defer t.wg.Done()
+	for {
+		b := make([]byte, 2048)
+		n, addr, err := t.inner.ReadFromUDP(b)
+		if err != nil {
+			select {
+			case <-t.closed:
+				return
+			default:
+				continue
+			}
+		}
+		d := datagram{data: b[:n], addr: addr}
+		deliver := func() {
+			select {
+			case t.received <- d:
+			case <-t.closed:
+			}
+		}
+		for _, delay := range t.delays(t.receive) {
+			if delay == 0 {
+				deliver()
+			} else {
+				t.later(delay, deliver)
+			}
+		}
+	}
+}
+
+// delays returns the delays after which a datagram is delivered: none
+// if it's lost, two if it's duplicated
+func (t *ImpairedTransport) delays(imp Impairment) []time.Duration {
+	t.randMutex.Lock()
+	defer t.randMutex.Unlock()
+	if t.rand.Float64() < imp.Loss {
+		return nil
+	}
+	copies := 1
+	if t.rand.Float64() < imp.Duplication {
+		copies = 2
+	}
+	delays := make([]time.Duration, copies)
+	for i := range delays {
+		delays[i] = imp.Latency
+		if imp.Jitter > 0 {
+			delays[i] += time.Duration(t.rand.Int63n(int64(imp.Jitter)))
+		}
+		if t.rand.Float64() < imp.Reordering {
+			delays[i] += imp.ReorderDelay
+		}
+	}
+	return delays
+}
+
+// later calls fn after the delay, unless the transport is closed
+// before
+func (t *ImpairedTransport) later(delay time.Duration, fn func()) {
+	time.AfterFunc(delay, func() {
+		select {
+		case <-t.closed:
+		default:
+			fn()
+		}
+	})
+}
+
+// ReadFromUDP returns the next datagram received, once impaired
+func (t *ImpairedTransport) ReadFromUDP(b []byte) (int, *net.UDPAddr, error) {
+	select {
+	case d := <-t.received:
+		return copy(b, d.data), d.addr, nil
+	case <-t.closed:
+		return 0, nil, net.ErrClosed
+	}
+}
+
+// WriteToUDP sends the datagram with the send impairment. Dropped
+// datagrams are reported as sent, and errors of delayed datagrams
+// are ignored, like on a real network
+func (t *ImpairedTransport) WriteToUDP(b []byte, addr *net.UDPAddr) (int, error) {
+	select {
+	case <-t.closed:
+		return 0, net.ErrClosed
+	default:
+	}
+	data := make([]byte, len(b))
+	copy(data, b)
+	for _, delay := range t.delays(t.send) {
+		if delay == 0 {
+			_, err := t.inner.WriteToUDP(data, addr)
+			if err != nil {
+				return 0, err
+			}
+			continue
+		}
+		t.later(delay, func() {
+			_, _ = t.inner.WriteToUDP(data, addr)
+		})
+	}
+	return len(b), nil
+}
+
+func (t *ImpairedTransport) LocalAddr() net.Addr {
+	return t.inner.LocalAddr()
+}
+
+// Close closes the wrapped transport. Datagrams not yet delivered are
+// dropped
+func (t *ImpairedTransport) Close() error {
+	err := net.ErrClosed
+	t.closeOnce.Do(func() {
+		close(t.closed)
+		err = t.inner.Close()
+		t.wg.Wait()
+	})
+	return err
+}
@@ -0,0 +1,60 @@
+package bacip
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestImpairedTransportSend(t *testing.T) {
+	is := is.New(t)
+	addr := &net.UDPAddr{IP: net.IPv4(10, 0, 0, 1), Port: DefaultUDPPort}
+
+	inner := newMemTransport()
+	tr := NewImpairedTransport(inner, Impairment{}, Impairment{}, 1)
+	n, err := tr.WriteToUDP([]byte{1, 2}, addr)
+	is.NoErr(err)
+	is.Equal(n, 2)
+	is.Equal(inner.count(), 1)
+	is.NoErr(tr.Close())
+
+	inner = newMemTransport()
+	tr = NewImpairedTransport(inner, Impairment{Loss: 1}, Impairment{}, 1)
+	n, err = tr.WriteToUDP([]byte{1, 2}, addr)
+	is.NoErr(err)
+	is.Equal(n, 2) //lost datagrams are reported as sent
+	is.Equal(inner.count(), 0)
+	is.NoErr(tr.Close())
+
+	inner = newMemTransport()
+	tr = NewImpairedTransport(inner, Impairment{Duplication: 1}, Impairment{}, 1)
+	_, err = tr.WriteToUDP([]byte{1, 2}, addr)
+	is.NoErr(err)
+	is.Equal(inner.count(), 2)
+	is.NoErr(tr.Close())
+}
+
+func TestImpairedTransportReceive(t *testing.T) {
+	is := is.New(t)
+	inner := newMemTransport()
+	latency := 20 * time.Millisecond
+	tr := NewImpairedTransport(inner, Impairment{}, Impairment{Latency: latency}, 1)
+	start := time.Now()
+	inner.in <- datagram{data: []byte{1, 2, 3}}
+	b := make([]byte, 10)
+	n, _, err := tr.ReadFromUDP(b)
+	is.NoErr(err)
+	is.Equal(b[:n], []byte{1, 2, 3})
+	is.True(time.Since(start) >= latency)
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		_ = tr.Close()
+	}()
+	_, _, err = tr.ReadFromUDP(b)
+	is.True(errors.Is(err, net.ErrClosed))
+	is.True(errors.Is(tr.Close(), net.ErrClosed))
+}
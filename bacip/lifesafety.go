@@ -0,0 +1,120 @@
+package bacip
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/REQUEA/bacnet"
+	"github.com/REQUEA/bacnet/internal/encoding"
+)
+
+// LifeSafetyStatus contains the state properties of a Life Safety
+// Point or Zone object
+type LifeSafetyStatus struct {
+	PresentValue      bacnet.LifeSafetyState
+	TrackingValue     bacnet.LifeSafetyState
+	Mode              bacnet.LifeSafetyMode
+	OperationExpected bacnet.LifeSafetyOperation
+}
+
+func isLifeSafetyObject(id bacnet.ObjectID) bool {
+	return id.Type == bacnet.LifeSafetyPoint || id.Type == bacnet.LifeSafetyZone
+}
+
+// ReadLifeSafetyStatus reads the state, the mode and the expected
+// operation of a Life Safety Point or Zone object
+func (c *Client) ReadLifeSafetyStatus(ctx context.Context, device bacnet.Device, object bacnet.ObjectID) (LifeSafetyStatus, error) {
+	s := LifeSafetyStatus{}
+	if !isLifeSafetyObject(object) {
+		return s, fmt.Errorf("%v isn't a life safety object", object)
+	}
+	props := []struct {
+		prop bacnet.PropertyType
+		v    *uint32
+	}{
+		{bacnet.PresentValue, (*uint32)(&s.PresentValue)},
+		{bacnet.TrackingValue, (*uint32)(&s.TrackingValue)},
+		{bacnet.Mode, (*uint32)(&s.Mode)},
+		{bacnet.OperationExpected, (*uint32)(&s.OperationExpected)},
+	}
+	for _, p := range props {
+		d, err := c.ReadProperty(ctx, device, ReadProperty{
+			ObjectID: object,
+			Property: bacnet.PropertyIdentifier{Type: p.prop},
+		})
+		if err != nil {
+			return s, fmt.Errorf("read %v: %w", p.prop, err)
+		}
+		v, ok := d.(uint32)
+		if !ok {
+			return s, fmt.Errorf("read %v: unexpected type %T", p.prop, d)
+		}
+		*p.v = v
+	}
+	return s, nil
+}
+
+// WriteLifeSafetyMode writes the mode of a Life Safety Point or Zone
+// object. The priority is ignored by devices where the mode isn't
+// commandable
+func (c *Client) WriteLifeSafetyMode(ctx context.Context, device bacnet.Device, object bacnet.ObjectID, mode bacnet.LifeSafetyMode, priority bacnet.PriorityList) error {
+	if !isLifeSafetyObject(object) {
+		return fmt.Errorf("%v isn't a life safety object", object)
+	}
+	return c.WriteProperty(ctx, device, WriteProperty{
+		ObjectID:      object,
+		Property:      bacnet.PropertyIdentifier{Type: bacnet.Mode},
+		PropertyValue: bacnet.PropertyValue{Type: bacnet.TypeEnumerated, Value: uint32(mode)},
+		Priority:      priority,
+	})
+}
+
+// LifeSafetyOperationRequest asks a device to silence or reset life
+// safety objects
+type LifeSafetyOperationRequest struct {
+	ProcessID        uint32
+	RequestingSource string
+	Request          bacnet.LifeSafetyOperation
+	//ObjectID is nil to apply the operation to all the life safety
+	//objects of the device
+	ObjectID *bacnet.ObjectID
+}
+
+func (r LifeSafetyOperationRequest) MarshalBinary() ([]byte, error) {
+	encoder := encoding.NewEncoder()
+	encoder.ContextUnsigned(0, r.ProcessID)
+	encoder.ContextString(1, r.RequestingSource)
+	encoder.ContextUnsigned(2, uint32(r.Request))
+	if r.ObjectID != nil {
+		encoder.ContextObjectID(3, *r.ObjectID)
+	}
+	return encoder.Bytes(), encoder.Error()
+}
+
+func (r *LifeSafetyOperationRequest) UnmarshalBinary(data []byte) error {
+	decoder := encoding.NewDecoder(data)
+	decoder.ContextValue(0, &r.ProcessID)
+	decoder.ContextString(1, &r.RequestingSource)
+	var val uint32
+	decoder.ContextValue(2, &val)
+	r.Request = bacnet.LifeSafetyOperation(val)
+	r.ObjectID = nil
+	if decoder.IsContextTag(3) {
+		r.ObjectID = new(bacnet.ObjectID)
+		decoder.ContextObjectID(3, r.ObjectID)
+	}
+	return decoder.Error()
+}
+
+// LifeSafetyOperation sends a LifeSafetyOperation request to the device
+func (c *Client) LifeSafetyOperation(ctx context.Context, device bacnet.Device, req LifeSafetyOperationRequest) error {
+	apdu, err := c.confirmedRequest(ctx, device, ServiceConfirmedLifeSafetyOperation, &req)
+	if err != nil {
+		return err
+	}
+	if apdu.DataType == SimpleAck {
+		return nil
+	}
+	return errors.New("invalid answer")
+}
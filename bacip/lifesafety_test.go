@@ -0,0 +1,27 @@
+package bacip
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/REQUEA/bacnet"
+
+	"github.com/matryer/is"
+)
+
+func TestLifeSafetyOperationCoherency(t *testing.T) {
+	is := is.New(t)
+	zone := bacnet.ObjectID{Type: bacnet.LifeSafetyZone, Instance: 1}
+	req := LifeSafetyOperationRequest{
+		ProcessID:        1,
+		RequestingSource: "op",
+		Request:          bacnet.LifeSafetyOperationSilence,
+		ObjectID:         &zone,
+	}
+	b, err := req.MarshalBinary()
+	is.NoErr(err)
+	is.Equal(hex.EncodeToString(b), "09011b006f7029013c05800001")
+	req2 := LifeSafetyOperationRequest{}
+	is.NoErr(req2.UnmarshalBinary(b))
+	is.Equal(req2, req)
+}
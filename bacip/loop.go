@@ -0,0 +1,224 @@
+package bacip
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/REQUEA/bacnet"
+	"github.com/REQUEA/bacnet/internal/encoding"
+)
+
+// LoopAction tells if the output of a loop increases (direct) or
+// decreases (reverse) when the controlled value is above the setpoint
+type LoopAction uint32
+
+const (
+	LoopDirect  LoopAction = 0
+	LoopReverse LoopAction = 1
+)
+
+func (a LoopAction) String() string {
+	switch a {
+	case LoopDirect:
+		return "direct"
+	case LoopReverse:
+		return "reverse"
+	default:
+		return fmt.Sprintf("LoopAction(%d)", uint32(a))
+	}
+}
+
+// SetpointReference is the property used as setpoint by a loop. The
+// loop uses its own Setpoint property if Reference is nil
+type SetpointReference struct {
+	//Reference.Device must be nil, the setpoint is in the same
+	//device
+	Reference *DeviceObjectPropertyReference
+}
+
+func (r SetpointReference) MarshalBinary() ([]byte, error) {
+	encoder := encoding.NewEncoder()
+	if r.Reference != nil {
+		encoder.OpeningTag(0)
+		r.Reference.encode(&encoder)
+		encoder.ClosingTag(0)
+	}
+	return encoder.Bytes(), encoder.Error()
+}
+
+func (r *SetpointReference) UnmarshalBinary(data []byte) error {
+	r.Reference = nil
+	if len(data) == 0 {
+		return nil
+	}
+	decoder := encoding.NewDecoder(data)
+	r.Reference = &DeviceObjectPropertyReference{}
+	decoder.OpeningTag(0)
+	r.Reference.decode(decoder)
+	decoder.ClosingTag(0)
+	return decoder.Error()
+}
+
+// LoopTuning contains the tuning properties of a Loop object. Nil
+// fields are left unchanged on the device
+type LoopTuning struct {
+	ProportionalConstant *float32
+	IntegralConstant     *float32
+	DerivativeConstant   *float32
+	Bias                 *float32
+	Setpoint             *float32
+	SetpointReference    *SetpointReference
+	Action               *LoopAction
+}
+
+// Validate checks the values before sending them to a device
+func (t LoopTuning) Validate() error {
+	floats := []struct {
+		name string
+		v    *float32
+	}{
+		{"proportional constant", t.ProportionalConstant},
+		{"integral constant", t.IntegralConstant},
+		{"derivative constant", t.DerivativeConstant},
+		{"bias", t.Bias},
+		{"setpoint", t.Setpoint},
+	}
+	for _, f := range floats {
+		if f.v != nil && (math.IsNaN(float64(*f.v)) || math.IsInf(float64(*f.v), 0)) {
+			return fmt.Errorf("invalid %s: %v", f.name, *f.v)
+		}
+	}
+	if t.SetpointReference != nil && t.SetpointReference.Reference != nil {
+		if t.SetpointReference.Reference.Device != nil {
+			return errors.New("invalid setpoint reference: must be in the same device")
+		}
+	}
+	if t.Action != nil && *t.Action != LoopDirect && *t.Action != LoopReverse {
+		return fmt.Errorf("invalid loop action %v", *t.Action)
+	}
+	return nil
+}
+
+// ReadLoopTuning reads the tuning properties of a Loop object
+func (c *Client) ReadLoopTuning(ctx context.Context, device bacnet.Device, loop bacnet.ObjectID) (LoopTuning, error) {
+	t := LoopTuning{}
+	readReal := func(prop bacnet.PropertyType) (*float32, error) {
+		d, err := c.ReadProperty(ctx, device, ReadProperty{
+			ObjectID: loop,
+			Property: bacnet.PropertyIdentifier{Type: prop},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("read %v: %w", prop, err)
+		}
+		v, ok := d.(float32)
+		if !ok {
+			return nil, fmt.Errorf("read %v: unexpected type %T", prop, d)
+		}
+		return &v, nil
+	}
+	var err error
+	reals := []struct {
+		prop bacnet.PropertyType
+		v    **float32
+	}{
+		{bacnet.ProportionalAnt, &t.ProportionalConstant},
+		{bacnet.IntegralAnt, &t.IntegralConstant},
+		{bacnet.DerivativeAnt, &t.DerivativeConstant},
+		{bacnet.Bias, &t.Bias},
+		{bacnet.Setpoint, &t.Setpoint},
+	}
+	for _, r := range reals {
+		*r.v, err = readReal(r.prop)
+		if err != nil {
+			return t, err
+		}
+	}
+	raw, err := c.readRaw(ctx, device, ReadProperty{
+		ObjectID: loop,
+		Property: bacnet.PropertyIdentifier{Type: bacnet.SetpointReference},
+	})
+	if err != nil {
+		return t, fmt.Errorf("read setpoint reference: %w", err)
+	}
+	t.SetpointReference = &SetpointReference{}
+	err = t.SetpointReference.UnmarshalBinary(raw)
+	if err != nil {
+		return t, fmt.Errorf("decode setpoint reference: %w", err)
+	}
+	d, err := c.ReadProperty(ctx, device, ReadProperty{
+		ObjectID: loop,
+		Property: bacnet.PropertyIdentifier{Type: bacnet.Action},
+	})
+	if err != nil {
+		return t, fmt.Errorf("read action: %w", err)
+	}
+	v, ok := d.(uint32)
+	if !ok {
+		return t, fmt.Errorf("read action: unexpected type %T", d)
+	}
+	t.Action = new(LoopAction)
+	*t.Action = LoopAction(v)
+	return t, nil
+}
+
+// WriteLoopTuning writes the non nil fields of t to the Loop
+// object. Devices usually refuse to change the setpoint when a
+// setpoint reference is configured.
+func (c *Client) WriteLoopTuning(ctx context.Context, device bacnet.Device, loop bacnet.ObjectID, t LoopTuning) error {
+	if loop.Type != bacnet.Loop {
+		return fmt.Errorf("%v isn't a loop", loop)
+	}
+	err := t.Validate()
+	if err != nil {
+		return err
+	}
+	write := func(prop bacnet.PropertyType, value bacnet.PropertyValue) error {
+		err := c.WriteProperty(ctx, device, WriteProperty{
+			ObjectID:      loop,
+			Property:      bacnet.PropertyIdentifier{Type: prop},
+			PropertyValue: value,
+		})
+		if err != nil {
+			return fmt.Errorf("write %v: %w", prop, err)
+		}
+		return nil
+	}
+	reals := []struct {
+		prop bacnet.PropertyType
+		v    *float32
+	}{
+		{bacnet.ProportionalAnt, t.ProportionalConstant},
+		{bacnet.IntegralAnt, t.IntegralConstant},
+		{bacnet.DerivativeAnt, t.DerivativeConstant},
+		{bacnet.Bias, t.Bias},
+	}
+	for _, r := range reals {
+		if r.v == nil {
+			continue
+		}
+		err := write(r.prop, bacnet.PropertyValue{Value: *r.v})
+		if err != nil {
+			return err
+		}
+	}
+	if t.Action != nil {
+		err := write(bacnet.Action, bacnet.PropertyValue{Type: bacnet.TypeEnumerated, Value: uint32(*t.Action)})
+		if err != nil {
+			return err
+		}
+	}
+	//The reference is written before the setpoint, so that the
+	//setpoint can be written when the reference is removed
+	if t.SetpointReference != nil {
+		err := write(bacnet.SetpointReference, bacnet.PropertyValue{Value: *t.SetpointReference})
+		if err != nil {
+			return err
+		}
+	}
+	if t.Setpoint != nil {
+		return write(bacnet.Setpoint, bacnet.PropertyValue{Value: *t.Setpoint})
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package bacip
+
+import (
+	"encoding/hex"
+	"math"
+	"testing"
+
+	"github.com/REQUEA/bacnet"
+
+	"github.com/matryer/is"
+)
+
+func TestSetpointReferenceCoherency(t *testing.T) {
+	is := is.New(t)
+	ref := SetpointReference{Reference: &DeviceObjectPropertyReference{
+		ObjectID: bacnet.ObjectID{Type: bacnet.AnalogValue, Instance: 1},
+		Property: bacnet.PropertyIdentifier{Type: bacnet.PresentValue},
+	}}
+	b, err := ref.MarshalBinary()
+	is.NoErr(err)
+	is.Equal(hex.EncodeToString(b), "0e0c0080000119550f")
+	ref2 := SetpointReference{}
+	is.NoErr(ref2.UnmarshalBinary(b))
+	is.Equal(ref2, ref)
+
+	b, err = SetpointReference{}.MarshalBinary()
+	is.NoErr(err)
+	is.Equal(len(b), 0)
+	is.NoErr(ref2.UnmarshalBinary(b))
+	is.True(ref2.Reference == nil)
+}
+
+func TestLoopTuningValidate(t *testing.T) {
+	is := is.New(t)
+	p := float32(2.5)
+	is.NoErr(LoopTuning{ProportionalConstant: &p}.Validate())
+	nan := float32(math.NaN())
+	is.True(LoopTuning{IntegralConstant: &nan}.Validate() != nil)
+	action := LoopAction(2)
+	is.True(LoopTuning{Action: &action}.Validate() != nil)
+	dev := bacnet.ObjectID{Type: bacnet.BacnetDevice, Instance: 1}
+	ref := SetpointReference{Reference: &DeviceObjectPropertyReference{Device: &dev}}
+	is.True(LoopTuning{SetpointReference: &ref}.Validate() != nil)
+}
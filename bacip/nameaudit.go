@@ -0,0 +1,186 @@
+package bacip
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/REQUEA/bacnet"
+)
+
+// NameIssueKind is a naming problem found by AuditNames
+type NameIssueKind byte
+
+const (
+	//NameEmpty is an object without name
+	NameEmpty NameIssueKind = iota
+	//NameUnreadable is an object whose name couldn't be read
+	NameUnreadable
+	//DuplicateDeviceName is a name shared by several devices, while
+	//device names must be unique on the whole network
+	DuplicateDeviceName
+	//DuplicateObjectName is a name shared by several objects of the
+	//same device
+	DuplicateObjectName
+)
+
+func (k NameIssueKind) String() string {
+	switch k {
+	case NameEmpty:
+		return "empty name"
+	case NameUnreadable:
+		return "unreadable name"
+	case DuplicateDeviceName:
+		return "duplicate device name"
+	case DuplicateObjectName:
+		return "duplicate object name"
+	default:
+		return fmt.Sprintf("NameIssueKind(%d)", k)
+	}
+}
+
+// NamedObject is an object of a device and its name
+type NamedObject struct {
+	Device bacnet.ObjectID
+	Object bacnet.ObjectID
+	Name   string
+}
+
+// NameIssue is a naming problem and the objects involved
+type NameIssue struct {
+	Kind    NameIssueKind
+	Name    string
+	Objects []NamedObject
+}
+
+func (i NameIssue) String() string {
+	objects := make([]string, len(i.Objects))
+	for j, o := range i.Objects {
+		objects[j] = fmt.Sprintf("%v/%v", o.Device, o.Object)
+	}
+	return fmt.Sprintf("%v %q: %s", i.Kind, i.Name, strings.Join(objects, ", "))
+}
+
+// NameAudit is the result of AuditNames
+type NameAudit struct {
+	//Issues are sorted by kind, then by name
+	Issues []NameIssue
+	//Errors are the devices whose object list couldn't be read
+	Errors map[bacnet.ObjectID]error
+}
+
+// AuditNames reads the names of the devices and of all their objects,
+// and reports the empty names, the device names used by several
+// devices and the object names used by several objects of a device.
+// Front-ends identifying points by name require these issues to be
+// fixed before they are connected. The devices are read concurrently
+func (c *Client) AuditNames(ctx context.Context, devices []bacnet.Device) NameAudit {
+	names := make([][]NamedObject, len(devices))
+	errs := make([]error, len(devices))
+	unreadable := make([][]NamedObject, len(devices))
+	slots := make(chan struct{}, maxCountConcurrency)
+	var wg sync.WaitGroup
+	for i, d := range devices {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(i int, d bacnet.Device) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			names[i], unreadable[i], errs[i] = c.readNames(ctx, d)
+		}(i, d)
+	}
+	wg.Wait()
+
+	audit := NameAudit{Errors: map[bacnet.ObjectID]error{}}
+	var deviceNames []NamedObject
+	for i, d := range devices {
+		if errs[i] != nil {
+			audit.Errors[d.ID] = errs[i]
+			continue
+		}
+		for _, o := range unreadable[i] {
+			audit.Issues = append(audit.Issues, NameIssue{Kind: NameUnreadable, Objects: []NamedObject{o}})
+		}
+		for _, o := range names[i] {
+			if o.Name == "" {
+				audit.Issues = append(audit.Issues, NameIssue{Kind: NameEmpty, Objects: []NamedObject{o}})
+			}
+			if o.Object == d.ID {
+				deviceNames = append(deviceNames, o)
+			}
+		}
+		audit.Issues = append(audit.Issues, duplicateNames(DuplicateObjectName, names[i])...)
+	}
+	audit.Issues = append(audit.Issues, duplicateNames(DuplicateDeviceName, deviceNames)...)
+	sort.SliceStable(audit.Issues, func(i, j int) bool {
+		a, b := audit.Issues[i], audit.Issues[j]
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		return a.Name < b.Name
+	})
+	return audit
+}
+
+// duplicateNames returns an issue of the kind for every non-empty name
+// shared by several objects
+func duplicateNames(kind NameIssueKind, objects []NamedObject) []NameIssue {
+	byName := map[string][]NamedObject{}
+	for _, o := range objects {
+		if o.Name != "" {
+			byName[o.Name] = append(byName[o.Name], o)
+		}
+	}
+	var issues []NameIssue
+	for name, objects := range byName {
+		if len(objects) > 1 {
+			issues = append(issues, NameIssue{Kind: kind, Name: name, Objects: objects})
+		}
+	}
+	return issues
+}
+
+// readNames reads the names of the objects of the device, including
+// the device object. The objects whose name couldn't be read are
+// returned separately
+func (c *Client) readNames(ctx context.Context, device bacnet.Device) ([]NamedObject, []NamedObject, error) {
+	ids, err := c.ReadObjectList(ctx, device)
+	if err != nil {
+		return nil, nil, err
+	}
+	specs := make([]ReadAccessSpecification, 0, len(ids)+1)
+	hasDevice := false
+	for _, id := range ids {
+		hasDevice = hasDevice || id == device.ID
+		specs = append(specs, ReadAccessSpecification{
+			ObjectID:   id,
+			Properties: []bacnet.PropertyIdentifier{{Type: bacnet.ObjectName}},
+		})
+	}
+	if !hasDevice {
+		specs = append([]ReadAccessSpecification{{
+			ObjectID:   device.ID,
+			Properties: []bacnet.PropertyIdentifier{{Type: bacnet.ObjectName}},
+		}}, specs...)
+	}
+	results, err := c.ReadPropertyMultiple(ctx, device, specs)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read object names: %w", err)
+	}
+	var names, unreadable []NamedObject
+	for _, r := range results {
+		for _, res := range r.Results {
+			o := NamedObject{Device: device.ID, Object: r.ObjectID}
+			name, ok := res.Value.(string)
+			if res.Error != nil || (!ok && res.Value != nil) {
+				unreadable = append(unreadable, o)
+				continue
+			}
+			o.Name = name
+			names = append(names, o)
+		}
+	}
+	return names, unreadable, nil
+}
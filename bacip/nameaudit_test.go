@@ -0,0 +1,44 @@
+package bacip
+
+import (
+	"context"
+	"testing"
+
+	"github.com/REQUEA/bacnet"
+	"github.com/matryer/is"
+)
+
+func TestAuditNames(t *testing.T) {
+	is := is.New(t)
+	ai1 := bacnet.ObjectID{Type: bacnet.AnalogInput, Instance: 1}
+	ahu1 := newMemDevice(10)
+	ahu1.set(ahu1.id, bacnet.ObjectName, "AHU")
+	ahu1.set(ai1, bacnet.ObjectName, "SupplyTemp")
+	ahu2 := newMemDevice(20)
+	ahu2.set(ahu2.id, bacnet.ObjectName, "AHU")
+	ahu2.set(ai1, bacnet.ObjectName, "SupplyTemp")
+	boiler := newMemDevice(30)
+	boiler.set(boiler.id, bacnet.ObjectName, "Boiler")
+	boiler.set(ai1, bacnet.ObjectName, "Temp")
+	boiler.set(bacnet.ObjectID{Type: bacnet.AnalogInput, Instance: 2}, bacnet.ObjectName, "Temp")
+	boiler.set(bacnet.ObjectID{Type: bacnet.AnalogValue, Instance: 1}, bacnet.ObjectName, "")
+	//An object without name
+	boiler.set(bacnet.ObjectID{Type: bacnet.AnalogValue, Instance: 2}, bacnet.ObjectName, nil)
+	m := newMemTransport()
+	m.respond = serveDevices(t, ahu1, ahu2, boiler)
+	c := newMemClient(t, m)
+
+	audit := c.AuditNames(context.Background(), []bacnet.Device{ahu1.device(), ahu2.device(), boiler.device()})
+	is.Equal(len(audit.Errors), 0)
+	var kinds []NameIssueKind
+	for _, issue := range audit.Issues {
+		kinds = append(kinds, issue.Kind)
+	}
+	is.Equal(kinds, []NameIssueKind{NameEmpty, NameUnreadable, DuplicateDeviceName, DuplicateObjectName})
+	is.Equal(audit.Issues[0].Objects[0].Object, bacnet.ObjectID{Type: bacnet.AnalogValue, Instance: 1})
+	is.Equal(audit.Issues[2].Name, "AHU")
+	is.Equal(len(audit.Issues[2].Objects), 2)
+	//Objects of different devices may share a name
+	is.Equal(audit.Issues[3].Name, "Temp")
+	is.Equal(audit.Issues[3].Objects[0].Device, boiler.id)
+}
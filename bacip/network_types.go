@@ -5,6 +5,7 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"net"
 
 	"github.com/REQUEA/bacnet"
 )
@@ -96,17 +97,24 @@ func (npdu NPDU) MarshalBinary() ([]byte, error) {
 }
 
 func (npdu *NPDU) UnmarshallBinary(data []byte) error {
+	_, err := npdu.unmarshal(data)
+	return err
+}
+
+// unmarshal decodes the NPDU, and returns the length of the headers of
+// the NPDU and its APDU: the offset of the service data
+func (npdu *NPDU) unmarshal(data []byte) (int, error) {
 	buf := bytes.NewBuffer(data)
 	err := binary.Read(buf, binary.BigEndian, &npdu.Version)
 	if err != nil {
-		return fmt.Errorf("read NPDU version: %w", err)
+		return 0, fmt.Errorf("read NPDU version: %w", err)
 	}
 	if npdu.Version != Version1 {
-		return fmt.Errorf("invalid NPDU version %d", npdu.Version)
+		return 0, fmt.Errorf("invalid NPDU version %d", npdu.Version)
 	}
 	control, err := buf.ReadByte()
 	if err != nil {
-		return fmt.Errorf("read NPDU control byte:  %w", err)
+		return 0, fmt.Errorf("read NPDU control byte:  %w", err)
 	}
 	if control&(1<<7) > 0 {
 		npdu.IsNetworkLayerMessage = true
@@ -120,17 +128,17 @@ func (npdu *NPDU) UnmarshallBinary(data []byte) error {
 		npdu.Destination = &bacnet.Address{}
 		err := binary.Read(buf, binary.BigEndian, &npdu.Destination.Net)
 		if err != nil {
-			return fmt.Errorf("read NPDU dest Address.Net: %w", err)
+			return 0, fmt.Errorf("read NPDU dest Address.Net: %w", err)
 		}
 		var length byte
 		err = binary.Read(buf, binary.BigEndian, &length)
 		if err != nil {
-			return fmt.Errorf("read NPDU dest Address.Len: %w", err)
+			return 0, fmt.Errorf("read NPDU dest Address.Len: %w", err)
 		}
 		npdu.Destination.Adr = make([]byte, int(length))
 		err = binary.Read(buf, binary.BigEndian, &npdu.Destination.Adr)
 		if err != nil {
-			return fmt.Errorf("read NPDU dest Address.Net: %w", err)
+			return 0, fmt.Errorf("read NPDU dest Address.Net: %w", err)
 		}
 	}
 
@@ -138,44 +146,45 @@ func (npdu *NPDU) UnmarshallBinary(data []byte) error {
 		npdu.Source = &bacnet.Address{}
 		err := binary.Read(buf, binary.BigEndian, &npdu.Source.Net)
 		if err != nil {
-			return fmt.Errorf("read NPDU src Address.Net: %w", err)
+			return 0, fmt.Errorf("read NPDU src Address.Net: %w", err)
 		}
 		var length byte
 		err = binary.Read(buf, binary.BigEndian, &length)
 		if err != nil {
-			return fmt.Errorf("read NPDU src Address.Len: %w", err)
+			return 0, fmt.Errorf("read NPDU src Address.Len: %w", err)
 		}
 		npdu.Source.Adr = make([]byte, int(length))
 		err = binary.Read(buf, binary.BigEndian, &npdu.Source.Adr)
 		if err != nil {
-			return fmt.Errorf("read NPDU src Address.Net: %w", err)
+			return 0, fmt.Errorf("read NPDU src Address.Net: %w", err)
 		}
 	}
 
 	if npdu.Destination != nil {
 		err := binary.Read(buf, binary.BigEndian, &npdu.HopCount)
 		if err != nil {
-			return fmt.Errorf("read NPDU HopCount: %w", err)
+			return 0, fmt.Errorf("read NPDU HopCount: %w", err)
 		}
 	}
 
 	if npdu.IsNetworkLayerMessage {
 		err := binary.Read(buf, binary.BigEndian, &npdu.NetworkMessageType)
 		if err != nil {
-			return fmt.Errorf("read NPDU NetworkMessageType: %w", err)
+			return 0, fmt.Errorf("read NPDU NetworkMessageType: %w", err)
 		}
 		if npdu.NetworkMessageType > 0x80 {
 			err := binary.Read(buf, binary.BigEndian, &npdu.VendorID)
 			if err != nil {
-				return fmt.Errorf("read NPDU VendorId: %w", err)
+				return 0, fmt.Errorf("read NPDU VendorId: %w", err)
 			}
 		}
 	} else {
 		npdu.ADPU = &APDU{}
-		return npdu.ADPU.UnmarshalBinary(buf.Bytes())
-
+		header := len(data) - buf.Len()
+		apduHeader, err := npdu.ADPU.unmarshal(buf.Bytes())
+		return header + apduHeader, err
 	}
-	return nil
+	return len(data), nil
 }
 
 // //go:generate stringer -type=PDUType
@@ -207,12 +216,14 @@ const (
 	ServiceUnconfirmedWhoIs             ServiceType = 8
 	ServiceUnconfirmedUTCTimeSync       ServiceType = 9
 	ServiceUnconfirmedWriteGroup        ServiceType = 10
+	ServiceUnconfirmedWhoAmI            ServiceType = 13
+	ServiceUnconfirmedYouAre            ServiceType = 14
 	/* Other services to be added as they are defined. */
 	/* All choice values in this production are reserved */
 	/* for definition by ASHRAE. */
 	/* Proprietary extensions are made by using the */
 	/* UnconfirmedPrivateTransfer service. See Clause 23. */
-	MaxServiceUnconfirmed ServiceType = 11
+	MaxServiceUnconfirmed ServiceType = 15
 )
 
 const (
@@ -262,26 +273,87 @@ const (
 
 // Todo: support more complex APDU
 type APDU struct {
-	DataType    PDUType
+	DataType PDUType
+	//ServiceType holds the reason of Reject and Abort PDUs
 	ServiceType ServiceType
 	Payload     Payload
 	//Only meaningfully for confirmed and ack
 	InvokeID byte
-	// MaxSegs
-	// Segmented message
-	// MoreFollow
-	// SegmentedResponseAccepted
-	// MaxApdu int
-	// Sequence                  uint8
-	// WindowNumber              uint8
+	//SegmentedResponseAccepted tells, in a confirmed request, that the
+	//answer may be segmented
+	SegmentedResponseAccepted bool
+	//Segmented and MoreFollows are set on the segments of confirmed
+	//requests and complex acks. The payload of a segment is a
+	//DataPayload with the raw data of the segment
+	Segmented   bool
+	MoreFollows bool
+	//Sequence and WindowSize are the sequence number and window size
+	//of a segment, or of a segment ack
+	Sequence   byte
+	WindowSize byte
+	//NegativeAck tells, in a segment ack, that a segment was missed
+	NegativeAck bool
+}
+
+// maxSegmentsAccepted is the number of segments of a response the
+// client accepts, and segmentsAcceptedFlag its encoding in a confirmed
+// request
+const (
+	maxSegmentsAccepted  = 64
+	segmentsAcceptedFlag = 0x60
+)
+
+// segmentFlags returns the flags of the first byte of segmented PDUs
+func (apdu APDU) segmentFlags() byte {
+	var flags byte
+	if apdu.Segmented {
+		flags |= 0x08
+	}
+	if apdu.MoreFollows {
+		flags |= 0x04
+	}
+	return flags
 }
 
 func (apdu APDU) MarshalBinary() ([]byte, error) {
 	b := &bytes.Buffer{}
-	b.WriteByte(byte(apdu.DataType))
-	if apdu.DataType == ConfirmedServiceRequest {
-		b.WriteByte(5) //Todo: Write other  control flag here
+	switch apdu.DataType {
+	case ConfirmedServiceRequest:
+		flags := apdu.segmentFlags()
+		var maxSegs byte
+		if apdu.SegmentedResponseAccepted {
+			flags |= 0x02
+			maxSegs = segmentsAcceptedFlag
+		}
+		b.WriteByte(byte(apdu.DataType) | flags)
+		b.WriteByte(maxSegs | 5) //Max APDU of 1476 bytes
+		b.WriteByte(apdu.InvokeID)
+		if apdu.Segmented {
+			b.WriteByte(apdu.Sequence)
+			b.WriteByte(apdu.WindowSize)
+		}
+	case ComplexAck:
+		b.WriteByte(byte(apdu.DataType) | apdu.segmentFlags())
+		b.WriteByte(apdu.InvokeID)
+		if apdu.Segmented {
+			b.WriteByte(apdu.Sequence)
+			b.WriteByte(apdu.WindowSize)
+		}
+	case SegmentAck:
+		flags := byte(0)
+		if apdu.NegativeAck {
+			flags |= 0x02
+		}
+		b.WriteByte(byte(apdu.DataType) | flags)
+		b.WriteByte(apdu.InvokeID)
+		b.WriteByte(apdu.Sequence)
+		b.WriteByte(apdu.WindowSize)
+		return b.Bytes(), nil
+	case SimpleAck, Error, Reject, Abort:
+		b.WriteByte(byte(apdu.DataType))
 		b.WriteByte(apdu.InvokeID)
+	default:
+		b.WriteByte(byte(apdu.DataType))
 	}
 	b.WriteByte(byte(apdu.ServiceType))
 	bytes, err := apdu.Payload.MarshalBinary()
@@ -292,39 +364,183 @@ func (apdu APDU) MarshalBinary() ([]byte, error) {
 	return b.Bytes(), nil
 }
 func (apdu *APDU) UnmarshalBinary(data []byte) error {
+	_, err := apdu.unmarshal(data)
+	return err
+}
+
+// unmarshal decodes the APDU, and returns the length of its header:
+// the offset of the service data
+func (apdu *APDU) unmarshal(data []byte) (int, error) {
 	buf := bytes.NewBuffer(data)
 	err := binary.Read(buf, binary.BigEndian, &apdu.DataType)
 	if err != nil {
-		return fmt.Errorf("read APDU DataType: %w", err)
+		return 0, fmt.Errorf("read APDU DataType: %w", err)
+	}
+	if t := apdu.DataType & 0xF0; t == Reject || t == Abort {
+		//The low bit of an abort tells if it was sent by the server
+		apdu.DataType = t
 	}
-	if apdu.DataType == ComplexAck || apdu.DataType == Error {
+	if t := apdu.DataType & 0xF0; t == ConfirmedServiceRequest {
+		flags := apdu.DataType
+		apdu.DataType = t
+		apdu.SegmentedResponseAccepted = flags&0x02 > 0
+		//Skip the max segments and max APDU size accepted
+		_, err = buf.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		apdu.InvokeID, err = buf.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		err = apdu.readSegmentHeader(buf, flags)
+		if err != nil {
+			return 0, err
+		}
+	}
+	if t := apdu.DataType & 0xF0; t == ComplexAck {
+		flags := apdu.DataType
+		apdu.DataType = t
+		apdu.InvokeID, err = buf.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		err = apdu.readSegmentHeader(buf, flags)
+		if err != nil {
+			return 0, err
+		}
+	}
+	if t := apdu.DataType & 0xF0; t == SegmentAck {
+		flags := apdu.DataType
+		apdu.DataType = t
+		apdu.NegativeAck = flags&0x02 > 0
+		header := buf.Next(3)
+		if len(header) != 3 {
+			return 0, errors.New("read segment ack: unexpected end of data")
+		}
+		apdu.InvokeID, apdu.Sequence, apdu.WindowSize = header[0], header[1], header[2]
+		apdu.Payload = &DataPayload{}
+		return len(data) - buf.Len(), nil
+	}
+	if apdu.DataType == SimpleAck || apdu.DataType == Error ||
+		apdu.DataType == Reject || apdu.DataType == Abort {
 		apdu.InvokeID, err = buf.ReadByte()
 		if err != nil {
-			return err
+			return 0, err
 		}
 	}
 	//Todo refactor
 	err = binary.Read(buf, binary.BigEndian, &apdu.ServiceType)
 	if err != nil {
-		return fmt.Errorf("read APDU ServiceType: %w", err)
+		return 0, fmt.Errorf("read APDU ServiceType: %w", err)
 	}
-	if apdu.DataType == UnconfirmedServiceRequest && apdu.ServiceType == ServiceUnconfirmedWhoIs {
+	if apdu.Segmented {
+		//The payload is only decoded once reassembled
+		apdu.Payload = &DataPayload{}
+
+	} else if apdu.DataType == UnconfirmedServiceRequest && apdu.ServiceType == ServiceUnconfirmedWhoIs {
 		apdu.Payload = &WhoIs{}
 
 	} else if apdu.DataType == UnconfirmedServiceRequest && apdu.ServiceType == ServiceUnconfirmedIAm {
 		apdu.Payload = &Iam{}
 
+	} else if (apdu.DataType == UnconfirmedServiceRequest && apdu.ServiceType == ServiceUnconfirmedCOVNotification) ||
+		(apdu.DataType == ConfirmedServiceRequest && apdu.ServiceType == ServiceConfirmedCOVNotification) {
+		apdu.Payload = &COVNotification{}
+
+	} else if (apdu.DataType == UnconfirmedServiceRequest && apdu.ServiceType == ServiceUnconfirmedEventNotification) ||
+		(apdu.DataType == ConfirmedServiceRequest && apdu.ServiceType == ServiceConfirmedEventNotification) {
+		apdu.Payload = &EventNotification{}
+
+	} else if apdu.DataType == UnconfirmedServiceRequest && apdu.ServiceType == ServiceUnconfirmedWhoHas {
+		apdu.Payload = &WhoHas{}
+
+	} else if apdu.DataType == UnconfirmedServiceRequest && apdu.ServiceType == ServiceUnconfirmedIHave {
+		apdu.Payload = &IHave{}
+
+	} else if apdu.DataType == UnconfirmedServiceRequest &&
+		(apdu.ServiceType == ServiceUnconfirmedTimeSync || apdu.ServiceType == ServiceUnconfirmedUTCTimeSync) {
+		apdu.Payload = &TimeSync{}
+
+	} else if (apdu.DataType == UnconfirmedServiceRequest && apdu.ServiceType == ServiceUnconfirmedTextMessage) ||
+		(apdu.DataType == ConfirmedServiceRequest && apdu.ServiceType == ServiceConfirmedTextMessage) {
+		apdu.Payload = &TextMessage{}
+
 	} else if apdu.DataType == ComplexAck && apdu.ServiceType == ServiceConfirmedReadProperty {
 		apdu.Payload = &ReadProperty{}
 
+	} else if apdu.DataType == ComplexAck && apdu.ServiceType == ServiceConfirmedGetAlarmSummary {
+		apdu.Payload = &GetAlarmSummary{}
+
+	} else if apdu.DataType == ComplexAck && apdu.ServiceType == ServiceConfirmedGetEnrollmentSummary {
+		apdu.Payload = &GetEnrollmentSummary{}
+
+	} else if apdu.DataType == ComplexAck && apdu.ServiceType == ServiceConfirmedGetEventInformation {
+		apdu.Payload = &GetEventInformation{}
+
+	} else if apdu.DataType == ComplexAck && apdu.ServiceType == ServiceConfirmedReadRange {
+		apdu.Payload = &ReadRange{}
+
+	} else if apdu.DataType == ComplexAck && apdu.ServiceType == ServiceConfirmedAtomicReadFile {
+		apdu.Payload = &AtomicReadFile{}
+
+	} else if apdu.DataType == ComplexAck && apdu.ServiceType == ServiceConfirmedAtomicWriteFile {
+		apdu.Payload = &AtomicWriteFile{}
+
+	} else if apdu.DataType == ComplexAck && apdu.ServiceType == ServiceConfirmedReadPropMultiple {
+		apdu.Payload = &ReadPropertyMultiple{}
+
+	} else if apdu.DataType == ComplexAck && apdu.ServiceType == ServiceConfirmedReadPropConditional {
+		apdu.Payload = &ReadPropertyConditional{}
+
+	} else if apdu.DataType == UnconfirmedServiceRequest && apdu.ServiceType == ServiceUnconfirmedWriteGroup {
+		apdu.Payload = &WriteGroup{}
+
+	} else if apdu.DataType == UnconfirmedServiceRequest && apdu.ServiceType == ServiceUnconfirmedWhoAmI {
+		apdu.Payload = &WhoAmI{}
+
+	} else if apdu.DataType == UnconfirmedServiceRequest && apdu.ServiceType == ServiceUnconfirmedYouAre {
+		apdu.Payload = &YouAre{}
+
+	} else if (apdu.DataType == ComplexAck && apdu.ServiceType == ServiceConfirmedPrivateTransfer) ||
+		(apdu.DataType == UnconfirmedServiceRequest && apdu.ServiceType == ServiceUnconfirmedPrivateTransfer) {
+		apdu.Payload = &PrivateTransfer{}
+
+	} else if apdu.DataType == Error && apdu.ServiceType == ServiceConfirmedPrivateTransfer {
+		apdu.Payload = &PrivateTransferError{}
+
+	} else if apdu.DataType == ComplexAck && apdu.ServiceType == ServiceConfirmedCreateObject {
+		apdu.Payload = &CreateObject{}
+
+	} else if apdu.DataType == Error && apdu.ServiceType == ServiceConfirmedCreateObject {
+		apdu.Payload = &CreateObjectError{}
+
+	} else if apdu.DataType == Error && apdu.ServiceType == ServiceConfirmedWritePropMultiple {
+		apdu.Payload = &WritePropertyMultipleError{}
 	} else if apdu.DataType == Error {
 		apdu.Payload = &ApduError{}
 	} else {
 		// Just pass raw data, decoding is not yet ready
 		apdu.Payload = &DataPayload{}
 	}
-	return apdu.Payload.UnmarshalBinary(buf.Bytes())
+	header := len(data) - buf.Len()
+	return header, apdu.Payload.UnmarshalBinary(buf.Bytes())
+}
 
+// readSegmentHeader reads the sequence number and window size of a
+// segment, if the flags of the PDU tell it's segmented
+func (apdu *APDU) readSegmentHeader(buf *bytes.Buffer, flags PDUType) error {
+	if flags&0x08 == 0 {
+		return nil
+	}
+	apdu.Segmented = true
+	apdu.MoreFollows = flags&0x04 > 0
+	header := buf.Next(2)
+	if len(header) != 2 {
+		return errors.New("read APDU segment header: unexpected end of data")
+	}
+	apdu.Sequence, apdu.WindowSize = header[0], header[1]
+	return nil
 }
 
 type Payload interface {
@@ -359,23 +575,56 @@ const (
 	BacFuncBroadcastDistributionTable      Function = 2
 	BacFuncBroadcastDistributionTableAck   Function = 3
 	BacFuncForwardedNPDU                   Function = 4
+	BacFuncRegisterForeignDevice           Function = 5
+	BacFuncReadForeignDeviceTable          Function = 6
+	BacFuncReadForeignDeviceTableAck       Function = 7
+	BacFuncDeleteForeignDeviceTableEntry   Function = 8
+	BacFuncDistributeBroadcastToNetwork    Function = 9
 	BacFuncUnicast                         Function = 10
 	BacFuncBroadcast                       Function = 11
 )
 
+// hasNPDU tells if the BVLL function carries an NPDU
+func (f Function) hasNPDU() bool {
+	switch f {
+	case BacFuncForwardedNPDU, BacFuncDistributeBroadcastToNetwork, BacFuncUnicast, BacFuncBroadcast:
+		return true
+	default:
+		return false
+	}
+}
+
 type BVLC struct {
 	Type     BVLCType
 	Function Function
-	NPDU     NPDU
+	//Origin is the address of the device that sent a forwarded NPDU
+	Origin *net.UDPAddr
+	NPDU   NPDU
+	//Data is the payload of the functions that don't carry an NPDU,
+	//such as results and foreign device registrations
+	Data []byte
 }
 
 func (bvlc BVLC) MarshalBinary() ([]byte, error) {
 	b := &bytes.Buffer{}
 	b.WriteByte(byte(bvlc.Type))
 	b.WriteByte(byte(bvlc.Function))
-	data, err := bvlc.NPDU.MarshalBinary()
-	if err != nil {
-		return nil, err
+	data := bvlc.Data
+	if bvlc.Function.hasNPDU() {
+		var err error
+		data, err = bvlc.NPDU.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+	}
+	if bvlc.Function == BacFuncForwardedNPDU {
+		if bvlc.Origin == nil || bvlc.Origin.IP.To4() == nil {
+			return nil, errors.New("forwarded NPDU without IPv4 origin address")
+		}
+		origin := make([]byte, 6, 6+len(data))
+		copy(origin, bvlc.Origin.IP.To4())
+		binary.BigEndian.PutUint16(origin[4:], uint16(bvlc.Origin.Port))
+		data = append(origin, data...)
 	}
 	len := uint16(4 + len(data)) //len includes Type,Function and itself
 	_ = binary.Write(b, binary.BigEndian, len)
@@ -386,29 +635,55 @@ func (bvlc BVLC) MarshalBinary() ([]byte, error) {
 var ErrNotBAcnetIP = errors.New("packet isn't a bacnet/IP payload ")
 
 func (bvlc *BVLC) UnmarshalBinary(data []byte) error {
+	_, err := bvlc.unmarshal(data)
+	return err
+}
+
+// unmarshal decodes the BVLC, and returns the length of all the headers
+// of the message: the offset of the service data of its APDU
+func (bvlc *BVLC) unmarshal(data []byte) (int, error) {
 	buf := bytes.NewBuffer(data)
 	bvlcType, err := buf.ReadByte()
 	if err != nil {
-		return fmt.Errorf("read bvlc type: %w", err)
+		return 0, fmt.Errorf("read bvlc type: %w", err)
 	}
 	bvlc.Type = BVLCType(bvlcType)
 	if bvlc.Type != TypeBacnetIP {
-		return ErrNotBAcnetIP
+		return 0, ErrNotBAcnetIP
 	}
 	bvlcFunc, err := buf.ReadByte()
 	if err != nil {
-		return fmt.Errorf("read bvlc func: %w", err)
+		return 0, fmt.Errorf("read bvlc func: %w", err)
 	}
 	var length uint16
 	err = binary.Read(buf, binary.BigEndian, &length)
 	if err != nil {
-		return fmt.Errorf("read bvlc length: %w", err)
+		return 0, fmt.Errorf("read bvlc length: %w", err)
 	}
 	remaining := buf.Bytes()
 
 	bvlc.Function = Function(bvlcFunc)
 	if len(remaining) != int(length)-4 {
-		return fmt.Errorf("incoherent Length field in BVCL. Advertized payload size is %d, real size  %d", length-4, len(remaining))
+		return 0, fmt.Errorf("incoherent Length field in BVCL. Advertized payload size is %d, real size  %d", length-4, len(remaining))
+	}
+	bvlc.Origin = nil
+	bvlc.Data = nil
+	if bvlc.Function == BacFuncForwardedNPDU {
+		if len(remaining) < 6 {
+			return 0, errors.New("forwarded NPDU without origin address")
+		}
+		bvlc.Origin = &net.UDPAddr{
+			IP:   net.IPv4(remaining[0], remaining[1], remaining[2], remaining[3]),
+			Port: int(binary.BigEndian.Uint16(remaining[4:6])),
+		}
+		remaining = remaining[6:]
+	}
+	if !bvlc.Function.hasNPDU() {
+		bvlc.Data = make([]byte, len(remaining))
+		copy(bvlc.Data, remaining)
+		return len(data), nil
 	}
-	return bvlc.NPDU.UnmarshallBinary(remaining)
+	header := len(data) - len(remaining)
+	npduHeader, err := bvlc.NPDU.unmarshal(remaining)
+	return header + npduHeader, err
 }
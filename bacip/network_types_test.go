@@ -2,6 +2,7 @@ package bacip
 
 import (
 	"encoding/hex"
+	"net"
 	"testing"
 
 	"github.com/REQUEA/bacnet"
@@ -64,6 +65,68 @@ func TestFullEncodingAndCoherency(t *testing.T) {
 			},
 			encoded: "810b00190120ffff00ff1000c4020075e92205c4910022016c",
 		},
+		{
+			bvlc: BVLC{
+				Type:     TypeBacnetIP,
+				Function: BacFuncForwardedNPDU,
+				Origin:   &net.UDPAddr{IP: net.IPv4(10, 0, 0, 1), Port: DefaultUDPPort},
+				NPDU: NPDU{
+					Version:               Version1,
+					IsNetworkLayerMessage: false,
+					ExpectingReply:        false,
+					Priority:              Normal,
+					ADPU: &APDU{
+						DataType:    UnconfirmedServiceRequest,
+						ServiceType: ServiceUnconfirmedWhoIs,
+						Payload:     &WhoIs{},
+					},
+				},
+			},
+			encoded: "8104000e0a000001bac001001008",
+		},
+		{
+			bvlc: BVLC{
+				Type:     TypeBacnetIP,
+				Function: BacFuncUnicast,
+				NPDU: NPDU{
+					Version:        Version1,
+					ExpectingReply: true,
+					Priority:       Normal,
+					ADPU: &APDU{
+						DataType:    ConfirmedServiceRequest,
+						ServiceType: ServiceConfirmedReadProperty,
+						InvokeID:    7,
+						Payload:     &DataPayload{Bytes: []byte{0x0c, 0x00, 0x00, 0x00, 0x01, 0x19, 0x55}},
+					},
+				},
+			},
+			encoded: "810a001101040005070c0c000000011955",
+		},
+		{
+			bvlc: BVLC{
+				Type:     TypeBacnetIP,
+				Function: BacFuncUnicast,
+				NPDU: NPDU{
+					Version:  Version1,
+					Priority: Normal,
+					ADPU: &APDU{
+						DataType:    SimpleAck,
+						ServiceType: ServiceConfirmedWriteProperty,
+						InvokeID:    7,
+						Payload:     &DataPayload{},
+					},
+				},
+			},
+			encoded: "810a0009010020070f",
+		},
+		{
+			bvlc: BVLC{
+				Type:     TypeBacnetIP,
+				Function: BacFuncRegisterForeignDevice,
+				Data:     []byte{0x00, 0x3c},
+			},
+			encoded: "81050006003c",
+		},
 	}
 
 	for _, tc := range ttc {
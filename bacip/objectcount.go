@@ -0,0 +1,68 @@
+package bacip
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/REQUEA/bacnet"
+)
+
+// maxCountConcurrency is the number of devices counted at the same
+// time by CountDevicesObjects
+const maxCountConcurrency = 16
+
+// CountObjects returns the number of objects of the device. Only the
+// size of the object list is read, which is much faster than reading
+// the list
+func (c *Client) CountObjects(ctx context.Context, device bacnet.Device) (int, error) {
+	prop := bacnet.PropertyIdentifier{Type: bacnet.ObjectList, ArrayIndex: new(uint32)}
+	d, err := c.ReadProperty(ctx, device, ReadProperty{ObjectID: device.ID, Property: prop})
+	if err != nil {
+		return 0, fmt.Errorf("read object list length: %w", err)
+	}
+	length, ok := d.(uint32)
+	if !ok {
+		return 0, fmt.Errorf("unexpected object list length type %T", d)
+	}
+	return int(length), nil
+}
+
+// ObjectCount is the number of objects of a device, or the error
+// that prevented counting them
+type ObjectCount struct {
+	Device bacnet.Device
+	Count  int
+	Err    error
+}
+
+// CountDevicesObjects counts the objects of several devices
+// concurrently. The counts are sorted from the largest device to the
+// smallest, followed by the devices that couldn't be counted, so that
+// inventory tools can estimate the duration of a scan and start with
+// the largest controllers
+func (c *Client) CountDevicesObjects(ctx context.Context, devices []bacnet.Device) []ObjectCount {
+	counts := make([]ObjectCount, len(devices))
+	slots := make(chan struct{}, maxCountConcurrency)
+	var wg sync.WaitGroup
+	for i, d := range devices {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(i int, d bacnet.Device) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			n, err := c.CountObjects(ctx, d)
+			counts[i] = ObjectCount{Device: d, Count: n, Err: err}
+		}(i, d)
+	}
+	wg.Wait()
+	sort.SliceStable(counts, func(i, j int) bool {
+		a, b := counts[i], counts[j]
+		if (a.Err == nil) != (b.Err == nil) {
+			return a.Err == nil
+		}
+		return a.Count > b.Count
+	})
+	return counts
+}
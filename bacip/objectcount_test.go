@@ -0,0 +1,70 @@
+package bacip
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/REQUEA/bacnet"
+	"github.com/REQUEA/bacnet/internal/encoding"
+
+	"github.com/matryer/is"
+)
+
+func TestCountDevicesObjects(t *testing.T) {
+	is := is.New(t)
+	counts := map[byte]uint32{3: 12, 4: 250}
+	m := newMemTransport()
+	m.respond = func(b []byte, addr *net.UDPAddr) []byte {
+		var bvlc BVLC
+		is.NoErr(bvlc.UnmarshalBinary(b))
+		request := bvlc.NPDU.ADPU
+		count, ok := counts[addr.IP.To4()[3]]
+		if !ok {
+			//Offline device
+			return nil
+		}
+		e := encoding.NewEncoder()
+		e.ContextObjectID(0, bacnet.ObjectID{Type: bacnet.BacnetDevice, Instance: bacnet.ObjectInstance(addr.IP.To4()[3])})
+		e.ContextUnsigned(1, uint32(bacnet.ObjectList))
+		e.ContextUnsigned(2, 0)
+		value := encoding.NewEncoder()
+		value.AppData(count)
+		e.ContextRaw(3, value.Bytes())
+		answer, err := BVLC{
+			Type:     TypeBacnetIP,
+			Function: BacFuncUnicast,
+			NPDU: NPDU{
+				Version: Version1,
+				ADPU: &APDU{
+					DataType:    ComplexAck,
+					ServiceType: ServiceConfirmedReadProperty,
+					InvokeID:    request.InvokeID,
+					Payload:     &DataPayload{Bytes: e.Bytes()},
+				},
+			},
+		}.MarshalBinary()
+		is.NoErr(err)
+		return answer
+	}
+	c := newMemClient(t, m)
+	device := func(i byte) bacnet.Device {
+		return bacnet.Device{
+			ID:   bacnet.ObjectID{Type: bacnet.BacnetDevice, Instance: bacnet.ObjectInstance(i)},
+			Addr: *bacnet.AddressFromUDP(net.UDPAddr{IP: net.IPv4(10, 0, 2, i).To4(), Port: DefaultUDPPort}),
+		}
+	}
+
+	n, err := c.CountObjects(context.Background(), device(3))
+	is.NoErr(err)
+	is.Equal(n, 12)
+
+	c.SetDefaultProfile(DeviceProfile{Timeout: 20 * time.Millisecond})
+	results := c.CountDevicesObjects(context.Background(), []bacnet.Device{device(5), device(3), device(4)})
+	is.Equal(len(results), 3)
+	is.Equal(results[0].Device.ID.Instance, bacnet.ObjectInstance(4))
+	is.Equal(results[0].Count, 250)
+	is.Equal(results[1].Count, 12)
+	is.True(results[2].Err != nil)
+}
@@ -0,0 +1,128 @@
+package bacip
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/REQUEA/bacnet"
+)
+
+// Point is an object of a device
+type Point struct {
+	Device bacnet.Device
+	Object bacnet.ObjectID
+}
+
+// Operation is a command applied to the present value of many points
+// by ApplyOperation, such as the seasonal changeover of a site
+type Operation struct {
+	//Value is written at Priority. A nil Value relinquishes the
+	//priority
+	Value    *bacnet.PropertyValue
+	Priority bacnet.PriorityList
+	//Filter, if set, selects the points the operation applies to
+	Filter func(Point) bool
+	//DryRun reports the points that would be commanded without
+	//writing anything
+	DryRun bool
+	//Concurrency is the number of devices commanded at the same time,
+	//1 if 0. The points of a device are commanded one after the other
+	Concurrency int
+	//Progress, if set, is called after each point with its result,
+	//the number of points done and the number of points selected. The
+	//calls aren't concurrent
+	Progress func(result OperationResult, done, total int)
+}
+
+// Override returns the operation writing the value at the priority
+func Override(value bacnet.PropertyValue, priority bacnet.PriorityList) Operation {
+	return Operation{Value: &value, Priority: priority}
+}
+
+// RelinquishOperation returns the operation relinquishing the priority
+func RelinquishOperation(priority bacnet.PriorityList) Operation {
+	return Operation{Priority: priority}
+}
+
+// OperationResult is the outcome of an operation on a point. Err is
+// always nil in dry run mode
+type OperationResult struct {
+	Point Point
+	Err   error
+}
+
+// ApplyOperation commands the present value of the points selected by
+// the filter of the operation, and returns their results in the order
+// of the points. A failed point doesn't stop the operation; an error
+// is only returned if the operation is invalid or ctx is done
+func (c *Client) ApplyOperation(ctx context.Context, points []Point, op Operation) ([]OperationResult, error) {
+	if op.Priority < 1 || op.Priority > 16 {
+		return nil, fmt.Errorf("invalid priority %d", op.Priority)
+	}
+	var selected []Point
+	for _, p := range points {
+		if op.Filter == nil || op.Filter(p) {
+			selected = append(selected, p)
+		}
+	}
+	results := make([]OperationResult, len(selected))
+	//Indexes of the points of each device, in order
+	var devices []bacnet.ObjectID
+	byDevice := map[bacnet.ObjectID][]int{}
+	for i, p := range selected {
+		if _, ok := byDevice[p.Device.ID]; !ok {
+			devices = append(devices, p.Device.ID)
+		}
+		byDevice[p.Device.ID] = append(byDevice[p.Device.ID], i)
+	}
+	concurrency := op.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	var mutex sync.Mutex
+	done := 0
+	report := func(i int, err error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		results[i] = OperationResult{Point: selected[i], Err: err}
+		done++
+		if op.Progress != nil {
+			op.Progress(results[i], done, len(selected))
+		}
+	}
+	value := bacnet.PropertyValue{Type: bacnet.TypeNull}
+	if op.Value != nil {
+		value = *op.Value
+	}
+	slots := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for _, device := range devices {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(indexes []int) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			for _, i := range indexes {
+				if err := ctx.Err(); err != nil {
+					report(i, err)
+					continue
+				}
+				if op.DryRun {
+					report(i, nil)
+					continue
+				}
+				p := selected[i]
+				err := c.WriteProperty(ctx, p.Device, WriteProperty{
+					ObjectID:      p.Object,
+					Property:      bacnet.PropertyIdentifier{Type: bacnet.PresentValue},
+					PropertyValue: value,
+					Priority:      op.Priority,
+				})
+				report(i, err)
+			}
+		}(byDevice[device])
+	}
+	wg.Wait()
+	return results, ctx.Err()
+}
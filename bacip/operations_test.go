@@ -0,0 +1,65 @@
+package bacip
+
+import (
+	"context"
+	"testing"
+
+	"github.com/REQUEA/bacnet"
+	"github.com/matryer/is"
+)
+
+func TestApplyOperation(t *testing.T) {
+	is := is.New(t)
+	d1 := newMemDevice(10)
+	d2 := newMemDevice(20)
+	ao := func(instance bacnet.ObjectInstance) bacnet.ObjectID {
+		return bacnet.ObjectID{Type: bacnet.AnalogOutput, Instance: instance}
+	}
+	d1.set(ao(1), bacnet.PresentValue, float32(0))
+	d1.set(ao(2), bacnet.PresentValue, float32(0))
+	d2.set(ao(1), bacnet.PresentValue, float32(0))
+	m := newMemTransport()
+	m.respond = serveDevices(t, d1, d2)
+	c := newMemClient(t, m)
+	points := []Point{
+		{Device: d1.device(), Object: ao(1)},
+		{Device: d1.device(), Object: ao(2)},
+		{Device: d2.device(), Object: ao(1)},
+		{Device: d2.device(), Object: ao(3)}, //Unknown object
+		{Device: d2.device(), Object: bacnet.ObjectID{Type: bacnet.AnalogInput, Instance: 1}},
+	}
+	op := Override(bacnet.PropertyValue{Type: bacnet.TypeReal, Value: float32(18)}, 8)
+	op.Filter = func(p Point) bool { return p.Object.Type == bacnet.AnalogOutput }
+	op.Concurrency = 2
+	op.DryRun = true
+	var progress []int
+	op.Progress = func(_ OperationResult, done, total int) {
+		is.Equal(total, 4)
+		progress = append(progress, done)
+	}
+	results, err := c.ApplyOperation(context.Background(), points, op)
+	is.NoErr(err)
+	is.Equal(len(results), 4)
+	is.Equal(progress, []int{1, 2, 3, 4})
+	is.Equal(len(d1.received())+len(d2.received()), 0)
+
+	op.DryRun = false
+	results, err = c.ApplyOperation(context.Background(), points, op)
+	is.NoErr(err)
+	for i, r := range results {
+		is.Equal(r.Point, points[i])
+		is.Equal(r.Err != nil, i == 3)
+	}
+	v, _ := d1.written(ao(2), bacnet.PresentValue)
+	is.Equal(v, float32(18))
+	requests := d2.received()
+	is.Equal(requests[len(requests)-1].priority, bacnet.PriorityList(8))
+
+	_, err = c.ApplyOperation(context.Background(), points, RelinquishOperation(8))
+	is.NoErr(err)
+	v, ok := d1.written(ao(1), bacnet.PresentValue)
+	is.True(ok)
+	is.Equal(v, nil)
+	_, err = c.ApplyOperation(context.Background(), points, RelinquishOperation(0))
+	is.True(err != nil)
+}
@@ -0,0 +1,145 @@
+package bacip
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/REQUEA/bacnet"
+)
+
+// StatusFlags are the four flags summarizing the health of an object
+type StatusFlags struct {
+	InAlarm      bool
+	Fault        bool
+	Overridden   bool
+	OutOfService bool
+}
+
+// StatusFlagsFromBits decodes the status-flags property
+func StatusFlagsFromBits(bs bacnet.BitString) StatusFlags {
+	return StatusFlags{
+		InAlarm:      bs.Bit(0),
+		Fault:        bs.Bit(1),
+		Overridden:   bs.Bit(2),
+		OutOfService: bs.Bit(3),
+	}
+}
+
+func (f StatusFlags) bits() bacnet.BitString {
+	return bacnet.BitString{f.InAlarm, f.Fault, f.Overridden, f.OutOfService}
+}
+
+// Reliability tells if the present value of an object is reliable,
+// or why it isn't
+type Reliability uint32
+
+//go:generate stringer -type=Reliability
+const (
+	ReliabilityNoFaultDetected               Reliability = 0
+	ReliabilityNoSensor                      Reliability = 1
+	ReliabilityOverRange                     Reliability = 2
+	ReliabilityUnderRange                    Reliability = 3
+	ReliabilityOpenLoop                      Reliability = 4
+	ReliabilityShortedLoop                   Reliability = 5
+	ReliabilityNoOutput                      Reliability = 6
+	ReliabilityUnreliableOther               Reliability = 7
+	ReliabilityProcessError                  Reliability = 8
+	ReliabilityMultiStateFault               Reliability = 9
+	ReliabilityConfigurationError            Reliability = 10
+	ReliabilityCommunicationFailure          Reliability = 12
+	ReliabilityMemberFault                   Reliability = 13
+	ReliabilityMonitoredObjectFault          Reliability = 14
+	ReliabilityTripped                       Reliability = 15
+	ReliabilityLampFailure                   Reliability = 16
+	ReliabilityActivationFailure             Reliability = 17
+	ReliabilityRenewDHCPFailure              Reliability = 18
+	ReliabilityRenewFDRegistrationFailure    Reliability = 19
+	ReliabilityRestartAutoNegotiationFailure Reliability = 20
+	ReliabilityRestartFailure                Reliability = 21
+	ReliabilityProprietaryCommandFailure     Reliability = 22
+	ReliabilityFaultsListed                  Reliability = 23
+	ReliabilityReferencedObjectFault         Reliability = 24
+)
+
+// Quality tells if a value can be trusted
+type Quality byte
+
+//go:generate stringer -type=Quality
+const (
+	//QualityGood values can be used as is
+	QualityGood Quality = iota
+	//QualityUncertain values are valid but don't come from the
+	//process: the object is out of service or overridden
+	QualityUncertain
+	//QualityBad values are wrong, the object reports a fault
+	QualityBad
+	//QualityCommFailure values couldn't be read, or the device
+	//couldn't read them from the field
+	QualityCommFailure
+)
+
+// QualityOf derives the quality of a present value from the status
+// flags and the reliability of its object. The OutOfService flag
+// mirrors the out-of-service property
+func QualityOf(flags StatusFlags, reliability Reliability) Quality {
+	switch {
+	case reliability == ReliabilityCommunicationFailure:
+		return QualityCommFailure
+	case flags.Fault || reliability != ReliabilityNoFaultDetected:
+		return QualityBad
+	case flags.OutOfService || flags.Overridden:
+		return QualityUncertain
+	default:
+		return QualityGood
+	}
+}
+
+// PointValue is the present value of an object along with its quality
+type PointValue struct {
+	Value       interface{}
+	Quality     Quality
+	StatusFlags StatusFlags
+	Reliability Reliability
+}
+
+// ReadPoint reads the present value of an object and the properties
+// telling if it can be trusted. If the device doesn't answer, the
+// error is returned along with a value of quality QualityCommFailure.
+func (c *Client) ReadPoint(ctx context.Context, device bacnet.Device, object bacnet.ObjectID) (PointValue, error) {
+	p := PointValue{Quality: QualityCommFailure}
+	value, err := c.ReadProperty(ctx, device, ReadProperty{
+		ObjectID: object,
+		Property: bacnet.PropertyIdentifier{Type: bacnet.PresentValue},
+	})
+	if err != nil {
+		if !errors.Is(err, context.DeadlineExceeded) {
+			p.Quality = QualityBad
+		}
+		return p, fmt.Errorf("read present value: %w", err)
+	}
+	p.Value = value
+	d, err := c.ReadProperty(ctx, device, ReadProperty{
+		ObjectID: object,
+		Property: bacnet.PropertyIdentifier{Type: bacnet.StatusFlags},
+	})
+	if err != nil {
+		return p, fmt.Errorf("read status flags: %w", err)
+	}
+	if bs, ok := d.(bacnet.BitString); ok {
+		p.StatusFlags = StatusFlagsFromBits(bs)
+	}
+	//Reliability is optional for most objects
+	d, err = c.ReadProperty(ctx, device, ReadProperty{
+		ObjectID: object,
+		Property: bacnet.PropertyIdentifier{Type: bacnet.Reliability},
+	})
+	if err != nil && !isUnknownProperty(err) {
+		return p, fmt.Errorf("read reliability: %w", err)
+	}
+	if v, ok := d.(uint32); ok {
+		p.Reliability = Reliability(v)
+	}
+	p.Quality = QualityOf(p.StatusFlags, p.Reliability)
+	return p, nil
+}
@@ -0,0 +1,26 @@
+package bacip
+
+import (
+	"testing"
+
+	"github.com/REQUEA/bacnet"
+
+	"github.com/matryer/is"
+)
+
+func TestQualityOf(t *testing.T) {
+	is := is.New(t)
+	is.Equal(QualityOf(StatusFlags{}, ReliabilityNoFaultDetected), QualityGood)
+	is.Equal(QualityOf(StatusFlags{InAlarm: true}, ReliabilityNoFaultDetected), QualityGood)
+	is.Equal(QualityOf(StatusFlags{OutOfService: true}, ReliabilityNoFaultDetected), QualityUncertain)
+	is.Equal(QualityOf(StatusFlags{Overridden: true}, ReliabilityNoFaultDetected), QualityUncertain)
+	is.Equal(QualityOf(StatusFlags{Fault: true, OutOfService: true}, ReliabilityNoFaultDetected), QualityBad)
+	is.Equal(QualityOf(StatusFlags{}, ReliabilityOverRange), QualityBad)
+	is.Equal(QualityOf(StatusFlags{Fault: true}, ReliabilityCommunicationFailure), QualityCommFailure)
+}
+
+func TestStatusFlagsFromBits(t *testing.T) {
+	is := is.New(t)
+	is.Equal(StatusFlagsFromBits(bacnet.BitString{false, true, false, true}), StatusFlags{Fault: true, OutOfService: true})
+	is.Equal(StatusFlagsFromBits(nil), StatusFlags{})
+}
@@ -0,0 +1,235 @@
+package bacip
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/REQUEA/bacnet"
+)
+
+// DefaultPollBatch is the number of properties read by a single
+// request of a Poller, unless the profile of the device sets a lower
+// MaxReadPropertyMultiple
+const DefaultPollBatch = 20
+
+// Poller reads points at their poll interval. The reads of the
+// points of a device are grouped in ReadPropertyMultiple batches, and
+// the batches of the devices are interleaved: the devices having
+// points due are served in turn, one batch at a time, most overdue
+// points first, so that a device with many points doesn't delay the
+// others.
+type Poller struct {
+	client *Client
+
+	mutex   sync.Mutex
+	devices map[bacnet.ObjectID]*pollDevice
+	//order is the round-robin order of the devices, next is the index
+	//of the device served first at the next turn
+	order []bacnet.ObjectID
+	next  int
+	wake  chan struct{}
+}
+
+type pollDevice struct {
+	device bacnet.Device
+	points map[PointRef]*pollPoint
+}
+
+type pollPoint struct {
+	ref      PointRef
+	interval time.Duration
+	deadline time.Time
+}
+
+// NewPoller returns a poller without points
+func NewPoller(c *Client) *Poller {
+	return &Poller{
+		client:  c,
+		devices: map[bacnet.ObjectID]*pollDevice{},
+		wake:    make(chan struct{}, 1),
+	}
+}
+
+// Poll adds a point to poll every interval, the first time right
+// away. Polling a point again changes its interval, and the address
+// of its device
+func (p *Poller) Poll(device bacnet.Device, object bacnet.ObjectID, property bacnet.PropertyType, interval time.Duration) error {
+	if interval <= 0 {
+		return errors.New("poll interval must be positive")
+	}
+	ref := PointRef{Device: device.ID, Object: object, Property: property}
+	p.mutex.Lock()
+	d, ok := p.devices[device.ID]
+	if !ok {
+		d = &pollDevice{points: map[PointRef]*pollPoint{}}
+		p.devices[device.ID] = d
+		p.order = append(p.order, device.ID)
+	}
+	d.device = device
+	if pt, ok := d.points[ref]; ok {
+		pt.interval = interval
+	} else {
+		d.points[ref] = &pollPoint{ref: ref, interval: interval, deadline: time.Now()}
+	}
+	p.mutex.Unlock()
+	select {
+	case p.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Remove stops polling the point
+func (p *Poller) Remove(ref PointRef) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	d, ok := p.devices[ref.Device]
+	if !ok {
+		return
+	}
+	delete(d.points, ref)
+	if len(d.points) > 0 {
+		return
+	}
+	delete(p.devices, ref.Device)
+	for i, id := range p.order {
+		if id == ref.Device {
+			p.order = append(p.order[:i], p.order[i+1:]...)
+			if p.next > i {
+				p.next--
+			}
+			break
+		}
+	}
+}
+
+// Run polls the points until the context is done, calling f with
+// every sample read. The points whose read failed get a sample
+// without value, of quality QualityBad if the device returned an
+// error or QualityCommFailure if it didn't answer
+func (p *Poller) Run(ctx context.Context, f func(PointRef, Sample)) {
+	timer := time.NewTimer(time.Hour)
+	defer timer.Stop()
+	for {
+		device, batch, wait := p.nextBatch(time.Now())
+		if batch != nil {
+			p.read(ctx, device, batch, f)
+			if ctx.Err() != nil {
+				return
+			}
+			continue
+		}
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(wait)
+		select {
+		case <-timer.C:
+		case <-p.wake:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// nextBatch returns the next batch of points due, and schedules their
+// next read. If no point is due, it returns how long to wait for the
+// next one
+func (p *Poller) nextBatch(now time.Time) (bacnet.Device, []PointRef, time.Duration) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	wait := time.Hour
+	for i := range p.order {
+		index := (p.next + i) % len(p.order)
+		d := p.devices[p.order[index]]
+		var due []*pollPoint
+		for _, pt := range d.points {
+			if !pt.deadline.After(now) {
+				due = append(due, pt)
+			} else if pt.deadline.Sub(now) < wait {
+				wait = pt.deadline.Sub(now)
+			}
+		}
+		if len(due) == 0 {
+			continue
+		}
+		sort.Slice(due, func(i, j int) bool {
+			if !due[i].deadline.Equal(due[j].deadline) {
+				return due[i].deadline.Before(due[j].deadline)
+			}
+			return lessPointRef(due[i].ref, due[j].ref)
+		})
+		size := DefaultPollBatch
+		if limit := p.client.DeviceProfile(d.device.ID).MaxReadPropertyMultiple; limit > 0 && limit < size {
+			size = limit
+		}
+		if len(due) > size {
+			due = due[:size]
+		}
+		batch := make([]PointRef, 0, len(due))
+		for _, pt := range due {
+			batch = append(batch, pt.ref)
+			pt.deadline = pt.deadline.Add(pt.interval)
+			if !pt.deadline.After(now) {
+				//Late by more than an interval: skip the missed reads
+				pt.deadline = now.Add(pt.interval)
+			}
+		}
+		p.next = (index + 1) % len(p.order)
+		return d.device, batch, 0
+	}
+	return bacnet.Device{}, nil, wait
+}
+
+func lessPointRef(a, b PointRef) bool {
+	if a.Object != b.Object {
+		return lessObjectID(a.Object, b.Object)
+	}
+	return a.Property < b.Property
+}
+
+// read reads a batch of points of the device and passes their samples
+// to f
+func (p *Poller) read(ctx context.Context, device bacnet.Device, batch []PointRef, f func(PointRef, Sample)) {
+	var specs []ReadAccessSpecification
+	for _, ref := range batch {
+		prop := bacnet.PropertyIdentifier{Type: ref.Property}
+		if n := len(specs); n > 0 && specs[n-1].ObjectID == ref.Object {
+			specs[n-1].Properties = append(specs[n-1].Properties, prop)
+			continue
+		}
+		specs = append(specs, ReadAccessSpecification{ObjectID: ref.Object, Properties: []bacnet.PropertyIdentifier{prop}})
+	}
+	results, err := p.client.ReadPropertyMultiple(ctx, device, specs)
+	now := time.Now()
+	if err != nil {
+		if ctx.Err() != nil {
+			return
+		}
+		quality := QualityCommFailure
+		var apduErr ApduError
+		if errors.As(err, &apduErr) {
+			quality = QualityBad
+		}
+		for _, ref := range batch {
+			f(ref, Sample{Time: now, Quality: quality})
+		}
+		return
+	}
+	for _, r := range results {
+		for _, res := range r.Results {
+			ref := PointRef{Device: device.ID, Object: r.ObjectID, Property: res.Property.Type}
+			s := Sample{Time: now, Value: res.Value, Quality: QualityGood}
+			if res.Error != nil {
+				s = Sample{Time: now, Quality: QualityBad}
+			}
+			f(ref, s)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package bacip
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/REQUEA/bacnet"
+	"github.com/matryer/is"
+)
+
+func TestPollerFairness(t *testing.T) {
+	is := is.New(t)
+	ai1 := bacnet.ObjectID{Type: bacnet.AnalogInput, Instance: 1}
+	big := newMemDevice(10)
+	small := newMemDevice(20)
+	m := newMemTransport()
+	m.respond = serveDevices(t, big, small)
+	c := newMemClient(t, m)
+	poller := NewPoller(c)
+	for i := 1; i <= 2*DefaultPollBatch+5; i++ {
+		id := bacnet.ObjectID{Type: bacnet.AnalogValue, Instance: bacnet.ObjectInstance(i)}
+		big.set(id, bacnet.PresentValue, float32(i))
+		is.NoErr(poller.Poll(big.device(), id, bacnet.PresentValue, time.Hour))
+	}
+	small.set(ai1, bacnet.PresentValue, float32(21.5))
+	small.set(ai1, bacnet.ObjectName, "OutdoorTemp")
+	is.NoErr(poller.Poll(small.device(), ai1, bacnet.PresentValue, time.Hour))
+	is.NoErr(poller.Poll(small.device(), ai1, bacnet.ObjectName, time.Hour))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var batches []bacnet.ObjectInstance
+	var sizes []int
+	samples, bad := 0, 0
+	var value interface{}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		poller.Run(ctx, func(p PointRef, s Sample) {
+			if s.Quality != QualityGood {
+				bad++
+			}
+			if p.Device == small.id && p.Property == bacnet.PresentValue {
+				value = s.Value
+			}
+			if len(batches) == 0 || batches[len(batches)-1] != p.Device.Instance {
+				batches = append(batches, p.Device.Instance)
+				sizes = append(sizes, 0)
+			}
+			sizes[len(sizes)-1]++
+			samples++
+			if samples == 2*DefaultPollBatch+7 {
+				cancel()
+			}
+		})
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("points not polled")
+	}
+	is.Equal(bad, 0)
+	is.Equal(value, float32(21.5))
+	//The small device is served between the batches of the big one
+	is.Equal(batches, []bacnet.ObjectInstance{10, 20, 10})
+	is.Equal(sizes, []int{DefaultPollBatch, 2, DefaultPollBatch + 5})
+	is.Equal(big.received()[0].service, ServiceConfirmedReadPropMultiple)
+}
@@ -0,0 +1,196 @@
+package bacip
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+
+	"github.com/REQUEA/bacnet"
+	"github.com/REQUEA/bacnet/internal/encoding"
+)
+
+// PrivateTransfer invokes a proprietary service of a vendor. It's
+// also the acknowledgment of a confirmed private transfer, Parameters
+// being then the result block
+type PrivateTransfer struct {
+	VendorID      uint32
+	ServiceNumber uint32
+	//Parameters are the encoded parameters of the service, nil for
+	//none
+	Parameters []byte
+}
+
+func (pt PrivateTransfer) MarshalBinary() ([]byte, error) {
+	encoder := encoding.NewEncoder()
+	encoder.ContextUnsigned(0, pt.VendorID)
+	encoder.ContextUnsigned(1, pt.ServiceNumber)
+	if pt.Parameters != nil {
+		encoder.ContextRaw(2, pt.Parameters)
+	}
+	return encoder.Bytes(), encoder.Error()
+}
+
+func (pt *PrivateTransfer) UnmarshalBinary(data []byte) error {
+	decoder := encoding.NewDecoder(data)
+	decoder.ContextValue(0, &pt.VendorID)
+	decoder.ContextValue(1, &pt.ServiceNumber)
+	pt.Parameters = nil
+	if decoder.IsOpeningTag(2) {
+		decoder.ContextRaw(2, &pt.Parameters)
+	}
+	return decoder.Error()
+}
+
+// Decode decodes the parameters with the codec registered for the
+// service
+func (pt PrivateTransfer) Decode() (interface{}, error) {
+	codec, ok := lookupPrivateTransferCodec(pt.VendorID, pt.ServiceNumber)
+	if !ok {
+		return nil, fmt.Errorf("no codec for service %d of vendor %d", pt.ServiceNumber, pt.VendorID)
+	}
+	return codec.Decode(pt.Parameters)
+}
+
+// PrivateTransferError is returned when a device fails to execute a
+// confirmed private transfer
+type PrivateTransferError struct {
+	Err           ApduError
+	VendorID      uint32
+	ServiceNumber uint32
+	//Parameters are the encoded error parameters, nil for none
+	Parameters []byte
+}
+
+func (e PrivateTransferError) Error() string {
+	return fmt.Sprintf("private transfer %d of vendor %d: %v", e.ServiceNumber, e.VendorID, e.Err)
+}
+
+func (e PrivateTransferError) Unwrap() error {
+	return e.Err
+}
+
+func (e PrivateTransferError) MarshalBinary() ([]byte, error) {
+	encoder := encoding.NewEncoder()
+	encoder.OpeningTag(0)
+	e.Err.encode(&encoder)
+	encoder.ClosingTag(0)
+	encoder.ContextUnsigned(1, e.VendorID)
+	encoder.ContextUnsigned(2, e.ServiceNumber)
+	if e.Parameters != nil {
+		encoder.ContextRaw(3, e.Parameters)
+	}
+	return encoder.Bytes(), encoder.Error()
+}
+
+func (e *PrivateTransferError) UnmarshalBinary(data []byte) error {
+	decoder := encoding.NewDecoder(data)
+	decoder.OpeningTag(0)
+	e.Err.decode(decoder)
+	decoder.ClosingTag(0)
+	decoder.ContextValue(1, &e.VendorID)
+	decoder.ContextValue(2, &e.ServiceNumber)
+	e.Parameters = nil
+	if decoder.IsOpeningTag(3) {
+		decoder.ContextRaw(3, &e.Parameters)
+	}
+	return decoder.Error()
+}
+
+// PrivateTransferCodec encodes the parameters of a proprietary service
+// and decodes its parameters and results
+type PrivateTransferCodec interface {
+	Encode(v interface{}) ([]byte, error)
+	Decode(data []byte) (interface{}, error)
+}
+
+type privateTransferKey struct {
+	vendor  uint32
+	service uint32
+}
+
+var privateTransferCodecs = struct {
+	sync.RWMutex
+	codecs map[privateTransferKey]PrivateTransferCodec
+}{codecs: map[privateTransferKey]PrivateTransferCodec{}}
+
+// RegisterPrivateTransferCodec registers the codec of a proprietary
+// service of a vendor, used by PrivateTransferValue and
+// PrivateTransfer.Decode. A codec registered again replaces the
+// previous one, a nil codec removes it
+func RegisterPrivateTransferCodec(vendor, service uint32, codec PrivateTransferCodec) {
+	privateTransferCodecs.Lock()
+	defer privateTransferCodecs.Unlock()
+	key := privateTransferKey{vendor: vendor, service: service}
+	if codec == nil {
+		delete(privateTransferCodecs.codecs, key)
+		return
+	}
+	privateTransferCodecs.codecs[key] = codec
+}
+
+func lookupPrivateTransferCodec(vendor, service uint32) (PrivateTransferCodec, bool) {
+	privateTransferCodecs.RLock()
+	defer privateTransferCodecs.RUnlock()
+	codec, ok := privateTransferCodecs.codecs[privateTransferKey{vendor: vendor, service: service}]
+	return codec, ok
+}
+
+// PrivateTransfer sends a confirmed private transfer to the device and
+// returns the result block, nil if none. If the device fails, a
+// PrivateTransferError holds the error parameters
+func (c *Client) PrivateTransfer(ctx context.Context, device bacnet.Device, pt PrivateTransfer) ([]byte, error) {
+	apdu, err := c.confirmedRequest(ctx, device, ServiceConfirmedPrivateTransfer, &pt)
+	if err != nil {
+		return nil, err
+	}
+	if apdu.DataType == ComplexAck && apdu.ServiceType == ServiceConfirmedPrivateTransfer {
+		ack := apdu.Payload.(*PrivateTransfer)
+		if ack.VendorID != pt.VendorID || ack.ServiceNumber != pt.ServiceNumber {
+			return nil, fmt.Errorf("acknowledgment of service %d of vendor %d", ack.ServiceNumber, ack.VendorID)
+		}
+		return ack.Parameters, nil
+	}
+	return nil, errors.New("invalid answer")
+}
+
+// PrivateTransferValue sends a confirmed private transfer whose
+// parameters and result are encoded by the codec registered for the
+// service
+func (c *Client) PrivateTransferValue(ctx context.Context, device bacnet.Device, vendor, service uint32, v interface{}) (interface{}, error) {
+	codec, ok := lookupPrivateTransferCodec(vendor, service)
+	if !ok {
+		return nil, fmt.Errorf("no codec for service %d of vendor %d", service, vendor)
+	}
+	params, err := codec.Encode(v)
+	if err != nil {
+		return nil, err
+	}
+	result, err := c.PrivateTransfer(ctx, device, PrivateTransfer{VendorID: vendor, ServiceNumber: service, Parameters: params})
+	if err != nil {
+		return nil, err
+	}
+	return codec.Decode(result)
+}
+
+// UnconfirmedPrivateTransfer sends an unconfirmed private transfer to
+// the device
+func (c *Client) UnconfirmedPrivateTransfer(device bacnet.Device, pt PrivateTransfer) error {
+	_, err := c.send(NPDU{
+		Version:     Version1,
+		Priority:    Normal,
+		Destination: &device.Addr,
+		Source: bacnet.AddressFromUDP(net.UDPAddr{
+			IP:   c.ipAddress,
+			Port: c.udpPort,
+		}),
+		HopCount: 255,
+		ADPU: &APDU{
+			DataType:    UnconfirmedServiceRequest,
+			ServiceType: ServiceUnconfirmedPrivateTransfer,
+			Payload:     &pt,
+		},
+	})
+	return err
+}
@@ -0,0 +1,136 @@
+package bacip
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/REQUEA/bacnet"
+	"github.com/REQUEA/bacnet/internal/encoding"
+	"github.com/matryer/is"
+)
+
+func TestPrivateTransferEncoding(t *testing.T) {
+	is := is.New(t)
+	pt := PrivateTransfer{VendorID: 7, ServiceNumber: 1, Parameters: []byte{0x21, 0x05}}
+	b, err := pt.MarshalBinary()
+	is.NoErr(err)
+	is.Equal(hex.EncodeToString(b), "090719012e21052f")
+	var pt2 PrivateTransfer
+	is.NoErr(pt2.UnmarshalBinary(b))
+	is.Equal(pt2, pt)
+	b, err = PrivateTransfer{VendorID: 7, ServiceNumber: 1}.MarshalBinary()
+	is.NoErr(err)
+	is.Equal(hex.EncodeToString(b), "09071901")
+	is.NoErr(pt2.UnmarshalBinary(b))
+	is.Equal(pt2.Parameters, nil)
+
+	e := PrivateTransferError{
+		Err:           ApduError{Class: bacnet.ServicesError, Code: bacnet.ServiceRequestDenied},
+		VendorID:      7,
+		ServiceNumber: 1,
+		Parameters:    []byte{0x21, 0x02},
+	}
+	b, err = e.MarshalBinary()
+	is.NoErr(err)
+	is.Equal(hex.EncodeToString(b), "0e9105911d0f190729013e21023f")
+	var e2 PrivateTransferError
+	is.NoErr(e2.UnmarshalBinary(b))
+	is.Equal(e2, e)
+	var apduErr ApduError
+	is.True(errors.As(error(e2), &apduErr))
+	is.Equal(apduErr.Code, bacnet.ServiceRequestDenied)
+}
+
+// unsignedCodec encodes the parameters of a test service as a single
+// unsigned
+type unsignedCodec struct{}
+
+func (unsignedCodec) Encode(v interface{}) ([]byte, error) {
+	e := encoding.NewEncoder()
+	e.AppData(v)
+	return e.Bytes(), e.Error()
+}
+
+func (unsignedCodec) Decode(data []byte) (interface{}, error) {
+	d := encoding.NewDecoder(data)
+	var v uint32
+	d.AppData(&v)
+	return v, d.Error()
+}
+
+func TestPrivateTransfer(t *testing.T) {
+	is := is.New(t)
+	const vendor, double, fail = 7, 1, 2
+	RegisterPrivateTransferCodec(vendor, double, unsignedCodec{})
+	defer RegisterPrivateTransferCodec(vendor, double, nil)
+	deviceAddr := net.UDPAddr{IP: net.IPv4(10, 0, 2, 3).To4(), Port: DefaultUDPPort}
+	device := bacnet.Device{
+		ID:   bacnet.ObjectID{Type: bacnet.BacnetDevice, Instance: 3},
+		Addr: *bacnet.AddressFromUDP(deviceAddr),
+	}
+	m := newMemTransport()
+	m.respond = answerRequests(t, func(request *APDU) *APDU {
+		var pt PrivateTransfer
+		is.NoErr(pt.UnmarshalBinary(request.Payload.(*DataPayload).Bytes))
+		answer := &APDU{ServiceType: request.ServiceType, InvokeID: request.InvokeID}
+		if pt.ServiceNumber == fail {
+			answer.DataType = Error
+			answer.Payload = &PrivateTransferError{
+				Err:           ApduError{Class: bacnet.ServicesError, Code: bacnet.ServiceRequestDenied},
+				VendorID:      pt.VendorID,
+				ServiceNumber: pt.ServiceNumber,
+			}
+		} else {
+			v, err := pt.Decode()
+			is.NoErr(err)
+			pt.Parameters, err = unsignedCodec{}.Encode(v.(uint32) * 2)
+			is.NoErr(err)
+			answer.DataType = ComplexAck
+			answer.Payload = &pt
+		}
+		return answer
+	})
+	c := newMemClient(t, m)
+
+	v, err := c.PrivateTransferValue(context.Background(), device, vendor, double, uint32(21))
+	is.NoErr(err)
+	is.Equal(v, uint32(42))
+
+	_, err = c.PrivateTransfer(context.Background(), device, PrivateTransfer{VendorID: vendor, ServiceNumber: fail})
+	var ptErr PrivateTransferError
+	is.True(errors.As(err, &ptErr))
+	is.Equal(ptErr.ServiceNumber, uint32(fail))
+
+	_, err = c.PrivateTransferValue(context.Background(), device, vendor, 3, nil)
+	is.True(err != nil)
+
+	//Unconfirmed private transfers are decoded when received
+	received := make(chan PrivateTransfer, 1)
+	unsubscribe := c.subscriptions.subscribe(func(bvlc BVLC, _ net.UDPAddr) {
+		if apdu := bvlc.NPDU.ADPU; apdu != nil {
+			if pt, ok := apdu.Payload.(*PrivateTransfer); ok {
+				received <- *pt
+			}
+		}
+	})
+	defer unsubscribe()
+	pt := PrivateTransfer{VendorID: vendor, ServiceNumber: double, Parameters: []byte{0x21, 0x05}}
+	is.NoErr(c.UnconfirmedPrivateTransfer(device, pt))
+	m.Lock()
+	sent := m.written[len(m.written)-1]
+	m.Unlock()
+	m.in <- datagram{data: sent, addr: &deviceAddr}
+	select {
+	case got := <-received:
+		is.Equal(got, pt)
+		v, err := got.Decode()
+		is.NoErr(err)
+		is.Equal(v, uint32(5))
+	case <-time.After(time.Second):
+		t.Fatal("private transfer not received")
+	}
+}
@@ -0,0 +1,254 @@
+package bacip
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/REQUEA/bacnet"
+)
+
+// DeviceProfile tunes how the client talks to a device. Devices behind
+// an MS/TP router are much slower than IP devices and are easily
+// overwhelmed. The zero value doesn't limit anything
+type DeviceProfile struct {
+	//MaxConcurrentRequests is the maximum number of confirmed
+	//requests waiting for an answer from the device, 0 for no limit
+	MaxConcurrentRequests int
+	//RequestDelay is the minimum delay between two requests sent to
+	//the device
+	RequestDelay time.Duration
+	//Timeout is the APDU timeout: the time to wait for the answer
+	//before sending the request again or giving up. It applies in
+	//addition to the context deadline. 0 relies on the context only
+	Timeout time.Duration
+	//Retries is the number of times a request is sent again when
+	//the device doesn't answer before the Timeout
+	Retries int
+	//AdaptiveTimeout derives the APDU timeout from the round trip
+	//times measured on the device, bounded by MinTimeout and
+	//MaxTimeout. Timeout is used until the first answer
+	AdaptiveTimeout bool
+	MinTimeout      time.Duration
+	MaxTimeout      time.Duration
+	//DisableReadPropertyMultiple makes the helpers use ReadProperty
+	//even if the device claims to support ReadPropertyMultiple
+	DisableReadPropertyMultiple bool
+	//MaxReadPropertyMultiple caps the number of properties read by a
+	//single ReadPropertyMultiple request, 0 for no cap
+	MaxReadPropertyMultiple int
+	//NoArrayIndex reads whole arrays when a single element is
+	//requested, for devices that don't handle array indexes. Only
+	//arrays of application values are supported
+	NoArrayIndex bool
+	//MaxApdu overrides the maximum APDU length announced by the
+	//device if not 0
+	MaxApdu uint32
+	//Segmentation overrides the segmentation support announced by
+	//the device, for devices that fail segmented transfers
+	Segmentation *bacnet.SegmentationSupport
+}
+
+// apply returns the device with the overridden capabilities
+func (p DeviceProfile) apply(device bacnet.Device) bacnet.Device {
+	if p.MaxApdu != 0 {
+		device.MaxApdu = p.MaxApdu
+	}
+	if p.Segmentation != nil {
+		device.Segmentation = *p.Segmentation
+	}
+	return device
+}
+
+// pacer enforces the concurrency and the delay between requests of a
+// profile
+type pacer struct {
+	profile DeviceProfile
+	//isDefault is true if the profile comes from the default one
+	isDefault bool
+	slots     chan struct{}
+	sync.Mutex
+	next time.Time
+	rtt  rttEstimator
+}
+
+// rttEstimator smooths the round trip times of a device, as TCP does
+// (RFC 6298)
+type rttEstimator struct {
+	srtt    time.Duration
+	rttvar  time.Duration
+	samples int
+}
+
+func (e *rttEstimator) add(rtt time.Duration) {
+	if e.samples == 0 {
+		e.srtt = rtt
+		e.rttvar = rtt / 2
+	} else {
+		diff := e.srtt - rtt
+		if diff < 0 {
+			diff = -diff
+		}
+		e.rttvar = (3*e.rttvar + diff) / 4
+		e.srtt = (7*e.srtt + rtt) / 8
+	}
+	e.samples++
+}
+
+// timeout returns the time after which an answer is considered lost
+func (e *rttEstimator) timeout() time.Duration {
+	return e.srtt + 4*e.rttvar
+}
+
+// timeout returns the APDU timeout to use for the next request
+func (pc *pacer) timeout() time.Duration {
+	p := pc.profile
+	if !p.AdaptiveTimeout {
+		return p.Timeout
+	}
+	pc.Lock()
+	t, ok := pc.rtt.timeout(), pc.rtt.samples > 0
+	pc.Unlock()
+	if !ok {
+		t = p.Timeout
+	}
+	if p.MinTimeout > 0 && t < p.MinTimeout {
+		t = p.MinTimeout
+	}
+	if p.MaxTimeout > 0 && t > p.MaxTimeout {
+		t = p.MaxTimeout
+	}
+	return t
+}
+
+// sample records the round trip time of a request answered at the
+// first attempt. The answers to retried requests are ignored, as it
+// isn't known which attempt they answer
+func (pc *pacer) sample(rtt time.Duration) {
+	pc.Lock()
+	defer pc.Unlock()
+	pc.rtt.add(rtt)
+}
+
+func newPacer(p DeviceProfile) *pacer {
+	pc := &pacer{profile: p}
+	if p.MaxConcurrentRequests > 0 {
+		pc.slots = make(chan struct{}, p.MaxConcurrentRequests)
+	}
+	return pc
+}
+
+// acquire waits until a request can be sent, and returns the function
+// to call once the answer is received
+func (pc *pacer) acquire(ctx context.Context) (func(), error) {
+	if pc.slots != nil {
+		select {
+		case pc.slots <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	release := func() {
+		if pc.slots != nil {
+			<-pc.slots
+		}
+	}
+	if pc.profile.RequestDelay <= 0 {
+		return release, nil
+	}
+	pc.Lock()
+	now := time.Now()
+	at := pc.next
+	if at.Before(now) {
+		at = now
+	}
+	pc.next = at.Add(pc.profile.RequestDelay)
+	pc.Unlock()
+	if wait := time.Until(at); wait > 0 {
+		t := time.NewTimer(wait)
+		defer t.Stop()
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			release()
+			return nil, ctx.Err()
+		}
+	}
+	return release, nil
+}
+
+type profiles struct {
+	sync.RWMutex
+	byDevice map[bacnet.ObjectID]*pacer
+	fallback DeviceProfile
+}
+
+// SetDeviceProfile sets the profile used for the requests sent to the
+// device
+func (c *Client) SetDeviceProfile(device bacnet.ObjectID, p DeviceProfile) {
+	c.profiles.Lock()
+	defer c.profiles.Unlock()
+	if c.profiles.byDevice == nil {
+		c.profiles.byDevice = map[bacnet.ObjectID]*pacer{}
+	}
+	c.profiles.byDevice[device] = newPacer(p)
+}
+
+// RemoveDeviceProfile makes the device use the default profile again
+func (c *Client) RemoveDeviceProfile(device bacnet.ObjectID) {
+	c.profiles.Lock()
+	defer c.profiles.Unlock()
+	delete(c.profiles.byDevice, device)
+}
+
+// SetDefaultProfile sets the profile of the devices without a profile
+// of their own. The limits apply to each device separately
+func (c *Client) SetDefaultProfile(p DeviceProfile) {
+	c.profiles.Lock()
+	defer c.profiles.Unlock()
+	c.profiles.fallback = p
+	//The pacers of the devices using the default profile are created
+	//lazily from the fallback
+	for id, pc := range c.profiles.byDevice {
+		if pc.isDefault {
+			delete(c.profiles.byDevice, id)
+		}
+	}
+}
+
+// RoundTripTime returns the smoothed round trip time of the requests
+// sent to the device, and false if it didn't answer any request yet
+func (c *Client) RoundTripTime(device bacnet.ObjectID) (time.Duration, bool) {
+	pc := c.pacer(device)
+	pc.Lock()
+	defer pc.Unlock()
+	return pc.rtt.srtt, pc.rtt.samples > 0
+}
+
+// DeviceProfile returns the profile used for the device
+func (c *Client) DeviceProfile(device bacnet.ObjectID) DeviceProfile {
+	return c.pacer(device).profile
+}
+
+// pacer returns the pacer of the device, creating it from the default
+// profile if needed
+func (c *Client) pacer(device bacnet.ObjectID) *pacer {
+	c.profiles.RLock()
+	pc, ok := c.profiles.byDevice[device]
+	c.profiles.RUnlock()
+	if ok {
+		return pc
+	}
+	c.profiles.Lock()
+	defer c.profiles.Unlock()
+	if pc, ok := c.profiles.byDevice[device]; ok {
+		return pc
+	}
+	pc = newPacer(c.profiles.fallback)
+	pc.isDefault = true
+	if c.profiles.byDevice == nil {
+		c.profiles.byDevice = map[bacnet.ObjectID]*pacer{}
+	}
+	c.profiles.byDevice[device] = pc
+	return pc
+}
@@ -0,0 +1,88 @@
+package bacip
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/REQUEA/bacnet"
+
+	"github.com/matryer/is"
+)
+
+func TestPacerConcurrency(t *testing.T) {
+	is := is.New(t)
+	pc := newPacer(DeviceProfile{MaxConcurrentRequests: 1})
+	release, err := pc.acquire(context.Background())
+	is.NoErr(err)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = pc.acquire(ctx)
+	is.Equal(err, context.DeadlineExceeded)
+	release()
+	release, err = pc.acquire(context.Background())
+	is.NoErr(err)
+	release()
+}
+
+func TestPacerDelay(t *testing.T) {
+	is := is.New(t)
+	pc := newPacer(DeviceProfile{RequestDelay: 20 * time.Millisecond})
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		release, err := pc.acquire(context.Background())
+		is.NoErr(err)
+		release()
+	}
+	is.True(time.Since(start) >= 40*time.Millisecond)
+}
+
+func TestDeviceProfiles(t *testing.T) {
+	is := is.New(t)
+	c := &Client{}
+	slow := bacnet.ObjectID{Type: bacnet.BacnetDevice, Instance: 1}
+	fast := bacnet.ObjectID{Type: bacnet.BacnetDevice, Instance: 2}
+	c.SetDeviceProfile(slow, DeviceProfile{MaxConcurrentRequests: 1})
+	is.Equal(c.DeviceProfile(slow).MaxConcurrentRequests, 1)
+	is.Equal(c.DeviceProfile(fast), DeviceProfile{})
+	c.SetDefaultProfile(DeviceProfile{Timeout: time.Second})
+	is.Equal(c.DeviceProfile(fast).Timeout, time.Second)
+	is.Equal(c.DeviceProfile(slow).Timeout, time.Duration(0))
+	c.RemoveDeviceProfile(slow)
+	is.Equal(c.DeviceProfile(slow).Timeout, time.Second)
+
+	seg := bacnet.SegmentationSupportNone
+	d := DeviceProfile{MaxApdu: 206, Segmentation: &seg}.apply(bacnet.Device{MaxApdu: 1476})
+	is.Equal(d.MaxApdu, uint32(206))
+	is.Equal(d.Segmentation, bacnet.SegmentationSupportNone)
+}
+
+func TestAdaptiveTimeout(t *testing.T) {
+	is := is.New(t)
+	pc := newPacer(DeviceProfile{
+		Timeout:         3 * time.Second,
+		AdaptiveTimeout: true,
+		MinTimeout:      100 * time.Millisecond,
+		MaxTimeout:      10 * time.Second,
+	})
+	is.Equal(pc.timeout(), 3*time.Second)
+	for i := 0; i < 20; i++ {
+		pc.sample(10 * time.Millisecond)
+	}
+	//Fast device: the floor applies
+	is.Equal(pc.timeout(), 100*time.Millisecond)
+	pc.sample(time.Minute)
+	is.Equal(pc.timeout(), 10*time.Second)
+
+	pc = newPacer(DeviceProfile{Timeout: time.Second})
+	pc.sample(10 * time.Millisecond)
+	is.Equal(pc.timeout(), time.Second)
+
+	var e rttEstimator
+	e.add(200 * time.Millisecond)
+	is.Equal(e.srtt, 200*time.Millisecond)
+	is.Equal(e.timeout(), 600*time.Millisecond)
+	e.add(200 * time.Millisecond)
+	is.Equal(e.srtt, 200*time.Millisecond)
+	is.Equal(e.rttvar, 75*time.Millisecond)
+}
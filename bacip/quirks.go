@@ -0,0 +1,89 @@
+package bacip
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/REQUEA/bacnet"
+)
+
+// QuirksRegistry maps vendor identifiers and model names to the
+// profiles working around the bugs of these devices
+type QuirksRegistry struct {
+	sync.RWMutex
+	profiles map[quirksKey]DeviceProfile
+}
+
+type quirksKey struct {
+	vendor uint32
+	model  string
+}
+
+// KnownQuirks is the registry used by ApplyQuirks
+var KnownQuirks = &QuirksRegistry{}
+
+// Register sets the profile of the devices of the vendor with the
+// given model name. An empty model matches all the models of the
+// vendor without a profile of their own
+func (r *QuirksRegistry) Register(vendor uint32, model string, p DeviceProfile) {
+	r.Lock()
+	defer r.Unlock()
+	if r.profiles == nil {
+		r.profiles = map[quirksKey]DeviceProfile{}
+	}
+	r.profiles[quirksKey{vendor: vendor, model: model}] = p
+}
+
+// Lookup returns the profile registered for the vendor and model
+func (r *QuirksRegistry) Lookup(vendor uint32, model string) (DeviceProfile, bool) {
+	r.RLock()
+	defer r.RUnlock()
+	if p, ok := r.profiles[quirksKey{vendor: vendor, model: model}]; ok {
+		return p, true
+	}
+	p, ok := r.profiles[quirksKey{vendor: vendor}]
+	return p, ok
+}
+
+// ApplyQuirks reads the model name of the device and sets its profile
+// if one is registered in KnownQuirks for its vendor and model. It
+// returns whether a profile was found. The device must have been
+// discovered with WhoIs so that its vendor is known
+func (c *Client) ApplyQuirks(ctx context.Context, device bacnet.Device) (bool, error) {
+	model, err := c.ReadProperty(ctx, device, ReadProperty{
+		ObjectID: device.ID,
+		Property: bacnet.PropertyIdentifier{Type: bacnet.ModelName},
+	})
+	if err != nil && !isUnknownProperty(err) {
+		return false, fmt.Errorf("read model name: %w", err)
+	}
+	name, _ := model.(string)
+	p, ok := KnownQuirks.Lookup(device.Vendor, name)
+	if ok {
+		c.SetDeviceProfile(device.ID, p)
+	}
+	return ok, nil
+}
+
+// readWholeArray answers a read of an array element by reading the
+// whole array, for devices that don't support array indexes
+func (c *Client) readWholeArray(ctx context.Context, device bacnet.Device, readProp ReadProperty) (interface{}, error) {
+	index := *readProp.Property.ArrayIndex
+	readProp.Property.ArrayIndex = nil
+	data, err := c.ReadProperty(ctx, device, readProp)
+	if err != nil {
+		return nil, err
+	}
+	values, ok := data.([]interface{})
+	if !ok {
+		values = []interface{}{data}
+	}
+	if index == 0 {
+		return uint32(len(values)), nil
+	}
+	if index > uint32(len(values)) {
+		return nil, ApduError{Class: bacnet.PropertyError, Code: bacnet.InvalidArrayIndex}
+	}
+	return values[index-1], nil
+}
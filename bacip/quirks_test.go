@@ -0,0 +1,24 @@
+package bacip
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestQuirksRegistry(t *testing.T) {
+	is := is.New(t)
+	r := &QuirksRegistry{}
+	_, ok := r.Lookup(5, "X")
+	is.True(!ok)
+	r.Register(5, "", DeviceProfile{MaxReadPropertyMultiple: 10})
+	r.Register(5, "Old", DeviceProfile{NoArrayIndex: true})
+	p, ok := r.Lookup(5, "Old")
+	is.True(ok)
+	is.Equal(p, DeviceProfile{NoArrayIndex: true})
+	p, ok = r.Lookup(5, "New")
+	is.True(ok)
+	is.Equal(p, DeviceProfile{MaxReadPropertyMultiple: 10})
+	_, ok = r.Lookup(6, "Old")
+	is.True(!ok)
+}
@@ -0,0 +1,140 @@
+package bacip
+
+import (
+	"fmt"
+
+	"github.com/REQUEA/bacnet"
+	"github.com/REQUEA/bacnet/internal/encoding"
+)
+
+// ReadAccessSpecification lists properties to read from an object
+type ReadAccessSpecification struct {
+	ObjectID   bacnet.ObjectID
+	Properties []bacnet.PropertyIdentifier
+}
+
+func (s ReadAccessSpecification) encode(e *encoding.Encoder) {
+	e.ContextObjectID(0, s.ObjectID)
+	e.OpeningTag(1)
+	for _, p := range s.Properties {
+		e.ContextUnsigned(0, uint32(p.Type))
+		if p.ArrayIndex != nil {
+			e.ContextUnsigned(1, *p.ArrayIndex)
+		}
+	}
+	e.ClosingTag(1)
+}
+
+func (s *ReadAccessSpecification) decode(d *encoding.Decoder) {
+	d.ContextObjectID(0, &s.ObjectID)
+	d.OpeningTag(1)
+	s.Properties = []bacnet.PropertyIdentifier{}
+	for d.Error() == nil && !d.IsClosingTag(1) {
+		var val uint32
+		p := bacnet.PropertyIdentifier{}
+		d.ContextValue(0, &val)
+		p.Type = bacnet.PropertyType(val)
+		if d.IsContextTag(1) {
+			p.ArrayIndex = new(uint32)
+			d.ContextValue(1, p.ArrayIndex)
+		}
+		s.Properties = append(s.Properties, p)
+	}
+	d.ClosingTag(1)
+}
+
+// PropertyResult is the result of the read of a single property. Either
+// Value or Error is set
+type PropertyResult struct {
+	Property bacnet.PropertyIdentifier
+	//Value is decoded like ReadProperty.Data
+	Value interface{}
+	Error *ApduError
+}
+
+// ReadAccessResult contains the results of the read of the properties
+// of an object
+type ReadAccessResult struct {
+	ObjectID bacnet.ObjectID
+	Results  []PropertyResult
+}
+
+func (r ReadAccessResult) encode(e *encoding.Encoder) {
+	e.ContextObjectID(0, r.ObjectID)
+	e.OpeningTag(1)
+	for _, res := range r.Results {
+		e.ContextUnsigned(2, uint32(res.Property.Type))
+		if res.Property.ArrayIndex != nil {
+			e.ContextUnsigned(3, *res.Property.ArrayIndex)
+		}
+		encodeResult(e, 4, res.Value, res.Error)
+	}
+	e.ClosingTag(1)
+}
+
+func (r *ReadAccessResult) decode(d *encoding.Decoder) {
+	d.ContextObjectID(0, &r.ObjectID)
+	r.Results = []PropertyResult{}
+	if !d.IsOpeningTag(1) {
+		//The list of results is optional
+		return
+	}
+	d.OpeningTag(1)
+	for d.Error() == nil && !d.IsClosingTag(1) {
+		var val uint32
+		res := PropertyResult{}
+		d.ContextValue(2, &val)
+		res.Property.Type = bacnet.PropertyType(val)
+		if d.IsContextTag(3) {
+			res.Property.ArrayIndex = new(uint32)
+			d.ContextValue(3, res.Property.ArrayIndex)
+		}
+		res.Value, res.Error = decodeResult(d, 4)
+		r.Results = append(r.Results, res)
+	}
+	d.ClosingTag(1)
+}
+
+// encodeResult writes the value in the valueTag constructed tag, or
+// the error in the following one if err isn't nil
+func encodeResult(e *encoding.Encoder, valueTag byte, value interface{}, err *ApduError) {
+	if err != nil {
+		e.OpeningTag(valueTag + 1)
+		err.encode(e)
+		e.ClosingTag(valueTag + 1)
+		return
+	}
+	e.ContextAbstractType(valueTag, bacnet.PropertyValue{Value: value})
+}
+
+// decodeResult is the counterpart of encodeResult
+func decodeResult(d *encoding.Decoder, valueTag byte) (interface{}, *ApduError) {
+	if d.IsOpeningTag(valueTag + 1) {
+		apduErr := &ApduError{}
+		d.OpeningTag(valueTag + 1)
+		apduErr.decode(d)
+		d.ClosingTag(valueTag + 1)
+		return nil, apduErr
+	}
+	var raw []byte
+	d.ContextRaw(valueTag, &raw)
+	if d.Error() != nil {
+		return nil, nil
+	}
+	return decodeValue(raw), nil
+}
+
+// decodeReadAccessResults decodes a list of ReadAccessResult
+func decodeReadAccessResults(data []byte) ([]ReadAccessResult, error) {
+	results := []ReadAccessResult{}
+	err := decodeList(data, func(d *encoding.Decoder) error {
+		r := ReadAccessResult{}
+		r.decode(d)
+		results = append(results, r)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("decode read access results: %w", err)
+	}
+	return results, nil
+}
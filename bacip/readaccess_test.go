@@ -0,0 +1,99 @@
+package bacip
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/REQUEA/bacnet"
+	"github.com/REQUEA/bacnet/internal/encoding"
+
+	"github.com/matryer/is"
+)
+
+func TestReadAccessSpecificationCoherency(t *testing.T) {
+	is := is.New(t)
+	index := uint32(1)
+	s := ReadAccessSpecification{
+		ObjectID: bacnet.ObjectID{Type: bacnet.AnalogInput, Instance: 1},
+		Properties: []bacnet.PropertyIdentifier{
+			{Type: bacnet.PresentValue},
+			{Type: bacnet.PriorityArray, ArrayIndex: &index},
+		},
+	}
+	e := encoding.NewEncoder()
+	s.encode(&e)
+	is.NoErr(e.Error())
+	is.Equal(hex.EncodeToString(e.Bytes()), "0c000000011e0955095719011f")
+	s2 := ReadAccessSpecification{}
+	d := encoding.NewDecoder(e.Bytes())
+	s2.decode(d)
+	is.NoErr(d.Error())
+	is.Equal(s2, s)
+}
+
+func TestReadAccessResultCoherency(t *testing.T) {
+	is := is.New(t)
+	r := ReadAccessResult{
+		ObjectID: bacnet.ObjectID{Type: bacnet.AnalogInput, Instance: 1},
+		Results: []PropertyResult{
+			{Property: bacnet.PropertyIdentifier{Type: bacnet.PresentValue}, Value: float32(21)},
+			{
+				Property: bacnet.PropertyIdentifier{Type: bacnet.Description},
+				Error:    &ApduError{Class: bacnet.PropertyError, Code: bacnet.UnknownProperty},
+			},
+		},
+	}
+	e := encoding.NewEncoder()
+	r.encode(&e)
+	is.NoErr(e.Error())
+	expected := "0c000000011e29554e4441a800004f291c5e910291205f1f"
+	is.Equal(hex.EncodeToString(e.Bytes()), expected)
+	results, err := decodeReadAccessResults(e.Bytes())
+	is.NoErr(err)
+	is.Equal(results, []ReadAccessResult{r})
+}
+
+func TestSplitSpecifications(t *testing.T) {
+	is := is.New(t)
+	ai := bacnet.ObjectID{Type: bacnet.AnalogInput, Instance: 1}
+	av := bacnet.ObjectID{Type: bacnet.AnalogValue, Instance: 2}
+	props := func(types ...bacnet.PropertyType) []bacnet.PropertyIdentifier {
+		ids := []bacnet.PropertyIdentifier{}
+		for _, t := range types {
+			ids = append(ids, bacnet.PropertyIdentifier{Type: t})
+		}
+		return ids
+	}
+	specs := []ReadAccessSpecification{
+		{ObjectID: ai, Properties: props(bacnet.PresentValue, bacnet.StatusFlags, bacnet.Units)},
+		{ObjectID: av, Properties: props(bacnet.PresentValue)},
+	}
+	is.Equal(splitSpecifications(specs, 0), []rpmChunk{{specs: specs}})
+	is.Equal(splitSpecifications(specs, 2), []rpmChunk{
+		{specs: []ReadAccessSpecification{{ObjectID: ai, Properties: props(bacnet.PresentValue, bacnet.StatusFlags)}}},
+		{
+			specs: []ReadAccessSpecification{
+				{ObjectID: ai, Properties: props(bacnet.Units)},
+				{ObjectID: av, Properties: props(bacnet.PresentValue)},
+			},
+			continued: true,
+		},
+	})
+	is.Equal(len(splitSpecifications(specs, 1)), 4)
+}
+
+func TestReadPropertyMultipleEncoding(t *testing.T) {
+	is := is.New(t)
+	rpm := ReadPropertyMultiple{Specifications: []ReadAccessSpecification{{
+		ObjectID:   bacnet.ObjectID{Type: bacnet.AnalogInput, Instance: 1},
+		Properties: []bacnet.PropertyIdentifier{{Type: bacnet.PresentValue}},
+	}}}
+	b, err := rpm.MarshalBinary()
+	is.NoErr(err)
+	is.Equal(hex.EncodeToString(b), "0c000000011e09551f")
+	ack := ReadPropertyMultiple{}
+	raw, _ := hex.DecodeString("0c000000011e29554e4441a800004f1f")
+	is.NoErr(ack.UnmarshalBinary(raw))
+	is.Equal(len(ack.Results), 1)
+	is.Equal(ack.Results[0].Results[0].Value, float32(21))
+}
@@ -0,0 +1,175 @@
+package bacip
+
+import (
+	"sync"
+	"time"
+
+	"github.com/REQUEA/bacnet"
+)
+
+// DefaultCachedProperties are the properties cached by a ReadCache
+// created without a list of properties. They rarely change once a
+// device is commissioned
+var DefaultCachedProperties = []bacnet.PropertyType{
+	bacnet.ObjectName,
+	bacnet.Description,
+	bacnet.Units,
+	bacnet.StateText,
+	bacnet.ActiveText,
+	bacnet.InactiveText,
+}
+
+// ReadCache keeps the values of slowly changing properties read by
+// ReadProperty, so that repeated reads don't hit the network. Entries
+// expire after the TTL, and are removed when the property is written
+// by the client. The properties of a device are removed when it sends
+// a restart notification; call InvalidateDevice when a device is
+// reconfigured.
+type ReadCache struct {
+	sync.Mutex
+	ttl        time.Duration
+	properties map[bacnet.PropertyType]struct{}
+	entries    map[cacheKey]cacheEntry
+	pruneAt    int
+	now        func() time.Time
+}
+
+type cacheKey struct {
+	device   bacnet.ObjectID
+	object   bacnet.ObjectID
+	property bacnet.PropertyType
+	//index is the array index plus one, 0 when there is no index
+	index uint64
+}
+
+type cacheEntry struct {
+	value   interface{}
+	expires time.Time
+}
+
+const minPruneSize = 256
+
+// NewReadCache returns a cache keeping the values of the given
+// properties for ttl, or of DefaultCachedProperties if none is given
+func NewReadCache(ttl time.Duration, properties ...bacnet.PropertyType) *ReadCache {
+	if len(properties) == 0 {
+		properties = DefaultCachedProperties
+	}
+	rc := &ReadCache{
+		ttl:        ttl,
+		properties: map[bacnet.PropertyType]struct{}{},
+		entries:    map[cacheKey]cacheEntry{},
+		pruneAt:    minPruneSize,
+		now:        time.Now,
+	}
+	for _, p := range properties {
+		rc.properties[p] = struct{}{}
+	}
+	return rc
+}
+
+// key returns the key of the property and whether it is cached
+func (rc *ReadCache) key(device bacnet.ObjectID, object bacnet.ObjectID, prop bacnet.PropertyIdentifier) (cacheKey, bool) {
+	if _, ok := rc.properties[prop.Type]; !ok {
+		return cacheKey{}, false
+	}
+	k := cacheKey{device: device, object: object, property: prop.Type}
+	if prop.ArrayIndex != nil {
+		k.index = uint64(*prop.ArrayIndex) + 1
+	}
+	return k, true
+}
+
+func (rc *ReadCache) get(device bacnet.ObjectID, object bacnet.ObjectID, prop bacnet.PropertyIdentifier) (interface{}, bool) {
+	rc.Lock()
+	defer rc.Unlock()
+	k, ok := rc.key(device, object, prop)
+	if !ok {
+		return nil, false
+	}
+	e, ok := rc.entries[k]
+	if !ok {
+		return nil, false
+	}
+	if !rc.now().Before(e.expires) {
+		delete(rc.entries, k)
+		return nil, false
+	}
+	return e.value, true
+}
+
+func (rc *ReadCache) put(device bacnet.ObjectID, object bacnet.ObjectID, prop bacnet.PropertyIdentifier, value interface{}) {
+	rc.Lock()
+	defer rc.Unlock()
+	k, ok := rc.key(device, object, prop)
+	if !ok {
+		return
+	}
+	now := rc.now()
+	if len(rc.entries) >= rc.pruneAt {
+		for k, e := range rc.entries {
+			if !now.Before(e.expires) {
+				delete(rc.entries, k)
+			}
+		}
+		rc.pruneAt = 2 * len(rc.entries)
+		if rc.pruneAt < minPruneSize {
+			rc.pruneAt = minPruneSize
+		}
+	}
+	rc.entries[k] = cacheEntry{value: value, expires: now.Add(rc.ttl)}
+}
+
+// invalidateProperty removes all the entries of the property,
+// whatever their array index
+func (rc *ReadCache) invalidateProperty(device bacnet.ObjectID, object bacnet.ObjectID, prop bacnet.PropertyType) {
+	rc.Lock()
+	defer rc.Unlock()
+	for k := range rc.entries {
+		if k.device == device && k.object == object && k.property == prop {
+			delete(rc.entries, k)
+		}
+	}
+}
+
+// InvalidateObject removes the cached properties of an object
+func (rc *ReadCache) InvalidateObject(device bacnet.ObjectID, object bacnet.ObjectID) {
+	rc.Lock()
+	defer rc.Unlock()
+	for k := range rc.entries {
+		if k.device == device && k.object == object {
+			delete(rc.entries, k)
+		}
+	}
+}
+
+// InvalidateDevice removes the cached properties of all the objects
+// of a device. It's called by the client when the device sends a
+// restart notification
+func (rc *ReadCache) InvalidateDevice(device bacnet.ObjectID) {
+	rc.Lock()
+	defer rc.Unlock()
+	for k := range rc.entries {
+		if k.device == device {
+			delete(rc.entries, k)
+		}
+	}
+}
+
+// Clear removes all the entries of the cache
+func (rc *ReadCache) Clear() {
+	rc.Lock()
+	defer rc.Unlock()
+	rc.entries = map[cacheKey]cacheEntry{}
+}
+
+// SetReadCache makes ReadProperty use the cache. A nil cache disables
+// caching
+func (c *Client) SetReadCache(rc *ReadCache) {
+	c.readCache.Store(rc)
+}
+
+func (c *Client) cache() *ReadCache {
+	rc, _ := c.readCache.Load().(*ReadCache)
+	return rc
+}
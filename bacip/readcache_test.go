@@ -0,0 +1,50 @@
+package bacip
+
+import (
+	"testing"
+	"time"
+
+	"github.com/REQUEA/bacnet"
+
+	"github.com/matryer/is"
+)
+
+func TestReadCache(t *testing.T) {
+	is := is.New(t)
+	now := time.Now()
+	rc := NewReadCache(time.Minute)
+	rc.now = func() time.Time { return now }
+	dev := bacnet.ObjectID{Type: bacnet.BacnetDevice, Instance: 1}
+	ai := bacnet.ObjectID{Type: bacnet.AnalogInput, Instance: 1}
+	name := bacnet.PropertyIdentifier{Type: bacnet.ObjectName}
+	pv := bacnet.PropertyIdentifier{Type: bacnet.PresentValue}
+	index := uint32(1)
+	stateText1 := bacnet.PropertyIdentifier{Type: bacnet.StateText, ArrayIndex: &index}
+	stateText := bacnet.PropertyIdentifier{Type: bacnet.StateText}
+
+	rc.put(dev, ai, name, "OAT")
+	rc.put(dev, ai, pv, float32(1))
+	rc.put(dev, ai, stateText1, "Off")
+	v, ok := rc.get(dev, ai, name)
+	is.True(ok)
+	is.Equal(v, "OAT")
+	_, ok = rc.get(dev, ai, pv)
+	is.True(!ok) //present value isn't cached
+	_, ok = rc.get(dev, ai, stateText)
+	is.True(!ok) //different index
+	_, ok = rc.get(dev, ai, stateText1)
+	is.True(ok)
+
+	rc.invalidateProperty(dev, ai, bacnet.StateText)
+	_, ok = rc.get(dev, ai, stateText1)
+	is.True(!ok)
+
+	now = now.Add(2 * time.Minute)
+	_, ok = rc.get(dev, ai, name)
+	is.True(!ok) //expired
+
+	rc.put(dev, ai, name, "OAT")
+	rc.InvalidateDevice(dev)
+	_, ok = rc.get(dev, ai, name)
+	is.True(!ok)
+}
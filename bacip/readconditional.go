@@ -0,0 +1,110 @@
+package bacip
+
+import (
+	"context"
+	"errors"
+
+	"github.com/REQUEA/bacnet"
+	"github.com/REQUEA/bacnet/internal/encoding"
+)
+
+// SelectionLogic tells how the selection criteria of a
+// ReadPropertyConditional request are combined
+type SelectionLogic uint32
+
+const (
+	SelectionLogicAnd SelectionLogic = 0
+	SelectionLogicOr  SelectionLogic = 1
+	//SelectionLogicAll selects all the objects, without criteria
+	SelectionLogicAll SelectionLogic = 2
+)
+
+// RelationSpecifier is the comparison of a selection criterion
+type RelationSpecifier uint32
+
+const (
+	RelationEqual              RelationSpecifier = 0
+	RelationNotEqual           RelationSpecifier = 1
+	RelationLessThan           RelationSpecifier = 2
+	RelationGreaterThan        RelationSpecifier = 3
+	RelationLessThanOrEqual    RelationSpecifier = 4
+	RelationGreaterThanOrEqual RelationSpecifier = 5
+)
+
+// SelectionCriterion selects the objects whose property compares to
+// the value
+type SelectionCriterion struct {
+	Property bacnet.PropertyIdentifier
+	Relation RelationSpecifier
+	Value    bacnet.PropertyValue
+}
+
+// ReadPropertyConditional reads the properties of the objects of a
+// device matching selection criteria. This service is deprecated, but
+// still used by older devices. Properties are the properties read from
+// each selected object, only their object identifier if empty. Results
+// contains the response
+type ReadPropertyConditional struct {
+	Logic      SelectionLogic
+	Criteria   []SelectionCriterion
+	Properties []bacnet.PropertyIdentifier
+
+	Results []ReadAccessResult
+}
+
+func (r ReadPropertyConditional) MarshalBinary() ([]byte, error) {
+	encoder := encoding.NewEncoder()
+	encoder.OpeningTag(0)
+	encoder.ContextUnsigned(0, uint32(r.Logic))
+	if len(r.Criteria) > 0 {
+		encoder.OpeningTag(1)
+		for _, c := range r.Criteria {
+			encoder.ContextUnsigned(0, uint32(c.Property.Type))
+			if c.Property.ArrayIndex != nil {
+				encoder.ContextUnsigned(1, *c.Property.ArrayIndex)
+			}
+			encoder.ContextUnsigned(2, uint32(c.Relation))
+			encoder.ContextAbstractType(3, c.Value)
+		}
+		encoder.ClosingTag(1)
+	}
+	encoder.ClosingTag(0)
+	if len(r.Properties) > 0 {
+		encoder.OpeningTag(1)
+		for _, p := range r.Properties {
+			encoder.ContextUnsigned(0, uint32(p.Type))
+			if p.ArrayIndex != nil {
+				encoder.ContextUnsigned(1, *p.ArrayIndex)
+			}
+		}
+		encoder.ClosingTag(1)
+	}
+	return encoder.Bytes(), encoder.Error()
+}
+
+func (r *ReadPropertyConditional) UnmarshalBinary(data []byte) error {
+	results, err := decodeReadAccessResults(data)
+	if err != nil {
+		return err
+	}
+	r.Results = results
+	return nil
+}
+
+// ReadPropertyConditional reads the properties of the objects of the
+// device selected by the request. The errors of the reads of single
+// properties are returned in their PropertyResult
+func (c *Client) ReadPropertyConditional(ctx context.Context, device bacnet.Device, r ReadPropertyConditional) ([]ReadAccessResult, error) {
+	if r.Logic != SelectionLogicAll && len(r.Criteria) == 0 {
+		return nil, errors.New("selection criteria required")
+	}
+	apdu, err := c.confirmedRequest(ctx, device, ServiceConfirmedReadPropConditional, &r)
+	if err != nil {
+		return nil, err
+	}
+	answer, ok := apdu.Payload.(*ReadPropertyConditional)
+	if !ok {
+		return nil, errors.New("invalid answer")
+	}
+	return answer.Results, nil
+}
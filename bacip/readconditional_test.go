@@ -0,0 +1,62 @@
+package bacip
+
+import (
+	"context"
+	"encoding/hex"
+	"net"
+	"testing"
+
+	"github.com/REQUEA/bacnet"
+	"github.com/REQUEA/bacnet/internal/encoding"
+	"github.com/matryer/is"
+)
+
+func TestReadPropertyConditional(t *testing.T) {
+	is := is.New(t)
+	request := ReadPropertyConditional{
+		Logic: SelectionLogicAnd,
+		Criteria: []SelectionCriterion{{
+			Property: bacnet.PropertyIdentifier{Type: bacnet.PresentValue},
+			Relation: RelationGreaterThan,
+			Value:    bacnet.PropertyValue{Type: bacnet.TypeReal, Value: float32(21)},
+		}},
+		Properties: []bacnet.PropertyIdentifier{{Type: bacnet.ObjectName}},
+	}
+	b, err := request.MarshalBinary()
+	is.NoErr(err)
+	is.Equal(hex.EncodeToString(b), "0e09001e095529033e4441a800003f1f0f1e094d1f")
+	b, err = ReadPropertyConditional{Logic: SelectionLogicAll}.MarshalBinary()
+	is.NoErr(err)
+	is.Equal(hex.EncodeToString(b), "0e09020f")
+
+	av := bacnet.ObjectID{Type: bacnet.AnalogValue, Instance: 1}
+	m := newMemTransport()
+	m.respond = answerRequests(t, func(request *APDU) *APDU {
+		e := encoding.NewEncoder()
+		ReadAccessResult{ObjectID: av, Results: []PropertyResult{{
+			Property: bacnet.PropertyIdentifier{Type: bacnet.ObjectName},
+			Value:    "AV1",
+		}}}.encode(&e)
+		is.NoErr(e.Error())
+		return &APDU{
+			DataType:    ComplexAck,
+			ServiceType: request.ServiceType,
+			InvokeID:    request.InvokeID,
+			Payload:     &DataPayload{Bytes: e.Bytes()},
+		}
+	})
+	c := newMemClient(t, m)
+	device := bacnet.Device{
+		ID:   bacnet.ObjectID{Type: bacnet.BacnetDevice, Instance: 3},
+		Addr: *bacnet.AddressFromUDP(net.UDPAddr{IP: net.IPv4(10, 0, 2, 3).To4(), Port: DefaultUDPPort}),
+	}
+
+	results, err := c.ReadPropertyConditional(context.Background(), device, request)
+	is.NoErr(err)
+	is.Equal(results, []ReadAccessResult{{ObjectID: av, Results: []PropertyResult{{
+		Property: bacnet.PropertyIdentifier{Type: bacnet.ObjectName},
+		Value:    "AV1",
+	}}}})
+	_, err = c.ReadPropertyConditional(context.Background(), device, ReadPropertyConditional{})
+	is.True(err != nil)
+}
@@ -0,0 +1,134 @@
+package bacip
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/REQUEA/bacnet"
+	"github.com/REQUEA/bacnet/internal/encoding"
+)
+
+// ReadPropertyMultiple reads several properties of several objects in
+// a single request
+type ReadPropertyMultiple struct {
+	Specifications []ReadAccessSpecification
+	//Results contains the response
+	Results []ReadAccessResult
+}
+
+func (rpm ReadPropertyMultiple) MarshalBinary() ([]byte, error) {
+	encoder := encoding.NewEncoder()
+	for _, s := range rpm.Specifications {
+		s.encode(&encoder)
+	}
+	return encoder.Bytes(), encoder.Error()
+}
+
+func (rpm *ReadPropertyMultiple) UnmarshalBinary(data []byte) error {
+	results, err := decodeReadAccessResults(data)
+	if err != nil {
+		return err
+	}
+	rpm.Results = results
+	return nil
+}
+
+// rpmChunk is a part of a ReadPropertyMultiple request split to fit
+// the number of properties a device accepts
+type rpmChunk struct {
+	specs []ReadAccessSpecification
+	//continued is true if the first specification continues the last
+	//one of the previous chunk
+	continued bool
+}
+
+// splitSpecifications splits the specifications into chunks of at
+// most max properties. max <= 0 means no limit
+func splitSpecifications(specs []ReadAccessSpecification, max int) []rpmChunk {
+	if max <= 0 {
+		return []rpmChunk{{specs: specs}}
+	}
+	var chunks []rpmChunk
+	current := rpmChunk{}
+	count := 0
+	for _, s := range specs {
+		props := s.Properties
+		continued := false
+		for len(props) > 0 {
+			if count == max {
+				chunks = append(chunks, current)
+				current = rpmChunk{continued: continued}
+				count = 0
+			}
+			n := max - count
+			if n > len(props) {
+				n = len(props)
+			}
+			current.specs = append(current.specs, ReadAccessSpecification{ObjectID: s.ObjectID, Properties: props[:n]})
+			count += n
+			props = props[n:]
+			continued = true
+		}
+	}
+	if len(current.specs) > 0 {
+		chunks = append(chunks, current)
+	}
+	return chunks
+}
+
+// ReadPropertyMultiple reads the properties of the specifications.
+// The errors of the reads of single properties are returned in their
+// PropertyResult. The request is split according to the
+// MaxReadPropertyMultiple of the device profile, or sent as
+// ReadProperty requests if the profile disables ReadPropertyMultiple
+func (c *Client) ReadPropertyMultiple(ctx context.Context, device bacnet.Device, specs []ReadAccessSpecification) ([]ReadAccessResult, error) {
+	profile := c.DeviceProfile(device.ID)
+	if profile.DisableReadPropertyMultiple {
+		return c.readEach(ctx, device, specs)
+	}
+	results := []ReadAccessResult{}
+	for _, chunk := range splitSpecifications(specs, profile.MaxReadPropertyMultiple) {
+		apdu, err := c.confirmedRequest(ctx, device, ServiceConfirmedReadPropMultiple, &ReadPropertyMultiple{Specifications: chunk.specs})
+		if err != nil {
+			return nil, err
+		}
+		if apdu.DataType != ComplexAck || apdu.ServiceType != ServiceConfirmedReadPropMultiple {
+			return nil, errors.New("invalid answer")
+		}
+		rpm := apdu.Payload.(*ReadPropertyMultiple)
+		for i, r := range rpm.Results {
+			if i == 0 && chunk.continued && len(results) > 0 {
+				last := &results[len(results)-1]
+				last.Results = append(last.Results, r.Results...)
+				continue
+			}
+			results = append(results, r)
+		}
+	}
+	return results, nil
+}
+
+// readEach reads the properties of the specifications one by one
+func (c *Client) readEach(ctx context.Context, device bacnet.Device, specs []ReadAccessSpecification) ([]ReadAccessResult, error) {
+	results := make([]ReadAccessResult, 0, len(specs))
+	for _, s := range specs {
+		r := ReadAccessResult{ObjectID: s.ObjectID, Results: []PropertyResult{}}
+		for _, p := range s.Properties {
+			res := PropertyResult{Property: p}
+			v, err := c.ReadProperty(ctx, device, ReadProperty{ObjectID: s.ObjectID, Property: p})
+			var apduErr ApduError
+			switch {
+			case errors.As(err, &apduErr):
+				res.Error = &apduErr
+			case err != nil:
+				return nil, fmt.Errorf("read %v %v: %w", s.ObjectID, p.Type, err)
+			default:
+				res.Value = v
+			}
+			r.Results = append(r.Results, res)
+		}
+		results = append(results, r)
+	}
+	return results, nil
+}
@@ -0,0 +1,60 @@
+package bacip
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/REQUEA/bacnet"
+)
+
+// ReadObject reads all the properties of an object. The properties
+// are listed by the property list of the object, read first, or by the
+// ALL special property of ReadPropertyMultiple for the devices older
+// than the property list. The properties whose read failed are left
+// out, the values are decoded like ReadProperty.Data
+func (c *Client) ReadObject(ctx context.Context, device bacnet.Device, objectID bacnet.ObjectID) (map[bacnet.PropertyType]interface{}, error) {
+	spec := ReadAccessSpecification{ObjectID: objectID}
+	list, err := c.ReadProperty(ctx, device, ReadProperty{
+		ObjectID: objectID,
+		Property: bacnet.PropertyIdentifier{Type: bacnet.PropertyList},
+	})
+	switch {
+	case err == nil:
+		//The property list doesn't contain these ones
+		for _, p := range []bacnet.PropertyType{bacnet.ObjectIdentifier, bacnet.ObjectName, bacnet.ObjectTypeProp} {
+			spec.Properties = append(spec.Properties, bacnet.PropertyIdentifier{Type: p})
+		}
+		values, ok := list.([]interface{})
+		if !ok {
+			//A list of a single property
+			values = []interface{}{list}
+		}
+		for _, v := range values {
+			p, ok := v.(uint32)
+			if !ok {
+				return nil, fmt.Errorf("unexpected property list element type %T", v)
+			}
+			spec.Properties = append(spec.Properties, bacnet.PropertyIdentifier{Type: bacnet.PropertyType(p)})
+		}
+	case isUnknownProperty(err):
+		if c.DeviceProfile(device.ID).DisableReadPropertyMultiple {
+			return nil, fmt.Errorf("read properties of %v: no property list and ReadPropertyMultiple disabled", objectID)
+		}
+		spec.Properties = []bacnet.PropertyIdentifier{{Type: bacnet.All}}
+	default:
+		return nil, fmt.Errorf("read property list: %w", err)
+	}
+	results, err := c.ReadPropertyMultiple(ctx, device, []ReadAccessSpecification{spec})
+	if err != nil {
+		return nil, fmt.Errorf("read properties of %v: %w", objectID, err)
+	}
+	props := map[bacnet.PropertyType]interface{}{}
+	for _, r := range results {
+		for _, res := range r.Results {
+			if res.Error == nil {
+				props[res.Property.Type] = res.Value
+			}
+		}
+	}
+	return props, nil
+}
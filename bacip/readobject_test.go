@@ -0,0 +1,50 @@
+package bacip
+
+import (
+	"context"
+	"testing"
+
+	"github.com/REQUEA/bacnet"
+	"github.com/matryer/is"
+)
+
+func TestReadObject(t *testing.T) {
+	is := is.New(t)
+	ai1 := bacnet.ObjectID{Type: bacnet.AnalogInput, Instance: 1}
+	d := newMemDevice(10)
+	d.set(ai1, bacnet.ObjectName, "OutdoorTemp")
+	d.set(ai1, bacnet.PresentValue, float32(21.5))
+	d.set(ai1, bacnet.Units, bacnet.PropertyValue{Type: bacnet.TypeEnumerated, Value: uint32(62)})
+	m := newMemTransport()
+	m.respond = serveDevices(t, d)
+	c := newMemClient(t, m)
+
+	//Without property list
+	props, err := c.ReadObject(context.Background(), d.device(), ai1)
+	is.NoErr(err)
+	is.Equal(props, map[bacnet.PropertyType]interface{}{
+		bacnet.ObjectIdentifier: ai1,
+		bacnet.ObjectTypeProp:   uint32(bacnet.AnalogInput),
+		bacnet.ObjectName:       "OutdoorTemp",
+		bacnet.PresentValue:     float32(21.5),
+		bacnet.Units:            uint32(62),
+	})
+	requests := d.received()
+	is.Equal(requests[len(requests)-1].property.Type, bacnet.All)
+
+	d.reset()
+	d.set(ai1, bacnet.PropertyList, []interface{}{
+		bacnet.PropertyValue{Type: bacnet.TypeEnumerated, Value: uint32(bacnet.PresentValue)},
+		bacnet.PropertyValue{Type: bacnet.TypeEnumerated, Value: uint32(bacnet.Description)},
+	})
+	props, err = c.ReadObject(context.Background(), d.device(), ai1)
+	is.NoErr(err)
+	is.Equal(props, map[bacnet.PropertyType]interface{}{
+		bacnet.ObjectIdentifier: ai1,
+		bacnet.ObjectTypeProp:   uint32(bacnet.AnalogInput),
+		bacnet.ObjectName:       "OutdoorTemp",
+		bacnet.PresentValue:     float32(21.5),
+	})
+	is.True(!d.read(ai1, bacnet.All))
+	is.True(d.read(ai1, bacnet.Description))
+}
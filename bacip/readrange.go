@@ -0,0 +1,235 @@
+package bacip
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/REQUEA/bacnet"
+	"github.com/REQUEA/bacnet/internal/encoding"
+)
+
+// RangeType selects how the items of a ReadRange request are chosen
+type RangeType byte
+
+const (
+	RangeByPosition RangeType = 3
+	RangeBySequence RangeType = 6
+	RangeByTime     RangeType = 7
+)
+
+// Range selects which items of a list are returned by a ReadRange
+// request. Count is the number of items to read, a negative count
+// reads the items before the reference instead of after it. It's sent
+// as a 16 bits integer, so it's between -32768 and 32767.
+type Range struct {
+	Type RangeType
+	//Only one of the reference is used, depending on the Type
+	ReferenceIndex    uint32
+	ReferenceSequence uint32
+	ReferenceTime     bacnet.DateTime
+	Count             int32
+}
+
+// ByPosition selects count items starting at the given index of the
+// list. Index starts at 1. A negative count selects the items
+// before the index (included)
+func ByPosition(index uint32, count int32) (*Range, error) {
+	r := &Range{Type: RangeByPosition, ReferenceIndex: index, Count: count}
+	return r, r.Validate()
+}
+
+// BySequence selects count items starting at the given sequence
+// number. A negative count selects the items before the sequence
+// number (included)
+func BySequence(sequenceNumber uint32, count int32) (*Range, error) {
+	r := &Range{Type: RangeBySequence, ReferenceSequence: sequenceNumber, Count: count}
+	return r, r.Validate()
+}
+
+// ByTime selects count items recorded after the given time. A
+// negative count selects the items recorded before it. The time is
+// sent in the time zone of t, which should match the one of the
+// device.
+func ByTime(t time.Time, count int32) (*Range, error) {
+	return ByDateTime(bacnet.DateTimeFromTime(t), count)
+}
+
+// ByDateTime is the same as ByTime with a bacnet date time
+func ByDateTime(dt bacnet.DateTime, count int32) (*Range, error) {
+	r := &Range{Type: RangeByTime, ReferenceTime: dt, Count: count}
+	return r, r.Validate()
+}
+
+// Validate checks that the range can be sent to a device.
+func (r Range) Validate() error {
+	if r.Count == 0 {
+		return errors.New("invalid range: count must not be 0")
+	}
+	if r.Count < math.MinInt16 || r.Count > math.MaxInt16 {
+		return fmt.Errorf("invalid range: count %d doesn't fit in 16 bits", r.Count)
+	}
+	switch r.Type {
+	case RangeByPosition:
+		if r.ReferenceIndex == 0 {
+			return errors.New("invalid range: position index starts at 1")
+		}
+	case RangeBySequence:
+	case RangeByTime:
+		d, t := r.ReferenceTime.Date, r.ReferenceTime.Time
+		for _, v := range []int{d.Month, d.Day, t.Hour, t.Minute, t.Second, t.Hundredths} {
+			if v == bacnet.Unspecified {
+				return errors.New("invalid range: reference time must be fully specified")
+			}
+		}
+		if d.Year == bacnet.UnspecifiedYear {
+			return errors.New("invalid range: reference time must be fully specified")
+		}
+		if d.Month < 1 || d.Month > 12 || d.Day < 1 || d.Day > 31 || t.Hour > 23 || t.Minute > 59 || t.Second > 59 || t.Hundredths > 99 {
+			return fmt.Errorf("invalid range: invalid reference time %v", r.ReferenceTime)
+		}
+	default:
+		return fmt.Errorf("invalid range type %d", r.Type)
+	}
+	return nil
+}
+
+// Next returns the range that reads the items following (or
+// preceding if count is negative) the ones returned in ack, with the
+// same count. It returns nil if there are no more items to read.
+//
+// When reading by time, the next range is by sequence number if the
+// device returned the sequence number of the items, because several
+// items may have the same timestamp.
+func (r Range) Next(ack ReadRange) *Range {
+	if ack.ItemCount == 0 {
+		return nil
+	}
+	forward := r.Count > 0
+	if forward && (ack.ResultFlags.LastItem || !ack.ResultFlags.MoreItems) {
+		return nil
+	}
+	if !forward && (ack.ResultFlags.FirstItem || !ack.ResultFlags.MoreItems) {
+		return nil
+	}
+	next := r
+	switch {
+	case r.Type == RangeByPosition:
+		if forward {
+			next.ReferenceIndex = r.ReferenceIndex + ack.ItemCount
+		} else {
+			if r.ReferenceIndex <= ack.ItemCount {
+				return nil
+			}
+			next.ReferenceIndex = r.ReferenceIndex - ack.ItemCount
+		}
+	case ack.FirstSequenceNumber != nil:
+		next.Type = RangeBySequence
+		next.ReferenceTime = bacnet.DateTime{}
+		if forward {
+			next.ReferenceSequence = *ack.FirstSequenceNumber + ack.ItemCount
+		} else {
+			next.ReferenceSequence = *ack.FirstSequenceNumber - 1
+		}
+	default:
+		//The device didn't return sequence numbers, we can't know
+		//where to continue
+		return nil
+	}
+	return &next
+}
+
+// ResultFlags tells where the returned items are located in the list
+type ResultFlags struct {
+	FirstItem bool
+	LastItem  bool
+	MoreItems bool
+}
+
+type ReadRange struct {
+	ObjectID bacnet.ObjectID
+	Property bacnet.PropertyIdentifier
+	//Range is nil to read the whole list
+	Range *Range
+
+	//The following fields contains the response
+	ResultFlags ResultFlags
+	ItemCount   uint32
+	//ItemData contains the encoded items, use the decoding function
+	//matching the property that was read
+	ItemData []byte
+	//FirstSequenceNumber is only set when reading by sequence or by time
+	FirstSequenceNumber *uint32
+}
+
+func (rr ReadRange) MarshalBinary() ([]byte, error) {
+	encoder := encoding.NewEncoder()
+	encoder.ContextObjectID(0, rr.ObjectID)
+	encoder.ContextUnsigned(1, uint32(rr.Property.Type))
+	if rr.Property.ArrayIndex != nil {
+		encoder.ContextUnsigned(2, *rr.Property.ArrayIndex)
+	}
+	if rr.Range != nil {
+		r := rr.Range
+		if err := r.Validate(); err != nil {
+			return nil, err
+		}
+		encoder.OpeningTag(byte(r.Type))
+		switch r.Type {
+		case RangeByPosition:
+			encoder.AppData(r.ReferenceIndex)
+		case RangeBySequence:
+			encoder.AppData(r.ReferenceSequence)
+		case RangeByTime:
+			encodeDateTime(&encoder, r.ReferenceTime)
+		}
+		encoder.AppData(r.Count)
+		encoder.ClosingTag(byte(r.Type))
+	}
+	return encoder.Bytes(), encoder.Error()
+}
+
+// UnmarshalBinary decodes a ReadRange acknowledgment
+func (rr *ReadRange) UnmarshalBinary(data []byte) error {
+	decoder := encoding.NewDecoder(data)
+	decoder.ContextObjectID(0, &rr.ObjectID)
+	var val uint32
+	decoder.ContextValue(1, &val)
+	rr.Property.Type = bacnet.PropertyType(val)
+	rr.Property.ArrayIndex = nil
+	if decoder.IsContextTag(2) {
+		rr.Property.ArrayIndex = new(uint32)
+		decoder.ContextValue(2, rr.Property.ArrayIndex)
+	}
+	var flags bacnet.BitString
+	decoder.ContextBitString(3, &flags)
+	rr.ResultFlags = ResultFlags{
+		FirstItem: flags.Bit(0),
+		LastItem:  flags.Bit(1),
+		MoreItems: flags.Bit(2),
+	}
+	decoder.ContextValue(4, &rr.ItemCount)
+	rr.ItemData = nil
+	decoder.ContextRaw(5, &rr.ItemData)
+	rr.FirstSequenceNumber = nil
+	if decoder.IsContextTag(6) {
+		rr.FirstSequenceNumber = new(uint32)
+		decoder.ContextValue(6, rr.FirstSequenceNumber)
+	}
+	return decoder.Error()
+}
+
+// ReadRange reads a range of items of a list property, such as the
+// log buffer of trend log and event log objects
+func (c *Client) ReadRange(ctx context.Context, device bacnet.Device, readRange ReadRange) (ReadRange, error) {
+	apdu, err := c.confirmedRequest(ctx, device, ServiceConfirmedReadRange, &readRange)
+	if err != nil {
+		return ReadRange{}, err
+	}
+	if apdu.DataType == ComplexAck && apdu.ServiceType == ServiceConfirmedReadRange {
+		return *apdu.Payload.(*ReadRange), nil
+	}
+	return ReadRange{}, errors.New("invalid answer")
+}
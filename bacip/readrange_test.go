@@ -0,0 +1,185 @@
+package bacip
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/REQUEA/bacnet"
+	"github.com/REQUEA/bacnet/internal/encoding"
+
+	"github.com/matryer/is"
+)
+
+func TestReadRangeReq(t *testing.T) {
+	ttc := []struct {
+		data string //hex string
+		rr   ReadRange
+	}{
+		{
+			data: "0c064000011983",
+			rr: ReadRange{
+				ObjectID: bacnet.ObjectID{Type: bacnet.EventLog, Instance: 1},
+				Property: bacnet.PropertyIdentifier{Type: bacnet.LogBuffer},
+			},
+		},
+		{
+			data: "0c0640000119833e2101310a3f",
+			rr: ReadRange{
+				ObjectID: bacnet.ObjectID{Type: bacnet.EventLog, Instance: 1},
+				Property: bacnet.PropertyIdentifier{Type: bacnet.LogBuffer},
+				Range:    &Range{Type: RangeByPosition, ReferenceIndex: 1, Count: 10},
+			},
+		},
+		{
+			data: "0c0640000119837ea47a0a0e05b40c1e000031f67f",
+			rr: ReadRange{
+				ObjectID: bacnet.ObjectID{Type: bacnet.EventLog, Instance: 1},
+				Property: bacnet.PropertyIdentifier{Type: bacnet.LogBuffer},
+				Range: &Range{
+					Type: RangeByTime,
+					ReferenceTime: bacnet.DateTime{
+						Date: bacnet.Date{Year: 2022, Month: 10, Day: 14, Weekday: 5},
+						Time: bacnet.Time{Hour: 12, Minute: 30},
+					},
+					Count: -10,
+				},
+			},
+		},
+	}
+	for _, tc := range ttc {
+		t.Run(tc.data, func(t *testing.T) {
+			is := is.New(t)
+			result, err := tc.rr.MarshalBinary()
+			is.NoErr(err)
+			is.Equal(hex.EncodeToString(result), tc.data)
+		})
+	}
+}
+
+func TestReadRangeEventLogAck(t *testing.T) {
+	is := is.New(t)
+	b, err := hex.DecodeString("0c0640000119833a05c049015e0ea47a0a0e05b40c1e00000f1e0a05801f5f6907")
+	is.NoErr(err)
+	rr := ReadRange{}
+	is.NoErr(rr.UnmarshalBinary(b))
+	is.Equal(rr.ObjectID, bacnet.ObjectID{Type: bacnet.EventLog, Instance: 1})
+	is.Equal(rr.ResultFlags, ResultFlags{FirstItem: true, LastItem: true})
+	is.Equal(rr.ItemCount, uint32(1))
+	is.True(rr.FirstSequenceNumber != nil && *rr.FirstSequenceNumber == 7)
+	records, err := DecodeEventLogRecords(rr.ItemData)
+	is.NoErr(err)
+	is.Equal(len(records), 1)
+	is.Equal(records[0].Timestamp.Time, bacnet.Time{Hour: 12, Minute: 30})
+	is.Equal(*records[0].LogStatus, LogStatus{LogDisabled: true})
+}
+
+func TestEventLogNotificationCoherency(t *testing.T) {
+	is := is.New(t)
+	record := EventLogRecord{
+		Timestamp: bacnet.DateTime{
+			Date: bacnet.Date{Year: 2022, Month: 10, Day: 14, Weekday: 5},
+			Time: bacnet.Time{Hour: 8},
+		},
+		Notification: &EventNotification{
+			ProcessID:         1,
+			InitiatingDevice:  bacnet.ObjectID{Type: bacnet.BacnetDevice, Instance: 1234},
+			EventObject:       bacnet.ObjectID{Type: bacnet.AnalogInput, Instance: 3},
+			TimeStamp:         bacnet.TimeStamp{Kind: bacnet.TimeStampSequence, SequenceNumber: 42},
+			NotificationClass: 10,
+			Priority:          100,
+			EventType:         bacnet.EventTypeOutOfRange,
+			MessageText:       "High temperature",
+			NotifyType:        bacnet.NotifyTypeAlarm,
+			AckRequired:       true,
+			FromState:         bacnet.EventStateNormal,
+			ToState:           bacnet.EventStateHighLimit,
+			EventValues:       []byte{0x5e, 0x0c, 0x42, 0x48, 0x00, 0x00, 0x5f},
+		},
+	}
+	e := encoding.NewEncoder()
+	is.NoErr(record.encode(&e))
+	records, err := DecodeEventLogRecords(e.Bytes())
+	is.NoErr(err)
+	is.Equal(len(records), 1)
+	is.Equal(records[0], record)
+}
+
+func TestRangeHelpers(t *testing.T) {
+	is := is.New(t)
+	_, err := ByPosition(0, 10)
+	is.True(err != nil)
+	_, err = BySequence(10, 0)
+	is.True(err != nil)
+	_, err = BySequence(10, 32768)
+	is.True(err != nil)
+	_, err = ByPosition(1, -32769)
+	is.True(err != nil)
+	_, err = BySequence(10, 32767)
+	is.NoErr(err)
+	_, err = ByPosition(40000, -32768)
+	is.NoErr(err)
+	_, err = ByDateTime(bacnet.DateTime{
+		Date: bacnet.Date{Year: 2022, Month: bacnet.Unspecified, Day: 1},
+	}, 10)
+	is.True(err != nil)
+
+	r, err := ByPosition(1, 10)
+	is.NoErr(err)
+	next := r.Next(ReadRange{ItemCount: 10, ResultFlags: ResultFlags{FirstItem: true, MoreItems: true}})
+	is.Equal(*next, Range{Type: RangeByPosition, ReferenceIndex: 11, Count: 10})
+	is.Equal(next.Next(ReadRange{ItemCount: 3, ResultFlags: ResultFlags{LastItem: true}}), (*Range)(nil))
+
+	r, err = ByPosition(25, -10)
+	is.NoErr(err)
+	next = r.Next(ReadRange{ItemCount: 10, ResultFlags: ResultFlags{LastItem: true, MoreItems: true}})
+	is.Equal(next.ReferenceIndex, uint32(15))
+
+	first := uint32(100)
+	r, err = ByDateTime(bacnet.DateTime{Date: bacnet.Date{Year: 2022, Month: 10, Day: 14, Weekday: 5}}, 20)
+	is.NoErr(err)
+	next = r.Next(ReadRange{ItemCount: 20, FirstSequenceNumber: &first, ResultFlags: ResultFlags{MoreItems: true}})
+	is.Equal(*next, Range{Type: RangeBySequence, ReferenceSequence: 120, Count: 20})
+}
+
+func TestTrendLogRecords(t *testing.T) {
+	is := is.New(t)
+	b, err := hex.DecodeString("0ea47a0a0e05b40c1e00000f1e2c41ac00001f2a0400")
+	is.NoErr(err)
+	records, err := DecodeTrendLogRecords(b)
+	is.NoErr(err)
+	is.Equal(len(records), 1)
+	is.Equal(records[0].Kind, LogDatumReal)
+	is.Equal(records[0].Value, float32(21.5))
+	is.Equal(*records[0].StatusFlags, StatusFlags{})
+
+	timestamp := bacnet.DateTime{
+		Date: bacnet.Date{Year: 2022, Month: 10, Day: 14, Weekday: 5},
+		Time: bacnet.Time{Hour: 8},
+	}
+	flags := StatusFlags{InAlarm: true, OutOfService: true}
+	values := []TrendLogRecord{
+		{Kind: LogDatumStatus, Value: LogStatus{BufferPurged: true}},
+		{Kind: LogDatumBoolean, Value: true},
+		{Kind: LogDatumReal, Value: float32(-3.5), StatusFlags: &flags},
+		{Kind: LogDatumEnumerated, Value: uint32(2)},
+		{Kind: LogDatumUnsigned, Value: uint32(70000)},
+		{Kind: LogDatumSigned, Value: int32(-12)},
+		{Kind: LogDatumBitString, Value: bacnet.BitString{true, false, true}},
+		{Kind: LogDatumNull},
+		{Kind: LogDatumFailure, Value: ApduError{Class: bacnet.PropertyError, Code: 32}},
+		{Kind: LogDatumTimeChange, Value: float32(60)},
+		{Kind: LogDatumAny, Value: []byte{0x91, 0x01}},
+	}
+	e := encoding.NewEncoder()
+	for i := range values {
+		values[i].Timestamp = timestamp
+		is.NoErr(values[i].encode(&e))
+	}
+	is.NoErr(e.Error())
+	records, err = DecodeTrendLogRecords(e.Bytes())
+	is.NoErr(err)
+	is.Equal(records, values)
+
+	e = encoding.NewEncoder()
+	is.True(TrendLogRecord{Kind: LogDatumReal, Value: 1.5}.encode(&e) != nil)
+}
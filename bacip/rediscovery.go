@@ -0,0 +1,198 @@
+package bacip
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/REQUEA/bacnet"
+)
+
+// ObjectInfo holds the key properties of an object that are tracked
+// between two discoveries of the same device
+type ObjectInfo struct {
+	ID          bacnet.ObjectID
+	Name        string
+	Description string
+}
+
+// DeviceSnapshot is the list of objects of a device at a given
+// time. It can be stored and later compared with a fresh scan of the
+// device to keep a point database in sync with the controller
+type DeviceSnapshot struct {
+	Device  bacnet.Device
+	Taken   time.Time
+	Objects []ObjectInfo
+}
+
+// ChangeKind describes how an object changed between two snapshots
+type ChangeKind byte
+
+const (
+	ObjectAdded ChangeKind = iota
+	ObjectRemoved
+	ObjectRenamed
+	ObjectModified
+)
+
+func (k ChangeKind) String() string {
+	switch k {
+	case ObjectAdded:
+		return "added"
+	case ObjectRemoved:
+		return "removed"
+	case ObjectRenamed:
+		return "renamed"
+	case ObjectModified:
+		return "modified"
+	default:
+		return fmt.Sprintf("ChangeKind(%d)", k)
+	}
+}
+
+// ObjectChange is a single difference between two snapshots. Old is
+// nil for added objects and New is nil for removed objects
+type ObjectChange struct {
+	Kind ChangeKind
+	Old  *ObjectInfo
+	New  *ObjectInfo
+}
+
+// SnapshotDiff is the result of the comparison of two snapshots of
+// the same device. Changes are sorted by object ID
+type SnapshotDiff struct {
+	Changes []ObjectChange
+}
+
+// Empty returns true if both snapshots describe the same objects
+func (d SnapshotDiff) Empty() bool {
+	return len(d.Changes) == 0
+}
+
+// DiffSnapshots compares the objects of two snapshots. An object
+// whose name changed is reported as renamed, an object whose only
+// description changed is reported as modified.
+func DiffSnapshots(previous, current DeviceSnapshot) SnapshotDiff {
+	old := make(map[bacnet.ObjectID]ObjectInfo, len(previous.Objects))
+	for _, o := range previous.Objects {
+		old[o.ID] = o
+	}
+	diff := SnapshotDiff{}
+	seen := make(map[bacnet.ObjectID]struct{}, len(current.Objects))
+	for _, o := range current.Objects {
+		o := o
+		seen[o.ID] = struct{}{}
+		p, ok := old[o.ID]
+		switch {
+		case !ok:
+			diff.Changes = append(diff.Changes, ObjectChange{Kind: ObjectAdded, New: &o})
+		case p.Name != o.Name:
+			diff.Changes = append(diff.Changes, ObjectChange{Kind: ObjectRenamed, Old: &p, New: &o})
+		case p.Description != o.Description:
+			diff.Changes = append(diff.Changes, ObjectChange{Kind: ObjectModified, Old: &p, New: &o})
+		}
+	}
+	for _, o := range previous.Objects {
+		o := o
+		if _, ok := seen[o.ID]; !ok {
+			diff.Changes = append(diff.Changes, ObjectChange{Kind: ObjectRemoved, Old: &o})
+		}
+	}
+	sort.Slice(diff.Changes, func(i, j int) bool {
+		return lessObjectID(diff.Changes[i].id(), diff.Changes[j].id())
+	})
+	return diff
+}
+
+func (c ObjectChange) id() bacnet.ObjectID {
+	if c.New != nil {
+		return c.New.ID
+	}
+	return c.Old.ID
+}
+
+func lessObjectID(a, b bacnet.ObjectID) bool {
+	if a.Type != b.Type {
+		return a.Type < b.Type
+	}
+	return a.Instance < b.Instance
+}
+
+// Snapshot reads the object list of the device and the name and
+// description of every object in it.
+func (c *Client) Snapshot(ctx context.Context, device bacnet.Device) (DeviceSnapshot, error) {
+	snapshot := DeviceSnapshot{Device: device, Taken: time.Now()}
+	ids, err := c.ReadObjectList(ctx, device)
+	if err != nil {
+		return snapshot, err
+	}
+	for _, id := range ids {
+		info := ObjectInfo{ID: id}
+		name, err := c.ReadProperty(ctx, device, ReadProperty{
+			ObjectID: id,
+			Property: bacnet.PropertyIdentifier{Type: bacnet.ObjectName},
+		})
+		if err != nil {
+			return snapshot, fmt.Errorf("read name of %v: %w", id, err)
+		}
+		info.Name, _ = name.(string)
+		desc, err := c.ReadProperty(ctx, device, ReadProperty{
+			ObjectID: id,
+			Property: bacnet.PropertyIdentifier{Type: bacnet.Description},
+		})
+		//Description is optional for most object types
+		if err != nil && !isUnknownProperty(err) {
+			return snapshot, fmt.Errorf("read description of %v: %w", id, err)
+		}
+		info.Description, _ = desc.(string)
+		snapshot.Objects = append(snapshot.Objects, info)
+	}
+	return snapshot, nil
+}
+
+// Rediscover takes a new snapshot of the device previously scanned
+// and returns it along with the differences from the previous one
+func (c *Client) Rediscover(ctx context.Context, previous DeviceSnapshot) (DeviceSnapshot, SnapshotDiff, error) {
+	current, err := c.Snapshot(ctx, previous.Device)
+	if err != nil {
+		return current, SnapshotDiff{}, err
+	}
+	return current, DiffSnapshots(previous, current), nil
+}
+
+// ReadObjectList reads the object list of the device one index at a
+// time, which is supported by all devices regardless of their
+// segmentation capabilities
+func (c *Client) ReadObjectList(ctx context.Context, device bacnet.Device) ([]bacnet.ObjectID, error) {
+	length, err := c.CountObjects(ctx, device)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]bacnet.ObjectID, 0, length)
+	for i := uint32(1); i <= uint32(length); i++ {
+		prop := bacnet.PropertyIdentifier{Type: bacnet.ObjectList, ArrayIndex: new(uint32)}
+		*prop.ArrayIndex = i
+		d, err := c.ReadProperty(ctx, device, ReadProperty{ObjectID: device.ID, Property: prop})
+		if err != nil {
+			return nil, fmt.Errorf("read object list index %d: %w", i, err)
+		}
+		id, ok := d.(bacnet.ObjectID)
+		if !ok {
+			return nil, fmt.Errorf("unexpected object list element type %T", d)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func isUnknownProperty(err error) bool {
+	var e ApduError
+	return errors.As(err, &e) && e.Code == bacnet.UnknownProperty
+}
+
+func isWriteAccessDenied(err error) bool {
+	var e ApduError
+	return errors.As(err, &e) && e.Code == bacnet.WriteAccessDenied
+}
@@ -0,0 +1,42 @@
+package bacip
+
+import (
+	"testing"
+
+	"github.com/REQUEA/bacnet"
+
+	"github.com/matryer/is"
+)
+
+func TestDiffSnapshots(t *testing.T) {
+	is := is.New(t)
+	ai1 := bacnet.ObjectID{Type: bacnet.AnalogInput, Instance: 1}
+	ai2 := bacnet.ObjectID{Type: bacnet.AnalogInput, Instance: 2}
+	bo1 := bacnet.ObjectID{Type: bacnet.BinaryOutput, Instance: 1}
+	av7 := bacnet.ObjectID{Type: bacnet.AnalogValue, Instance: 7}
+	previous := DeviceSnapshot{Objects: []ObjectInfo{
+		{ID: ai1, Name: "OAT", Description: "Outside air"},
+		{ID: ai2, Name: "RAT"},
+		{ID: bo1, Name: "Fan", Description: "Supply fan"},
+	}}
+	current := DeviceSnapshot{Objects: []ObjectInfo{
+		{ID: ai1, Name: "OAT", Description: "Outside air"},
+		{ID: ai2, Name: "RAT-1"},
+		{ID: bo1, Name: "Fan", Description: "Supply fan start"},
+		{ID: av7, Name: "SP"},
+	}}
+	diff := DiffSnapshots(previous, current)
+	is.Equal(len(diff.Changes), 3)
+	is.Equal(diff.Changes[0].Kind, ObjectRenamed)
+	is.Equal(diff.Changes[0].Old.Name, "RAT")
+	is.Equal(diff.Changes[0].New.Name, "RAT-1")
+	is.Equal(diff.Changes[1].Kind, ObjectAdded)
+	is.Equal(diff.Changes[1].New.ID, av7)
+	is.Equal(diff.Changes[2].Kind, ObjectModified)
+	is.Equal(diff.Changes[2].New.ID, bo1)
+
+	diff = DiffSnapshots(current, previous)
+	is.Equal(diff.Changes[1].Kind, ObjectRemoved)
+	is.Equal(diff.Changes[1].Old.ID, av7)
+	is.True(DiffSnapshots(current, current).Empty())
+}
@@ -0,0 +1,91 @@
+package bacip
+
+import (
+	"math"
+	"reflect"
+	"time"
+)
+
+// Sample is a value of a point at a given time, whatever the way it
+// was obtained: polling, COV notification or trend log
+type Sample struct {
+	Time    time.Time
+	Value   interface{}
+	Quality Quality
+}
+
+// SampleFilter drops the samples of a point that are too close to the
+// last kept one, so that noisy values don't flood the consumers. Use
+// one filter per point. The zero value keeps only the samples whose
+// value or quality changed.
+type SampleFilter struct {
+	//Deadband is the minimum change of a numeric value for a
+	//sample to be kept. Other values are kept when they change
+	Deadband float64
+	//MinInterval is the minimum time between two kept samples
+	MinInterval time.Duration
+	//MaxInterval, if not 0, keeps a sample when the last kept one
+	//is older, even if the value didn't change
+	MaxInterval time.Duration
+
+	last *Sample
+}
+
+// Accept returns true if the sample should be kept, and remembers it
+// as the last kept sample
+func (f *SampleFilter) Accept(s Sample) bool {
+	if f.keep(s) {
+		f.last = &s
+		return true
+	}
+	return false
+}
+
+func (f *SampleFilter) keep(s Sample) bool {
+	if f.last == nil {
+		return true
+	}
+	elapsed := s.Time.Sub(f.last.Time)
+	if elapsed < f.MinInterval {
+		return false
+	}
+	if f.MaxInterval > 0 && elapsed >= f.MaxInterval {
+		return true
+	}
+	if s.Quality != f.last.Quality {
+		return true
+	}
+	v, ok1 := toFloat(s.Value)
+	last, ok2 := toFloat(f.last.Value)
+	if ok1 && ok2 {
+		return math.Abs(v-last) > f.Deadband
+	}
+	return !reflect.DeepEqual(s.Value, f.last.Value)
+}
+
+// Reset forgets the last kept sample, the next one will be kept
+func (f *SampleFilter) Reset() {
+	f.last = nil
+}
+
+// toFloat converts numeric values to float64
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	case uint32:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,31 @@
+package bacip
+
+import (
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestSampleFilter(t *testing.T) {
+	is := is.New(t)
+	start := time.Now()
+	at := func(s int) time.Time { return start.Add(time.Duration(s) * time.Second) }
+	f := SampleFilter{Deadband: 0.5, MinInterval: 2 * time.Second, MaxInterval: time.Minute}
+	is.True(f.Accept(Sample{Time: at(0), Value: float32(20)}))
+	is.True(!f.Accept(Sample{Time: at(1), Value: float32(25)}))                         //too soon
+	is.True(!f.Accept(Sample{Time: at(3), Value: float32(20.4)}))                       //within deadband
+	is.True(f.Accept(Sample{Time: at(4), Value: float32(20.6)}))                        //out of deadband
+	is.True(f.Accept(Sample{Time: at(6), Value: float32(20.6), Quality: QualityBad}))   //quality changed
+	is.True(!f.Accept(Sample{Time: at(30), Value: float32(20.6), Quality: QualityBad})) //unchanged
+	is.True(f.Accept(Sample{Time: at(70), Value: float32(20.6), Quality: QualityBad}))  //heartbeat
+
+	f = SampleFilter{}
+	is.True(f.Accept(Sample{Time: at(0), Value: "on"}))
+	is.True(!f.Accept(Sample{Time: at(1), Value: "on"}))
+	is.True(f.Accept(Sample{Time: at(2), Value: "off"}))
+	is.True(f.Accept(Sample{Time: at(2), Value: []byte{1}}))
+	is.True(!f.Accept(Sample{Time: at(2), Value: []byte{1}}))
+	f.Reset()
+	is.True(f.Accept(Sample{Time: at(3), Value: "off"}))
+}
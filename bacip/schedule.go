@@ -0,0 +1,240 @@
+package bacip
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/REQUEA/bacnet"
+	"github.com/REQUEA/bacnet/internal/encoding"
+)
+
+// TimeValue is a value that a schedule writes at a given time of the
+// day
+type TimeValue struct {
+	//Time is the time elapsed since midnight, with a resolution of
+	//10ms
+	Time time.Duration
+	//Value keeps the application tag so the value can be written
+	//back identically. A nil Value (Null) relinquishes the
+	//scheduled property
+	Value bacnet.PropertyValue
+}
+
+// At returns a TimeValue at the given hour and minute of the day
+func At(hour, minute int, value bacnet.PropertyValue) TimeValue {
+	return TimeValue{
+		Time:  time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute,
+		Value: value,
+	}
+}
+
+// WeeklySchedule contains the values of each day of the week,
+// starting by Monday
+type WeeklySchedule [7][]TimeValue
+
+// SpecialEvent is an entry of the exception schedule. The period is
+// either given by Entry or by a reference to a Calendar object
+type SpecialEvent struct {
+	Entry       *CalendarEntry
+	CalendarRef *bacnet.ObjectID
+	TimeValues  []TimeValue
+	//Priority goes from 1 (highest) to 16
+	Priority uint8
+}
+
+// Schedule is the content of the schedule properties of a Schedule
+// object
+type Schedule struct {
+	Weekly WeeklySchedule
+	//Exceptions is nil if the schedule doesn't support exceptions
+	Exceptions []SpecialEvent
+}
+
+func encodeTimeValues(e *encoding.Encoder, tvs []TimeValue) error {
+	for _, tv := range tvs {
+		if tv.Time < 0 || tv.Time >= 24*time.Hour {
+			return fmt.Errorf("invalid time of day %v", tv.Time)
+		}
+		e.AppData(bacnet.Time{
+			Hour:       int(tv.Time / time.Hour),
+			Minute:     int(tv.Time % time.Hour / time.Minute),
+			Second:     int(tv.Time % time.Minute / time.Second),
+			Hundredths: int(tv.Time % time.Second / (10 * time.Millisecond)),
+		})
+		e.AppValue(tv.Value)
+	}
+	return nil
+}
+
+// decodeTimeValues decodes time values until the closing tag
+func decodeTimeValues(d *encoding.Decoder, closingTag byte) []TimeValue {
+	tvs := []TimeValue{}
+	for d.Error() == nil && !d.IsClosingTag(closingTag) {
+		var t bacnet.Time
+		tv := TimeValue{}
+		d.AppData(&t)
+		tv.Time = t.Duration()
+		d.AppValue(&tv.Value)
+		tvs = append(tvs, tv)
+	}
+	return tvs
+}
+
+func (w WeeklySchedule) MarshalBinary() ([]byte, error) {
+	encoder := encoding.NewEncoder()
+	for _, day := range w {
+		encoder.OpeningTag(0)
+		err := encodeTimeValues(&encoder, day)
+		if err != nil {
+			return nil, err
+		}
+		encoder.ClosingTag(0)
+	}
+	return encoder.Bytes(), encoder.Error()
+}
+
+func (w *WeeklySchedule) UnmarshalBinary(data []byte) error {
+	day := 0
+	return decodeList(data, func(d *encoding.Decoder) error {
+		if day >= len(w) {
+			return errors.New("more than 7 days in weekly schedule")
+		}
+		d.OpeningTag(0)
+		w[day] = decodeTimeValues(d, 0)
+		d.ClosingTag(0)
+		day++
+		return nil
+	})
+}
+
+func (ev SpecialEvent) encode(e *encoding.Encoder) error {
+	if ev.Priority < 1 || ev.Priority > 16 {
+		return fmt.Errorf("invalid special event priority %d", ev.Priority)
+	}
+	switch {
+	case ev.Entry != nil:
+		e.OpeningTag(0)
+		ev.Entry.encode(e)
+		e.ClosingTag(0)
+	case ev.CalendarRef != nil:
+		e.ContextObjectID(1, *ev.CalendarRef)
+	default:
+		return errors.New("special event without period")
+	}
+	e.OpeningTag(2)
+	err := encodeTimeValues(e, ev.TimeValues)
+	if err != nil {
+		return err
+	}
+	e.ClosingTag(2)
+	e.ContextUnsigned(3, uint32(ev.Priority))
+	return nil
+}
+
+func (ev *SpecialEvent) decode(d *encoding.Decoder) error {
+	if d.IsOpeningTag(0) {
+		ev.Entry = &CalendarEntry{}
+		d.OpeningTag(0)
+		err := ev.Entry.decode(d)
+		if err != nil {
+			return err
+		}
+		d.ClosingTag(0)
+	} else {
+		ev.CalendarRef = new(bacnet.ObjectID)
+		d.ContextObjectID(1, ev.CalendarRef)
+	}
+	d.OpeningTag(2)
+	ev.TimeValues = decodeTimeValues(d, 2)
+	d.ClosingTag(2)
+	var priority uint32
+	d.ContextValue(3, &priority)
+	ev.Priority = uint8(priority)
+	return d.Error()
+}
+
+// ExceptionSchedule is the list of special events of a schedule
+type ExceptionSchedule []SpecialEvent
+
+func (es ExceptionSchedule) MarshalBinary() ([]byte, error) {
+	encoder := encoding.NewEncoder()
+	for _, ev := range es {
+		err := ev.encode(&encoder)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return encoder.Bytes(), encoder.Error()
+}
+
+func (es *ExceptionSchedule) UnmarshalBinary(data []byte) error {
+	*es = ExceptionSchedule{}
+	return decodeList(data, func(d *encoding.Decoder) error {
+		ev := SpecialEvent{}
+		err := ev.decode(d)
+		*es = append(*es, ev)
+		return err
+	})
+}
+
+// ReadSchedule reads the weekly schedule and the exception schedule
+// of a Schedule object. Exceptions is nil if the object doesn't
+// have an exception schedule
+func (c *Client) ReadSchedule(ctx context.Context, device bacnet.Device, schedule bacnet.ObjectID) (Schedule, error) {
+	s := Schedule{}
+	raw, err := c.readRaw(ctx, device, ReadProperty{
+		ObjectID: schedule,
+		Property: bacnet.PropertyIdentifier{Type: bacnet.WeeklySchedule},
+	})
+	if err != nil && !isUnknownProperty(err) {
+		return s, fmt.Errorf("read weekly schedule: %w", err)
+	}
+	err = s.Weekly.UnmarshalBinary(raw)
+	if err != nil {
+		return s, fmt.Errorf("decode weekly schedule: %w", err)
+	}
+	raw, err = c.readRaw(ctx, device, ReadProperty{
+		ObjectID: schedule,
+		Property: bacnet.PropertyIdentifier{Type: bacnet.ExceptionSchedule},
+	})
+	if err != nil {
+		if isUnknownProperty(err) {
+			return s, nil
+		}
+		return s, fmt.Errorf("read exception schedule: %w", err)
+	}
+	es := ExceptionSchedule{}
+	err = es.UnmarshalBinary(raw)
+	if err != nil {
+		return s, fmt.Errorf("decode exception schedule: %w", err)
+	}
+	s.Exceptions = es
+	return s, nil
+}
+
+// WriteSchedule writes the weekly schedule of the Schedule object, and
+// its exception schedule if s.Exceptions isn't nil
+func (c *Client) WriteSchedule(ctx context.Context, device bacnet.Device, schedule bacnet.ObjectID, s Schedule) error {
+	err := c.WriteProperty(ctx, device, WriteProperty{
+		ObjectID:      schedule,
+		Property:      bacnet.PropertyIdentifier{Type: bacnet.WeeklySchedule},
+		PropertyValue: bacnet.PropertyValue{Value: s.Weekly},
+	})
+	if err != nil {
+		return fmt.Errorf("write weekly schedule: %w", err)
+	}
+	if s.Exceptions == nil {
+		return nil
+	}
+	err = c.WriteProperty(ctx, device, WriteProperty{
+		ObjectID:      schedule,
+		Property:      bacnet.PropertyIdentifier{Type: bacnet.ExceptionSchedule},
+		PropertyValue: bacnet.PropertyValue{Value: ExceptionSchedule(s.Exceptions)},
+	})
+	if err != nil {
+		return fmt.Errorf("write exception schedule: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package bacip
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/REQUEA/bacnet"
+
+	"github.com/matryer/is"
+)
+
+func TestWeeklyScheduleCoherency(t *testing.T) {
+	is := is.New(t)
+	w := WeeklySchedule{}
+	w[0] = []TimeValue{
+		At(8, 0, bacnet.PropertyValue{Type: bacnet.TypeReal, Value: float32(21)}),
+		At(18, 0, bacnet.PropertyValue{}),
+	}
+	for i := 1; i < 7; i++ {
+		w[i] = []TimeValue{}
+	}
+	expected := "0eb4080000004441a80000b412000000000f" + strings.Repeat("0e0f", 6)
+	b, err := w.MarshalBinary()
+	is.NoErr(err)
+	is.Equal(hex.EncodeToString(b), expected)
+	w2 := WeeklySchedule{}
+	is.NoErr(w2.UnmarshalBinary(b))
+	is.Equal(w2, w)
+}
+
+func TestExceptionScheduleCoherency(t *testing.T) {
+	is := is.New(t)
+	christmas := CalendarEntry{Kind: CalendarDate, Date: bacnet.Date{Year: 2022, Month: 12, Day: 25, Weekday: 7}}
+	calendar := bacnet.ObjectID{Type: bacnet.Calendar, Instance: 1}
+	es := ExceptionSchedule{
+		{
+			Entry:      &christmas,
+			TimeValues: []TimeValue{At(0, 0, bacnet.PropertyValue{Type: bacnet.TypeEnumerated, Value: uint32(0)})},
+			Priority:   16,
+		},
+		{
+			CalendarRef: &calendar,
+			TimeValues:  []TimeValue{},
+			Priority:    1,
+		},
+	}
+	b, err := es.MarshalBinary()
+	is.NoErr(err)
+	is.Equal(hex.EncodeToString(b), "0e0c7a0c19070f2eb40000000091002f3910"+"1c018000012e2f3901")
+	es2 := ExceptionSchedule{}
+	is.NoErr(es2.UnmarshalBinary(b))
+	is.Equal(es2, es)
+
+	es[0].Priority = 0
+	_, err = es.MarshalBinary()
+	is.True(err != nil)
+}
@@ -0,0 +1,297 @@
+package bacip
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/REQUEA/bacnet"
+)
+
+// maxWindowSize is the largest number of segments the client accepts
+// before acknowledging them
+const maxWindowSize = 16
+
+// segmentedAnswer is a segmented complex ack being received
+type segmentedAnswer struct {
+	service ServiceType
+	data    bytes.Buffer
+	//pending are the segments received before the previous ones,
+	//by sequence number
+	pending map[byte][]byte
+	//next is the sequence number of the next segment expected, and
+	//windowAt the first one of the current window
+	next     byte
+	windowAt byte
+	window   byte
+	count    int
+	//final is the sequence number of the last segment, if received
+	final    byte
+	hasFinal bool
+	received time.Time
+}
+
+// reassembly keeps the segmented answers being received, by invoke ID
+type reassembly struct {
+	sync.Mutex
+	answers map[byte]*segmentedAnswer
+}
+
+// receiving tells if a segment of the answer of the transaction was
+// received during the last timeout
+func (r *reassembly) receiving(invokeID byte, timeout time.Duration) bool {
+	r.Lock()
+	defer r.Unlock()
+	a, ok := r.answers[invokeID]
+	return ok && time.Since(a.received) < timeout
+}
+
+func (r *reassembly) remove(invokeID byte) {
+	r.Lock()
+	defer r.Unlock()
+	delete(r.answers, invokeID)
+}
+
+// add adds a segment to its answer. It returns the segment ack or the
+// abort to send, if any, and the answer of the transaction once all
+// the segments are received.
+//
+// The segments received out of order are kept until the missing ones
+// arrive: the datagrams are handled concurrently, so the order isn't
+// guaranteed even if the network keeps it. A negative ack is only sent
+// when the window or the answer is over and a segment is still missing
+func (r *reassembly) add(apdu *APDU, now time.Time) (*APDU, *APDU, error) {
+	p, ok := apdu.Payload.(*DataPayload)
+	if !ok {
+		return nil, nil, fmt.Errorf("invalid segment payload %T", apdu.Payload)
+	}
+	r.Lock()
+	defer r.Unlock()
+	if r.answers == nil {
+		r.answers = map[byte]*segmentedAnswer{}
+	}
+	a, ok := r.answers[apdu.InvokeID]
+	if !ok {
+		window := apdu.WindowSize
+		if window == 0 || window > maxWindowSize {
+			window = maxWindowSize
+		}
+		a = &segmentedAnswer{service: apdu.ServiceType, window: window, pending: map[byte][]byte{}}
+		r.answers[apdu.InvokeID] = a
+	}
+	a.received = now
+	ack := &APDU{
+		DataType:   SegmentAck,
+		InvokeID:   apdu.InvokeID,
+		WindowSize: a.window,
+		Payload:    &DataPayload{},
+	}
+	if apdu.Sequence-a.next >= 128 {
+		//A segment received again, the previous ack may have been lost
+		ack.Sequence = a.next - 1
+		return ack, nil, nil
+	}
+	a.pending[apdu.Sequence] = p.Bytes
+	if !apdu.MoreFollows {
+		a.final = apdu.Sequence
+		a.hasFinal = true
+	}
+	if a.count+len(a.pending) > maxSegmentsAccepted {
+		delete(r.answers, apdu.InvokeID)
+		abort := &APDU{
+			DataType:    Abort,
+			InvokeID:    apdu.InvokeID,
+			ServiceType: ServiceType(AbortReasonBufferOverflow),
+			Payload:     &DataPayload{},
+		}
+		//The transaction fails with the abort sent
+		return abort, abort, nil
+	}
+	for {
+		data, ok := a.pending[a.next]
+		if !ok {
+			break
+		}
+		delete(a.pending, a.next)
+		a.data.Write(data)
+		a.count++
+		if a.hasFinal && a.next == a.final {
+			delete(r.answers, apdu.InvokeID)
+			ack.Sequence = a.final
+			whole := &APDU{}
+			b := append([]byte{byte(ComplexAck), apdu.InvokeID, byte(a.service)}, a.data.Bytes()...)
+			err := whole.UnmarshalBinary(b)
+			if err != nil {
+				return ack, nil, fmt.Errorf("decode reassembled answer: %w", err)
+			}
+			return ack, whole, nil
+		}
+		a.next++
+	}
+	ack.Sequence = a.next - 1
+	if a.next-a.windowAt >= a.window {
+		a.windowAt = a.next
+		return ack, nil, nil
+	}
+	if apdu.Sequence-a.windowAt == a.window-1 || a.hasFinal {
+		//The server sends the segments again from the one following
+		//the last acknowledged
+		ack.NegativeAck = true
+		a.windowAt = a.next
+		return ack, nil, nil
+	}
+	return nil, nil, nil
+}
+
+// handleSegment acknowledges a segment of a complex ack, and returns
+// the answer of the transaction once its last segment is received.
+// Answers of too many segments are aborted
+func (c *Client) handleSegment(bvlc BVLC, src *net.UDPAddr) (*APDU, error) {
+	apdu := bvlc.NPDU.ADPU
+	if _, ok := c.transactions.GetTransaction(apdu.InvokeID); !ok {
+		return nil, fmt.Errorf("no transaction found for id %d", apdu.InvokeID)
+	}
+	reply, whole, err := c.segments.add(apdu, time.Now())
+	if reply != nil {
+		_, sendErr := c.sendTo(bvlc, src, reply)
+		if err == nil {
+			err = sendErr
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return whole, nil
+}
+
+// sendTo sends the APDU to the sender of a message
+func (c *Client) sendTo(bvlc BVLC, src *net.UDPAddr, apdu *APDU) (int, error) {
+	return c.send(NPDU{
+		Version:     Version1,
+		Priority:    Normal,
+		Destination: replyAddress(bvlc, src),
+		HopCount:    255,
+		ADPU:        apdu,
+	})
+}
+
+// segmentedHeaderSize is the size of the header of a segment of a
+// confirmed request, up to the service choice
+const segmentedHeaderSize = 6
+
+// segmentRequest returns the segments of the payload of a confirmed
+// request if it doesn't fit in the max APDU of the device, or nil. An
+// error is returned if the device doesn't accept segmented requests
+func segmentRequest(device bacnet.Device, payload Payload) ([][]byte, error) {
+	if device.MaxApdu == 0 {
+		//Unknown capabilities, the request is sent as is
+		return nil, nil
+	}
+	data, err := payload.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	//The header of an unsegmented request is 4 bytes long
+	if len(data)+4 <= int(device.MaxApdu) {
+		return nil, nil
+	}
+	if device.Segmentation != bacnet.SegmentationSupportBoth && device.Segmentation != bacnet.SegmentationSupportReceive {
+		return nil, fmt.Errorf("request of %d bytes larger than the max APDU of %d bytes of a device not accepting segmented requests", len(data)+4, device.MaxApdu)
+	}
+	size := int(device.MaxApdu) - segmentedHeaderSize
+	if size <= 0 {
+		return nil, fmt.Errorf("invalid max APDU %d", device.MaxApdu)
+	}
+	var segments [][]byte
+	for len(data) > size {
+		segments = append(segments, data[:size])
+		data = data[size:]
+	}
+	segments = append(segments, data)
+	if len(segments) > 256 {
+		return nil, fmt.Errorf("request of %d segments", len(segments))
+	}
+	return segments, nil
+}
+
+// sendSegments sends the segments of a confirmed request, window by
+// window. The first segment is sent alone, the segment ack of the
+// device gives the size of the following windows. A window is sent
+// again from the first segment not acknowledged when the device asks
+// for it or doesn't acknowledge it in time. It returns when the last
+// segment is acknowledged, or with the answer of the device if it
+// answered before, for instance with an abort
+func (c *Client) sendSegments(ctx context.Context, npdu NPDU, segments [][]byte, answers <-chan APDU, pc *pacer) (*APDU, error) {
+	request := *npdu.ADPU
+	window := 1
+	base := 0
+	tries := 0
+	for base < len(segments) {
+		end := base + window
+		if end > len(segments) {
+			end = len(segments)
+		}
+		for i := base; i < end; i++ {
+			segment := request
+			segment.Segmented = true
+			segment.MoreFollows = i < len(segments)-1
+			segment.Sequence = byte(i)
+			segment.WindowSize = maxWindowSize
+			segment.Payload = &DataPayload{Bytes: segments[i]}
+			npdu.ADPU = &segment
+			_, err := c.send(npdu)
+			if err != nil {
+				return nil, err
+			}
+		}
+		apdu, err := waitAnswer(ctx, answers, pc.timeout())
+		if err != nil {
+			return nil, err
+		}
+		if apdu != nil && apdu.DataType != SegmentAck {
+			return apdu, nil
+		}
+		if apdu != nil {
+			window = int(apdu.WindowSize)
+			if window < 1 || window > maxWindowSize {
+				window = maxWindowSize
+			}
+			//The sequence number of the last segment received, relative
+			//to the first one of the window
+			acked := int(apdu.Sequence - byte(base))
+			if acked < end-base {
+				base += acked + 1
+				tries = 0
+				continue
+			}
+		}
+		//No segment of the window was acknowledged
+		tries++
+		if tries > pc.profile.Retries {
+			return nil, context.DeadlineExceeded
+		}
+	}
+	return nil, nil
+}
+
+// waitAnswer waits for an answer during the timeout, forever if 0. It
+// returns nil if the timeout elapsed
+func waitAnswer(ctx context.Context, answers <-chan APDU, timeout time.Duration) (*APDU, error) {
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+	select {
+	case apdu := <-answers:
+		return &apdu, nil
+	case <-expired:
+		return nil, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
@@ -0,0 +1,285 @@
+package bacip
+
+import (
+	"context"
+	"encoding/hex"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/REQUEA/bacnet"
+	"github.com/REQUEA/bacnet/internal/encoding"
+	"github.com/matryer/is"
+)
+
+func TestSegmentEncoding(t *testing.T) {
+	is := is.New(t)
+	b, err := APDU{
+		DataType:                  ConfirmedServiceRequest,
+		ServiceType:               ServiceConfirmedReadProperty,
+		InvokeID:                  1,
+		SegmentedResponseAccepted: true,
+		Payload:                   &DataPayload{Bytes: []byte{0x0c}},
+	}.MarshalBinary()
+	is.NoErr(err)
+	is.Equal(hex.EncodeToString(b), "0265010c0c")
+
+	segment := APDU{
+		DataType:    ComplexAck,
+		ServiceType: ServiceConfirmedReadProperty,
+		InvokeID:    1,
+		Segmented:   true,
+		MoreFollows: true,
+		Sequence:    3,
+		WindowSize:  4,
+		Payload:     &DataPayload{Bytes: []byte{1, 2}},
+	}
+	b, err = segment.MarshalBinary()
+	is.NoErr(err)
+	is.Equal(hex.EncodeToString(b), "3c0103040c0102")
+	var decoded APDU
+	is.NoErr(decoded.UnmarshalBinary(b))
+	is.Equal(decoded, segment)
+
+	ack := APDU{DataType: SegmentAck, InvokeID: 1, Sequence: 3, WindowSize: 4, NegativeAck: true, Payload: &DataPayload{}}
+	b, err = ack.MarshalBinary()
+	is.NoErr(err)
+	is.Equal(hex.EncodeToString(b), "42010304")
+	decoded = APDU{}
+	is.NoErr(decoded.UnmarshalBinary(b))
+	is.Equal(decoded, ack)
+}
+
+func TestReassembly(t *testing.T) {
+	is := is.New(t)
+	segment := func(seq byte, more bool) *APDU {
+		return &APDU{
+			DataType:    ComplexAck,
+			ServiceType: ServiceConfirmedAuthenticate,
+			InvokeID:    7,
+			Segmented:   true,
+			MoreFollows: more,
+			Sequence:    seq,
+			WindowSize:  2,
+			Payload:     &DataPayload{Bytes: []byte{seq}},
+		}
+	}
+	r := reassembly{}
+	now := time.Now()
+	ack, whole, err := r.add(segment(0, true), now)
+	is.NoErr(err)
+	is.True(ack == nil && whole == nil)
+	//End of the window
+	ack, _, err = r.add(segment(1, true), now)
+	is.NoErr(err)
+	is.Equal(ack.DataType, SegmentAck)
+	is.Equal(ack.Sequence, byte(1))
+	is.True(!ack.NegativeAck)
+	//Segment 2 is missing at the end of the window
+	ack, _, err = r.add(segment(3, true), now)
+	is.NoErr(err)
+	is.True(ack.NegativeAck)
+	is.Equal(ack.Sequence, byte(1))
+	is.True(r.receiving(7, time.Second))
+	ack, _, err = r.add(segment(2, true), now)
+	is.NoErr(err)
+	is.Equal(ack.Sequence, byte(3))
+	is.True(!ack.NegativeAck)
+	//A segment received twice is acknowledged again
+	ack, _, err = r.add(segment(3, true), now)
+	is.NoErr(err)
+	is.Equal(ack.Sequence, byte(3))
+	ack, whole, err = r.add(segment(4, false), now)
+	is.NoErr(err)
+	is.Equal(ack.Sequence, byte(4))
+	is.Equal(whole.DataType, ComplexAck)
+	is.Equal(whole.Payload, &DataPayload{Bytes: []byte{0, 1, 2, 3, 4}})
+	is.True(!r.receiving(7, time.Second))
+
+	//Segments received out of order
+	ack, _, err = r.add(segment(1, false), now)
+	is.NoErr(err)
+	is.True(ack.NegativeAck)
+	ack, whole, err = r.add(segment(0, true), now)
+	is.NoErr(err)
+	is.True(!ack.NegativeAck)
+	is.Equal(whole.Payload, &DataPayload{Bytes: []byte{0, 1}})
+
+	//Too many segments
+	for i := 0; i < maxSegmentsAccepted; i++ {
+		_, _, err = r.add(segment(byte(i), true), now)
+		is.NoErr(err)
+	}
+	abort, whole, err := r.add(segment(maxSegmentsAccepted, true), now)
+	is.NoErr(err)
+	is.Equal(abort.DataType, Abort)
+	is.Equal(whole, abort)
+}
+
+func TestSegmentedReadProperty(t *testing.T) {
+	is := is.New(t)
+	addr := net.UDPAddr{IP: net.IPv4(10, 0, 2, 3).To4(), Port: DefaultUDPPort}
+	device := bacnet.Device{
+		ID:   bacnet.ObjectID{Type: bacnet.BacnetDevice, Instance: 10},
+		Addr: *bacnet.AddressFromUDP(addr),
+	}
+	description := strings.Repeat("long description ", 200)
+	e := encoding.NewEncoder()
+	e.ContextObjectID(0, device.ID)
+	e.ContextUnsigned(1, uint32(bacnet.Description))
+	e.ContextAbstractType(3, bacnet.PropertyValue{Value: description})
+	data := e.Bytes()
+	is.NoErr(e.Error())
+
+	m := newMemTransport()
+	m.respond = func(b []byte, _ *net.UDPAddr) []byte {
+		var bvlc BVLC
+		if bvlc.UnmarshalBinary(b) != nil || bvlc.NPDU.ADPU == nil || bvlc.NPDU.ADPU.DataType != ConfirmedServiceRequest {
+			return nil
+		}
+		request := bvlc.NPDU.ADPU
+		is.True(request.SegmentedResponseAccepted)
+		//Three segments in a single window
+		size := len(data)/3 + 1
+		for seq := 0; seq*size < len(data); seq++ {
+			end := (seq + 1) * size
+			if end > len(data) {
+				end = len(data)
+			}
+			segment, err := datagramOf(&APDU{
+				DataType:    ComplexAck,
+				ServiceType: request.ServiceType,
+				InvokeID:    request.InvokeID,
+				Segmented:   true,
+				MoreFollows: end < len(data),
+				Sequence:    byte(seq),
+				WindowSize:  4,
+				Payload:     &DataPayload{Bytes: data[seq*size : end]},
+			})
+			is.NoErr(err)
+			m.in <- datagram{data: segment, addr: &addr}
+		}
+		return nil
+	}
+	c := newMemClient(t, m)
+
+	v, err := c.ReadProperty(context.Background(), device, ReadProperty{
+		ObjectID: device.ID,
+		Property: bacnet.PropertyIdentifier{Type: bacnet.Description},
+	})
+	is.NoErr(err)
+	is.Equal(v, description)
+	//The last segment is acknowledged. Negative acks may be sent
+	//before, the segments being handled concurrently
+	acked := func() bool {
+		m.Lock()
+		defer m.Unlock()
+		for _, b := range m.written[1:] {
+			var bvlc BVLC
+			if bvlc.UnmarshalBinary(b) != nil || bvlc.NPDU.ADPU == nil {
+				continue
+			}
+			ack := bvlc.NPDU.ADPU
+			if ack.DataType == SegmentAck && !ack.NegativeAck && ack.Sequence == 2 && ack.WindowSize == 4 {
+				return true
+			}
+		}
+		return false
+	}
+	is.True(waitFor(acked))
+}
+
+func TestSegmentRequest(t *testing.T) {
+	is := is.New(t)
+	payload := &DataPayload{Bytes: make([]byte, 100)}
+	device := bacnet.Device{MaxApdu: 50, Segmentation: bacnet.SegmentationSupportReceive}
+	segments, err := segmentRequest(device, payload)
+	is.NoErr(err)
+	is.Equal(len(segments), 3)
+	is.Equal(len(segments[0]), 44)
+	is.Equal(len(segments[2]), 12)
+
+	segments, err = segmentRequest(bacnet.Device{MaxApdu: 104}, payload)
+	is.NoErr(err)
+	is.True(segments == nil)
+	segments, err = segmentRequest(bacnet.Device{}, payload)
+	is.NoErr(err)
+	is.True(segments == nil)
+	_, err = segmentRequest(bacnet.Device{MaxApdu: 50, Segmentation: bacnet.SegmentationSupportTransmit}, payload)
+	is.True(err != nil)
+}
+
+func TestSegmentedWriteProperty(t *testing.T) {
+	is := is.New(t)
+	addr := net.UDPAddr{IP: net.IPv4(10, 0, 2, 3).To4(), Port: DefaultUDPPort}
+	device := bacnet.Device{
+		ID:           bacnet.ObjectID{Type: bacnet.BacnetDevice, Instance: 10},
+		MaxApdu:      128,
+		Segmentation: bacnet.SegmentationSupportBoth,
+		Addr:         *bacnet.AddressFromUDP(addr),
+	}
+	write := WriteProperty{
+		ObjectID:      device.ID,
+		Property:      bacnet.PropertyIdentifier{Type: bacnet.Description},
+		PropertyValue: bacnet.PropertyValue{Value: strings.Repeat("long description ", 40)},
+	}
+	expected, err := write.MarshalBinary()
+	is.NoErr(err)
+
+	//The device acknowledges windows of 2 segments, and loses the
+	//third segment the first time
+	var received []byte
+	var next byte
+	var inWindow int
+	lost := false
+	m := newMemTransport()
+	segmentAck := func(invokeID, seq byte, negative bool) []byte {
+		b, _ := datagramOf(&APDU{DataType: SegmentAck, InvokeID: invokeID, Sequence: seq, WindowSize: 2, NegativeAck: negative})
+		return b
+	}
+	m.respond = func(b []byte, _ *net.UDPAddr) []byte {
+		var bvlc BVLC
+		if bvlc.UnmarshalBinary(b) != nil || bvlc.NPDU.ADPU == nil || bvlc.NPDU.ADPU.DataType != ConfirmedServiceRequest {
+			return nil
+		}
+		request := bvlc.NPDU.ADPU
+		is.True(request.Segmented)
+		if request.Sequence == 2 && !lost {
+			lost = true
+			return nil
+		}
+		if request.Sequence != next {
+			inWindow = 0
+			return segmentAck(request.InvokeID, next-1, true)
+		}
+		received = append(received, request.Payload.(*DataPayload).Bytes...)
+		next++
+		inWindow++
+		if !request.MoreFollows {
+			m.in <- datagram{data: segmentAck(request.InvokeID, request.Sequence, false), addr: &addr}
+			answer, _ := datagramOf(&APDU{
+				DataType:    SimpleAck,
+				ServiceType: request.ServiceType,
+				InvokeID:    request.InvokeID,
+				Payload:     &DataPayload{},
+			})
+			return answer
+		}
+		if request.Sequence == 0 || inWindow == 2 {
+			inWindow = 0
+			return segmentAck(request.InvokeID, request.Sequence, false)
+		}
+		return nil
+	}
+	c := newMemClient(t, m)
+	c.SetDeviceProfile(device.ID, DeviceProfile{Timeout: 100 * time.Millisecond, Retries: 2})
+
+	is.NoErr(c.WriteProperty(context.Background(), device, write))
+	is.True(lost)
+	is.Equal(received, expected)
+
+	//Devices not accepting segmented requests fail right away
+	device.Segmentation = bacnet.SegmentationSupportNone
+	is.True(c.WriteProperty(context.Background(), device, write) != nil)
+}
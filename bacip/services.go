@@ -69,8 +69,41 @@ func (iam *Iam) UnmarshalBinary(data []byte) error {
 type ReadProperty struct {
 	ObjectID bacnet.ObjectID
 	Property bacnet.PropertyIdentifier
-	//Data contains the response
+	//Data contains the response. Arrays and lists of application
+	//values are returned as []interface{}, constructed values as
+	//RawValue
 	Data interface{}
+	//raw is the encoded value, used by the helpers decoding
+	//constructed values
+	raw []byte
+}
+
+// RawValue is an encoded property value that isn't made of
+// application tagged data only. It can be written back as is with
+// WriteProperty.
+type RawValue []byte
+
+func (r RawValue) MarshalBinary() ([]byte, error) {
+	return r, nil
+}
+
+// decodeValue decodes a property value made of application tagged
+// data. If the value is constructed, it is returned as a RawValue
+func decodeValue(raw []byte) interface{} {
+	decoder := encoding.NewDecoder(raw)
+	values := []interface{}{}
+	for decoder.Len() > 0 {
+		var v interface{}
+		decoder.AppData(&v)
+		if decoder.Error() != nil {
+			return RawValue(raw)
+		}
+		values = append(values, v)
+	}
+	if len(values) == 1 {
+		return values[0]
+	}
+	return values
 }
 
 func (rp ReadProperty) MarshalBinary() ([]byte, error) {
@@ -98,8 +131,12 @@ func (rp *ReadProperty) UnmarshalBinary(data []byte) error {
 		rp.Property.ArrayIndex = nil
 		decoder.ResetError()
 	}
-	decoder.ContextAbstractType(3, &rp.Data)
-	return decoder.Error()
+	decoder.ContextRaw(3, &rp.raw)
+	if decoder.Error() != nil {
+		return decoder.Error()
+	}
+	rp.Data = decodeValue(rp.raw)
+	return nil
 }
 
 type WriteProperty struct {
@@ -117,15 +154,50 @@ func (wp WriteProperty) MarshalBinary() ([]byte, error) {
 		encoder.ContextUnsigned(2, *wp.Property.ArrayIndex)
 	}
 	encoder.ContextAbstractType(3, wp.PropertyValue)
+	if wp.Priority > bacnet.Available16 {
+		return nil, fmt.Errorf("invalid priority %d", wp.Priority)
+	}
 	if wp.Priority != 0 {
 		encoder.ContextUnsigned(4, uint32(wp.Priority))
 	}
 	return encoder.Bytes(), encoder.Error()
 }
 
+// UnmarshalBinary decodes a WriteProperty request. A primitive value
+// is decoded with its application tag as type, a Null as a
+// PropertyValue of TypeNull. Constructed values are kept encoded, as a
+// RawValue
 func (wp *WriteProperty) UnmarshalBinary(data []byte) error {
 	decoder := encoding.NewDecoder(data)
-	return decoder.Error()
+	decoder.ContextObjectID(0, &wp.ObjectID)
+	var val uint32
+	decoder.ContextValue(1, &val)
+	wp.Property.Type = bacnet.PropertyType(val)
+	wp.Property.ArrayIndex = nil
+	if decoder.IsContextTag(2) {
+		wp.Property.ArrayIndex = new(uint32)
+		decoder.ContextValue(2, wp.Property.ArrayIndex)
+	}
+	var raw []byte
+	decoder.ContextRaw(3, &raw)
+	wp.Priority = 0
+	if decoder.IsContextTag(4) {
+		decoder.ContextValue(4, &val)
+		wp.Priority = bacnet.PriorityList(val)
+	}
+	if decoder.Error() != nil {
+		return decoder.Error()
+	}
+	if wp.Priority > bacnet.Available16 {
+		return fmt.Errorf("invalid priority %d", wp.Priority)
+	}
+	switch v := decodeValue(raw).(type) {
+	case []interface{}, RawValue:
+		wp.PropertyValue = bacnet.PropertyValue{Value: RawValue(raw)}
+	default:
+		wp.PropertyValue = bacnet.PropertyValue{Type: raw[0] >> 4, Value: v}
+	}
+	return nil
 }
 
 type ApduError struct {
@@ -137,12 +209,23 @@ func (e ApduError) Error() string {
 	return fmt.Sprintf("apdu error class %v code %v", e.Class, e.Code)
 }
 func (e ApduError) MarshalBinary() ([]byte, error) {
-	panic("not implemented")
+	encoder := encoding.NewEncoder()
+	e.encode(&encoder)
+	return encoder.Bytes(), encoder.Error()
 }
 
 func (e *ApduError) UnmarshalBinary(data []byte) error {
 	decoder := encoding.NewDecoder(data)
+	e.decode(decoder)
+	return decoder.Error()
+}
+
+func (e ApduError) encode(encoder *encoding.Encoder) {
+	encoder.AppValue(bacnet.PropertyValue{Type: bacnet.TypeEnumerated, Value: uint32(e.Class)})
+	encoder.AppValue(bacnet.PropertyValue{Type: bacnet.TypeEnumerated, Value: uint32(e.Code)})
+}
+
+func (e *ApduError) decode(decoder *encoding.Decoder) {
 	decoder.AppData(&e.Class)
 	decoder.AppData(&e.Code)
-	return decoder.Error()
 }
@@ -153,6 +153,7 @@ func TestReadPropertyResp(t *testing.T) {
 					Type: bacnet.Units,
 				},
 				Data: uint32(98),
+				raw:  []byte{0x91, 0x62},
 			},
 		},
 	}
@@ -189,6 +190,37 @@ func TestWritePropertyReq(t *testing.T) {
 				Priority: 0,
 			},
 		},
+		{
+			data: "0c0500000119843e0c0000000319553f",
+			wp: WriteProperty{
+				ObjectID: bacnet.ObjectID{
+					Type:     bacnet.Trendlog,
+					Instance: 1,
+				},
+				Property: bacnet.PropertyIdentifier{
+					Type: bacnet.LogDeviceObjectProperty,
+				},
+				PropertyValue: bacnet.PropertyValue{
+					Value: DeviceObjectPropertyReference{
+						ObjectID: bacnet.ObjectID{Type: bacnet.AnalogInput, Instance: 3},
+						Property: bacnet.PropertyIdentifier{Type: bacnet.PresentValue},
+					},
+				},
+			},
+		},
+		{
+			data: "0c0500000119853e113f",
+			wp: WriteProperty{
+				ObjectID: bacnet.ObjectID{
+					Type:     bacnet.Trendlog,
+					Instance: 1,
+				},
+				Property: bacnet.PropertyIdentifier{
+					Type: bacnet.Enable,
+				},
+				PropertyValue: boolValue(true),
+			},
+		},
 	}
 	for _, tc := range ttc {
 		t.Run(tc.data, func(t *testing.T) {
@@ -199,3 +231,58 @@ func TestWritePropertyReq(t *testing.T) {
 		})
 	}
 }
+
+func TestWritePropertyDec(t *testing.T) {
+	ttc := []struct {
+		data string
+		wp   WriteProperty
+	}{
+		{
+			data: "0c0100000119553e91003f",
+			wp: WriteProperty{
+				ObjectID:      bacnet.ObjectID{Type: bacnet.BinaryOutput, Instance: 1},
+				Property:      bacnet.PropertyIdentifier{Type: bacnet.PresentValue},
+				PropertyValue: bacnet.PropertyValue{Type: bacnet.TypeEnumerated, Value: uint32(0)},
+			},
+		},
+		{
+			data: "0c0080000119553e003f4908",
+			wp: WriteProperty{
+				ObjectID:      bacnet.ObjectID{Type: bacnet.AnalogValue, Instance: 1},
+				Property:      bacnet.PropertyIdentifier{Type: bacnet.PresentValue},
+				PropertyValue: bacnet.PropertyValue{Type: bacnet.TypeNull},
+				Priority:      bacnet.ManualOperator8,
+			},
+		},
+		{
+			data: "0c0500000119843e0c0000000319553f",
+			wp: WriteProperty{
+				ObjectID:      bacnet.ObjectID{Type: bacnet.Trendlog, Instance: 1},
+				Property:      bacnet.PropertyIdentifier{Type: bacnet.LogDeviceObjectProperty},
+				PropertyValue: bacnet.PropertyValue{Value: RawValue{0x0c, 0x00, 0x00, 0x00, 0x03, 0x19, 0x55}},
+			},
+		},
+	}
+	for _, tc := range ttc {
+		t.Run(tc.data, func(t *testing.T) {
+			is := is.New(t)
+			data, err := hex.DecodeString(tc.data)
+			is.NoErr(err)
+			var wp WriteProperty
+			is.NoErr(wp.UnmarshalBinary(data))
+			is.Equal(wp, tc.wp)
+			result, err := wp.MarshalBinary()
+			is.NoErr(err)
+			is.Equal(hex.EncodeToString(result), tc.data)
+		})
+	}
+
+	is := is.New(t)
+	_, err := WriteProperty{
+		ObjectID:      bacnet.ObjectID{Type: bacnet.AnalogValue, Instance: 1},
+		Property:      bacnet.PropertyIdentifier{Type: bacnet.PresentValue},
+		PropertyValue: bacnet.PropertyValue{Type: bacnet.TypeNull},
+		Priority:      17,
+	}.MarshalBinary()
+	is.True(err != nil)
+}
@@ -0,0 +1,154 @@
+package bacip
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/REQUEA/bacnet"
+	"github.com/REQUEA/bacnet/internal/encoding"
+)
+
+// DeviceObjectReference references an object, optionally located in
+// another device
+type DeviceObjectReference struct {
+	//Device is nil if the object is in the same device
+	Device   *bacnet.ObjectID
+	ObjectID bacnet.ObjectID
+}
+
+func (r DeviceObjectReference) encode(e *encoding.Encoder) {
+	if r.Device != nil {
+		e.ContextObjectID(0, *r.Device)
+	}
+	e.ContextObjectID(1, r.ObjectID)
+}
+
+func (r *DeviceObjectReference) decode(d *encoding.Decoder) {
+	r.Device = nil
+	if d.IsContextTag(0) {
+		r.Device = new(bacnet.ObjectID)
+		d.ContextObjectID(0, r.Device)
+	}
+	d.ContextObjectID(1, &r.ObjectID)
+}
+
+//go:generate stringer -type=NodeType
+type NodeType uint32
+
+const (
+	NodeUnknown        NodeType = 0
+	NodeSystem         NodeType = 1
+	NodeNetwork        NodeType = 2
+	NodeDevice         NodeType = 3
+	NodeOrganizational NodeType = 4
+	NodeArea           NodeType = 5
+	NodeEquipment      NodeType = 6
+	NodePoint          NodeType = 7
+	NodeCollection     NodeType = 8
+	NodeProperty       NodeType = 9
+	NodeFunctional     NodeType = 10
+	NodeOther          NodeType = 11
+	NodeSubsystem      NodeType = 12
+	NodeBuilding       NodeType = 13
+	NodeFloor          NodeType = 14
+	NodeSection        NodeType = 15
+	NodeModule         NodeType = 16
+	NodeTree           NodeType = 17
+	NodeMember         NodeType = 18
+	NodeProtocol       NodeType = 19
+	NodeRoom           NodeType = 20
+	NodeZone           NodeType = 21
+)
+
+// ViewNode is a node of the hierarchy described by Structured View
+// objects. Leaves are the referenced points.
+type ViewNode struct {
+	Object DeviceObjectReference
+	Name   string
+	//NodeType is only meaningful for Structured View objects
+	NodeType NodeType
+	Children []*ViewNode
+	//Err is set if the object couldn't be read. Objects located in
+	//other devices are not read.
+	Err error
+}
+
+// BrowseStructuredView reads the Structured View object and walks its
+// subordinate-list recursively to build the hierarchy it describes.
+// Structured views referenced several times are only expanded once.
+func (c *Client) BrowseStructuredView(ctx context.Context, device bacnet.Device, view bacnet.ObjectID) (*ViewNode, error) {
+	root := &ViewNode{Object: DeviceObjectReference{ObjectID: view}}
+	err := c.browse(ctx, device, root, map[bacnet.ObjectID]struct{}{})
+	if err != nil {
+		return nil, err
+	}
+	return root, nil
+}
+
+func (c *Client) browse(ctx context.Context, device bacnet.Device, node *ViewNode, visited map[bacnet.ObjectID]struct{}) error {
+	id := node.Object.ObjectID
+	name, err := c.ReadProperty(ctx, device, ReadProperty{
+		ObjectID: id,
+		Property: bacnet.PropertyIdentifier{Type: bacnet.ObjectName},
+	})
+	if err != nil {
+		return fmt.Errorf("read name of %v: %w", id, err)
+	}
+	node.Name, _ = name.(string)
+	if id.Type != bacnet.StructuredView {
+		return nil
+	}
+	if _, ok := visited[id]; ok {
+		return nil
+	}
+	visited[id] = struct{}{}
+	nodeType, err := c.ReadProperty(ctx, device, ReadProperty{
+		ObjectID: id,
+		Property: bacnet.PropertyIdentifier{Type: bacnet.NodeType},
+	})
+	if err != nil {
+		return fmt.Errorf("read node type of %v: %w", id, err)
+	}
+	if v, ok := nodeType.(uint32); ok {
+		node.NodeType = NodeType(v)
+	}
+	raw, err := c.readRaw(ctx, device, ReadProperty{
+		ObjectID: id,
+		Property: bacnet.PropertyIdentifier{Type: bacnet.SubordinateList},
+	})
+	if err != nil {
+		return fmt.Errorf("read subordinate list of %v: %w", id, err)
+	}
+	err = decodeList(raw, func(d *encoding.Decoder) error {
+		child := &ViewNode{}
+		child.Object.decode(d)
+		node.Children = append(node.Children, child)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("decode subordinate list of %v: %w", id, err)
+	}
+	for _, child := range node.Children {
+		if child.Object.Device != nil && *child.Object.Device != device.ID {
+			continue
+		}
+		child.Err = c.browse(ctx, device, child, visited)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// Walk calls fn for the node and all its descendants, depth first.
+// depth is 0 for the node on which Walk is called
+func (n *ViewNode) Walk(fn func(node *ViewNode, depth int)) {
+	n.walk(fn, 0)
+}
+
+func (n *ViewNode) walk(fn func(node *ViewNode, depth int), depth int) {
+	fn(n, depth)
+	for _, child := range n.Children {
+		child.walk(fn, depth+1)
+	}
+}
@@ -0,0 +1,45 @@
+package bacip
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/REQUEA/bacnet"
+	"github.com/REQUEA/bacnet/internal/encoding"
+
+	"github.com/matryer/is"
+)
+
+func TestSubordinateListDecoding(t *testing.T) {
+	is := is.New(t)
+	b, _ := hex.DecodeString("1c00000001" + "0c020003e81c07400002")
+	refs := []DeviceObjectReference{}
+	err := decodeList(b, func(d *encoding.Decoder) error {
+		r := DeviceObjectReference{}
+		r.decode(d)
+		refs = append(refs, r)
+		return nil
+	})
+	is.NoErr(err)
+	dev := bacnet.ObjectID{Type: bacnet.BacnetDevice, Instance: 1000}
+	is.Equal(refs, []DeviceObjectReference{
+		{ObjectID: bacnet.ObjectID{Type: bacnet.AnalogInput, Instance: 1}},
+		{Device: &dev, ObjectID: bacnet.ObjectID{Type: bacnet.StructuredView, Instance: 2}},
+	})
+}
+
+func TestViewNodeWalk(t *testing.T) {
+	is := is.New(t)
+	root := &ViewNode{Name: "building", Children: []*ViewNode{
+		{Name: "floor 1", Children: []*ViewNode{{Name: "temperature"}}},
+		{Name: "floor 2"},
+	}}
+	var names []string
+	var depths []int
+	root.Walk(func(n *ViewNode, depth int) {
+		names = append(names, n.Name)
+		depths = append(depths, depth)
+	})
+	is.Equal(names, []string{"building", "floor 1", "temperature", "floor 2"})
+	is.Equal(depths, []int{0, 1, 2, 1})
+}
@@ -0,0 +1,259 @@
+package bacip
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/REQUEA/bacnet"
+)
+
+// ErrTenantClosed is returned by the requests made for a closed tenant
+var ErrTenantClosed = errors.New("tenant closed")
+
+// TenantOptions configures a tenant
+type TenantOptions struct {
+	//Rate is the number of confirmed requests per second the tenant
+	//can send, unlimited if 0
+	Rate float64
+	//Burst is the number of requests the tenant can send at once after
+	//being idle, 1 if 0
+	Burst int
+}
+
+// TenantStats are the counters of the confirmed requests of a tenant
+type TenantStats struct {
+	Requests uint64
+	Retries  uint64
+	Errors   uint64
+	Timeouts uint64
+}
+
+// Tenant is a consumer of a client shared with others, such as one of
+// the applications hosted on a gateway. Each tenant has its own COV
+// subscriptions, request rate budget and statistics. The requests
+// made with the context returned by Context are charged to the tenant
+type Tenant struct {
+	client *Client
+	name   string
+	budget *rateBudget
+	hook   atomic.Value
+
+	requests, retries, failures, timeouts uint64
+
+	mutex  sync.Mutex
+	closed bool
+	covs   map[covKey]COVSubscription
+	unsubs map[int]func()
+	next   int
+}
+
+// NewTenant creates a tenant of the client. name identifies the
+// tenant in the transaction events, to keep the metrics of the
+// tenants apart
+func (c *Client) NewTenant(name string, opts TenantOptions) *Tenant {
+	t := &Tenant{
+		client: c,
+		name:   name,
+		covs:   map[covKey]COVSubscription{},
+		unsubs: map[int]func(){},
+	}
+	if opts.Rate > 0 {
+		burst := opts.Burst
+		if burst < 1 {
+			burst = 1
+		}
+		t.budget = &rateBudget{interval: time.Duration(float64(time.Second) / opts.Rate), burst: burst}
+	}
+	return t
+}
+
+// Name returns the name of the tenant
+func (t *Tenant) Name() string {
+	return t.name
+}
+
+type tenantKey struct{}
+
+// Context returns a context making the requests of the client
+// charged to the tenant
+func (t *Tenant) Context(ctx context.Context) context.Context {
+	return context.WithValue(ctx, tenantKey{}, t)
+}
+
+func tenantFrom(ctx context.Context) *Tenant {
+	t, _ := ctx.Value(tenantKey{}).(*Tenant)
+	return t
+}
+
+// OnTransaction sets the function called at each step of the confirmed
+// requests of the tenant, like Client.OnTransaction
+func (t *Tenant) OnTransaction(f func(TransactionEvent)) {
+	t.hook.Store(f)
+}
+
+// Stats returns the counters of the requests of the tenant
+func (t *Tenant) Stats() TenantStats {
+	return TenantStats{
+		Requests: atomic.LoadUint64(&t.requests),
+		Retries:  atomic.LoadUint64(&t.retries),
+		Errors:   atomic.LoadUint64(&t.failures),
+		Timeouts: atomic.LoadUint64(&t.timeouts),
+	}
+}
+
+// record counts the event and passes it to the hook of the tenant
+func (t *Tenant) record(ev TransactionEvent) {
+	switch ev.Type {
+	case TransactionSent:
+		atomic.AddUint64(&t.requests, 1)
+	case TransactionRetried:
+		atomic.AddUint64(&t.retries, 1)
+	case TransactionErrored, TransactionAborted:
+		atomic.AddUint64(&t.failures, 1)
+	case TransactionTimedOut:
+		atomic.AddUint64(&t.timeouts, 1)
+	}
+	if f, _ := t.hook.Load().(func(TransactionEvent)); f != nil {
+		f(ev)
+	}
+}
+
+// acquire waits until the budget of the tenant allows a request
+func (t *Tenant) acquire(ctx context.Context) error {
+	t.mutex.Lock()
+	closed := t.closed
+	t.mutex.Unlock()
+	if closed {
+		return ErrTenantClosed
+	}
+	if t.budget == nil {
+		return nil
+	}
+	wait := t.budget.reserve(time.Now())
+	if wait <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// SubscribeCOV subscribes to the change of value notifications of the
+// object for the tenant, like Client.SubscribeCOV
+func (t *Tenant) SubscribeCOV(ctx context.Context, device bacnet.Device, object bacnet.ObjectID, confirmed bool, lifetime time.Duration, callback func(COVNotification)) (COVSubscription, error) {
+	sub, err := t.client.SubscribeCOV(t.Context(ctx), device, object, confirmed, lifetime, callback)
+	if err != nil {
+		return sub, err
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.covs[sub.key()] = sub
+	return sub, nil
+}
+
+// UnsubscribeCOV cancels a subscription of the tenant
+func (t *Tenant) UnsubscribeCOV(ctx context.Context, sub COVSubscription) error {
+	t.mutex.Lock()
+	_, ok := t.covs[sub.key()]
+	delete(t.covs, sub.key())
+	t.mutex.Unlock()
+	if !ok {
+		return fmt.Errorf("subscription %d isn't one of tenant %s", sub.ProcessID, t.name)
+	}
+	return t.client.UnsubscribeCOV(t.Context(ctx), sub)
+}
+
+// COVSubscriptions returns the subscriptions of the tenant that
+// didn't expire, sorted by process ID
+func (t *Tenant) COVSubscriptions() []COVSubscription {
+	now := time.Now()
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	subs := []COVSubscription{}
+	for _, sub := range t.covs {
+		if sub.Expires.IsZero() || now.Before(sub.Expires) {
+			subs = append(subs, sub)
+		}
+	}
+	sort.Slice(subs, func(i, j int) bool { return subs[i].ProcessID < subs[j].ProcessID })
+	return subs
+}
+
+// Subscribe adds a function called for every message received by the
+// client, until the returned function is called or the tenant is
+// closed. It must not block
+func (t *Tenant) Subscribe(f func(BVLC, net.UDPAddr)) func() {
+	unsubscribe := t.client.subscriptions.subscribe(f)
+	t.mutex.Lock()
+	id := t.next
+	t.next++
+	t.unsubs[id] = unsubscribe
+	t.mutex.Unlock()
+	return func() {
+		t.mutex.Lock()
+		delete(t.unsubs, id)
+		t.mutex.Unlock()
+		unsubscribe()
+	}
+}
+
+// Close cancels the subscriptions of the tenant. The requests made for
+// the tenant afterwards fail with ErrTenantClosed. The client isn't
+// closed
+func (t *Tenant) Close(ctx context.Context) error {
+	t.mutex.Lock()
+	t.closed = true
+	covs, unsubs := t.covs, t.unsubs
+	t.covs, t.unsubs = map[covKey]COVSubscription{}, map[int]func(){}
+	t.mutex.Unlock()
+	for _, unsubscribe := range unsubs {
+		unsubscribe()
+	}
+	var first error
+	for _, sub := range covs {
+		//The budget and the closed tenant don't apply to the cleanup
+		err := t.client.UnsubscribeCOV(ctx, sub)
+		if err != nil && first == nil {
+			first = fmt.Errorf("unsubscribe %v of %v: %w", sub.ObjectID, sub.Device.ID, err)
+		}
+	}
+	return first
+}
+
+// rateBudget spaces the requests by interval, allowing burst requests
+// at once after being idle
+type rateBudget struct {
+	sync.Mutex
+	interval time.Duration
+	burst    int
+	//next is the time the next request would be sent at without
+	//burst
+	next time.Time
+}
+
+// reserve books a request and returns how long to wait before sending
+// it
+func (b *rateBudget) reserve(now time.Time) time.Duration {
+	b.Lock()
+	defer b.Unlock()
+	if b.next.Before(now) {
+		b.next = now
+	}
+	wait := b.next.Sub(now) - time.Duration(b.burst-1)*b.interval
+	b.next = b.next.Add(b.interval)
+	if wait < 0 {
+		return 0
+	}
+	return wait
+}
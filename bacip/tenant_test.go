@@ -0,0 +1,92 @@
+package bacip
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/REQUEA/bacnet"
+	"github.com/matryer/is"
+)
+
+func TestRateBudget(t *testing.T) {
+	is := is.New(t)
+	b := rateBudget{interval: time.Second, burst: 2}
+	now := time.Now()
+	is.Equal(b.reserve(now), time.Duration(0))
+	is.Equal(b.reserve(now), time.Duration(0))
+	is.Equal(b.reserve(now), time.Second)
+	is.Equal(b.reserve(now.Add(10*time.Second)), time.Duration(0))
+}
+
+func TestTenants(t *testing.T) {
+	is := is.New(t)
+	m := newAckTransport(t)
+	c := newMemClient(t, m)
+	device := bacnet.Device{
+		ID:      bacnet.ObjectID{Type: bacnet.BacnetDevice, Instance: 3},
+		MaxApdu: 480,
+		Addr:    *bacnet.AddressFromUDP(net.UDPAddr{IP: net.IPv4(10, 0, 2, 3).To4(), Port: DefaultUDPPort}),
+	}
+	object := bacnet.ObjectID{Type: bacnet.AnalogValue, Instance: 1}
+	write := func(ctx context.Context) error {
+		return c.WriteProperty(ctx, device, WriteProperty{
+			ObjectID:      object,
+			Property:      bacnet.PropertyIdentifier{Type: bacnet.PresentValue},
+			PropertyValue: bacnet.PropertyValue{Type: bacnet.TypeReal, Value: float32(21)},
+		})
+	}
+	var mutex sync.Mutex
+	var global []string
+	c.OnTransaction(func(ev TransactionEvent) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if ev.Type == TransactionSent {
+			global = append(global, ev.Tenant)
+		}
+	})
+
+	analytics := c.NewTenant("analytics", TenantOptions{Rate: 20})
+	hvac := c.NewTenant("hvac", TenantOptions{})
+	var events []TransactionEvent
+	analytics.OnTransaction(func(ev TransactionEvent) { events = append(events, ev) })
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		is.NoErr(write(analytics.Context(context.Background())))
+	}
+	//The requests are spaced by 50ms
+	is.True(time.Since(start) >= 100*time.Millisecond)
+	is.NoErr(write(hvac.Context(context.Background())))
+	is.NoErr(write(context.Background()))
+	is.Equal(analytics.Stats(), TenantStats{Requests: 3})
+	is.Equal(hvac.Stats(), TenantStats{Requests: 1})
+	is.Equal(len(events), 6)
+	for _, ev := range events {
+		is.Equal(ev.Tenant, "analytics")
+	}
+	mutex.Lock()
+	is.Equal(global, []string{"analytics", "analytics", "analytics", "hvac", ""})
+	mutex.Unlock()
+
+	//Each tenant sees its own subscriptions
+	sub, err := hvac.SubscribeCOV(context.Background(), device, object, false, 0, func(COVNotification) {})
+	is.NoErr(err)
+	is.Equal(hvac.COVSubscriptions(), []COVSubscription{sub})
+	is.Equal(len(analytics.COVSubscriptions()), 0)
+	is.True(analytics.UnsubscribeCOV(context.Background(), sub) != nil)
+	hvac.Subscribe(func(BVLC, net.UDPAddr) {})
+
+	is.NoErr(hvac.Close(context.Background()))
+	is.Equal(len(hvac.COVSubscriptions()), 0)
+	is.Equal(len(c.COVSubscriptions()), 0)
+	is.True(errors.Is(write(hvac.Context(context.Background())), ErrTenantClosed))
+	is.Equal(hvac.Stats().Requests, uint64(2))
+	c.subscriptions.RLock()
+	is.Equal(len(c.subscriptions.subs), 0)
+	c.subscriptions.RUnlock()
+	is.NoErr(write(analytics.Context(context.Background())))
+}
@@ -0,0 +1,139 @@
+package bacip
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/REQUEA/bacnet"
+	"github.com/REQUEA/bacnet/internal/encoding"
+)
+
+// TextMessagePriority is the priority of a text message
+type TextMessagePriority uint32
+
+const (
+	TextMessageNormal TextMessagePriority = 0
+	TextMessageUrgent TextMessagePriority = 1
+)
+
+// TextMessage is a message sent to an operator or a display
+type TextMessage struct {
+	//Source is the device sending the message
+	Source bacnet.ObjectID
+	//Class is the class of the message, an uint32 or a string, nil
+	//for none
+	Class    interface{}
+	Priority TextMessagePriority
+	Message  string
+}
+
+func (m TextMessage) MarshalBinary() ([]byte, error) {
+	encoder := encoding.NewEncoder()
+	encoder.ContextObjectID(0, m.Source)
+	switch class := m.Class.(type) {
+	case nil:
+	case uint32:
+		encoder.OpeningTag(1)
+		encoder.ContextUnsigned(0, class)
+		encoder.ClosingTag(1)
+	case string:
+		encoder.OpeningTag(1)
+		encoder.ContextString(1, class)
+		encoder.ClosingTag(1)
+	default:
+		return nil, fmt.Errorf("invalid message class type %T", m.Class)
+	}
+	encoder.ContextUnsigned(2, uint32(m.Priority))
+	encoder.ContextString(3, m.Message)
+	return encoder.Bytes(), encoder.Error()
+}
+
+func (m *TextMessage) UnmarshalBinary(data []byte) error {
+	decoder := encoding.NewDecoder(data)
+	decoder.ContextObjectID(0, &m.Source)
+	m.Class = nil
+	if decoder.IsOpeningTag(1) {
+		decoder.OpeningTag(1)
+		if decoder.IsContextTag(0) {
+			var class uint32
+			decoder.ContextValue(0, &class)
+			m.Class = class
+		} else {
+			var class string
+			decoder.ContextString(1, &class)
+			m.Class = class
+		}
+		decoder.ClosingTag(1)
+	}
+	var priority uint32
+	decoder.ContextValue(2, &priority)
+	m.Priority = TextMessagePriority(priority)
+	decoder.ContextString(3, &m.Message)
+	return decoder.Error()
+}
+
+// SendTextMessage sends a confirmed text message to the device
+func (c *Client) SendTextMessage(ctx context.Context, device bacnet.Device, m TextMessage) error {
+	apdu, err := c.confirmedRequest(ctx, device, ServiceConfirmedTextMessage, &m)
+	if err != nil {
+		return err
+	}
+	if apdu.DataType == SimpleAck {
+		return nil
+	}
+	return errors.New("invalid answer")
+}
+
+// SendUnconfirmedTextMessage sends an unconfirmed text message to the
+// device, or to all the devices if device is nil
+func (c *Client) SendUnconfirmedTextMessage(device *bacnet.Device, m TextMessage) error {
+	npdu := NPDU{
+		Version:  Version1,
+		Priority: Normal,
+		HopCount: 255,
+		ADPU: &APDU{
+			DataType:    UnconfirmedServiceRequest,
+			ServiceType: ServiceUnconfirmedTextMessage,
+			Payload:     &m,
+		},
+	}
+	if device == nil {
+		_, err := c.broadcast(npdu)
+		return err
+	}
+	npdu.Destination = &device.Addr
+	npdu.Source = bacnet.AddressFromUDP(net.UDPAddr{
+		IP:   c.ipAddress,
+		Port: c.udpPort,
+	})
+	_, err := c.send(npdu)
+	return err
+}
+
+// OnTextMessage sets the function called with the text messages
+// received, and the address of their sender. The confirmed messages
+// are acknowledged once it returns. It must not block
+func (c *Client) OnTextMessage(f func(TextMessage, bacnet.Address)) {
+	c.textHook.Store(f)
+}
+
+// handleTextMessage passes a text message to the hook, and
+// acknowledges the confirmed messages
+func (c *Client) handleTextMessage(bvlc BVLC, src *net.UDPAddr) {
+	apdu := bvlc.NPDU.ADPU
+	m, ok := apdu.Payload.(*TextMessage)
+	if !ok {
+		return
+	}
+	if f, _ := c.textHook.Load().(func(TextMessage, bacnet.Address)); f != nil {
+		f(*m, SourceAddress(bvlc, *src))
+	}
+	if apdu.DataType != ConfirmedServiceRequest {
+		return
+	}
+	if err := c.acknowledge(bvlc, src); err != nil {
+		c.logger.Error("acknowledge text message: ", err)
+	}
+}
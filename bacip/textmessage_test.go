@@ -0,0 +1,81 @@
+package bacip
+
+import (
+	"context"
+	"encoding/hex"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/REQUEA/bacnet"
+	"github.com/matryer/is"
+)
+
+func TestTextMessageEncoding(t *testing.T) {
+	is := is.New(t)
+	source := bacnet.ObjectID{Type: bacnet.BacnetDevice, Instance: 3}
+	for _, tc := range []struct {
+		message TextMessage
+		hex     string
+	}{
+		{TextMessage{Source: source, Message: "hi"}, "0c0200000329003b006869"},
+		{TextMessage{Source: source, Class: uint32(5), Priority: TextMessageUrgent, Message: "hi"}, "0c020000031e09051f29013b006869"},
+		{TextMessage{Source: source, Class: "ops", Message: "hi"}, "0c020000031e1c006f70731f29003b006869"},
+	} {
+		b, err := tc.message.MarshalBinary()
+		is.NoErr(err)
+		is.Equal(hex.EncodeToString(b), tc.hex)
+		var m TextMessage
+		is.NoErr(m.UnmarshalBinary(b))
+		is.Equal(m, tc.message)
+	}
+	_, err := TextMessage{Source: source, Class: 5}.MarshalBinary()
+	is.True(err != nil)
+}
+
+func TestTextMessage(t *testing.T) {
+	is := is.New(t)
+	deviceAddr := net.UDPAddr{IP: net.IPv4(10, 0, 2, 3).To4(), Port: DefaultUDPPort}
+	device := bacnet.Device{
+		ID:   bacnet.ObjectID{Type: bacnet.BacnetDevice, Instance: 3},
+		Addr: *bacnet.AddressFromUDP(deviceAddr),
+	}
+	m := newAckTransport(t)
+	c := newMemClient(t, m)
+
+	msg := TextMessage{Source: device.ID, Class: "ops", Message: "door open"}
+	is.NoErr(c.SendTextMessage(context.Background(), device, msg))
+
+	received := make(chan TextMessage, 1)
+	c.OnTextMessage(func(m TextMessage, _ bacnet.Address) { received <- m })
+	request := &APDU{
+		DataType:    ConfirmedServiceRequest,
+		ServiceType: ServiceConfirmedTextMessage,
+		InvokeID:    42,
+		Payload:     &msg,
+	}
+	b, err := datagramOf(request)
+	is.NoErr(err)
+	m.Lock()
+	written := len(m.written)
+	m.Unlock()
+	m.in <- datagram{data: b, addr: &deviceAddr}
+	select {
+	case got := <-received:
+		is.Equal(got, msg)
+	case <-time.After(time.Second):
+		t.Fatal("text message not received")
+	}
+	//The confirmed message is acknowledged
+	is.True(waitFor(func() bool {
+		m.Lock()
+		defer m.Unlock()
+		return len(m.written) > written
+	}))
+	m.Lock()
+	var ack BVLC
+	is.NoErr(ack.UnmarshalBinary(m.written[len(m.written)-1]))
+	m.Unlock()
+	is.Equal(ack.NPDU.ADPU.DataType, SimpleAck)
+	is.Equal(ack.NPDU.ADPU.InvokeID, byte(42))
+}
@@ -0,0 +1,109 @@
+package bacip
+
+import (
+	"net"
+	"time"
+
+	"github.com/REQUEA/bacnet"
+	"github.com/REQUEA/bacnet/internal/encoding"
+)
+
+// TimeSync is the payload of the TimeSynchronization and
+// UTCTimeSynchronization services
+type TimeSync struct {
+	Date bacnet.Date
+	Time bacnet.Time
+}
+
+// TimeSyncFromTime returns the time synchronization of the given time,
+// in its location
+func TimeSyncFromTime(t time.Time) TimeSync {
+	return TimeSync{Date: bacnet.DateFromTime(t), Time: bacnet.TimeFromTime(t)}
+}
+
+func (ts TimeSync) MarshalBinary() ([]byte, error) {
+	encoder := encoding.NewEncoder()
+	encoder.AppData(ts.Date)
+	encoder.AppData(ts.Time)
+	return encoder.Bytes(), encoder.Error()
+}
+
+func (ts *TimeSync) UnmarshalBinary(data []byte) error {
+	decoder := encoding.NewDecoder(data)
+	decoder.AppData(&ts.Date)
+	decoder.AppData(&ts.Time)
+	return decoder.Error()
+}
+
+// TimeSync sends the time t, in the local time zone, to the device,
+// or to all the devices if device is nil
+func (c *Client) TimeSync(device *bacnet.Device, t time.Time) error {
+	ts := TimeSyncFromTime(t.Local())
+	return c.sendTimeSync(device, ServiceUnconfirmedTimeSync, ts)
+}
+
+// UTCTimeSync sends the time t, in UTC, to the device, or to all the
+// devices if device is nil
+func (c *Client) UTCTimeSync(device *bacnet.Device, t time.Time) error {
+	ts := TimeSyncFromTime(t.UTC())
+	return c.sendTimeSync(device, ServiceUnconfirmedUTCTimeSync, ts)
+}
+
+func (c *Client) sendTimeSync(device *bacnet.Device, service ServiceType, ts TimeSync) error {
+	npdu := NPDU{
+		Version:  Version1,
+		Priority: Normal,
+		HopCount: 255,
+		ADPU: &APDU{
+			DataType:    UnconfirmedServiceRequest,
+			ServiceType: service,
+			Payload:     &ts,
+		},
+	}
+	if device == nil {
+		_, err := c.broadcast(npdu)
+		return err
+	}
+	npdu.Destination = &device.Addr
+	npdu.Source = bacnet.AddressFromUDP(net.UDPAddr{
+		IP:   c.ipAddress,
+		Port: c.udpPort,
+	})
+	_, err := c.send(npdu)
+	return err
+}
+
+// TimeSyncMode selects the time synchronization sent automatically to
+// the devices
+type TimeSyncMode int
+
+const (
+	TimeSyncOff TimeSyncMode = iota
+	TimeSyncLocal
+	TimeSyncUTC
+)
+
+// SetAutoTimeSync makes the client answer the devices requesting a time
+// synchronization: the devices announcing themselves for the first time,
+// which they usually do when they start, and the devices notifying a
+// restart. TimeSyncOff, the default, disables it
+func (c *Client) SetAutoTimeSync(mode TimeSyncMode) {
+	c.timeSyncMode.Store(mode)
+}
+
+// autoTimeSync synchronizes the time of the device if enabled
+func (c *Client) autoTimeSync(d bacnet.Device) {
+	mode, _ := c.timeSyncMode.Load().(TimeSyncMode)
+	var err error
+	switch mode {
+	case TimeSyncLocal:
+		err = c.TimeSync(&d, time.Now())
+	case TimeSyncUTC:
+		err = c.UTCTimeSync(&d, time.Now())
+	default:
+		return
+	}
+	if err != nil {
+		c.logger.Error("time synchronization of ", d.ID, ": ", err)
+	}
+}
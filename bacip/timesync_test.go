@@ -0,0 +1,75 @@
+package bacip
+
+import (
+	"encoding/hex"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/REQUEA/bacnet"
+	"github.com/matryer/is"
+)
+
+func TestTimeSyncEncoding(t *testing.T) {
+	is := is.New(t)
+	ts := TimeSyncFromTime(time.Date(2024, 3, 14, 13, 5, 30, 250e6, time.UTC))
+	b, err := ts.MarshalBinary()
+	is.NoErr(err)
+	is.Equal(hex.EncodeToString(b), "a47c030e04b40d051e19")
+	var ts2 TimeSync
+	is.NoErr(ts2.UnmarshalBinary(b))
+	is.Equal(ts2, ts)
+}
+
+func TestAutoTimeSync(t *testing.T) {
+	is := is.New(t)
+	m := newMemTransport()
+	c := newMemClient(t, m)
+	c.SetAutoTimeSync(TimeSyncUTC)
+
+	deviceAddr := net.UDPAddr{IP: net.IPv4(10, 0, 2, 3).To4(), Port: DefaultUDPPort}
+	iam, err := BVLC{
+		Type:     TypeBacnetIP,
+		Function: BacFuncBroadcast,
+		NPDU: NPDU{Version: Version1, Priority: Normal, ADPU: &APDU{
+			DataType:    UnconfirmedServiceRequest,
+			ServiceType: ServiceUnconfirmedIAm,
+			Payload: &Iam{
+				ObjectID:            bacnet.ObjectID{Type: bacnet.BacnetDevice, Instance: 3},
+				MaxApduLength:       1476,
+				SegmentationSupport: bacnet.SegmentationSupportBoth,
+			},
+		}},
+	}.MarshalBinary()
+	is.NoErr(err)
+	synced := func() int {
+		m.Lock()
+		defer m.Unlock()
+		n := 0
+		for _, b := range m.written {
+			var bvlc BVLC
+			if bvlc.UnmarshalBinary(b) != nil || bvlc.NPDU.ADPU == nil {
+				continue
+			}
+			if ts, ok := bvlc.NPDU.ADPU.Payload.(*TimeSync); ok && bvlc.NPDU.ADPU.ServiceType == ServiceUnconfirmedUTCTimeSync {
+				is.Equal(ts.Date.Year, time.Now().UTC().Year())
+				n++
+			}
+		}
+		return n
+	}
+	m.in <- datagram{data: iam, addr: &deviceAddr}
+	is.True(waitFor(func() bool { return synced() == 1 }))
+	//A known device isn't synchronized again when it announces itself
+	m.in <- datagram{data: iam, addr: &deviceAddr}
+	time.Sleep(50 * time.Millisecond)
+	is.Equal(synced(), 1)
+
+	is.NoErr(c.TimeSync(nil, time.Now()))
+	m.Lock()
+	var bvlc BVLC
+	is.NoErr(bvlc.UnmarshalBinary(m.written[len(m.written)-1]))
+	m.Unlock()
+	is.Equal(bvlc.Function, BacFuncBroadcast)
+	is.Equal(bvlc.NPDU.ADPU.ServiceType, ServiceUnconfirmedTimeSync)
+}
@@ -3,11 +3,23 @@ package bacip
 import (
 	"context"
 	"sync"
+	"time"
+
+	"github.com/REQUEA/bacnet"
 )
 
 type Tx struct {
 	APDU chan<- APDU
 	Ctx  context.Context
+
+	//future, if set, is completed by the answer instead of sending it
+	//to the channel
+	future *Future
+
+	//The following fields describe the request, for debugging
+	started     time.Time
+	service     ServiceType
+	destination bacnet.Address
 }
 type Transactions struct {
 	sync.Mutex
@@ -44,8 +56,31 @@ func (t *Transactions) SetTransaction(id byte, apdu chan<- APDU, ctx context.Con
 	t.Lock()
 	defer t.Unlock()
 	t.currents[id] = Tx{
-		APDU: apdu,
-		Ctx:  ctx,
+		APDU:    apdu,
+		Ctx:     ctx,
+		started: time.Now(),
+	}
+}
+
+// setFuture sets up the future as the handler of the bacnet response
+func (t *Transactions) setFuture(id byte, f *Future) {
+	t.Lock()
+	defer t.Unlock()
+	t.currents[id] = Tx{
+		Ctx:     f.ctx,
+		future:  f,
+		started: time.Now(),
+	}
+}
+
+// describe records the request of a transaction
+func (t *Transactions) describe(id byte, service ServiceType, destination bacnet.Address) {
+	t.Lock()
+	defer t.Unlock()
+	if tx, ok := t.currents[id]; ok {
+		tx.service = service
+		tx.destination = destination
+		t.currents[id] = tx
 	}
 }
 
@@ -0,0 +1,344 @@
+package bacip
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/REQUEA/bacnet"
+	"github.com/REQUEA/bacnet/internal/encoding"
+)
+
+// TrendLogConfig contains the configuration properties of a Trend
+// Log object. Nil fields are left unchanged on the device
+type TrendLogConfig struct {
+	LogProperty *DeviceObjectPropertyReference
+	//LogInterval is sent in hundredths of seconds. 0 means that
+	//the trend log uses COV instead of polling
+	LogInterval  *time.Duration
+	Enable       *bool
+	BufferSize   *uint32
+	StopWhenFull *bool
+}
+
+// Validate checks the values before sending them to a device
+func (cfg TrendLogConfig) Validate() error {
+	if ref := cfg.LogProperty; ref != nil {
+		if ref.ObjectID.Instance > bacnet.MaxInstance {
+			return fmt.Errorf("invalid log property: object instance %d is too high", ref.ObjectID.Instance)
+		}
+		switch ref.Property.Type {
+		case bacnet.All, bacnet.Required, bacnet.Optional:
+			return fmt.Errorf("invalid log property: %v can't be logged", ref.Property.Type)
+		}
+		if ref.Device != nil && ref.Device.Type != bacnet.BacnetDevice {
+			return fmt.Errorf("invalid log property: %v isn't a device", *ref.Device)
+		}
+	}
+	if cfg.LogInterval != nil {
+		if *cfg.LogInterval < 0 {
+			return errors.New("invalid log interval: negative duration")
+		}
+		if *cfg.LogInterval/(10*time.Millisecond) > math.MaxUint32 {
+			return fmt.Errorf("invalid log interval: %v is too long", *cfg.LogInterval)
+		}
+		if *cfg.LogInterval%(10*time.Millisecond) != 0 {
+			return fmt.Errorf("invalid log interval: %v isn't a multiple of 10ms", *cfg.LogInterval)
+		}
+	}
+	if cfg.BufferSize != nil && *cfg.BufferSize == 0 {
+		return errors.New("invalid buffer size: must not be 0")
+	}
+	return nil
+}
+
+// ConfigureTrendLog writes the non nil fields of cfg to the trend
+// log. The trend log is disabled while it's reconfigured if
+// cfg.Enable is set, as many devices refuse to change the logged
+// property or the buffer size of an enabled log. If a write fails, an
+// enabled log is enabled again before returning the error.
+func (c *Client) ConfigureTrendLog(ctx context.Context, device bacnet.Device, trendLog bacnet.ObjectID, cfg TrendLogConfig) error {
+	if trendLog.Type != bacnet.Trendlog {
+		return fmt.Errorf("%v isn't a trend log", trendLog)
+	}
+	err := cfg.Validate()
+	if err != nil {
+		return err
+	}
+	writeWith := func(ctx context.Context, prop bacnet.PropertyType, value bacnet.PropertyValue) error {
+		err := c.WriteProperty(ctx, device, WriteProperty{
+			ObjectID:      trendLog,
+			Property:      bacnet.PropertyIdentifier{Type: prop},
+			PropertyValue: value,
+		})
+		if err != nil {
+			return fmt.Errorf("write %v: %w", prop, err)
+		}
+		return nil
+	}
+	write := func(prop bacnet.PropertyType, value bacnet.PropertyValue) error {
+		return writeWith(ctx, prop, value)
+	}
+	if cfg.Enable == nil {
+		return writeTrendLogConfig(write, cfg)
+	}
+	enabled, err := c.ReadProperty(ctx, device, ReadProperty{
+		ObjectID: trendLog,
+		Property: bacnet.PropertyIdentifier{Type: bacnet.Enable},
+	})
+	if err != nil {
+		return fmt.Errorf("read %v: %w", bacnet.Enable, err)
+	}
+	err = write(bacnet.Enable, boolValue(false))
+	if err != nil {
+		return err
+	}
+	err = writeTrendLogConfig(write, cfg)
+	if err != nil {
+		//Best effort to leave a running log running, even if ctx is
+		//done
+		if wasEnabled, _ := enabled.(bool); wasEnabled {
+			cleanupCtx, cancel := cleanupContext()
+			defer cancel()
+			if restoreErr := writeWith(cleanupCtx, bacnet.Enable, boolValue(true)); restoreErr != nil {
+				return fmt.Errorf("%w; enabling it again failed: %v", err, restoreErr)
+			}
+		}
+		return err
+	}
+	if *cfg.Enable {
+		return write(bacnet.Enable, boolValue(true))
+	}
+	return nil
+}
+
+// writeTrendLogConfig writes the non nil fields of cfg but Enable,
+// stopping at the first failure
+func writeTrendLogConfig(write func(bacnet.PropertyType, bacnet.PropertyValue) error, cfg TrendLogConfig) error {
+	if cfg.LogProperty != nil {
+		err := write(bacnet.LogDeviceObjectProperty, bacnet.PropertyValue{Value: *cfg.LogProperty})
+		if err != nil {
+			return err
+		}
+	}
+	if cfg.LogInterval != nil {
+		hundredths := uint32(*cfg.LogInterval / (10 * time.Millisecond))
+		err := write(bacnet.LogInterval, bacnet.PropertyValue{Value: hundredths})
+		if err != nil {
+			return err
+		}
+	}
+	if cfg.BufferSize != nil {
+		err := write(bacnet.BufferSize, bacnet.PropertyValue{Value: *cfg.BufferSize})
+		if err != nil {
+			return err
+		}
+	}
+	if cfg.StopWhenFull != nil {
+		return write(bacnet.StopWhenFull, boolValue(*cfg.StopWhenFull))
+	}
+	return nil
+}
+
+// boolValue returns a property value encoded as a bacnet boolean, and
+// not as an enumeration like binary present values
+func boolValue(v bool) bacnet.PropertyValue {
+	return bacnet.PropertyValue{Type: bacnet.TypeBoolean, Value: v}
+}
+
+//go:generate stringer -type=LogDatumKind
+type LogDatumKind byte
+
+const (
+	LogDatumStatus     LogDatumKind = 0
+	LogDatumBoolean    LogDatumKind = 1
+	LogDatumReal       LogDatumKind = 2
+	LogDatumEnumerated LogDatumKind = 3
+	LogDatumUnsigned   LogDatumKind = 4
+	LogDatumSigned     LogDatumKind = 5
+	LogDatumBitString  LogDatumKind = 6
+	LogDatumNull       LogDatumKind = 7
+	LogDatumFailure    LogDatumKind = 8
+	LogDatumTimeChange LogDatumKind = 9
+	LogDatumAny        LogDatumKind = 10
+)
+
+// TrendLogRecord is an entry of the log buffer of a Trend Log object.
+// The type of Value depends on Kind: LogStatus, bool, float32,
+// uint32 for enumerated and unsigned values, int32, bacnet.BitString,
+// nil, ApduError, float32 for the clock adjustment in seconds, or the
+// encoded bytes of any other value
+type TrendLogRecord struct {
+	Timestamp   bacnet.DateTime
+	Kind        LogDatumKind
+	Value       interface{}
+	StatusFlags *StatusFlags
+}
+
+func (r TrendLogRecord) encode(e *encoding.Encoder) error {
+	e.OpeningTag(0)
+	encodeDateTime(e, r.Timestamp)
+	e.ClosingTag(0)
+	e.OpeningTag(1)
+	tag := byte(r.Kind)
+	var ok bool
+	switch r.Kind {
+	case LogDatumStatus:
+		var v LogStatus
+		if v, ok = r.Value.(LogStatus); ok {
+			e.ContextBitString(tag, v.bits())
+		}
+	case LogDatumBoolean:
+		var v bool
+		if v, ok = r.Value.(bool); ok {
+			e.ContextBool(tag, v)
+		}
+	case LogDatumReal, LogDatumTimeChange:
+		var v float32
+		if v, ok = r.Value.(float32); ok {
+			e.ContextReal(tag, v)
+		}
+	case LogDatumEnumerated, LogDatumUnsigned:
+		var v uint32
+		if v, ok = r.Value.(uint32); ok {
+			e.ContextUnsigned(tag, v)
+		}
+	case LogDatumSigned:
+		var v int32
+		if v, ok = r.Value.(int32); ok {
+			e.ContextSigned(tag, v)
+		}
+	case LogDatumBitString:
+		var v bacnet.BitString
+		if v, ok = r.Value.(bacnet.BitString); ok {
+			e.ContextBitString(tag, v)
+		}
+	case LogDatumNull:
+		ok = r.Value == nil
+		e.ContextNull(tag)
+	case LogDatumFailure:
+		var v ApduError
+		if v, ok = r.Value.(ApduError); ok {
+			e.OpeningTag(tag)
+			v.encode(e)
+			e.ClosingTag(tag)
+		}
+	case LogDatumAny:
+		var v []byte
+		if v, ok = r.Value.([]byte); ok {
+			e.ContextRaw(tag, v)
+		}
+	default:
+		return fmt.Errorf("unknown trend log datum %d", r.Kind)
+	}
+	if !ok {
+		return fmt.Errorf("invalid value %T for trend log datum %v", r.Value, r.Kind)
+	}
+	e.ClosingTag(1)
+	if r.StatusFlags != nil {
+		e.ContextBitString(2, r.StatusFlags.bits())
+	}
+	return nil
+}
+
+func (r *TrendLogRecord) decode(d *encoding.Decoder) error {
+	d.OpeningTag(0)
+	decodeDateTime(d, &r.Timestamp)
+	d.ClosingTag(0)
+	d.OpeningTag(1)
+	switch {
+	case d.IsContextTag(0):
+		var bs bacnet.BitString
+		d.ContextBitString(0, &bs)
+		r.Kind, r.Value = LogDatumStatus, logStatusFromBits(bs)
+	case d.IsContextTag(1):
+		var v bool
+		d.ContextBool(1, &v)
+		r.Kind, r.Value = LogDatumBoolean, v
+	case d.IsContextTag(2), d.IsContextTag(9):
+		r.Kind = LogDatumReal
+		if d.IsContextTag(9) {
+			r.Kind = LogDatumTimeChange
+		}
+		var v float32
+		d.ContextReal(byte(r.Kind), &v)
+		r.Value = v
+	case d.IsContextTag(3), d.IsContextTag(4):
+		r.Kind = LogDatumEnumerated
+		if d.IsContextTag(4) {
+			r.Kind = LogDatumUnsigned
+		}
+		var v uint32
+		d.ContextValue(byte(r.Kind), &v)
+		r.Value = v
+	case d.IsContextTag(5):
+		var v int32
+		d.ContextSigned(5, &v)
+		r.Kind, r.Value = LogDatumSigned, v
+	case d.IsContextTag(6):
+		var v bacnet.BitString
+		d.ContextBitString(6, &v)
+		r.Kind, r.Value = LogDatumBitString, v
+	case d.IsContextTag(7):
+		d.ContextNull(7)
+		r.Kind, r.Value = LogDatumNull, nil
+	case d.IsOpeningTag(8):
+		var v ApduError
+		d.OpeningTag(8)
+		v.decode(d)
+		d.ClosingTag(8)
+		r.Kind, r.Value = LogDatumFailure, v
+	case d.IsOpeningTag(10):
+		var v []byte
+		d.ContextRaw(10, &v)
+		r.Kind, r.Value = LogDatumAny, v
+	default:
+		return fmt.Errorf("unknown trend log datum")
+	}
+	d.ClosingTag(1)
+	if d.IsContextTag(2) {
+		var bs bacnet.BitString
+		d.ContextBitString(2, &bs)
+		flags := StatusFlagsFromBits(bs)
+		r.StatusFlags = &flags
+	}
+	return d.Error()
+}
+
+// DecodeTrendLogRecords decodes the items returned by a ReadRange of
+// the log buffer of a Trend Log object
+func DecodeTrendLogRecords(itemData []byte) ([]TrendLogRecord, error) {
+	var records []TrendLogRecord
+	err := decodeList(itemData, func(d *encoding.Decoder) error {
+		r := TrendLogRecord{}
+		err := r.decode(d)
+		records = append(records, r)
+		return err
+	})
+	return records, err
+}
+
+// ReadTrendLog reads the records of the log buffer of a Trend Log
+// object selected by rng, or the whole buffer if rng is nil. The
+// ReadRange acknowledgment is also returned to allow the caller to
+// check if more items are available.
+func (c *Client) ReadTrendLog(ctx context.Context, device bacnet.Device, trendLog bacnet.ObjectID, rng *Range) ([]TrendLogRecord, ReadRange, error) {
+	if trendLog.Type != bacnet.Trendlog {
+		return nil, ReadRange{}, fmt.Errorf("%v isn't a trend log", trendLog)
+	}
+	ack, err := c.ReadRange(ctx, device, ReadRange{
+		ObjectID: trendLog,
+		Property: bacnet.PropertyIdentifier{Type: bacnet.LogBuffer},
+		Range:    rng,
+	})
+	if err != nil {
+		return nil, ack, err
+	}
+	records, err := DecodeTrendLogRecords(ack.ItemData)
+	if err != nil {
+		return nil, ack, fmt.Errorf("decode trend log records: %w", err)
+	}
+	return records, ack, nil
+}
@@ -0,0 +1,147 @@
+package bacip
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/REQUEA/bacnet"
+)
+
+// AbortReason tells why a device aborted a transaction
+type AbortReason byte
+
+//go:generate stringer -type=AbortReason
+const (
+	AbortReasonOther                         AbortReason = 0
+	AbortReasonBufferOverflow                AbortReason = 1
+	AbortReasonInvalidAPDUInThisState        AbortReason = 2
+	AbortReasonPreemptedByHigherPriorityTask AbortReason = 3
+	AbortReasonSegmentationNotSupported      AbortReason = 4
+	AbortReasonSecurityError                 AbortReason = 5
+	AbortReasonInsufficientSecurity          AbortReason = 6
+	AbortReasonWindowSizeOutOfRange          AbortReason = 7
+	AbortReasonApplicationExceededReplyTime  AbortReason = 8
+	AbortReasonOutOfResources                AbortReason = 9
+	AbortReasonTSMTimeout                    AbortReason = 10
+	AbortReasonAPDUTooLong                   AbortReason = 11
+)
+
+// RejectReason tells why a device rejected a request
+type RejectReason byte
+
+//go:generate stringer -type=RejectReason
+const (
+	RejectReasonOther                    RejectReason = 0
+	RejectReasonBufferOverflow           RejectReason = 1
+	RejectReasonInconsistentParameters   RejectReason = 2
+	RejectReasonInvalidParameterDataType RejectReason = 3
+	RejectReasonInvalidTag               RejectReason = 4
+	RejectReasonMissingRequiredParameter RejectReason = 5
+	RejectReasonParameterOutOfRange      RejectReason = 6
+	RejectReasonTooManyArguments         RejectReason = 7
+	RejectReasonUndefinedEnumeration     RejectReason = 8
+	RejectReasonUnrecognizedService      RejectReason = 9
+)
+
+// AbortError is returned when the device aborts a transaction
+type AbortError struct {
+	Reason AbortReason
+}
+
+func (e AbortError) Error() string {
+	return fmt.Sprintf("transaction aborted: %v", e.Reason)
+}
+
+// RejectError is returned when the device rejects a request, usually
+// because it's malformed or not supported
+type RejectError struct {
+	Reason RejectReason
+}
+
+func (e RejectError) Error() string {
+	return fmt.Sprintf("request rejected: %v", e.Reason)
+}
+
+// TransactionEventType is a step of the life of a confirmed request
+type TransactionEventType byte
+
+//go:generate stringer -type=TransactionEventType
+const (
+	//TransactionSent is emitted when the request is first sent
+	TransactionSent TransactionEventType = iota
+	//TransactionRetried is emitted when the request is sent again
+	//after the APDU timeout of the device profile
+	TransactionRetried
+	//TransactionAcked is emitted when the device answered with an
+	//acknowledgment
+	TransactionAcked
+	//TransactionErrored is emitted when the request couldn't be sent,
+	//or the device answered with an error or a reject
+	TransactionErrored
+	//TransactionTimedOut is emitted when no answer was received
+	//before the timeout of the last attempt or the context deadline
+	TransactionTimedOut
+	//TransactionAborted is emitted when the device aborted the
+	//transaction or the context was canceled
+	TransactionAborted
+)
+
+// TransactionEvent describes a step of a confirmed request
+type TransactionEvent struct {
+	Type     TransactionEventType
+	InvokeID byte
+	Service  ServiceType
+	Device   bacnet.Device
+	//Attempt is 1 for the first transmission of the request
+	Attempt int
+	//Elapsed is the time since the request was first sent
+	Elapsed time.Duration
+	//RoundTrip is the time since the last transmission, set when an
+	//answer is received
+	RoundTrip time.Duration
+	Err       error
+	//Tenant is the name of the tenant the request was made for, empty
+	//if none
+	Tenant string
+	tenant *Tenant
+}
+
+// OnTransaction sets the function called at each step of the confirmed
+// requests, for instance to measure the response time of the devices.
+// It's called synchronously and must not block
+func (c *Client) OnTransaction(f func(TransactionEvent)) {
+	c.txHook.Store(f)
+}
+
+func (c *Client) emit(ev TransactionEvent, t TransactionEventType, start, sent time.Time, err error) {
+	f, _ := c.txHook.Load().(func(TransactionEvent))
+	if f == nil && ev.tenant == nil {
+		return
+	}
+	now := time.Now()
+	ev.Type = t
+	ev.Elapsed = now.Sub(start)
+	ev.Err = err
+	switch t {
+	case TransactionAcked, TransactionErrored, TransactionAborted:
+		if err == nil || isDeviceAnswer(err) {
+			ev.RoundTrip = now.Sub(sent)
+		}
+	}
+	if ev.tenant != nil {
+		ev.tenant.record(ev)
+	}
+	if f != nil {
+		f(ev)
+	}
+}
+
+// isDeviceAnswer tells if the error was sent by the device
+func isDeviceAnswer(err error) bool {
+	switch err.(type) {
+	case ApduError, RejectError, AbortError:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,86 @@
+package bacip
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/REQUEA/bacnet"
+
+	"github.com/matryer/is"
+)
+
+// newTestClient returns a client whose requests are answered by
+// respond, called with the invoke ID of the confirmed requests
+func newTestClient(t *testing.T, respond func(invokeID byte) []byte) *Client {
+	m := newMemTransport()
+	m.respond = func(b []byte, _ *net.UDPAddr) []byte {
+		//BVLC (4 bytes), NPDU without addresses (2 bytes), then the
+		//PDU type and the max segments and APDU
+		if len(b) < 9 || PDUType(b[6])&0xF0 != ConfirmedServiceRequest {
+			return nil
+		}
+		return respond(b[8])
+	}
+	return newMemClient(t, m)
+}
+
+func TestTransactionEvents(t *testing.T) {
+	is := is.New(t)
+	device := bacnet.Device{
+		ID:   bacnet.ObjectID{Type: bacnet.BacnetDevice, Instance: 1},
+		Addr: *bacnet.AddressFromUDP(net.UDPAddr{IP: net.IPv4(10, 0, 2, 3).To4(), Port: DefaultUDPPort}),
+	}
+	write := WriteProperty{
+		ObjectID:      bacnet.ObjectID{Type: bacnet.AnalogValue, Instance: 1},
+		Property:      bacnet.PropertyIdentifier{Type: bacnet.PresentValue},
+		PropertyValue: bacnet.PropertyValue{Value: float32(1)},
+	}
+	var mutex sync.Mutex
+	var events []TransactionEventType
+	record := func(ev TransactionEvent) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		events = append(events, ev.Type)
+	}
+
+	answer := false
+	c := newTestClient(t, func(invokeID byte) []byte {
+		if !answer {
+			return nil
+		}
+		//Simple ack of WriteProperty
+		return []byte{0x81, 0x0a, 0x00, 0x09, 0x01, 0x00, 0x20, invokeID, 0x0f}
+	})
+	c.OnTransaction(record)
+	c.SetDeviceProfile(device.ID, DeviceProfile{Timeout: 10 * time.Millisecond, Retries: 1})
+	err := c.WriteProperty(context.Background(), device, write)
+	is.True(errors.Is(err, context.DeadlineExceeded))
+	is.Equal(events, []TransactionEventType{TransactionSent, TransactionRetried, TransactionTimedOut})
+
+	events = nil
+	answer = true
+	c.SetDeviceProfile(device.ID, DeviceProfile{})
+	is.NoErr(c.WriteProperty(context.Background(), device, write))
+	is.Equal(events, []TransactionEventType{TransactionSent, TransactionAcked})
+}
+
+func TestAbort(t *testing.T) {
+	is := is.New(t)
+	device := bacnet.Device{
+		ID:   bacnet.ObjectID{Type: bacnet.BacnetDevice, Instance: 1},
+		Addr: *bacnet.AddressFromUDP(net.UDPAddr{IP: net.IPv4(10, 0, 2, 3).To4(), Port: DefaultUDPPort}),
+	}
+	c := newTestClient(t, func(invokeID byte) []byte {
+		//Abort sent by the server, segmentation not supported
+		return []byte{0x81, 0x0a, 0x00, 0x09, 0x01, 0x00, 0x71, invokeID, 0x04}
+	})
+	_, err := c.ReadProperty(context.Background(), device, ReadProperty{
+		ObjectID: device.ID,
+		Property: bacnet.PropertyIdentifier{Type: bacnet.ObjectList},
+	})
+	is.Equal(err, AbortError{Reason: AbortReasonSegmentationNotSupported})
+}
@@ -0,0 +1,138 @@
+package bacip
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/REQUEA/bacnet"
+)
+
+// ErrTypeMismatch is returned, wrapped in a TypeMismatchError, when the
+// value read can't be converted to the requested type
+var ErrTypeMismatch = errors.New("type mismatch")
+
+// TypeMismatchError describes a value read that can't be converted to
+// the type requested by Read
+type TypeMismatchError struct {
+	ObjectID bacnet.ObjectID
+	Property bacnet.PropertyType
+	Type     reflect.Type
+	//Value is the value read, decoded like ReadProperty.Data
+	Value interface{}
+}
+
+func (e TypeMismatchError) Error() string {
+	return fmt.Sprintf("%v: %v of %v is %T %v, not %v", ErrTypeMismatch, e.Property, e.ObjectID, e.Value, e.Value, e.Type)
+}
+
+func (e TypeMismatchError) Unwrap() error {
+	return ErrTypeMismatch
+}
+
+// Read reads a property and returns its value as a T. The value is
+// converted when it fits T without loss: an Unsigned or Enumerated to
+// any integer type, including the enumerations of the bacnet package,
+// an integer to a float, an Enumerated 0 or 1 to a bool, and a list of
+// values to a slice of T elements. Other values return a
+// TypeMismatchError
+func Read[T any](ctx context.Context, c *Client, device bacnet.Device, objectID bacnet.ObjectID, property bacnet.PropertyType) (T, error) {
+	var result T
+	v, err := c.ReadProperty(ctx, device, ReadProperty{
+		ObjectID: objectID,
+		Property: bacnet.PropertyIdentifier{Type: property},
+	})
+	if err != nil {
+		return result, err
+	}
+	rv := reflect.ValueOf(&result).Elem()
+	if !convertValue(v, rv) {
+		return result, TypeMismatchError{ObjectID: objectID, Property: property, Type: rv.Type(), Value: v}
+	}
+	return result, nil
+}
+
+// convertValue sets rv to the decoded value v, if it fits its type
+func convertValue(v interface{}, rv reflect.Value) bool {
+	if v == nil {
+		//Only a Null can be read as an interface or a pointer
+		switch rv.Kind() {
+		case reflect.Interface, reflect.Ptr:
+			rv.Set(reflect.Zero(rv.Type()))
+			return true
+		}
+		return false
+	}
+	value := reflect.ValueOf(v)
+	if value.Type().AssignableTo(rv.Type()) {
+		rv.Set(value)
+		return true
+	}
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, ok := toInt(v)
+		if !ok || rv.OverflowInt(i) {
+			return false
+		}
+		rv.SetInt(i)
+		return true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		i, ok := toInt(v)
+		if !ok || i < 0 || rv.OverflowUint(uint64(i)) {
+			return false
+		}
+		rv.SetUint(uint64(i))
+		return true
+	case reflect.Float32:
+		if f, ok := v.(float32); ok {
+			rv.SetFloat(float64(f))
+			return true
+		}
+		i, ok := toInt(v)
+		if !ok || float64(float32(i)) != float64(i) {
+			return false
+		}
+		rv.SetFloat(float64(i))
+		return true
+	case reflect.Float64:
+		f, ok := toFloat(v)
+		if !ok {
+			return false
+		}
+		rv.SetFloat(f)
+		return true
+	case reflect.Bool:
+		//Binary present values are enumerated
+		if i, ok := v.(uint32); ok && i <= 1 {
+			rv.SetBool(i == 1)
+			return true
+		}
+		return false
+	case reflect.Slice:
+		list, ok := v.([]interface{})
+		if !ok {
+			if value.Kind() == rv.Kind() && value.Type().ConvertibleTo(rv.Type()) {
+				//Named slice types, such as a bitstring read as []bool
+				rv.Set(value.Convert(rv.Type()))
+				return true
+			}
+			//A list of a single element
+			list = []interface{}{v}
+		}
+		slice := reflect.MakeSlice(rv.Type(), len(list), len(list))
+		for i, e := range list {
+			if !convertValue(e, slice.Index(i)) {
+				return false
+			}
+		}
+		rv.Set(slice)
+		return true
+	}
+	if value.Kind() == rv.Kind() && value.Type().ConvertibleTo(rv.Type()) {
+		//Named types, such as a string type
+		rv.Set(value.Convert(rv.Type()))
+		return true
+	}
+	return false
+}
@@ -0,0 +1,45 @@
+package bacip
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/REQUEA/bacnet"
+	"github.com/matryer/is"
+)
+
+func TestConvertValue(t *testing.T) {
+	ttc := []struct {
+		value    interface{}
+		target   interface{}
+		expected interface{}
+	}{
+		{value: float32(21.5), target: new(float32), expected: float32(21.5)},
+		{value: float32(21.5), target: new(float64), expected: float64(21.5)},
+		{value: uint32(62), target: new(bacnet.Unit), expected: bacnet.Unit(62)},
+		{value: uint32(3), target: new(int), expected: 3},
+		{value: uint32(300), target: new(uint8), expected: nil},
+		{value: int32(-1), target: new(uint32), expected: nil},
+		{value: uint32(1), target: new(bool), expected: true},
+		{value: uint32(2), target: new(bool), expected: nil},
+		{value: float64(1.5), target: new(float32), expected: nil},
+		{value: "AHU", target: new(string), expected: "AHU"},
+		{value: "AHU", target: new(float32), expected: nil},
+		{value: nil, target: new(float32), expected: nil},
+		{value: bacnet.BitString{true, false}, target: new([]bool), expected: []bool{true, false}},
+		{value: []interface{}{uint32(1), uint32(2)}, target: new([]uint16), expected: []uint16{1, 2}},
+		{value: uint32(1), target: new([]uint32), expected: []uint32{1}},
+		{value: []interface{}{uint32(1), "x"}, target: new([]uint32), expected: nil},
+	}
+	for _, tc := range ttc {
+		is := is.New(t)
+		rv := reflect.ValueOf(tc.target).Elem()
+		ok := convertValue(tc.value, rv)
+		if tc.expected == nil {
+			is.True(!ok)
+			continue
+		}
+		is.True(ok)
+		is.Equal(rv.Interface(), tc.expected)
+	}
+}
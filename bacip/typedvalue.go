@@ -0,0 +1,54 @@
+package bacip
+
+import (
+	"context"
+
+	"github.com/REQUEA/bacnet"
+)
+
+// TypedValue is a property value as read from a device. It keeps the
+// original encoding, so that writing it back with WriteProperty sends
+// exactly the bytes that were read, whatever the tags and the length
+// of the encoding used by the device. Value is the decoded value, as
+// returned by ReadProperty.
+type TypedValue struct {
+	Value interface{}
+	Raw   []byte
+}
+
+// MarshalBinary returns the original encoding of the value
+func (v TypedValue) MarshalBinary() ([]byte, error) {
+	return v.Raw, nil
+}
+
+// UnmarshalBinary keeps the encoding and decodes the value
+func (v *TypedValue) UnmarshalBinary(data []byte) error {
+	v.Raw = make([]byte, len(data))
+	copy(v.Raw, data)
+	v.Value = decodeValue(v.Raw)
+	return nil
+}
+
+// ReadTypedProperty reads a property and keeps its encoding. The read
+// cache isn't used
+func (c *Client) ReadTypedProperty(ctx context.Context, device bacnet.Device, readProp ReadProperty) (TypedValue, error) {
+	raw, err := c.readRaw(ctx, device, readProp)
+	if err != nil {
+		return TypedValue{}, err
+	}
+	v := TypedValue{}
+	err = v.UnmarshalBinary(raw)
+	return v, err
+}
+
+// CopyProperty reads a property of an object and writes it identically
+// in a property of another object, possibly of another device. The
+// value is written with the given priority, 0 for none
+func (c *Client) CopyProperty(ctx context.Context, from bacnet.Device, src ReadProperty, to bacnet.Device, dst WriteProperty) error {
+	v, err := c.ReadTypedProperty(ctx, from, src)
+	if err != nil {
+		return err
+	}
+	dst.PropertyValue = bacnet.PropertyValue{Value: v}
+	return c.WriteProperty(ctx, to, dst)
+}
@@ -0,0 +1,30 @@
+package bacip
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/REQUEA/bacnet"
+
+	"github.com/matryer/is"
+)
+
+func TestTypedValueRoundTrip(t *testing.T) {
+	is := is.New(t)
+	//Present value of analog-value:1, an unsigned encoded on two
+	//bytes instead of one
+	ack, _ := hex.DecodeString("0c0080000119553e2200053f")
+	rp := ReadProperty{}
+	is.NoErr(rp.UnmarshalBinary(ack))
+	v := TypedValue{}
+	is.NoErr(v.UnmarshalBinary(rp.raw))
+	is.Equal(v.Value, uint32(5))
+
+	b, err := WriteProperty{
+		ObjectID:      rp.ObjectID,
+		Property:      rp.Property,
+		PropertyValue: bacnet.PropertyValue{Value: v},
+	}.MarshalBinary()
+	is.NoErr(err)
+	is.Equal(hex.EncodeToString(b), "0c0080000119553e2200053f")
+}
@@ -0,0 +1,107 @@
+package bacip
+
+import (
+	"context"
+	stdencoding "encoding"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+
+	"github.com/REQUEA/bacnet"
+	"github.com/REQUEA/bacnet/internal/encoding"
+)
+
+// ErrWriteNotApplied is returned, wrapped in a WriteNotAppliedError,
+// when a device acknowledged a write but doesn't return the written
+// value when it's read back
+var ErrWriteNotApplied = errors.New("write acknowledged but not applied")
+
+// WriteNotAppliedError describes a write that the device acknowledged
+// without applying it
+type WriteNotAppliedError struct {
+	//Property is the property that was read back. It's an entry of
+	//the priority array when a commandable property was written
+	Property bacnet.PropertyIdentifier
+	Written  interface{}
+	Read     interface{}
+}
+
+func (e WriteNotAppliedError) Error() string {
+	return fmt.Sprintf("%v: wrote %v, read back %v from %v", ErrWriteNotApplied, e.Written, e.Read, e.Property.Type)
+}
+
+func (e WriteNotAppliedError) Unwrap() error {
+	return ErrWriteNotApplied
+}
+
+// WriteVerified writes the property, and reads it back once the device
+// acknowledged the write to check that it was applied. Numbers are
+// considered equal if they differ by at most tolerance, to allow for
+// the rounding done by some devices.
+//
+// When the present value is written, the entry of the priority array
+// at the write priority (16 if none) is read back instead, because
+// the present value is the one of the highest priority command, or
+// the relinquish default when the written value is a relinquish
+// (Null). If the object doesn't have a priority array, the present
+// value is read back.
+func (c *Client) WriteVerified(ctx context.Context, device bacnet.Device, writeProp WriteProperty, tolerance float64) error {
+	written, err := encodedValue(writeProp.PropertyValue)
+	if err != nil {
+		return err
+	}
+	err = c.WriteProperty(ctx, device, writeProp)
+	if err != nil {
+		return err
+	}
+	readProp := ReadProperty{ObjectID: writeProp.ObjectID, Property: writeProp.Property}
+	var raw []byte
+	if writeProp.Property.Type == bacnet.PresentValue && writeProp.Property.ArrayIndex == nil {
+		index := uint32(writeProp.Priority)
+		if index == 0 {
+			index = uint32(bacnet.Available16)
+		}
+		slot := ReadProperty{
+			ObjectID: writeProp.ObjectID,
+			Property: bacnet.PropertyIdentifier{Type: bacnet.PriorityArray, ArrayIndex: &index},
+		}
+		raw, err = c.readRaw(ctx, device, slot)
+		if err == nil {
+			readProp = slot
+		} else if !isUnknownProperty(err) {
+			return fmt.Errorf("read back priority array: %w", err)
+		}
+	}
+	if readProp.Property.Type != bacnet.PriorityArray {
+		raw, err = c.readRaw(ctx, device, readProp)
+		if err != nil {
+			return fmt.Errorf("read back: %w", err)
+		}
+	}
+	w, r := decodeValue(written), decodeValue(raw)
+	if !sameValue(w, r, tolerance) {
+		return WriteNotAppliedError{Property: readProp.Property, Written: w, Read: r}
+	}
+	return nil
+}
+
+// encodedValue returns the value as it's encoded in a WriteProperty
+// request
+func encodedValue(pv bacnet.PropertyValue) ([]byte, error) {
+	if m, ok := pv.Value.(stdencoding.BinaryMarshaler); ok {
+		return m.MarshalBinary()
+	}
+	encoder := encoding.NewEncoder()
+	encoder.AppValue(pv)
+	return encoder.Bytes(), encoder.Error()
+}
+
+func sameValue(written, read interface{}, tolerance float64) bool {
+	w, wok := toFloat(written)
+	r, rok := toFloat(read)
+	if wok && rok {
+		return math.Abs(w-r) <= tolerance
+	}
+	return reflect.DeepEqual(written, read)
+}
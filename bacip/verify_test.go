@@ -0,0 +1,38 @@
+package bacip
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/REQUEA/bacnet"
+
+	"github.com/matryer/is"
+)
+
+func TestSameValue(t *testing.T) {
+	is := is.New(t)
+	enc := func(pv bacnet.PropertyValue) interface{} {
+		b, err := encodedValue(pv)
+		is.NoErr(err)
+		return decodeValue(b)
+	}
+	is.True(sameValue(enc(bacnet.PropertyValue{Value: float32(21.5)}), float32(21.5), 0))
+	is.True(sameValue(enc(bacnet.PropertyValue{Value: float32(21.5)}), float32(21.49), 0.05))
+	is.True(!sameValue(enc(bacnet.PropertyValue{Value: float32(21.5)}), float32(20), 0.05))
+	is.True(sameValue(enc(bacnet.PropertyValue{Type: bacnet.TypeEnumerated, Value: uint32(1)}), enc(bacnet.PropertyValue{Type: bacnet.TypeEnumerated, Value: uint32(1)}), 0))
+	is.True(sameValue(enc(bacnet.PropertyValue{Value: "AHU-1"}), "AHU-1", 0))
+	is.True(!sameValue(enc(bacnet.PropertyValue{Value: "AHU-1"}), "AHU-2", 0))
+	//Relinquish
+	is.True(sameValue(enc(bacnet.PropertyValue{}), nil, 0))
+	is.True(!sameValue(enc(bacnet.PropertyValue{}), float32(0), 0))
+}
+
+func TestWriteNotAppliedError(t *testing.T) {
+	is := is.New(t)
+	var err error = WriteNotAppliedError{
+		Property: bacnet.PropertyIdentifier{Type: bacnet.PresentValue},
+		Written:  float32(1),
+		Read:     float32(0),
+	}
+	is.True(errors.Is(err, ErrWriteNotApplied))
+}
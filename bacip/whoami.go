@@ -0,0 +1,133 @@
+package bacip
+
+import (
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/REQUEA/bacnet"
+	"github.com/REQUEA/bacnet/internal/encoding"
+)
+
+// WhoAmI is broadcast by a device without device instance, or
+// without MAC address on MS/TP, to be assigned one with YouAre. The
+// device is identified by its vendor, model and serial number
+type WhoAmI struct {
+	VendorID     uint16
+	ModelName    string
+	SerialNumber string
+}
+
+func (w WhoAmI) MarshalBinary() ([]byte, error) {
+	encoder := encoding.NewEncoder()
+	encoder.AppData(uint32(w.VendorID))
+	encoder.AppData(w.ModelName)
+	encoder.AppData(w.SerialNumber)
+	return encoder.Bytes(), encoder.Error()
+}
+
+func (w *WhoAmI) UnmarshalBinary(data []byte) error {
+	decoder := encoding.NewDecoder(data)
+	decoder.AppData(&w.VendorID)
+	decoder.AppData(&w.ModelName)
+	decoder.AppData(&w.SerialNumber)
+	return decoder.Error()
+}
+
+// YouAre assigns a device instance, a MAC address or both to the
+// device of the vendor, model and serial number, as asked by its
+// WhoAmI. The fields not assigned are nil
+type YouAre struct {
+	VendorID     uint16
+	ModelName    string
+	SerialNumber string
+	DeviceID     *bacnet.ObjectID
+	MACAddress   []byte
+}
+
+func (y YouAre) MarshalBinary() ([]byte, error) {
+	if y.DeviceID == nil && y.MACAddress == nil {
+		return nil, errors.New("invalid YouAre: no device identifier nor MAC address")
+	}
+	if y.DeviceID != nil && y.DeviceID.Type != bacnet.BacnetDevice {
+		return nil, fmt.Errorf("invalid YouAre: %v isn't a device", *y.DeviceID)
+	}
+	encoder := encoding.NewEncoder()
+	encoder.AppData(uint32(y.VendorID))
+	encoder.AppData(y.ModelName)
+	encoder.AppData(y.SerialNumber)
+	if y.DeviceID != nil {
+		encoder.AppData(*y.DeviceID)
+	}
+	if y.MACAddress != nil {
+		encoder.AppData(y.MACAddress)
+	}
+	return encoder.Bytes(), encoder.Error()
+}
+
+func (y *YouAre) UnmarshalBinary(data []byte) error {
+	decoder := encoding.NewDecoder(data)
+	decoder.AppData(&y.VendorID)
+	decoder.AppData(&y.ModelName)
+	decoder.AppData(&y.SerialNumber)
+	y.DeviceID, y.MACAddress = nil, nil
+	for decoder.Len() > 0 && decoder.Error() == nil {
+		var v bacnet.PropertyValue
+		decoder.AppValue(&v)
+		switch v := v.Value.(type) {
+		case bacnet.ObjectID:
+			y.DeviceID = &v
+		case []byte:
+			y.MACAddress = v
+		default:
+			return fmt.Errorf("unexpected YouAre value %T", v)
+		}
+	}
+	return decoder.Error()
+}
+
+// OnWhoAmI sets the function called with the WhoAmI requests
+// received, and the address of their sender, to find the devices
+// waiting to be assigned a device instance. It must not block
+func (c *Client) OnWhoAmI(f func(WhoAmI, bacnet.Address)) {
+	c.whoAmIHook.Store(f)
+}
+
+// handleWhoAmI passes a WhoAmI to the hook
+func (c *Client) handleWhoAmI(bvlc BVLC, src *net.UDPAddr) {
+	w, ok := bvlc.NPDU.ADPU.Payload.(*WhoAmI)
+	if !ok {
+		return
+	}
+	if f, _ := c.whoAmIHook.Load().(func(WhoAmI, bacnet.Address)); f != nil {
+		f(*w, SourceAddress(bvlc, *src))
+	}
+}
+
+// YouAre sends the assignment to the address of the device, or
+// broadcasts it if addr is nil, as required for the devices without
+// MAC address. The device checks the vendor, model and serial number
+// of the request before applying it
+func (c *Client) YouAre(addr *bacnet.Address, y YouAre) error {
+	npdu := NPDU{
+		Version:  Version1,
+		Priority: Normal,
+		HopCount: 255,
+		ADPU: &APDU{
+			DataType:    UnconfirmedServiceRequest,
+			ServiceType: ServiceUnconfirmedYouAre,
+			Payload:     &y,
+		},
+	}
+	if addr == nil {
+		_, err := c.broadcast(npdu)
+		return err
+	}
+	npdu.Destination = addr
+	npdu.Source = bacnet.AddressFromUDP(net.UDPAddr{
+		IP:   c.ipAddress,
+		Port: c.udpPort,
+	})
+	_, err := c.send(npdu)
+	return err
+}
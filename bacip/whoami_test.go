@@ -0,0 +1,83 @@
+package bacip
+
+import (
+	"encoding/hex"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/REQUEA/bacnet"
+	"github.com/matryer/is"
+)
+
+func TestWhoAmIEncoding(t *testing.T) {
+	is := is.New(t)
+	w := WhoAmI{VendorID: 260, ModelName: "X1", SerialNumber: "42"}
+	b, err := w.MarshalBinary()
+	is.NoErr(err)
+	is.Equal(hex.EncodeToString(b), "2201047300583173003432")
+	var w2 WhoAmI
+	is.NoErr(w2.UnmarshalBinary(b))
+	is.Equal(w2, w)
+
+	id := bacnet.ObjectID{Type: bacnet.BacnetDevice, Instance: 3}
+	for _, tc := range []struct {
+		y   YouAre
+		hex string
+	}{
+		{YouAre{VendorID: 260, ModelName: "X1", SerialNumber: "42", DeviceID: &id, MACAddress: []byte{5}}, "2201047300583173003432c4020000036105"},
+		{YouAre{VendorID: 260, ModelName: "X1", SerialNumber: "42", MACAddress: []byte{5}}, "22010473005831730034326105"},
+	} {
+		b, err := tc.y.MarshalBinary()
+		is.NoErr(err)
+		is.Equal(hex.EncodeToString(b), tc.hex)
+		var y YouAre
+		is.NoErr(y.UnmarshalBinary(b))
+		is.Equal(y, tc.y)
+	}
+	_, err = YouAre{VendorID: 260}.MarshalBinary()
+	is.True(err != nil)
+	_, err = YouAre{DeviceID: &bacnet.ObjectID{Type: bacnet.AnalogValue, Instance: 3}}.MarshalBinary()
+	is.True(err != nil)
+}
+
+func TestWhoAmI(t *testing.T) {
+	is := is.New(t)
+	deviceAddr := net.UDPAddr{IP: net.IPv4(10, 0, 2, 3).To4(), Port: DefaultUDPPort}
+	m := newMemTransport()
+	c := newMemClient(t, m)
+
+	type request struct {
+		w    WhoAmI
+		addr bacnet.Address
+	}
+	received := make(chan request, 1)
+	c.OnWhoAmI(func(w WhoAmI, addr bacnet.Address) { received <- request{w, addr} })
+	w := WhoAmI{VendorID: 260, ModelName: "X1", SerialNumber: "42"}
+	b, err := datagramOf(&APDU{
+		DataType:    UnconfirmedServiceRequest,
+		ServiceType: ServiceUnconfirmedWhoAmI,
+		Payload:     &w,
+	})
+	is.NoErr(err)
+	m.in <- datagram{data: b, addr: &deviceAddr}
+	var got request
+	select {
+	case got = <-received:
+		is.Equal(got.w, w)
+	case <-time.After(time.Second):
+		t.Fatal("WhoAmI not received")
+	}
+
+	id := bacnet.ObjectID{Type: bacnet.BacnetDevice, Instance: 3}
+	y := YouAre{VendorID: w.VendorID, ModelName: w.ModelName, SerialNumber: w.SerialNumber, DeviceID: &id}
+	is.NoErr(c.YouAre(&got.addr, y))
+	m.Lock()
+	var sent BVLC
+	is.NoErr(sent.UnmarshalBinary(m.written[len(m.written)-1]))
+	to := m.to[len(m.to)-1]
+	m.Unlock()
+	is.Equal(to.String(), deviceAddr.String())
+	is.Equal(sent.NPDU.ADPU.ServiceType, ServiceUnconfirmedYouAre)
+	is.Equal(sent.NPDU.ADPU.Payload, &y)
+}
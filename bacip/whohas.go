@@ -0,0 +1,161 @@
+package bacip
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"time"
+
+	"github.com/REQUEA/bacnet"
+	"github.com/REQUEA/bacnet/internal/encoding"
+)
+
+// WhoHas looks for the devices having an object, by identifier if
+// ObjectID is set, by name otherwise
+type WhoHas struct {
+	Low, High  *uint32 //may be null if we want to check all range
+	ObjectID   *bacnet.ObjectID
+	ObjectName string
+}
+
+func (w WhoHas) MarshalBinary() ([]byte, error) {
+	encoder := encoding.NewEncoder()
+	if w.Low != nil && w.High != nil {
+		if *w.Low > bacnet.MaxInstance || *w.High > bacnet.MaxInstance {
+			return nil, fmt.Errorf("invalid WhoHas range: [%d, %d]: max value is %d", *w.Low, *w.High, bacnet.MaxInstance)
+		}
+		if *w.Low > *w.High {
+			return nil, fmt.Errorf("invalid WhoHas range: [%d, %d]: low limit is higher than high limit", *w.Low, *w.High)
+		}
+		encoder.ContextUnsigned(0, *w.Low)
+		encoder.ContextUnsigned(1, *w.High)
+	}
+	if w.ObjectID != nil {
+		encoder.ContextObjectID(2, *w.ObjectID)
+	} else if w.ObjectName != "" {
+		encoder.ContextString(3, w.ObjectName)
+	} else {
+		return nil, errors.New("invalid WhoHas: no object identifier nor name")
+	}
+	return encoder.Bytes(), encoder.Error()
+}
+
+func (w *WhoHas) UnmarshalBinary(data []byte) error {
+	decoder := encoding.NewDecoder(data)
+	*w = WhoHas{}
+	if decoder.IsContextTag(0) {
+		var low, high uint32
+		decoder.ContextValue(0, &low)
+		decoder.ContextValue(1, &high)
+		w.Low, w.High = &low, &high
+	}
+	if decoder.IsContextTag(2) {
+		var id bacnet.ObjectID
+		decoder.ContextObjectID(2, &id)
+		w.ObjectID = &id
+	} else {
+		decoder.ContextString(3, &w.ObjectName)
+	}
+	return decoder.Error()
+}
+
+// IHave is the answer of a device having the object looked up by a
+// WhoHas
+type IHave struct {
+	Device     bacnet.ObjectID
+	ObjectID   bacnet.ObjectID
+	ObjectName string
+}
+
+func (i IHave) MarshalBinary() ([]byte, error) {
+	encoder := encoding.NewEncoder()
+	encoder.AppData(i.Device)
+	encoder.AppData(i.ObjectID)
+	encoder.AppData(i.ObjectName)
+	return encoder.Bytes(), encoder.Error()
+}
+
+func (i *IHave) UnmarshalBinary(data []byte) error {
+	decoder := encoding.NewDecoder(data)
+	decoder.AppData(&i.Device)
+	decoder.AppData(&i.ObjectID)
+	decoder.AppData(&i.ObjectName)
+	return decoder.Error()
+}
+
+// WhoHas broadcasts the request and returns the answers received until
+// the timeout, sorted by device instance. The devices answering can be
+// bound with WhoIs, or automatically with SetAutoBinding
+func (c *Client) WhoHas(data WhoHas, timeout time.Duration) ([]IHave, error) {
+	npdu := NPDU{
+		Version:  Version1,
+		Priority: Normal,
+		ADPU: &APDU{
+			DataType:    UnconfirmedServiceRequest,
+			ServiceType: ServiceUnconfirmedWhoHas,
+			Payload:     &data,
+		},
+	}
+	rChan := make(chan IHave)
+	done := make(chan struct{})
+	unsubscribe := c.subscriptions.subscribe(func(bvlc BVLC, _ net.UDPAddr) {
+		apdu := bvlc.NPDU.ADPU
+		if apdu == nil || apdu.DataType != UnconfirmedServiceRequest {
+			return
+		}
+		ihave, ok := apdu.Payload.(*IHave)
+		if !ok {
+			return
+		}
+		select {
+		case rChan <- *ihave:
+		case <-done:
+		}
+	})
+	defer unsubscribe()
+	defer close(done)
+	_, err := c.broadcast(npdu)
+	if err != nil {
+		return nil, err
+	}
+	low, high := uint32(0), uint32(bacnet.MaxInstance)
+	if data.Low != nil && data.High != nil {
+		low, high = *data.Low, *data.High
+	}
+	//Use a set to deduplicate results
+	set := map[IHave]struct{}{}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for {
+		select {
+		case <-timer.C:
+			result := make([]IHave, 0, len(set))
+			for ihave := range set {
+				result = append(result, ihave)
+			}
+			sort.Slice(result, func(i, j int) bool {
+				a, b := result[i], result[j]
+				if a.Device.Instance != b.Device.Instance {
+					return a.Device.Instance < b.Device.Instance
+				}
+				return a.ObjectID.Type < b.ObjectID.Type ||
+					(a.ObjectID.Type == b.ObjectID.Type && a.ObjectID.Instance < b.ObjectID.Instance)
+			})
+			return result, nil
+		case ihave := <-rChan:
+			//The answers are broadcast, and might have been triggered
+			//by another WhoHas
+			if ihave.Device.Instance < bacnet.ObjectInstance(low) || ihave.Device.Instance > bacnet.ObjectInstance(high) {
+				continue
+			}
+			if data.ObjectID != nil && ihave.ObjectID != *data.ObjectID {
+				continue
+			}
+			if data.ObjectID == nil && ihave.ObjectName != data.ObjectName {
+				continue
+			}
+			set[ihave] = struct{}{}
+		}
+	}
+}
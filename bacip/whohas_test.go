@@ -0,0 +1,39 @@
+package bacip
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/REQUEA/bacnet"
+	"github.com/matryer/is"
+)
+
+func TestWhoHasEncoding(t *testing.T) {
+	is := is.New(t)
+	av := bacnet.ObjectID{Type: bacnet.AnalogValue, Instance: 1}
+	low, high := uint32(10), uint32(20)
+	for _, tc := range []struct {
+		whoHas WhoHas
+		hex    string
+	}{
+		{WhoHas{ObjectID: &av}, "2c00800001"},
+		{WhoHas{Low: &low, High: &high, ObjectName: "AV"}, "090a19143b004156"},
+	} {
+		b, err := tc.whoHas.MarshalBinary()
+		is.NoErr(err)
+		is.Equal(hex.EncodeToString(b), tc.hex)
+		var w WhoHas
+		is.NoErr(w.UnmarshalBinary(b))
+		is.Equal(w, tc.whoHas)
+	}
+	_, err := WhoHas{}.MarshalBinary()
+	is.True(err != nil)
+
+	ihave := IHave{Device: bacnet.ObjectID{Type: bacnet.BacnetDevice, Instance: 10}, ObjectID: av, ObjectName: "AV"}
+	b, err := ihave.MarshalBinary()
+	is.NoErr(err)
+	is.Equal(hex.EncodeToString(b), "c40200000ac40080000173004156")
+	var ihave2 IHave
+	is.NoErr(ihave2.UnmarshalBinary(b))
+	is.Equal(ihave2, ihave)
+}
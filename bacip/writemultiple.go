@@ -0,0 +1,136 @@
+package bacip
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/REQUEA/bacnet"
+	"github.com/REQUEA/bacnet/internal/encoding"
+)
+
+// PropertyWrite is a value to write to a property
+type PropertyWrite struct {
+	Property bacnet.PropertyIdentifier
+	Value    bacnet.PropertyValue
+	//Priority is only used for commandable properties, 0 for none
+	Priority bacnet.PriorityList
+}
+
+// WriteAccessSpecification lists the properties to write to an object
+type WriteAccessSpecification struct {
+	ObjectID   bacnet.ObjectID
+	Properties []PropertyWrite
+}
+
+func (s WriteAccessSpecification) encode(e *encoding.Encoder) {
+	e.ContextObjectID(0, s.ObjectID)
+	e.OpeningTag(1)
+	encodePropertyWrites(e, s.Properties)
+	e.ClosingTag(1)
+}
+
+// encodePropertyWrites encodes the writes as a list of
+// BACnetPropertyValue
+func encodePropertyWrites(e *encoding.Encoder, writes []PropertyWrite) {
+	for _, p := range writes {
+		e.ContextUnsigned(0, uint32(p.Property.Type))
+		if p.Property.ArrayIndex != nil {
+			e.ContextUnsigned(1, *p.Property.ArrayIndex)
+		}
+		e.ContextAbstractType(2, p.Value)
+		if p.Priority != 0 {
+			e.ContextUnsigned(3, uint32(p.Priority))
+		}
+	}
+}
+
+// WritePropertyMultiple writes several properties of several objects
+// in a single request
+type WritePropertyMultiple struct {
+	Specifications []WriteAccessSpecification
+}
+
+func (wpm WritePropertyMultiple) MarshalBinary() ([]byte, error) {
+	encoder := encoding.NewEncoder()
+	for _, s := range wpm.Specifications {
+		s.encode(&encoder)
+	}
+	return encoder.Bytes(), encoder.Error()
+}
+
+func (wpm *WritePropertyMultiple) UnmarshalBinary(data []byte) error {
+	return errors.New("decoding of WritePropertyMultiple requests isn't supported")
+}
+
+// WritePropertyMultipleError is returned when a write of a
+// WritePropertyMultiple request fails. The device stops at the first
+// failure: the properties written before it were written, the ones
+// after it weren't
+type WritePropertyMultipleError struct {
+	Err ApduError
+	//ObjectID and Property are the write that failed
+	ObjectID bacnet.ObjectID
+	Property bacnet.PropertyIdentifier
+}
+
+func (e WritePropertyMultipleError) Error() string {
+	return fmt.Sprintf("write %v %v: %v", e.ObjectID, e.Property.Type, e.Err)
+}
+
+func (e WritePropertyMultipleError) Unwrap() error {
+	return e.Err
+}
+
+func (e WritePropertyMultipleError) MarshalBinary() ([]byte, error) {
+	encoder := encoding.NewEncoder()
+	encoder.OpeningTag(0)
+	e.Err.encode(&encoder)
+	encoder.ClosingTag(0)
+	encoder.OpeningTag(1)
+	encoder.ContextObjectID(0, e.ObjectID)
+	encoder.ContextUnsigned(1, uint32(e.Property.Type))
+	if e.Property.ArrayIndex != nil {
+		encoder.ContextUnsigned(2, *e.Property.ArrayIndex)
+	}
+	encoder.ClosingTag(1)
+	return encoder.Bytes(), encoder.Error()
+}
+
+func (e *WritePropertyMultipleError) UnmarshalBinary(data []byte) error {
+	decoder := encoding.NewDecoder(data)
+	decoder.OpeningTag(0)
+	e.Err.decode(decoder)
+	decoder.ClosingTag(0)
+	decoder.OpeningTag(1)
+	decoder.ContextObjectID(0, &e.ObjectID)
+	var val uint32
+	decoder.ContextValue(1, &val)
+	e.Property = bacnet.PropertyIdentifier{Type: bacnet.PropertyType(val)}
+	if decoder.IsContextTag(2) {
+		e.Property.ArrayIndex = new(uint32)
+		decoder.ContextValue(2, e.Property.ArrayIndex)
+	}
+	decoder.ClosingTag(1)
+	return decoder.Error()
+}
+
+// WritePropertyMultiple writes the properties of the specifications in
+// a single request. If a write fails, a WritePropertyMultipleError
+// tells which one. The values are checked like with WriteProperty
+func (c *Client) WritePropertyMultiple(ctx context.Context, device bacnet.Device, specs []WriteAccessSpecification) error {
+	payload, writes, err := c.prepareWrite(device.ID, &WritePropertyMultiple{Specifications: specs})
+	if err != nil {
+		return err
+	}
+	//Even a failed request may have changed some values
+	defer c.invalidateWrites(device.ID, writes)
+	apdu, err := c.confirmedRequest(ctx, device, ServiceConfirmedWritePropMultiple, payload)
+	if err != nil {
+		return err
+	}
+	if apdu.DataType == SimpleAck {
+		return nil
+	}
+	return errors.New("invalid answer")
+}
@@ -0,0 +1,45 @@
+package bacip
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/REQUEA/bacnet"
+
+	"github.com/matryer/is"
+)
+
+func TestWritePropertyMultipleEncoding(t *testing.T) {
+	is := is.New(t)
+	wpm := WritePropertyMultiple{Specifications: []WriteAccessSpecification{{
+		ObjectID: bacnet.ObjectID{Type: bacnet.AnalogValue, Instance: 1},
+		Properties: []PropertyWrite{{
+			Property: bacnet.PropertyIdentifier{Type: bacnet.PresentValue},
+			Value:    bacnet.PropertyValue{Type: bacnet.TypeReal, Value: float32(21)},
+			Priority: bacnet.ManualOperator8,
+		}},
+	}}}
+	b, err := wpm.MarshalBinary()
+	is.NoErr(err)
+	is.Equal(hex.EncodeToString(b), "0c008000011e09552e4441a800002f39081f")
+}
+
+func TestWritePropertyMultipleError(t *testing.T) {
+	is := is.New(t)
+	index := uint32(2)
+	e := WritePropertyMultipleError{
+		Err:      ApduError{Class: bacnet.PropertyError, Code: bacnet.WriteAccessDenied},
+		ObjectID: bacnet.ObjectID{Type: bacnet.AnalogValue, Instance: 1},
+		Property: bacnet.PropertyIdentifier{Type: bacnet.PriorityArray, ArrayIndex: &index},
+	}
+	b, err := e.MarshalBinary()
+	is.NoErr(err)
+	is.Equal(hex.EncodeToString(b), "0e910291280f1e0c00800001195729021f")
+	e2 := WritePropertyMultipleError{}
+	is.NoErr(e2.UnmarshalBinary(b))
+	is.Equal(e2, e)
+	var apduErr ApduError
+	is.True(errors.As(error(e2), &apduErr))
+	is.Equal(apduErr.Code, bacnet.WriteAccessDenied)
+}
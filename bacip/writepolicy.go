@@ -0,0 +1,240 @@
+package bacip
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/REQUEA/bacnet"
+)
+
+// ErrWriteDenied is returned, wrapped in a WriteDeniedError, when the
+// write policy of the client denies a write
+var ErrWriteDenied = errors.New("write denied by policy")
+
+// WriteDeniedError describes a write denied by the write policy
+type WriteDeniedError struct {
+	Device   bacnet.ObjectID
+	ObjectID bacnet.ObjectID
+	Property bacnet.PropertyIdentifier
+	//Service is set for the requests other than property writes, see
+	//WriteAttempt
+	Service string
+	Reason  string
+}
+
+func (e WriteDeniedError) Error() string {
+	if e.Service != "" {
+		return fmt.Sprintf("%v: %v %s of %v: %s", ErrWriteDenied, e.Device, e.Service, e.ObjectID, e.Reason)
+	}
+	return fmt.Sprintf("%v: %v %v of %v: %s", ErrWriteDenied, e.Device, e.Property.Type, e.ObjectID, e.Reason)
+}
+
+func (e WriteDeniedError) Unwrap() error {
+	return ErrWriteDenied
+}
+
+// WritePolicy restricts the writes made by the client, so that an
+// application meant to read data can't command equipment by mistake.
+// The writes are allowed only if they pass all the restrictions. An
+// empty list doesn't restrict anything.
+//
+// The other requests changing a device, CreateObject, DeleteObject,
+// AtomicWriteFile and ReinitializeDevice, are only restricted by
+// ReadOnly and Devices. They are audited like the writes. The
+// ReinitializeDevice requests of a backup, which only reads the
+// device, are allowed
+type WritePolicy struct {
+	//ReadOnly denies all the writes
+	ReadOnly bool
+	//Devices are the only devices that can be written to
+	Devices []bacnet.ObjectID
+	//ObjectTypes are the only object types that can be written to
+	ObjectTypes []bacnet.ObjectType
+	//Properties are the only properties that can be written
+	Properties []bacnet.PropertyType
+	//MinPriority and MaxPriority are the range of the allowed
+	//priorities, 1 and 16 if 0. A write without priority is at
+	//priority 16, as devices consider it
+	MinPriority bacnet.PriorityList
+	MaxPriority bacnet.PriorityList
+	//Audit, if set, is called with every attempted write, allowed or
+	//not. The writes are logged with the logger of the client if
+	//it's nil
+	Audit func(WriteAttempt)
+}
+
+// WriteAttempt is a write checked by the write policy
+type WriteAttempt struct {
+	Time     time.Time
+	Device   bacnet.ObjectID
+	ObjectID bacnet.ObjectID
+	Property bacnet.PropertyIdentifier
+	Value    bacnet.PropertyValue
+	Priority bacnet.PriorityList
+	//Service is the name of the request for the requests other than
+	//property writes, such as DeleteObject, and empty for the writes.
+	//Only ObjectID is set for them: the object created, deleted or
+	//written, or the device reinitialized
+	Service string
+	//Denied is the reason the write was denied, nil if it's allowed
+	Denied error
+}
+
+// check returns why the write isn't allowed, nil if it is
+func (p *WritePolicy) check(a WriteAttempt) error {
+	deny := func(format string, args ...interface{}) error {
+		return WriteDeniedError{
+			Device:   a.Device,
+			ObjectID: a.ObjectID,
+			Property: a.Property,
+			Service:  a.Service,
+			Reason:   fmt.Sprintf(format, args...),
+		}
+	}
+	if p.ReadOnly {
+		return deny("read only")
+	}
+	if len(p.Devices) > 0 && !containsObjectID(p.Devices, a.Device) {
+		return deny("device not allowed")
+	}
+	if a.Service != "" {
+		//The other restrictions are about property writes
+		return nil
+	}
+	if len(p.ObjectTypes) > 0 && !containsObjectType(p.ObjectTypes, a.ObjectID.Type) {
+		return deny("object type not allowed")
+	}
+	if len(p.Properties) > 0 && !containsProperty(p.Properties, a.Property.Type) {
+		return deny("property not allowed")
+	}
+	priority, low, high := a.Priority, p.MinPriority, p.MaxPriority
+	if priority == 0 {
+		priority = bacnet.Available16
+	}
+	if low == 0 {
+		low = bacnet.ManualLifeSafety1
+	}
+	if high == 0 {
+		high = bacnet.Available16
+	}
+	if priority < low || priority > high {
+		return deny("priority %d out of %d-%d", priority, low, high)
+	}
+	return nil
+}
+
+// SetWritePolicy makes the client check every write with the policy
+// before sending it. A nil policy allows all the writes
+func (c *Client) SetWritePolicy(p *WritePolicy) {
+	c.writePolicy.Store(p)
+}
+
+// checkWrites checks the writes against the write policy, if any, and
+// audits them. It returns the error of the first denied write: the
+// writes of a request are allowed or denied together
+func (c *Client) checkWrites(device bacnet.ObjectID, specs []WriteAccessSpecification) error {
+	p, _ := c.writePolicy.Load().(*WritePolicy)
+	if p == nil {
+		return nil
+	}
+	var denied error
+	now := time.Now()
+	for _, s := range specs {
+		for _, w := range s.Properties {
+			a := WriteAttempt{
+				Time:     now,
+				Device:   device,
+				ObjectID: s.ObjectID,
+				Property: w.Property,
+				Value:    w.Value,
+				Priority: w.Priority,
+			}
+			a.Denied = p.check(a)
+			if a.Denied != nil && denied == nil {
+				denied = a.Denied
+			}
+			c.audit(p, a)
+		}
+	}
+	return denied
+}
+
+// checkRequest checks the requests changing the device other than
+// property writes against the write policy, if any, and audits them.
+// The other requests are allowed
+func (c *Client) checkRequest(device bacnet.ObjectID, payload Payload) error {
+	p, _ := c.writePolicy.Load().(*WritePolicy)
+	if p == nil {
+		return nil
+	}
+	a := WriteAttempt{Time: time.Now(), Device: device}
+	switch r := payload.(type) {
+	case *CreateObject:
+		a.Service = "CreateObject"
+		a.ObjectID = bacnet.ObjectID{Type: r.ObjectType}
+		if r.ObjectID != nil {
+			a.ObjectID = *r.ObjectID
+		}
+	case *DeleteObject:
+		a.Service = "DeleteObject"
+		a.ObjectID = r.ObjectID
+	case *AtomicWriteFile:
+		a.Service = "AtomicWriteFile"
+		a.ObjectID = r.ObjectID
+	case *ReinitializeDevice:
+		if r.State == StartBackup || r.State == EndBackup {
+			return nil
+		}
+		a.Service = "ReinitializeDevice"
+		a.ObjectID = device
+	default:
+		return nil
+	}
+	a.Denied = p.check(a)
+	c.audit(p, a)
+	return a.Denied
+}
+
+func (c *Client) audit(p *WritePolicy, a WriteAttempt) {
+	if p.Audit != nil {
+		p.Audit(a)
+		return
+	}
+	if a.Denied != nil {
+		c.logger.Error("write: ", a.Denied)
+		return
+	}
+	if a.Service != "" {
+		c.logger.Info(fmt.Sprintf("write: %v %s of %v", a.Device, a.Service, a.ObjectID))
+		return
+	}
+	c.logger.Info(fmt.Sprintf("write: %v %v of %v = %v at priority %d", a.Device, a.Property.Type, a.ObjectID, a.Value.Value, a.Priority))
+}
+
+func containsObjectID(ids []bacnet.ObjectID, id bacnet.ObjectID) bool {
+	for _, i := range ids {
+		if i == id {
+			return true
+		}
+	}
+	return false
+}
+
+func containsObjectType(types []bacnet.ObjectType, t bacnet.ObjectType) bool {
+	for _, i := range types {
+		if i == t {
+			return true
+		}
+	}
+	return false
+}
+
+func containsProperty(props []bacnet.PropertyType, p bacnet.PropertyType) bool {
+	for _, i := range props {
+		if i == p {
+			return true
+		}
+	}
+	return false
+}
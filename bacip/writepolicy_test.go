@@ -0,0 +1,144 @@
+package bacip
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/REQUEA/bacnet"
+	"github.com/matryer/is"
+)
+
+func TestWritePolicy(t *testing.T) {
+	is := is.New(t)
+	m := newAckTransport(t)
+	c := newMemClient(t, m)
+	device := func(instance bacnet.ObjectInstance) bacnet.Device {
+		return bacnet.Device{
+			ID:      bacnet.ObjectID{Type: bacnet.BacnetDevice, Instance: instance},
+			MaxApdu: 480,
+			Addr:    *bacnet.AddressFromUDP(net.UDPAddr{IP: net.IPv4(10, 0, 2, byte(instance)).To4(), Port: DefaultUDPPort}),
+		}
+	}
+	setpoint := bacnet.ObjectID{Type: bacnet.AnalogValue, Instance: 1}
+	write := func(d bacnet.Device, object bacnet.ObjectID, prop bacnet.PropertyType, priority bacnet.PriorityList) error {
+		return c.WriteProperty(context.Background(), d, WriteProperty{
+			ObjectID:      object,
+			Property:      bacnet.PropertyIdentifier{Type: prop},
+			PropertyValue: bacnet.PropertyValue{Type: bacnet.TypeReal, Value: float32(21)},
+			Priority:      priority,
+		})
+	}
+	sent := func() int {
+		m.Lock()
+		defer m.Unlock()
+		return len(m.written)
+	}
+
+	var audit []WriteAttempt
+	c.SetWritePolicy(&WritePolicy{
+		Devices:     []bacnet.ObjectID{device(3).ID},
+		ObjectTypes: []bacnet.ObjectType{bacnet.AnalogValue},
+		Properties:  []bacnet.PropertyType{bacnet.PresentValue},
+		MinPriority: bacnet.ManualOperator8,
+		Audit:       func(a WriteAttempt) { audit = append(audit, a) },
+	})
+	is.NoErr(write(device(3), setpoint, bacnet.PresentValue, 0))
+	is.NoErr(write(device(3), setpoint, bacnet.PresentValue, bacnet.ManualOperator8))
+	is.Equal(sent(), 2)
+	denied := []error{
+		write(device(4), setpoint, bacnet.PresentValue, 0),
+		write(device(3), bacnet.ObjectID{Type: bacnet.BinaryOutput, Instance: 1}, bacnet.PresentValue, 0),
+		write(device(3), setpoint, bacnet.ObjectName, 0),
+		write(device(3), setpoint, bacnet.PresentValue, bacnet.ManualLifeSafety1),
+	}
+	for _, err := range denied {
+		is.True(errors.Is(err, ErrWriteDenied))
+	}
+	var deniedErr WriteDeniedError
+	is.True(errors.As(denied[3], &deniedErr))
+	is.Equal(deniedErr.Reason, "priority 1 out of 8-16")
+	is.Equal(sent(), 2)
+	is.Equal(len(audit), 6)
+	is.True(audit[0].Denied == nil)
+	is.Equal(audit[1].Priority, bacnet.ManualOperator8)
+	is.True(audit[2].Denied != nil)
+
+	//The writes of a request are denied together
+	err := c.WritePropertyMultiple(context.Background(), device(3), []WriteAccessSpecification{{
+		ObjectID: setpoint,
+		Properties: []PropertyWrite{
+			{Property: bacnet.PropertyIdentifier{Type: bacnet.PresentValue}, Value: bacnet.PropertyValue{Type: bacnet.TypeReal, Value: float32(21)}},
+			{Property: bacnet.PropertyIdentifier{Type: bacnet.Description}, Value: bacnet.PropertyValue{Type: bacnet.TypeCharacterString, Value: "a"}},
+		},
+	}})
+	is.True(errors.Is(err, ErrWriteDenied))
+	is.Equal(sent(), 2)
+	is.Equal(len(audit), 8)
+
+	c.SetWritePolicy(&WritePolicy{ReadOnly: true})
+	is.True(errors.Is(write(device(3), setpoint, bacnet.PresentValue, 0), ErrWriteDenied))
+	c.SetWritePolicy(nil)
+	is.NoErr(write(device(4), setpoint, bacnet.PresentValue, bacnet.ManualLifeSafety1))
+	is.Equal(sent(), 3)
+}
+
+func TestWritePolicyRequests(t *testing.T) {
+	is := is.New(t)
+	m := newAckTransport(t)
+	c := newMemClient(t, m)
+	device := func(instance bacnet.ObjectInstance) bacnet.Device {
+		return bacnet.Device{
+			ID:      bacnet.ObjectID{Type: bacnet.BacnetDevice, Instance: instance},
+			MaxApdu: 480,
+			Addr:    *bacnet.AddressFromUDP(net.UDPAddr{IP: net.IPv4(10, 0, 2, byte(instance)).To4(), Port: DefaultUDPPort}),
+		}
+	}
+	file := bacnet.ObjectID{Type: bacnet.File, Instance: 1}
+	ctx := context.Background()
+
+	var audit []WriteAttempt
+	c.SetWritePolicy(&WritePolicy{
+		Devices:    []bacnet.ObjectID{device(3).ID},
+		Properties: []bacnet.PropertyType{bacnet.PresentValue},
+		Audit:      func(a WriteAttempt) { audit = append(audit, a) },
+	})
+	//The restrictions about properties don't apply
+	is.NoErr(c.DeleteObject(ctx, device(3), bacnet.ObjectID{Type: bacnet.AnalogValue, Instance: 1}))
+	is.Equal(len(audit), 1)
+	is.Equal(audit[0].Service, "DeleteObject")
+	is.Equal(audit[0].ObjectID, bacnet.ObjectID{Type: bacnet.AnalogValue, Instance: 1})
+	is.True(audit[0].Denied == nil)
+	denied := []error{
+		c.DeleteObject(ctx, device(4), bacnet.ObjectID{Type: bacnet.AnalogValue, Instance: 1}),
+		func() error {
+			_, err := c.CreateObject(ctx, device(4), CreateObject{ObjectType: bacnet.AnalogValue})
+			return err
+		}(),
+		func() error {
+			_, err := c.WriteFile(ctx, device(4), file, bytes.NewReader([]byte("data")))
+			return err
+		}(),
+		c.ReinitializeDevice(ctx, device(4), ReinitializeDevice{State: WarmStart}),
+		c.RestoreDevice(ctx, device(4), DeviceBackup{Device: device(4).ID}, BackupOptions{}),
+		c.Async(ctx, device(4), ServiceConfirmedDeleteObject, &DeleteObject{ObjectID: file}).err,
+	}
+	for _, err := range denied {
+		is.True(errors.Is(err, ErrWriteDenied))
+	}
+	is.Equal(len(audit), 7)
+	is.Equal(audit[2].ObjectID, bacnet.ObjectID{Type: bacnet.AnalogValue})
+	is.Equal(audit[4].ObjectID, device(4).ID)
+	var deniedErr WriteDeniedError
+	is.True(errors.As(denied[3], &deniedErr))
+	is.Equal(deniedErr.Error(), "write denied by policy: device:4 ReinitializeDevice of device:4: device not allowed")
+	is.Equal(m.count(), 1)
+
+	//A backup only reads the device
+	c.SetWritePolicy(&WritePolicy{ReadOnly: true})
+	is.NoErr(c.ReinitializeDevice(ctx, device(3), ReinitializeDevice{State: StartBackup}))
+	is.True(errors.Is(c.ReinitializeDevice(ctx, device(3), ReinitializeDevice{State: ColdStart}), ErrWriteDenied))
+	is.Equal(m.count(), 2)
+}
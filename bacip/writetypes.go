@@ -0,0 +1,269 @@
+package bacip
+
+import (
+	stdencoding "encoding"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/REQUEA/bacnet"
+)
+
+// ErrInvalidDatatype is returned, wrapped in a DatatypeError, when the
+// value to write doesn't have the datatype of the property
+var ErrInvalidDatatype = errors.New("invalid datatype")
+
+// DatatypeError describes a value that can't be written to a property
+// because of its datatype
+type DatatypeError struct {
+	ObjectID bacnet.ObjectID
+	Property bacnet.PropertyIdentifier
+	//Expected is the application tag of the datatype of the property
+	Expected byte
+	Value    bacnet.PropertyValue
+}
+
+func (e DatatypeError) Error() string {
+	got := fmt.Sprintf("%T", e.Value.Value)
+	if e.Value.Type != 0 {
+		got += " as " + datatypeName(e.Value.Type)
+	}
+	return fmt.Sprintf("%v: %v of %v is a %s, not %s", ErrInvalidDatatype, e.Property.Type, e.ObjectID, datatypeName(e.Expected), got)
+}
+
+func (e DatatypeError) Unwrap() error {
+	return ErrInvalidDatatype
+}
+
+func datatypeName(tag byte) string {
+	switch tag {
+	case bacnet.TypeNull:
+		return "Null"
+	case bacnet.TypeBoolean:
+		return "Boolean"
+	case bacnet.TypeUnsignedInt:
+		return "Unsigned"
+	case bacnet.TypeSignedInt:
+		return "Signed"
+	case bacnet.TypeReal:
+		return "Real"
+	case bacnet.TypeDouble:
+		return "Double"
+	case bacnet.TypeOctetString:
+		return "OctetString"
+	case bacnet.TypeCharacterString:
+		return "CharacterString"
+	case bacnet.TypeBitString:
+		return "BitString"
+	case bacnet.TypeEnumerated:
+		return "Enumerated"
+	case bacnet.TypeDate:
+		return "Date"
+	case bacnet.TypeTime:
+		return "Time"
+	case bacnet.TypeObjectID:
+		return "ObjectIdentifier"
+	default:
+		return fmt.Sprintf("tag %d", tag)
+	}
+}
+
+// presentValueDatatypes are the datatypes of the present value of the
+// object types, which is also the datatype of their relinquish default
+// and limits
+var presentValueDatatypes = map[bacnet.ObjectType]byte{
+	bacnet.AnalogInput:          bacnet.TypeReal,
+	bacnet.AnalogOutput:         bacnet.TypeReal,
+	bacnet.AnalogValue:          bacnet.TypeReal,
+	bacnet.BinaryInput:          bacnet.TypeEnumerated,
+	bacnet.BinaryOutput:         bacnet.TypeEnumerated,
+	bacnet.BinaryValue:          bacnet.TypeEnumerated,
+	bacnet.MultiStateInput:      bacnet.TypeUnsignedInt,
+	bacnet.MultiStateOutput:     bacnet.TypeUnsignedInt,
+	bacnet.MultiStateValue:      bacnet.TypeUnsignedInt,
+	bacnet.Loop:                 bacnet.TypeReal,
+	bacnet.Accumulator:          bacnet.TypeUnsignedInt,
+	bacnet.PulseConverter:       bacnet.TypeReal,
+	bacnet.BitstringValue:       bacnet.TypeBitString,
+	bacnet.CharacterstringValue: bacnet.TypeCharacterString,
+	bacnet.DateValue:            bacnet.TypeDate,
+	bacnet.IntegerValue:         bacnet.TypeSignedInt,
+	bacnet.LargeAnalogValue:     bacnet.TypeDouble,
+	bacnet.OctetstringValue:     bacnet.TypeOctetString,
+	bacnet.PositiveIntegerValue: bacnet.TypeUnsignedInt,
+	bacnet.TimeValue:            bacnet.TypeTime,
+	bacnet.LightingOutput:       bacnet.TypeReal,
+	bacnet.BinaryLightingOutput: bacnet.TypeEnumerated,
+}
+
+// propertyDatatypes are the datatypes of the standard properties that
+// don't depend on the object type
+var propertyDatatypes = map[bacnet.PropertyType]byte{
+	bacnet.ObjectName:                bacnet.TypeCharacterString,
+	bacnet.Description:               bacnet.TypeCharacterString,
+	bacnet.Location:                  bacnet.TypeCharacterString,
+	bacnet.ProfileName:               bacnet.TypeCharacterString,
+	bacnet.OutOfService:              bacnet.TypeBoolean,
+	bacnet.Units:                     bacnet.TypeEnumerated,
+	bacnet.Polarity:                  bacnet.TypeEnumerated,
+	bacnet.Reliability:               bacnet.TypeEnumerated,
+	bacnet.TimeDelay:                 bacnet.TypeUnsignedInt,
+	bacnet.MinimumOnTime:             bacnet.TypeUnsignedInt,
+	bacnet.MinimumOffTime:            bacnet.TypeUnsignedInt,
+	bacnet.NumberOfStates:            bacnet.TypeUnsignedInt,
+	bacnet.ApduTimeout:               bacnet.TypeUnsignedInt,
+	bacnet.NumberOfApduRetries:       bacnet.TypeUnsignedInt,
+	bacnet.CovResubscriptionInterval: bacnet.TypeUnsignedInt,
+	bacnet.UtcOffset:                 bacnet.TypeSignedInt,
+	bacnet.EventEnable:               bacnet.TypeBitString,
+	bacnet.LimitEnable:               bacnet.TypeBitString,
+}
+
+// propertyDatatype returns the datatype of a property of an object
+// type, if it's known
+func propertyDatatype(object bacnet.ObjectType, prop bacnet.PropertyType) (byte, bool) {
+	switch prop {
+	case bacnet.PresentValue, bacnet.RelinquishDefault, bacnet.HighLimit, bacnet.LowLimit:
+		t, ok := presentValueDatatypes[object]
+		return t, ok
+	case bacnet.CovIncrement, bacnet.Deadband:
+		if object == bacnet.IntegerValue || object == bacnet.PositiveIntegerValue {
+			return bacnet.TypeUnsignedInt, true
+		}
+		t, ok := presentValueDatatypes[object]
+		if t == bacnet.TypeReal || t == bacnet.TypeDouble {
+			return t, ok
+		}
+		return 0, false
+	}
+	t, ok := propertyDatatypes[prop]
+	return t, ok
+}
+
+// coerceWrite checks that the value can be written to the property,
+// and converts it to the go type encoding the datatype of the
+// property. The values of the properties whose datatype isn't known,
+// of array elements and the values already encoded are returned as
+// is
+func coerceWrite(objectID bacnet.ObjectID, prop bacnet.PropertyIdentifier, pv bacnet.PropertyValue) (bacnet.PropertyValue, error) {
+	expected, ok := propertyDatatype(objectID.Type, prop.Type)
+	if !ok || prop.ArrayIndex != nil {
+		return pv, nil
+	}
+	if _, ok := pv.Value.(stdencoding.BinaryMarshaler); ok {
+		return pv, nil
+	}
+	invalid := DatatypeError{ObjectID: objectID, Property: prop, Expected: expected, Value: pv}
+	if pv.Value == nil {
+		//Relinquishes a commandable property
+		if prop.Type != bacnet.PresentValue {
+			return pv, invalid
+		}
+		return bacnet.PropertyValue{Type: bacnet.TypeNull}, nil
+	}
+	if pv.Type != 0 && pv.Type != expected {
+		return pv, invalid
+	}
+	v, ok := coerceValue(expected, pv.Value)
+	if !ok {
+		return pv, invalid
+	}
+	return bacnet.PropertyValue{Type: expected, Value: v}, nil
+}
+
+// coerceValue converts the value to the go type of the datatype
+func coerceValue(datatype byte, value interface{}) (interface{}, bool) {
+	switch datatype {
+	case bacnet.TypeBoolean:
+		v, ok := value.(bool)
+		return v, ok
+	case bacnet.TypeReal:
+		f, ok := toFloat(value)
+		if !ok || math.Abs(f) > math.MaxFloat32 {
+			return nil, false
+		}
+		return float32(f), true
+	case bacnet.TypeDouble:
+		f, ok := toFloat(value)
+		return f, ok
+	case bacnet.TypeUnsignedInt:
+		i, ok := toInt(value)
+		if !ok || i < 0 || i > math.MaxUint32 {
+			return nil, false
+		}
+		return uint32(i), true
+	case bacnet.TypeEnumerated:
+		if b, ok := value.(bool); ok {
+			//Binary objects, inactive or active
+			return b, true
+		}
+		i, ok := toInt(value)
+		if !ok || i < 0 || i > math.MaxUint32 {
+			return nil, false
+		}
+		return uint32(i), true
+	case bacnet.TypeSignedInt:
+		i, ok := toInt(value)
+		if !ok || i < math.MinInt32 || i > math.MaxInt32 {
+			return nil, false
+		}
+		return int32(i), true
+	case bacnet.TypeCharacterString:
+		v, ok := value.(string)
+		return v, ok
+	case bacnet.TypeOctetString:
+		v, ok := value.([]byte)
+		return v, ok
+	case bacnet.TypeBitString:
+		v, ok := value.(bacnet.BitString)
+		return v, ok
+	case bacnet.TypeDate:
+		v, ok := value.(bacnet.Date)
+		return v, ok
+	case bacnet.TypeTime:
+		v, ok := value.(bacnet.Time)
+		return v, ok
+	}
+	return nil, false
+}
+
+// toInt converts integer values to int64
+func toInt(value interface{}) (int64, bool) {
+	switch v := value.(type) {
+	case int:
+		return int64(v), true
+	case int8:
+		return int64(v), true
+	case int16:
+		return int64(v), true
+	case int32:
+		return int64(v), true
+	case int64:
+		return v, true
+	case uint8:
+		return int64(v), true
+	case uint16:
+		return int64(v), true
+	case uint32:
+		return int64(v), true
+	}
+	return 0, false
+}
+
+// coerceWrites applies coerceWrite to the writes of a copy of the
+// specifications
+func coerceWrites(specs []WriteAccessSpecification) ([]WriteAccessSpecification, error) {
+	result := make([]WriteAccessSpecification, len(specs))
+	for i, s := range specs {
+		result[i] = WriteAccessSpecification{ObjectID: s.ObjectID, Properties: make([]PropertyWrite, len(s.Properties))}
+		for j, p := range s.Properties {
+			v, err := coerceWrite(s.ObjectID, p.Property, p.Value)
+			if err != nil {
+				return nil, err
+			}
+			p.Value = v
+			result[i].Properties[j] = p
+		}
+	}
+	return result, nil
+}
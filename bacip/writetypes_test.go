@@ -0,0 +1,64 @@
+package bacip
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/REQUEA/bacnet"
+	"github.com/matryer/is"
+)
+
+func TestCoerceWrite(t *testing.T) {
+	is := is.New(t)
+	av := bacnet.ObjectID{Type: bacnet.AnalogValue, Instance: 1}
+	bv := bacnet.ObjectID{Type: bacnet.BinaryValue, Instance: 1}
+	msv := bacnet.ObjectID{Type: bacnet.MultiStateValue, Instance: 1}
+	prop := func(p bacnet.PropertyType) bacnet.PropertyIdentifier {
+		return bacnet.PropertyIdentifier{Type: p}
+	}
+	index := uint32(3)
+	for _, tc := range []struct {
+		object bacnet.ObjectID
+		prop   bacnet.PropertyIdentifier
+		value  bacnet.PropertyValue
+		want   bacnet.PropertyValue
+	}{
+		{av, prop(bacnet.PresentValue), bacnet.PropertyValue{Value: 21}, bacnet.PropertyValue{Type: bacnet.TypeReal, Value: float32(21)}},
+		{av, prop(bacnet.PresentValue), bacnet.PropertyValue{Value: 21.5}, bacnet.PropertyValue{Type: bacnet.TypeReal, Value: float32(21.5)}},
+		{av, prop(bacnet.PresentValue), bacnet.PropertyValue{}, bacnet.PropertyValue{Type: bacnet.TypeNull}},
+		{bv, prop(bacnet.PresentValue), bacnet.PropertyValue{Value: true}, bacnet.PropertyValue{Type: bacnet.TypeEnumerated, Value: true}},
+		{msv, prop(bacnet.PresentValue), bacnet.PropertyValue{Value: int32(2)}, bacnet.PropertyValue{Type: bacnet.TypeUnsignedInt, Value: uint32(2)}},
+		{msv, prop(bacnet.CovIncrement), bacnet.PropertyValue{Value: "unknown"}, bacnet.PropertyValue{Value: "unknown"}},
+		{av, prop(bacnet.OutOfService), bacnet.PropertyValue{Type: bacnet.TypeBoolean, Value: true}, bacnet.PropertyValue{Type: bacnet.TypeBoolean, Value: true}},
+		{av, bacnet.PropertyIdentifier{Type: bacnet.PresentValue, ArrayIndex: &index}, bacnet.PropertyValue{Value: "x"}, bacnet.PropertyValue{Value: "x"}},
+		{av, prop(bacnet.PropertyType(1000)), bacnet.PropertyValue{Value: "x"}, bacnet.PropertyValue{Value: "x"}},
+	} {
+		got, err := coerceWrite(tc.object, tc.prop, tc.value)
+		is.NoErr(err)
+		is.Equal(got, tc.want)
+	}
+
+	for _, tc := range []struct {
+		object bacnet.ObjectID
+		prop   bacnet.PropertyType
+		value  bacnet.PropertyValue
+	}{
+		{av, bacnet.PresentValue, bacnet.PropertyValue{Value: "21"}},
+		{av, bacnet.PresentValue, bacnet.PropertyValue{Type: bacnet.TypeDouble, Value: 21.5}},
+		{bv, bacnet.PresentValue, bacnet.PropertyValue{Type: bacnet.TypeReal, Value: float32(1)}},
+		{msv, bacnet.PresentValue, bacnet.PropertyValue{Value: -1}},
+		{av, bacnet.ObjectName, bacnet.PropertyValue{Value: 3}},
+		{av, bacnet.Description, bacnet.PropertyValue{}},
+	} {
+		_, err := coerceWrite(tc.object, bacnet.PropertyIdentifier{Type: tc.prop}, tc.value)
+		is.True(errors.Is(err, ErrInvalidDatatype))
+	}
+	_, err := coerceWrite(av, prop(bacnet.PresentValue), bacnet.PropertyValue{Value: "21"})
+	is.Equal(err.Error(), "invalid datatype: PresentValue of analog-value:1 is a Real, not string")
+
+	//Values already encoded aren't checked
+	raw := TypedValue{Value: "x", Raw: []byte{0x71, 0x00, 0x78}}
+	got, err := coerceWrite(av, prop(bacnet.PresentValue), bacnet.PropertyValue{Value: raw})
+	is.NoErr(err)
+	is.Equal(got.Value, raw)
+}
@@ -0,0 +1,84 @@
+package bactest
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/REQUEA/bacnet"
+	"github.com/REQUEA/bacnet/bacip"
+)
+
+// isRead tells if the request reads the property
+func (r Request) isRead(id bacnet.ObjectID, prop bacnet.PropertyType) bool {
+	return (r.Service == bacip.ServiceConfirmedReadProperty || r.Service == bacip.ServiceConfirmedReadPropMultiple) &&
+		r.Object == id && r.Property.Type == prop
+}
+
+// isWrite tells if the request writes a property
+func (r Request) isWrite() bool {
+	return r.Service == bacip.ServiceConfirmedWriteProperty || r.Service == bacip.ServiceConfirmedWritePropMultiple
+}
+
+// Writes returns the values written to the property, oldest first
+func (d *Device) Writes(id bacnet.ObjectID, prop bacnet.PropertyType) []interface{} {
+	var values []interface{}
+	for _, r := range d.Requests() {
+		if r.isWrite() && r.Object == id && r.Property.Type == prop {
+			values = append(values, r.Value)
+		}
+	}
+	return values
+}
+
+// AssertRead fails the test if the property wasn't read from the
+// device
+func AssertRead(t testing.TB, d *Device, id bacnet.ObjectID, prop bacnet.PropertyType) {
+	t.Helper()
+	for _, r := range d.Requests() {
+		if r.isRead(id, prop) {
+			return
+		}
+	}
+	t.Errorf("%v %v of device %d wasn't read", id, prop, d.Iam.ObjectID.Instance)
+}
+
+// AssertNotRead fails the test if the property was read from the
+// device
+func AssertNotRead(t testing.TB, d *Device, id bacnet.ObjectID, prop bacnet.PropertyType) {
+	t.Helper()
+	for _, r := range d.Requests() {
+		if r.isRead(id, prop) {
+			t.Errorf("%v %v of device %d was read", id, prop, d.Iam.ObjectID.Instance)
+			return
+		}
+	}
+}
+
+// AssertWritten fails the test if the last value written to the
+// property isn't value. The value is compared to the written one
+// decoded like bacip.ReadProperty.Data: enumerated and unsigned
+// values are uint32, reals are float32
+func AssertWritten(t testing.TB, d *Device, id bacnet.ObjectID, prop bacnet.PropertyType, value interface{}) {
+	t.Helper()
+	writes := d.Writes(id, prop)
+	if len(writes) == 0 {
+		t.Errorf("%v %v of device %d wasn't written", id, prop, d.Iam.ObjectID.Instance)
+		return
+	}
+	last := writes[len(writes)-1]
+	if !reflect.DeepEqual(last, value) {
+		t.Errorf("%v %v of device %d: written %#v, expected %#v", id, prop, d.Iam.ObjectID.Instance, last, value)
+	}
+}
+
+// AssertNoWrite fails the test if any property of the device was
+// written
+func AssertNoWrite(t testing.TB, d *Device) {
+	t.Helper()
+	for _, r := range d.Requests() {
+		if r.isWrite() {
+			t.Errorf("%v %v of device %d was written", r.Object, r.Property.Type, d.Iam.ObjectID.Instance)
+			return
+		}
+	}
+}
@@ -0,0 +1,361 @@
+package bactest
+
+import (
+	stdencoding "encoding"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/REQUEA/bacnet"
+	"github.com/REQUEA/bacnet/bacip"
+	"github.com/REQUEA/bacnet/internal/encoding"
+)
+
+// Device is a fake BACnet/IP device. Its objects and properties are
+// set by the test, and it answers WhoIs, WhoHas, ReadProperty,
+// ReadPropertyMultiple, WriteProperty, WritePropertyMultiple and
+// SubscribeCOV requests. The wildcard device object designates its
+// device object.
+//
+// Property values are the types accepted by the encoder: float32,
+// uint32, int32, string, bool, bacnet.ObjectID, ... Enumerated values
+// are set as a bacnet.PropertyValue with the TypeEnumerated type,
+// arrays and lists as a []interface{}, and constructed values as an
+// encoding.BinaryMarshaler such as bacip.RawValue.
+type Device struct {
+	Iam  bacip.Iam
+	Addr net.UDPAddr
+
+	network *Network
+
+	sync.Mutex
+	objects       map[bacnet.ObjectID]map[bacnet.PropertyType]interface{}
+	requests      []Request
+	subscriptions []Subscription
+	faults        map[bacip.ServiceType]*Fault
+}
+
+// Request is a request received by a device. ReadPropertyMultiple
+// and WritePropertyMultiple requests are recorded as one Request per
+// property
+type Request struct {
+	Service  bacip.ServiceType
+	InvokeID byte
+	Object   bacnet.ObjectID
+	Property bacnet.PropertyIdentifier
+	//Value is the written value, decoded like bacip.ReadProperty.Data
+	Value    interface{}
+	Priority bacnet.PriorityList
+}
+
+// Subscription is a COV subscription received by a device
+type Subscription struct {
+	Subscriber net.UDPAddr
+	ProcessID  uint32
+	Object     bacnet.ObjectID
+	//Confirmed is the requested kind of notifications. Notifications
+	//are always sent unconfirmed by the fake devices
+	Confirmed bool
+	//Lifetime is 0 for an indefinite subscription
+	Lifetime time.Duration
+}
+
+func newDevice(instance bacnet.ObjectInstance, addr net.UDPAddr) *Device {
+	d := &Device{
+		Iam: bacip.Iam{
+			ObjectID:            bacnet.ObjectID{Type: bacnet.BacnetDevice, Instance: instance},
+			MaxApduLength:       1476,
+			SegmentationSupport: bacnet.SegmentationSupportNone,
+			VendorID:            260,
+		},
+		Addr:    addr,
+		objects: map[bacnet.ObjectID]map[bacnet.PropertyType]interface{}{},
+	}
+	d.objects[d.Iam.ObjectID] = map[bacnet.PropertyType]interface{}{}
+	return d
+}
+
+// Device returns the device as seen by a client
+func (d *Device) Device() bacnet.Device {
+	return bacnet.Device{
+		ID:           d.Iam.ObjectID,
+		MaxApdu:      d.Iam.MaxApduLength,
+		Segmentation: d.Iam.SegmentationSupport,
+		Vendor:       d.Iam.VendorID,
+		Addr:         *bacnet.AddressFromUDP(d.Addr),
+	}
+}
+
+// AddObject creates an object without properties. The object
+// identifier, type and the object list of the device don't have to be
+// set, they are derived from the objects of the device
+func (d *Device) AddObject(id bacnet.ObjectID) {
+	d.Lock()
+	defer d.Unlock()
+	if _, ok := d.objects[id]; !ok {
+		d.objects[id] = map[bacnet.PropertyType]interface{}{}
+	}
+}
+
+// Set sets the value of a property, creating the object if needed
+func (d *Device) Set(id bacnet.ObjectID, prop bacnet.PropertyType, value interface{}) {
+	d.Lock()
+	defer d.Unlock()
+	props, ok := d.objects[id]
+	if !ok {
+		props = map[bacnet.PropertyType]interface{}{}
+		d.objects[id] = props
+	}
+	props[prop] = value
+}
+
+// Get returns the value of a property. Written values are returned
+// decoded like bacip.ReadProperty.Data
+func (d *Device) Get(id bacnet.ObjectID, prop bacnet.PropertyType) (interface{}, bool) {
+	d.Lock()
+	defer d.Unlock()
+	v, err := d.property(id, prop)
+	if err != nil {
+		return nil, false
+	}
+	if raw, ok := v.(bacip.RawValue); ok {
+		return decodeValue(raw), true
+	}
+	return v, true
+}
+
+// Requests returns the requests received by the device, oldest first
+func (d *Device) Requests() []Request {
+	d.Lock()
+	defer d.Unlock()
+	return append([]Request(nil), d.requests...)
+}
+
+// ResetRequests forgets the requests received so far
+func (d *Device) ResetRequests() {
+	d.Lock()
+	defer d.Unlock()
+	d.requests = nil
+}
+
+// Subscriptions returns the active COV subscriptions
+func (d *Device) Subscriptions() []Subscription {
+	d.Lock()
+	defer d.Unlock()
+	return append([]Subscription(nil), d.subscriptions...)
+}
+
+// NotifyCOV sends the present value and status flags of the object to
+// its COV subscribers
+func (d *Device) NotifyCOV(id bacnet.ObjectID) error {
+	d.Lock()
+	var subs []Subscription
+	for _, s := range d.subscriptions {
+		if s.Object == id {
+			subs = append(subs, s)
+		}
+	}
+	values := map[bacnet.PropertyType]interface{}{}
+	for _, prop := range []bacnet.PropertyType{bacnet.PresentValue, bacnet.StatusFlags} {
+		if v, err := d.property(id, prop); err == nil {
+			values[prop] = v
+		}
+	}
+	d.Unlock()
+	for _, s := range subs {
+		e := encoding.NewEncoder()
+		e.ContextUnsigned(0, s.ProcessID)
+		e.ContextObjectID(1, d.Iam.ObjectID)
+		e.ContextObjectID(2, id)
+		e.ContextUnsigned(3, uint32(s.Lifetime/time.Second))
+		e.OpeningTag(4)
+		for _, prop := range []bacnet.PropertyType{bacnet.PresentValue, bacnet.StatusFlags} {
+			v, ok := values[prop]
+			if !ok {
+				continue
+			}
+			raw, err := encodeValue(v)
+			if err != nil {
+				return err
+			}
+			e.ContextUnsigned(0, uint32(prop))
+			e.ContextRaw(2, raw)
+		}
+		e.ClosingTag(4)
+		if e.Error() != nil {
+			return e.Error()
+		}
+		err := d.network.send(d, &bacip.APDU{
+			DataType:    bacip.UnconfirmedServiceRequest,
+			ServiceType: bacip.ServiceUnconfirmedCOVNotification,
+			Payload:     &bacip.DataPayload{Bytes: e.Bytes()},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NotifyRestart sends the restart notification of the device to the
+// client, as devices do when they restart. The objects and the
+// subscriptions of the device are left unchanged
+func (d *Device) NotifyRestart() error {
+	e := encoding.NewEncoder()
+	e.ContextUnsigned(0, 0)
+	e.ContextObjectID(1, d.Iam.ObjectID)
+	e.ContextObjectID(2, d.Iam.ObjectID)
+	e.ContextUnsigned(3, 0)
+	e.OpeningTag(4)
+	e.ContextUnsigned(0, uint32(bacnet.SystemStatus))
+	e.OpeningTag(2)
+	//Operational
+	e.AppValue(bacnet.PropertyValue{Type: bacnet.TypeEnumerated, Value: uint32(0)})
+	e.ClosingTag(2)
+	//The time stamp of the restart is its sequence number
+	e.ContextUnsigned(0, uint32(bacnet.TimeOfDeviceRestart))
+	e.OpeningTag(2)
+	e.ContextUnsigned(1, 1)
+	e.ClosingTag(2)
+	e.ClosingTag(4)
+	if e.Error() != nil {
+		return e.Error()
+	}
+	return d.network.send(d, &bacip.APDU{
+		DataType:    bacip.UnconfirmedServiceRequest,
+		ServiceType: bacip.ServiceUnconfirmedCOVNotification,
+		Payload:     &bacip.DataPayload{Bytes: e.Bytes()},
+	})
+}
+
+// object returns the object designated by the identifier of a
+// request: the device object for the wildcard device
+func (d *Device) object(id bacnet.ObjectID) bacnet.ObjectID {
+	if id == bacnet.WildcardDevice {
+		return d.Iam.ObjectID
+	}
+	return id
+}
+
+// property returns the value of a property. The lock must be held
+func (d *Device) property(id bacnet.ObjectID, prop bacnet.PropertyType) (interface{}, *bacip.ApduError) {
+	id = d.object(id)
+	props, ok := d.objects[id]
+	if !ok {
+		return nil, &bacip.ApduError{Class: bacnet.ObjectError, Code: bacnet.UnknownObject}
+	}
+	if v, ok := props[prop]; ok {
+		return v, nil
+	}
+	switch {
+	case prop == bacnet.ObjectIdentifier:
+		return id, nil
+	case prop == bacnet.ObjectTypeProp:
+		return bacnet.PropertyValue{Type: bacnet.TypeEnumerated, Value: uint32(id.Type)}, nil
+	case prop == bacnet.ObjectList && id == d.Iam.ObjectID:
+		ids := make([]bacnet.ObjectID, 0, len(d.objects))
+		for o := range d.objects {
+			ids = append(ids, o)
+		}
+		sort.Slice(ids, func(i, j int) bool {
+			a, b := ids[i], ids[j]
+			return a.Type < b.Type || (a.Type == b.Type && a.Instance < b.Instance)
+		})
+		list := make([]interface{}, len(ids))
+		for i, o := range ids {
+			list[i] = o
+		}
+		return list, nil
+	}
+	return nil, &bacip.ApduError{Class: bacnet.PropertyError, Code: bacnet.UnknownProperty}
+}
+
+// read returns the encoded value of a property, or of an element of
+// an array. The lock must be held
+func (d *Device) read(id bacnet.ObjectID, prop bacnet.PropertyIdentifier) ([]byte, *bacip.ApduError) {
+	v, apduErr := d.property(id, prop.Type)
+	if apduErr != nil {
+		return nil, apduErr
+	}
+	if prop.ArrayIndex != nil {
+		array, ok := v.([]interface{})
+		switch {
+		case !ok:
+			return nil, &bacip.ApduError{Class: bacnet.PropertyError, Code: bacnet.PropertyIsNotAnArray}
+		case *prop.ArrayIndex == 0:
+			v = uint32(len(array))
+		case int(*prop.ArrayIndex) <= len(array):
+			v = array[*prop.ArrayIndex-1]
+		default:
+			return nil, &bacip.ApduError{Class: bacnet.PropertyError, Code: bacnet.InvalidArrayIndex}
+		}
+	}
+	raw, err := encodeValue(v)
+	if err != nil {
+		return nil, &bacip.ApduError{Class: bacnet.PropertyError, Code: bacnet.InvalidDataType}
+	}
+	return raw, nil
+}
+
+// properties returns the properties of an object, for the reads of
+// all of them. The lock must be held
+func (d *Device) properties(id bacnet.ObjectID) []bacnet.PropertyType {
+	id = d.object(id)
+	props := []bacnet.PropertyType{bacnet.ObjectIdentifier, bacnet.ObjectTypeProp}
+	if id == d.Iam.ObjectID {
+		props = append(props, bacnet.ObjectList)
+	}
+	for p := range d.objects[id] {
+		if p != bacnet.ObjectIdentifier && p != bacnet.ObjectTypeProp && p != bacnet.ObjectList {
+			props = append(props, p)
+		}
+	}
+	sort.Slice(props[2:], func(i, j int) bool { return props[2+i] < props[2+j] })
+	return props
+}
+
+// encodeValue encodes a property value with application tags
+func encodeValue(v interface{}) ([]byte, error) {
+	switch v := v.(type) {
+	case stdencoding.BinaryMarshaler:
+		return v.MarshalBinary()
+	case []interface{}:
+		var b []byte
+		for _, elem := range v {
+			raw, err := encodeValue(elem)
+			if err != nil {
+				return nil, err
+			}
+			b = append(b, raw...)
+		}
+		return b, nil
+	case bacnet.PropertyValue:
+		e := encoding.NewEncoder()
+		e.AppValue(v)
+		return e.Bytes(), e.Error()
+	default:
+		e := encoding.NewEncoder()
+		e.AppData(v)
+		return e.Bytes(), e.Error()
+	}
+}
+
+// decodeValue decodes a value made of application tagged data like
+// bacip.ReadProperty.Data
+func decodeValue(raw []byte) interface{} {
+	d := encoding.NewDecoder(raw)
+	values := []interface{}{}
+	for d.Len() > 0 {
+		var v interface{}
+		d.AppData(&v)
+		if d.Error() != nil {
+			return bacip.RawValue(raw)
+		}
+		values = append(values, v)
+	}
+	if len(values) == 1 {
+		return values[0]
+	}
+	return values
+}
@@ -0,0 +1,100 @@
+package bactest
+
+import (
+	"time"
+
+	"github.com/REQUEA/bacnet/bacip"
+)
+
+// Fault alters the answers of a device to a confirmed service, to test
+// how the client copes with misbehaving devices
+type Fault struct {
+	//Error, Reject and Abort replace the answer. The request isn't
+	//processed by the device
+	Error  *bacip.ApduError
+	Reject *bacip.RejectReason
+	Abort  *bacip.AbortReason
+	//Drop processes the request but doesn't answer it, as if the
+	//answer was lost
+	Drop bool
+	//Delay delays the answer
+	Delay time.Duration
+	//Corrupt truncates the answer, so that it can't be decoded
+	Corrupt bool
+	//Count is the number of requests the fault applies to, 0 for all
+	//of them
+	Count int
+	//After is the number of requests answered normally before the
+	//fault applies, to make a step of a procedure fail
+	After int
+}
+
+// InjectFault makes the device answer the requests of the service
+// according to the fault, replacing the previous fault of the service
+func (d *Device) InjectFault(service bacip.ServiceType, f Fault) {
+	d.Lock()
+	defer d.Unlock()
+	if d.faults == nil {
+		d.faults = map[bacip.ServiceType]*Fault{}
+	}
+	d.faults[service] = &f
+}
+
+// ClearFaults removes the faults of all the services
+func (d *Device) ClearFaults() {
+	d.Lock()
+	defer d.Unlock()
+	d.faults = nil
+}
+
+// fault returns the fault to apply to a request of the service, if
+// any
+func (d *Device) fault(service bacip.ServiceType) *Fault {
+	d.Lock()
+	defer d.Unlock()
+	f, ok := d.faults[service]
+	if !ok {
+		return nil
+	}
+	if f.After > 0 {
+		f.After--
+		return nil
+	}
+	if f.Count > 0 {
+		f.Count--
+		if f.Count == 0 {
+			delete(d.faults, service)
+		}
+	}
+	fault := *f
+	return &fault
+}
+
+// answer returns the answer replacing the one of the device, or
+// nil if the request must be processed
+func (f *Fault) answer(apdu *bacip.APDU) *bacip.APDU {
+	switch {
+	case f.Error != nil:
+		return &bacip.APDU{
+			DataType:    bacip.Error,
+			InvokeID:    apdu.InvokeID,
+			ServiceType: apdu.ServiceType,
+			Payload:     f.Error,
+		}
+	case f.Reject != nil:
+		return &bacip.APDU{
+			DataType:    bacip.Reject,
+			InvokeID:    apdu.InvokeID,
+			ServiceType: bacip.ServiceType(*f.Reject),
+			Payload:     &bacip.DataPayload{},
+		}
+	case f.Abort != nil:
+		return &bacip.APDU{
+			DataType:    bacip.Abort,
+			InvokeID:    apdu.InvokeID,
+			ServiceType: bacip.ServiceType(*f.Abort),
+			Payload:     &bacip.DataPayload{},
+		}
+	}
+	return nil
+}
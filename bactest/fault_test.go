@@ -0,0 +1,70 @@
+package bactest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/REQUEA/bacnet"
+	"github.com/REQUEA/bacnet/bacip"
+	"github.com/matryer/is"
+)
+
+func TestFaults(t *testing.T) {
+	is := is.New(t)
+	n := NewNetwork()
+	d := n.AddDevice(10)
+	d.Set(ai1, bacnet.PresentValue, float32(21.5))
+	c := n.Client(t)
+	c.SetDeviceProfile(d.Iam.ObjectID, bacip.DeviceProfile{Timeout: 50 * time.Millisecond, Retries: 1})
+	read := func() (interface{}, error) {
+		return c.ReadProperty(context.Background(), d.Device(), bacip.ReadProperty{
+			ObjectID: ai1,
+			Property: bacnet.PropertyIdentifier{Type: bacnet.PresentValue},
+		})
+	}
+
+	d.InjectFault(bacip.ServiceConfirmedReadProperty, Fault{
+		Error: &bacip.ApduError{Class: bacnet.DeviceError, Code: bacnet.ErrorCode(0)},
+		Count: 1,
+	})
+	_, err := read()
+	var apduErr bacip.ApduError
+	is.True(errors.As(err, &apduErr))
+	is.Equal(apduErr.Class, bacnet.DeviceError)
+	//The fault applied once
+	v, err := read()
+	is.NoErr(err)
+	is.Equal(v, float32(21.5))
+
+	reason := bacip.RejectReasonUnrecognizedService
+	d.InjectFault(bacip.ServiceConfirmedReadProperty, Fault{Reject: &reason})
+	_, err = read()
+	is.Equal(err, bacip.RejectError{Reason: reason})
+
+	abort := bacip.AbortReasonOutOfResources
+	d.InjectFault(bacip.ServiceConfirmedReadProperty, Fault{Abort: &abort})
+	_, err = read()
+	is.Equal(err, bacip.AbortError{Reason: abort})
+
+	//The first answer is lost, the retry succeeds
+	d.InjectFault(bacip.ServiceConfirmedReadProperty, Fault{Drop: true, Count: 1})
+	d.ResetRequests()
+	_, err = read()
+	is.NoErr(err)
+	is.Equal(len(d.Requests()), 2)
+
+	d.InjectFault(bacip.ServiceConfirmedReadProperty, Fault{Delay: time.Second})
+	_, err = read()
+	is.True(errors.Is(err, context.DeadlineExceeded))
+
+	d.InjectFault(bacip.ServiceConfirmedReadProperty, Fault{Corrupt: true})
+	_, err = read()
+	is.True(errors.Is(err, context.DeadlineExceeded))
+	is.True(len(c.DebugInfo().DecodeErrors) > 0)
+
+	d.ClearFaults()
+	_, err = read()
+	is.NoErr(err)
+}
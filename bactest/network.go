@@ -0,0 +1,193 @@
+// Package bactest provides fake BACnet/IP devices, to unit test code
+// using a bacip.Client without a network or a simulator:
+//
+//	n := bactest.NewNetwork()
+//	d := n.AddDevice(1234)
+//	d.Set(bacnet.ObjectID{Type: bacnet.AnalogInput, Instance: 1}, bacnet.PresentValue, float32(21.5))
+//	c := n.Client(t)
+//	v, err := c.ReadProperty(ctx, d.Device(), ...)
+//	bactest.AssertRead(t, d, bacnet.ObjectID{Type: bacnet.AnalogInput, Instance: 1}, bacnet.PresentValue)
+package bactest
+
+import (
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/REQUEA/bacnet"
+	"github.com/REQUEA/bacnet/bacip"
+)
+
+// ClientInterface is the network interface of the clients created by
+// Network.Client. The devices are given addresses in the same network
+const ClientInterface = "10.0.0.1/24"
+
+type datagram struct {
+	data []byte
+	addr *net.UDPAddr
+}
+
+// Network connects a client to fake devices. It implements
+// bacip.Transport for a single client: the datagrams written by the
+// client are handled by the devices, and their answers are read by
+// the client
+type Network struct {
+	sync.Mutex
+	devices   []*Device
+	in        chan datagram
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+// NewNetwork returns a network without devices
+func NewNetwork() *Network {
+	return &Network{
+		in:     make(chan datagram, 64),
+		closed: make(chan struct{}),
+	}
+}
+
+// AddDevice adds a device with the given instance number. It's given
+// the next free address of the network
+func (n *Network) AddDevice(instance bacnet.ObjectInstance) *Device {
+	n.Lock()
+	defer n.Unlock()
+	addr := net.UDPAddr{IP: net.IPv4(10, 0, 0, byte(len(n.devices)+2)).To4(), Port: bacip.DefaultUDPPort}
+	d := newDevice(instance, addr)
+	d.network = n
+	n.devices = append(n.devices, d)
+	return d
+}
+
+// Devices returns the devices of the network
+func (n *Network) Devices() []*Device {
+	n.Lock()
+	defer n.Unlock()
+	return append([]*Device(nil), n.devices...)
+}
+
+// Listen returns the network as the transport of a client, to be
+// given to bacip.NewClientWithTransport
+func (n *Network) Listen(port int) (bacip.Transport, error) {
+	return n, nil
+}
+
+// Client returns a client connected to the network, closed at the end
+// of the test
+func (n *Network) Client(t testing.TB) *bacip.Client {
+	t.Helper()
+	c, err := bacip.NewClientWithTransport(ClientInterface, n.Listen, bacip.DefaultUDPPort, bacip.NoOpLogger{})
+	if err != nil {
+		t.Fatalf("create client: %v", err)
+	}
+	t.Cleanup(func() { _ = c.Close() })
+	return c
+}
+
+func (n *Network) ReadFromUDP(b []byte) (int, *net.UDPAddr, error) {
+	select {
+	case d := <-n.in:
+		return copy(b, d.data), d.addr, nil
+	case <-n.closed:
+		return 0, nil, net.ErrClosed
+	}
+}
+
+// WriteToUDP delivers the datagram to the device at addr, or to all
+// the devices if it's a broadcast
+func (n *Network) WriteToUDP(b []byte, addr *net.UDPAddr) (int, error) {
+	var bvlc bacip.BVLC
+	err := bvlc.UnmarshalBinary(b)
+	if err != nil {
+		return 0, fmt.Errorf("invalid datagram: %w", err)
+	}
+	apdu := bvlc.NPDU.ADPU
+	if apdu == nil {
+		return len(b), nil
+	}
+	broadcast := bvlc.Function == bacip.BacFuncBroadcast || bvlc.Function == bacip.BacFuncDistributeBroadcastToNetwork
+	src := *n.LocalAddr().(*net.UDPAddr)
+	for _, d := range n.Devices() {
+		if !broadcast && !(d.Addr.IP.Equal(addr.IP) && d.Addr.Port == addr.Port) {
+			continue
+		}
+		var fault *Fault
+		if apdu.DataType == bacip.ConfirmedServiceRequest {
+			fault = d.fault(apdu.ServiceType)
+		}
+		var answer *bacip.APDU
+		if fault != nil {
+			answer = fault.answer(apdu)
+		}
+		if answer != nil {
+			d.record(Request{Service: apdu.ServiceType, InvokeID: apdu.InvokeID})
+		} else {
+			answer = d.serve(apdu, src)
+		}
+		if answer == nil || (fault != nil && fault.Drop) {
+			continue
+		}
+		data, err := encode(answer)
+		if err != nil {
+			return 0, err
+		}
+		if fault == nil {
+			err = n.push(d, data)
+			if err != nil {
+				return 0, err
+			}
+			continue
+		}
+		if fault.Corrupt {
+			data = data[:len(data)-1]
+		}
+		time.AfterFunc(fault.Delay, func() { _ = n.push(d, data) })
+	}
+	return len(b), nil
+}
+
+// send sends an APDU from the device to the client
+func (n *Network) send(d *Device, apdu *bacip.APDU) error {
+	b, err := encode(apdu)
+	if err != nil {
+		return err
+	}
+	return n.push(d, b)
+}
+
+// encode returns the datagram carrying the APDU
+func encode(apdu *bacip.APDU) ([]byte, error) {
+	return bacip.BVLC{
+		Type:     bacip.TypeBacnetIP,
+		Function: bacip.BacFuncUnicast,
+		NPDU: bacip.NPDU{
+			Version:  bacip.Version1,
+			Priority: bacip.Normal,
+			ADPU:     apdu,
+		},
+	}.MarshalBinary()
+}
+
+// push makes the client receive the datagram from the device
+func (n *Network) push(d *Device, b []byte) error {
+	addr := d.Addr
+	select {
+	case n.in <- datagram{data: b, addr: &addr}:
+		return nil
+	case <-n.closed:
+		return net.ErrClosed
+	}
+}
+
+// LocalAddr returns the address of the client
+func (n *Network) LocalAddr() net.Addr {
+	ip, _, _ := net.ParseCIDR(ClientInterface)
+	return &net.UDPAddr{IP: ip.To4(), Port: bacip.DefaultUDPPort}
+}
+
+func (n *Network) Close() error {
+	n.closeOnce.Do(func() { close(n.closed) })
+	return nil
+}
@@ -0,0 +1,459 @@
+package bactest
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/REQUEA/bacnet"
+	"github.com/REQUEA/bacnet/bacip"
+	"github.com/REQUEA/bacnet/internal/encoding"
+	"github.com/matryer/is"
+)
+
+var ai1 = bacnet.ObjectID{Type: bacnet.AnalogInput, Instance: 1}
+
+func TestWhoIs(t *testing.T) {
+	is := is.New(t)
+	n := NewNetwork()
+	n.AddDevice(10)
+	n.AddDevice(20)
+	c := n.Client(t)
+	devices, err := c.WhoIs(bacip.WhoIs{}, 100*time.Millisecond)
+	is.NoErr(err)
+	is.Equal(len(devices), 2)
+
+	low, high := uint32(15), uint32(25)
+	devices, err = c.WhoIs(bacip.WhoIs{Low: &low, High: &high}, 100*time.Millisecond)
+	is.NoErr(err)
+	is.Equal(len(devices), 1)
+	is.Equal(devices[0].ID.Instance, bacnet.ObjectInstance(20))
+}
+
+func TestDiscover(t *testing.T) {
+	is := is.New(t)
+	n := NewNetwork()
+	n.AddDevice(10)
+	n.AddDevice(20)
+	n.AddDevice(30)
+	c := n.Client(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	low, high := uint32(15), uint32(35)
+	devices, err := c.Discover(ctx, bacip.WhoIs{Low: &low, High: &high})
+	is.NoErr(err)
+	found := map[bacnet.ObjectInstance]bool{}
+	for len(found) < 2 {
+		select {
+		case d := <-devices:
+			found[d.ID.Instance] = true
+		case <-time.After(time.Second):
+			t.Fatal("devices not discovered")
+		}
+	}
+	is.Equal(found, map[bacnet.ObjectInstance]bool{20: true, 30: true})
+	cancel()
+	for range devices {
+		//Drained until closed
+	}
+}
+
+func TestDiscoverDetailed(t *testing.T) {
+	is := is.New(t)
+	n := NewNetwork()
+	d := n.AddDevice(10)
+	d.Set(d.Iam.ObjectID, bacnet.ObjectName, "AHU-1")
+	d.Set(d.Iam.ObjectID, bacnet.ModelName, "X1")
+	d.Set(d.Iam.ObjectID, bacnet.FirmwareRevision, "2.1")
+	c := n.Client(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	devices, err := c.DiscoverDetailed(ctx, bacip.WhoIs{})
+	is.NoErr(err)
+	select {
+	case details := <-devices:
+		is.NoErr(details.Err)
+		is.Equal(details.ID, d.Iam.ObjectID)
+		is.Equal(details.Name, "AHU-1")
+		is.Equal(details.ModelName, "X1")
+		is.Equal(details.FirmwareRevision, "2.1")
+		is.Equal(details.VendorName, "") //unknown property
+	case <-time.After(time.Second):
+		t.Fatal("device not discovered")
+	}
+	cancel()
+	for range devices {
+		//Drained until closed
+	}
+}
+
+func TestWhoHas(t *testing.T) {
+	is := is.New(t)
+	n := NewNetwork()
+	n.AddDevice(10).Set(ai1, bacnet.ObjectName, "ChilledWaterSetpoint")
+	n.AddDevice(20).AddObject(ai1)
+	c := n.Client(t)
+	found, err := c.WhoHas(bacip.WhoHas{ObjectName: "ChilledWaterSetpoint"}, 100*time.Millisecond)
+	is.NoErr(err)
+	is.Equal(found, []bacip.IHave{{
+		Device:     bacnet.ObjectID{Type: bacnet.BacnetDevice, Instance: 10},
+		ObjectID:   ai1,
+		ObjectName: "ChilledWaterSetpoint",
+	}})
+
+	found, err = c.WhoHas(bacip.WhoHas{ObjectID: &ai1}, 100*time.Millisecond)
+	is.NoErr(err)
+	is.Equal(len(found), 2)
+	is.Equal(found[1].Device.Instance, bacnet.ObjectInstance(20))
+
+	low, high := uint32(15), uint32(25)
+	found, err = c.WhoHas(bacip.WhoHas{Low: &low, High: &high, ObjectID: &ai1}, 100*time.Millisecond)
+	is.NoErr(err)
+	is.Equal(len(found), 1)
+	is.Equal(found[0].Device.Instance, bacnet.ObjectInstance(20))
+}
+
+func TestReadWriteProperty(t *testing.T) {
+	is := is.New(t)
+	n := NewNetwork()
+	d := n.AddDevice(10)
+	d.Set(ai1, bacnet.PresentValue, float32(21.5))
+	c := n.Client(t)
+	ctx := context.Background()
+
+	v, err := c.ReadProperty(ctx, d.Device(), bacip.ReadProperty{
+		ObjectID: ai1,
+		Property: bacnet.PropertyIdentifier{Type: bacnet.PresentValue},
+	})
+	is.NoErr(err)
+	is.Equal(v, float32(21.5))
+	AssertRead(t, d, ai1, bacnet.PresentValue)
+	AssertNotRead(t, d, ai1, bacnet.Description)
+
+	index := uint32(0)
+	v, err = c.ReadProperty(ctx, d.Device(), bacip.ReadProperty{
+		ObjectID: d.Iam.ObjectID,
+		Property: bacnet.PropertyIdentifier{Type: bacnet.ObjectList, ArrayIndex: &index},
+	})
+	is.NoErr(err)
+	is.Equal(v, uint32(2))
+
+	_, err = c.ReadProperty(ctx, d.Device(), bacip.ReadProperty{
+		ObjectID: ai1,
+		Property: bacnet.PropertyIdentifier{Type: bacnet.Description},
+	})
+	var apduErr bacip.ApduError
+	is.True(errors.As(err, &apduErr))
+	is.Equal(apduErr.Code, bacnet.UnknownProperty)
+
+	err = c.WriteProperty(ctx, d.Device(), bacip.WriteProperty{
+		ObjectID:      ai1,
+		Property:      bacnet.PropertyIdentifier{Type: bacnet.PresentValue},
+		PropertyValue: bacnet.PropertyValue{Type: bacnet.TypeReal, Value: float32(18)},
+		Priority:      bacnet.ManualOperator8,
+	})
+	is.NoErr(err)
+	AssertWritten(t, d, ai1, bacnet.PresentValue, float32(18))
+	is.Equal(d.Requests()[len(d.Requests())-1].Priority, bacnet.ManualOperator8)
+	v, ok := d.Get(ai1, bacnet.PresentValue)
+	is.True(ok)
+	is.Equal(v, float32(18))
+
+	d.ResetRequests()
+	AssertNoWrite(t, d)
+}
+
+func TestCOVNotification(t *testing.T) {
+	is := is.New(t)
+	n := NewNetwork()
+	d := n.AddDevice(10)
+	d.Set(ai1, bacnet.PresentValue, float32(21.5))
+
+	e := encoding.NewEncoder()
+	e.ContextUnsigned(0, 7)
+	e.ContextObjectID(1, ai1)
+	e.ContextBool(2, false)
+	e.ContextUnsigned(3, 60)
+	b, err := bacip.BVLC{
+		Type:     bacip.TypeBacnetIP,
+		Function: bacip.BacFuncUnicast,
+		NPDU: bacip.NPDU{
+			Version:        bacip.Version1,
+			ExpectingReply: true,
+			ADPU: &bacip.APDU{
+				DataType:    bacip.ConfirmedServiceRequest,
+				ServiceType: bacip.ServiceConfirmedSubscribeCOV,
+				InvokeID:    3,
+				Payload:     &bacip.DataPayload{Bytes: e.Bytes()},
+			},
+		},
+	}.MarshalBinary()
+	is.NoErr(err)
+	_, err = n.WriteToUDP(b, &d.Addr)
+	is.NoErr(err)
+	ack := read(t, n)
+	is.Equal(ack.DataType, bacip.SimpleAck)
+	is.Equal(ack.InvokeID, byte(3))
+	is.Equal(len(d.Subscriptions()), 1)
+	is.Equal(d.Subscriptions()[0].Lifetime, time.Minute)
+
+	is.NoErr(d.NotifyCOV(ai1))
+	notification := read(t, n)
+	is.Equal(notification.DataType, bacip.UnconfirmedServiceRequest)
+	is.Equal(notification.ServiceType, bacip.ServiceUnconfirmedCOVNotification)
+	cov := notification.Payload.(*bacip.COVNotification)
+	is.Equal(cov.ProcessID, uint32(7))
+	is.Equal(cov.Device, d.Iam.ObjectID)
+	is.Equal(cov.ObjectID, ai1)
+	v, ok := cov.Value(bacnet.PresentValue)
+	is.True(ok)
+	is.Equal(v, float32(21.5))
+}
+
+// read returns the next APDU sent by the devices
+func read(t *testing.T, n *Network) *bacip.APDU {
+	is := is.New(t)
+	b := make([]byte, 1500)
+	l, _, err := n.ReadFromUDP(b)
+	is.NoErr(err)
+	var bvlc bacip.BVLC
+	is.NoErr(bvlc.UnmarshalBinary(b[:l]))
+	is.True(bvlc.NPDU.ADPU != nil)
+	return bvlc.NPDU.ADPU
+}
+
+var _ bacip.Transport = (*Network)(nil)
+
+func TestReadPropertyMultiple(t *testing.T) {
+	is := is.New(t)
+	n := NewNetwork()
+	d := n.AddDevice(10)
+	d.Set(ai1, bacnet.PresentValue, float32(21.5))
+	d.Set(ai1, bacnet.Units, bacnet.PropertyValue{Type: bacnet.TypeEnumerated, Value: uint32(62)})
+	c := n.Client(t)
+	specs := []bacip.ReadAccessSpecification{{
+		ObjectID: ai1,
+		Properties: []bacnet.PropertyIdentifier{
+			{Type: bacnet.PresentValue},
+			{Type: bacnet.Units},
+			{Type: bacnet.Description},
+		},
+	}}
+	for _, profile := range []bacip.DeviceProfile{
+		{},
+		{MaxReadPropertyMultiple: 2},
+		{DisableReadPropertyMultiple: true},
+	} {
+		c.SetDeviceProfile(d.Iam.ObjectID, profile)
+		d.ResetRequests()
+		results, err := c.ReadPropertyMultiple(context.Background(), d.Device(), specs)
+		is.NoErr(err)
+		is.Equal(len(results), 1)
+		is.Equal(results[0].ObjectID, ai1)
+		r := results[0].Results
+		is.Equal(len(r), 3)
+		is.Equal(r[0].Value, float32(21.5))
+		is.Equal(r[1].Value, uint32(62))
+		is.Equal(r[2].Error, &bacip.ApduError{Class: bacnet.PropertyError, Code: bacnet.UnknownProperty})
+		AssertRead(t, d, ai1, bacnet.Units)
+	}
+	is.Equal(d.Requests()[0].Service, bacip.ServiceConfirmedReadProperty)
+
+	c.SetDeviceProfile(d.Iam.ObjectID, bacip.DeviceProfile{})
+	results, err := c.ReadPropertyMultiple(context.Background(), d.Device(), []bacip.ReadAccessSpecification{{
+		ObjectID:   ai1,
+		Properties: []bacnet.PropertyIdentifier{{Type: bacnet.All}},
+	}})
+	is.NoErr(err)
+	is.Equal(len(results[0].Results), 4)
+}
+
+func TestWritePropertyMultiple(t *testing.T) {
+	is := is.New(t)
+	n := NewNetwork()
+	d := n.AddDevice(10)
+	av1 := bacnet.ObjectID{Type: bacnet.AnalogValue, Instance: 1}
+	d.AddObject(av1)
+	c := n.Client(t)
+	write := func(id bacnet.ObjectID, v float32) bacip.WriteAccessSpecification {
+		return bacip.WriteAccessSpecification{
+			ObjectID: id,
+			Properties: []bacip.PropertyWrite{{
+				Property: bacnet.PropertyIdentifier{Type: bacnet.PresentValue},
+				Value:    bacnet.PropertyValue{Type: bacnet.TypeReal, Value: v},
+			}},
+		}
+	}
+	err := c.WritePropertyMultiple(context.Background(), d.Device(), []bacip.WriteAccessSpecification{write(av1, 20)})
+	is.NoErr(err)
+	AssertWritten(t, d, av1, bacnet.PresentValue, float32(20))
+
+	unknown := bacnet.ObjectID{Type: bacnet.AnalogValue, Instance: 2}
+	err = c.WritePropertyMultiple(context.Background(), d.Device(), []bacip.WriteAccessSpecification{
+		write(av1, 21), write(unknown, 22),
+	})
+	var wpmErr bacip.WritePropertyMultipleError
+	is.True(errors.As(err, &wpmErr))
+	is.Equal(wpmErr.ObjectID, unknown)
+	is.Equal(wpmErr.Err.Code, bacnet.UnknownObject)
+	v, _ := d.Get(av1, bacnet.PresentValue)
+	is.Equal(v, float32(21))
+}
+
+func TestDeviceTime(t *testing.T) {
+	is := is.New(t)
+	n := NewNetwork()
+	d := n.AddDevice(10)
+	//Central European Summer Time
+	d.Set(d.Iam.ObjectID, bacnet.UtcOffset, int32(-60))
+	d.Set(d.Iam.ObjectID, bacnet.DaylightSavingsStatus, true)
+	d.Set(d.Iam.ObjectID, bacnet.LocalDate, bacnet.Date{Year: 2022, Month: 7, Day: 14, Weekday: 4})
+	d.Set(d.Iam.ObjectID, bacnet.LocalTime, bacnet.Time{Hour: 14, Minute: 30})
+	c := n.Client(t)
+
+	loc, err := c.DeviceLocation(context.Background(), d.Device())
+	is.NoErr(err)
+	is.Equal(loc.String(), "UTC+02:00")
+	now, err := c.DeviceTime(context.Background(), d.Device())
+	is.NoErr(err)
+	is.True(now.Equal(time.Date(2022, 7, 14, 12, 30, 0, 0, time.UTC)))
+	is.Equal(bacnet.DateTimeFromTime(now.In(loc)).Time, bacnet.Time{Hour: 14, Minute: 30})
+
+	//Without the daylight savings status, in New York
+	d = n.AddDevice(11)
+	d.Set(d.Iam.ObjectID, bacnet.UtcOffset, int32(300))
+	loc, err = c.DeviceLocation(context.Background(), d.Device())
+	is.NoErr(err)
+	is.Equal(loc.String(), "UTC-05:00")
+}
+
+func TestTypedRead(t *testing.T) {
+	is := is.New(t)
+	n := NewNetwork()
+	d := n.AddDevice(10)
+	d.Set(ai1, bacnet.PresentValue, float32(21.5))
+	d.Set(ai1, bacnet.Units, bacnet.PropertyValue{Type: bacnet.TypeEnumerated, Value: uint32(62)})
+	c := n.Client(t)
+	ctx := context.Background()
+
+	v, err := bacip.Read[float32](ctx, c, d.Device(), ai1, bacnet.PresentValue)
+	is.NoErr(err)
+	is.Equal(v, float32(21.5))
+	units, err := bacip.Read[bacnet.Unit](ctx, c, d.Device(), ai1, bacnet.Units)
+	is.NoErr(err)
+	is.Equal(units, bacnet.Unit(62))
+
+	_, err = bacip.Read[string](ctx, c, d.Device(), ai1, bacnet.PresentValue)
+	is.True(errors.Is(err, bacip.ErrTypeMismatch))
+	var mismatch bacip.TypeMismatchError
+	is.True(errors.As(err, &mismatch))
+	is.Equal(mismatch.Value, float32(21.5))
+	_, err = bacip.Read[float32](ctx, c, d.Device(), ai1, bacnet.Description)
+	is.True(!errors.Is(err, bacip.ErrTypeMismatch))
+}
+
+func TestReadDeviceID(t *testing.T) {
+	is := is.New(t)
+	n := NewNetwork()
+	d10 := n.AddDevice(10)
+	d20 := n.AddDevice(20)
+	c := n.Client(t)
+	c.SetReadCache(bacip.NewReadCache(time.Minute, bacnet.ObjectIdentifier))
+
+	device, err := c.ReadDeviceID(context.Background(), d10.Device().Addr)
+	is.NoErr(err)
+	is.Equal(device.ID, d10.Iam.ObjectID)
+	//Not answered from the cache
+	device, err = c.ReadDeviceID(context.Background(), d20.Device().Addr)
+	is.NoErr(err)
+	is.Equal(device.ID, d20.Iam.ObjectID)
+	AssertRead(t, d20, bacnet.WildcardDevice, bacnet.ObjectIdentifier)
+}
+
+func TestWriteAtPriority(t *testing.T) {
+	is := is.New(t)
+	n := NewNetwork()
+	d := n.AddDevice(10)
+	av1 := bacnet.ObjectID{Type: bacnet.AnalogValue, Instance: 1}
+	d.Set(av1, bacnet.PresentValue, float32(20))
+	c := n.Client(t)
+	ctx := context.Background()
+
+	is.NoErr(c.WriteAtPriority(ctx, d.Device(), av1, bacnet.PropertyValue{Value: 22}, bacnet.ManualOperator8))
+	is.NoErr(c.Relinquish(ctx, d.Device(), av1, bacnet.ManualOperator8))
+	requests := d.Requests()
+	is.Equal(len(requests), 2)
+	is.Equal(requests[0].Value, float32(22))
+	is.Equal(requests[0].Priority, bacnet.ManualOperator8)
+	is.Equal(requests[1].Value, nil)
+	is.Equal(requests[1].Priority, bacnet.ManualOperator8)
+
+	is.True(c.Relinquish(ctx, d.Device(), av1, 0) != nil)
+	is.True(c.WriteAtPriority(ctx, d.Device(), av1, bacnet.PropertyValue{Value: 22}, 17) != nil)
+	is.Equal(len(d.Requests()), 2)
+}
+
+func TestConfigureTrendLogFailure(t *testing.T) {
+	is := is.New(t)
+	n := NewNetwork()
+	d := n.AddDevice(10)
+	tl1 := bacnet.ObjectID{Type: bacnet.Trendlog, Instance: 1}
+	d.Set(tl1, bacnet.Enable, true)
+	d.Set(tl1, bacnet.LogInterval, uint32(6000))
+	c := n.Client(t)
+	interval := 30 * time.Second
+	enable := true
+	cfg := bacip.TrendLogConfig{LogInterval: &interval, Enable: &enable}
+	denied := &bacip.ApduError{Class: bacnet.PropertyError, Code: bacnet.WriteAccessDenied}
+
+	//The log is disabled, then the write of the interval fails
+	d.InjectFault(bacip.ServiceConfirmedWriteProperty, Fault{Error: denied, After: 1, Count: 1})
+	err := c.ConfigureTrendLog(context.Background(), d.Device(), tl1, cfg)
+	var apduErr bacip.ApduError
+	is.True(errors.As(err, &apduErr))
+	is.Equal(apduErr.Code, bacnet.WriteAccessDenied)
+	//The log is running again
+	is.Equal(d.Writes(tl1, bacnet.Enable), []interface{}{false, true})
+	AssertWritten(t, d, tl1, bacnet.Enable, true)
+
+	//Both errors are reported when the log can't be enabled again
+	d.InjectFault(bacip.ServiceConfirmedWriteProperty, Fault{Error: denied, After: 1})
+	err = c.ConfigureTrendLog(context.Background(), d.Device(), tl1, cfg)
+	is.True(errors.As(err, &apduErr))
+	is.True(strings.Contains(err.Error(), "enabling it again failed"))
+}
+
+func TestReadCacheRestart(t *testing.T) {
+	is := is.New(t)
+	n := NewNetwork()
+	d := n.AddDevice(10)
+	d.Set(ai1, bacnet.ObjectName, "OAT")
+	c := n.Client(t)
+	c.SetReadCache(bacip.NewReadCache(time.Minute))
+	read := func() interface{} {
+		v, err := c.ReadProperty(context.Background(), d.Device(), bacip.ReadProperty{
+			ObjectID: ai1,
+			Property: bacnet.PropertyIdentifier{Type: bacnet.ObjectName},
+		})
+		is.NoErr(err)
+		return v
+	}
+	is.Equal(read(), "OAT")
+	d.Set(ai1, bacnet.ObjectName, "Outside air")
+	d.ResetRequests()
+	is.Equal(read(), "OAT")
+	AssertNotRead(t, d, ai1, bacnet.ObjectName)
+
+	//The device restarted with another configuration
+	is.NoErr(d.NotifyRestart())
+	deadline := time.Now().Add(time.Second)
+	v := read()
+	for v != "Outside air" && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+		v = read()
+	}
+	is.Equal(v, "Outside air")
+	AssertRead(t, d, ai1, bacnet.ObjectName)
+}
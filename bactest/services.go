@@ -0,0 +1,364 @@
+package bactest
+
+import (
+	"net"
+	"time"
+
+	"github.com/REQUEA/bacnet"
+	"github.com/REQUEA/bacnet/bacip"
+	"github.com/REQUEA/bacnet/internal/encoding"
+)
+
+// serve answers a request sent to the device. It returns nil if the
+// request doesn't need an answer
+func (d *Device) serve(apdu *bacip.APDU, src net.UDPAddr) *bacip.APDU {
+	switch apdu.DataType {
+	case bacip.UnconfirmedServiceRequest:
+		if whoIs, ok := apdu.Payload.(*bacip.WhoIs); ok && d.includedIn(*whoIs) {
+			iam := d.Iam
+			return &bacip.APDU{
+				DataType:    bacip.UnconfirmedServiceRequest,
+				ServiceType: bacip.ServiceUnconfirmedIAm,
+				Payload:     &iam,
+			}
+		}
+		if whoHas, ok := apdu.Payload.(*bacip.WhoHas); ok {
+			return d.iHave(*whoHas)
+		}
+		return nil
+	case bacip.ConfirmedServiceRequest:
+	default:
+		return nil
+	}
+	var data []byte
+	if p, ok := apdu.Payload.(*bacip.DataPayload); ok {
+		data = p.Bytes
+	}
+	var ack []byte
+	var apduErr *bacip.ApduError
+	var rejected bool
+	switch apdu.ServiceType {
+	case bacip.ServiceConfirmedReadProperty:
+		ack, apduErr, rejected = d.readProperty(apdu.InvokeID, data)
+	case bacip.ServiceConfirmedReadPropMultiple:
+		ack, apduErr, rejected = d.readPropertyMultiple(apdu.InvokeID, data)
+	case bacip.ServiceConfirmedWriteProperty:
+		apduErr, rejected = d.writeProperty(apdu.InvokeID, data)
+	case bacip.ServiceConfirmedWritePropMultiple:
+		var wpmErr *bacip.WritePropertyMultipleError
+		wpmErr, rejected = d.writePropertyMultiple(apdu.InvokeID, data)
+		if wpmErr != nil && !rejected {
+			return &bacip.APDU{
+				DataType:    bacip.Error,
+				InvokeID:    apdu.InvokeID,
+				ServiceType: apdu.ServiceType,
+				Payload:     wpmErr,
+			}
+		}
+	case bacip.ServiceConfirmedSubscribeCOV:
+		apduErr, rejected = d.subscribeCOV(apdu.InvokeID, data, src)
+	default:
+		d.record(Request{Service: apdu.ServiceType, InvokeID: apdu.InvokeID})
+		return &bacip.APDU{
+			DataType:    bacip.Reject,
+			InvokeID:    apdu.InvokeID,
+			ServiceType: bacip.ServiceType(bacip.RejectReasonUnrecognizedService),
+			Payload:     &bacip.DataPayload{},
+		}
+	}
+	switch {
+	case rejected:
+		return &bacip.APDU{
+			DataType:    bacip.Reject,
+			InvokeID:    apdu.InvokeID,
+			ServiceType: bacip.ServiceType(bacip.RejectReasonInvalidTag),
+			Payload:     &bacip.DataPayload{},
+		}
+	case apduErr != nil:
+		return &bacip.APDU{
+			DataType:    bacip.Error,
+			InvokeID:    apdu.InvokeID,
+			ServiceType: apdu.ServiceType,
+			Payload:     apduErr,
+		}
+	case ack != nil:
+		return &bacip.APDU{
+			DataType:    bacip.ComplexAck,
+			InvokeID:    apdu.InvokeID,
+			ServiceType: apdu.ServiceType,
+			Payload:     &bacip.DataPayload{Bytes: ack},
+		}
+	default:
+		return &bacip.APDU{
+			DataType:    bacip.SimpleAck,
+			InvokeID:    apdu.InvokeID,
+			ServiceType: apdu.ServiceType,
+			Payload:     &bacip.DataPayload{},
+		}
+	}
+}
+
+// includedIn tells if the WhoIs range includes the device
+func (d *Device) includedIn(w bacip.WhoIs) bool {
+	if w.Low == nil || w.High == nil {
+		return true
+	}
+	instance := uint32(d.Iam.ObjectID.Instance)
+	return instance >= *w.Low && instance <= *w.High
+}
+
+// iHave returns the answer to a WhoHas, nil if the device doesn't
+// have the object
+func (d *Device) iHave(w bacip.WhoHas) *bacip.APDU {
+	if !d.includedIn(bacip.WhoIs{Low: w.Low, High: w.High}) {
+		return nil
+	}
+	d.Lock()
+	defer d.Unlock()
+	for id, props := range d.objects {
+		name, _ := props[bacnet.ObjectName].(string)
+		if (w.ObjectID != nil && id == *w.ObjectID) || (w.ObjectID == nil && name == w.ObjectName) {
+			return &bacip.APDU{
+				DataType:    bacip.UnconfirmedServiceRequest,
+				ServiceType: bacip.ServiceUnconfirmedIHave,
+				Payload:     &bacip.IHave{Device: d.Iam.ObjectID, ObjectID: id, ObjectName: name},
+			}
+		}
+	}
+	return nil
+}
+
+func (d *Device) record(r Request) {
+	d.Lock()
+	defer d.Unlock()
+	d.requests = append(d.requests, r)
+}
+
+// decodeProperty decodes a property identifier and its optional array
+// index
+func decodeProperty(dec *encoding.Decoder, propTag byte) bacnet.PropertyIdentifier {
+	var val uint32
+	dec.ContextValue(propTag, &val)
+	prop := bacnet.PropertyIdentifier{Type: bacnet.PropertyType(val)}
+	if dec.IsContextTag(propTag + 1) {
+		prop.ArrayIndex = new(uint32)
+		dec.ContextValue(propTag+1, prop.ArrayIndex)
+	}
+	return prop
+}
+
+func encodeProperty(e *encoding.Encoder, propTag byte, prop bacnet.PropertyIdentifier) {
+	e.ContextUnsigned(propTag, uint32(prop.Type))
+	if prop.ArrayIndex != nil {
+		e.ContextUnsigned(propTag+1, *prop.ArrayIndex)
+	}
+}
+
+func (d *Device) readProperty(invokeID byte, data []byte) ([]byte, *bacip.ApduError, bool) {
+	dec := encoding.NewDecoder(data)
+	var id bacnet.ObjectID
+	dec.ContextObjectID(0, &id)
+	prop := decodeProperty(dec, 1)
+	if dec.Error() != nil {
+		return nil, nil, true
+	}
+	d.record(Request{Service: bacip.ServiceConfirmedReadProperty, InvokeID: invokeID, Object: id, Property: prop})
+	d.Lock()
+	raw, apduErr := d.read(id, prop)
+	d.Unlock()
+	if apduErr != nil {
+		return nil, apduErr, false
+	}
+	e := encoding.NewEncoder()
+	e.ContextObjectID(0, id)
+	encodeProperty(&e, 1, prop)
+	e.ContextRaw(3, raw)
+	return e.Bytes(), nil, false
+}
+
+func (d *Device) readPropertyMultiple(invokeID byte, data []byte) ([]byte, *bacip.ApduError, bool) {
+	dec := encoding.NewDecoder(data)
+	e := encoding.NewEncoder()
+	for dec.Error() == nil && dec.Len() > 0 {
+		var id bacnet.ObjectID
+		dec.ContextObjectID(0, &id)
+		dec.OpeningTag(1)
+		var props []bacnet.PropertyIdentifier
+		for dec.Error() == nil && !dec.IsClosingTag(1) {
+			props = append(props, decodeProperty(dec, 0))
+		}
+		dec.ClosingTag(1)
+		if dec.Error() != nil {
+			return nil, nil, true
+		}
+		e.ContextObjectID(0, id)
+		e.OpeningTag(1)
+		d.Lock()
+		for _, prop := range props {
+			d.requests = append(d.requests, Request{Service: bacip.ServiceConfirmedReadPropMultiple, InvokeID: invokeID, Object: id, Property: prop})
+			all := []bacnet.PropertyIdentifier{prop}
+			if _, ok := d.objects[d.object(id)]; ok && (prop.Type == bacnet.All || prop.Type == bacnet.Required || prop.Type == bacnet.Optional) {
+				all = nil
+				for _, p := range d.properties(id) {
+					all = append(all, bacnet.PropertyIdentifier{Type: p})
+				}
+			}
+			for _, p := range all {
+				encodeProperty(&e, 2, p)
+				raw, apduErr := d.read(id, p)
+				if apduErr != nil {
+					e.OpeningTag(5)
+					e.AppValue(bacnet.PropertyValue{Type: bacnet.TypeEnumerated, Value: uint32(apduErr.Class)})
+					e.AppValue(bacnet.PropertyValue{Type: bacnet.TypeEnumerated, Value: uint32(apduErr.Code)})
+					e.ClosingTag(5)
+					continue
+				}
+				e.ContextRaw(4, raw)
+			}
+		}
+		d.Unlock()
+		e.ClosingTag(1)
+	}
+	if dec.Error() != nil {
+		return nil, nil, true
+	}
+	return e.Bytes(), nil, e.Error() != nil
+}
+
+func (d *Device) writeProperty(invokeID byte, data []byte) (*bacip.ApduError, bool) {
+	dec := encoding.NewDecoder(data)
+	var id bacnet.ObjectID
+	dec.ContextObjectID(0, &id)
+	prop := decodeProperty(dec, 1)
+	var raw []byte
+	dec.ContextRaw(3, &raw)
+	var priority uint32
+	if dec.IsContextTag(4) {
+		dec.ContextValue(4, &priority)
+	}
+	if dec.Error() != nil {
+		return nil, true
+	}
+	d.record(Request{
+		Service:  bacip.ServiceConfirmedWriteProperty,
+		InvokeID: invokeID,
+		Object:   id,
+		Property: prop,
+		Value:    decodeValue(raw),
+		Priority: bacnet.PriorityList(priority),
+	})
+	d.Lock()
+	defer d.Unlock()
+	return d.write(id, prop, raw), false
+}
+
+func (d *Device) writePropertyMultiple(invokeID byte, data []byte) (*bacip.WritePropertyMultipleError, bool) {
+	type write struct {
+		id       bacnet.ObjectID
+		prop     bacnet.PropertyIdentifier
+		raw      []byte
+		priority uint32
+	}
+	var writes []write
+	dec := encoding.NewDecoder(data)
+	for dec.Error() == nil && dec.Len() > 0 {
+		var id bacnet.ObjectID
+		dec.ContextObjectID(0, &id)
+		dec.OpeningTag(1)
+		for dec.Error() == nil && !dec.IsClosingTag(1) {
+			w := write{id: id, prop: decodeProperty(dec, 0)}
+			dec.ContextRaw(2, &w.raw)
+			if dec.IsContextTag(3) {
+				dec.ContextValue(3, &w.priority)
+			}
+			writes = append(writes, w)
+		}
+		dec.ClosingTag(1)
+	}
+	if dec.Error() != nil {
+		return nil, true
+	}
+	d.Lock()
+	defer d.Unlock()
+	for _, w := range writes {
+		d.requests = append(d.requests, Request{
+			Service:  bacip.ServiceConfirmedWritePropMultiple,
+			InvokeID: invokeID,
+			Object:   w.id,
+			Property: w.prop,
+			Value:    decodeValue(w.raw),
+			Priority: bacnet.PriorityList(w.priority),
+		})
+	}
+	for _, w := range writes {
+		if apduErr := d.write(w.id, w.prop, w.raw); apduErr != nil {
+			return &bacip.WritePropertyMultipleError{Err: *apduErr, ObjectID: w.id, Property: w.prop}, false
+		}
+	}
+	return nil, false
+}
+
+// write writes an encoded value to a property. The lock must be held
+func (d *Device) write(id bacnet.ObjectID, prop bacnet.PropertyIdentifier, raw []byte) *bacip.ApduError {
+	id = d.object(id)
+	v, apduErr := d.property(id, prop.Type)
+	if apduErr != nil && apduErr.Code == bacnet.UnknownObject {
+		return apduErr
+	}
+	if prop.ArrayIndex == nil {
+		d.objects[id][prop.Type] = bacip.RawValue(raw)
+		return nil
+	}
+	array, ok := v.([]interface{})
+	if !ok {
+		return &bacip.ApduError{Class: bacnet.PropertyError, Code: bacnet.PropertyIsNotAnArray}
+	}
+	i := int(*prop.ArrayIndex)
+	if i == 0 || i > len(array) {
+		return &bacip.ApduError{Class: bacnet.PropertyError, Code: bacnet.InvalidArrayIndex}
+	}
+	array = append([]interface{}(nil), array...)
+	array[i-1] = bacip.RawValue(raw)
+	d.objects[id][prop.Type] = array
+	return nil
+}
+
+func (d *Device) subscribeCOV(invokeID byte, data []byte, src net.UDPAddr) (*bacip.ApduError, bool) {
+	dec := encoding.NewDecoder(data)
+	s := Subscription{Subscriber: src}
+	dec.ContextValue(0, &s.ProcessID)
+	dec.ContextObjectID(1, &s.Object)
+	//A request without the optional parameters cancels the
+	//subscription
+	cancellation := true
+	if dec.IsContextTag(2) {
+		cancellation = false
+		dec.ContextBool(2, &s.Confirmed)
+	}
+	if dec.IsContextTag(3) {
+		cancellation = false
+		var lifetime uint32
+		dec.ContextValue(3, &lifetime)
+		s.Lifetime = time.Duration(lifetime) * time.Second
+	}
+	if dec.Error() != nil {
+		return nil, true
+	}
+	d.record(Request{Service: bacip.ServiceConfirmedSubscribeCOV, InvokeID: invokeID, Object: s.Object})
+	d.Lock()
+	defer d.Unlock()
+	if _, ok := d.objects[s.Object]; !ok {
+		return &bacip.ApduError{Class: bacnet.ObjectError, Code: bacnet.UnknownObject}, false
+	}
+	subs := d.subscriptions[:0]
+	for _, old := range d.subscriptions {
+		if old.ProcessID != s.ProcessID || old.Object != s.Object || old.Subscriber.String() != s.Subscriber.String() {
+			subs = append(subs, old)
+		}
+	}
+	d.subscriptions = subs
+	if !cancellation {
+		d.subscriptions = append(d.subscriptions, s)
+	}
+	return nil, false
+}
@@ -0,0 +1,238 @@
+package bacnet
+
+import (
+	"fmt"
+	"time"
+)
+
+// Unspecified is the value used by bacnet for date and time fields
+// that are not specified (wildcards)
+const Unspecified = 0xFF
+
+// Date is a bacnet date. Year is the full year (1900-2154) and Weekday
+// goes from 1 (Monday) to 7 (Sunday). Fields may be set to Unspecified,
+// and Month and Day to the special values below, making the date a
+// pattern matching several days.
+type Date struct {
+	Year    int
+	Month   int
+	Day     int
+	Weekday int
+}
+
+// UnspecifiedYear is the value of Date.Year when the year isn't
+// specified. A Year set to Unspecified is encoded the same way
+const UnspecifiedYear = 1900 + Unspecified
+
+// Special values of Date.Month and Date.Day
+const (
+	OddMonths      = 13
+	EvenMonths     = 14
+	LastDayOfMonth = 32
+	OddDays        = 33
+	EvenDays       = 34
+)
+
+// DateFromTime returns the bacnet date of the given time
+func DateFromTime(t time.Time) Date {
+	wd := int(t.Weekday())
+	if wd == 0 {
+		wd = 7
+	}
+	return Date{
+		Year:    t.Year(),
+		Month:   int(t.Month()),
+		Day:     t.Day(),
+		Weekday: wd,
+	}
+}
+
+// Specified tells if the date is a single day: its year, month and day
+// aren't unspecified nor special values. The weekday may be unspecified
+func (d Date) Specified() bool {
+	return d.Year != UnspecifiedYear && d.Year != Unspecified &&
+		d.Month >= 1 && d.Month <= 12 && d.Day >= 1 && d.Day <= 31
+}
+
+// Matches tells if the day of t matches the date, taking the
+// unspecified fields and special values into account
+func (d Date) Matches(t time.Time) bool {
+	year, month, day := t.Date()
+	if d.Year != UnspecifiedYear && d.Year != Unspecified && d.Year != year {
+		return false
+	}
+	switch d.Month {
+	case Unspecified:
+	case OddMonths:
+		if month%2 == 0 {
+			return false
+		}
+	case EvenMonths:
+		if month%2 == 1 {
+			return false
+		}
+	default:
+		if time.Month(d.Month) != month {
+			return false
+		}
+	}
+	switch d.Day {
+	case Unspecified:
+	case LastDayOfMonth:
+		if t.AddDate(0, 0, 1).Day() != 1 {
+			return false
+		}
+	case OddDays:
+		if day%2 == 0 {
+			return false
+		}
+	case EvenDays:
+		if day%2 == 1 {
+			return false
+		}
+	default:
+		if d.Day != day {
+			return false
+		}
+	}
+	wd := int(t.Weekday())
+	if wd == 0 {
+		wd = 7
+	}
+	return d.Weekday == Unspecified || d.Weekday == wd
+}
+
+func (d Date) String() string {
+	month := field(d.Month, 2, d.Month == Unspecified)
+	switch d.Month {
+	case OddMonths:
+		month = "odd"
+	case EvenMonths:
+		month = "even"
+	}
+	day := field(d.Day, 2, d.Day == Unspecified)
+	switch d.Day {
+	case LastDayOfMonth:
+		day = "last"
+	case OddDays:
+		day = "odd"
+	case EvenDays:
+		day = "even"
+	}
+	return fmt.Sprintf("%s-%s-%s", field(d.Year, 4, d.Year == UnspecifiedYear || d.Year == Unspecified), month, day)
+}
+
+// Time is a bacnet time of the day. Fields may be set to Unspecified.
+type Time struct {
+	Hour       int
+	Minute     int
+	Second     int
+	Hundredths int
+}
+
+// TimeFromTime returns the bacnet time of the day of the given time
+func TimeFromTime(t time.Time) Time {
+	return Time{
+		Hour:       t.Hour(),
+		Minute:     t.Minute(),
+		Second:     t.Second(),
+		Hundredths: t.Nanosecond() / int(10*time.Millisecond),
+	}
+}
+
+// Duration returns the time elapsed since midnight. Unspecified
+// fields are counted as zero
+func (t Time) Duration() time.Duration {
+	return time.Duration(specified(t.Hour))*time.Hour +
+		time.Duration(specified(t.Minute))*time.Minute +
+		time.Duration(specified(t.Second))*time.Second +
+		time.Duration(specified(t.Hundredths))*10*time.Millisecond
+}
+
+// Matches tells if the time of the day of t matches the time. The
+// unspecified fields match any value
+func (t Time) Matches(tt time.Time) bool {
+	return (t.Hour == Unspecified || t.Hour == tt.Hour()) &&
+		(t.Minute == Unspecified || t.Minute == tt.Minute()) &&
+		(t.Second == Unspecified || t.Second == tt.Second()) &&
+		(t.Hundredths == Unspecified || t.Hundredths == tt.Nanosecond()/int(10*time.Millisecond))
+}
+
+func (t Time) String() string {
+	return fmt.Sprintf("%s:%s:%s.%s", field(t.Hour, 2, t.Hour == Unspecified), field(t.Minute, 2, t.Minute == Unspecified), field(t.Second, 2, t.Second == Unspecified), field(t.Hundredths, 2, t.Hundredths == Unspecified))
+}
+
+// DateTime is a bacnet date and time pair
+type DateTime struct {
+	Date Date
+	Time Time
+}
+
+// DateTimeFromTime returns the bacnet date and time of the given time
+func DateTimeFromTime(t time.Time) DateTime {
+	return DateTime{Date: DateFromTime(t), Time: TimeFromTime(t)}
+}
+
+// ToTime converts the date time in a go time in the given
+// location. Unspecified fields of the time are counted as zero. The
+// date must be specified.
+func (dt DateTime) ToTime(loc *time.Location) time.Time {
+	d := time.Date(dt.Date.Year, time.Month(dt.Date.Month), dt.Date.Day, 0, 0, 0, 0, loc)
+	return d.Add(dt.Time.Duration())
+}
+
+func (dt DateTime) String() string {
+	return dt.Date.String() + " " + dt.Time.String()
+}
+
+// DeviceLocation returns the time zone of a device from its UTC_Offset
+// property, in minutes west of UTC, and its Daylight_Savings_Status
+// property. Daylight saving time is assumed to add one hour. The zone
+// is fixed: it must be read again when the daylight saving status of
+// the device changes. The date times of the device are converted with
+// ToTime and DateTimeFromTime(t.In(loc))
+func DeviceLocation(utcOffset int, daylightSavings bool) *time.Location {
+	offset := -utcOffset * 60
+	if daylightSavings {
+		offset += 3600
+	}
+	sign := '+'
+	minutes := offset / 60
+	if minutes < 0 {
+		sign = '-'
+		minutes = -minutes
+	}
+	return time.FixedZone(fmt.Sprintf("UTC%c%02d:%02d", sign, minutes/60, minutes%60), offset)
+}
+
+//go:generate stringer -type=TimeStampKind
+type TimeStampKind byte
+
+const (
+	TimeStampTime     TimeStampKind = 0
+	TimeStampSequence TimeStampKind = 1
+	TimeStampDateTime TimeStampKind = 2
+)
+
+// TimeStamp is a time, a sequence number or a date time depending on
+// its Kind. Only the field matching the kind is meaningful
+type TimeStamp struct {
+	Kind           TimeStampKind
+	Time           Time
+	SequenceNumber uint32
+	DateTime       DateTime
+}
+
+func field(v, width int, unspecified bool) string {
+	if unspecified {
+		return "*"
+	}
+	return fmt.Sprintf("%0*d", width, v)
+}
+
+func specified(v int) int {
+	if v == Unspecified {
+		return 0
+	}
+	return v
+}
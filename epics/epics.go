@@ -0,0 +1,241 @@
+// Package epics builds the EPICS (Electronic Protocol Implementation
+// Conformance Statement) of a device, as defined by ASHRAE 135.1, by
+// reading all its objects. The statement documents the commissioning
+// of the device and is the base of its conformance review
+package epics
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/REQUEA/bacnet"
+	"github.com/REQUEA/bacnet/bacip"
+)
+
+// Object is an object of the device and the values of its properties
+type Object struct {
+	ID bacnet.ObjectID
+	//Properties are decoded like bacip.ReadProperty.Data
+	Properties map[bacnet.PropertyType]interface{}
+}
+
+// Document is the EPICS of a device
+type Document struct {
+	Device                     bacnet.ObjectID
+	VendorID                   uint32
+	VendorName                 string
+	ModelName                  string
+	Description                string
+	FirmwareRevision           string
+	ApplicationSoftwareVersion string
+	ProtocolRevision           uint32
+	//Services are the names of the services the device executes, as
+	//listed by its protocol services supported
+	Services []string
+	//ObjectTypes are the object types listed by the protocol object
+	//types supported of the device
+	ObjectTypes []bacnet.ObjectType
+	//BIBBs are inferred from the services and object types
+	BIBBs []string
+	//Objects are sorted by ID, the device object first
+	Objects []Object
+}
+
+// Read walks the device: it reads all the properties of its device
+// object, then of every object of its object list. The services,
+// object types and BIBBs are taken from the device object
+func Read(ctx context.Context, c *bacip.Client, device bacnet.Device) (*Document, error) {
+	props, err := c.ReadObject(ctx, device, device.ID)
+	if err != nil {
+		return nil, fmt.Errorf("read device object: %w", err)
+	}
+	ids, err := c.ReadObjectList(ctx, device)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		a, b := ids[i], ids[j]
+		return a.Type < b.Type || (a.Type == b.Type && a.Instance < b.Instance)
+	})
+	list := make([]interface{}, len(ids))
+	for i, id := range ids {
+		list[i] = id
+	}
+	//Sorted like the objects of the document
+	props[bacnet.ObjectList] = list
+
+	d := &Document{Device: device.ID, Objects: []Object{{ID: device.ID, Properties: props}}}
+	d.VendorID, _ = props[bacnet.VendorIdentifier].(uint32)
+	d.VendorName, _ = props[bacnet.VendorName].(string)
+	d.ModelName, _ = props[bacnet.ModelName].(string)
+	d.Description, _ = props[bacnet.Description].(string)
+	d.FirmwareRevision, _ = props[bacnet.FirmwareRevision].(string)
+	d.ApplicationSoftwareVersion, _ = props[bacnet.ApplicationSoftwareVersion].(string)
+	d.ProtocolRevision, _ = props[bacnet.ProtocolRevision].(uint32)
+	services, _ := props[bacnet.ProtocolServicesSupported].(bacnet.BitString)
+	for i, supported := range services {
+		if supported && i < len(serviceNames) {
+			d.Services = append(d.Services, serviceNames[i])
+		}
+	}
+	types, _ := props[bacnet.ProtocolObjectTypesSupported].(bacnet.BitString)
+	for i, supported := range types {
+		if supported {
+			d.ObjectTypes = append(d.ObjectTypes, bacnet.ObjectType(i))
+		}
+	}
+	d.BIBBs = inferBIBBs(services, types)
+
+	for _, id := range ids {
+		if id == device.ID {
+			continue
+		}
+		props, err := c.ReadObject(ctx, device, id)
+		if err != nil {
+			return nil, err
+		}
+		d.Objects = append(d.Objects, Object{ID: id, Properties: props})
+	}
+	return d, nil
+}
+
+// serviceNames are the names of the services, indexed by their bit in
+// the protocol services supported
+var serviceNames = []string{
+	"AcknowledgeAlarm",
+	"ConfirmedCOVNotification",
+	"ConfirmedEventNotification",
+	"GetAlarmSummary",
+	"GetEnrollmentSummary",
+	"SubscribeCOV",
+	"AtomicReadFile",
+	"AtomicWriteFile",
+	"AddListElement",
+	"RemoveListElement",
+	"CreateObject",
+	"DeleteObject",
+	"ReadProperty",
+	"ReadPropertyConditional",
+	"ReadPropertyMultiple",
+	"WriteProperty",
+	"WritePropertyMultiple",
+	"DeviceCommunicationControl",
+	"ConfirmedPrivateTransfer",
+	"ConfirmedTextMessage",
+	"ReinitializeDevice",
+	"VT-Open",
+	"VT-Close",
+	"VT-Data",
+	"Authenticate",
+	"RequestKey",
+	"I-Am",
+	"I-Have",
+	"UnconfirmedCOVNotification",
+	"UnconfirmedEventNotification",
+	"UnconfirmedPrivateTransfer",
+	"UnconfirmedTextMessage",
+	"TimeSynchronization",
+	"Who-Has",
+	"Who-Is",
+	"ReadRange",
+	"UTCTimeSynchronization",
+	"LifeSafetyOperation",
+	"SubscribeCOVProperty",
+	"GetEventInformation",
+	"WriteGroup",
+	"SubscribeCOVPropertyMultiple",
+	"ConfirmedCOVNotificationMultiple",
+	"UnconfirmedCOVNotificationMultiple",
+	"ConfirmedAuditNotification",
+	"AuditLogQuery",
+	"UnconfirmedAuditNotification",
+	"Who-Am-I",
+	"You-Are",
+}
+
+// Bits of the protocol services supported
+const (
+	acknowledgeAlarm           = 0
+	getAlarmSummary            = 3
+	getEnrollmentSummary       = 4
+	subscribeCOV               = 5
+	atomicReadFile             = 6
+	atomicWriteFile            = 7
+	addListElement             = 8
+	removeListElement          = 9
+	createObject               = 10
+	deleteObject               = 11
+	readProperty               = 12
+	readPropertyMultiple       = 14
+	writeProperty              = 15
+	writePropertyMultiple      = 16
+	deviceCommunicationControl = 17
+	confirmedPrivateTransfer   = 18
+	reinitializeDevice         = 20
+	timeSynchronization        = 32
+	whoHas                     = 33
+	whoIs                      = 34
+	readRange                  = 35
+	utcTimeSynchronization     = 36
+	subscribeCOVProperty       = 38
+	getEventInformation        = 39
+	writeGroup                 = 40
+)
+
+// bibbRule infers a BIBB from the services a device executes and the
+// object types it supports
+type bibbRule struct {
+	name        string
+	services    []int
+	objectTypes []bacnet.ObjectType
+}
+
+// bibbRules are the BIBBs of a device answering requests (B side)
+// that can be inferred. Those of a device initiating them (A side)
+// can't be, as the protocol services supported only list the services
+// executed
+var bibbRules = []bibbRule{
+	{name: "DS-RP-B", services: []int{readProperty}},
+	{name: "DS-RPM-B", services: []int{readPropertyMultiple}},
+	{name: "DS-WP-B", services: []int{writeProperty}},
+	{name: "DS-WPM-B", services: []int{writePropertyMultiple}},
+	{name: "DS-COV-B", services: []int{subscribeCOV}},
+	{name: "DS-COVP-B", services: []int{subscribeCOVProperty}},
+	{name: "DS-WG-B", services: []int{writeGroup}, objectTypes: []bacnet.ObjectType{bacnet.Channel}},
+	{name: "AE-ACK-B", services: []int{acknowledgeAlarm}},
+	{name: "AE-ASUM-B", services: []int{getAlarmSummary}},
+	{name: "AE-ESUM-B", services: []int{getEnrollmentSummary}},
+	{name: "AE-INFO-B", services: []int{getEventInformation}},
+	{name: "SCHED-I-B", services: []int{readProperty}, objectTypes: []bacnet.ObjectType{bacnet.Schedule, bacnet.Calendar}},
+	{name: "T-VMT-I-B", services: []int{readRange}, objectTypes: []bacnet.ObjectType{bacnet.Trendlog}},
+	{name: "DM-DDB-B", services: []int{whoIs}},
+	{name: "DM-DOB-B", services: []int{whoHas}},
+	{name: "DM-DCC-B", services: []int{deviceCommunicationControl}},
+	{name: "DM-PT-B", services: []int{confirmedPrivateTransfer}},
+	{name: "DM-TS-B", services: []int{timeSynchronization}},
+	{name: "DM-UTC-B", services: []int{utcTimeSynchronization}},
+	{name: "DM-RD-B", services: []int{reinitializeDevice}},
+	{name: "DM-BR-B", services: []int{atomicReadFile, atomicWriteFile, reinitializeDevice}, objectTypes: []bacnet.ObjectType{bacnet.File}},
+	{name: "DM-LM-B", services: []int{addListElement, removeListElement}},
+	{name: "DM-OCD-B", services: []int{createObject, deleteObject}},
+}
+
+// inferBIBBs returns the BIBBs whose services and object types are all
+// supported
+func inferBIBBs(services, objectTypes bacnet.BitString) []string {
+	var bibbs []string
+	for _, r := range bibbRules {
+		supported := true
+		for _, s := range r.services {
+			supported = supported && services.Bit(s)
+		}
+		for _, t := range r.objectTypes {
+			supported = supported && objectTypes.Bit(int(t))
+		}
+		if supported {
+			bibbs = append(bibbs, r.name)
+		}
+	}
+	return bibbs
+}
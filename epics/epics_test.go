@@ -0,0 +1,97 @@
+package epics
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/REQUEA/bacnet"
+	"github.com/REQUEA/bacnet/bactest"
+	"github.com/matryer/is"
+)
+
+func TestRead(t *testing.T) {
+	is := is.New(t)
+	n := bactest.NewNetwork()
+	d := n.AddDevice(10)
+	dev := d.Iam.ObjectID
+	ai1 := bacnet.ObjectID{Type: bacnet.AnalogInput, Instance: 1}
+	services := make(bacnet.BitString, 40)
+	services[readProperty] = true
+	services[readPropertyMultiple] = true
+	services[whoIs] = true
+	services[readRange] = true
+	types := make(bacnet.BitString, 21)
+	types[bacnet.AnalogInput] = true
+	types[bacnet.BacnetDevice] = true
+	d.Set(dev, bacnet.ObjectName, "AHU-1")
+	d.Set(dev, bacnet.VendorName, "Acme \"Controls\"")
+	d.Set(dev, bacnet.ModelName, "AC-100")
+	d.Set(dev, bacnet.ProtocolRevision, uint32(14))
+	d.Set(dev, bacnet.ProtocolServicesSupported, services)
+	d.Set(dev, bacnet.ProtocolObjectTypesSupported, types)
+	d.Set(ai1, bacnet.ObjectName, "OutdoorTemp")
+	d.Set(ai1, bacnet.PresentValue, float32(21.5))
+	d.Set(ai1, bacnet.StatusFlags, bacnet.BitString{false, true, false, false})
+	c := n.Client(t)
+
+	doc, err := Read(context.Background(), c, d.Device())
+	is.NoErr(err)
+	is.Equal(doc.VendorName, "Acme \"Controls\"")
+	is.Equal(doc.ProtocolRevision, uint32(14))
+	is.Equal(doc.Services, []string{"ReadProperty", "ReadPropertyMultiple", "Who-Is", "ReadRange"})
+	is.Equal(doc.ObjectTypes, []bacnet.ObjectType{bacnet.AnalogInput, bacnet.BacnetDevice})
+	//Without trend log, T-VMT-I-B isn't inferred
+	is.Equal(doc.BIBBs, []string{"DS-RP-B", "DS-RPM-B", "DM-DDB-B"})
+	is.Equal(len(doc.Objects), 2)
+	is.Equal(doc.Objects[0].ID, dev)
+	is.Equal(doc.Objects[1].Properties[bacnet.PresentValue], float32(21.5))
+
+	var text bytes.Buffer
+	is.NoErr(doc.WriteText(&text))
+	for _, line := range []string{
+		`Vendor Name: "Acme ""Controls"""`,
+		"BACnet Protocol Revision: 14",
+		"  DS-RPM-B",
+		"  analog-input",
+		"  ReadRange Execute",
+		"    object-identifier: (device, 10)",
+		"    object-list: {(analog-input, 1), (device, 10)}",
+		"    object-type: 0",
+		`    object-name: "OutdoorTemp"`,
+		"    present-value: 21.5",
+		"    status-flags: {FALSE,TRUE,FALSE,FALSE}",
+	} {
+		is.True(strings.Contains(text.String(), line+"\n"))
+	}
+	is.True(strings.HasSuffix(text.String(), "End of BACnet Protocol Implementation Conformance Statement\n"))
+
+	b, err := json.Marshal(doc)
+	is.NoErr(err)
+	var decoded struct {
+		Device  string   `json:"device"`
+		BIBBs   []string `json:"bibbs"`
+		Objects []struct {
+			ID         string            `json:"id"`
+			Properties map[string]string `json:"properties"`
+		} `json:"objects"`
+	}
+	is.NoErr(json.Unmarshal(b, &decoded))
+	is.Equal(decoded.Device, "device:10")
+	is.Equal(decoded.BIBBs, doc.BIBBs)
+	is.Equal(decoded.Objects[1].ID, "analog-input:1")
+	is.Equal(decoded.Objects[1].Properties["present-value"], "21.5")
+}
+
+func TestPropertyName(t *testing.T) {
+	is := is.New(t)
+	is.Equal(PropertyName(bacnet.PresentValue), "present-value")
+	is.Equal(PropertyName(bacnet.ObjectTypeProp), "object-type")
+	is.Equal(PropertyName(bacnet.EventState), "event-state")
+	is.Equal(PropertyName(bacnet.NotifyType), "notify-type")
+	is.Equal(PropertyName(bacnet.AccessEvent), "access-event")
+	is.Equal(PropertyName(bacnet.MaxApduLengthAccepted), "max-apdu-length-accepted")
+	is.Equal(PropertyName(bacnet.PropertyType(600)), "600")
+}
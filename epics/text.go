@@ -0,0 +1,225 @@
+package epics
+
+import (
+	"bufio"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/REQUEA/bacnet"
+	"github.com/REQUEA/bacnet/bacip"
+)
+
+// WriteText writes the document in the EPICS text format of ASHRAE
+// 135.1 Annex A, as read by the conformance test tools. The values
+// the tools can't know, such as the present values, are written as
+// read
+func (d *Document) WriteText(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "PICS 0")
+	fmt.Fprintln(bw, "BACnet Protocol Implementation Conformance Statement")
+	fmt.Fprintln(bw)
+	fmt.Fprintf(bw, "Vendor Name: %s\n", quote(d.VendorName))
+	fmt.Fprintf(bw, "Product Name: %s\n", quote(d.ModelName))
+	fmt.Fprintf(bw, "Product Model Number: %s\n", quote(d.ModelName))
+	fmt.Fprintf(bw, "Product Description: %s\n", quote(d.Description))
+	fmt.Fprintf(bw, "Firmware Revision: %s\n", quote(d.FirmwareRevision))
+	fmt.Fprintf(bw, "Application Software Version: %s\n", quote(d.ApplicationSoftwareVersion))
+	fmt.Fprintf(bw, "BACnet Protocol Revision: %d\n", d.ProtocolRevision)
+	fmt.Fprintln(bw)
+	writeSection(bw, "BIBBs Supported:", d.BIBBs)
+	types := make([]string, len(d.ObjectTypes))
+	for i, t := range d.ObjectTypes {
+		types[i] = bacnet.ObjectTypeName(d.VendorID, t)
+	}
+	writeSection(bw, "Standard Object Types Supported:", types)
+	services := make([]string, len(d.Services))
+	for i, s := range d.Services {
+		services[i] = s + " Execute"
+	}
+	writeSection(bw, "BACnet Standard Application Services Supported:", services)
+	writeSection(bw, "Data Link Layer Option:", []string{"BACnet IP, (Annex J)"})
+
+	fmt.Fprintln(bw, "List of Objects in test device:")
+	fmt.Fprintln(bw, "{")
+	for i, o := range d.Objects {
+		fmt.Fprintln(bw, "  {")
+		for _, p := range sortedProperties(o.Properties) {
+			fmt.Fprintf(bw, "    %s: %s\n", PropertyName(p), d.formatValue(o.Properties[p]))
+		}
+		if i < len(d.Objects)-1 {
+			fmt.Fprintln(bw, "  },")
+		} else {
+			fmt.Fprintln(bw, "  }")
+		}
+	}
+	fmt.Fprintln(bw, "}")
+	fmt.Fprintln(bw)
+	fmt.Fprintln(bw, "End of BACnet Protocol Implementation Conformance Statement")
+	return bw.Flush()
+}
+
+func writeSection(w io.Writer, title string, lines []string) {
+	fmt.Fprintln(w, title)
+	fmt.Fprintln(w, "{")
+	for _, l := range lines {
+		fmt.Fprintf(w, "  %s\n", l)
+	}
+	fmt.Fprintln(w, "}")
+	fmt.Fprintln(w)
+}
+
+// sortedProperties returns the properties in the order of the EPICS:
+// the identifier, name and type of the object first, then by number
+func sortedProperties(props map[bacnet.PropertyType]interface{}) []bacnet.PropertyType {
+	rank := func(p bacnet.PropertyType) int {
+		switch p {
+		case bacnet.ObjectIdentifier:
+			return 0
+		case bacnet.ObjectName:
+			return 1
+		case bacnet.ObjectTypeProp:
+			return 2
+		}
+		return 3
+	}
+	sorted := make([]bacnet.PropertyType, 0, len(props))
+	for p := range props {
+		sorted = append(sorted, p)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if rank(a) != rank(b) {
+			return rank(a) < rank(b)
+		}
+		return a < b
+	})
+	return sorted
+}
+
+// PropertyName returns the name of a property as written in the
+// standard, such as present-value. Proprietary properties are written
+// as numbers
+func PropertyName(p bacnet.PropertyType) string {
+	name := p.String()
+	if strings.HasPrefix(name, "PropertyType(") {
+		return strconv.Itoa(int(p))
+	}
+	//Suffix of the constants named like a type
+	name = strings.TrimSuffix(name, "Prop")
+	var b strings.Builder
+	for i, r := range name {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('-')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// formatValue formats a property value with the EPICS syntax
+func (d *Document) formatValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "NULL"
+	case bool:
+		if v {
+			return "TRUE"
+		}
+		return "FALSE"
+	case string:
+		return quote(v)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'g', -1, 32)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case []byte:
+		return "X'" + strings.ToUpper(hex.EncodeToString(v)) + "'"
+	case bacnet.ObjectID:
+		return fmt.Sprintf("(%s, %d)", bacnet.ObjectTypeName(d.VendorID, v.Type), v.Instance)
+	case bacnet.BitString:
+		bits := make([]string, len(v))
+		for i, b := range v {
+			bits[i] = d.formatValue(b)
+		}
+		return "{" + strings.Join(bits, ",") + "}"
+	case bacnet.Date:
+		return "(" + v.String() + ")"
+	case bacnet.Time:
+		return v.String()
+	case []interface{}:
+		values := make([]string, len(v))
+		for i, e := range v {
+			values[i] = d.formatValue(e)
+		}
+		return "{" + strings.Join(values, ", ") + "}"
+	case bacip.RawValue:
+		//Constructed values the conformance tools don't compare
+		return "?"
+	}
+	return fmt.Sprint(v)
+}
+
+func quote(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
+
+type jsonDocument struct {
+	Device                     string       `json:"device"`
+	VendorID                   uint32       `json:"vendorId"`
+	VendorName                 string       `json:"vendorName"`
+	ModelName                  string       `json:"modelName"`
+	Description                string       `json:"description,omitempty"`
+	FirmwareRevision           string       `json:"firmwareRevision"`
+	ApplicationSoftwareVersion string       `json:"applicationSoftwareVersion,omitempty"`
+	ProtocolRevision           uint32       `json:"protocolRevision"`
+	BIBBs                      []string     `json:"bibbs"`
+	ObjectTypes                []string     `json:"objectTypes"`
+	Services                   []string     `json:"services"`
+	Objects                    []jsonObject `json:"objects"`
+}
+
+type jsonObject struct {
+	ID string `json:"id"`
+	//Properties are keyed by their standard name, with their values in
+	//the EPICS syntax
+	Properties map[string]string `json:"properties"`
+}
+
+// MarshalJSON encodes the document as a JSON document, whose property
+// values are written like in the EPICS text format
+func (d *Document) MarshalJSON() ([]byte, error) {
+	doc := jsonDocument{
+		Device:                     bacnet.FormatObjectID(d.VendorID, d.Device),
+		VendorID:                   d.VendorID,
+		VendorName:                 d.VendorName,
+		ModelName:                  d.ModelName,
+		Description:                d.Description,
+		FirmwareRevision:           d.FirmwareRevision,
+		ApplicationSoftwareVersion: d.ApplicationSoftwareVersion,
+		ProtocolRevision:           d.ProtocolRevision,
+		BIBBs:                      append([]string{}, d.BIBBs...),
+		ObjectTypes:                []string{},
+		Services:                   append([]string{}, d.Services...),
+		Objects:                    []jsonObject{},
+	}
+	for _, t := range d.ObjectTypes {
+		doc.ObjectTypes = append(doc.ObjectTypes, bacnet.ObjectTypeName(d.VendorID, t))
+	}
+	for _, o := range d.Objects {
+		object := jsonObject{ID: bacnet.FormatObjectID(d.VendorID, o.ID), Properties: map[string]string{}}
+		for p, v := range o.Properties {
+			object.Properties[PropertyName(p)] = d.formatValue(v)
+		}
+		doc.Objects = append(doc.Objects, object)
+	}
+	return json.Marshal(doc)
+}
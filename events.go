@@ -0,0 +1,55 @@
+package bacnet
+
+// EventStateValue is the state of an object regarding alarms and
+// events, the value of its EventState property
+type EventStateValue uint32
+
+//go:generate stringer -type=EventStateValue
+const (
+	EventStateNormal          EventStateValue = 0
+	EventStateFault           EventStateValue = 1
+	EventStateOffnormal       EventStateValue = 2
+	EventStateHighLimit       EventStateValue = 3
+	EventStateLowLimit        EventStateValue = 4
+	EventStateLifeSafetyAlarm EventStateValue = 5
+)
+
+// EventTypeValue is the algorithm used to generate an event
+// notification, the value of the EventType property
+type EventTypeValue uint32
+
+//go:generate stringer -type=EventTypeValue
+const (
+	EventTypeChangeOfBitstring       EventTypeValue = 0
+	EventTypeChangeOfState           EventTypeValue = 1
+	EventTypeChangeOfValue           EventTypeValue = 2
+	EventTypeCommandFailure          EventTypeValue = 3
+	EventTypeFloatingLimit           EventTypeValue = 4
+	EventTypeOutOfRange              EventTypeValue = 5
+	EventTypeComplexEventType        EventTypeValue = 6
+	EventTypeChangeOfLifeSafety      EventTypeValue = 8
+	EventTypeExtended                EventTypeValue = 9
+	EventTypeBufferReady             EventTypeValue = 10
+	EventTypeUnsignedRange           EventTypeValue = 11
+	EventTypeAccessEvent             EventTypeValue = 13
+	EventTypeDoubleOutOfRange        EventTypeValue = 14
+	EventTypeSignedOutOfRange        EventTypeValue = 15
+	EventTypeUnsignedOutOfRange      EventTypeValue = 16
+	EventTypeChangeOfCharacterstring EventTypeValue = 17
+	EventTypeChangeOfStatusFlags     EventTypeValue = 18
+	EventTypeChangeOfReliability     EventTypeValue = 19
+	EventTypeNone                    EventTypeValue = 20
+	EventTypeChangeOfDiscreteValue   EventTypeValue = 21
+	EventTypeChangeOfTimer           EventTypeValue = 22
+)
+
+// NotifyTypeValue tells if a notification is an alarm, an event or an
+// acknowledgment, the value of the NotifyType property
+type NotifyTypeValue uint32
+
+//go:generate stringer -type=NotifyTypeValue
+const (
+	NotifyTypeAlarm           NotifyTypeValue = 0
+	NotifyTypeEvent           NotifyTypeValue = 1
+	NotifyTypeAckNotification NotifyTypeValue = 2
+)
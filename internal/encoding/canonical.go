@@ -0,0 +1,140 @@
+package encoding
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// NonCanonicalError describes an encoding that is valid, but not the
+// one required by the standard, such as an unsigned with leading zero
+// bytes or a length written in the extended form while it fits the
+// tag
+type NonCanonicalError struct {
+	//Offset is the position of the tag of the faulty value
+	Offset int
+	Reason string
+}
+
+func (e NonCanonicalError) Error() string {
+	return fmt.Sprintf("offset %d: %s", e.Offset, e.Reason)
+}
+
+// tagLength returns the length of the canonical encoding of the tag
+func tagLength(t tag) int {
+	n := 1
+	if t.ID > 14 {
+		n++
+	}
+	switch {
+	case t.Opening || t.Closing || t.Value <= 4:
+	case t.Value <= 253:
+		n++
+	case t.Value <= 65535:
+		n += 3
+	default:
+		n += 5
+	}
+	return n
+}
+
+// Canonical checks that the data is a sequence of tagged values
+// encoded canonically: the tags are in their shortest form, the
+// opening and closing tags are balanced and the application values
+// have their standard length without redundant bytes. The content of
+// the context tagged values isn't checked, as their datatype isn't
+// known without the definition of the service
+func Canonical(data []byte) error {
+	buf := bytes.NewBuffer(data)
+	var opened []byte
+	for buf.Len() > 0 {
+		offset := len(data) - buf.Len()
+		length, t, err := decodeTag(buf)
+		if err != nil {
+			return fmt.Errorf("offset %d: %w", offset, err)
+		}
+		if length != tagLength(t) {
+			return NonCanonicalError{Offset: offset, Reason: "tag not in its shortest form"}
+		}
+		switch {
+		case t.Opening:
+			opened = append(opened, t.ID)
+			continue
+		case t.Closing:
+			if len(opened) == 0 || opened[len(opened)-1] != t.ID {
+				return NonCanonicalError{Offset: offset, Reason: fmt.Sprintf("unexpected closing tag %d", t.ID)}
+			}
+			opened = opened[:len(opened)-1]
+			continue
+		case !t.Context && t.ID == applicationTagBoolean:
+			if t.Value > 1 {
+				return NonCanonicalError{Offset: offset, Reason: fmt.Sprintf("boolean value %d", t.Value)}
+			}
+			continue
+		}
+		content, err := readN(buf, int(t.Value))
+		if err != nil {
+			return fmt.Errorf("offset %d: %w", offset, err)
+		}
+		if t.Context {
+			continue
+		}
+		if reason := canonicalContent(t.ID, content); reason != "" {
+			return NonCanonicalError{Offset: offset, Reason: reason}
+		}
+	}
+	if len(opened) > 0 {
+		return NonCanonicalError{Offset: len(data), Reason: fmt.Sprintf("tag %d not closed", opened[len(opened)-1])}
+	}
+	return nil
+}
+
+// canonicalContent returns why the content of an application tagged
+// value isn't canonical, or an empty string
+func canonicalContent(tagID byte, content []byte) string {
+	fixed := map[byte]int{
+		applicationTagNull:     0,
+		applicationTagReal:     4,
+		applicationTagDouble:   8,
+		applicationTagDate:     4,
+		applicationTagTime:     4,
+		applicationTagObjectID: 4,
+	}
+	if n, ok := fixed[tagID]; ok {
+		if len(content) != n {
+			return fmt.Sprintf("%s of length %d", tagName(tagID), len(content))
+		}
+		return ""
+	}
+	switch tagID {
+	case applicationTagUnsignedInt, applicationTagEnumerated:
+		if len(content) < size8 || len(content) > size32 {
+			return fmt.Sprintf("%s of length %d", tagName(tagID), len(content))
+		}
+		if len(content) > 1 && content[0] == 0 {
+			return fmt.Sprintf("%s with leading zero", tagName(tagID))
+		}
+	case applicationTagSignedInt:
+		if len(content) < size8 || len(content) > size32 {
+			return fmt.Sprintf("SignedInt of length %d", len(content))
+		}
+		if len(content) > 1 && ((content[0] == 0 && content[1]&0x80 == 0) || (content[0] == 0xFF && content[1]&0x80 != 0)) {
+			return "SignedInt with redundant sign byte"
+		}
+	case applicationTagCharacterString:
+		if len(content) == 0 {
+			return "CharacterString without character set"
+		}
+	case applicationTagBitString:
+		if len(content) == 0 {
+			return "BitString without unused bits count"
+		}
+		unused := content[0]
+		if unused > 7 || (len(content) == 1 && unused != 0) {
+			return fmt.Sprintf("BitString with %d unused bits", unused)
+		}
+		if len(content) > 1 && content[len(content)-1]&(1<<unused-1) != 0 {
+			return "BitString with unused bits set"
+		}
+	}
+	return ""
+}
@@ -0,0 +1,54 @@
+package encoding
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestCanonical(t *testing.T) {
+	ttc := []struct {
+		data      string //hex string
+		canonical bool
+	}{
+		{data: "", canonical: true},
+		{data: "2105", canonical: true},
+		{data: "220005", canonical: false},
+		{data: "2501050000", canonical: false},
+		{data: "3180", canonical: true},
+		{data: "32ff80", canonical: false},
+		{data: "320080", canonical: true},
+		{data: "3200ff", canonical: true},
+		{data: "32ffff", canonical: false},
+		{data: "11", canonical: true},
+		{data: "12", canonical: false},
+		{data: "4400000000", canonical: true},
+		{data: "4300000000", canonical: false},
+		{data: "8205a0", canonical: true},
+		{data: "8205a4", canonical: false},
+		{data: "8108", canonical: false},
+		{data: "0e2105", canonical: false},
+		{data: "0e21050f", canonical: true},
+		{data: "1f", canonical: false},
+		//Extended tag number below 15
+		{data: "f90105", canonical: false},
+		//Context tags aren't checked beyond their tag
+		{data: "1a0005", canonical: true},
+	}
+	for _, tc := range ttc {
+		t.Run(tc.data, func(t *testing.T) {
+			is := is.New(t)
+			b, err := hex.DecodeString(tc.data)
+			is.NoErr(err)
+			err = Canonical(b)
+			if tc.canonical {
+				is.NoErr(err)
+				return
+			}
+			var e NonCanonicalError
+			is.True(errors.As(err, &e))
+		})
+	}
+}
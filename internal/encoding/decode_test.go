@@ -109,3 +109,66 @@ func TestValidAppData(t *testing.T) {
 		})
 	}
 }
+
+func TestAppDataCoherency(t *testing.T) {
+	ttc := []struct {
+		data     string //hex string
+		expected interface{}
+	}{
+		{data: "11", expected: true},
+		{data: "10", expected: false},
+		{data: "31f6", expected: int32(-10)},
+		{data: "3301e240", expected: int32(123456)},
+		{data: "55083ff0000000000000", expected: float64(1)},
+		{data: "6303abcd", expected: []byte{0x03, 0xab, 0xcd}},
+		{data: "8205a0", expected: bacnet.BitString{true, false, true}},
+		{data: "a47a0a0e05", expected: bacnet.Date{Year: 2022, Month: 10, Day: 14, Weekday: 5}},
+		{data: "a4ffff0bff", expected: bacnet.Date{Year: bacnet.UnspecifiedYear, Month: bacnet.Unspecified, Day: 11, Weekday: bacnet.Unspecified}},
+		{data: "a4ff0d21ff", expected: bacnet.Date{Year: bacnet.UnspecifiedYear, Month: bacnet.OddMonths, Day: bacnet.OddDays, Weekday: bacnet.Unspecified}},
+		{data: "a47b0e2003", expected: bacnet.Date{Year: 2023, Month: bacnet.EvenMonths, Day: bacnet.LastDayOfMonth, Weekday: 3}},
+		{data: "b40c1e0000", expected: bacnet.Time{Hour: 12, Minute: 30}},
+		{data: "b408ffffff", expected: bacnet.Time{Hour: 8, Minute: bacnet.Unspecified, Second: bacnet.Unspecified, Hundredths: bacnet.Unspecified}},
+		{data: "24ff000000", expected: uint32(0xff000000)},
+	}
+	for _, tc := range ttc {
+		t.Run(fmt.Sprintf("AppData %s (%T)", tc.data, tc.expected), func(t *testing.T) {
+			is := is.New(t)
+			b, err := hex.DecodeString(tc.data)
+			is.NoErr(err)
+			var v interface{}
+			decoder := NewDecoder(b)
+			decoder.AppData(&v)
+			is.NoErr(decoder.Error())
+			is.Equal(v, tc.expected)
+			enc := NewEncoder()
+			enc.AppData(tc.expected)
+			is.NoErr(enc.Error())
+			is.Equal(hex.EncodeToString(enc.Bytes()), tc.data)
+		})
+	}
+}
+
+func TestUnspecifiedYear(t *testing.T) {
+	is := is.New(t)
+	enc := NewEncoder()
+	enc.AppData(bacnet.Date{Year: bacnet.Unspecified, Month: 12, Day: 25, Weekday: bacnet.Unspecified})
+	is.NoErr(enc.Error())
+	is.Equal(hex.EncodeToString(enc.Bytes()), "a4ff0c19ff")
+}
+
+func TestContextRaw(t *testing.T) {
+	is := is.New(t)
+	//[5] { [0] { real } bool } [6] 7
+	b, err := hex.DecodeString("5e0e44424800000f115f6907")
+	is.NoErr(err)
+	d := NewDecoder(b)
+	var raw []byte
+	d.ContextRaw(5, &raw)
+	is.NoErr(d.Error())
+	is.Equal(hex.EncodeToString(raw), "0e44424800000f11")
+	var v uint32
+	d.ContextValue(6, &v)
+	is.NoErr(d.Error())
+	is.Equal(v, uint32(7))
+	is.Equal(d.Len(), 0)
+}
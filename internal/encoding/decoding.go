@@ -138,103 +138,205 @@ func (d *Decoder) AppData(v interface{}) {
 		d.err = errors.New("decode AppData: unexpected context tag ")
 		return
 	}
+	var val interface{}
+	if tag.ID == applicationTagBoolean {
+		//Application booleans are encoded in the tag itself
+		val = tag.Value != 0
+	} else {
+		val, err = decodePrimitive(d.buf, tag.ID, tag.Value)
+		if err != nil {
+			d.err = fmt.Errorf("decodeAppData: %w", err)
+			return
+		}
+	}
 	//Take the pointer value
-	rv = rv.Elem()
-	switch tag.ID {
+	d.err = assign(rv.Elem(), val, tag.ID)
+}
+
+// assign sets rv to val, converting it if rv is a named type of the
+// same kind (ex: an enumeration)
+func assign(rv reflect.Value, val interface{}, tagID byte) error {
+	if val == nil {
+		//Null, nothing to do
+		return nil
+	}
+	if isEmptyInterface(rv) || reflect.TypeOf(val) == rv.Type() {
+		rv.Set(reflect.ValueOf(val))
+		return nil
+	}
+	switch v := val.(type) {
+	case uint32:
+		switch rv.Kind() {
+		case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			rv.SetUint(uint64(v))
+			return nil
+		}
+	case int32:
+		switch rv.Kind() {
+		case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			rv.SetInt(int64(v))
+			return nil
+		}
+	case bool:
+		if rv.Kind() == reflect.Bool {
+			rv.SetBool(v)
+			return nil
+		}
+	}
+	return AppDataTypeMismatch{wanted: tagName(tagID), got: rv.Type()}
+}
+
+func tagName(tagID byte) string {
+	switch tagID {
 	case applicationTagNull:
-		//nothing to do
+		return "Null"
+	case applicationTagBoolean:
+		return "Boolean"
 	case applicationTagUnsignedInt:
-		val, err := decodeUnsignedWithLen(d.buf, int(tag.Value))
+		return "UnsignedInt"
+	case applicationTagSignedInt:
+		return "SignedInt"
+	case applicationTagReal:
+		return "Real"
+	case applicationTagDouble:
+		return "Double"
+	case applicationTagOctetString:
+		return "OctetString"
+	case applicationTagCharacterString:
+		return "CharacterString"
+	case applicationTagBitString:
+		return "BitString"
+	case applicationTagEnumerated:
+		return "Enumerated"
+	case applicationTagDate:
+		return "Date"
+	case applicationTagTime:
+		return "Time"
+	case applicationTagObjectID:
+		return "ObjectID"
+	default:
+		return fmt.Sprintf("tag 0x%x", tagID)
+	}
+}
+
+// decodePrimitive decodes the content of a primitive value of the
+// given application type. length is the size of the content in bytes
+func decodePrimitive(buf *bytes.Buffer, tagID byte, length uint32) (interface{}, error) {
+	switch tagID {
+	case applicationTagNull:
+		return nil, nil
+	case applicationTagBoolean:
+		//Only reached for context tagged booleans, which have a
+		//content byte
+		v, err := decodeUnsignedWithLen(buf, int(length))
 		if err != nil {
-			d.err = fmt.Errorf("decodeAppData: read ObjectID: %w", err)
-			return
+			return nil, fmt.Errorf("read boolean: %w", err)
 		}
-		if rv.Kind() != reflect.Uint8 && rv.Kind() != reflect.Uint16 && rv.Kind() != reflect.Uint32 && !isEmptyInterface(rv) {
-			d.err = AppDataTypeMismatch{wanted: "UnsignedInt", got: rv.Type()}
-			return
+		return v != 0, nil
+	case applicationTagUnsignedInt, applicationTagEnumerated:
+		v, err := decodeUnsignedWithLen(buf, int(length))
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", tagName(tagID), err)
 		}
-		rv.Set(reflect.ValueOf(val))
+		return v, nil
+	case applicationTagSignedInt:
+		v, err := decodeSignedWithLen(buf, int(length))
+		if err != nil {
+			return nil, fmt.Errorf("read SignedInt: %w", err)
+		}
+		return v, nil
 	case applicationTagReal:
 		var f float32
-		err := binary.Read(d.buf, binary.BigEndian, &f)
+		err := binary.Read(buf, binary.BigEndian, &f)
 		if err != nil {
-			d.err = fmt.Errorf("decode AppData: read float32: %w", err)
-			return
+			return nil, fmt.Errorf("read float32: %w", err)
 		}
-		if rv.Kind() != reflect.Float32 && !isEmptyInterface(rv) {
-			d.err = AppDataTypeMismatch{wanted: "Real", got: rv.Type()}
-			return
+		return f, nil
+	case applicationTagDouble:
+		var f float64
+		err := binary.Read(buf, binary.BigEndian, &f)
+		if err != nil {
+			return nil, fmt.Errorf("read float64: %w", err)
 		}
-		rv.Set(reflect.ValueOf(f))
-	case applicationTagCharacterString:
-		sEncoding, err := d.buf.ReadByte()
+		return f, nil
+	case applicationTagOctetString:
+		b, err := readN(buf, int(length))
 		if err != nil {
-			d.err = fmt.Errorf("decode appData: read string encoding: %w", err)
-			return
+			return nil, fmt.Errorf("read octet string: %w", err)
 		}
-		if sEncoding != utf8Encoding {
-			d.err = fmt.Errorf("unsuported strign encoding: 0x%x", sEncoding)
-			return
+		return b, nil
+	case applicationTagCharacterString:
+		if length == 0 {
+			return nil, errors.New("read string: missing encoding")
 		}
-		b := make([]byte, int(tag.Value)-1) //Minus one because encoding is already consumed
-		n, err := d.buf.Read(b)
+		sEncoding, err := buf.ReadByte()
 		if err != nil {
-			d.err = fmt.Errorf("decode appdata: read string: %w", err)
-			return
-		}
-		if n != len(b) {
-			d.err = fmt.Errorf("decode string: stort read %d instead of %d", n, len(b))
-			return
+			return nil, fmt.Errorf("read string encoding: %w", err)
 		}
-		s := string(b) //Conversion allowed because string are utf8 only in go
-		if rv.Type() != reflect.TypeOf(s) && !isEmptyInterface(rv) {
-			d.err = AppDataTypeMismatch{wanted: "CharacterString", got: rv.Type()}
-			return
+		if sEncoding != utf8Encoding {
+			return nil, fmt.Errorf("unsuported strign encoding: 0x%x", sEncoding)
 		}
-		rv.Set(reflect.ValueOf(s))
-	case applicationTagEnumerated:
-		val, err := decodeUnsignedWithLen(d.buf, int(tag.Value))
+		b, err := readN(buf, int(length)-1) //Minus one because encoding is already consumed
 		if err != nil {
-			d.err = fmt.Errorf("decodeAppData: read ObjectID: %w", err)
-			return
+			return nil, fmt.Errorf("read string: %w", err)
 		}
-		switch rv.Type() {
-		case reflect.TypeOf(bacnet.SegmentationSupport(0)):
-			rv.Set(reflect.ValueOf(bacnet.SegmentationSupport(val)))
-		case reflect.TypeOf(bacnet.ErrorClass(0)):
-			rv.Set(reflect.ValueOf(bacnet.ErrorClass(val)))
-		case reflect.TypeOf(bacnet.ErrorCode(0)):
-			rv.Set(reflect.ValueOf(bacnet.ErrorCode(val)))
-		default:
-			if isEmptyInterface(rv) {
-				rv.Set(reflect.ValueOf(val))
-			} else {
-				d.err = AppDataTypeMismatch{wanted: "Enumerated", got: rv.Type()}
-				return
-			}
+		return string(b), nil //Conversion allowed because string are utf8 only in go
+	case applicationTagBitString:
+		return decodeBitString(buf, int(length))
+	case applicationTagDate:
+		b, err := readN(buf, int(length))
+		if err != nil || len(b) != 4 {
+			return nil, fmt.Errorf("read date: %v", err)
+		}
+		return bacnet.Date{Year: 1900 + int(b[0]), Month: int(b[1]), Day: int(b[2]), Weekday: int(b[3])}, nil
+	case applicationTagTime:
+		b, err := readN(buf, int(length))
+		if err != nil || len(b) != 4 {
+			return nil, fmt.Errorf("read time: %v", err)
 		}
+		return bacnet.Time{Hour: int(b[0]), Minute: int(b[1]), Second: int(b[2]), Hundredths: int(b[3])}, nil
 	case applicationTagObjectID:
-		var obj bacnet.ObjectID
 		var val uint32
-		err := binary.Read(d.buf, binary.BigEndian, &val)
+		err := binary.Read(buf, binary.BigEndian, &val)
 		if err != nil {
-			d.err = fmt.Errorf("decodeAppData: read ObjectID: %w", err)
-			return
-		}
-		obj = bacnet.ObjectIDFromUint32(val)
-		if rv.Type() != reflect.TypeOf(obj) && !isEmptyInterface(rv) {
-			d.err = AppDataTypeMismatch{wanted: "ObjectID", got: rv.Type()}
-			return
+			return nil, fmt.Errorf("read ObjectID: %w", err)
 		}
-		rv.Set(reflect.ValueOf(obj))
-
+		return bacnet.ObjectIDFromUint32(val), nil
 	default:
 		//TODO: support all app data types
-		d.err = fmt.Errorf("decodeAppData: unsupported type 0x%x", tag.ID)
-		return
+		return nil, fmt.Errorf("unsupported type 0x%x", tagID)
 	}
 }
 
+func decodeBitString(buf *bytes.Buffer, length int) (bacnet.BitString, error) {
+	b, err := readN(buf, length)
+	if err != nil {
+		return nil, fmt.Errorf("read bitstring: %w", err)
+	}
+	if len(b) == 0 {
+		return nil, errors.New("read bitstring: missing unused bits count")
+	}
+	unused := int(b[0])
+	n := (len(b)-1)*8 - unused
+	if unused > 7 || n < 0 {
+		return nil, fmt.Errorf("read bitstring: invalid unused bits count %d", unused)
+	}
+	bs := make(bacnet.BitString, n)
+	for i := range bs {
+		bs[i] = b[1+i/8]&(0x80>>(i%8)) != 0
+	}
+	return bs, nil
+}
+
+func readN(buf *bytes.Buffer, n int) ([]byte, error) {
+	if n > buf.Len() {
+		return nil, fmt.Errorf("short read: %d bytes available, %d wanted", buf.Len(), n)
+	}
+	b := make([]byte, n)
+	_, err := buf.Read(b)
+	return b, err
+}
+
 func isEmptyInterface(rv reflect.Value) bool {
 	return rv.Kind() == reflect.Interface && rv.Type().NumMethod() == 0
 }
@@ -330,3 +432,272 @@ func decodeUnsignedWithLen(buf *bytes.Buffer, length int) (uint32, error) {
 		return 0, nil
 	}
 }
+
+func decodeSignedWithLen(buf *bytes.Buffer, length int) (int32, error) {
+	if length < size8 || length > size32 {
+		return 0, fmt.Errorf("invalid signed length %d", length)
+	}
+	b, err := readN(buf, length)
+	if err != nil {
+		return 0, fmt.Errorf("read signed with length %d: %w", length, err)
+	}
+	//Sign extension of the first byte
+	v := int32(int8(b[0]))
+	for _, x := range b[1:] {
+		v = v<<8 | int32(x)
+	}
+	return v, nil
+}
+
+// Len returns the number of bytes not yet decoded
+func (d *Decoder) Len() int {
+	return d.buf.Len()
+}
+
+// peekTag decodes the next tag without consuming it
+func (d *Decoder) peekTag() (tag, error) {
+	_, t, err := decodeTag(bytes.NewBuffer(d.buf.Bytes()))
+	return t, err
+}
+
+// IsOpeningTag returns true if the next tag is the opening tag of the
+// given number. It doesn't consume the tag
+func (d *Decoder) IsOpeningTag(tagID byte) bool {
+	if d.err != nil {
+		return false
+	}
+	t, err := d.peekTag()
+	return err == nil && t.Opening && t.ID == tagID
+}
+
+// IsClosingTag returns true if the next tag is the closing tag of the
+// given number. It doesn't consume the tag
+func (d *Decoder) IsClosingTag(tagID byte) bool {
+	if d.err != nil {
+		return false
+	}
+	t, err := d.peekTag()
+	return err == nil && t.Closing && t.ID == tagID
+}
+
+// IsContextTag returns true if the next tag is a primitive context tag
+// of the given number. It doesn't consume the tag
+func (d *Decoder) IsContextTag(tagID byte) bool {
+	if d.err != nil {
+		return false
+	}
+	t, err := d.peekTag()
+	return err == nil && t.Context && !t.Opening && !t.Closing && t.ID == tagID
+}
+
+// ContextTagNumber returns the number of the next tag if it's a
+// primitive context tag, as in the choices. It doesn't consume the tag
+func (d *Decoder) ContextTagNumber() (byte, bool) {
+	if d.err != nil {
+		return 0, false
+	}
+	t, err := d.peekTag()
+	if err != nil || !t.Context || t.Opening || t.Closing {
+		return 0, false
+	}
+	return t.ID, true
+}
+
+// OpeningTag consumes the opening tag of the given number.
+// If ErrorIncorrectTag is set, the internal buffer cursor is ready to read again the same tag.
+func (d *Decoder) OpeningTag(tagID byte) {
+	d.constructedTag(tagID, true)
+}
+
+// ClosingTag consumes the closing tag of the given number.
+// If ErrorIncorrectTag is set, the internal buffer cursor is ready to read again the same tag.
+func (d *Decoder) ClosingTag(tagID byte) {
+	d.constructedTag(tagID, false)
+}
+
+func (d *Decoder) constructedTag(tagID byte, opening bool) {
+	if d.err != nil {
+		return
+	}
+	t, err := d.peekTag()
+	if err != nil {
+		d.err = err
+		return
+	}
+	if t.ID != tagID || !t.Context || t.Opening != opening || t.Closing == opening {
+		d.err = ErrorIncorrectTagID{Expected: tagID, Got: t.ID}
+		return
+	}
+	_, _, _ = decodeTag(d.buf)
+}
+
+// contextTag consumes the next primitive context tag and returns the
+// length of its content. If the tag number isn't the expected one,
+// ErrorIncorrectTag is set and the tag isn't consumed
+func (d *Decoder) contextTag(expectedTagID byte) (uint32, bool) {
+	if d.err != nil {
+		return 0, false
+	}
+	t, err := d.peekTag()
+	if err != nil {
+		d.err = err
+		return 0, false
+	}
+	if t.ID != expectedTagID || t.Opening || t.Closing {
+		d.err = ErrorIncorrectTagID{Expected: expectedTagID, Got: t.ID}
+		return 0, false
+	}
+	if !t.Context {
+		d.err = errors.New("tag isn't contextual")
+		return 0, false
+	}
+	_, _, _ = decodeTag(d.buf)
+	return t.Value, true
+}
+
+// contextPrimitive decodes the content of a context tag as the given
+// application type and stores it in v
+func (d *Decoder) contextPrimitive(expectedTagID byte, appTag byte, v interface{}) {
+	length, ok := d.contextTag(expectedTagID)
+	if !ok {
+		return
+	}
+	val, err := decodePrimitive(d.buf, appTag, length)
+	if err != nil {
+		d.err = fmt.Errorf("decode context tag %d: %w", expectedTagID, err)
+		return
+	}
+	d.err = assign(reflect.ValueOf(v).Elem(), val, appTag)
+}
+
+// ContextBool reads a context tagged boolean.
+// If ErrorIncorrectTag is set, the internal buffer cursor is ready to read again the same tag.
+func (d *Decoder) ContextBool(expectedTagID byte, v *bool) {
+	d.contextPrimitive(expectedTagID, applicationTagBoolean, v)
+}
+
+// ContextSigned reads a context tagged signed integer.
+// If ErrorIncorrectTag is set, the internal buffer cursor is ready to read again the same tag.
+func (d *Decoder) ContextSigned(expectedTagID byte, v *int32) {
+	d.contextPrimitive(expectedTagID, applicationTagSignedInt, v)
+}
+
+// ContextReal reads a context tagged real.
+// If ErrorIncorrectTag is set, the internal buffer cursor is ready to read again the same tag.
+func (d *Decoder) ContextReal(expectedTagID byte, v *float32) {
+	d.contextPrimitive(expectedTagID, applicationTagReal, v)
+}
+
+// ContextDouble reads a context tagged double.
+// If ErrorIncorrectTag is set, the internal buffer cursor is ready to read again the same tag.
+func (d *Decoder) ContextDouble(expectedTagID byte, v *float64) {
+	d.contextPrimitive(expectedTagID, applicationTagDouble, v)
+}
+
+// ContextString reads a context tagged character string.
+// If ErrorIncorrectTag is set, the internal buffer cursor is ready to read again the same tag.
+func (d *Decoder) ContextString(expectedTagID byte, v *string) {
+	d.contextPrimitive(expectedTagID, applicationTagCharacterString, v)
+}
+
+// ContextOctetString reads a context tagged octet string.
+// If ErrorIncorrectTag is set, the internal buffer cursor is ready to read again the same tag.
+func (d *Decoder) ContextOctetString(expectedTagID byte, v *[]byte) {
+	d.contextPrimitive(expectedTagID, applicationTagOctetString, v)
+}
+
+// ContextBitString reads a context tagged bitstring.
+// If ErrorIncorrectTag is set, the internal buffer cursor is ready to read again the same tag.
+func (d *Decoder) ContextBitString(expectedTagID byte, v *bacnet.BitString) {
+	d.contextPrimitive(expectedTagID, applicationTagBitString, v)
+}
+
+// ContextDate reads a context tagged date.
+// If ErrorIncorrectTag is set, the internal buffer cursor is ready to read again the same tag.
+func (d *Decoder) ContextDate(expectedTagID byte, v *bacnet.Date) {
+	d.contextPrimitive(expectedTagID, applicationTagDate, v)
+}
+
+// ContextTime reads a context tagged time.
+// If ErrorIncorrectTag is set, the internal buffer cursor is ready to read again the same tag.
+func (d *Decoder) ContextTime(expectedTagID byte, v *bacnet.Time) {
+	d.contextPrimitive(expectedTagID, applicationTagTime, v)
+}
+
+// ContextNull reads a context tagged null.
+// If ErrorIncorrectTag is set, the internal buffer cursor is ready to read again the same tag.
+func (d *Decoder) ContextNull(expectedTagID byte) {
+	length, ok := d.contextTag(expectedTagID)
+	if ok && length != 0 {
+		d.err = fmt.Errorf("decode context tag %d: null of length %d", expectedTagID, length)
+	}
+}
+
+// ContextRaw reads the opening tag of the given number and returns
+// all bytes up to the matching closing tag, which are consumed.
+// If ErrorIncorrectTag is set, the internal buffer cursor is ready to read again the same tag.
+func (d *Decoder) ContextRaw(expectedTagID byte, v *[]byte) {
+	d.OpeningTag(expectedTagID)
+	if d.err != nil {
+		return
+	}
+	data := d.buf.Bytes()
+	n, err := constructedLen(data, expectedTagID)
+	if err != nil {
+		d.err = fmt.Errorf("decode constructed tag %d: %w", expectedTagID, err)
+		return
+	}
+	*v = make([]byte, n)
+	copy(*v, data[:n])
+	d.buf.Next(n)
+	d.ClosingTag(expectedTagID)
+}
+
+// constructedLen returns the number of bytes before the closing tag
+// matching an opening tag that has already been consumed
+func constructedLen(data []byte, tagID byte) (int, error) {
+	buf := bytes.NewBuffer(data)
+	depth := 0
+	for {
+		start := len(data) - buf.Len()
+		_, t, err := decodeTag(buf)
+		if err != nil {
+			return 0, err
+		}
+		switch {
+		case t.Opening:
+			depth++
+		case t.Closing:
+			if depth == 0 {
+				if t.ID != tagID {
+					return 0, ErrorIncorrectTagID{Expected: tagID, Got: t.ID}
+				}
+				return start, nil
+			}
+			depth--
+		case !t.Context && t.ID == applicationTagBoolean:
+			//No content, value is in the tag
+		default:
+			if int(t.Value) > buf.Len() {
+				return 0, fmt.Errorf("tag length %d exceeds remaining data", t.Value)
+			}
+			buf.Next(int(t.Value))
+		}
+	}
+}
+
+// AppValue reads the next application tag and value, and keeps the
+// tag in pv.Type so the value can be encoded again identically
+func (d *Decoder) AppValue(pv *bacnet.PropertyValue) {
+	if d.err != nil {
+		return
+	}
+	t, err := d.peekTag()
+	if err != nil {
+		d.err = fmt.Errorf("decodeAppValue: read tag: %w", err)
+		return
+	}
+	pv.Type = t.ID
+	pv.Value = nil
+	d.AppData(&pv.Value)
+}
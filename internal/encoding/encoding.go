@@ -2,7 +2,9 @@ package encoding
 
 import (
 	"bytes"
+	stdencoding "encoding"
 	"encoding/binary"
+
 	"github.com/REQUEA/bacnet"
 )
 
@@ -91,14 +93,167 @@ func (e *Encoder) AppData(v any) {
 			return
 		}
 		_ = binary.Write(e.buf, binary.BigEndian, v)
+	case bool:
+		writeValue(e.buf, bacnet.PropertyValue{Type: applicationTagBoolean, Value: val})
 	default:
 		writeValue(e.buf, bacnet.PropertyValue{Value: v})
 	}
 }
 
+// AppValue writes the value with the application tag pv.Type, or a
+// tag matching its go type if pv.Type is 0
+func (e *Encoder) AppValue(pv bacnet.PropertyValue) {
+	if e.err != nil {
+		return
+	}
+	e.err = checkValue(pv.Value)
+	if e.err != nil {
+		return
+	}
+	writeValue(e.buf, pv)
+}
+
+// checkValue returns the errors that writeValue can't report
+func checkValue(v any) error {
+	if id, ok := v.(bacnet.ObjectID); ok {
+		_, err := id.Encode()
+		return err
+	}
+	return nil
+}
+
+// OpeningTag writes the opening tag of a constructed value
+func (e *Encoder) OpeningTag(tagNumber byte) {
+	if e.err != nil {
+		return
+	}
+	encodeTag(e.buf, tag{ID: tagNumber, Context: true, Opening: true})
+}
+
+// ClosingTag writes the closing tag of a constructed value
+func (e *Encoder) ClosingTag(tagNumber byte) {
+	if e.err != nil {
+		return
+	}
+	encodeTag(e.buf, tag{ID: tagNumber, Context: true, Closing: true})
+}
+
+// ContextRaw writes already encoded data between an opening and a
+// closing tag
+func (e *Encoder) ContextRaw(tagNumber byte, data []byte) {
+	if e.err != nil {
+		return
+	}
+	e.OpeningTag(tagNumber)
+	e.buf.Write(data)
+	e.ClosingTag(tagNumber)
+}
+
+// contextValue writes a context tag with the value v, encoded as the
+// application type appTag
+func (e *Encoder) contextValue(tagNumber byte, appTag byte, v any) {
+	if e.err != nil {
+		return
+	}
+	tmp := &bytes.Buffer{}
+	writeValue(tmp, bacnet.PropertyValue{Type: appTag, Value: v})
+	//Re-use the application encoding but replace the tag by the
+	//context one
+	_, t, err := decodeTag(tmp)
+	if err != nil {
+		e.err = err
+		return
+	}
+	if appTag == applicationTagBoolean {
+		//Context booleans have a content byte
+		t.Value = 1
+		tmp = bytes.NewBuffer([]byte{0})
+		if v.(bool) {
+			tmp = bytes.NewBuffer([]byte{1})
+		}
+	}
+	t.ID = tagNumber
+	t.Context = true
+	encodeTag(e.buf, t)
+	e.buf.Write(tmp.Bytes())
+}
+
+// ContextBool writes a context tagged boolean
+func (e *Encoder) ContextBool(tagNumber byte, v bool) {
+	e.contextValue(tagNumber, applicationTagBoolean, v)
+}
+
+// ContextSigned writes a context tagged signed integer
+func (e *Encoder) ContextSigned(tagNumber byte, v int32) {
+	e.contextValue(tagNumber, applicationTagSignedInt, v)
+}
+
+// ContextReal writes a context tagged real
+func (e *Encoder) ContextReal(tagNumber byte, v float32) {
+	e.contextValue(tagNumber, applicationTagReal, v)
+}
+
+// ContextDouble writes a context tagged double
+func (e *Encoder) ContextDouble(tagNumber byte, v float64) {
+	e.contextValue(tagNumber, applicationTagDouble, v)
+}
+
+// ContextString writes a context tagged character string
+func (e *Encoder) ContextString(tagNumber byte, v string) {
+	e.contextValue(tagNumber, applicationTagCharacterString, v)
+}
+
+// ContextOctetString writes a context tagged octet string
+func (e *Encoder) ContextOctetString(tagNumber byte, v []byte) {
+	e.contextValue(tagNumber, applicationTagOctetString, v)
+}
+
+// ContextBitString writes a context tagged bitstring
+func (e *Encoder) ContextBitString(tagNumber byte, v bacnet.BitString) {
+	e.contextValue(tagNumber, applicationTagBitString, v)
+}
+
+// ContextDate writes a context tagged date
+func (e *Encoder) ContextDate(tagNumber byte, v bacnet.Date) {
+	e.contextValue(tagNumber, applicationTagDate, v)
+}
+
+// ContextNull writes a context tagged null, which has no content
+func (e *Encoder) ContextNull(tagNumber byte) {
+	if e.err != nil {
+		return
+	}
+	encodeTag(e.buf, tag{ID: tagNumber, Context: true})
+}
+
+// ContextTime writes a context tagged time
+func (e *Encoder) ContextTime(tagNumber byte, v bacnet.Time) {
+	e.contextValue(tagNumber, applicationTagTime, v)
+}
+
+// ContextAbstractType writes the value between an opening and a
+// closing tag. If the value implements encoding.BinaryMarshaler, it
+// is considered already encoded, which allows to write constructed
+// values
 func (e *Encoder) ContextAbstractType(tabNumber byte, v bacnet.PropertyValue) {
+	if e.err != nil {
+		return
+	}
 	encodeTag(e.buf, tag{ID: tabNumber, Context: true, Opening: true})
-	writeValue(e.buf, v)
+	if m, ok := v.Value.(stdencoding.BinaryMarshaler); ok {
+		b, err := m.MarshalBinary()
+		if err != nil {
+			e.err = err
+			return
+		}
+		e.buf.Write(b)
+	} else {
+		e.err = checkValue(v.Value)
+		if e.err != nil {
+			return
+		}
+		writeValue(e.buf, v)
+	}
 	encodeTag(e.buf, tag{ID: tabNumber, Context: true, Closing: true})
 }
 
@@ -132,6 +287,16 @@ func writeValue(buf *bytes.Buffer, pv bacnet.PropertyValue) {
 				writeUint(buf, t, 0)
 			}
 		}
+	case int:
+		v := value.(int)
+		if (pv.Type == applicationTagUnsignedInt || pv.Type == applicationTagEnumerated) && v >= 0 {
+			writeUint(buf, t, uint32(v))
+			return
+		}
+		if pv.Type == 0 {
+			t.ID = applicationTagSignedInt
+		}
+		writeInt(buf, t, int32(v))
 	case uint8:
 		if pv.Type == 0 {
 			t.ID = applicationTagUnsignedInt
@@ -183,9 +348,74 @@ func writeValue(buf *bytes.Buffer, pv bacnet.PropertyValue) {
 		encodeTag(buf, t)
 		_ = buf.WriteByte(utf8Encoding)
 		_, _ = buf.Write([]byte(v))
+	case []byte:
+		v := value.([]byte)
+		if pv.Type == 0 {
+			t.ID = applicationTagOctetString
+		}
+		t.Value = uint32(len(v))
+		encodeTag(buf, t)
+		_, _ = buf.Write(v)
+	case bacnet.BitString:
+		if pv.Type == 0 {
+			t.ID = applicationTagBitString
+		}
+		writeBitString(buf, t, value.(bacnet.BitString))
+	case bacnet.ObjectID:
+		if pv.Type == 0 {
+			t.ID = applicationTagObjectID
+		}
+		v, err := value.(bacnet.ObjectID).Encode()
+		if err != nil {
+			//Already checked by checkValue
+			return
+		}
+		t.Value = 4
+		encodeTag(buf, t)
+		_ = binary.Write(buf, binary.BigEndian, v)
+	case bacnet.Date:
+		if pv.Type == 0 {
+			t.ID = applicationTagDate
+		}
+		writeDate(buf, t, value.(bacnet.Date))
+	case bacnet.Time:
+		if pv.Type == 0 {
+			t.ID = applicationTagTime
+		}
+		writeTime(buf, t, value.(bacnet.Time))
 	}
 }
 
+func writeBitString(buf *bytes.Buffer, t tag, bs bacnet.BitString) {
+	n := (len(bs) + 7) / 8
+	t.Value = uint32(n + 1)
+	encodeTag(buf, t)
+	buf.WriteByte(byte(n*8 - len(bs))) //unused bits in the last byte
+	b := make([]byte, n)
+	for i, bit := range bs {
+		if bit {
+			b[i/8] |= 0x80 >> (i % 8)
+		}
+	}
+	buf.Write(b)
+}
+
+func writeDate(buf *bytes.Buffer, t tag, d bacnet.Date) {
+	t.Value = 4
+	encodeTag(buf, t)
+	year := byte(d.Year - 1900)
+	if d.Year == bacnet.Unspecified {
+		year = bacnet.Unspecified
+	}
+	buf.Write([]byte{year, byte(d.Month), byte(d.Day), byte(d.Weekday)})
+}
+
+func writeTime(buf *bytes.Buffer, t tag, v bacnet.Time) {
+	t.Value = 4
+	encodeTag(buf, t)
+	buf.Write([]byte{byte(v.Hour), byte(v.Minute), byte(v.Second), byte(v.Hundredths)})
+}
+
 func writeUint(buf *bytes.Buffer, t tag, value uint32) {
 	switch {
 	case value < 0x100:
@@ -205,6 +435,7 @@ func writeUint(buf *bytes.Buffer, t tag, value uint32) {
 		_ = binary.Write(buf, binary.BigEndian, uint16(value))
 	default:
 		t.Value = 4
+		encodeTag(buf, t)
 		_ = binary.Write(buf, binary.BigEndian, value)
 	}
 }
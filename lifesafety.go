@@ -0,0 +1,72 @@
+package bacnet
+
+// LifeSafetyState is the state of a Life Safety Point or Zone object
+type LifeSafetyState uint32
+
+//go:generate stringer -type=LifeSafetyState
+const (
+	LifeSafetyStateQuiet           LifeSafetyState = 0
+	LifeSafetyStatePreAlarm        LifeSafetyState = 1
+	LifeSafetyStateAlarm           LifeSafetyState = 2
+	LifeSafetyStateFault           LifeSafetyState = 3
+	LifeSafetyStateFaultPreAlarm   LifeSafetyState = 4
+	LifeSafetyStateFaultAlarm      LifeSafetyState = 5
+	LifeSafetyStateNotReady        LifeSafetyState = 6
+	LifeSafetyStateActive          LifeSafetyState = 7
+	LifeSafetyStateTamper          LifeSafetyState = 8
+	LifeSafetyStateTestAlarm       LifeSafetyState = 9
+	LifeSafetyStateTestActive      LifeSafetyState = 10
+	LifeSafetyStateTestFault       LifeSafetyState = 11
+	LifeSafetyStateTestFaultAlarm  LifeSafetyState = 12
+	LifeSafetyStateHoldup          LifeSafetyState = 13
+	LifeSafetyStateDuress          LifeSafetyState = 14
+	LifeSafetyStateTamperAlarm     LifeSafetyState = 15
+	LifeSafetyStateAbnormal        LifeSafetyState = 16
+	LifeSafetyStateEmergencyPower  LifeSafetyState = 17
+	LifeSafetyStateDelayed         LifeSafetyState = 18
+	LifeSafetyStateBlocked         LifeSafetyState = 19
+	LifeSafetyStateLocalAlarm      LifeSafetyState = 20
+	LifeSafetyStateGeneralAlarm    LifeSafetyState = 21
+	LifeSafetyStateSupervisory     LifeSafetyState = 22
+	LifeSafetyStateTestSupervisory LifeSafetyState = 23
+)
+
+// LifeSafetyMode is the operating mode of a Life Safety Point or Zone object
+type LifeSafetyMode uint32
+
+//go:generate stringer -type=LifeSafetyMode
+const (
+	LifeSafetyModeOff                      LifeSafetyMode = 0
+	LifeSafetyModeOn                       LifeSafetyMode = 1
+	LifeSafetyModeTest                     LifeSafetyMode = 2
+	LifeSafetyModeManned                   LifeSafetyMode = 3
+	LifeSafetyModeUnmanned                 LifeSafetyMode = 4
+	LifeSafetyModeArmed                    LifeSafetyMode = 5
+	LifeSafetyModeDisarmed                 LifeSafetyMode = 6
+	LifeSafetyModePrearmed                 LifeSafetyMode = 7
+	LifeSafetyModeSlow                     LifeSafetyMode = 8
+	LifeSafetyModeFast                     LifeSafetyMode = 9
+	LifeSafetyModeDisconnected             LifeSafetyMode = 10
+	LifeSafetyModeEnabled                  LifeSafetyMode = 11
+	LifeSafetyModeDisabled                 LifeSafetyMode = 12
+	LifeSafetyModeAutomaticReleaseDisabled LifeSafetyMode = 13
+	LifeSafetyModeDefault                  LifeSafetyMode = 14
+)
+
+// LifeSafetyOperation is an operation requested to a life safety object,
+// such as silencing or resetting an alarm
+type LifeSafetyOperation uint32
+
+//go:generate stringer -type=LifeSafetyOperation
+const (
+	LifeSafetyOperationNone             LifeSafetyOperation = 0
+	LifeSafetyOperationSilence          LifeSafetyOperation = 1
+	LifeSafetyOperationSilenceAudible   LifeSafetyOperation = 2
+	LifeSafetyOperationSilenceVisual    LifeSafetyOperation = 3
+	LifeSafetyOperationReset            LifeSafetyOperation = 4
+	LifeSafetyOperationResetAlarm       LifeSafetyOperation = 5
+	LifeSafetyOperationResetFault       LifeSafetyOperation = 6
+	LifeSafetyOperationUnsilence        LifeSafetyOperation = 7
+	LifeSafetyOperationUnsilenceAudible LifeSafetyOperation = 8
+	LifeSafetyOperationUnsilenceVisual  LifeSafetyOperation = 9
+)
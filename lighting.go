@@ -0,0 +1,20 @@
+package bacnet
+
+// LightingOperation is the operation of a lighting command, sent to
+// Lighting Output and Channel objects
+type LightingOperation uint32
+
+//go:generate stringer -type=LightingOperation
+const (
+	LightingOperationNone           LightingOperation = 0
+	LightingOperationFadeTo         LightingOperation = 1
+	LightingOperationRampTo         LightingOperation = 2
+	LightingOperationStepUp         LightingOperation = 3
+	LightingOperationStepDown       LightingOperation = 4
+	LightingOperationStepOn         LightingOperation = 5
+	LightingOperationStepOff        LightingOperation = 6
+	LightingOperationWarn           LightingOperation = 7
+	LightingOperationWarnOff        LightingOperation = 8
+	LightingOperationWarnRelinquish LightingOperation = 9
+	LightingOperationStop           LightingOperation = 10
+)
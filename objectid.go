@@ -0,0 +1,158 @@
+package bacnet
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// objectTypeNames are the names of the standard object types, as
+// written in the BACnet standard
+var objectTypeNames = [...]string{
+	"analog-input", "analog-output", "analog-value", "binary-input",
+	"binary-output", "binary-value", "calendar", "command", "device",
+	"event-enrollment", "file", "group", "loop", "multi-state-input",
+	"multi-state-output", "notification-class", "program", "schedule",
+	"averaging", "multi-state-value", "trend-log", "life-safety-point",
+	"life-safety-zone", "accumulator", "pulse-converter", "event-log",
+	"global-group", "trend-log-multiple", "load-control",
+	"structured-view", "access-door", "timer", "access-credential",
+	"access-point", "access-rights", "access-user", "access-zone",
+	"credential-data-input", "network-security", "bitstring-value",
+	"characterstring-value", "date-pattern-value", "date-value",
+	"datetime-pattern-value", "datetime-value", "integer-value",
+	"large-analog-value", "octetstring-value", "positive-integer-value",
+	"time-pattern-value", "time-value", "notification-forwarder",
+	"alert-enrollment", "channel", "lighting-output",
+	"binary-lighting-output", "network-port",
+}
+
+// String formats the object identifier as type:instance, such as
+// analog-input:3. Proprietary types are written as numbers
+func (o ObjectID) String() string {
+	t := strconv.Itoa(int(o.Type))
+	if int(o.Type) < len(objectTypeNames) {
+		t = objectTypeNames[o.Type]
+	}
+	return t + ":" + strconv.Itoa(int(o.Instance))
+}
+
+// ParseObjectType parses the name of an object type as written in
+// the standard (analog-input), as the name of its constant
+// (AnalogInput) or as a number
+func ParseObjectType(s string) (ObjectType, error) {
+	if v, err := strconv.ParseUint(s, 10, 16); err == nil {
+		if v >= maxObjectType {
+			return 0, fmt.Errorf("invalid object type %d", v)
+		}
+		return ObjectType(v), nil
+	}
+	for i, name := range objectTypeNames {
+		if strings.EqualFold(s, name) || strings.EqualFold(s, ObjectType(i).String()) {
+			return ObjectType(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown object type %q", s)
+}
+
+// ParseObjectID parses an object identifier formatted as
+// type:instance, such as analog-input:3
+func ParseObjectID(s string) (ObjectID, error) {
+	i := strings.LastIndexByte(s, ':')
+	if i < 0 {
+		return ObjectID{}, fmt.Errorf("invalid object identifier %q: missing instance", s)
+	}
+	t, err := ParseObjectType(s[:i])
+	if err != nil {
+		return ObjectID{}, err
+	}
+	instance, err := strconv.ParseUint(s[i+1:], 10, 32)
+	if err != nil || instance > MaxInstance {
+		return ObjectID{}, fmt.Errorf("invalid object instance %q", s[i+1:])
+	}
+	return ObjectID{Type: t, Instance: ObjectInstance(instance)}, nil
+}
+
+// ParseAddress parses an address formatted by Address.String:
+//
+//	192.168.1.20:47808           device on the local network
+//	2001:0x0D@192.168.1.1:47808  device 0x0D on network 2001, behind
+//	                             the router 192.168.1.1
+//	192.168.1.1:47808@2001       all the devices of network 2001,
+//	                             behind the router 192.168.1.1
+//	2001:0x0D                    device 0x0D on network 2001, without
+//	                             router
+//
+// The port can be omitted to use 47808. A network number after the @
+// is read as a remote network rather than as a station:
+// 192.168.1.20:47808@2001 doesn't address the device 192.168.1.20 on
+// network 2001, but all the devices of network 2001 through the router
+// 192.168.1.20. It can also be written 2001:*@192.168.1.20:47808.
+// The network 65535 is the global broadcast, to all the networks
+func ParseAddress(s string) (Address, error) {
+	station, router := "", s
+	if i := strings.IndexByte(s, '@'); i >= 0 {
+		station, router = s[:i], s[i+1:]
+		if router == "" {
+			return Address{}, fmt.Errorf("invalid address %q: missing router", s)
+		}
+		if network, err := strconv.ParseUint(router, 10, 16); err == nil {
+			station, router = strconv.FormatUint(network, 10)+":*", s[:i]
+		}
+	} else if i := strings.IndexByte(s, ':'); i > 0 {
+		if _, err := strconv.ParseUint(s[:i], 10, 16); err == nil {
+			//Remote station without router
+			station, router = s, ""
+		}
+	}
+	var addr Address
+	//The router can only be omitted for a remote station
+	if router != "" || station == "" {
+		udp, err := parseUDP(router)
+		if err != nil {
+			return Address{}, err
+		}
+		addr = *AddressFromUDP(udp)
+	}
+	if station == "" {
+		return addr, nil
+	}
+	i := strings.IndexByte(station, ':')
+	if i < 0 {
+		return Address{}, fmt.Errorf("invalid remote station %q: missing network", station)
+	}
+	network, err := strconv.ParseUint(station[:i], 10, 16)
+	if err != nil || network == 0 {
+		return Address{}, fmt.Errorf("invalid network number %q", station[:i])
+	}
+	mac, err := ParseMAC(station[i+1:])
+	if err != nil {
+		return Address{}, err
+	}
+	if len(mac) == 0 {
+		//Broadcast on the network
+		mac = nil
+	} else if network == GlobalNetwork {
+		return Address{}, fmt.Errorf("invalid remote station %q: the global broadcast has no station", station)
+	}
+	addr.Net = uint16(network)
+	addr.Adr = mac
+	return addr, nil
+}
+
+func parseUDP(s string) (net.UDPAddr, error) {
+	host, port := s, "47808"
+	if h, p, err := net.SplitHostPort(s); err == nil {
+		host, port = h, p
+	}
+	ip := net.ParseIP(host).To4()
+	if ip == nil {
+		return net.UDPAddr{}, fmt.Errorf("invalid IPv4 address %q", host)
+	}
+	p, err := strconv.ParseUint(port, 10, 16)
+	if err != nil {
+		return net.UDPAddr{}, fmt.Errorf("invalid port %q", port)
+	}
+	return net.UDPAddr{IP: ip, Port: int(p)}, nil
+}
@@ -0,0 +1,147 @@
+package bacnet
+
+import (
+	"net"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestParseObjectID(t *testing.T) {
+	ttc := []struct {
+		s         string
+		id        ObjectID
+		formatted string
+	}{
+		{s: "analog-input:3", id: ObjectID{Type: AnalogInput, Instance: 3}, formatted: "analog-input:3"},
+		{s: "AnalogInput:3", id: ObjectID{Type: AnalogInput, Instance: 3}, formatted: "analog-input:3"},
+		{s: "Analog-Input:3", id: ObjectID{Type: AnalogInput, Instance: 3}, formatted: "analog-input:3"},
+		{s: "0:3", id: ObjectID{Type: AnalogInput, Instance: 3}, formatted: "analog-input:3"},
+		{s: "device:4194303", id: WildcardDevice, formatted: "device:4194303"},
+		{s: "trend-log:0", id: ObjectID{Type: Trendlog}, formatted: "trend-log:0"},
+		{s: "network-port:1", id: ObjectID{Type: NetworkPort, Instance: 1}, formatted: "network-port:1"},
+		{s: "128:7", id: ObjectID{Type: 128, Instance: 7}, formatted: "128:7"},
+		{s: "1023:7", id: ObjectID{Type: 1023, Instance: 7}, formatted: "1023:7"},
+	}
+	for _, tc := range ttc {
+		t.Run(tc.s, func(t *testing.T) {
+			is := is.New(t)
+			id, err := ParseObjectID(tc.s)
+			is.NoErr(err)
+			is.Equal(id, tc.id)
+			is.Equal(id.String(), tc.formatted)
+			again, err := ParseObjectID(id.String())
+			is.NoErr(err)
+			is.Equal(again, id)
+		})
+	}
+}
+
+func TestParseObjectIDInvalid(t *testing.T) {
+	for _, s := range []string{"", "analog-input", "analog-input:", "analog-input:-1", "analog-input:4194304", "analog-inputs:3", "1024:3", ":3"} {
+		t.Run(s, func(t *testing.T) {
+			_, err := ParseObjectID(s)
+			if err == nil {
+				t.Errorf("%q parsed", s)
+			}
+		})
+	}
+}
+
+func TestParseObjectType(t *testing.T) {
+	is := is.New(t)
+	for i := range objectTypeNames {
+		typ := ObjectType(i)
+		parsed, err := ParseObjectType(objectTypeNames[i])
+		is.NoErr(err)
+		is.Equal(parsed, typ)
+		parsed, err = ParseObjectType(typ.String())
+		is.NoErr(err)
+		is.Equal(parsed, typ)
+	}
+}
+
+func TestParseAddress(t *testing.T) {
+	router := net.UDPAddr{IP: net.IPv4(192, 168, 1, 1).To4(), Port: 47808}
+	ttc := []struct {
+		s         string
+		addr      Address
+		formatted string
+	}{
+		{
+			s:         "192.168.1.20:47808",
+			addr:      *AddressFromUDP(net.UDPAddr{IP: net.IPv4(192, 168, 1, 20).To4(), Port: 47808}),
+			formatted: "192.168.1.20:47808",
+		},
+		{
+			s:         "192.168.1.20",
+			addr:      *AddressFromUDP(net.UDPAddr{IP: net.IPv4(192, 168, 1, 20).To4(), Port: 47808}),
+			formatted: "192.168.1.20:47808",
+		},
+		{
+			s:         "2001:0x0D@192.168.1.1:47808",
+			addr:      MSTPAddress(router, 2001, 0x0D),
+			formatted: "2001:0x0D@192.168.1.1:47808",
+		},
+		{
+			s:         "2001:13@192.168.1.1",
+			addr:      MSTPAddress(router, 2001, 0x0D),
+			formatted: "2001:0x0D@192.168.1.1:47808",
+		},
+		{
+			s:         "5:10.0.0.1:47808@192.168.1.1:47808",
+			addr:      RoutedAddress(router, 5, []byte{10, 0, 0, 1, 0xBA, 0xC0}),
+			formatted: "5:10.0.0.1:47808@192.168.1.1:47808",
+		},
+		{
+			s:         "2001:*@192.168.1.1:47808",
+			addr:      RoutedAddress(router, 2001, nil),
+			formatted: "192.168.1.1:47808@2001",
+		},
+		//The network after the router is the remote network, reached
+		//through the router 192.168.1.20, not the network of the device
+		{
+			s:         "192.168.1.20:47808@2001",
+			addr:      RoutedAddress(net.UDPAddr{IP: net.IPv4(192, 168, 1, 20).To4(), Port: 47808}, 2001, nil),
+			formatted: "192.168.1.20:47808@2001",
+		},
+		{
+			s:         "192.168.1.255@65535",
+			addr:      RoutedAddress(net.UDPAddr{IP: net.IPv4(192, 168, 1, 255).To4(), Port: 47808}, GlobalNetwork, nil),
+			formatted: "192.168.1.255:47808@65535",
+		},
+		{
+			s:         "2001:0x0D",
+			addr:      Address{Net: 2001, Adr: []byte{0x0D}},
+			formatted: "2001:0x0D",
+		},
+		{
+			s:         "2001:*",
+			addr:      Address{Net: 2001},
+			formatted: "2001:*",
+		},
+	}
+	for _, tc := range ttc {
+		t.Run(tc.s, func(t *testing.T) {
+			is := is.New(t)
+			addr, err := ParseAddress(tc.s)
+			is.NoErr(err)
+			is.Equal(addr, tc.addr)
+			is.Equal(addr.String(), tc.formatted)
+			again, err := ParseAddress(addr.String())
+			is.NoErr(err)
+			is.Equal(again, addr)
+		})
+	}
+}
+
+func TestParseAddressInvalid(t *testing.T) {
+	for _, s := range []string{"", "device", "192.168.1.20:70000", "[::1]:47808", "0x0D@192.168.1.1", "0:0x0D@192.168.1.1", "65535:0x0D@192.168.1.1", "2001:0xZZ@192.168.1.1", "2001:0x0D@router", "0:0x0D", "2001:", "2001:0x0D@"} {
+		t.Run(s, func(t *testing.T) {
+			_, err := ParseAddress(s)
+			if err == nil {
+				t.Errorf("%q parsed", s)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package bacnet
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ProprietaryObjectType describes an object type defined by a vendor
+type ProprietaryObjectType struct {
+	//Name is written in place of the type number, such as
+	//"acme-valve-actuator"
+	Name string
+	//Properties are the properties of the objects of this type, for
+	//the devices that can't list them
+	Properties []PropertyType
+}
+
+type proprietaryKey struct {
+	vendor uint32
+	typ    ObjectType
+}
+
+var proprietaryTypes = struct {
+	sync.RWMutex
+	types map[proprietaryKey]ProprietaryObjectType
+}{types: map[proprietaryKey]ProprietaryObjectType{}}
+
+// RegisterObjectType registers a proprietary object type of a vendor,
+// identified by its vendor identifier. A type registered again
+// replaces the previous one. The name must not be the name of a
+// standard type nor of another type of the vendor
+func RegisterObjectType(vendor uint32, t ObjectType, desc ProprietaryObjectType) error {
+	if t < ProprietaryMin || t > Proprietarymax {
+		return fmt.Errorf("object type %d isn't proprietary", t)
+	}
+	if desc.Name == "" {
+		return fmt.Errorf("object type %d has no name", t)
+	}
+	if _, err := ParseObjectType(desc.Name); err == nil {
+		return fmt.Errorf("object type name %q is already used by a standard type", desc.Name)
+	}
+	desc.Properties = append([]PropertyType(nil), desc.Properties...)
+	proprietaryTypes.Lock()
+	defer proprietaryTypes.Unlock()
+	for key, other := range proprietaryTypes.types {
+		if key.vendor == vendor && key.typ != t && strings.EqualFold(other.Name, desc.Name) {
+			return fmt.Errorf("object type name %q is already used by the type %d", desc.Name, key.typ)
+		}
+	}
+	proprietaryTypes.types[proprietaryKey{vendor: vendor, typ: t}] = desc
+	return nil
+}
+
+// LookupObjectType returns the proprietary object type registered for
+// the vendor
+func LookupObjectType(vendor uint32, t ObjectType) (ProprietaryObjectType, bool) {
+	proprietaryTypes.RLock()
+	defer proprietaryTypes.RUnlock()
+	desc, ok := proprietaryTypes.types[proprietaryKey{vendor: vendor, typ: t}]
+	if ok {
+		desc.Properties = append([]PropertyType(nil), desc.Properties...)
+	}
+	return desc, ok
+}
+
+// ObjectTypeName returns the name of an object type of a device of the
+// vendor: the standard name, the registered name of a proprietary
+// type, or the type number
+func ObjectTypeName(vendor uint32, t ObjectType) string {
+	if int(t) < len(objectTypeNames) {
+		return objectTypeNames[t]
+	}
+	if desc, ok := LookupObjectType(vendor, t); ok {
+		return desc.Name
+	}
+	return strconv.Itoa(int(t))
+}
+
+// FormatObjectID formats the object identifier like ObjectID.String,
+// using the names registered for the proprietary types of the vendor
+func FormatObjectID(vendor uint32, o ObjectID) string {
+	return ObjectTypeName(vendor, o.Type) + ":" + strconv.Itoa(int(o.Instance))
+}
+
+// ParseVendorObjectType parses an object type like ParseObjectType,
+// also accepting the names registered for the proprietary types of
+// the vendor
+func ParseVendorObjectType(vendor uint32, s string) (ObjectType, error) {
+	t, err := ParseObjectType(s)
+	if err == nil {
+		return t, nil
+	}
+	proprietaryTypes.RLock()
+	defer proprietaryTypes.RUnlock()
+	for key, desc := range proprietaryTypes.types {
+		if key.vendor == vendor && strings.EqualFold(desc.Name, s) {
+			return key.typ, nil
+		}
+	}
+	return 0, err
+}
@@ -0,0 +1,79 @@
+package bacnet
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestRegisterObjectType(t *testing.T) {
+	is := is.New(t)
+	//The registry is global: the vendors are only used by this test
+	const vendor, other = 9001, 9002
+	valve := ProprietaryObjectType{Name: "acme-valve", Properties: []PropertyType{ObjectName, PresentValue}}
+	is.NoErr(RegisterObjectType(vendor, 0x80, valve))
+
+	desc, ok := LookupObjectType(vendor, 0x80)
+	is.True(ok)
+	is.Equal(desc, valve)
+	//The registry keeps its own copy of the properties
+	desc.Properties[0] = Units
+	desc, _ = LookupObjectType(vendor, 0x80)
+	is.Equal(desc.Properties[0], ObjectName)
+	_, ok = LookupObjectType(other, 0x80)
+	is.True(!ok)
+
+	id := ObjectID{Type: 0x80, Instance: 3}
+	is.Equal(FormatObjectID(vendor, id), "acme-valve:3")
+	is.Equal(FormatObjectID(other, id), "128:3")
+	is.Equal(FormatObjectID(vendor, ObjectID{Type: AnalogInput, Instance: 1}), "analog-input:1")
+	typ, err := ParseVendorObjectType(vendor, "ACME-Valve")
+	is.NoErr(err)
+	is.Equal(typ, ObjectType(0x80))
+	_, err = ParseVendorObjectType(other, "acme-valve")
+	is.True(err != nil)
+	typ, err = ParseVendorObjectType(vendor, "analog-input")
+	is.NoErr(err)
+	is.Equal(typ, AnalogInput)
+	typ, err = ParseVendorObjectType(vendor, "200")
+	is.NoErr(err)
+	is.Equal(typ, ObjectType(200))
+
+	//Registering a type again replaces it
+	is.NoErr(RegisterObjectType(vendor, 0x80, ProprietaryObjectType{Name: "acme-damper"}))
+	is.Equal(ObjectTypeName(vendor, 0x80), "acme-damper")
+	_, err = ParseVendorObjectType(vendor, "acme-valve")
+	is.True(err != nil)
+	//Its name can't be used by another type of the vendor
+	is.True(RegisterObjectType(vendor, 0x81, ProprietaryObjectType{Name: "acme-damper"}) != nil)
+	_, ok = LookupObjectType(vendor, 0x81)
+	is.True(!ok)
+	//but it can by another vendor
+	is.NoErr(RegisterObjectType(other, 0x81, ProprietaryObjectType{Name: "acme-damper"}))
+	typ, err = ParseVendorObjectType(other, "acme-damper")
+	is.NoErr(err)
+	is.Equal(typ, ObjectType(0x81))
+}
+
+func TestRegisterObjectTypeInvalid(t *testing.T) {
+	ttc := []struct {
+		name string
+		typ  ObjectType
+		desc ProprietaryObjectType
+	}{
+		{name: "standard type", typ: AnalogInput, desc: ProprietaryObjectType{Name: "acme-input"}},
+		{name: "reserved type", typ: 0x7F, desc: ProprietaryObjectType{Name: "acme-reserved"}},
+		{name: "above the range", typ: 0x400, desc: ProprietaryObjectType{Name: "acme-large"}},
+		{name: "without name", typ: 0x90, desc: ProprietaryObjectType{}},
+		{name: "standard name", typ: 0x90, desc: ProprietaryObjectType{Name: "analog-input"}},
+		{name: "constant name", typ: 0x90, desc: ProprietaryObjectType{Name: "AnalogInput"}},
+	}
+	for _, tc := range ttc {
+		t.Run(tc.name, func(t *testing.T) {
+			is := is.New(t)
+			is.True(RegisterObjectType(9003, tc.typ, tc.desc) != nil)
+			_, ok := LookupObjectType(9003, tc.typ)
+			is.True(!ok)
+		})
+	}
+}
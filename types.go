@@ -14,6 +14,14 @@ const (
 	maxObjectType = 0x400
 )
 
+// WildcardInstance is the instance of the device object that a device
+// accepts as its own in the requests it receives, to read its
+// identifier without knowing it
+const WildcardInstance ObjectInstance = MaxInstance
+
+// WildcardDevice is the device object designated by WildcardInstance
+var WildcardDevice = ObjectID{Type: BacnetDevice, Instance: WildcardInstance}
+
 // ObjectType is the category of an object
 type ObjectType uint16
 
@@ -211,7 +219,41 @@ const (
 	Available16                PriorityList = 16
 )
 
+// PropertyValue is a value to write in a property. Type is the
+// application tag used to encode the value. If Type is 0 (null), the
+// tag is chosen from the go type of Value
 type PropertyValue struct {
 	Type  byte
 	Value any
 }
+
+// Application tags of the primitive bacnet data types, used as
+// PropertyValue.Type
+const (
+	TypeNull            byte = 0x00
+	TypeBoolean         byte = 0x01
+	TypeUnsignedInt     byte = 0x02
+	TypeSignedInt       byte = 0x03
+	TypeReal            byte = 0x04
+	TypeDouble          byte = 0x05
+	TypeOctetString     byte = 0x06
+	TypeCharacterString byte = 0x07
+	TypeBitString       byte = 0x08
+	TypeEnumerated      byte = 0x09
+	TypeDate            byte = 0x0A
+	TypeTime            byte = 0x0B
+	TypeObjectID        byte = 0x0C
+)
+
+// BitString is a sequence of bits. The first bit of the bacnet
+// bitstring is at index 0
+type BitString []bool
+
+// Bit returns the bit at the given index, or false if the bitstring
+// is too short
+func (b BitString) Bit(i int) bool {
+	if i < 0 || i >= len(b) {
+		return false
+	}
+	return b[i]
+}